@@ -55,6 +55,13 @@ const (
 	RemoveBackupShortId = "rm"
 )
 
+const (
+	ListWorktreeId        = "list"
+	AddWorktreeId         = "add"
+	RemoveWorktreeId      = "remove"
+	RemoveWorktreeShortId = "rm"
+)
+
 var branchForceFlagDesc = "Reset {{.LessThan}}branchname{{.GreaterThan}} to {{.LessThan}}startpoint{{.GreaterThan}}, even if {{.LessThan}}branchname{{.GreaterThan}} exists already. Without {{.EmphasisLeft}}-f{{.EmphasisRight}}, {{.EmphasisLeft}}dolt branch{{.EmphasisRight}} refuses to change an existing branch. In combination with {{.EmphasisLeft}}-d{{.EmphasisRight}} (or {{.EmphasisLeft}}--delete{{.EmphasisRight}}), allow deleting the branch irrespective of its merged status. In combination with -m (or {{.EmphasisLeft}}--move{{.EmphasisRight}}), allow renaming the branch even if the new branch name already exists, the same applies for {{.EmphasisLeft}}-c{{.EmphasisRight}} (or {{.EmphasisLeft}}--copy{{.EmphasisRight}})."
 
 // CreateCommitArgParser creates the argparser shared dolt commit cli and DOLT_COMMIT.
@@ -162,6 +169,12 @@ func CreateCleanArgParser() *argparser.ArgParser {
 	return ap
 }
 
+func CreateBranchPruneArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("branch_prune", 0)
+	ap.SupportsFlag(DryRunFlag, "", "Lists the branches and tags that the branch policies would delete, without deleting them.")
+	return ap
+}
+
 func CreateCheckoutArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParserWithVariableArgs("checkout")
 	ap.SupportsString(CheckoutCreateBranch, "", "branch", "Create a new branch named {{.LessThan}}new_branch{{.GreaterThan}} and start it at {{.LessThan}}start_point{{.GreaterThan}}.")
@@ -264,6 +277,10 @@ func CreateBackupArgParser() *argparser.ArgParser {
 	return ap
 }
 
+func CreateWorktreeArgParser() *argparser.ArgParser {
+	return argparser.NewArgParserWithVariableArgs("worktree")
+}
+
 func CreateVerifyConstraintsArgParser(name string) *argparser.ArgParser {
 	ap := argparser.NewArgParserWithVariableArgs(name)
 	ap.SupportsFlag(AllFlag, "a", "Verifies that all rows in the database do not violate constraints instead of just rows modified or inserted in the working set.")
@@ -291,6 +308,23 @@ func CreateLogArgParser(isTableFunction bool) *argparser.ArgParser {
 	return ap
 }
 
+func CreateBlameArgParser(isTableFunction bool) *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("blame", 2)
+	ap.SupportsString(SinceFlag, "", "revision", "Stops searching history at the given revision, and attributes every cell that wasn't modified after it to it.")
+	ap.SupportsString(UntilFlag, "", "revision", "Annotates the table as of the given revision instead of HEAD.")
+	ap.SupportsFlag(FollowFlag, "", "Follows columns across renames by matching them by their tags, rather than their names.")
+	if !isTableFunction {
+		ap.SupportsFlag(ColumnsFlag, "", "Annotates each column value, rather than each row, with the revision that last modified it.")
+	}
+	return ap
+}
+
+func CreateTestRunArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("run", 1)
+	ap.SupportsString(GroupFlag, "g", "group", "Only run the tests in the given group.")
+	return ap
+}
+
 func CreateGCArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParserWithMaxArgs("gc", 0)
 	ap.SupportsFlag(ShallowFlag, "s", "perform a fast, but incomplete garbage collection pass")
@@ -304,6 +338,24 @@ func CreateCountCommitsArgParser() *argparser.ArgParser {
 	return ap
 }
 
+func CreateBisectArgParser() *argparser.ArgParser {
+	return argparser.NewArgParserWithVariableArgs("bisect")
+}
+
+func CreateRerereArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithVariableArgs("rerere")
+	ap.SupportsFlag(AllFlag, "a", "Forget every recorded resolution.")
+	return ap
+}
+
+func CreateMaterializedViewArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithVariableArgs("materialized_view")
+	ap.SupportsFlag(RefreshOnCommitFlag, "", "Refresh the created view every time a commit is made.")
+	ap.SupportsFlag(FullFlag, "", "Rebuild the refreshed views from scratch instead of applying the changes to the tables they read.")
+	ap.SupportsFlag(IfExistsFlag, "", "Don't fail when dropping a view that doesn't exist.")
+	return ap
+}
+
 func CreateReflogArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParserWithMaxArgs("reflog", 1)
 	ap.SupportsFlag(AllFlag, "", "Show all refs, including hidden refs, such as DoltHub workspace refs")
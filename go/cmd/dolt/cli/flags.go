@@ -25,6 +25,7 @@ const (
 	BranchParam          = "branch"
 	CachedFlag           = "cached"
 	CheckoutCreateBranch = "b"
+	ColumnsFlag          = "columns"
 	CreateResetBranch    = "B"
 	CommitFlag           = "commit"
 	ContinueFlag         = "continue"
@@ -36,10 +37,14 @@ const (
 	DepthFlag            = "depth"
 	DryRunFlag           = "dry-run"
 	EmptyParam           = "empty"
+	FollowFlag           = "follow"
+	FullFlag             = "full"
 	ForceFlag            = "force"
 	GraphFlag            = "graph"
+	GroupFlag            = "group"
 	HardResetParam       = "hard"
 	HostFlag             = "host"
+	IfExistsFlag         = "if-exists"
 	InteractiveFlag      = "interactive"
 	ListFlag             = "list"
 	MergesFlag           = "merges"
@@ -63,6 +68,7 @@ const (
 	PortFlag             = "port"
 	PruneFlag            = "prune"
 	QuietFlag            = "quiet"
+	RefreshOnCommitFlag  = "refresh-on-commit"
 	RemoteParam          = "remote"
 	SetUpstreamFlag      = "set-upstream"
 	ShallowFlag          = "shallow"
@@ -70,6 +76,7 @@ const (
 	ShowSignatureFlag    = "show-signature"
 	SignFlag             = "gpg-sign"
 	SilentFlag           = "silent"
+	SinceFlag            = "since"
 	SingleBranchFlag     = "single-branch"
 	SkipEmptyFlag        = "skip-empty"
 	SoftResetParam       = "soft"
@@ -80,6 +87,7 @@ const (
 	TablesFlag           = "tables"
 	TheirsFlag           = "theirs"
 	TrackFlag            = "track"
+	UntilFlag            = "until"
 	UpperCaseAllFlag     = "ALL"
 	UserFlag             = "user"
 )
@@ -21,6 +21,7 @@ import (
 var Commands = cli.NewHiddenSubCommandHandler("admin", "Commands for directly working with Dolt storage for purposes of testing or database recovery", []cli.Command{
 	SetRefCmd{},
 	ShowRootCmd{},
+	RekeyCmd{},
 
 	ZstdCmd{},
 })
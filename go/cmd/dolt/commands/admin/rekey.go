@@ -0,0 +1,116 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+const skipGCFlag = "skip-gc"
+
+var rekeyDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Rewrites the database's storage encrypted with the active encryption key",
+	LongDesc: `Rewrites every table file, archive and chunk journal of the database that is not encrypted with the active encryption key, which is the first key of {{.EmphasisLeft}}DOLT_ENCRYPTION_KEY{{.EmphasisRight}} or {{.EmphasisLeft}}DOLT_ENCRYPTION_KEY_FILE{{.EmphasisRight}}. The remaining keys are the previous keys, which are needed to read the storage being rewritten. Unencrypted storage is encrypted.
+
+The database is garbage collected first, which rewrites all recently written data under the active key. Once rekey completes, previous keys are no longer needed and can be removed. The database should not be in use by a running server while it is rekeyed.`,
+	Synopsis: []string{
+		"[--skip-gc]",
+	},
+}
+
+type RekeyCmd struct {
+}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd RekeyCmd) Name() string {
+	return "rekey"
+}
+
+// Description returns a description of the command
+func (cmd RekeyCmd) Description() string {
+	return rekeyDocs.ShortDesc
+}
+
+// RequiresRepo should return false if this interface is implemented, and the command does not have the requirement
+// that it be run from within a data repository directory
+func (cmd RekeyCmd) RequiresRepo() bool {
+	return true
+}
+
+func (cmd RekeyCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(rekeyDocs, ap)
+}
+
+func (cmd RekeyCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 0)
+	ap.SupportsFlag(skipGCFlag, "", "Rewrite storage without garbage collecting the database first.")
+	return ap
+}
+
+func (cmd RekeyCmd) Hidden() bool {
+	return true
+}
+
+// Exec executes the command
+func (cmd RekeyCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, rekeyDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	keys, err := nbs.EncryptionKeysFromEnv()
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	} else if keys == nil {
+		err = errors.New("no encryption key is configured; set " + dconfig.EnvEncryptionKey + " or " + dconfig.EnvEncryptionKeyFile)
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	db := doltdb.HackDatasDatabaseFromDoltDB(dEnv.DoltDB)
+	gcs, ok := datas.ChunkStoreFromDatabase(db).(*nbs.GenerationalNBS)
+	if !ok {
+		err = errors.New("rekey requires a database with local storage")
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	if !apr.Contains(skipGCFlag) {
+		err = dEnv.DoltDB.GC(ctx, func() error { return nil })
+		if err != nil && !errors.Is(err, chunks.ErrNothingToCollect) {
+			verr := errhand.BuildDError("failed to garbage collect the database").AddCause(err).Build()
+			return commands.HandleVErrAndExitCode(verr, usage)
+		}
+	}
+
+	n, err := gcs.Rekey(ctx)
+	if err != nil {
+		verr := errhand.BuildDError("failed to rekey the database").AddCause(err).Build()
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	cli.Printf("Rewrote %d storage files with encryption key %s\n", n, keys.ActiveKeyID())
+	return 0
+}
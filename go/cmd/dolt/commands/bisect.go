@@ -0,0 +1,127 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+var bisectDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Use binary search to find the commit that introduced a bug",
+	LongDesc: `Searches the commit history between a known good commit and a known bad commit for the first bad commit.
+Each step of the search picks the commit that splits the remaining commits most evenly, including the commits reachable
+through every parent of a merge commit. Commits are not checked out; instead, each step reports the commit to test,
+which can be inspected with {{.EmphasisLeft}}AS OF{{.EmphasisRight}} queries, and then marked good or bad.
+
+The state of the bisect is stored in the repository, so it carries over between invocations of {{.EmphasisLeft}}dolt bisect{{.EmphasisRight}} until it is reset.
+
+{{.EmphasisLeft}}start [<bad> [<good>...]]{{.EmphasisRight}}
+Starts a new bisect, optionally marking the first revision given as bad and any other revisions as good.
+
+{{.EmphasisLeft}}bad [<rev>]{{.EmphasisRight}}, {{.EmphasisLeft}}good [<rev>...]{{.EmphasisRight}}
+Marks revisions as bad or good. Without a revision, marks the commit currently being tested, or HEAD if there is none.
+
+{{.EmphasisLeft}}skip [<rev>...]{{.EmphasisRight}}
+Marks revisions that cannot be tested, so that a nearby commit is chosen instead.
+
+{{.EmphasisLeft}}run <query>{{.EmphasisRight}}
+Tests commits automatically until the first bad commit is found. The query is evaluated with every table it reads
+resolved AS OF the commit being tested, and must return a single boolean value: true if the commit is good, false if
+it is bad, or NULL to skip it.
+
+{{.EmphasisLeft}}log{{.EmphasisRight}}
+Shows the commands applied to the current bisect.
+
+{{.EmphasisLeft}}reset{{.EmphasisRight}}
+Ends the current bisect and clears its state.`,
+	Synopsis: []string{
+		`start [{{.LessThan}}bad{{.GreaterThan}} [{{.LessThan}}good{{.GreaterThan}}...]]`,
+		`(bad | good | skip) [{{.LessThan}}rev{{.GreaterThan}}...]`,
+		`run {{.LessThan}}query{{.GreaterThan}}`,
+		`(log | reset)`,
+	},
+}
+
+type BisectCmd struct{}
+
+var _ cli.Command = BisectCmd{}
+
+// Name returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd BisectCmd) Name() string {
+	return "bisect"
+}
+
+// Description returns a description of the command
+func (cmd BisectCmd) Description() string {
+	return bisectDocs.ShortDesc
+}
+
+// EventType returns the type of the event to log
+func (cmd BisectCmd) EventType() eventsapi.ClientEventType {
+	return eventsapi.ClientEventType_TYPE_UNSPECIFIED
+}
+
+func (cmd BisectCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(bisectDocs, ap)
+}
+
+func (cmd BisectCmd) ArgParser() *argparser.ArgParser {
+	return cli.CreateBisectArgParser()
+}
+
+// Exec executes the command
+func (cmd BisectCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, bisectDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	if apr.NArg() == 0 {
+		usage()
+		return 1
+	}
+
+	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if closeFunc != nil {
+		defer closeFunc()
+	}
+
+	query, err := interpolateStoredProcedureCall("DOLT_BISECT", apr.Args)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	rows, err := GetRowsForSql(queryist, sqlCtx, query)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	if len(rows) > 0 {
+		if message, ok := rows[0][2].(string); ok {
+			cli.Println(message)
+		}
+	}
+
+	return 0
+}
@@ -20,6 +20,8 @@ import (
 	"regexp"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/gocraft/dbr/v2"
+	"github.com/gocraft/dbr/v2/dialect"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
@@ -37,9 +39,12 @@ const (
 
 var blameDocs = cli.CommandDocumentationContent{
 	ShortDesc: `Show what revision and author last modified each row of a table`,
-	LongDesc:  `Annotates each row in the given table with information from the revision which last modified the row. Optionally, start annotating from the given revision.`,
+	LongDesc: `Annotates each row in the given table with information from the revision which last modified the row. Optionally, start annotating from the given revision.
+
+With {{.EmphasisLeft}}--columns{{.EmphasisRight}}, annotates each column value of each row instead, with the revision which last modified the value. Cells are blamed by following the first parent of each commit. {{.EmphasisLeft}}--since{{.EmphasisRight}} stops at the given revision, which must be in the first-parent history of the annotated revision, and attributes the cells that weren't modified after it to it. {{.EmphasisLeft}}--until{{.EmphasisRight}} annotates the table as of the given revision, like the optional revision argument. Columns are matched across revisions by name, so the values of a renamed column are attributed to the revision that renamed it, unless {{.EmphasisLeft}}--follow{{.EmphasisRight}} is given, which matches columns by their tags instead.`,
 	Synopsis: []string{
 		`[{{.LessThan}}rev{{.GreaterThan}}] {{.LessThan}}tablename{{.GreaterThan}}`,
+		`--columns [--since {{.LessThan}}rev{{.GreaterThan}}] [--until {{.LessThan}}rev{{.GreaterThan}}] [--follow] [{{.LessThan}}rev{{.GreaterThan}}] {{.LessThan}}tablename{{.GreaterThan}}`,
 	},
 }
 
@@ -61,8 +66,7 @@ func (cmd BlameCmd) Docs() *cli.CommandDocumentation {
 }
 
 func (cmd BlameCmd) ArgParser() *argparser.ArgParser {
-	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 2)
-	return ap
+	return cli.CreateBlameArgParser(false)
 }
 
 func (cmd BlameCmd) RequiresRepo() bool {
@@ -101,6 +105,14 @@ func (cmd BlameCmd) Exec(ctx context.Context, commandStr string, args []string,
 		usage()
 		return 1
 	}
+	if !apr.Contains(cli.ColumnsFlag) && (apr.Contains(cli.SinceFlag) || apr.Contains(cli.UntilFlag) || apr.Contains(cli.FollowFlag)) {
+		iohelp.WriteLine(cli.CliOut, "--since, --until and --follow can only be used with --columns")
+		return 1
+	}
+	if apr.NArg() == 2 && apr.Contains(cli.UntilFlag) {
+		iohelp.WriteLine(cli.CliOut, "--until cannot be used with a revision argument")
+		return 1
+	}
 
 	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
 	if err != nil {
@@ -113,12 +125,18 @@ func (cmd BlameCmd) Exec(ctx context.Context, commandStr string, args []string,
 
 	var schema sql.Schema
 	var ri sql.RowIter
-	if apr.NArg() == 1 {
+	if apr.Contains(cli.ColumnsFlag) {
+		var query string
+		query, err = blameCellsQuery(apr)
+		if err == nil {
+			schema, ri, _, err = queryist.Query(sqlCtx, query)
+		}
+	} else if apr.NArg() == 1 {
 		schema, ri, _, err = queryist.Query(sqlCtx, fmt.Sprintf(blameQueryTemplate, apr.Arg(0), "HEAD"))
 	} else {
 		// validate input
 		ref := apr.Arg(0)
-		if !ref2.IsValidTagName(ref) && !doltdb.IsValidCommitHash(ref) && !isValidHeadRef(ref) {
+		if !isValidBlameRef(ref) {
 			iohelp.WriteLine(cli.CliOut, "Invalid reference provided")
 			return 1
 		}
@@ -139,6 +157,39 @@ func (cmd BlameCmd) Exec(ctx context.Context, commandStr string, args []string,
 	return 0
 }
 
+// blameCellsQuery returns the query for `dolt blame --columns`, which selects from the dolt_blame_cells() table
+// function.
+func blameCellsQuery(apr *argparser.ArgParseResults) (string, error) {
+	tableName := apr.Arg(apr.NArg() - 1)
+	args := []string{tableName}
+	until, hasUntil := apr.GetValue(cli.UntilFlag)
+	if apr.NArg() == 2 {
+		until, hasUntil = apr.Arg(0), true
+	}
+	if hasUntil {
+		if !isValidBlameRef(until) {
+			return "", fmt.Errorf("Invalid reference provided")
+		}
+		args = append(args, "--"+cli.UntilFlag, until)
+	}
+	if since, ok := apr.GetValue(cli.SinceFlag); ok {
+		if !isValidBlameRef(since) {
+			return "", fmt.Errorf("Invalid reference provided")
+		}
+		args = append(args, "--"+cli.SinceFlag, since)
+	}
+	if apr.Contains(cli.FollowFlag) {
+		args = append(args, "--"+cli.FollowFlag)
+	}
+	query := fmt.Sprintf("SELECT * FROM dolt_blame_cells(%s)", buildPlaceholdersString(len(args)))
+	return dbr.InterpolateForDialect(query, stringSliceToInterfaceSlice(args), dialect.MySQL)
+}
+
+// isValidBlameRef returns whether |ref| is a tag name, commit hash or HEAD ancestor spec that blame can start from.
+func isValidBlameRef(ref string) bool {
+	return ref2.IsValidTagName(ref) || doltdb.IsValidCommitHash(ref) || isValidHeadRef(ref)
+}
+
 func isValidHeadRef(s string) bool {
 	var refRegex = regexp.MustCompile(`(?i)^head[\~\^0-9]*$`)
 	return refRegex.MatchString(s)
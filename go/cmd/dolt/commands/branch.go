@@ -51,19 +51,23 @@ With a {{.EmphasisLeft}}-m{{.EmphasisRight}}, {{.LessThan}}oldbranch{{.GreaterTh
 
 The {{.EmphasisLeft}}-c{{.EmphasisRight}} options have the exact same semantics as {{.EmphasisLeft}}-m{{.EmphasisRight}}, except instead of the branch being renamed it will be copied to a new name.
 
-With a {{.EmphasisLeft}}-d{{.EmphasisRight}}, {{.LessThan}}branchname{{.GreaterThan}} will be deleted. You may specify more than one branch for deletion.`,
+With a {{.EmphasisLeft}}-d{{.EmphasisRight}}, {{.LessThan}}branchname{{.GreaterThan}} will be deleted. You may specify more than one branch for deletion.
+
+With {{.EmphasisLeft}}--prune-policy{{.EmphasisRight}}, the branches and tags selected by the retention policies of the {{.EmphasisLeft}}dolt_branch_policies{{.EmphasisRight}} system table are deleted, except for checked out branches and those that {{.EmphasisLeft}}dolt_branch_control{{.EmphasisRight}} doesn't allow to delete. With {{.EmphasisLeft}}--dry-run{{.EmphasisRight}}, they are only listed.`,
 	Synopsis: []string{
 		`[--list] [-v] [-a] [-r]`,
 		`[-f] {{.LessThan}}branchname{{.GreaterThan}} [{{.LessThan}}start-point{{.GreaterThan}}]`,
 		`-m [-f] [{{.LessThan}}oldbranch{{.GreaterThan}}] {{.LessThan}}newbranch{{.GreaterThan}}`,
 		`-c [-f] [{{.LessThan}}oldbranch{{.GreaterThan}}] {{.LessThan}}newbranch{{.GreaterThan}}`,
 		`-d [-f] [-r] {{.LessThan}}branchname{{.GreaterThan}}...`,
+		`--prune-policy [--dry-run]`,
 	},
 }
 
 const (
 	datasetsFlag    = "datasets"
 	showCurrentFlag = "show-current"
+	prunePolicyFlag = "prune-policy"
 )
 
 type BranchCmd struct{}
@@ -94,6 +98,8 @@ func (cmd BranchCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsFlag(datasetsFlag, "", "List all datasets in the database")
 	ap.SupportsFlag(cli.RemoteParam, "r", "When in list mode, show only remote tracked branches. When with -d, delete a remote tracking branch.")
 	ap.SupportsFlag(showCurrentFlag, "", "Print the name of the current branch")
+	ap.SupportsFlag(prunePolicyFlag, "", "Delete the branches and tags selected by the policies of the dolt_branch_policies table")
+	ap.SupportsFlag(cli.DryRunFlag, "", "With --prune-policy, list the branches and tags that would be deleted without deleting them")
 	return ap
 }
 
@@ -119,8 +125,8 @@ func (cmd BranchCmd) Exec(ctx context.Context, commandStr string, args []string,
 		defer closeFunc()
 	}
 
-	if len(apr.ContainsMany(cli.MoveFlag, cli.CopyFlag, cli.DeleteFlag, cli.DeleteForceFlag, cli.ListFlag, showCurrentFlag)) > 1 {
-		cli.PrintErrln("Must specify exactly one of --move/-m, --copy/-c, --delete/-d, -D, --show-current, or --list.")
+	if len(apr.ContainsMany(cli.MoveFlag, cli.CopyFlag, cli.DeleteFlag, cli.DeleteForceFlag, cli.ListFlag, showCurrentFlag, prunePolicyFlag)) > 1 {
+		cli.PrintErrln("Must specify exactly one of --move/-m, --copy/-c, --delete/-d, -D, --show-current, --prune-policy, or --list.")
 		return 1
 	}
 
@@ -137,6 +143,8 @@ func (cmd BranchCmd) Exec(ctx context.Context, commandStr string, args []string,
 		return printBranches(sqlCtx, queryEngine, apr, usage)
 	case apr.Contains(showCurrentFlag):
 		return printCurrentBranch(sqlCtx, queryEngine)
+	case apr.Contains(prunePolicyFlag):
+		return pruneBranches(sqlCtx, queryEngine, apr, usage)
 	case apr.Contains(datasetsFlag):
 		return printAllDatasets(ctx, dEnv)
 	case apr.NArg() > 0:
@@ -469,6 +477,35 @@ func deleteBranches(sqlCtx *sql.Context, queryEngine cli.Queryist, apr *argparse
 	return callStoredProcedure(sqlCtx, queryEngine, args)
 }
 
+// pruneBranches deletes the branches and tags selected by the branch policies of the database, or only prints them if
+// --dry-run is given.
+func pruneBranches(sqlCtx *sql.Context, queryEngine cli.Queryist, apr *argparser.ArgParseResults, usage cli.UsagePrinter) int {
+	if apr.NArg() > 0 {
+		usage()
+		return 1
+	}
+
+	dryRun := apr.Contains(cli.DryRunFlag)
+	query := "CALL DOLT_BRANCH_PRUNE()"
+	if dryRun {
+		query = "CALL DOLT_BRANCH_PRUNE('--dry-run')"
+	}
+	rows, err := GetRowsForSql(queryEngine, sqlCtx, query)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(fmt.Errorf("error: %s", err.Error())), nil)
+	}
+
+	for _, row := range rows {
+		refType, name, hash, policy := row[0].(string), row[1].(string), row[2].(string), row[3].(string)
+		if dryRun {
+			cli.Printf("Would delete %s %s (was %s), per policy %s\n", refType, name, hash, policy)
+		} else {
+			cli.Printf("Deleted %s %s (was %s), per policy %s\n", refType, name, hash, policy)
+		}
+	}
+	return 0
+}
+
 func generateForceDeleteMessage(args []string) string {
 	newArgs := ""
 	for _, arg := range args {
@@ -135,6 +135,14 @@ func (cmd CheckoutCmd) Exec(ctx context.Context, commandStr string, args []strin
 		branchName = apr.Arg(0)
 	}
 
+	// The working set of a branch checked out in another worktree belongs to that worktree, so it can't be checked
+	// out here too
+	if dEnv != nil && branchName != "" {
+		if err := dEnv.CheckBranchNotInOtherWorktree(branchName); err != nil {
+			return HandleVErrAndExitCode(errhand.BuildDError("fatal: %s", err.Error()).Build(), usage)
+		}
+	}
+
 	sqlQuery, err := generateCheckoutSql(args)
 	if err != nil {
 		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
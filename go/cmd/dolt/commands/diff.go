@@ -72,6 +72,8 @@ const (
 	MergeBase    = "merge-base"
 	DiffMode     = "diff-mode"
 	ReverseFlag  = "reverse"
+
+	DetectRenamesFlag = "detect-renames"
 )
 
 var diffDocs = cli.CommandDocumentationContent{
@@ -99,6 +101,8 @@ The diffs displayed can be limited to show the first N by providing the paramete
 To filter which data rows are displayed, use {{.EmphasisLeft}}--where <SQL expression>{{.EmphasisRight}}. Table column names in the filter expression must be prefixed with {{.EmphasisLeft}}from_{{.EmphasisRight}} or {{.EmphasisLeft}}to_{{.EmphasisRight}}, e.g. {{.EmphasisLeft}}to_COLUMN_NAME > 100{{.EmphasisRight}} or {{.EmphasisLeft}}from_COLUMN_NAME + to_COLUMN_NAME = 0{{.EmphasisRight}}.
 
 The {{.EmphasisLeft}}--diff-mode{{.EmphasisRight}} argument controls how modified rows are presented when the format output is set to {{.EmphasisLeft}}tabular{{.EmphasisRight}}. When set to {{.EmphasisLeft}}row{{.EmphasisRight}}, modified rows are presented as old and new rows. When set to {{.EmphasisLeft}}line{{.EmphasisRight}}, modified rows are presented as a single row, and changes are presented using "+" and "-" within the column. When set to {{.EmphasisLeft}}in-place{{.EmphasisRight}}, modified rows are presented as a single row, and changes are presented side-by-side with a color distinction (requires a color-enabled terminal). When set to {{.EmphasisLeft}}context{{.EmphasisRight}}, rows that contain at least one column that spans multiple lines uses {{.EmphasisLeft}}line{{.EmphasisRight}}, while all other rows use {{.EmphasisLeft}}row{{.EmphasisRight}}. The default value is {{.EmphasisLeft}}context{{.EmphasisRight}}.
+
+With {{.EmphasisLeft}}--detect-renames{{.EmphasisRight}}, rows that were removed and added by the diff are paired when they appear to be the same row, and shown as modified rows. This shows a change to the primary key of a row as a modification of the row, and shows the data diff of tables whose primary key was redefined. Rows are paired when they have the same values for a unique index, or for the primary key before or after it was redefined, or when the fraction of their other columns with the same values is at least {{.EmphasisLeft}}@@dolt_row_lineage_similarity{{.EmphasisRight}}. Renamed rows can't be shown with {{.EmphasisLeft}}sql{{.EmphasisRight}} output.
 `,
 	Synopsis: []string{
 		`[options] [{{.LessThan}}commit{{.GreaterThan}}] [{{.LessThan}}tables{{.GreaterThan}}...]`,
@@ -113,6 +117,9 @@ type diffDisplaySettings struct {
 	limit      int
 	where      string
 	skinny     bool
+	// renameSimilarity is the similarity threshold of rows paired by --detect-renames, or a negative number if rows
+	// aren't paired
+	renameSimilarity float64
 }
 
 type diffDatasets struct {
@@ -179,6 +186,7 @@ func (cmd DiffCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsString(DiffMode, "", "diff mode", "Determines how to display modified rows with tabular output. Valid values are row, line, in-place, context. Defaults to context.")
 	ap.SupportsFlag(ReverseFlag, "R", "Reverses the direction of the diff.")
 	ap.SupportsFlag(NameOnlyFlag, "", "Only shows table names.")
+	ap.SupportsFlag(DetectRenamesFlag, "", "Shows removed and added rows that appear to be the same row as modified rows.")
 	return ap
 }
 
@@ -236,6 +244,10 @@ func (cmd DiffCmd) validateArgs(apr *argparser.ArgParseResults) errhand.VerboseE
 		return errhand.BuildDError("invalid output format: %s", f).Build()
 	}
 
+	if apr.Contains(DetectRenamesFlag) && strings.ToLower(f) == "sql" {
+		return errhand.BuildDError("invalid Arguments: --detect-renames cannot be combined with sql output").Build()
+	}
+
 	return nil
 }
 
@@ -279,6 +291,7 @@ func parseDiffDisplaySettings(apr *argparser.ArgParseResults) *diffDisplaySettin
 
 	displaySettings.limit, _ = apr.GetInt(limitParam)
 	displaySettings.where = apr.GetValueOrDefault(whereParam, "")
+	displaySettings.renameSimilarity = -1
 
 	return displaySettings
 }
@@ -309,9 +322,36 @@ func parseDiffArgs(queryist cli.Queryist, sqlCtx *sql.Context, apr *argparser.Ar
 
 	dArgs.tableSet = tableSet
 
+	if apr.Contains(DetectRenamesFlag) {
+		dArgs.renameSimilarity, err = getRowLineageSimilarity(queryist, sqlCtx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return dArgs, nil
 }
 
+// getRowLineageSimilarity returns the value of @@dolt_row_lineage_similarity, the similarity threshold of rows paired
+// by --detect-renames.
+func getRowLineageSimilarity(queryist cli.Queryist, sqlCtx *sql.Context) (float64, error) {
+	rows, err := GetRowsForSql(queryist, sqlCtx, "select @@dolt_row_lineage_similarity")
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 {
+		return 0, fmt.Errorf("unexpected result for @@dolt_row_lineage_similarity")
+	}
+	switch v := rows[0][0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("unexpected type for @@dolt_row_lineage_similarity: %T", v)
+	}
+}
+
 func parseDiffTableSetSql(queryist cli.Queryist, sqlCtx *sql.Context, datasets *diffDatasets, tableNames []string) (*set.StrSet, error) {
 
 	tablesAtFromRef, err := getTableNamesAtRef(queryist, sqlCtx, datasets.fromRef)
@@ -779,7 +819,7 @@ func diffUserTables(queryist cli.Queryist, sqlCtx *sql.Context, dArgs *diffArgs)
 
 	doltSchemasChanged := false
 	for _, delta := range deltas {
-		if doltdb.IsFullTextTable(delta.TableName.Name) {
+		if doltdb.IsFullTextTable(delta.TableName.Name) || doltdb.IsVectorIndexTable(delta.TableName.Name) {
 			continue
 		}
 
@@ -1409,8 +1449,8 @@ func diffRows(
 		return errhand.VerboseErrorFromError(err)
 	}
 
-	// can't diff
-	if !diffable {
+	// can't diff, unless rows are paired across the change to the primary key
+	if !diffable && dArgs.renameSimilarity < 0 {
 		// TODO: this messes up some structured output if the user didn't redirect it
 		cli.PrintErrf("Primary key sets differ between revisions for table '%s', skipping data diff\n", tableSummary.ToTableName)
 		err = rowWriter.Close(sqlCtx)
@@ -1523,7 +1563,12 @@ func diffRows(
 		}
 	}
 
-	err = writeDiffResults(sqlCtx, sch, unionSch, rowIter, rowWriter, modifiedColNames, dArgs)
+	var renames *renameDetector
+	if dArgs.renameSimilarity >= 0 && fromTableInfo != nil && toTableInfo != nil {
+		renames = newRenameDetector(fromTableInfo.Sch, toTableInfo.Sch, unionSch, dArgs.renameSimilarity)
+	}
+
+	err = writeDiffResults(sqlCtx, sch, unionSch, rowIter, rowWriter, modifiedColNames, dArgs, renames)
 	if err != nil {
 		return errhand.BuildDError("Error running diff query:\n%s", interpolatedQuery).AddCause(err).Build()
 	}
@@ -1640,6 +1685,7 @@ func writeDiffResults(
 	writer diff.SqlRowDiffWriter,
 	modifiedColNames map[string]bool,
 	dArgs *diffArgs,
+	renames *renameDetector,
 ) error {
 	ds, err := diff.NewDiffSplitter(diffQuerySch, targetSch)
 	if err != nil {
@@ -1649,7 +1695,7 @@ func writeDiffResults(
 	for {
 		r, err := iter.Next(ctx)
 		if err == io.EOF {
-			return nil
+			break
 		} else if err != nil {
 			return err
 		}
@@ -1659,46 +1705,151 @@ func writeDiffResults(
 			return err
 		}
 
-		if dArgs.skinny {
-			var filteredOldRow, filteredNewRow diff.RowDiff
-			for i, changeType := range newRow.ColDiffs {
-				if (changeType == diff.Added|diff.Removed) || modifiedColNames[targetSch[i].Name] {
-					if i < len(oldRow.Row) {
-						filteredOldRow.Row = append(filteredOldRow.Row, oldRow.Row[i])
-						filteredOldRow.ColDiffs = append(filteredOldRow.ColDiffs, oldRow.ColDiffs[i])
-						filteredOldRow.RowDiff = oldRow.RowDiff
-					}
-
-					if i < len(newRow.Row) {
-						filteredNewRow.Row = append(filteredNewRow.Row, newRow.Row[i])
-						filteredNewRow.ColDiffs = append(filteredNewRow.ColDiffs, newRow.ColDiffs[i])
-						filteredNewRow.RowDiff = newRow.RowDiff
-					}
+		// removed and added rows are written once they've all been seen, and paired
+		if renames != nil && oldRow.RowDiff == diff.Removed {
+			renames.removed = append(renames.removed, oldRow)
+			continue
+		} else if renames != nil && newRow.RowDiff == diff.Added {
+			renames.added = append(renames.added, newRow)
+			continue
+		}
+
+		if err = writeDiffRow(ctx, targetSch, writer, modifiedColNames, dArgs, oldRow, newRow); err != nil {
+			return err
+		}
+	}
+
+	if renames == nil {
+		return nil
+	}
+	return renames.write(ctx, func(oldRow, newRow diff.RowDiff) error {
+		return writeDiffRow(ctx, targetSch, writer, modifiedColNames, dArgs, oldRow, newRow)
+	})
+}
+
+// writeDiffRow writes the old and new versions of a row of a diff, either of which may be empty.
+func writeDiffRow(
+	ctx *sql.Context,
+	targetSch sql.Schema,
+	writer diff.SqlRowDiffWriter,
+	modifiedColNames map[string]bool,
+	dArgs *diffArgs,
+	oldRow, newRow diff.RowDiff,
+) error {
+	if dArgs.skinny {
+		var filteredOldRow, filteredNewRow diff.RowDiff
+		for i, changeType := range newRow.ColDiffs {
+			if (changeType == diff.Added|diff.Removed) || modifiedColNames[targetSch[i].Name] {
+				if i < len(oldRow.Row) {
+					filteredOldRow.Row = append(filteredOldRow.Row, oldRow.Row[i])
+					filteredOldRow.ColDiffs = append(filteredOldRow.ColDiffs, oldRow.ColDiffs[i])
+					filteredOldRow.RowDiff = oldRow.RowDiff
+				}
+
+				if i < len(newRow.Row) {
+					filteredNewRow.Row = append(filteredNewRow.Row, newRow.Row[i])
+					filteredNewRow.ColDiffs = append(filteredNewRow.ColDiffs, newRow.ColDiffs[i])
+					filteredNewRow.RowDiff = newRow.RowDiff
 				}
 			}
+		}
+
+		oldRow = filteredOldRow
+		newRow = filteredNewRow
+	}
 
-			oldRow = filteredOldRow
-			newRow = filteredNewRow
+	// We are guaranteed to have "ModeRow" for writers that do not support combined rows
+	if dArgs.diffMode != diff.ModeRow && oldRow.RowDiff == diff.ModifiedOld && newRow.RowDiff == diff.ModifiedNew {
+		return writer.WriteCombinedRow(ctx, oldRow.Row, newRow.Row, dArgs.diffMode)
+	}
+	if oldRow.Row != nil {
+		if err := writer.WriteRow(ctx, oldRow.Row, oldRow.RowDiff, oldRow.ColDiffs); err != nil {
+			return err
 		}
+	}
+	if newRow.Row != nil {
+		if err := writer.WriteRow(ctx, newRow.Row, newRow.RowDiff, newRow.ColDiffs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		// We are guaranteed to have "ModeRow" for writers that do not support combined rows
-		if dArgs.diffMode != diff.ModeRow && oldRow.RowDiff == diff.ModifiedOld && newRow.RowDiff == diff.ModifiedNew {
-			if err = writer.WriteCombinedRow(ctx, oldRow.Row, newRow.Row, dArgs.diffMode); err != nil {
-				return err
-			}
-		} else {
-			if oldRow.Row != nil {
-				if err = writer.WriteRow(ctx, oldRow.Row, oldRow.RowDiff, oldRow.ColDiffs); err != nil {
-					return err
+// renameDetector pairs the rows removed from a table by a diff with the rows added to it, for --detect-renames.
+type renameDetector struct {
+	unionSch sql.Schema
+	matcher  *diff.RowLineageMatcher
+	// fromOrds and toOrds are the indexes in the rows of the union schema of the columns of the from and to schemas
+	fromOrds, toOrds []int
+	removed, added   []diff.RowDiff
+}
+
+func newRenameDetector(fromSch, toSch schema.Schema, unionSch sql.Schema, similarity float64) *renameDetector {
+	ords := func(sch schema.Schema) []int {
+		var ords []int
+		for _, col := range sch.GetAllCols().GetColumns() {
+			ords = append(ords, unionSch.IndexOfColName(col.Name))
+		}
+		return ords
+	}
+	return &renameDetector{
+		unionSch: unionSch,
+		matcher:  diff.NewRowLineageMatcher(fromSch, toSch, similarity),
+		fromOrds: ords(fromSch),
+		toOrds:   ords(toSch),
+	}
+}
+
+// write pairs the removed and added rows, and calls |writeRow| with the removed rows, as modified rows when paired,
+// followed by the added rows that weren't paired.
+func (rd *renameDetector) write(ctx *sql.Context, writeRow func(oldRow, newRow diff.RowDiff) error) error {
+	project := func(rows []diff.RowDiff, ords []int) []sql.Row {
+		projected := make([]sql.Row, len(rows))
+		for i, r := range rows {
+			projected[i] = make(sql.Row, len(ords))
+			for j, ord := range ords {
+				if ord >= 0 {
+					projected[i][j] = r.Row[ord]
 				}
 			}
-			if newRow.Row != nil {
-				if err = writer.WriteRow(ctx, newRow.Row, newRow.RowDiff, newRow.ColDiffs); err != nil {
-					return err
-				}
+		}
+		return projected
+	}
+	pairs, err := rd.matcher.Match(project(rd.removed, rd.fromOrds), project(rd.added, rd.toOrds))
+	if err != nil {
+		return err
+	}
+
+	pairedTo := make(map[int]int, len(pairs))
+	for _, p := range pairs {
+		pairedTo[p.From] = p.To
+	}
+	addedPaired := make([]bool, len(rd.added))
+	for i, oldRow := range rd.removed {
+		j, ok := pairedTo[i]
+		if !ok {
+			if err = writeRow(oldRow, diff.RowDiff{}); err != nil {
+				return err
+			}
+			continue
+		}
+		addedPaired[j] = true
+		from, to, err := diff.ModifiedRowDiffs(rd.unionSch, oldRow.Row, rd.added[j].Row)
+		if err != nil {
+			return err
+		}
+		if err = writeRow(from, to); err != nil {
+			return err
+		}
+	}
+	for j, newRow := range rd.added {
+		if !addedPaired[j] {
+			if err = writeRow(diff.RowDiff{}, newRow); err != nil {
+				return err
 			}
 		}
 	}
+	return nil
 }
 
 // getModifiedCols returns a set of the names of columns that are modified, as well as the name of the primary key for a particular row iterator and schema.
@@ -28,6 +28,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/arrow"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/json"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/parquet"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/csv"
@@ -44,6 +45,7 @@ const (
 	FormatNull // used for profiling
 	FormatVertical
 	FormatParquet
+	FormatArrow
 )
 
 type PrintSummaryBehavior byte
@@ -105,6 +107,12 @@ func prettyPrintResultsWithSummary(ctx *sql.Context, resultFormat PrintResultFor
 		if err != nil {
 			return err
 		}
+	case FormatArrow:
+		var err error
+		wr, err = arrow.NewArrowRowWriter(sqlSch, iohelp.NopWrCloser(cli.CliOut))
+		if err != nil {
+			return err
+		}
 	}
 
 	numRows, err := writeResultSet(ctx, rowIter, wr)
@@ -35,6 +35,7 @@ import (
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/cdc"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
@@ -47,6 +48,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/statsnoms"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/statspro"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/writer"
+	"github.com/dolthub/dolt/go/libraries/doltcore/webhook"
 	"github.com/dolthub/dolt/go/libraries/utils/config"
 	"github.com/dolthub/dolt/go/store/types"
 )
@@ -81,6 +83,14 @@ type SqlEngineConfig struct {
 	ClusterController       *cluster.Controller
 	BinlogReplicaController binlogreplication.BinlogReplicaController
 	EventSchedulerStatus    eventscheduler.SchedulerStatus
+	// Webhooks are the webhooks configured for the server, which are installed along with those of each
+	// database's local config if InstallWebhooks is set. Only sql-server installs webhooks.
+	Webhooks        []webhook.Config
+	InstallWebhooks bool
+	CDC             *cdc.Hub
+	// QueryParallelism is the number of workers which read a table scan
+	// concurrently. Table scans are serial unless it is greater than one.
+	QueryParallelism int
 }
 
 // NewSqlEngine returns a SqlEngine
@@ -99,8 +109,12 @@ func NewSqlEngine(
 		nbf = dbs[0].DbData().Ddb.Format()
 	}
 	parallelism := runtime.GOMAXPROCS(0)
+	scanParallelism := 1
 	if types.IsFormat_DOLT(nbf) {
+		// table scans are read in parallel by the kvexec builder, which keeps the
+		// order of their rows, rather than by exchange nodes
 		parallelism = 1
+		scanParallelism = config.QueryParallelism
 	}
 
 	bThreads := sql.NewBackgroundThreads()
@@ -108,6 +122,12 @@ func NewSqlEngine(
 	if err != nil {
 		return nil, err
 	}
+	if config.InstallWebhooks {
+		dsqle.ApplyWebhookConfig(ctx, bThreads, mrEnv, config.Webhooks, cli.CliOut, dbs...)
+	}
+	if config.CDC != nil {
+		dsqle.ApplyCDCHooks(ctx, config.CDC, mrEnv, cli.CliOut, dbs...)
+	}
 
 	config.ClusterController.ManageSystemVariables(sql.SystemVariables)
 
@@ -142,6 +162,13 @@ func NewSqlEngine(
 		pro.DropDatabaseHooks = append(pro.DropDatabaseHooks, config.ClusterController.DropDatabaseHook())
 		config.ClusterController.SetDropDatabase(pro.DropDatabase)
 	}
+	if config.InstallWebhooks {
+		pro.InitDatabaseHooks = append(pro.InitDatabaseHooks, dsqle.NewWebhookInitDatabaseHook(config.Webhooks, bThreads, cli.CliOut))
+	}
+	if config.CDC != nil {
+		pro.InitDatabaseHooks = append(pro.InitDatabaseHooks, dsqle.NewCDCInitDatabaseHook(config.CDC, cli.CliOut))
+		pro.DropDatabaseHooks = append(pro.DropDatabaseHooks, dsqle.NewCDCDropDatabaseHook(config.CDC))
+	}
 
 	sqlEngine := &SqlEngine{}
 
@@ -193,8 +220,9 @@ func NewSqlEngine(
 	statsPro := statspro.NewProvider(pro, statsnoms.NewNomsStatsFactory(mrEnv.RemoteDialProvider()))
 	engine.Analyzer.Catalog.StatsProvider = statsPro
 
-	engine.Analyzer.ExecBuilder = rowexec.NewOverrideBuilder(kvexec.Builder{})
-	sessFactory := doltSessionFactory(pro, statsPro, mrEnv.Config(), bcController, config.Autocommit)
+	engine.Analyzer.ExecBuilder = rowexec.NewOverrideBuilder(kvexec.Builder{ScanParallelism: scanParallelism})
+	engine.Parser = dsqle.NewMaterializedViewParser(dsqle.NewVectorIndexParser(dsqle.NewHistoryWindowParser(engine.Parser)))
+	sessFactory := doltSessionFactory(engine, pro, statsPro, mrEnv.Config(), bcController, config.Autocommit)
 	sqlEngine.provider = pro
 	sqlEngine.contextFactory = sqlContextFactory()
 	sqlEngine.dsessFactory = sessFactory
@@ -312,6 +340,17 @@ func (se *SqlEngine) Query(ctx *sql.Context, query string) (sql.Schema, sql.RowI
 }
 
 func (se *SqlEngine) QueryWithBindings(ctx *sql.Context, query string, parsed sqlparser.Statement, bindings map[string]sqlparser.Expr, qFlags *sql.QueryFlags) (sql.Schema, sql.RowIter, *sql.QueryFlags, error) {
+	// Statements parsed by the caller didn't go through the engine's parser, which rewrites history windows and
+	// vector index creation
+	if parsed != nil {
+		if err := dsqle.RewriteHistoryWindows(parsed); err != nil {
+			return nil, nil, nil, err
+		}
+		var err error
+		if parsed, err = dsqle.RewriteVectorIndexStatement(parsed); err != nil {
+			return nil, nil, nil, err
+		}
+	}
 	return se.engine.QueryWithBindings(ctx, query, parsed, bindings, qFlags)
 }
 
@@ -418,13 +457,14 @@ func sqlContextFactory() contextFactory {
 	}
 }
 
-// doltSessionFactory returns a sessionFactory that creates a new DoltSession
-func doltSessionFactory(pro *dsqle.DoltDatabaseProvider, statsPro sql.StatsProvider, config config.ReadWriteConfig, bc *branch_control.Controller, autocommit bool) sessionFactory {
+// doltSessionFactory returns a sessionFactory that creates a new DoltSession for |engine|
+func doltSessionFactory(engine *gms.Engine, pro *dsqle.DoltDatabaseProvider, statsPro sql.StatsProvider, config config.ReadWriteConfig, bc *branch_control.Controller, autocommit bool) sessionFactory {
 	return func(mysqlSess *sql.BaseSession, provider sql.DatabaseProvider) (*dsess.DoltSession, error) {
 		doltSession, err := dsess.NewDoltSession(mysqlSess, pro, config, bc, statsPro, writer.NewWriteSession)
 		if err != nil {
 			return nil, err
 		}
+		doltSession.SetQueryRunner(engine)
 
 		// nil ctx is actually fine in this context, not used in setting a session variable. Creating a new context isn't
 		// free, and would be throwaway work, since we need to create a session before creating a sql.Context for user work.
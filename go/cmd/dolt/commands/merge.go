@@ -477,8 +477,8 @@ func calculateMergeStats(queryist cli.Queryist, sqlCtx *sql.Context, mergeStats
 	var allUnmodified = true
 	// get table operations
 	for _, summary := range diffSummaries {
-		// We want to ignore all statistics for Full-Text tables
-		if doltdb.IsFullTextTable(summary.TableName.Name) {
+		// We want to ignore all statistics for Full-Text and vector index tables
+		if doltdb.IsFullTextTable(summary.TableName.Name) || doltdb.IsVectorIndexTable(summary.TableName.Name) {
 			continue
 		}
 		// Ignore stats for database collation changes
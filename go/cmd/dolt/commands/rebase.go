@@ -23,6 +23,8 @@ import (
 	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/gocraft/dbr/v2"
+	"github.com/gocraft/dbr/v2/dialect"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
@@ -47,6 +49,12 @@ the same as Git and Dolt's "two dot log" syntax, or |upstreamBranch|..|currentBr
 Rebasing is useful to clean and organize your commit history, especially before merging a feature branch back to a shared 
 branch. For example, you can drop commits that contain debugging or test changes, or squash or fixup small commits into a 
 single commit, or reorder commits so that related changes are adjacent in the new commit history.
+
+The rebase can also be stopped along the way. An {{.EmphasisLeft}}edit{{.EmphasisRight}} step stops after its commit is replayed, so 
+that its data can be changed; changes staged when the rebase is continued are amended into the commit. A 
+{{.EmphasisLeft}}break{{.EmphasisRight}} step stops the rebase at that point in the plan. An {{.EmphasisLeft}}exec{{.EmphasisRight}} step runs a 
+SQL statement, such as a validation query, after the previous commit is replayed, and aborts the rebase if the 
+statement fails. Use {{.EmphasisLeft}}dolt rebase --continue{{.EmphasisRight}} to resume a stopped rebase.
 `,
 	Synopsis: []string{
 		`(-i | --interactive) [--empty=drop|keep] {{.LessThan}}upstream{{.GreaterThan}}`,
@@ -114,6 +122,10 @@ func (cmd RebaseCmd) Exec(ctx context.Context, commandStr string, args []string,
 
 	rows, err := GetRowsForSql(queryist, sqlCtx, query)
 	if err != nil {
+		// A failed rebase step may have aborted the rebase and switched back to the branch being rebased
+		if checkoutErr := syncCliBranchToSqlSessionBranch(sqlCtx, dEnv); checkoutErr != nil {
+			return HandleVErrAndExitCode(errhand.VerboseErrorFromError(checkoutErr), usage)
+		}
 		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
 	}
 
@@ -129,7 +141,8 @@ func (cmd RebaseCmd) Exec(ctx context.Context, commandStr string, args []string,
 	// ensure the branch being rebased is checked out in the CLI
 	message := rows[0][1].(string)
 	if strings.Contains(message, dprocedures.SuccessfulRebaseMessage) ||
-		strings.Contains(message, dprocedures.RebaseAbortedMessage) {
+		strings.Contains(message, dprocedures.RebaseAbortedMessage) ||
+		strings.Contains(message, dprocedures.RebaseStoppedMessage) {
 		cli.Println(message)
 		if err = syncCliBranchToSqlSessionBranch(sqlCtx, dEnv); err != nil {
 			return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
@@ -201,7 +214,16 @@ func (cmd RebaseCmd) Exec(ctx context.Context, commandStr string, args []string,
 		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(errors.New("error: "+rows[0][1].(string))), usage)
 	}
 
-	cli.Println(rows[0][1].(string))
+	// If the rebase stopped at an edit or break step, check out the rebase working branch, so that
+	// changes can be made before the rebase is continued
+	message = rows[0][1].(string)
+	if strings.Contains(message, dprocedures.RebaseStoppedMessage) {
+		if err = syncCliBranchToSqlSessionBranch(sqlCtx, dEnv); err != nil {
+			return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+		}
+	}
+
+	cli.Println(message)
 	return 0
 }
 
@@ -244,7 +266,7 @@ func getRebasePlan(cliCtx cli.CliContext, sqlCtx *sql.Context, queryist cli.Quer
 func buildInitialRebaseMsg(sqlCtx *sql.Context, queryist cli.Queryist, rebaseBranch, currentBranch string) (string, error) {
 	var buffer bytes.Buffer
 
-	rows, err := GetRowsForSql(queryist, sqlCtx, "SELECT action, commit_hash, commit_message FROM dolt_rebase ORDER BY rebase_order")
+	rows, err := GetRowsForSql(queryist, sqlCtx, "SELECT action, commit_hash, commit_message, exec_statement FROM dolt_rebase ORDER BY rebase_order")
 	if err != nil {
 		return "", err
 	}
@@ -257,7 +279,15 @@ func buildInitialRebaseMsg(sqlCtx *sql.Context, queryist cli.Queryist, rebaseBra
 		}
 		commitHash := row[1].(string)
 		commitMessage := row[2].(string)
-		buffer.WriteString(fmt.Sprintf("%s %s %s\n", action, commitHash, commitMessage))
+		switch action {
+		case rebase.RebaseActionBreak:
+			buffer.WriteString(action + "\n")
+		case rebase.RebaseActionExec:
+			execStatement, _ := row[3].(string)
+			buffer.WriteString(fmt.Sprintf("%s %s\n", action, execStatement))
+		default:
+			buffer.WriteString(fmt.Sprintf("%s %s %s\n", action, commitHash, commitMessage))
+		}
 	}
 	buffer.WriteString("\n")
 
@@ -279,6 +309,9 @@ func buildInitialRebaseMsg(sqlCtx *sql.Context, queryist cli.Queryist, rebaseBra
 	buffer.WriteString("# r, reword <commit> = use commit, but edit the commit message\n")
 	buffer.WriteString("# s, squash <commit> = use commit, but meld into previous commit\n")
 	buffer.WriteString("# f, fixup <commit> = like \"squash\", but discard this commit's message\n")
+	buffer.WriteString("# e, edit <commit> = use commit, but stop for amending\n")
+	buffer.WriteString("# x, exec <statement> = run a SQL statement, aborting the rebase if it fails\n")
+	buffer.WriteString("# b, break = stop here (continue rebase later with 'dolt rebase --continue')\n")
 	buffer.WriteString("# These lines can be re-ordered; they are executed from top to bottom.\n")
 	buffer.WriteString("#\n")
 	buffer.WriteString("# If you remove a line here THAT COMMIT WILL BE LOST.\n")
@@ -301,21 +334,51 @@ func getRebaseAction(col interface{}) (string, bool) {
 	}
 }
 
+// rebaseActionAbbreviations maps the abbreviated rebase actions accepted in the rebase plan editor to their actions.
+var rebaseActionAbbreviations = map[string]string{
+	"p": rebase.RebaseActionPick,
+	"d": rebase.RebaseActionDrop,
+	"r": rebase.RebaseActionReword,
+	"s": rebase.RebaseActionSquash,
+	"f": rebase.RebaseActionFixup,
+	"e": rebase.RebaseActionEdit,
+	"x": rebase.RebaseActionExec,
+	"b": rebase.RebaseActionBreak,
+}
+
 // parseRebaseMessage parses the rebase message from the editor and adds all uncommented out lines as steps in the rebase plan.
 func parseRebaseMessage(rebaseMsg string) (*rebase.RebasePlan, error) {
 	plan := &rebase.RebasePlan{}
 	splitMsg := strings.Split(rebaseMsg, "\n")
 	for i, line := range splitMsg {
 		if !strings.HasPrefix(line, "#") && strings.TrimSpace(line) != "" {
-			rebaseStepParts := strings.SplitN(line, " ", 3)
-			if len(rebaseStepParts) != 3 {
-				return nil, fmt.Errorf("invalid line %d: %s", i, line)
+			action, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+			if fullAction, ok := rebaseActionAbbreviations[action]; ok {
+				action = fullAction
+			}
+
+			switch action {
+			case rebase.RebaseActionBreak:
+				plan.Steps = append(plan.Steps, rebase.RebasePlanStep{Action: action})
+			case rebase.RebaseActionExec:
+				if strings.TrimSpace(rest) == "" {
+					return nil, fmt.Errorf("invalid line %d: %s", i, line)
+				}
+				plan.Steps = append(plan.Steps, rebase.RebasePlanStep{
+					Action:        action,
+					ExecStatement: strings.TrimSpace(rest),
+				})
+			default:
+				rebaseStepParts := strings.SplitN(line, " ", 3)
+				if len(rebaseStepParts) != 3 {
+					return nil, fmt.Errorf("invalid line %d: %s", i, line)
+				}
+				plan.Steps = append(plan.Steps, rebase.RebasePlanStep{
+					Action:     action,
+					CommitHash: rebaseStepParts[1],
+					CommitMsg:  rebaseStepParts[2],
+				})
 			}
-			plan.Steps = append(plan.Steps, rebase.RebasePlanStep{
-				Action:     rebaseStepParts[0],
-				CommitHash: rebaseStepParts[1],
-				CommitMsg:  rebaseStepParts[2],
-			})
 		}
 	}
 
@@ -331,7 +394,16 @@ func insertRebasePlanIntoDoltRebaseTable(plan *rebase.RebasePlan, sqlCtx *sql.Co
 	}
 
 	for i, step := range plan.Steps {
-		_, err := GetRowsForSql(queryist, sqlCtx, fmt.Sprintf("INSERT INTO dolt_rebase VALUES (%d, '%s', '%s', '%s')", i+1, step.Action, step.CommitHash, step.CommitMsg))
+		var execStatement interface{}
+		if step.ExecStatement != "" {
+			execStatement = step.ExecStatement
+		}
+		query, err := dbr.InterpolateForDialect("INSERT INTO dolt_rebase VALUES (?, ?, ?, ?, ?)",
+			[]interface{}{i + 1, step.Action, step.CommitHash, step.CommitMsg, execStatement}, dialect.MySQL)
+		if err != nil {
+			return err
+		}
+		_, err = GetRowsForSql(queryist, sqlCtx, query)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,126 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+var rerereDocs = cli.CommandDocumentationContent{
+	ShortDesc: "List or forget recorded conflict resolutions",
+	LongDesc: `Dolt records how each row conflict is resolved, whether with {{.EmphasisLeft}}dolt conflicts resolve{{.EmphasisRight}}, {{.EmphasisLeft}}dolt_conflicts_resolve(){{.EmphasisRight}}, or by editing a table and deleting the conflict from its {{.EmphasisLeft}}dolt_conflicts_<table>{{.EmphasisRight}} table. When a later merge, cherry-pick or rebase produces an identical conflict, meaning the same row has the same values in the merge base and on each side of the merge, the recorded resolution is applied instead of reporting the conflict again.
+
+Resolutions are stored in the repository, outside of its commit history. Conflicts of keyless tables are not recorded.
+
+{{.EmphasisLeft}}list{{.EmphasisRight}}
+Lists the recorded resolutions. This is the default when no subcommand is given. A resolution that deleted the row has no resolved row.
+
+{{.EmphasisLeft}}forget (--all | <id>...){{.EmphasisRight}}
+Forgets the resolutions with the given ids, or every recorded resolution, so that the conflicts are reported again by later merges.`,
+	Synopsis: []string{
+		`[list]`,
+		`forget (--all | {{.LessThan}}id{{.GreaterThan}}...)`,
+	},
+}
+
+type RerereCmd struct{}
+
+var _ cli.Command = RerereCmd{}
+
+// Name returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd RerereCmd) Name() string {
+	return "rerere"
+}
+
+// Description returns a description of the command
+func (cmd RerereCmd) Description() string {
+	return rerereDocs.ShortDesc
+}
+
+// EventType returns the type of the event to log
+func (cmd RerereCmd) EventType() eventsapi.ClientEventType {
+	return eventsapi.ClientEventType_TYPE_UNSPECIFIED
+}
+
+func (cmd RerereCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(rerereDocs, ap)
+}
+
+func (cmd RerereCmd) ArgParser() *argparser.ArgParser {
+	return cli.CreateRerereArgParser()
+}
+
+// Exec executes the command
+func (cmd RerereCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, rerereDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if closeFunc != nil {
+		defer closeFunc()
+	}
+
+	procArgs := apr.Args
+	if len(procArgs) == 0 {
+		procArgs = []string{"list"}
+	}
+	if apr.Contains(cli.AllFlag) {
+		procArgs = append(procArgs, "--"+cli.AllFlag)
+	}
+	query, err := interpolateStoredProcedureCall("DOLT_RERERE", procArgs)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	sch, rowIter, _, err := queryist.Query(sqlCtx, query)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	rows, err := sql.RowIterToRows(sqlCtx, rowIter)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	if apr.NArg() > 0 && apr.Arg(0) == "forget" {
+		for _, row := range rows {
+			cli.Printf("Forgot resolution %s of %s (%s)\n", row[0], row[1], row[2])
+		}
+		return 0
+	}
+
+	if len(rows) == 0 {
+		cli.Println("No recorded resolutions")
+		return 0
+	}
+	err = engine.PrettyPrintResults(sqlCtx, engine.FormatTabular, sch, sql.RowsToRowIter(rows...))
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("Error printing recorded resolutions").AddCause(err).Build(), usage)
+	}
+	return 0
+}
@@ -0,0 +1,337 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/auditlog"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+// privilegeKeywords are the words, one of which must appear in a statement that changes privileges. They let most
+// statements skip being parsed a second time to find out whether they change privileges.
+var privilegeKeywords = []string{"grant", "revoke", "user", "role", dtables.AccessTableName, dtables.NamespaceTableName, dtables.ProtectionTableName}
+
+// auditHandler is a mysql.Handler that records connection events and statements in an audit log, and delegates to
+// the handler it wraps.
+type auditHandler struct {
+	mysql.Handler
+	log *auditlog.Log
+
+	mu       sync.Mutex
+	sessions map[uint32]sql.Session
+	// connected holds the connections whose connect event was recorded, so their first ComInitDB isn't recorded again
+	connected map[uint32]struct{}
+}
+
+var _ mysql.Handler = (*auditHandler)(nil)
+var _ mysql.BinlogReplicaHandler = (*auditHandler)(nil)
+
+func newAuditHandler(h mysql.Handler, log *auditlog.Log) *auditHandler {
+	return &auditHandler{
+		Handler:   h,
+		log:       log,
+		sessions:  make(map[uint32]sql.Session),
+		connected: make(map[uint32]struct{}),
+	}
+}
+
+// auditLogConfig converts the audit log config of the server to the config used to open the log.
+func auditLogConfig(cfg servercfg.AuditLogConfig, cfgDir string) auditlog.Config {
+	path := cfg.Path()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfgDir, path)
+	}
+	events := make([]auditlog.Event, len(cfg.Events()))
+	for i, event := range cfg.Events() {
+		events[i] = auditlog.Event(strings.ToLower(event))
+	}
+	return auditlog.Config{
+		Path:       path,
+		Events:     events,
+		Users:      cfg.Users(),
+		Databases:  cfg.Databases(),
+		MaxSize:    int64(cfg.MaxSizeMB()) * 1024 * 1024,
+		MaxAge:     time.Duration(cfg.MaxAgeHours()) * time.Hour,
+		MaxBackups: cfg.MaxBackups(),
+	}
+}
+
+// sessionBuilder wraps |sb| to track the session of each connection, which records are filled in from.
+func (h *auditHandler) sessionBuilder(sb server.SessionBuilder) server.SessionBuilder {
+	return func(ctx context.Context, conn *mysql.Conn, addr string) (sql.Session, error) {
+		sess, err := sb(ctx, conn, addr)
+		if err != nil {
+			return nil, err
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.sessions[conn.ConnectionID] = sess
+		return sess, nil
+	}
+}
+
+func (h *auditHandler) session(c *mysql.Conn) sql.Session {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sessions[c.ConnectionID]
+}
+
+// ComInitDB implements mysql.Handler. The first call for a connection is made once it's authenticated, and is recorded
+// as its connect event.
+func (h *auditHandler) ComInitDB(c *mysql.Conn, schemaName string) error {
+	err := h.Handler.ComInitDB(c, schemaName)
+
+	h.mu.Lock()
+	_, ok := h.connected[c.ConnectionID]
+	h.connected[c.ConnectionID] = struct{}{}
+	h.mu.Unlock()
+	if !ok {
+		rec := h.newRecord(c, auditlog.ConnectEvent, time.Now())
+		h.write(rec, err)
+	}
+	return err
+}
+
+// ConnectionAborted implements mysql.Handler. A connection that couldn't be established is recorded as a connect
+// event with the reason as its error.
+func (h *auditHandler) ConnectionAborted(c *mysql.Conn, reason string) error {
+	rec := h.newRecord(c, auditlog.ConnectEvent, time.Now())
+	h.write(rec, fmt.Errorf("%s", reason))
+	return h.Handler.ConnectionAborted(c, reason)
+}
+
+// ConnectionClosed implements mysql.Handler.
+func (h *auditHandler) ConnectionClosed(c *mysql.Conn) {
+	h.mu.Lock()
+	_, ok := h.connected[c.ConnectionID]
+	h.mu.Unlock()
+	if ok {
+		rec := h.newRecord(c, auditlog.DisconnectEvent, time.Now())
+		h.write(rec, nil)
+	}
+
+	h.Handler.ConnectionClosed(c)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, c.ConnectionID)
+	delete(h.connected, c.ConnectionID)
+}
+
+// ComQuery implements mysql.Handler.
+func (h *auditHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	stmt := h.startStatement(ctx, c, query)
+	err := h.Handler.ComQuery(ctx, c, query, stmt.countResults(callback))
+	h.finishStatement(ctx, c, stmt, err)
+	return err
+}
+
+// ComMultiQuery implements mysql.Handler. Each statement of the query is recorded separately.
+func (h *auditHandler) ComMultiQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) (string, error) {
+	stmt := h.startStatement(ctx, c, query)
+	remainder, err := h.Handler.ComMultiQuery(ctx, c, query, stmt.countResults(callback))
+	if err == nil && len(remainder) < len(query) {
+		stmt.rec.Query = auditlog.RedactQuery(strings.TrimSpace(strings.TrimSuffix(query, remainder)))
+	}
+	h.finishStatement(ctx, c, stmt, err)
+	return remainder, err
+}
+
+// ComStmtExecute implements mysql.Handler.
+func (h *auditHandler) ComStmtExecute(ctx context.Context, c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	stmt := h.startStatement(ctx, c, prepare.PrepareStmt)
+	err := h.Handler.ComStmtExecute(ctx, c, prepare, func(res *sqltypes.Result) error {
+		stmt.count(res)
+		return callback(res)
+	})
+	h.finishStatement(ctx, c, stmt, err)
+	return err
+}
+
+// ComRegisterReplica implements mysql.BinlogReplicaHandler.
+func (h *auditHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
+	replicaHandler, ok := h.Handler.(mysql.BinlogReplicaHandler)
+	if !ok {
+		return fmt.Errorf("binlog replication is not supported")
+	}
+	return replicaHandler.ComRegisterReplica(c, replicaHost, replicaPort, replicaUser, replicaPassword)
+}
+
+// ComBinlogDumpGTID implements mysql.BinlogReplicaHandler.
+func (h *auditHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet mysql.GTIDSet) error {
+	replicaHandler, ok := h.Handler.(mysql.BinlogReplicaHandler)
+	if !ok {
+		return fmt.Errorf("binlog replication is not supported")
+	}
+	return replicaHandler.ComBinlogDumpGTID(c, logFile, logPos, gtidSet)
+}
+
+// auditStatement is the record of a statement being run, and the head of its branch before it ran.
+type auditStatement struct {
+	rec    auditlog.Record
+	start  time.Time
+	dbName string
+	head   string
+}
+
+func (s *auditStatement) count(res *sqltypes.Result) {
+	if res != nil {
+		s.rec.RowsAffected += res.RowsAffected
+		s.rec.RowsReturned += uint64(len(res.Rows))
+	}
+}
+
+func (s *auditStatement) countResults(callback mysql.ResultSpoolFn) mysql.ResultSpoolFn {
+	return func(res *sqltypes.Result, more bool) error {
+		s.count(res)
+		return callback(res, more)
+	}
+}
+
+func (h *auditHandler) startStatement(ctx context.Context, c *mysql.Conn, query string) *auditStatement {
+	start := time.Now()
+	stmt := &auditStatement{rec: h.newRecord(c, auditlog.StatementEvent, start), start: start}
+	// passwords set by the statement aren't recorded, since the log is stored in plain text
+	stmt.rec.Query = auditlog.RedactQuery(query)
+	if sess := h.session(c); sess != nil {
+		stmt.dbName = sess.GetCurrentDatabase()
+		stmt.head = h.branchHead(ctx, sess, stmt.dbName, stmt.rec.Branch)
+	}
+	return stmt
+}
+
+func (h *auditHandler) finishStatement(ctx context.Context, c *mysql.Conn, stmt *auditStatement, err error) {
+	stmt.rec.DurationMillis = time.Since(stmt.start).Milliseconds()
+	if changesPrivileges(stmt.rec.Query) {
+		stmt.rec.Event = auditlog.PrivilegeEvent
+	}
+	if sess := h.session(c); sess != nil && stmt.head != "" {
+		if head := h.branchHead(ctx, sess, stmt.dbName, stmt.rec.Branch); head != stmt.head {
+			stmt.rec.CommitHash = head
+		}
+	}
+	h.write(stmt.rec, err)
+}
+
+// newRecord returns a record of |event| on |c|, filled in from the connection and its session.
+func (h *auditHandler) newRecord(c *mysql.Conn, event auditlog.Event, t time.Time) auditlog.Record {
+	rec := auditlog.Record{
+		Time:         t.UTC(),
+		Event:        event,
+		ConnectionID: c.ConnectionID,
+		User:         c.User,
+	}
+	if addr := c.RemoteAddr(); addr != nil {
+		rec.Host = addr.String()
+		if host, _, err := net.SplitHostPort(rec.Host); err == nil {
+			rec.Host = host
+		}
+	}
+	if sess := h.session(c); sess != nil {
+		rec.Database, _ = dsess.SplitRevisionDbName(sess.GetCurrentDatabase())
+		if doltSess, ok := sess.(*dsess.DoltSession); ok {
+			rec.Branch, _ = doltSess.GetBranch()
+		}
+	}
+	return rec
+}
+
+// branchHead returns the hash of the head commit of |branch| in the database |dbName|, or "" if it can't be resolved.
+// The head is read from the database rather than the session, so that commits made by other sessions since the last
+// transaction of this session aren't attributed to the statement.
+func (h *auditHandler) branchHead(ctx context.Context, sess sql.Session, dbName, branch string) string {
+	doltSess, ok := sess.(*dsess.DoltSession)
+	if !ok || dbName == "" || branch == "" {
+		return ""
+	}
+	ddb, ok := doltSess.GetDoltDB(sql.NewContext(ctx, sql.WithSession(sess)), dbName)
+	if !ok {
+		return ""
+	}
+	head, err := ddb.GetHashForRefStr(ctx, ref.NewBranchRef(branch).String())
+	if err != nil {
+		return ""
+	}
+	return head.String()
+}
+
+func (h *auditHandler) write(rec auditlog.Record, err error) {
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if err = h.log.Write(rec); err != nil {
+		logrus.Errorf("unable to write to the audit log: %s", err.Error())
+	}
+}
+
+// changesPrivileges returns whether |query| grants or revokes privileges, manages users or roles, or writes to the
+// branch control tables.
+func changesPrivileges(query string) bool {
+	lower := strings.ToLower(query)
+	found := false
+	for _, keyword := range privilegeKeywords {
+		if strings.Contains(lower, keyword) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return false
+	}
+	switch stmt.(type) {
+	case *sqlparser.GrantPrivilege, *sqlparser.GrantRole, *sqlparser.GrantProxy,
+		*sqlparser.RevokePrivilege, *sqlparser.RevokeAllPrivileges, *sqlparser.RevokeRole, *sqlparser.RevokeProxy,
+		*sqlparser.CreateUser, *sqlparser.RenameUser, *sqlparser.DropUser, *sqlparser.CreateRole, *sqlparser.DropRole:
+		return true
+	case *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete:
+		writesBranchControl := false
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if tableName, ok := node.(sqlparser.TableName); ok {
+				name := tableName.Name.String()
+				if strings.EqualFold(name, dtables.AccessTableName) || strings.EqualFold(name, dtables.NamespaceTableName) ||
+					strings.EqualFold(name, dtables.ProtectionTableName) {
+					writesBranchControl = true
+					return false, nil
+				}
+			}
+			return true, nil
+		}, stmt)
+		return writesBranchControl
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/utils/svcs"
+)
+
+func TestChangesPrivileges(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"select * from users", false},
+		{"grant select on *.* to bob", true},
+		{"REVOKE ALL PRIVILEGES, GRANT OPTION FROM bob", true},
+		{"create user bob identified by 'pass'", true},
+		{"drop role if exists reader", true},
+		{"insert into dolt_branch_control values ('%', 'main', 'bob', '%', 'write')", true},
+		{"delete from mydb.DOLT_BRANCH_NAMESPACE_CONTROL where user = 'bob'", true},
+		{"select * from dolt_branch_control", false},
+		{"insert into t select * from user_roles", false},
+		{"update t set role = 'admin'", false},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			assert.Equal(t, test.expected, changesPrivileges(test.query))
+		})
+	}
+}
+
+func TestServerAuditLog(t *testing.T) {
+	dEnv, err := sqle.CreateEnvWithSeedData()
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, dEnv.DoltDB.Close())
+	}()
+
+	cfgDir := t.TempDir()
+	serverConfig, err := servercfg.NewYamlConfig([]byte(fmt.Sprintf(`
+log_level: fatal
+listener:
+    port: 15304
+cfg_dir: %s
+audit_log:
+    users: [root]
+`, cfgDir)))
+	require.NoError(t, err)
+
+	sc := svcs.NewController()
+	defer sc.Stop()
+	go func() {
+		_, _ = Serve(context.Background(), "0.0.0", serverConfig, sc, dEnv)
+	}()
+	require.NoError(t, sc.WaitForStart())
+
+	db, err := sql.Open("mysql", servercfg.ConnectionString(serverConfig, "dolt"))
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	for _, query := range []string{
+		"create table audited (pk int primary key)",
+		"insert into audited values (1), (2)",
+		"select * from audited",
+		"call dolt_commit('-Am', 'add audited')",
+		"create user bob identified by 'bobs password'",
+		"grant select on *.* to bob",
+	} {
+		_, err = db.Exec(query)
+		require.NoError(t, err)
+	}
+	_, err = db.Exec("select * from not_a_table")
+	require.Error(t, err)
+
+	var head string
+	require.NoError(t, db.QueryRow("select hashof('HEAD')").Scan(&head))
+
+	rows, err := db.Query("select event, user, `database`, branch, query, commit_hash, rows_affected, rows_returned, error is not null from dolt_audit_log where event != 'connect' order by time")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	type auditRow struct {
+		event, user, database, branch, query string
+		commitHash                           sql.NullString
+		rowsAffected, rowsReturned           uint64
+		failed                               bool
+	}
+	var actual []auditRow
+	for rows.Next() {
+		var r auditRow
+		require.NoError(t, rows.Scan(&r.event, &r.user, &r.database, &r.branch, &r.query, &r.commitHash, &r.rowsAffected, &r.rowsReturned, &r.failed))
+		actual = append(actual, r)
+	}
+	require.NoError(t, rows.Err())
+
+	require.Len(t, actual, 8)
+	for _, r := range actual {
+		assert.Equal(t, "root", r.user)
+		assert.Equal(t, "dolt", r.database)
+		assert.Equal(t, "main", r.branch)
+	}
+	assert.Equal(t, []string{"statement", "statement", "statement", "statement", "privilege", "privilege", "statement", "statement"},
+		[]string{actual[0].event, actual[1].event, actual[2].event, actual[3].event, actual[4].event, actual[5].event, actual[6].event, actual[7].event})
+	assert.Equal(t, "insert into audited values (1), (2)", actual[1].query)
+	assert.Equal(t, uint64(2), actual[1].rowsAffected)
+	assert.Equal(t, uint64(2), actual[2].rowsReturned)
+	assert.False(t, actual[2].commitHash.Valid)
+	assert.Equal(t, head, actual[3].commitHash.String)
+	assert.True(t, actual[6].failed)
+	assert.Equal(t, "select hashof('HEAD')", actual[7].query)
+
+	// passwords are redacted, in the system table and in the log file
+	assert.Equal(t, "create user `bob`@`%` identified by '<secret>'", actual[4].query)
+	contents, err := os.ReadFile(filepath.Join(cfgDir, servercfg.DefaultAuditLogPath))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "create user")
+	assert.NotContains(t, string(contents), "bobs password")
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/utils/svcs"
+)
+
+// branchPruneCheckInterval is how often the branch prune service reads dolt_branch_prune_interval, so that changes
+// to it take effect while the server runs.
+const branchPruneCheckInterval = time.Second
+
+// branchPruneService is a service that enforces the retention policies of the dolt_branch_policies table of every
+// database of the server, every dolt_branch_prune_interval seconds. An interval of 0 disables it until the variable
+// is set again.
+type branchPruneService struct {
+	sqlEngine func() *engine.SqlEngine
+}
+
+var _ svcs.Service = &branchPruneService{}
+
+func (s *branchPruneService) Init(context.Context) error { return nil }
+
+func (s *branchPruneService) Stop() error { return nil }
+
+func (s *branchPruneService) Run(ctx context.Context) {
+	runBranchPrune(ctx, branchPruneCheckInterval, branchPruneInterval, s.prune)
+}
+
+// runBranchPrune calls |prune| once every |interval()| until |ctx| is done. |interval| is read again every |check|,
+// and a prune is due once the current interval has elapsed since the last one, or since pruning was enabled.
+func runBranchPrune(ctx context.Context, check time.Duration, interval func() time.Duration, prune func(context.Context)) {
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			i := interval()
+			if i == 0 {
+				// the next interval starts when pruning is enabled again
+				last = now
+				continue
+			}
+			if now.Sub(last) < i {
+				continue
+			}
+			prune(ctx)
+			last = time.Now()
+		}
+	}
+}
+
+// prune runs dolt_branch_prune() on every database of the server, logging the refs it deletes.
+func (s *branchPruneService) prune(ctx context.Context) {
+	sqlEngine := s.sqlEngine()
+	sqlCtx, err := sqlEngine.NewLocalContext(ctx)
+	if err != nil {
+		logrus.Errorf("unable to enforce branch policies: %v", err)
+		return
+	}
+
+	for _, db := range dsess.DSessFromSess(sqlCtx.Session).Provider().DoltDatabases() {
+		dbName := db.Name()
+		sqlCtx.SetCurrentDatabase(dbName)
+		_, iter, _, err := sqlEngine.Query(sqlCtx, "CALL dolt_branch_prune()")
+		if err != nil {
+			logrus.Errorf("unable to enforce branch policies of database %s: %v", dbName, err)
+			continue
+		}
+		rows, err := sql.RowIterToRows(sqlCtx, iter)
+		if err != nil {
+			logrus.Errorf("unable to enforce branch policies of database %s: %v", dbName, err)
+			continue
+		}
+		for _, row := range rows {
+			logrus.Infof("branch policy %s deleted %s %s of database %s (was %s)", row[3], row[0], row[1], dbName, row[2])
+		}
+	}
+}
+
+// branchPruneInterval returns the value of the dolt_branch_prune_interval system variable.
+func branchPruneInterval() time.Duration {
+	_, interval, ok := sql.SystemVariables.GetGlobal(dsess.DoltBranchPruneInterval)
+	if !ok {
+		return 0
+	}
+	interval64, _, err := types.Int64.Convert(interval)
+	if err != nil {
+		return 0
+	}
+	return time.Second * time.Duration(interval64.(int64))
+}
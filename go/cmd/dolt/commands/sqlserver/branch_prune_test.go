@@ -0,0 +1,91 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBranchPrune(t *testing.T) {
+	const check = time.Millisecond
+
+	start := func(interval *atomic.Int64) (prunes *atomic.Int64, stop func()) {
+		prunes = &atomic.Int64{}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			runBranchPrune(ctx, check, func() time.Duration {
+				return time.Duration(interval.Load())
+			}, func(context.Context) {
+				prunes.Add(1)
+			})
+		}()
+		return prunes, func() {
+			cancel()
+			<-done
+		}
+	}
+
+	t.Run("disabled at start and enabled later", func(t *testing.T) {
+		interval := &atomic.Int64{}
+		prunes, stop := start(interval)
+		defer stop()
+
+		time.Sleep(50 * check)
+		assert.Equal(t, int64(0), prunes.Load())
+
+		interval.Store(int64(5 * check))
+		require.Eventually(t, func() bool {
+			return prunes.Load() >= 2
+		}, 5*time.Second, check)
+	})
+
+	t.Run("disabled while running", func(t *testing.T) {
+		interval := &atomic.Int64{}
+		interval.Store(int64(5 * check))
+		prunes, stop := start(interval)
+		defer stop()
+
+		require.Eventually(t, func() bool {
+			return prunes.Load() >= 1
+		}, 5*time.Second, check)
+		interval.Store(0)
+		// a prune may have been running when the interval changed
+		time.Sleep(10 * check)
+		n := prunes.Load()
+		time.Sleep(50 * check)
+		assert.Equal(t, n, prunes.Load())
+	})
+
+	t.Run("shortened while running", func(t *testing.T) {
+		interval := &atomic.Int64{}
+		interval.Store(int64(time.Hour))
+		prunes, stop := start(interval)
+		defer stop()
+
+		time.Sleep(10 * check)
+		interval.Store(int64(5 * check))
+		require.Eventually(t, func() bool {
+			return prunes.Load() >= 1
+		}, 5*time.Second, check)
+	})
+}
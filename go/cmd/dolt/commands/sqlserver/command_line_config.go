@@ -236,7 +236,7 @@ func (cfg *commandLineServerConfig) MaxConnections() uint64 {
 	return cfg.maxConnections
 }
 
-// QueryParallelism returns the parallelism that should be used by the go-mysql-server analyzer
+// QueryParallelism returns the number of workers which read a table scan in parallel. Scans are serial unless it is greater than 1
 func (cfg *commandLineServerConfig) QueryParallelism() int {
 	return cfg.queryParallelism
 }
@@ -307,6 +307,22 @@ func (cfg *commandLineServerConfig) ClusterConfig() servercfg.ClusterConfig {
 	return nil
 }
 
+func (cfg *commandLineServerConfig) WebhookConfigs() []servercfg.WebhookConfig {
+	return nil
+}
+
+func (cfg *commandLineServerConfig) AuditLogConfig() servercfg.AuditLogConfig {
+	return nil
+}
+
+func (cfg *commandLineServerConfig) TracingConfig() servercfg.TracingConfig {
+	return nil
+}
+
+func (cfg *commandLineServerConfig) QueryHistoryConfig() servercfg.QueryHistoryConfig {
+	return nil
+}
+
 // PrivilegeFilePath returns the path to the file which contains all needed privilege information in the form of a
 // JSON string.
 func (cfg *commandLineServerConfig) PrivilegeFilePath() string {
@@ -0,0 +1,654 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gms "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	querypb "github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dprocedures"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// forwardedMaxRows is the maximum number of rows of a result read from the primary.
+const forwardedMaxRows = math.MaxInt32
+
+// forwardedMaxSets is the maximum number of variables set by a client which are set on its connections to the
+// primary. When a client sets more variables, the least recently set ones are no longer set on new connections.
+const forwardedMaxSets = 256
+
+// primaryLocator tells a forwardingHandler whether this server is a standby, and where the primary is. It's
+// implemented by cluster.Controller.
+type primaryLocator interface {
+	IsStandby() bool
+	PrimarySQLAddress(ctx context.Context) (string, error)
+}
+
+// forwardAsProcedure is called by a standby on each of its connections to the primary, before anything is forwarded
+// on it, with the user and host of the client the connection forwards statements for. It isn't a stored procedure:
+// the forwardingHandler of the primary handles it, only for connections of the forwarding user which haven't called
+// it yet.
+const forwardAsProcedure = "dolt_cluster_forward_as"
+
+// localProcedures are the stored procedures that a standby runs itself rather than forwarding: the read only Dolt
+// procedures, and the procedures which manage the role of the server in its cluster.
+var localProcedures = func() map[string]bool {
+	ret := map[string]bool{
+		"dolt_assume_cluster_role":           true,
+		"dolt_cluster_transition_to_standby": true,
+	}
+	for _, proc := range dprocedures.DoltProcedures {
+		if proc.ReadOnly {
+			ret[proc.Name] = true
+		}
+	}
+	return ret
+}()
+
+// forwardingHandler is a mysql.Handler for a cluster standby which runs the write transactions of its clients on the
+// primary, and delegates everything else to the handler it wraps. Statements which write, and every statement after
+// them until the end of their transaction, are forwarded over a connection to the primary which is opened for each
+// client connection. Other statements are run locally, after waiting until the standby replicated the head commits of
+// the databases the client last wrote to on the primary, so that every client reads the Dolt commits it wrote. Changes
+// to a working set are replicated as well, but the standby can't tell whether it is behind the working set a client
+// wrote, so clients which need to read them right away should make their transactions Dolt commits with
+// @@dolt_transaction_commit.
+//
+// A standby connects to the primary as the configured forwarding user, then makes the connection run its statements as
+// the client they're forwarded for with forwardAsProcedure, so that the primary checks the privileges, branch
+// permissions and row policies of the client. On the primary, the forwardingHandler handles forwardAsProcedure for
+// the connections of the forwarding user.
+type forwardingHandler struct {
+	mysql.Handler
+	engine      *gms.Engine
+	primary     primaryLocator
+	user        string
+	password    string
+	waitTimeout time.Duration
+
+	mu        sync.Mutex
+	sessions  map[uint32]sql.Session
+	forwarded map[uint32]*forwardedSession
+}
+
+var _ mysql.Handler = (*forwardingHandler)(nil)
+var _ mysql.BinlogReplicaHandler = (*forwardingHandler)(nil)
+
+func newForwardingHandler(h mysql.Handler, engine *gms.Engine, primary primaryLocator, cfg servercfg.ClusterWriteForwardingConfig) *forwardingHandler {
+	return &forwardingHandler{
+		Handler:     h,
+		engine:      engine,
+		primary:     primary,
+		user:        cfg.User(),
+		password:    cfg.Password(),
+		waitTimeout: time.Duration(cfg.WaitTimeoutMillis()) * time.Millisecond,
+		sessions:    make(map[uint32]sql.Session),
+		forwarded:   make(map[uint32]*forwardedSession),
+	}
+}
+
+// forwardedSession is the forwarding state of a client connection. A connection runs one statement at a time, so it
+// isn't accessed concurrently.
+type forwardedSession struct {
+	// conn is the connection to the primary. nil until a statement is forwarded.
+	conn *mysql.Conn
+	// database is the current database of |conn|.
+	database string
+	// inTx is set while a transaction is open on |conn|. Every statement is forwarded until it ends.
+	inTx bool
+	// forwardedAs is set on the primary once the connection of a standby called forwardAsProcedure.
+	forwardedAs bool
+	// sets are the variables set by the client, which are set on every new connection to the primary. They're
+	// ordered from least to most recently set, and hold at most forwardedMaxSets variables.
+	sets []forwardedSet
+	// written holds the head commit of each database the client wrote to on the primary, which reads wait for.
+	written map[string]hash.Hash
+}
+
+// forwardedSet is a variable set by the client.
+type forwardedSet struct {
+	// variable identifies the variable, with its scope
+	variable string
+	// query sets the variable to the value the client set it to
+	query string
+}
+
+// recordSet records that the client ran |query|, which sets |variable|, replacing the previous value of the variable.
+func (fs *forwardedSession) recordSet(variable, query string) {
+	for i, set := range fs.sets {
+		if set.variable == variable {
+			fs.sets = append(fs.sets[:i], fs.sets[i+1:]...)
+			break
+		}
+	}
+	fs.sets = append(fs.sets, forwardedSet{variable: variable, query: query})
+	if len(fs.sets) > forwardedMaxSets {
+		logrus.Debugf("client set more than %d variables, %s is no longer set on new connections to the primary", forwardedMaxSets, fs.sets[0].variable)
+		fs.sets = fs.sets[1:]
+	}
+}
+
+func (fs *forwardedSession) close() {
+	if fs.conn != nil {
+		fs.conn.Close()
+		fs.conn = nil
+	}
+	fs.database = ""
+	fs.inTx = false
+}
+
+// sessionBuilder wraps |sb| to track the session of each connection, which statements are routed with.
+func (h *forwardingHandler) sessionBuilder(sb server.SessionBuilder) server.SessionBuilder {
+	return func(ctx context.Context, conn *mysql.Conn, addr string) (sql.Session, error) {
+		sess, err := sb(ctx, conn, addr)
+		if err != nil {
+			return nil, err
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.sessions[conn.ConnectionID] = sess
+		h.forwarded[conn.ConnectionID] = &forwardedSession{written: make(map[string]hash.Hash)}
+		return sess, nil
+	}
+}
+
+func (h *forwardingHandler) session(c *mysql.Conn) (sql.Session, *forwardedSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sessions[c.ConnectionID], h.forwarded[c.ConnectionID]
+}
+
+// ConnectionClosed implements mysql.Handler.
+func (h *forwardingHandler) ConnectionClosed(c *mysql.Conn) {
+	h.Handler.ConnectionClosed(c)
+
+	h.mu.Lock()
+	fs := h.forwarded[c.ConnectionID]
+	delete(h.sessions, c.ConnectionID)
+	delete(h.forwarded, c.ConnectionID)
+	h.mu.Unlock()
+	if fs != nil {
+		fs.close()
+	}
+}
+
+// ComResetConnection implements mysql.Handler.
+func (h *forwardingHandler) ComResetConnection(c *mysql.Conn) error {
+	if _, fs := h.session(c); fs != nil {
+		fs.close()
+		fs.sets = nil
+		fs.written = make(map[string]hash.Hash)
+		fs.forwardedAs = false
+	}
+	return h.Handler.ComResetConnection(c)
+}
+
+// ComQuery implements mysql.Handler.
+func (h *forwardingHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	res, err := h.run(ctx, c, query, func() error {
+		return h.Handler.ComQuery(ctx, c, query, callback)
+	})
+	if err != nil || res == nil {
+		return err
+	}
+	return callback(res, false)
+}
+
+// ComMultiQuery implements mysql.Handler.
+func (h *forwardingHandler) ComMultiQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) (string, error) {
+	first, remainder, err := sqlparser.SplitStatement(query)
+	if err != nil {
+		return h.Handler.ComMultiQuery(ctx, c, query, callback)
+	}
+	var localRemainder string
+	res, err := h.run(ctx, c, first, func() (err error) {
+		localRemainder, err = h.Handler.ComMultiQuery(ctx, c, query, callback)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return localRemainder, nil
+	}
+	return remainder, callback(res, remainder != "")
+}
+
+// ComPrepare implements mysql.Handler. Statements are prepared locally, but a standby may not be able to prepare the
+// statements it forwards.
+func (h *forwardingHandler) ComPrepare(ctx context.Context, c *mysql.Conn, query string, prepare *mysql.PrepareData) ([]*querypb.Field, error) {
+	fields, err := h.Handler.ComPrepare(ctx, c, query, prepare)
+	if err != nil && h.primary.IsStandby() {
+		if stmt, perr := sqlparser.Parse(query); perr == nil && isForwardedWrite(stmt) {
+			return nil, nil
+		}
+	}
+	return fields, err
+}
+
+// ComStmtExecute implements mysql.Handler. Forwarded statements are sent to the primary as text, with their
+// parameters bound.
+func (h *forwardingHandler) ComStmtExecute(ctx context.Context, c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	query := prepare.PrepareStmt
+	if stmt, err := sqlparser.Parse(prepare.PrepareStmt); err == nil && len(prepare.BindVars) > 0 {
+		if bound, err := sqlparser.NewParsedQuery(stmt).GenerateQuery(prepare.BindVars, nil); err == nil {
+			query = bound
+		}
+	}
+	res, err := h.run(ctx, c, query, func() error {
+		return h.Handler.ComStmtExecute(ctx, c, prepare, callback)
+	})
+	if err != nil || res == nil {
+		return err
+	}
+	return callback(res)
+}
+
+// ComRegisterReplica implements mysql.BinlogReplicaHandler.
+func (h *forwardingHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
+	replicaHandler, ok := h.Handler.(mysql.BinlogReplicaHandler)
+	if !ok {
+		return fmt.Errorf("binlog replication is not supported")
+	}
+	return replicaHandler.ComRegisterReplica(c, replicaHost, replicaPort, replicaUser, replicaPassword)
+}
+
+// ComBinlogDumpGTID implements mysql.BinlogReplicaHandler.
+func (h *forwardingHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet mysql.GTIDSet) error {
+	replicaHandler, ok := h.Handler.(mysql.BinlogReplicaHandler)
+	if !ok {
+		return fmt.Errorf("binlog replication is not supported")
+	}
+	return replicaHandler.ComBinlogDumpGTID(c, logFile, logPos, gtidSet)
+}
+
+// run routes the statement |query| of |c|. It returns the result of the statement if it was forwarded to the
+// primary, and otherwise runs it with |local| and returns a nil result.
+func (h *forwardingHandler) run(ctx context.Context, c *mysql.Conn, query string, local func() error) (*sqltypes.Result, error) {
+	sess, fs := h.session(c)
+	if sess == nil || fs == nil {
+		return nil, local()
+	}
+	if !h.primary.IsStandby() {
+		if call := parseForwardAs(query); call != nil {
+			return h.forwardAs(c, sess, fs, call)
+		}
+		return nil, local()
+	}
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, local()
+	}
+	if call, ok := stmt.(*sqlparser.Call); ok && isForwardAs(call) {
+		return nil, fmt.Errorf("%s may only be called by a cluster standby", forwardAsProcedure)
+	}
+	sqlCtx := sql.NewContext(ctx, sql.WithSession(sess))
+
+	switch stmt.(type) {
+	case *sqlparser.Set:
+		// Session state is kept both locally and on the primary.
+		if err := local(); err != nil {
+			return nil, err
+		}
+		recordSets(sqlCtx, fs, stmt.(*sqlparser.Set))
+		if fs.conn != nil {
+			if _, err := h.exec(ctx, fs, query); err != nil {
+				logrus.Debugf("unable to forward %s to the primary: %s", query, err.Error())
+			}
+		}
+		return nil, nil
+	case *sqlparser.Commit, *sqlparser.Rollback:
+		if !fs.inTx {
+			return nil, h.waitThenRun(sqlCtx, fs, local)
+		}
+		res, err := h.forward(ctx, sess, fs, query)
+		fs.inTx = false
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := stmt.(*sqlparser.Commit); ok {
+			h.recordWrite(ctx, fs)
+		}
+		// End the transaction the client started locally as well. It didn't write anything.
+		if err := h.Handler.ComQuery(ctx, c, "rollback", func(*sqltypes.Result, bool) error { return nil }); err != nil {
+			logrus.Debugf("unable to end the local transaction of a forwarded transaction: %s", err.Error())
+		}
+		return res, nil
+	}
+
+	if fs.inTx {
+		return h.forward(ctx, sess, fs, query)
+	}
+	if !isForwardedWrite(stmt) {
+		return nil, h.waitThenRun(sqlCtx, fs, local)
+	}
+
+	autocommit, err := plan.IsSessionAutocommit(sqlCtx)
+	if err != nil {
+		return nil, err
+	}
+	if sess.GetIgnoreAutoCommit() || !autocommit {
+		// The statement opens a transaction on the primary, which the following statements of the client join.
+		if _, err := h.forward(ctx, sess, fs, "start transaction"); err != nil {
+			return nil, err
+		}
+		fs.inTx = true
+		return h.forward(ctx, sess, fs, query)
+	}
+	res, err := h.forward(ctx, sess, fs, query)
+	if err != nil {
+		return nil, err
+	}
+	h.recordWrite(ctx, fs)
+	return res, nil
+}
+
+// waitThenRun waits until this server replicated the commits written by the client on the primary, then runs a
+// statement with |local|.
+func (h *forwardingHandler) waitThenRun(ctx *sql.Context, fs *forwardedSession, local func() error) error {
+	if err := h.waitForWrites(ctx, fs); err != nil {
+		return err
+	}
+	return local()
+}
+
+// waitForWrites waits until this server replicated the commits written by the client on the primary. A commit which
+// wasn't replicated before the timeout returns an error, and isn't waited for again.
+func (h *forwardingHandler) waitForWrites(ctx *sql.Context, fs *forwardedSession) error {
+	for db, commit := range fs.written {
+		err := dprocedures.WaitForCommit(ctx, db, commit, h.waitTimeout)
+		delete(fs.written, db)
+		if err != nil && !sql.ErrDatabaseNotFound.Is(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// forward runs |query| on the primary, in the database the client is using.
+func (h *forwardingHandler) forward(ctx context.Context, sess sql.Session, fs *forwardedSession, query string) (*sqltypes.Result, error) {
+	if err := h.connect(ctx, sess, fs); err != nil {
+		return nil, err
+	}
+	if db := forwardedDatabase(sess); db != "" && db != fs.database {
+		if _, err := h.exec(ctx, fs, "use `"+strings.ReplaceAll(db, "`", "``")+"`"); err != nil {
+			return nil, err
+		}
+		fs.database = db
+	}
+	return h.exec(ctx, fs, query)
+}
+
+// connect opens the connection of |fs| to the current primary, if it isn't open, and makes it run statements as the
+// client of |sess|.
+func (h *forwardingHandler) connect(ctx context.Context, sess sql.Session, fs *forwardedSession) error {
+	if fs.conn != nil {
+		return nil
+	}
+	addr, err := h.primary.PrimarySQLAddress(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to forward the write to the primary: %w", err)
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return err
+	}
+	conn, err := mysql.Connect(ctx, &mysql.ConnParams{
+		Host:  host,
+		Port:  port,
+		Uname: h.user,
+		Pass:  h.password,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to forward the write to the primary at %s: %w", addr, err)
+	}
+	fs.conn = conn
+	if _, err := h.exec(ctx, fs, forwardAsQuery(sess.Client())); err != nil {
+		fs.close()
+		return fmt.Errorf("unable to forward the write to the primary at %s: %w", addr, err)
+	}
+	for _, set := range fs.sets {
+		if _, err := h.exec(ctx, fs, set.query); err != nil {
+			logrus.Debugf("unable to forward %s to the primary: %s", set.query, err.Error())
+		}
+	}
+	return nil
+}
+
+// forwardAs handles the call of forwardAsProcedure |call| on the primary by the connection |c|, whose session is
+// |sess|, by making the session run its statements as the client named by the call.
+func (h *forwardingHandler) forwardAs(c *mysql.Conn, sess sql.Session, fs *forwardedSession, call *sqlparser.Call) (*sqltypes.Result, error) {
+	if c.User != h.user || fs.forwardedAs {
+		return nil, mysql.NewSQLError(mysql.ERSpecifiedAccessDenied, mysql.SSAccessDeniedError,
+			"Access denied; only the connections of cluster standbys may call %s", forwardAsProcedure)
+	}
+	var args []string
+	for _, param := range call.Params {
+		val, ok := param.(*sqlparser.SQLVal)
+		if !ok || val.Type != sqlparser.StrVal {
+			break
+		}
+		args = append(args, string(val.Val))
+	}
+	if len(args) != 2 || len(call.Params) != 2 {
+		return nil, fmt.Errorf("%s takes the user and host of a client", forwardAsProcedure)
+	}
+	userName, host := args[0], args[1]
+
+	mysqlDb := h.engine.Analyzer.Catalog.MySQLDb
+	if mysqlDb.Enabled() {
+		rd := mysqlDb.Reader()
+		user := mysqlDb.GetUser(rd, userName, host, false)
+		rd.Close()
+		if user == nil {
+			return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", userName)
+		}
+	}
+
+	client := sess.Client()
+	client.User, client.Address = userName, host
+	sess.SetClient(client)
+	// the privileges of the forwarding user are no longer those of the session
+	sess.SetPrivilegeSet(nil, 0)
+	fs.forwardedAs = true
+	return &sqltypes.Result{}, nil
+}
+
+// forwardAsQuery returns the call of forwardAsProcedure for |client|.
+func forwardAsQuery(client sql.Client) string {
+	return "call " + forwardAsProcedure + "(" +
+		sqlparser.String(sqlparser.NewStrVal([]byte(client.User))) + ", " +
+		sqlparser.String(sqlparser.NewStrVal([]byte(client.Address))) + ")"
+}
+
+// parseForwardAs returns the call of forwardAsProcedure |query| is, or nil if it's any other statement.
+func parseForwardAs(query string) *sqlparser.Call {
+	if !strings.Contains(strings.ToLower(query), forwardAsProcedure) {
+		return nil
+	}
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil
+	}
+	if call, ok := stmt.(*sqlparser.Call); ok && isForwardAs(call) {
+		return call
+	}
+	return nil
+}
+
+// isForwardAs returns whether |call| calls forwardAsProcedure.
+func isForwardAs(call *sqlparser.Call) bool {
+	return strings.EqualFold(call.ProcName.Name.String(), forwardAsProcedure)
+}
+
+// recordSets records the variables set by |set|, which the client of |ctx| ran locally, in |fs|. User variables and
+// session system variables are recorded with the values they were set to, so that their assignments don't depend on
+// the values of other variables when they're replayed. Other assignments, such as SET NAMES, are recorded as is.
+func recordSets(ctx *sql.Context, fs *forwardedSession, set *sqlparser.Set) {
+	for _, expr := range set.Exprs {
+		name, scope := expr.Name, expr.Scope
+		if scope == sqlparser.SetScope_None {
+			if n, s, _, err := sqlparser.VarScopeForColName(name); err == nil {
+				name, scope = n, s
+			}
+		}
+		varName := name.Name.String()
+
+		switch scope {
+		case sqlparser.SetScope_User:
+			typ, val, err := ctx.GetUserVariable(ctx, varName)
+			if lit, ok := sqlLiteral(ctx, typ, val, err); ok {
+				fs.recordSet("@"+strings.ToLower(varName), "set @`"+strings.ReplaceAll(varName, "`", "``")+"` = "+lit)
+				continue
+			}
+		case sqlparser.SetScope_None, sqlparser.SetScope_Session:
+			if sysVar, _, ok := sql.SystemVariables.GetGlobal(varName); ok {
+				val, err := ctx.GetSessionVariable(ctx, varName)
+				if lit, ok := sqlLiteral(ctx, sysVar.GetType(), val, err); ok {
+					fs.recordSet("@@session."+strings.ToLower(varName), "set @@session."+sysVar.GetName()+" = "+lit)
+					continue
+				}
+			}
+		}
+		query := sqlparser.String(&sqlparser.Set{Exprs: sqlparser.SetVarExprs{expr}})
+		fs.recordSet(string(expr.Scope)+":"+strings.ToLower(name.String()), query)
+	}
+}
+
+// sqlLiteral returns the SQL literal of the value |val| of the type |typ|, and whether it could be written. |err| is
+// the error reading |val|, if any.
+func sqlLiteral(ctx *sql.Context, typ sql.Type, val interface{}, err error) (string, bool) {
+	if err != nil {
+		return "", false
+	}
+	if val == nil || typ == nil {
+		return "NULL", true
+	}
+	v, err := typ.SQL(ctx, nil, val)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	v.EncodeSQL(&b)
+	return b.String(), true
+}
+
+// exec runs |query| on the connection of |fs| to the primary. Only the first result set of a statement that returns
+// more than one result is returned. The connection is closed when it fails, and the transaction open on it, if any,
+// is lost.
+func (h *forwardingHandler) exec(ctx context.Context, fs *forwardedSession, query string) (*sqltypes.Result, error) {
+	if fs.conn == nil {
+		return nil, errors.New("the connection to the primary was lost")
+	}
+	res, status, err := fs.conn.ExecuteFetchMulti(ctx, query, forwardedMaxRows, true)
+	for err == nil && uint16(status)&mysql.ServerMoreResultsExists != 0 {
+		var more *sqltypes.Result
+		more, status, _, err = fs.conn.ReadQueryResult(ctx, forwardedMaxRows, true)
+		if err == nil && len(res.Fields) == 0 {
+			res = more
+		}
+	}
+	if err != nil {
+		var sqlErr *mysql.SQLError
+		if !errors.As(err, &sqlErr) || sqlErr.Num >= mysql.CRUnknownError {
+			inTx := fs.inTx
+			fs.close()
+			if inTx {
+				return nil, fmt.Errorf("the transaction forwarded to the primary was lost: %w", err)
+			}
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// recordWrite records the head commit of the current database on the primary, which is waited for before the next
+// statement run locally.
+func (h *forwardingHandler) recordWrite(ctx context.Context, fs *forwardedSession) {
+	if fs.database == "" {
+		return
+	}
+	res, err := h.exec(ctx, fs, "select hashof('HEAD')")
+	if err != nil || len(res.Rows) != 1 || len(res.Rows[0]) != 1 {
+		logrus.Warnf("unable to read the head commit of %s on the primary, reads may not see the last write: %v", fs.database, err)
+		return
+	}
+	commit, ok := hash.MaybeParse(res.Rows[0][0].ToString())
+	if !ok {
+		return
+	}
+	db, _ := dsess.SplitRevisionDbName(fs.database)
+	fs.written[db] = commit
+}
+
+// forwardedDatabase returns the name of the database that statements of |sess| are forwarded to. It names the branch
+// the session is using explicitly, since the default branch of the primary connection may differ.
+func forwardedDatabase(sess sql.Session) string {
+	db := sess.GetCurrentDatabase()
+	if db == "" {
+		return ""
+	}
+	base, rev := dsess.SplitRevisionDbName(db)
+	if rev != "" {
+		return db
+	}
+	doltSess, ok := sess.(*dsess.DoltSession)
+	if !ok {
+		return db
+	}
+	branch, err := doltSess.GetBranch()
+	if err != nil || branch == "" {
+		return db
+	}
+	return base + dsess.DbRevisionDelimiter + branch
+}
+
+// isForwardedWrite returns whether |stmt| writes, and is forwarded to the primary by a standby.
+func isForwardedWrite(stmt sqlparser.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete, *sqlparser.Load,
+		*sqlparser.DDL, *sqlparser.AlterTable, *sqlparser.DBDDL, *sqlparser.CreateSpatialRefSys,
+		*sqlparser.CreateUser, *sqlparser.RenameUser, *sqlparser.DropUser, *sqlparser.CreateRole, *sqlparser.DropRole,
+		*sqlparser.GrantPrivilege, *sqlparser.GrantRole, *sqlparser.GrantProxy,
+		*sqlparser.RevokePrivilege, *sqlparser.RevokeAllPrivileges, *sqlparser.RevokeRole, *sqlparser.RevokeProxy:
+		return true
+	case *sqlparser.Call:
+		return !localProcedures[strings.ToLower(stmt.ProcName.Name.String())]
+	}
+	return false
+}
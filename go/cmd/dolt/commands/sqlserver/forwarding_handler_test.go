@@ -0,0 +1,122 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsForwardedWrite(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"select * from t", false},
+		{"show tables", false},
+		{"insert into t values (1)", true},
+		{"update t set v = 2", true},
+		{"delete from t where pk = 1", true},
+		{"create table t2 (pk int primary key)", true},
+		{"alter table t add column c int", true},
+		{"create database db2", true},
+		{"create user bob identified by 'pass'", true},
+		{"grant select on *.* to bob", true},
+		{"call dolt_commit('-Am', 'msg')", true},
+		{"call dolt_branch('b')", true},
+		{"call DOLT_WAIT_FOR_COMMIT('abc')", false},
+		{"call dolt_count_commits('--from', 'main', '--to', 'b')", false},
+		{"call dolt_assume_cluster_role('primary', 2)", false},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, isForwardedWrite(stmt))
+		})
+	}
+}
+
+func TestForwardAsQuery(t *testing.T) {
+	query := forwardAsQuery(sql.Client{User: "o'brien", Address: "127.0.0.1"})
+	call := parseForwardAs(query)
+	require.NotNil(t, call, query)
+	require.Len(t, call.Params, 2)
+	assert.Equal(t, "o'brien", string(call.Params[0].(*sqlparser.SQLVal).Val))
+	assert.Equal(t, "127.0.0.1", string(call.Params[1].(*sqlparser.SQLVal).Val))
+
+	assert.NotNil(t, parseForwardAs("CALL DOLT_CLUSTER_FORWARD_AS('bob', '%')"))
+	assert.Nil(t, parseForwardAs("call dolt_commit('-m', 'dolt_cluster_forward_as')"))
+	assert.Nil(t, parseForwardAs("select 'dolt_cluster_forward_as'"))
+}
+
+func TestRecordSets(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	fs := &forwardedSession{}
+	set := func(query string) {
+		stmt, err := sqlparser.Parse(query)
+		require.NoError(t, err)
+		recordSets(ctx, fs, stmt.(*sqlparser.Set))
+	}
+	queries := func() []string {
+		var ret []string
+		for _, set := range fs.sets {
+			_, err := sqlparser.Parse(set.query)
+			require.NoError(t, err, set.query)
+			ret = append(ret, set.query)
+		}
+		return ret
+	}
+
+	require.NoError(t, ctx.SetUserVariable(ctx, "a", int64(1), gmstypes.Int64))
+	require.NoError(t, ctx.SetUserVariable(ctx, "b", "it's", gmstypes.LongText))
+	set("set @a = 1, @b = 'it''s'")
+	assert.Equal(t, []string{"set @`a` = 1", "set @`b` = 'it\\'s'"}, queries())
+
+	// a variable set again is replayed with its last value, after the variables set before it
+	require.NoError(t, ctx.SetUserVariable(ctx, "a", int64(2), gmstypes.Int64))
+	set("set @a = @a + 1")
+	assert.Equal(t, []string{"set @`b` = 'it\\'s'", "set @`a` = 2"}, queries())
+
+	require.NoError(t, ctx.SetSessionVariable(ctx, "sql_select_limit", int64(10)))
+	set("set sql_select_limit = 10")
+	set("set names utf8mb4")
+	assert.Equal(t, []string{
+		"set @`b` = 'it\\'s'",
+		"set @`a` = 2",
+		"set @@session.sql_select_limit = 10",
+		"set names 'utf8mb4'",
+	}, queries())
+}
+
+func TestRecordSetEvictsLeastRecentlySet(t *testing.T) {
+	fs := &forwardedSession{}
+	for i := 0; i <= forwardedMaxSets; i++ {
+		fs.recordSet(fmt.Sprintf("@v%d", i), fmt.Sprintf("set @v%d = 1", i))
+	}
+	require.Len(t, fs.sets, forwardedMaxSets)
+	assert.Equal(t, "@v1", fs.sets[0].variable)
+
+	fs.recordSet("@v1", "set @v1 = 2")
+	require.Len(t, fs.sets, forwardedMaxSets)
+	assert.Equal(t, "@v2", fs.sets[0].variable)
+	assert.Equal(t, forwardedSet{variable: "@v1", query: "set @v1 = 2"}, fs.sets[forwardedMaxSets-1])
+}
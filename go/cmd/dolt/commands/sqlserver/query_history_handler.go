@@ -0,0 +1,255 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/transform"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/queryhistory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// queryHistoryFlushInterval is the interval at which the query history is persisted to its file.
+const queryHistoryFlushInterval = 30 * time.Second
+
+// historyHandler is a mysql.Handler that records the statements it runs in a query history, and delegates to the
+// handler it wraps.
+type historyHandler struct {
+	mysql.Handler
+	history *queryhistory.History
+
+	mu       sync.Mutex
+	sessions map[uint32]sql.Session
+}
+
+var _ mysql.Handler = (*historyHandler)(nil)
+var _ mysql.BinlogReplicaHandler = (*historyHandler)(nil)
+
+func newHistoryHandler(h mysql.Handler, history *queryhistory.History) *historyHandler {
+	return &historyHandler{
+		Handler:  h,
+		history:  history,
+		sessions: make(map[uint32]sql.Session),
+	}
+}
+
+// queryHistoryConfig converts the query history config of the server to the config used to open the history.
+func queryHistoryConfig(cfg servercfg.QueryHistoryConfig, cfgDir string) queryhistory.Config {
+	path := cfg.Path()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfgDir, path)
+	}
+	return queryhistory.Config{
+		Path:          path,
+		Size:          cfg.Size(),
+		FlushInterval: queryHistoryFlushInterval,
+	}
+}
+
+// sessionBuilder wraps |sb| to track the session of each connection, which entries are filled in from.
+func (h *historyHandler) sessionBuilder(sb server.SessionBuilder) server.SessionBuilder {
+	return func(ctx context.Context, conn *mysql.Conn, addr string) (sql.Session, error) {
+		sess, err := sb(ctx, conn, addr)
+		if err != nil {
+			return nil, err
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.sessions[conn.ConnectionID] = sess
+		return sess, nil
+	}
+}
+
+func (h *historyHandler) session(c *mysql.Conn) sql.Session {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sessions[c.ConnectionID]
+}
+
+// ConnectionClosed implements mysql.Handler.
+func (h *historyHandler) ConnectionClosed(c *mysql.Conn) {
+	h.Handler.ConnectionClosed(c)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, c.ConnectionID)
+}
+
+// ComQuery implements mysql.Handler.
+func (h *historyHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	stmt := h.startStatement(c, query)
+	err := h.Handler.ComQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		countResult(stmt, res)
+		return callback(res, more)
+	})
+	h.finishStatement(c, stmt, err)
+	return err
+}
+
+// ComMultiQuery implements mysql.Handler. Each statement of the query is recorded separately.
+func (h *historyHandler) ComMultiQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) (string, error) {
+	stmt := h.startStatement(c, query)
+	remainder, err := h.Handler.ComMultiQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		countResult(stmt, res)
+		return callback(res, more)
+	})
+	if err == nil && len(remainder) < len(query) {
+		stmt.SetQuery(strings.TrimSpace(strings.TrimSuffix(query, remainder)))
+	}
+	h.finishStatement(c, stmt, err)
+	return remainder, err
+}
+
+// ComStmtExecute implements mysql.Handler.
+func (h *historyHandler) ComStmtExecute(ctx context.Context, c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	stmt := h.startStatement(c, prepare.PrepareStmt)
+	err := h.Handler.ComStmtExecute(ctx, c, prepare, func(res *sqltypes.Result) error {
+		countResult(stmt, res)
+		return callback(res)
+	})
+	h.finishStatement(c, stmt, err)
+	return err
+}
+
+// ComRegisterReplica implements mysql.BinlogReplicaHandler.
+func (h *historyHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
+	replicaHandler, ok := h.Handler.(mysql.BinlogReplicaHandler)
+	if !ok {
+		return fmt.Errorf("binlog replication is not supported")
+	}
+	return replicaHandler.ComRegisterReplica(c, replicaHost, replicaPort, replicaUser, replicaPassword)
+}
+
+// ComBinlogDumpGTID implements mysql.BinlogReplicaHandler.
+func (h *historyHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet mysql.GTIDSet) error {
+	replicaHandler, ok := h.Handler.(mysql.BinlogReplicaHandler)
+	if !ok {
+		return fmt.Errorf("binlog replication is not supported")
+	}
+	return replicaHandler.ComBinlogDumpGTID(c, logFile, logPos, gtidSet)
+}
+
+// countResult adds the rows of |res| to the rows returned by |stmt|, or, if it is the OK result of a statement that
+// returns no rows, its rows affected. Result sets report the size of each batch of rows as their rows affected.
+func countResult(stmt *queryhistory.Statement, res *sqltypes.Result) {
+	if res == nil {
+		return
+	}
+	if len(res.Fields) > 0 {
+		stmt.RowsReturned += uint64(len(res.Rows))
+	} else {
+		stmt.RowsAffected += res.RowsAffected
+	}
+}
+
+func (h *historyHandler) startStatement(c *mysql.Conn, query string) *queryhistory.Statement {
+	stmt := h.history.Start(c.ConnectionID, query, time.Now())
+	stmt.User = c.User
+	h.fillLocation(c, stmt)
+	return stmt
+}
+
+func (h *historyHandler) finishStatement(c *mysql.Conn, stmt *queryhistory.Statement, err error) {
+	// a statement that wasn't run on a database, such as a USE statement, is recorded with the database it changed to
+	if stmt.Database == "" {
+		h.fillLocation(c, stmt)
+	}
+	h.history.Finish(stmt, err)
+}
+
+// fillLocation fills in the database and branch of |stmt| from the session of |c|.
+func (h *historyHandler) fillLocation(c *mysql.Conn, stmt *queryhistory.Statement) {
+	sess := h.session(c)
+	if sess == nil {
+		return
+	}
+	stmt.Database, _ = dsess.SplitRevisionDbName(sess.GetCurrentDatabase())
+	if doltSess, ok := sess.(*dsess.DoltSession); ok {
+		stmt.Branch, _ = doltSess.GetBranch()
+	}
+}
+
+// historyExecBuilder is a sql.NodeExecBuilder that records the plan of each statement of the query history, and counts
+// the rows it examines, before building it with the builder it wraps. Rows are counted as they are read by the table
+// scans of the plan; rows read through index lookups are not counted.
+type historyExecBuilder struct {
+	sql.NodeExecBuilder
+	history *queryhistory.History
+}
+
+var _ sql.NodeExecBuilder = historyExecBuilder{}
+
+// Build implements sql.NodeExecBuilder.
+func (b historyExecBuilder) Build(ctx *sql.Context, n sql.Node, r sql.Row) (sql.RowIter, error) {
+	// only the top level node of a statement is a QueryProcess; the builder is also used for subqueries
+	qp, ok := n.(*plan.QueryProcess)
+	if !ok || ctx.Session == nil {
+		return b.NodeExecBuilder.Build(ctx, n, r)
+	}
+	stmt := b.history.Running(ctx.Session.ID())
+	if stmt == nil {
+		return b.NodeExecBuilder.Build(ctx, n, r)
+	}
+
+	stmt.SetPlan(qp.Child().String())
+	counted, _, err := transform.Node(qp, func(n sql.Node) (sql.Node, transform.TreeIdentity, error) {
+		rt, ok := n.(*plan.ResolvedTable)
+		if !ok {
+			return n, transform.SameTree, nil
+		}
+		var t sql.Table
+		switch pt := rt.Table.(type) {
+		case *plan.ProcessTable:
+			t = plan.NewProcessTable(pt.Table, pt.OnPartitionDone, pt.OnPartitionStart, countRows(stmt, pt.OnRowNext))
+		case *plan.ProcessIndexableTable:
+			t = plan.NewProcessIndexableTable(pt.DriverIndexableTable, pt.OnPartitionDone, pt.OnPartitionStart, countRows(stmt, pt.OnRowNext))
+		default:
+			return n, transform.SameTree, nil
+		}
+		nt, err := rt.ReplaceTable(t)
+		if err != nil {
+			return nil, transform.SameTree, err
+		}
+		return nt.(sql.Node), transform.NewTree, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.NodeExecBuilder.Build(ctx, counted, r)
+}
+
+// countRows returns a NamedNotifyFunc which adds every row it's notified of to the rows examined by |stmt|, and then
+// calls |next|, if it's not nil.
+func countRows(stmt *queryhistory.Statement, next plan.NamedNotifyFunc) plan.NamedNotifyFunc {
+	return func(name string) {
+		stmt.AddRowsExamined(1)
+		if next != nil {
+			next(name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/utils/svcs"
+)
+
+func TestServerQueryHistory(t *testing.T) {
+	dEnv, err := sqle.CreateEnvWithSeedData()
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, dEnv.DoltDB.Close())
+	}()
+
+	cfgDir := t.TempDir()
+	serverConfig, err := servercfg.NewYamlConfig([]byte(fmt.Sprintf(`
+log_level: fatal
+listener:
+    port: 15306
+cfg_dir: %s
+query_history:
+    size: 100
+`, cfgDir)))
+	require.NoError(t, err)
+
+	sc := svcs.NewController()
+	defer sc.Stop()
+	go func() {
+		_, _ = Serve(context.Background(), "0.0.0", serverConfig, sc, dEnv)
+	}()
+	require.NoError(t, sc.WaitForStart())
+
+	db, err := sql.Open("mysql", servercfg.ConnectionString(serverConfig, "dolt"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	for _, query := range []string{
+		"create table readings (pk int primary key, v int)",
+		"insert into readings values (1, 10), (2, 20), (3, 30)",
+		"select * from readings where v > 10",
+		"select * from readings where v > 20",
+	} {
+		_, err = db.Exec(query)
+		require.NoError(t, err)
+	}
+	_, err = db.Exec("select * from not_a_table")
+	require.Error(t, err)
+
+	rows, err := db.Query("select user, `database`, branch, digest_text, query, rows_examined, rows_returned, rows_affected, plan, error is not null from dolt_query_history order by start_time")
+	require.NoError(t, err)
+	type historyRow struct {
+		user, database, branch, digestText, query string
+		rowsExamined, rowsReturned, rowsAffected  uint64
+		plan                                      sql.NullString
+		failed                                    bool
+	}
+	var actual []historyRow
+	for rows.Next() {
+		var r historyRow
+		require.NoError(t, rows.Scan(&r.user, &r.database, &r.branch, &r.digestText, &r.query, &r.rowsExamined, &r.rowsReturned, &r.rowsAffected, &r.plan, &r.failed))
+		actual = append(actual, r)
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+
+	require.Len(t, actual, 5)
+	for _, r := range actual {
+		assert.Equal(t, "root", r.user)
+		assert.Equal(t, "dolt", r.database)
+		assert.Equal(t, "main", r.branch)
+	}
+	assert.Equal(t, "INSERT INTO `readings` VALUES (...)", actual[1].digestText)
+	assert.Equal(t, uint64(3), actual[1].rowsAffected)
+	assert.Equal(t, "SELECT * FROM `readings` WHERE `v` > ?", actual[2].digestText)
+	assert.Equal(t, "select * from readings where v > 10", actual[2].query)
+	assert.Equal(t, uint64(3), actual[2].rowsExamined)
+	assert.Equal(t, uint64(2), actual[2].rowsReturned)
+	assert.Equal(t, uint64(0), actual[2].rowsAffected)
+	assert.True(t, strings.Contains(actual[2].plan.String, "Filter"), actual[2].plan.String)
+	assert.Equal(t, uint64(1), actual[3].rowsReturned)
+	assert.True(t, actual[4].failed)
+	assert.False(t, actual[4].plan.Valid)
+
+	var count, errorCount uint64
+	var p50, p99, max int64
+	require.NoError(t, db.QueryRow("select count, error_count, p50_latency_micros, p99_latency_micros, max_latency_micros from dolt_query_digest where digest_text = 'SELECT * FROM `readings` WHERE `v` > ?'").
+		Scan(&count, &errorCount, &p50, &p99, &max))
+	assert.Equal(t, uint64(2), count)
+	assert.Equal(t, uint64(0), errorCount)
+	assert.LessOrEqual(t, p50, p99)
+	assert.Equal(t, max, p99)
+	require.NoError(t, db.Close())
+
+	// stopping the server persists the history to the cfg_dir
+	sc.Stop()
+	require.NoError(t, sc.WaitForStop())
+	contents, err := os.ReadFile(filepath.Join(cfgDir, servercfg.DefaultQueryHistoryPath))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "select * from readings where v > 10")
+}
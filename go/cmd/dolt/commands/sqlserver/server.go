@@ -37,15 +37,21 @@ import (
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	goerrors "gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	cdcapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/cdcapi/v1alpha1"
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
 	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/auditlog"
+	"github.com/dolthub/dolt/go/libraries/doltcore/cdc"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/queryhistory"
 	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
 	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
@@ -54,6 +60,8 @@ import (
 	_ "github.com/dolthub/dolt/go/libraries/doltcore/sqle/dfunctions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqlserver"
+	"github.com/dolthub/dolt/go/libraries/doltcore/tracing"
+	"github.com/dolthub/dolt/go/libraries/doltcore/webhook"
 	"github.com/dolthub/dolt/go/libraries/events"
 	"github.com/dolthub/dolt/go/libraries/utils/config"
 	"github.com/dolthub/dolt/go/libraries/utils/svcs"
@@ -151,6 +159,38 @@ func ConfigureServices(
 	}
 	controller.Register(InitLogging)
 
+	var tracerProvider *tracesdk.TracerProvider
+	InitTracing := &svcs.AnonService{
+		InitF: func(ctx context.Context) (err error) {
+			cfg := serverConfig.TracingConfig()
+			if cfg == nil {
+				return nil
+			}
+			tracerProvider, err = tracing.NewTracerProvider(ctx, tracing.Config{
+				Endpoint:    cfg.Endpoint(),
+				Protocol:    cfg.Protocol(),
+				Insecure:    cfg.Insecure(),
+				ServiceName: cfg.ServiceName(),
+				SampleRatio: cfg.SampleRatio(),
+				Headers:     cfg.Headers(),
+			}, version)
+			if err != nil {
+				return fmt.Errorf("unable to configure tracing: %w", err)
+			}
+			otel.SetTracerProvider(tracerProvider)
+			otel.SetTextMapPropagator(tracing.Propagator)
+			return nil
+		},
+		StopF: func() error {
+			if tracerProvider == nil {
+				return nil
+			}
+			// flushes the spans of the last queries
+			return tracerProvider.Shutdown(context.Background())
+		},
+	}
+	controller.Register(InitTracing)
+
 	controller.Register(newHeartbeatService(version, dEnv))
 
 	fs := dEnv.FS
@@ -233,8 +273,13 @@ func ConfigureServices(
 
 	// Create SQL Engine with users
 	var config *engine.SqlEngineConfig
+	// Changes are streamed to subscribers by the remotesapi server, so they are only captured when it is enabled.
+	var cdcHub *cdc.Hub
 	InitSqlEngineConfig := &svcs.AnonService{
 		InitF: func(context.Context) error {
+			if serverConfig.RemotesapiPort() != nil {
+				cdcHub = cdc.NewHub()
+			}
 			config = &engine.SqlEngineConfig{
 				IsReadOnly:              serverConfig.ReadOnly(),
 				PrivFilePath:            serverConfig.PrivilegeFilePath(),
@@ -249,6 +294,10 @@ func ConfigureServices(
 				SystemVariables:         serverConfig.SystemVars(),
 				ClusterController:       clusterController,
 				BinlogReplicaController: binlogreplication.DoltBinlogReplicaController,
+				Webhooks:                webhookConfigs(serverConfig.WebhookConfigs()),
+				InstallWebhooks:         true,
+				CDC:                     cdcHub,
+				QueryParallelism:        serverConfig.QueryParallelism(),
 			}
 			return nil
 		},
@@ -411,6 +460,56 @@ func ConfigureServices(
 	}
 	controller.Register(InitMetricsListener)
 
+	var auditLog *auditlog.Log
+	InitAuditLog := &svcs.AnonService{
+		InitF: func(context.Context) (err error) {
+			cfg := serverConfig.AuditLogConfig()
+			if cfg == nil {
+				return nil
+			}
+			auditLog, err = auditlog.Open(auditLogConfig(cfg, serverConfig.CfgDir()))
+			if err != nil {
+				return fmt.Errorf("unable to open the audit log: %w", err)
+			}
+			auditlog.SetActiveLog(auditLog)
+			return nil
+		},
+		StopF: func() error {
+			if auditLog == nil {
+				return nil
+			}
+			auditlog.SetActiveLog(nil)
+			return auditLog.Close()
+		},
+	}
+	controller.Register(InitAuditLog)
+
+	var queryHistory *queryhistory.History
+	InitQueryHistory := &svcs.AnonService{
+		InitF: func(context.Context) (err error) {
+			cfg := serverConfig.QueryHistoryConfig()
+			if cfg == nil {
+				return nil
+			}
+			queryHistory, err = queryhistory.Open(queryHistoryConfig(cfg, serverConfig.CfgDir()))
+			if err != nil {
+				return fmt.Errorf("unable to open the query history: %w", err)
+			}
+			analyzer := sqlEngine.GetUnderlyingEngine().Analyzer
+			analyzer.ExecBuilder = historyExecBuilder{NodeExecBuilder: analyzer.ExecBuilder, history: queryHistory}
+			queryhistory.SetActiveHistory(queryHistory)
+			return nil
+		},
+		StopF: func() error {
+			if queryHistory == nil {
+				return nil
+			}
+			queryhistory.SetActiveHistory(nil)
+			return queryHistory.Close()
+		},
+	}
+	controller.Register(InitQueryHistory)
+
 	InitLockSuperUser := &svcs.AnonService{
 		InitF: func(context.Context) error {
 			mysqlDb := sqlEngine.GetUnderlyingEngine().Analyzer.Catalog.MySQLDb
@@ -516,6 +615,7 @@ func ConfigureServices(
 
 			authenticator := newAccessController(sqlEngine.NewDefaultContext, sqlEngine.GetUnderlyingEngine().Analyzer.Catalog.MySQLDb)
 			args = sqle.WithUserPasswordAuth(args, authenticator)
+			args.HttpInterceptor = cdc.HttpInterceptor(cdcHub, authenticator)
 			args.TLSConfig = serverConf.TLSConfig
 
 			remoteSrv.srv, err = remotesrv.NewServer(args)
@@ -523,6 +623,7 @@ func ConfigureServices(
 				lgr.Errorf("error creating remotesapi server on port %d: %v", port, err)
 				return err
 			}
+			cdcapi.RegisterChangeDataCaptureServiceServer(remoteSrv.srv.GrpcServer(), cdc.NewServer(cdcHub))
 			remoteSrv.lis, err = remoteSrv.srv.Listeners()
 			if err != nil {
 				lgr.Errorf("error starting remotesapi server listeners on port %d: %v", port, err)
@@ -537,6 +638,10 @@ func ConfigureServices(
 		},
 		StopF: func() error {
 			state := remoteSrv.state.Swap(svcs.ServiceState_Stopped)
+			if cdcHub != nil {
+				// change streams never end on their own, so end them before waiting for in-flight requests
+				cdcHub.Close()
+			}
 			if state == svcs.ServiceState_Run {
 				remoteSrv.srv.GracefulStop()
 			} else if state == svcs.ServiceState_Init {
@@ -613,22 +718,61 @@ func ConfigureServices(
 	var mySQLServer *server.Server
 	InitSQLServer := &svcs.AnonService{
 		InitF: func(context.Context) (err error) {
+			sessionBuilder := newSessionBuilder(sqlEngine, serverConfig)
+			var wrappers []server.HandlerWrapper
 			v, ok := serverConfig.(servercfg.ValidatingServerConfig)
 			if ok && v.GoldenMysqlConnectionString() != "" {
+				wrappers = append(wrappers, func(h mysql.Handler) (mysql.Handler, error) {
+					return golden.NewValidatingHandler(h, v.GoldenMysqlConnectionString(), logrus.StandardLogger())
+				})
+			}
+			if clusterController != nil && serverConfig.ClusterConfig().WriteForwardingConfig() != nil {
+				// the forwarding handler is wrapped by the others, so that they see forwarded statements
+				forwarding := newForwardingHandler(nil, sqlEngine.GetUnderlyingEngine(), clusterController, serverConfig.ClusterConfig().WriteForwardingConfig())
+				sessionBuilder = forwarding.sessionBuilder(sessionBuilder)
+				wrappers = append(wrappers, func(h mysql.Handler) (mysql.Handler, error) {
+					forwarding.Handler = h
+					return forwarding, nil
+				})
+			}
+			if queryHistory != nil {
+				history := newHistoryHandler(nil, queryHistory)
+				sessionBuilder = history.sessionBuilder(sessionBuilder)
+				wrappers = append(wrappers, func(h mysql.Handler) (mysql.Handler, error) {
+					history.Handler = h
+					return history, nil
+				})
+			}
+			if auditLog != nil {
+				// the audit handler wraps every other handler, so that it sees every statement and connection
+				audit := newAuditHandler(nil, auditLog)
+				sessionBuilder = audit.sessionBuilder(sessionBuilder)
+				wrappers = append(wrappers, func(h mysql.Handler) (mysql.Handler, error) {
+					audit.Handler = h
+					return audit, nil
+				})
+			}
+
+			if len(wrappers) > 0 {
 				mySQLServer, err = server.NewServerWithHandler(
 					serverConf,
 					sqlEngine.GetUnderlyingEngine(),
-					newSessionBuilder(sqlEngine, serverConfig),
+					sessionBuilder,
 					metListener,
 					func(h mysql.Handler) (mysql.Handler, error) {
-						return golden.NewValidatingHandler(h, v.GoldenMysqlConnectionString(), logrus.StandardLogger())
+						for _, wrap := range wrappers {
+							if h, err = wrap(h); err != nil {
+								return nil, err
+							}
+						}
+						return h, nil
 					},
 				)
 			} else {
 				mySQLServer, err = server.NewServer(
 					serverConf,
 					sqlEngine.GetUnderlyingEngine(),
-					newSessionBuilder(sqlEngine, serverConfig),
+					sessionBuilder,
 					metListener,
 				)
 			}
@@ -660,6 +804,9 @@ func ConfigureServices(
 	}
 	controller.Register(AutoStartBinlogReplica)
 
+	// Periodically delete the branches and tags selected by the dolt_branch_policies of every database
+	controller.Register(&branchPruneService{sqlEngine: func() *engine.SqlEngine { return sqlEngine }})
+
 	RunClusterController := &svcs.AnonService{
 		InitF: func(context.Context) error {
 			if clusterController == nil {
@@ -867,6 +1014,23 @@ func (r *remotesapiAuth) ApiAuthorize(ctx context.Context, superUserRequired boo
 	return true, nil
 }
 
+// webhookConfigs converts the webhooks in the server config to the configs used to create their commit hooks.
+func webhookConfigs(cfgs []servercfg.WebhookConfig) []webhook.Config {
+	ret := make([]webhook.Config, len(cfgs))
+	for i, cfg := range cfgs {
+		ret[i] = webhook.Config{
+			Name:       cfg.Name(),
+			URL:        cfg.URL(),
+			Secret:     cfg.Secret(),
+			Databases:  cfg.Databases(),
+			Branches:   cfg.Branches(),
+			MaxRetries: cfg.MaxRetries(),
+			Timeout:    time.Duration(cfg.TimeoutMillis()) * time.Millisecond,
+		}
+	}
+	return ret
+}
+
 func LoadClusterTLSConfig(cfg servercfg.ClusterConfig) (*tls.Config, error) {
 	rcfg := cfg.RemotesAPIConfig()
 	if rcfg.TLSKey() == "" && rcfg.TLSCert() == "" {
@@ -970,6 +1134,9 @@ func getConfigFromServerConfig(serverConfig servercfg.ServerConfig) (server.Conf
 	serverConf.RequireSecureTransport = serverConfig.RequireSecureTransport()
 	serverConf.MaxLoggedQueryLen = serverConfig.MaxLoggedQueryLen()
 	serverConf.EncodeLoggedQuery = serverConfig.ShouldEncodeLoggedQuery()
+	if serverConfig.TracingConfig() != nil {
+		serverConf.Tracer = otel.Tracer("github.com/dolthub/dolt/go/cmd/dolt/commands/sqlserver")
+	}
 
 	return serverConf, nil
 }
@@ -15,17 +15,23 @@
 package sqlserver
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/dolthub/go-mysql-server/sql"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gocraft/dbr/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	"golang.org/x/net/context"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/testcommands"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
@@ -319,6 +325,10 @@ func TestServerSetDefaultBranch(t *testing.T) {
 	require.NoError(t, err)
 
 	const dbName = "dolt"
+	// the default branch is a global variable, which would otherwise outlive this test
+	defer func() {
+		_ = sql.SystemVariables.SetGlobal(dsess.DefaultBranchKey(dbName), "")
+	}()
 
 	defaultBranch := env.DefaultInitBranch
 
@@ -510,3 +520,72 @@ func TestReadReplica(t *testing.T) {
 		assert.ElementsMatch(t, res, []int{0})
 	})
 }
+
+func TestServerTracing(t *testing.T) {
+	// an OTLP/HTTP collector which records the names of the spans it receives
+	var mu sync.Mutex
+	var spanNames []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req coltracepb.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &req))
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					spanNames = append(spanNames, span.Name)
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		resp, err := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		require.NoError(t, err)
+		w.Write(resp)
+	}))
+	defer collector.Close()
+
+	dEnv, err := sqle.CreateEnvWithSeedData()
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, dEnv.DoltDB.Close())
+	}()
+
+	serverConfig, err := servercfg.NewYamlConfig([]byte(fmt.Sprintf(`
+log_level: fatal
+listener:
+    port: 15305
+tracing:
+    endpoint: %s
+    protocol: http
+    insecure: true
+`, strings.TrimPrefix(collector.URL, "http://"))))
+	require.NoError(t, err)
+
+	sc := svcs.NewController()
+	go func() {
+		_, _ = Serve(context.Background(), "0.0.0", serverConfig, sc, dEnv)
+	}()
+	require.NoError(t, sc.WaitForStart())
+
+	conn, err := dbr.Open("mysql", servercfg.ConnectionString(serverConfig, "dolt"), nil)
+	require.NoError(t, err)
+	sess := conn.NewSession(nil)
+	_, err = sess.Exec("create table traced (pk int primary key)")
+	require.NoError(t, err)
+	_, err = sess.Exec("insert into traced values (1)")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// stopping the server flushes the spans to the collector
+	sc.Stop()
+	require.NoError(t, sc.WaitForStop())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, spanNames, "query")
+	assert.Contains(t, spanNames, "parse")
+	assert.Contains(t, spanNames, "analyze")
+	assert.Contains(t, spanNames, "dsess.DoltTransaction.doCommit")
+}
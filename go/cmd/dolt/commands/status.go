@@ -217,7 +217,7 @@ func createPrintData(err error, queryist cli.Queryist, sqlCtx *sql.Context, show
 				}
 				shouldIgnoreTable = ignored == doltdb.Ignore
 			}
-			shouldIgnoreTable = shouldIgnoreTable || doltdb.IsFullTextTable(tableName)
+			shouldIgnoreTable = shouldIgnoreTable || doltdb.IsFullTextTable(tableName) || doltdb.IsVectorIndexTable(tableName)
 
 			switch status {
 			case "renamed":
@@ -0,0 +1,219 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcmds
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/gocraft/dbr/v2"
+	"github.com/gocraft/dbr/v2/dialect"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtablefunctions"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+var runDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Runs the SQL tests stored in the dolt_tests system table",
+	LongDesc: `Runs the tests stored in the {{.EmphasisLeft}}dolt_tests{{.EmphasisRight}} system table against a revision of the database, and reports whether each test passed or failed.
+
+Each test runs a {{.EmphasisLeft}}SELECT{{.EmphasisRight}} query and makes an assertion about its result, declared by its {{.EmphasisLeft}}assertion_type{{.EmphasisRight}}:
+
+{{.EmphasisLeft}}no_rows{{.EmphasisRight}}: the query returns no rows.
+
+{{.EmphasisLeft}}expected_row_count{{.EmphasisRight}}: the number of rows returned by the query compares to the integer {{.EmphasisLeft}}assertion_value{{.EmphasisRight}} with the {{.EmphasisLeft}}assertion_comparator{{.EmphasisRight}}, one of ==, !=, <, <=, > or >=. Defaults to ==.
+
+{{.EmphasisLeft}}expected_rows{{.EmphasisRight}}: the query returns exactly the rows of the {{.EmphasisLeft}}assertion_value{{.EmphasisRight}}, in order. The value is a JSON array of rows, each of which is an array of values, e.g. {{.EmphasisLeft}}[[1, "a"], [2, null]]{{.EmphasisRight}}.
+
+Both the tests and their queries are read from {{.LessThan}}revision{{.GreaterThan}}, which defaults to the current branch. The command exits with a non-zero status if any test fails. The same tests can be run from SQL with the {{.EmphasisLeft}}dolt_test_run(){{.EmphasisRight}} table function.`,
+	Synopsis: []string{
+		"[--group {{.LessThan}}group{{.GreaterThan}}] [-r {{.LessThan}}result format{{.GreaterThan}}] [{{.LessThan}}revision{{.GreaterThan}}]",
+	},
+}
+
+type RunCmd struct{}
+
+var _ cli.Command = RunCmd{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd RunCmd) Name() string {
+	return "run"
+}
+
+// Description returns a description of the command
+func (cmd RunCmd) Description() string {
+	return runDocs.ShortDesc
+}
+
+func (cmd RunCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(runDocs, ap)
+}
+
+func (cmd RunCmd) ArgParser() *argparser.ArgParser {
+	ap := cli.CreateTestRunArgParser()
+	ap.SupportsString(commands.FormatFlag, "r", "result output format", "How to format test results. Valid values are tabular, json, junit. Defaults to tabular.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd RunCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, runDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	format := strings.ToLower(apr.GetValueOrDefault(commands.FormatFlag, "tabular"))
+	switch format {
+	case "tabular", "json", "junit":
+	default:
+		verr := errhand.BuildDError("Invalid argument for --%s. Valid values are tabular, json, junit", commands.FormatFlag).Build()
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if closeFunc != nil {
+		defer closeFunc()
+	}
+
+	var params []interface{}
+	if group, ok := apr.GetValue(cli.GroupFlag); ok {
+		params = append(params, "--"+cli.GroupFlag, group)
+	}
+	if apr.NArg() > 0 {
+		params = append(params, apr.Arg(0))
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(params)), ", ")
+	query, err := dbr.InterpolateForDialect("SELECT * FROM dolt_test_run("+placeholders+")", params, dialect.MySQL)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	sch, rowIter, _, err := queryist.Query(sqlCtx, query)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	rows, err := sql.RowIterToRows(sqlCtx, rowIter)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	switch format {
+	case "junit":
+		err = printJUnitResults(rows)
+	case "json":
+		err = engine.PrettyPrintResults(sqlCtx, engine.FormatJson, sch, sql.RowsToRowIter(rows...))
+	default:
+		err = engine.PrettyPrintResults(sqlCtx, engine.FormatTabular, sch, sql.RowsToRowIter(rows...))
+	}
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("Error outputting test results").AddCause(err).Build(), usage)
+	}
+
+	failed := 0
+	for _, row := range rows {
+		if testRunField(row, 3) != dtablefunctions.TestStatusPass {
+			failed++
+		}
+	}
+	if format == "tabular" {
+		cli.Printf("%d passed, %d failed\n", len(rows)-failed, failed)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// testRunField returns the |i|th column of |row|, a row of dolt_test_run(), as a string.
+func testRunField(row sql.Row, i int) string {
+	if row[i] == nil {
+		return ""
+	}
+	return fmt.Sprint(row[i])
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Query   string `xml:",chardata"`
+}
+
+// printJUnitResults prints the dolt_test_run() rows |rows| as a JUnit XML report, with a test suite for each test
+// group.
+func printJUnitResults(rows []sql.Row) error {
+	report := junitTestSuites{Name: "dolt_tests"}
+	suiteIdx := make(map[string]int)
+	for _, row := range rows {
+		group := testRunField(row, 1)
+		if group == "" {
+			group = "default"
+		}
+		i, ok := suiteIdx[group]
+		if !ok {
+			i = len(report.Suites)
+			suiteIdx[group] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: group})
+		}
+
+		tc := junitTestCase{Name: testRunField(row, 0), ClassName: group}
+		if testRunField(row, 3) != dtablefunctions.TestStatusPass {
+			tc.Failure = &junitFailure{Message: testRunField(row, 4), Query: testRunField(row, 2)}
+			report.Suites[i].Failures++
+			report.Failures++
+		}
+		report.Suites[i].Cases = append(report.Suites[i].Cases, tc)
+		report.Suites[i].Tests++
+		report.Tests++
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(cli.OutStream, xml.Header+string(out)+"\n")
+	return err
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+var worktreeDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Manage multiple working directories of a repository",
+	LongDesc: `A worktree is a directory with a branch of the repository checked out. The directory the repository was created in is its main worktree. Linked worktrees let several branches be worked on at once without cloning the repository again: each has its own repo state and the working set of its branch, and all of them share the storage of the main worktree. A branch can be checked out in only one worktree at a time.
+
+A linked worktree starts with a copy of the remotes, backups and local configuration of the main worktree. Changes to them in one worktree don't apply to the others.
+
+{{.EmphasisLeft}}add{{.EmphasisRight}}
+Creates a linked worktree at {{.LessThan}}path{{.GreaterThan}} with {{.LessThan}}branch{{.GreaterThan}} checked out. The path must not exist, or must be an empty directory.
+
+{{.EmphasisLeft}}list{{.EmphasisRight}}
+Lists the worktrees of the repository and the branch checked out in each. This is the default when no subcommand is given.
+
+{{.EmphasisLeft}}remove{{.EmphasisRight}}, {{.EmphasisLeft}}rm{{.EmphasisRight}}
+Deletes the linked worktree at {{.LessThan}}path{{.GreaterThan}}. Uncommitted changes in it are kept in the working set of its branch, and are there when the branch is next checked out.`,
+	Synopsis: []string{
+		`[list]`,
+		`add {{.LessThan}}path{{.GreaterThan}} {{.LessThan}}branch{{.GreaterThan}}`,
+		`remove {{.LessThan}}path{{.GreaterThan}}`,
+	},
+}
+
+type WorktreeCmd struct{}
+
+var _ cli.Command = WorktreeCmd{}
+
+// Name returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd WorktreeCmd) Name() string {
+	return "worktree"
+}
+
+// Description returns a description of the command
+func (cmd WorktreeCmd) Description() string {
+	return worktreeDocs.ShortDesc
+}
+
+// EventType returns the type of the event to log
+func (cmd WorktreeCmd) EventType() eventsapi.ClientEventType {
+	return eventsapi.ClientEventType_TYPE_UNSPECIFIED
+}
+
+func (cmd WorktreeCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(worktreeDocs, ap)
+}
+
+func (cmd WorktreeCmd) ArgParser() *argparser.ArgParser {
+	return cli.CreateWorktreeArgParser()
+}
+
+// Exec executes the command
+func (cmd WorktreeCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.CommandDocsForCommandString(commandStr, worktreeDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	var verr errhand.VerboseError
+	switch {
+	case apr.NArg() == 0 || apr.Arg(0) == cli.ListWorktreeId:
+		verr = listWorktrees(dEnv, apr)
+	case apr.Arg(0) == cli.AddWorktreeId:
+		verr = addWorktree(ctx, dEnv, apr)
+	case apr.Arg(0) == cli.RemoveWorktreeId, apr.Arg(0) == cli.RemoveWorktreeShortId:
+		verr = removeWorktree(dEnv, apr)
+	default:
+		verr = errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	return HandleVErrAndExitCode(verr, usage)
+}
+
+func listWorktrees(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	if apr.NArg() > 1 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	worktrees, err := dEnv.Worktrees()
+	if err != nil {
+		return errhand.BuildDError("error: failed to read worktrees").AddCause(err).Build()
+	}
+	for _, wt := range worktrees {
+		switch {
+		case wt.Missing:
+			cli.Printf("%s (missing)\n", wt.Path)
+		case wt.Branch == "":
+			cli.Printf("%s (unknown branch)\n", wt.Path)
+		default:
+			cli.Printf("%s [%s]\n", wt.Path, wt.Branch)
+		}
+	}
+	return nil
+}
+
+func addWorktree(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	if apr.NArg() != 3 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	path, branch := apr.Arg(1), apr.Arg(2)
+	err := dEnv.AddWorktree(ctx, path, branch)
+	switch {
+	case err == nil:
+		cli.Printf("Created worktree at %s with branch '%s'\n", path, branch)
+		return nil
+	case err == doltdb.ErrBranchNotFound:
+		return errhand.BuildDError("fatal: Branch '%s' not found.", branch).Build()
+	case env.ErrBranchCheckedOutInWorktree.Is(err), env.ErrWorktreePathExists.Is(err):
+		return errhand.BuildDError("fatal: %s", err.Error()).Build()
+	default:
+		return errhand.BuildDError("error: failed to create worktree at %s", path).AddCause(err).Build()
+	}
+}
+
+func removeWorktree(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	if apr.NArg() != 2 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	path := apr.Arg(1)
+	err := dEnv.RemoveWorktree(path)
+	switch {
+	case err == nil:
+		return nil
+	case err == env.ErrRemoveCurrentWorktree, env.ErrWorktreeNotFound.Is(err):
+		return errhand.BuildDError("fatal: %s", err.Error()).Build()
+	default:
+		return errhand.BuildDError("error: failed to remove worktree at %s", path).AddCause(err).Build()
+	}
+}
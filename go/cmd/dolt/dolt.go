@@ -52,6 +52,7 @@ import (
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/sqlserver"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/stashcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/tblcmds"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/testcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/doltversion"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
@@ -96,6 +97,7 @@ var doltSubCommands = []cli.Command{
 	commands.ConfigCmd{},
 	commands.RemoteCmd{},
 	commands.BackupCmd{},
+	commands.WorktreeCmd{},
 	commands.LoginCmd{},
 	credcmds.Commands,
 	commands.LsCmd{},
@@ -112,6 +114,8 @@ var doltSubCommands = []cli.Command{
 	commands.FsckCmd{},
 	commands.FilterBranchCmd{},
 	commands.MergeBaseCmd{},
+	commands.BisectCmd{},
+	commands.RerereCmd{},
 	commands.RootsCmd{},
 	commands.VersionCmd{VersionStr: doltversion.Version},
 	commands.DumpCmd{},
@@ -120,6 +124,7 @@ var doltSubCommands = []cli.Command{
 	dumpZshCommand,
 	docscmds.Commands,
 	stashcmds.StashCommands,
+	testcmds.Commands,
 	&commands.Assist{},
 	commands.ProfileCmd{},
 	commands.QueryDiff{},
@@ -133,6 +138,7 @@ var commandsWithoutCliCtx = []cli.Command{
 	sqlserver.SqlServerCmd{VersionStr: doltversion.Version},
 	commands.CloneCmd{},
 	commands.BackupCmd{},
+	commands.WorktreeCmd{},
 	commands.LoginCmd{},
 	credcmds.Commands,
 	schcmds.Commands,
@@ -0,0 +1,158 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v4.26.0
+// source: dolt/services/cdcapi/v1alpha1/cdc.proto
+
+package cdcapi
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ChangeDataCaptureServiceClient is the client API for ChangeDataCaptureService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ChangeDataCaptureServiceClient interface {
+	// Subscribe streams the row-level changes made to a branch of a database.
+	// For each commit to the branch, the stream contains one ChangeEvent for
+	// every inserted, updated and deleted row, in table and then primary key
+	// order, followed by a COMMIT event. The cursor of the COMMIT event can be
+	// passed in a later SubscribeRequest to resume the stream after that commit.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (ChangeDataCaptureService_SubscribeClient, error)
+}
+
+type changeDataCaptureServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChangeDataCaptureServiceClient(cc grpc.ClientConnInterface) ChangeDataCaptureServiceClient {
+	return &changeDataCaptureServiceClient{cc}
+}
+
+func (c *changeDataCaptureServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (ChangeDataCaptureService_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ChangeDataCaptureService_ServiceDesc.Streams[0], "/dolt.services.cdcapi.v1alpha1.ChangeDataCaptureService/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &changeDataCaptureServiceSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChangeDataCaptureService_SubscribeClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type changeDataCaptureServiceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *changeDataCaptureServiceSubscribeClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ChangeDataCaptureServiceServer is the server API for ChangeDataCaptureService service.
+// All implementations must embed UnimplementedChangeDataCaptureServiceServer
+// for forward compatibility
+type ChangeDataCaptureServiceServer interface {
+	// Subscribe streams the row-level changes made to a branch of a database.
+	// For each commit to the branch, the stream contains one ChangeEvent for
+	// every inserted, updated and deleted row, in table and then primary key
+	// order, followed by a COMMIT event. The cursor of the COMMIT event can be
+	// passed in a later SubscribeRequest to resume the stream after that commit.
+	Subscribe(*SubscribeRequest, ChangeDataCaptureService_SubscribeServer) error
+	mustEmbedUnimplementedChangeDataCaptureServiceServer()
+}
+
+// UnimplementedChangeDataCaptureServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedChangeDataCaptureServiceServer struct {
+}
+
+func (UnimplementedChangeDataCaptureServiceServer) Subscribe(*SubscribeRequest, ChangeDataCaptureService_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedChangeDataCaptureServiceServer) mustEmbedUnimplementedChangeDataCaptureServiceServer() {
+}
+
+// UnsafeChangeDataCaptureServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChangeDataCaptureServiceServer will
+// result in compilation errors.
+type UnsafeChangeDataCaptureServiceServer interface {
+	mustEmbedUnimplementedChangeDataCaptureServiceServer()
+}
+
+func RegisterChangeDataCaptureServiceServer(s grpc.ServiceRegistrar, srv ChangeDataCaptureServiceServer) {
+	s.RegisterService(&ChangeDataCaptureService_ServiceDesc, srv)
+}
+
+func _ChangeDataCaptureService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChangeDataCaptureServiceServer).Subscribe(m, &changeDataCaptureServiceSubscribeServer{stream})
+}
+
+type ChangeDataCaptureService_SubscribeServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type changeDataCaptureServiceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *changeDataCaptureServiceSubscribeServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ChangeDataCaptureService_ServiceDesc is the grpc.ServiceDesc for ChangeDataCaptureService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChangeDataCaptureService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dolt.services.cdcapi.v1alpha1.ChangeDataCaptureService",
+	HandlerType: (*ChangeDataCaptureServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _ChangeDataCaptureService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dolt/services/cdcapi/v1alpha1/cdc.proto",
+}
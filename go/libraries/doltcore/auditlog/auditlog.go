@@ -0,0 +1,339 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is the kind of event an audit log Record describes.
+type Event string
+
+const (
+	// ConnectEvent is recorded when a client connects to the server, or fails to.
+	ConnectEvent Event = "connect"
+	// DisconnectEvent is recorded when a client disconnects from the server.
+	DisconnectEvent Event = "disconnect"
+	// StatementEvent is recorded for every statement run on the server that isn't a PrivilegeEvent.
+	StatementEvent Event = "statement"
+	// PrivilegeEvent is recorded for statements that change privileges: GRANT and REVOKE, the statements that manage
+	// users and roles, and writes to the branch control tables.
+	PrivilegeEvent Event = "privilege"
+)
+
+// backupTimeFormat is the format of the time in the names of rotated log files, which sorts them oldest first.
+const backupTimeFormat = "20060102T150405.000000"
+
+// Record is a single entry of the audit log, written as one line of JSON.
+type Record struct {
+	Time         time.Time `json:"time"`
+	Event        Event     `json:"event"`
+	ConnectionID uint32    `json:"connection_id"`
+	User         string    `json:"user"`
+	Host         string    `json:"host"`
+	Database     string    `json:"database,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	Query        string    `json:"query,omitempty"`
+	// CommitHash is the new head of Branch, when the statement moved it.
+	CommitHash     string `json:"commit_hash,omitempty"`
+	RowsAffected   uint64 `json:"rows_affected"`
+	RowsReturned   uint64 `json:"rows_returned"`
+	DurationMillis int64  `json:"duration_millis"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Config is the configuration of a Log.
+type Config struct {
+	// Path is the path of the log file. Rotated log files are kept next to it, with the time they were rotated
+	// added to their name.
+	Path string
+	// Events limits the log to these events. If empty, every event is logged.
+	Events []Event
+	// Users limits the log to the records of these users. If empty, records of every user are logged.
+	Users []string
+	// Databases limits the log to the records of these databases, compared case-insensitively. Records without a
+	// database, such as those of a connection that didn't select one, are logged regardless. If empty, records of
+	// every database are logged.
+	Databases []string
+	// MaxSize is the size in bytes at which the log file is rotated. If 0, the log file isn't rotated by size.
+	MaxSize int64
+	// MaxAge is the age at which the log file is rotated. If 0, the log file isn't rotated by age.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated log files kept. If 0, every rotated log file is kept.
+	MaxBackups int
+}
+
+// Allows returns whether |rec| should be written to a log with this config.
+func (c Config) Allows(rec *Record) bool {
+	if len(c.Events) > 0 && !contains(c.Events, rec.Event) {
+		return false
+	}
+	if len(c.Users) > 0 && !contains(c.Users, rec.User) {
+		return false
+	}
+	if len(c.Databases) > 0 && rec.Database != "" {
+		for _, db := range c.Databases {
+			if strings.EqualFold(db, rec.Database) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func contains[T comparable](s []T, v T) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Log is an append-only file of Records, which is rotated when it grows too large or too old. It is safe for
+// concurrent use.
+type Log struct {
+	cfg Config
+
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	created time.Time
+	now     func() time.Time
+}
+
+// Open opens the log described by |cfg|, creating its file and directory if they don't exist, and appending to the
+// file if it does.
+func Open(cfg Config) (*Log, error) {
+	l := &Log{cfg: cfg, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) openFile() error {
+	f, err := os.OpenFile(l.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size, l.created = f, info.Size(), l.now()
+	if l.size > 0 {
+		l.created = info.ModTime()
+	}
+	return nil
+}
+
+// Path returns the path of the log file.
+func (l *Log) Path() string {
+	return l.cfg.Path
+}
+
+// Write appends |rec| to the log, unless the config of the log filters it out. The log file is rotated first if
+// writing |rec| would make it too large, or if it is too old.
+func (l *Log) Write(rec Record) error {
+	if !l.cfg.Allows(&rec) {
+		return nil
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return os.ErrClosed
+	}
+	if l.shouldRotate(len(line)) {
+		if err = l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.f.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+func (l *Log) shouldRotate(n int) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.cfg.MaxSize > 0 && l.size+int64(n) > l.cfg.MaxSize {
+		return true
+	}
+	return l.cfg.MaxAge > 0 && l.now().Sub(l.created) >= l.cfg.MaxAge
+}
+
+// rotate renames the log file to a backup, opens a new log file, and removes the oldest backups beyond MaxBackups.
+func (l *Log) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+
+	ext := filepath.Ext(l.cfg.Path)
+	backup := strings.TrimSuffix(l.cfg.Path, ext) + "-" + l.now().UTC().Format(backupTimeFormat) + ext
+	if err := os.Rename(l.cfg.Path, backup); err != nil {
+		return err
+	}
+	if err := l.openFile(); err != nil {
+		return err
+	}
+
+	if l.cfg.MaxBackups > 0 {
+		backups, err := l.backups()
+		if err != nil {
+			return err
+		}
+		for len(backups) > l.cfg.MaxBackups {
+			if err = os.Remove(backups[0]); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			backups = backups[1:]
+		}
+	}
+	return nil
+}
+
+// backups returns the paths of the rotated log files, oldest first.
+func (l *Log) backups() ([]string, error) {
+	ext := filepath.Ext(l.cfg.Path)
+	backups, err := filepath.Glob(strings.TrimSuffix(l.cfg.Path, ext) + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// Close closes the log file. Records written after Close return an error.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// NewReader returns a Reader of the records in the rotated log files and in the log file, oldest first.
+func (l *Log) NewReader() (*Reader, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	files, err := l.backups()
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{files: append(files, l.cfg.Path)}, nil
+}
+
+// Reader reads the records of a Log. Lines that aren't complete records, such as a line being written while it is
+// read, are skipped.
+type Reader struct {
+	files []string
+	f     *os.File
+	r     *bufio.Reader
+}
+
+// Next returns the next record, or io.EOF when there are no more.
+func (r *Reader) Next() (Record, error) {
+	for {
+		if r.r == nil {
+			if len(r.files) == 0 {
+				return Record{}, io.EOF
+			}
+			f, err := os.Open(r.files[0])
+			r.files = r.files[1:]
+			if errors.Is(err, os.ErrNotExist) {
+				// rotated away since the reader was created
+				continue
+			} else if err != nil {
+				return Record{}, err
+			}
+			r.f, r.r = f, bufio.NewReader(f)
+		}
+
+		line, err := r.r.ReadBytes('\n')
+		if err == io.EOF {
+			if err = r.closeFile(); err != nil {
+				return Record{}, err
+			}
+			continue
+		} else if err != nil {
+			return Record{}, err
+		}
+
+		var rec Record
+		if err = json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			continue
+		}
+		return rec, nil
+	}
+}
+
+func (r *Reader) closeFile() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f, r.r = nil, nil
+	return err
+}
+
+// Close closes the file being read.
+func (r *Reader) Close() error {
+	r.files = nil
+	return r.closeFile()
+}
+
+var active *Log
+var mutex sync.Mutex
+
+// ActiveLog returns the audit log of the SQL server running in this process, or nil if it has none.
+func ActiveLog() *Log {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return active
+}
+
+// SetActiveLog sets the audit log of the SQL server running in this process. A nil log unsets it.
+func SetActiveLog(l *Log) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	active = l
+}
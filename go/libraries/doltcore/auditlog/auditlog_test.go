@@ -0,0 +1,155 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, l *Log) []Record {
+	r, err := l.NewReader()
+	require.NoError(t, err)
+	defer r.Close()
+
+	var recs []Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs
+		}
+		require.NoError(t, err)
+		recs = append(recs, rec)
+	}
+}
+
+func queries(recs []Record) []string {
+	var ret []string
+	for _, rec := range recs {
+		ret = append(ret, rec.Query)
+	}
+	return ret
+}
+
+func TestConfigAllows(t *testing.T) {
+	cfg := Config{
+		Events:    []Event{StatementEvent, PrivilegeEvent},
+		Users:     []string{"alice"},
+		Databases: []string{"MyDB"},
+	}
+	assert.True(t, cfg.Allows(&Record{Event: StatementEvent, User: "alice", Database: "mydb"}))
+	assert.True(t, cfg.Allows(&Record{Event: PrivilegeEvent, User: "alice"}))
+	assert.False(t, cfg.Allows(&Record{Event: ConnectEvent, User: "alice", Database: "mydb"}))
+	assert.False(t, cfg.Allows(&Record{Event: StatementEvent, User: "bob", Database: "mydb"}))
+	assert.False(t, cfg.Allows(&Record{Event: StatementEvent, User: "alice", Database: "other"}))
+	assert.True(t, Config{}.Allows(&Record{Event: DisconnectEvent}))
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"select * from users where password = 'pass'", "select * from users where password = 'pass'"},
+		{"create user bob", "create user bob"},
+		{"create user bob identified by 'pass'", "create user `bob`@`%` identified by '<secret>'"},
+		{"CREATE USER bob IDENTIFIED WITH mysql_native_password BY 'pass', alice IDENTIFIED BY 'pass2'",
+			"create user `bob`@`%` identified with mysql_native_password by '<secret>', `alice`@`%` identified by '<secret>'"},
+		{"alter user bob identified by 'pass'", "alter user `bob`@`%` identified by '<secret>'"},
+		{"set password = 'pass'", "set password = '<secret>'"},
+		{"CHANGE REPLICATION SOURCE TO SOURCE_HOST='localhost', SOURCE_PASSWORD='pass'",
+			"change replication source to source_host = localhost, source_password = <secret>"},
+		{"select 1; create user bob identified by 'pass'; select 'a'",
+			"select 1; create user `bob`@`%` identified by '<secret>'; select 'a'"},
+		// statements that can't be parsed have all their string literals redacted
+		{"set password for `bob's` = 'it\\'s' -- 'pass'", "set password for `bob's` = '<secret>' -- 'pass'"},
+		{`set password for 'bob'@'%' = "pa""ss"`, `set password for '<secret>'@'<secret>' = '<secret>'`},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			assert.Equal(t, test.expected, RedactQuery(test.query))
+		})
+	}
+}
+
+func TestLogWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit_log.jsonl")
+	l, err := Open(Config{Path: path, Users: []string{"root"}})
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, l.Write(Record{Time: now, Event: StatementEvent, User: "root", Database: "db", Query: "select 1", RowsReturned: 1}))
+	require.NoError(t, l.Write(Record{Time: now, Event: StatementEvent, User: "bob", Query: "select 2"}))
+	require.NoError(t, l.Close())
+	require.ErrorIs(t, l.Write(Record{Event: StatementEvent, User: "root"}), os.ErrClosed)
+
+	// reopening appends to the file
+	l, err = Open(Config{Path: path})
+	require.NoError(t, err)
+	defer l.Close()
+	require.NoError(t, l.Write(Record{Time: now, Event: PrivilegeEvent, User: "root", Query: "grant select on *.* to bob", Error: "denied"}))
+
+	recs := readAll(t, l)
+	require.Len(t, recs, 2)
+	assert.Equal(t, Record{Time: now, Event: StatementEvent, User: "root", Database: "db", Query: "select 1", RowsReturned: 1}, recs[0])
+	assert.Equal(t, "denied", recs[1].Error)
+}
+
+func TestLogRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	l, err := Open(Config{Path: path, MaxSize: 200, MaxAge: time.Hour, MaxBackups: 2})
+	require.NoError(t, err)
+	defer l.Close()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	l.created = now
+
+	write := func(query string) {
+		now = now.Add(time.Second)
+		require.NoError(t, l.Write(Record{Event: StatementEvent, Query: query}))
+	}
+
+	// each record is more than half the max size, so each write after the first rotates the file
+	write("q1")
+	write("q2")
+	write("q3")
+	backups, err := l.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, []string{"q1", "q2", "q3"}, queries(readAll(t, l)))
+
+	// the oldest backup is removed beyond MaxBackups
+	write("q4")
+	assert.Equal(t, []string{"q2", "q3", "q4"}, queries(readAll(t, l)))
+
+	// rotated by age
+	l.cfg.MaxSize = 0
+	write("q5")
+	assert.Equal(t, []string{"q2", "q3", "q4", "q5"}, queries(readAll(t, l)))
+	now = now.Add(time.Hour)
+	write("q6")
+	backups, err = l.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, []string{"q3", "q4", "q5", "q6"}, queries(readAll(t, l)))
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// Secret replaces the passwords of the statements recorded by RedactQuery, as it does in the audit logs of MySQL.
+const Secret = "<secret>"
+
+// passwordWords are the words, one of which must appear in a statement that sets a password. They let most queries
+// skip being parsed to be redacted.
+var passwordWords = []string{"identified", "password"}
+
+// RedactQuery returns |query| with the passwords set by its statements replaced by Secret, so that they aren't
+// recorded in plain text. The statements of |query| that set passwords, such as CREATE USER, ALTER USER and
+// SET PASSWORD, are rewritten from their parsed form with their passwords redacted, and the other statements are kept
+// as they are. A statement that mentions a password but can't be parsed has all its string literals redacted.
+func RedactQuery(query string) string {
+	lower := strings.ToLower(query)
+	mentionsPassword := false
+	for _, word := range passwordWords {
+		if strings.Contains(lower, word) {
+			mentionsPassword = true
+			break
+		}
+	}
+	if !mentionsPassword {
+		return query
+	}
+
+	var sb strings.Builder
+	redacted := false
+	for rest := query; strings.TrimSpace(rest) != ""; {
+		stmt, end, err := sqlparser.ParseOne(context.Background(), rest)
+		if err != nil {
+			// the rest of the query can't be split into statements, so it's redacted as a whole
+			return sb.String() + redactStringLiterals(rest)
+		}
+		if end <= 0 || end > len(rest) {
+			end = len(rest)
+		}
+		if redactStatement(stmt) {
+			redacted = true
+			sb.WriteString(rest[:len(rest)-len(strings.TrimLeft(rest, " \t\r\n"))])
+			sb.WriteString(sqlparser.String(stmt))
+			if strings.HasSuffix(strings.TrimSpace(rest[:end]), ";") {
+				sb.WriteString(";")
+			}
+		} else {
+			sb.WriteString(rest[:end])
+		}
+		rest = rest[end:]
+	}
+	if !redacted {
+		return query
+	}
+	return sb.String()
+}
+
+// redactStatement replaces the passwords set by |stmt| with Secret, and returns whether it sets any.
+func redactStatement(stmt sqlparser.Statement) bool {
+	redactAuth := func(auths ...*sqlparser.Authentication) (redacted bool) {
+		for _, auth := range auths {
+			if auth != nil && auth.Password != "" {
+				auth.Password = Secret
+				redacted = true
+			}
+		}
+		return redacted
+	}
+
+	redacted := false
+	switch stmt := stmt.(type) {
+	case *sqlparser.CreateUser:
+		for i := range stmt.Users {
+			user := &stmt.Users[i]
+			if redactAuth(user.Auth1, user.Auth2, user.Auth3, user.AuthInitial) {
+				redacted = true
+			}
+		}
+	case *sqlparser.DDL:
+		redacted = redactAuth(stmt.Authentication)
+	case *sqlparser.Set:
+		for _, expr := range stmt.Exprs {
+			if expr.Name != nil && strings.EqualFold(expr.Name.Name.String(), "password") {
+				expr.Expr = sqlparser.NewStrVal([]byte(Secret))
+				redacted = true
+			}
+		}
+	case *sqlparser.ChangeReplicationSource:
+		for _, option := range stmt.Options {
+			if strings.EqualFold(option.Name, "source_password") {
+				option.Value = Secret
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// redactStringLiterals returns |query| with each of its string literals replaced by Secret. Quoted identifiers and
+// comments are kept as they are.
+func redactStringLiterals(query string) string {
+	var sb strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			sb.WriteString("'" + Secret + "'")
+			i = quotedEnd(query, i)
+			continue
+		case c == '`':
+			end := quotedEnd(query, i)
+			sb.WriteString(query[i:end])
+			i = end
+			continue
+		case c == '#' || strings.HasPrefix(query[i:], "-- "):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+			continue
+		}
+		sb.WriteByte(c)
+		i++
+	}
+	return sb.String()
+}
+
+// quotedEnd returns the index following the closing quote of the quoted text that starts at |start| in |query|, or the
+// length of |query| if the text isn't closed. Quotes are escaped by a backslash, except in quoted identifiers, or by
+// being doubled.
+func quotedEnd(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && quote != '`':
+			i++
+		case query[i] == quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
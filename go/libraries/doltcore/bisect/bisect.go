@@ -0,0 +1,231 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bisect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// ErrBadIsAncestorOfGood is returned when the commit marked bad is an ancestor of a commit marked good, which
+// means there is no range of history between them to search.
+var ErrBadIsAncestorOfGood = errors.New("the bad commit is an ancestor of a good commit")
+
+// Result describes the outcome of computing the next bisect step for a State.
+type Result struct {
+	// Next is the commit that should be tested next. It is empty when the bisect is waiting for a good or bad
+	// commit to be marked, or when the bisect has finished.
+	Next hash.Hash
+	// IsMergeBase is true when |Next| is the merge base of a good commit and the bad commit, which must be tested
+	// before the commits between them can be searched.
+	IsMergeBase bool
+	// Remaining is the number of commits that will be left to test after |Next| is tested.
+	Remaining int
+	// Steps is the approximate number of steps left after |Next| is tested.
+	Steps int
+	// FirstBad is the first bad commit, once the bisect has finished.
+	FirstBad hash.Hash
+	// Suspects is set when only skipped commits remain between the good and bad commits. In that case, the first
+	// bad commit is one of the commits listed.
+	Suspects []hash.Hash
+}
+
+// Waiting returns whether the bisect still needs a good or bad commit to be marked before it can make progress.
+func (r Result) Waiting() bool {
+	return r.Next.IsEmpty() && !r.Done()
+}
+
+// Done returns whether the bisect has finished searching.
+func (r Result) Done() bool {
+	return !r.FirstBad.IsEmpty() || len(r.Suspects) > 0
+}
+
+// Next computes the next step of the bisect described by |s|. The candidate commits are the commits reachable from
+// the bad commit that are not reachable from any good commit. The next commit to test is the candidate that splits
+// the candidates most evenly, counting the candidate ancestors of each commit so that merge commits and the
+// histories of all of their parents are accounted for.
+func Next(ctx context.Context, ddb *doltdb.DoltDB, s *State) (Result, error) {
+	if s.Bad == "" || len(s.Good) == 0 {
+		return Result{}, nil
+	}
+
+	badHash, badCommit, err := resolveHash(ctx, ddb, s.Bad)
+	if err != nil {
+		return Result{}, err
+	}
+
+	goodHashes := make([]hash.Hash, len(s.Good))
+	for i, g := range s.Good {
+		goodHash, goodCommit, err := resolveHash(ctx, ddb, g)
+		if err != nil {
+			return Result{}, err
+		}
+		goodHashes[i] = goodHash
+
+		// A good commit that isn't an ancestor of the bad commit doesn't bound the search on its own. The merge base
+		// of the two has to be good as well, otherwise the change we're looking for may lie outside the range.
+		mergeBase, err := merge.MergeBase(ctx, goodCommit, badCommit)
+		if err != nil {
+			return Result{}, err
+		}
+		if mergeBase == badHash {
+			return Result{}, ErrBadIsAncestorOfGood
+		}
+		if mergeBase != goodHash && !s.IsGood(mergeBase) && !s.IsSkipped(mergeBase) {
+			return Result{Next: mergeBase, IsMergeBase: true}, nil
+		}
+	}
+
+	candidates, err := loadCandidates(ctx, ddb, badHash, goodHashes)
+	if err != nil {
+		return Result{}, err
+	}
+
+	n := len(candidates.hashes)
+	if n <= 1 {
+		return Result{FirstBad: badHash}, nil
+	}
+
+	weights := candidates.ancestorCounts()
+	best, bestScore := -1, -1
+	for i, h := range candidates.hashes {
+		if h == badHash || s.IsSkipped(h) {
+			continue
+		}
+		score := weights[i]
+		if n-weights[i] < score {
+			score = n - weights[i]
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	if best < 0 {
+		suspects := make([]hash.Hash, 0, n)
+		for _, h := range candidates.hashes {
+			if h == badHash || s.IsSkipped(h) {
+				suspects = append(suspects, h)
+			}
+		}
+		return Result{Suspects: suspects}, nil
+	}
+
+	remaining := n - bestScore - 1
+	return Result{
+		Next:      candidates.hashes[best],
+		Remaining: remaining,
+		Steps:     bits.Len(uint(remaining)),
+	}, nil
+}
+
+// candidateSet holds the commits between the good and bad commits of a bisect, in reverse topological order, along
+// with the parents of each commit that are also in the set.
+type candidateSet struct {
+	hashes  []hash.Hash
+	parents [][]int
+}
+
+// loadCandidates walks the commit graph from |bad|, stopping at any commit reachable from |goods|.
+func loadCandidates(ctx context.Context, ddb *doltdb.DoltDB, bad hash.Hash, goods []hash.Hash) (*candidateSet, error) {
+	itr, err := commitwalk.GetDotDotRevisionsIterator(ctx, ddb, []hash.Hash{bad}, ddb, goods, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*doltdb.Commit
+	cs := &candidateSet{}
+	indexes := make(map[hash.Hash]int)
+	for {
+		h, optCmt, err := itr.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		cm, ok := optCmt.ToCommit()
+		if !ok {
+			return nil, doltdb.ErrGhostCommitEncountered
+		}
+
+		indexes[h] = len(cs.hashes)
+		cs.hashes = append(cs.hashes, h)
+		commits = append(commits, cm)
+	}
+
+	cs.parents = make([][]int, len(commits))
+	for i, cm := range commits {
+		parentHashes, err := cm.ParentHashes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ph := range parentHashes {
+			if j, ok := indexes[ph]; ok {
+				cs.parents[i] = append(cs.parents[i], j)
+			}
+		}
+	}
+
+	return cs, nil
+}
+
+// ancestorCounts returns, for each commit in the set, the number of commits in the set that it can reach, including
+// itself. Commits are ordered children first, so walking the set backwards visits every parent before its children.
+func (cs *candidateSet) ancestorCounts() []int {
+	n := len(cs.hashes)
+	words := (n + 63) / 64
+	reach := make([][]uint64, n)
+	counts := make([]int, n)
+
+	for i := n - 1; i >= 0; i-- {
+		set := make([]uint64, words)
+		set[i/64] |= 1 << (uint(i) % 64)
+		for _, p := range cs.parents[i] {
+			for w := range set {
+				set[w] |= reach[p][w]
+			}
+		}
+		reach[i] = set
+
+		for _, w := range set {
+			counts[i] += bits.OnesCount64(w)
+		}
+	}
+
+	return counts
+}
+
+func resolveHash(ctx context.Context, ddb *doltdb.DoltDB, s string) (hash.Hash, *doltdb.Commit, error) {
+	h, ok := hash.MaybeParse(s)
+	if !ok {
+		return hash.Hash{}, nil, fmt.Errorf("invalid commit hash in bisect state: %s", s)
+	}
+
+	cm, err := doltdb.HashToCommit(ctx, ddb.ValueReadWriter(), ddb.NodeStore(), h)
+	if err != nil {
+		return hash.Hash{}, nil, err
+	}
+
+	return h, cm, nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bisect
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	testHomeDir = "/doesnotexist/home"
+	workingDir  = "/doesnotexist/work"
+)
+
+type testRepo struct {
+	t    *testing.T
+	dEnv *env.DoltEnv
+	rvh  hash.Hash
+	ts   time.Time
+}
+
+func newTestRepo(t *testing.T) (*testRepo, *doltdb.Commit) {
+	ctx := context.Background()
+	fs := filesys.NewInMemFS([]string{testHomeDir, workingDir}, nil, workingDir)
+	dEnv := env.Load(ctx, func() (string, error) { return testHomeDir, nil }, fs, doltdb.InMemDoltDB, "test")
+	err := dEnv.InitRepo(ctx, types.Format_Default, "Bill Billerson", "bill@billerson.com", env.DefaultInitBranch)
+	require.NoError(t, err)
+
+	cs, err := doltdb.NewCommitSpec(env.DefaultInitBranch)
+	require.NoError(t, err)
+	opt, err := dEnv.DoltDB.Resolve(ctx, cs, nil)
+	require.NoError(t, err)
+	init, ok := opt.ToCommit()
+	require.True(t, ok)
+
+	rv, err := init.GetRootValue(ctx)
+	require.NoError(t, err)
+	_, rvh, err := dEnv.DoltDB.WriteRootValue(ctx, rv)
+	require.NoError(t, err)
+
+	return &testRepo{t: t, dEnv: dEnv, rvh: rvh, ts: time.Now()}, init
+}
+
+func (r *testRepo) commit(branch string, parents ...*doltdb.Commit) *doltdb.Commit {
+	r.ts = r.ts.Add(time.Second)
+	meta, err := datas.NewCommitMetaWithUserTS("Bill Billerson", "bill@billerson.com", "A New Commit.", r.ts)
+	require.NoError(r.t, err)
+
+	pcs := make([]*doltdb.CommitSpec, len(parents))
+	for i, p := range parents {
+		pcs[i], err = doltdb.NewCommitSpec(r.hashOf(p).String())
+		require.NoError(r.t, err)
+	}
+
+	cm, err := r.dEnv.DoltDB.CommitWithParentSpecs(context.Background(), r.rvh, ref.NewBranchRef(branch), pcs, meta)
+	require.NoError(r.t, err)
+	return cm
+}
+
+func (r *testRepo) hashOf(cm *doltdb.Commit) hash.Hash {
+	h, err := cm.HashOf()
+	require.NoError(r.t, err)
+	return h
+}
+
+// bisectUntilDone drives |s| to completion, marking each commit bad if it is in |bad|.
+func (r *testRepo) bisectUntilDone(s *State, bad map[hash.Hash]bool) (Result, int) {
+	ctx := context.Background()
+	for steps := 0; ; steps++ {
+		res, err := Next(ctx, r.dEnv.DoltDB, s)
+		require.NoError(r.t, err)
+		require.False(r.t, res.Waiting())
+		if res.Done() {
+			return res, steps
+		}
+		if bad[res.Next] {
+			s.MarkBad(res.Next)
+		} else {
+			s.MarkGood(res.Next)
+		}
+	}
+}
+
+func TestBisectLinearHistory(t *testing.T) {
+	r, init := newTestRepo(t)
+
+	commits := []*doltdb.Commit{init}
+	for i := 1; i < 16; i++ {
+		commits = append(commits, r.commit(env.DefaultInitBranch, commits[i-1]))
+	}
+
+	for firstBad := 1; firstBad < len(commits); firstBad++ {
+		bad := make(map[hash.Hash]bool)
+		for _, cm := range commits[firstBad:] {
+			bad[r.hashOf(cm)] = true
+		}
+
+		s := NewState(env.DefaultInitBranch)
+		s.MarkGood(r.hashOf(commits[0]))
+		s.MarkBad(r.hashOf(commits[len(commits)-1]))
+
+		res, steps := r.bisectUntilDone(s, bad)
+		assert.Equal(t, r.hashOf(commits[firstBad]), res.FirstBad)
+		assert.LessOrEqual(t, steps, 4)
+	}
+}
+
+func TestBisectMergeHistory(t *testing.T) {
+	r, init := newTestRepo(t)
+
+	// main:     init--M1--M2--M3--Merge--M4
+	//              \               /
+	// feature:      F1-----F2-----F3
+	m1 := r.commit(env.DefaultInitBranch, init)
+	f1 := r.commit("feature", m1)
+	m2 := r.commit(env.DefaultInitBranch, m1)
+	f2 := r.commit("feature", f1)
+	m3 := r.commit(env.DefaultInitBranch, m2)
+	f3 := r.commit("feature", f2)
+	merge := r.commit(env.DefaultInitBranch, m3, f3)
+	m4 := r.commit(env.DefaultInitBranch, merge)
+
+	// The bug was introduced on the feature branch, so every commit that has F2 as an ancestor is bad.
+	bad := map[hash.Hash]bool{
+		r.hashOf(f2):    true,
+		r.hashOf(f3):    true,
+		r.hashOf(merge): true,
+		r.hashOf(m4):    true,
+	}
+
+	s := NewState(env.DefaultInitBranch)
+	s.MarkGood(r.hashOf(init))
+	s.MarkBad(r.hashOf(m4))
+
+	res, _ := r.bisectUntilDone(s, bad)
+	assert.Equal(t, r.hashOf(f2), res.FirstBad)
+}
+
+func TestBisectGoodNotAncestorOfBad(t *testing.T) {
+	ctx := context.Background()
+	r, init := newTestRepo(t)
+
+	m1 := r.commit(env.DefaultInitBranch, init)
+	m2 := r.commit(env.DefaultInitBranch, m1)
+	f1 := r.commit("feature", m1)
+
+	s := NewState(env.DefaultInitBranch)
+	s.MarkGood(r.hashOf(f1))
+	s.MarkBad(r.hashOf(m2))
+
+	// The merge base of the good and bad commits has to be tested first
+	res, err := Next(ctx, r.dEnv.DoltDB, s)
+	require.NoError(t, err)
+	assert.True(t, res.IsMergeBase)
+	assert.Equal(t, r.hashOf(m1), res.Next)
+
+	s.MarkGood(r.hashOf(m1))
+	res, err = Next(ctx, r.dEnv.DoltDB, s)
+	require.NoError(t, err)
+	assert.Equal(t, r.hashOf(m2), res.FirstBad)
+
+	s = NewState(env.DefaultInitBranch)
+	s.MarkGood(r.hashOf(m2))
+	s.MarkBad(r.hashOf(m1))
+	_, err = Next(ctx, r.dEnv.DoltDB, s)
+	assert.ErrorIs(t, err, ErrBadIsAncestorOfGood)
+}
+
+func TestBisectSkip(t *testing.T) {
+	ctx := context.Background()
+	r, init := newTestRepo(t)
+
+	commits := []*doltdb.Commit{init}
+	for i := 1; i < 4; i++ {
+		commits = append(commits, r.commit(env.DefaultInitBranch, commits[i-1]))
+	}
+
+	s := NewState(env.DefaultInitBranch)
+	s.MarkGood(r.hashOf(commits[0]))
+	s.MarkBad(r.hashOf(commits[3]))
+	s.MarkSkip(r.hashOf(commits[1]))
+
+	res, err := Next(ctx, r.dEnv.DoltDB, s)
+	require.NoError(t, err)
+	assert.Equal(t, r.hashOf(commits[2]), res.Next)
+
+	s.MarkBad(r.hashOf(commits[2]))
+	res, err = Next(ctx, r.dEnv.DoltDB, s)
+	require.NoError(t, err)
+	assert.True(t, res.Done())
+	assert.ElementsMatch(t, []hash.Hash{r.hashOf(commits[1]), r.hashOf(commits[2])}, res.Suspects)
+}
+
+func TestBisectStatePersistence(t *testing.T) {
+	fs := filesys.NewInMemFS([]string{workingDir}, nil, workingDir)
+	require.NoError(t, fs.MkDirs(dbfactory.DoltDir))
+
+	_, err := LoadState(fs)
+	assert.ErrorIs(t, err, ErrNoBisectInProgress)
+	assert.False(t, InProgress(fs))
+
+	s := NewState("main")
+	s.MarkBad(hash.Of([]byte("bad")))
+	s.MarkGood(hash.Of([]byte("good")))
+	s.MarkSkip(hash.Of([]byte("skip")))
+	s.Current = hash.Of([]byte("current")).String()
+	require.NoError(t, s.Save(fs))
+	assert.True(t, InProgress(fs))
+
+	loaded, err := LoadState(fs)
+	require.NoError(t, err)
+	assert.Equal(t, s, loaded)
+
+	require.NoError(t, ClearState(fs))
+	assert.False(t, InProgress(fs))
+}
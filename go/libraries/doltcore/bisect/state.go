@@ -0,0 +1,163 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bisect
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	TermStart = "start"
+	TermGood  = "good"
+	TermBad   = "bad"
+	TermSkip  = "skip"
+)
+
+const stateFile = "bisect.json"
+
+// ErrNoBisectInProgress is returned when a bisect operation is attempted without first starting a bisect.
+var ErrNoBisectInProgress = errors.New("no bisect in progress; use dolt_bisect('start') to start one")
+
+// ErrBisectInProgress is returned when starting a bisect while another one is already in progress.
+var ErrBisectInProgress = errors.New("a bisect is already in progress; use dolt_bisect('reset') to end it first")
+
+// LogEntry records a single command applied to a bisect, so that the bisect can be inspected and replayed.
+type LogEntry struct {
+	Term   string `json:"term"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// State is the persisted state of a bisect. It is stored as a file in the .dolt directory of the repository, next to
+// repo_state.json, so that a bisect survives across CLI invocations and sql-server sessions.
+type State struct {
+	// Branch is the branch that was checked out when the bisect started.
+	Branch string `json:"branch"`
+	// Bad is the newest commit known to be bad.
+	Bad string `json:"bad,omitempty"`
+	// Good holds every commit known to be good.
+	Good []string `json:"good,omitempty"`
+	// Skip holds every commit that could not be tested.
+	Skip []string `json:"skip,omitempty"`
+	// Current is the commit that is currently being tested.
+	Current string     `json:"current,omitempty"`
+	Log     []LogEntry `json:"log,omitempty"`
+}
+
+// NewState returns a new State for a bisect started on |branch|.
+func NewState(branch string) *State {
+	return &State{
+		Branch: branch,
+		Log:    []LogEntry{{Term: TermStart}},
+	}
+}
+
+// LoadState loads the bisect state for the repository at the root of |fs|. If no bisect is in progress,
+// ErrNoBisectInProgress is returned.
+func LoadState(fs filesys.ReadableFS) (*State, error) {
+	path := getStateFile()
+	if exists, _ := fs.Exists(path); !exists {
+		return nil, ErrNoBisectInProgress
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s State
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// InProgress returns whether a bisect is in progress for the repository at the root of |fs|.
+func InProgress(fs filesys.ReadableFS) bool {
+	exists, _ := fs.Exists(getStateFile())
+	return exists
+}
+
+// Save writes this bisect state to the repository at the root of |fs|.
+func (s *State) Save(fs filesys.WritableFS) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fs.WriteFile(getStateFile(), data, os.ModePerm)
+}
+
+// ClearState removes any bisect state from the repository at the root of |fs|.
+func ClearState(fs filesys.ReadWriteFS) error {
+	path := getStateFile()
+	if exists, _ := fs.Exists(path); !exists {
+		return nil
+	}
+	return fs.DeleteFile(path)
+}
+
+// MarkGood records |h| as a good commit.
+func (s *State) MarkGood(h hash.Hash) {
+	if !s.IsGood(h) {
+		s.Good = append(s.Good, h.String())
+	}
+	s.Log = append(s.Log, LogEntry{Term: TermGood, Commit: h.String()})
+}
+
+// MarkBad records |h| as the bad commit. Only the most recently marked bad commit is kept, since any bad commit
+// replaces the previous one as the upper bound of the search.
+func (s *State) MarkBad(h hash.Hash) {
+	s.Bad = h.String()
+	s.Log = append(s.Log, LogEntry{Term: TermBad, Commit: h.String()})
+}
+
+// MarkSkip records |h| as a commit that could not be tested.
+func (s *State) MarkSkip(h hash.Hash) {
+	if !s.IsSkipped(h) {
+		s.Skip = append(s.Skip, h.String())
+	}
+	s.Log = append(s.Log, LogEntry{Term: TermSkip, Commit: h.String()})
+}
+
+// IsGood returns whether |h| has been marked good.
+func (s *State) IsGood(h hash.Hash) bool {
+	return contains(s.Good, h.String())
+}
+
+// IsSkipped returns whether |h| has been marked as skipped.
+func (s *State) IsSkipped(h hash.Hash) bool {
+	return contains(s.Skip, h.String())
+}
+
+func contains(hashes []string, h string) bool {
+	for _, s := range hashes {
+		if s == h {
+			return true
+		}
+	}
+	return false
+}
+
+func getStateFile() string {
+	return filepath.Join(dbfactory.DoltDir, stateFile)
+}
@@ -40,6 +40,7 @@ var (
 	ErrUpdatingToRow         = errors.NewKind("`%s`@`%s` cannot update the row [%q, %q, %q, %q] to the new branch expression [%q, %q]")
 	ErrDeletingRow           = errors.NewKind("`%s`@`%s` cannot delete the row [%q, %q, %q, %q]")
 	ErrMissingController     = errors.NewKind("a context has a non-nil session but is missing its branch controller")
+	ErrProtectedBranch       = errors.NewKind("branch `%s` is protected: %s")
 )
 
 // Context represents the interface that must be inherited from the context.
@@ -55,8 +56,9 @@ type Context interface {
 
 // Controller is the central hub for branch control functions. This is passed within a context.
 type Controller struct {
-	Access    *Access
-	Namespace *Namespace
+	Access     *Access
+	Namespace  *Namespace
+	Protection *Protection
 
 	Serialized atomic.Pointer[[]byte]
 
@@ -86,6 +88,7 @@ func LoadData(ctx context.Context, branchControlFilePath string, doltConfigDirPa
 	controller := &Controller{
 		Access:                accessTbl,
 		Namespace:             newNamespace(accessTbl),
+		Protection:            newProtection(),
 		branchControlFilePath: branchControlFilePath,
 		doltConfigDirPath:     doltConfigDirPath,
 	}
@@ -139,6 +142,10 @@ func (controller *Controller) LoadData(ctx context.Context, data []byte, isFirst
 	if err != nil {
 		return err
 	}
+	protection, err := bc.TryProtectionTbl(nil)
+	if err != nil {
+		return err
+	}
 
 	rollback := controller.Serialized.Load()
 
@@ -156,6 +163,11 @@ func (controller *Controller) LoadData(ctx context.Context, data []byte, isFirst
 		controller.LoadData(ctx, *rollback, isFirstLoad)
 		return err
 	}
+	if err = controller.Protection.Deserialize(protection); err != nil {
+		// TODO: More principaled rollback. Hopefully this does not fail.
+		controller.LoadData(ctx, *rollback, isFirstLoad)
+		return err
+	}
 
 	controller.Serialized.Store(&data)
 	if controller.SavedCallback != nil {
@@ -201,9 +213,20 @@ func (controller *Controller) SaveData(ctx context.Context, fs filesys.Filesys)
 	// The Serialize functions acquire read locks, so we don't acquire them here
 	accessOffset := controller.Access.Serialize(b)
 	namespaceOffset := controller.Namespace.Serialize(b)
+	// The protection table is only written when it has rows, so that versions that predate it can still read the file
+	controller.Protection.RWMutex.RLock()
+	hasProtection := len(controller.Protection.Values) > 0
+	controller.Protection.RWMutex.RUnlock()
+	var protectionOffset flatbuffers.UOffsetT
+	if hasProtection {
+		protectionOffset = controller.Protection.Serialize(b)
+	}
 	serial.BranchControlStart(b)
 	serial.BranchControlAddAccessTbl(b, accessOffset)
 	serial.BranchControlAddNamespaceTbl(b, namespaceOffset)
+	if hasProtection {
+		serial.BranchControlAddProtectionTbl(b, protectionOffset)
+	}
 	root := serial.BranchControlEnd(b)
 	// serial.FinishMessage() limits files to 2^24 bytes, so this works around it while maintaining read compatibility
 	b.Prep(1, flatbuffers.SizeInt32+4+serial.MessagePrefixSz)
@@ -314,6 +337,47 @@ func CanDeleteBranch(ctx context.Context, branchName string) error {
 	return ErrCannotDeleteBranch.New(user, host, branchName)
 }
 
+// CanUserDeleteBranch returns whether the given user and host can delete the branch with the given name in the given
+// database. Unlike CanDeleteBranch, the user is not the one of the context's session, which allows checking the
+// permissions of a user on whose behalf an operation runs, such as the creator of a branch retention policy. The
+// context only supplies the controller, so a context without a session allows the delete operation.
+func CanUserDeleteBranch(ctx context.Context, database, branchName, user, host string) error {
+	branchAwareSession := GetBranchAwareSession(ctx)
+	if branchAwareSession == nil {
+		return nil
+	}
+	controller := branchAwareSession.GetController()
+	if controller == nil {
+		return ErrMissingController.New()
+	}
+	controller.Access.RWMutex.RLock()
+	defer controller.Access.RWMutex.RUnlock()
+
+	_, perms := controller.Access.Match(database, branchName, user, host)
+	if (perms&Permissions_Write == Permissions_Write) || (perms&Permissions_Admin == Permissions_Admin) {
+		return nil
+	}
+	return ErrCannotDeleteBranch.New(user, host, branchName)
+}
+
+// GetProtectionRules returns the rules of the "dolt_branch_protection" table that protect the given branch of the given
+// database. Unlike the other checks of this package, the rules don't depend on the user of the context, which only
+// supplies the controller. A context without a session is not subject to any rules, as with the other checks.
+func GetProtectionRules(ctx context.Context, database string, branchName string) (ProtectionRules, error) {
+	branchAwareSession := GetBranchAwareSession(ctx)
+	if branchAwareSession == nil {
+		return ProtectionRules_None, nil
+	}
+	controller := branchAwareSession.GetController()
+	if controller == nil {
+		return ProtectionRules_None, ErrMissingController.New()
+	}
+	controller.Protection.RWMutex.RLock()
+	defer controller.Protection.RWMutex.RUnlock()
+
+	return controller.Protection.Match(database, branchName), nil
+}
+
 // AddAdminForContext adds an entry in the access table for the user represented by the given context. If the
 // context is missing some functionality that is needed to perform the addition, such as a user or the Controller, then
 // this simply returns.
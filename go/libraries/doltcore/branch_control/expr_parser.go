@@ -212,6 +212,17 @@ func Match(matchExprCollection []MatchExpression, str string, collation sql.Coll
 	return validMatches
 }
 
+// MatchesExpression returns whether the given string matches the given unparsed expression, using the same rules as
+// the expressions in the branch control tables. This is intended for matching against a single expression, such as
+// one that is stored outside of the branch control tables.
+func MatchesExpression(expr string, str string, collation sql.CollationID) bool {
+	matchExpr := MatchExpression{CollectionIndex: 0, SortOrders: ParseExpression(FoldExpression(expr), collation)}
+	matches := Match([]MatchExpression{matchExpr}, str, collation)
+	result := len(matches) > 0
+	indexPool.Put(matches)
+	return result
+}
+
 // Matches returns true when the given sort order matches the expectation of the calling match expression. Returns a
 // reduced match expression as `next`, which should take the place of the calling match function. In the event of a
 // branch, returns the branching match expression as `extra`.
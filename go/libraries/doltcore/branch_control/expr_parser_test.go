@@ -70,6 +70,7 @@ func TestSingleMatch(t *testing.T) {
 			} else {
 				require.Len(t, matchCount, 0)
 			}
+			require.Equal(t, test.matches, MatchesExpression(test.expression, test.testStr, test.collation))
 		})
 	}
 }
@@ -0,0 +1,160 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package branch_control
+
+import (
+	"strings"
+	"sync"
+
+	flatbuffers "github.com/dolthub/flatbuffers/v23/go"
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/gen/fb/serial"
+)
+
+// ProtectionRules are a set of flags that denote the rules protecting a branch. Unlike Permissions, the rules apply to
+// every user, including admins, as they describe how a branch may change rather than who may change it.
+type ProtectionRules uint64
+
+const (
+	ProtectionRules_NoDirectCommits   ProtectionRules = 1 << iota // ProtectionRules_NoDirectCommits only allows a branch to move to a merge commit, or to the head of another branch
+	ProtectionRules_FastForwardOnly                               // ProtectionRules_FastForwardOnly disallows merge commits on top of the head of a branch
+	ProtectionRules_MergeCommitsOnly                              // ProtectionRules_MergeCommitsOnly disallows fast-forwarding a branch to the head of another branch
+	ProtectionRules_VerifyConstraints                             // ProtectionRules_VerifyConstraints requires the new head of a branch to have no constraint violations
+	ProtectionRules_SignedCommits                                 // ProtectionRules_SignedCommits requires every commit added to a branch to be signed
+	ProtectionRules_NoForcePush                                   // ProtectionRules_NoForcePush requires the new head of a branch to descend from its old head
+	ProtectionRules_NoDelete                                      // ProtectionRules_NoDelete disallows deleting a branch
+
+	ProtectionRules_None ProtectionRules = 0 // ProtectionRules_None represents an unprotected branch
+)
+
+// Protection contains the rows of the "dolt_branch_protection" table, which declares the rules protecting branches.
+// There are few rows in practice, so unlike Access, the expressions are matched without building a match tree.
+type Protection struct {
+	RWMutex *sync.RWMutex
+	Values  []ProtectionValue
+}
+
+// ProtectionValue contains the user-facing values of a particular row of the Protection table.
+type ProtectionValue struct {
+	Database string
+	Branch   string
+	Rules    ProtectionRules
+}
+
+// newProtection returns a new Protection.
+func newProtection() *Protection {
+	return &Protection{
+		RWMutex: &sync.RWMutex{},
+	}
+}
+
+// Match returns the rules protecting the given branch of the given database, which are the rules of every row whose
+// expressions match them. Requires external synchronization handling, therefore manually manage the RWMutex.
+func (tbl *Protection) Match(database string, branch string) ProtectionRules {
+	rules := ProtectionRules_None
+	for _, val := range tbl.Values {
+		if MatchesExpression(val.Database, database, sql.Collation_utf8mb4_0900_ai_ci) &&
+			MatchesExpression(val.Branch, branch, sql.Collation_utf8mb4_0900_ai_ci) {
+			rules |= val.Rules
+		}
+	}
+	return rules
+}
+
+// Get returns the index of the row with exactly the given database and branch expressions, or -1 if there is none.
+// Requires external synchronization handling, therefore manually manage the RWMutex.
+func (tbl *Protection) Get(database string, branch string) int {
+	for i, val := range tbl.Values {
+		if strings.EqualFold(val.Database, database) && strings.EqualFold(val.Branch, branch) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Insert adds the given row to the table. The expressions are expected to have been folded, and to not already exist
+// in the table. Requires external synchronization handling, therefore manually manage the RWMutex.
+func (tbl *Protection) Insert(database string, branch string, rules ProtectionRules) {
+	tbl.Values = append(tbl.Values, ProtectionValue{
+		Database: database,
+		Branch:   branch,
+		Rules:    rules,
+	})
+}
+
+// Delete removes the row with exactly the given database and branch expressions, if it exists. Requires external
+// synchronization handling, therefore manually manage the RWMutex.
+func (tbl *Protection) Delete(database string, branch string) {
+	if i := tbl.Get(database, branch); i >= 0 {
+		tbl.Values = append(tbl.Values[:i], tbl.Values[i+1:]...)
+	}
+}
+
+// Serialize returns the offset for the Protection table written to the given builder.
+func (tbl *Protection) Serialize(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+	tbl.RWMutex.RLock()
+	defer tbl.RWMutex.RUnlock()
+
+	valueOffsets := make([]flatbuffers.UOffsetT, len(tbl.Values))
+	for i, val := range tbl.Values {
+		valueOffsets[i] = val.Serialize(b)
+	}
+	serial.BranchControlProtectionStartValuesVector(b, len(valueOffsets))
+	for i := len(valueOffsets) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(valueOffsets[i])
+	}
+	values := b.EndVector(len(valueOffsets))
+	serial.BranchControlProtectionStart(b)
+	serial.BranchControlProtectionAddValues(b, values)
+	return serial.BranchControlProtectionEnd(b)
+}
+
+// Deserialize populates the table with the data from the flatbuffers representation. A nil representation, which is
+// written by versions that predate the table, leaves the table empty.
+func (tbl *Protection) Deserialize(fb *serial.BranchControlProtection) error {
+	tbl.RWMutex.Lock()
+	defer tbl.RWMutex.Unlock()
+
+	tbl.Values = nil
+	if fb == nil {
+		return nil
+	}
+	for i := 0; i < fb.ValuesLength(); i++ {
+		serialProtectionValue := &serial.BranchControlProtectionValue{}
+		_, err := fb.TryValues(serialProtectionValue, i)
+		if err != nil {
+			return err
+		}
+		tbl.Values = append(tbl.Values, ProtectionValue{
+			Database: string(serialProtectionValue.Database()),
+			Branch:   string(serialProtectionValue.Branch()),
+			Rules:    ProtectionRules(serialProtectionValue.Rules()),
+		})
+	}
+	return nil
+}
+
+// Serialize returns the offset for the ProtectionValue written to the given builder.
+func (val *ProtectionValue) Serialize(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+	database := b.CreateSharedString(val.Database)
+	branch := b.CreateSharedString(val.Branch)
+
+	serial.BranchControlProtectionValueStart(b)
+	serial.BranchControlProtectionValueAddDatabase(b, database)
+	serial.BranchControlProtectionValueAddBranch(b, branch)
+	serial.BranchControlProtectionValueAddRules(b, uint64(val.Rules))
+	return serial.BranchControlProtectionValueEnd(b)
+}
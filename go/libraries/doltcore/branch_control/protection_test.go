@@ -0,0 +1,68 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package branch_control
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+func TestProtectionMatch(t *testing.T) {
+	tbl := newProtection()
+	tbl.Insert("%", "main", ProtectionRules_NoDelete)
+	tbl.Insert("mydb", "main", ProtectionRules_NoForcePush)
+	tbl.Insert("mydb", "release/%", ProtectionRules_SignedCommits)
+
+	assert.Equal(t, ProtectionRules_NoDelete|ProtectionRules_NoForcePush, tbl.Match("mydb", "main"))
+	assert.Equal(t, ProtectionRules_NoDelete|ProtectionRules_NoForcePush, tbl.Match("MyDb", "MAIN"))
+	assert.Equal(t, ProtectionRules_NoDelete, tbl.Match("otherdb", "main"))
+	assert.Equal(t, ProtectionRules_SignedCommits, tbl.Match("mydb", "release/1.0"))
+	assert.Equal(t, ProtectionRules_None, tbl.Match("mydb", "feature"))
+
+	assert.Equal(t, 1, tbl.Get("MYDB", "main"))
+	assert.Equal(t, -1, tbl.Get("mydb", "release/1.0"))
+	tbl.Delete("mydb", "main")
+	assert.Equal(t, ProtectionRules_NoDelete, tbl.Match("mydb", "main"))
+}
+
+func TestProtectionSerialization(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "branch_control.db")
+
+	controller, err := LoadData(ctx, path, dir)
+	require.NoError(t, err)
+	require.NoError(t, controller.SaveData(ctx, filesys.LocalFS))
+
+	// A controller without protected branches is written without the protection table
+	loaded, err := LoadData(ctx, path, dir)
+	require.NoError(t, err)
+	assert.Empty(t, loaded.Protection.Values)
+
+	controller.Protection.Insert("mydb", "main", ProtectionRules_NoDirectCommits|ProtectionRules_NoDelete)
+	controller.Protection.Insert("%", "release/%", ProtectionRules_SignedCommits)
+	require.NoError(t, controller.SaveData(ctx, filesys.LocalFS))
+
+	loaded, err = LoadData(ctx, path, dir)
+	require.NoError(t, err)
+	assert.Equal(t, controller.Protection.Values, loaded.Protection.Values)
+	assert.Equal(t, ProtectionRules_NoDirectCommits|ProtectionRules_NoDelete, loaded.Protection.Match("mydb", "main"))
+}
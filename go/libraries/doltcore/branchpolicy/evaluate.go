@@ -0,0 +1,197 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package branchpolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// Candidate is a ref that a policy deletes.
+type Candidate struct {
+	Policy  Policy
+	RefType string
+	Name    string
+	// Hash is the commit the ref points to.
+	Hash hash.Hash
+	Time time.Time
+}
+
+// namedRef is a branch or a tag, with the commit it points to and its age.
+type namedRef struct {
+	name   string
+	hash   hash.Hash
+	time   time.Time
+	commit *doltdb.Commit
+}
+
+// Evaluate returns the refs of |ddb| that |policies| delete at the time |now|, in the order of the policies and, for
+// each policy, oldest first. A ref deleted by several policies is returned once, for the first of them. The branch that
+// a policy requires refs to be merged into is never deleted by that policy.
+func Evaluate(ctx context.Context, ddb *doltdb.DoltDB, policies Policies, now time.Time) ([]Candidate, error) {
+	var branches, tags []namedRef
+	var err error
+	for _, p := range policies {
+		if p.RefType == RefTypeBranch && branches == nil {
+			if branches, err = getBranches(ctx, ddb); err != nil {
+				return nil, err
+			}
+		} else if p.RefType == RefTypeTag && tags == nil {
+			if tags, err = getTags(ctx, ddb); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var res []Candidate
+	for _, p := range policies {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		refs := branches
+		if p.RefType == RefTypeTag {
+			refs = tags
+		}
+		candidates, err := evaluatePolicy(ctx, ddb, p, refs, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range candidates {
+			key := c.RefType + "/" + c.Name
+			if !seen[key] {
+				seen[key] = true
+				res = append(res, c)
+			}
+		}
+	}
+	return res, nil
+}
+
+func evaluatePolicy(ctx context.Context, ddb *doltdb.DoltDB, p Policy, refs []namedRef, now time.Time) ([]Candidate, error) {
+	var matching []namedRef
+	for _, r := range refs {
+		if p.Matches(r.name) {
+			matching = append(matching, r)
+		}
+	}
+	// newest first, so that the refs kept by KeepLast come first
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].time.After(matching[j].time) })
+	if p.KeepLast > 0 {
+		if len(matching) <= p.KeepLast {
+			return nil, nil
+		}
+		matching = matching[p.KeepLast:]
+	}
+
+	var target *doltdb.Commit
+	if p.MergedInto != "" {
+		var err error
+		target, err = ddb.ResolveCommitRef(ctx, ref.NewBranchRef(p.MergedInto))
+		if err == doltdb.ErrBranchNotFound {
+			// nothing can be merged into a branch that doesn't exist
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	var res []Candidate
+	for i := len(matching) - 1; i >= 0; i-- {
+		r := matching[i]
+		if p.MaxAge > 0 && now.Sub(r.time) <= p.MaxAge {
+			continue
+		}
+		if target != nil {
+			if strings.EqualFold(r.name, p.MergedInto) {
+				continue
+			}
+			merged, err := isMerged(ctx, r.commit, target)
+			if err != nil {
+				return nil, err
+			}
+			if !merged {
+				continue
+			}
+		}
+		res = append(res, Candidate{Policy: p, RefType: p.RefType, Name: r.name, Hash: r.hash, Time: r.time})
+	}
+	return res, nil
+}
+
+// isMerged returns whether |c| is an ancestor of |target|, or |target| itself.
+func isMerged(ctx context.Context, c, target *doltdb.Commit) (bool, error) {
+	optAnc, err := doltdb.GetCommitAncestor(ctx, c, target)
+	if err == doltdb.ErrNoCommonAncestor {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	ancestor, ok := optAnc.ToCommit()
+	if !ok {
+		return false, doltdb.ErrGhostCommitEncountered
+	}
+	if ancestor == nil {
+		return false, nil
+	}
+	ancestorHash, err := ancestor.HashOf()
+	if err != nil {
+		return false, err
+	}
+	h, err := c.HashOf()
+	if err != nil {
+		return false, err
+	}
+	return ancestorHash == h, nil
+}
+
+func getBranches(ctx context.Context, ddb *doltdb.DoltDB) ([]namedRef, error) {
+	branches, err := ddb.GetBranchesWithHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]namedRef, 0, len(branches))
+	for _, b := range branches {
+		c, err := ddb.ResolveCommitRef(ctx, b.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve branch %s: %w", b.Ref.GetPath(), err)
+		}
+		meta, err := c.GetCommitMeta(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, namedRef{name: b.Ref.GetPath(), hash: b.Hash, time: meta.Time(), commit: c})
+	}
+	return res, nil
+}
+
+func getTags(ctx context.Context, ddb *doltdb.DoltDB) ([]namedRef, error) {
+	tags, err := ddb.GetTagsWithHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]namedRef, 0, len(tags))
+	for _, t := range tags {
+		res = append(res, namedRef{name: t.Tag.Name, hash: t.Hash, time: time.UnixMilli(t.Tag.Meta.UserTimestamp), commit: t.Tag.Commit})
+	}
+	return res, nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package branchpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const (
+	// RefTypeBranch is the ref type of policies that delete branches.
+	RefTypeBranch = "branch"
+	// RefTypeTag is the ref type of policies that delete tags.
+	RefTypeTag = "tag"
+)
+
+const policiesFile = "branch_policies.json"
+
+// Policy is a retention policy for the branches or tags of a database. Every branch or tag whose name matches Pattern
+// is deleted once it meets all the conditions the policy sets: being merged into MergedInto, being older than MaxAge,
+// and not being one of the KeepLast newest refs that match Pattern. A policy must set at least one condition.
+type Policy struct {
+	Name string `json:"name"`
+	// RefType is the type of ref the policy deletes, either RefTypeBranch or RefTypeTag.
+	RefType string `json:"ref_type"`
+	// Pattern is a glob matched against the names of refs, in which '*' matches any sequence of characters, including
+	// '/', and '?' matches any single character.
+	Pattern string `json:"pattern"`
+	// MergedInto is the branch a ref must be merged into to be deleted, if set.
+	MergedInto string `json:"merged_into,omitempty"`
+	// MaxAge is the age a ref must be older than to be deleted, if set. The age of a branch is that of its head commit,
+	// and the age of a tag is that of the tag itself.
+	MaxAge time.Duration `json:"max_age,omitempty"`
+	// KeepLast is the number of the newest refs matching Pattern that are never deleted, if set.
+	KeepLast int `json:"keep_last,omitempty"`
+	// User and Host are the user and host that created the policy. The policy only deletes branches that they are
+	// allowed to delete by the dolt_branch_control table.
+	User string `json:"user"`
+	Host string `json:"host"`
+}
+
+// Validate returns an error if the policy is not valid.
+func (p Policy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("a branch policy requires a name")
+	}
+	if p.RefType != RefTypeBranch && p.RefType != RefTypeTag {
+		return fmt.Errorf("branch policy '%s' has unknown ref_type '%s'; expected one of %s, %s", p.Name, p.RefType, RefTypeBranch, RefTypeTag)
+	}
+	if p.Pattern == "" {
+		return fmt.Errorf("branch policy '%s' requires a pattern", p.Name)
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("branch policy '%s' has a negative max_age", p.Name)
+	}
+	if p.KeepLast < 0 {
+		return fmt.Errorf("branch policy '%s' has a negative keep_last", p.Name)
+	}
+	if p.MergedInto == "" && p.MaxAge == 0 && p.KeepLast == 0 {
+		return fmt.Errorf("branch policy '%s' requires at least one of merged_into, max_age or keep_last", p.Name)
+	}
+	if p.MergedInto != "" && p.RefType != RefTypeBranch {
+		return fmt.Errorf("branch policy '%s': merged_into can only be set for %s policies", p.Name, RefTypeBranch)
+	}
+	return nil
+}
+
+// Matches returns whether |name| matches the pattern of the policy.
+func (p Policy) Matches(name string) bool {
+	return globToRegexp(p.Pattern).MatchString(name)
+}
+
+func globToRegexp(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// ParseMaxAge parses a max age such as "7d", "12h" or "90m". In addition to the units of time.ParseDuration, it
+// accepts "d" for days and "w" for weeks.
+func ParseMaxAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid max_age '%s'", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_age '%s'", s)
+	}
+	return d, nil
+}
+
+// FormatMaxAge formats |d| as ParseMaxAge parses it, in days when it is a whole number of days.
+func FormatMaxAge(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
+
+// Policies are the retention policies of a database, ordered by name.
+type Policies []Policy
+
+// Get returns the policy named |name|, ignoring case.
+func (ps Policies) Get(name string) (Policy, bool) {
+	for _, p := range ps {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Policy{}, false
+}
+
+// Put returns the policies with |p| added, replacing the policy with the same name, if any.
+func (ps Policies) Put(p Policy) Policies {
+	res := ps.Remove(p.Name)
+	res = append(res, p)
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// Remove returns the policies without the policy named |name|, ignoring case.
+func (ps Policies) Remove(name string) Policies {
+	res := make(Policies, 0, len(ps))
+	for _, p := range ps {
+		if !strings.EqualFold(p.Name, name) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// Load loads the policies of the repository at the root of |fs|. A repository without policies has none.
+func Load(fs filesys.ReadableFS) (Policies, error) {
+	path := getPoliciesFile()
+	if exists, _ := fs.Exists(path); !exists {
+		return nil, nil
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps Policies
+	if err = json.Unmarshal(data, &ps); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	return ps, nil
+}
+
+// Save writes |ps| as the policies of the repository at the root of |fs|.
+func Save(fs filesys.WritableFS, ps Policies) error {
+	if ps == nil {
+		ps = Policies{}
+	}
+	data, err := json.MarshalIndent(ps, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fs.WriteFile(getPoliciesFile(), data, os.ModePerm)
+}
+
+func getPoliciesFile() string {
+	return filepath.Join(dbfactory.DoltDir, policiesFile)
+}
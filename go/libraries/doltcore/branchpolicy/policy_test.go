@@ -0,0 +1,228 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package branchpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	testHomeDir = "/doesnotexist/home"
+	workingDir  = "/doesnotexist/work"
+)
+
+const day = 24 * time.Hour
+
+func TestPolicyMatches(t *testing.T) {
+	p := Policy{Pattern: "ci/*"}
+	assert.True(t, p.Matches("ci/123"))
+	assert.True(t, p.Matches("ci/pr/123"))
+	assert.False(t, p.Matches("main"))
+	assert.False(t, p.Matches("xci/123"))
+
+	p = Policy{Pattern: "v1.?"}
+	assert.True(t, p.Matches("v1.2"))
+	assert.False(t, p.Matches("v1.23"))
+	assert.False(t, p.Matches("v1x2"))
+}
+
+func TestParseMaxAge(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"7d":   7 * day,
+		"2w":   14 * day,
+		"12h":  12 * time.Hour,
+		"1.5d": 36 * time.Hour,
+		"90m":  90 * time.Minute,
+	} {
+		d, err := ParseMaxAge(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, d, s)
+	}
+	_, err := ParseMaxAge("seven days")
+	assert.Error(t, err)
+
+	assert.Equal(t, "7d", FormatMaxAge(7*day))
+	assert.Equal(t, "12h0m0s", FormatMaxAge(12*time.Hour))
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.NoError(t, Policy{Name: "ci", RefType: RefTypeBranch, Pattern: "ci/*", MergedInto: "main"}.Validate())
+	assert.NoError(t, Policy{Name: "releases", RefType: RefTypeTag, Pattern: "v*", KeepLast: 3}.Validate())
+	assert.Error(t, Policy{Name: "ci", RefType: RefTypeBranch, Pattern: "ci/*"}.Validate())
+	assert.Error(t, Policy{Name: "ci", RefType: "remote", Pattern: "ci/*", KeepLast: 1}.Validate())
+	assert.Error(t, Policy{Name: "ci", RefType: RefTypeBranch, KeepLast: 1}.Validate())
+	assert.Error(t, Policy{Name: "releases", RefType: RefTypeTag, Pattern: "v*", MergedInto: "main"}.Validate())
+}
+
+func TestLoadAndSave(t *testing.T) {
+	fs := filesys.NewInMemFS([]string{workingDir}, nil, workingDir)
+	ps, err := Load(fs)
+	require.NoError(t, err)
+	assert.Empty(t, ps)
+
+	ps = ps.Put(Policy{Name: "ci", RefType: RefTypeBranch, Pattern: "ci/*", MaxAge: 7 * day, User: "root", Host: "localhost"})
+	ps = ps.Put(Policy{Name: "alpha", RefType: RefTypeTag, Pattern: "v*", KeepLast: 2})
+	require.NoError(t, Save(fs, ps))
+
+	loaded, err := Load(fs)
+	require.NoError(t, err)
+	assert.Equal(t, ps, loaded)
+	assert.Equal(t, "alpha", loaded[0].Name)
+
+	_, ok := loaded.Get("CI")
+	assert.True(t, ok)
+	assert.Len(t, loaded.Remove("CI"), 1)
+}
+
+type testRepo struct {
+	t    *testing.T
+	dEnv *env.DoltEnv
+	rvh  hash.Hash
+	init *doltdb.Commit
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	ctx := context.Background()
+	fs := filesys.NewInMemFS([]string{testHomeDir, workingDir}, nil, workingDir)
+	dEnv := env.Load(ctx, func() (string, error) { return testHomeDir, nil }, fs, doltdb.InMemDoltDB, "test")
+	err := dEnv.InitRepo(ctx, types.Format_Default, "Bill Billerson", "bill@billerson.com", env.DefaultInitBranch)
+	require.NoError(t, err)
+
+	cs, err := doltdb.NewCommitSpec(env.DefaultInitBranch)
+	require.NoError(t, err)
+	opt, err := dEnv.DoltDB.Resolve(ctx, cs, nil)
+	require.NoError(t, err)
+	init, ok := opt.ToCommit()
+	require.True(t, ok)
+
+	rv, err := init.GetRootValue(ctx)
+	require.NoError(t, err)
+	_, rvh, err := dEnv.DoltDB.WriteRootValue(ctx, rv)
+	require.NoError(t, err)
+
+	return &testRepo{t: t, dEnv: dEnv, rvh: rvh, init: init}
+}
+
+// commit commits to |branch| at the time |ts|, on top of |parents|.
+func (r *testRepo) commit(branch string, ts time.Time, parents ...*doltdb.Commit) *doltdb.Commit {
+	meta, err := datas.NewCommitMetaWithUserTS("Bill Billerson", "bill@billerson.com", "A New Commit.", ts)
+	require.NoError(r.t, err)
+	pcs := make([]*doltdb.CommitSpec, len(parents))
+	for i, p := range parents {
+		h, err := p.HashOf()
+		require.NoError(r.t, err)
+		pcs[i], err = doltdb.NewCommitSpec(h.String())
+		require.NoError(r.t, err)
+	}
+
+	cm, err := r.dEnv.DoltDB.CommitWithParentSpecs(context.Background(), r.rvh, ref.NewBranchRef(branch), pcs, meta)
+	require.NoError(r.t, err)
+	return cm
+}
+
+func (r *testRepo) tag(name string, c *doltdb.Commit, ts time.Time) {
+	meta := datas.NewTagMetaWithUserTS("Bill Billerson", "bill@billerson.com", "A New Tag.", ts)
+	require.NoError(r.t, r.dEnv.DoltDB.NewTagAtCommit(context.Background(), ref.NewTagRef(name), c, meta))
+}
+
+func candidateNames(candidates []Candidate) []string {
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestEvaluate(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	now := time.Now()
+
+	main := r.commit(env.DefaultInitBranch, now.Add(-20*day), r.init)
+	// ci/merged and ci/old-merged are merged into main, ci/unmerged and ci/recent are not
+	merged := r.commit("ci/merged", now.Add(-10*day), main)
+	oldMerged := r.commit("ci/old-merged", now.Add(-15*day), main)
+	r.commit("ci/unmerged", now.Add(-10*day), main)
+	r.commit("ci/recent", now.Add(-time.Hour), merged)
+	r.commit("feature", now.Add(-30*day), main)
+	r.commit(env.DefaultInitBranch, now.Add(-time.Hour), merged, oldMerged)
+
+	ciPolicy := Policy{Name: "ci", RefType: RefTypeBranch, Pattern: "ci/*", MergedInto: env.DefaultInitBranch, MaxAge: 7 * day}
+	candidates, err := Evaluate(ctx, r.dEnv.DoltDB, Policies{ciPolicy}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci/old-merged", "ci/merged"}, candidateNames(candidates))
+
+	ageOnly := Policy{Name: "old", RefType: RefTypeBranch, Pattern: "*", MaxAge: 12 * day}
+	candidates, err = Evaluate(ctx, r.dEnv.DoltDB, Policies{ageOnly}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature", "ci/old-merged"}, candidateNames(candidates))
+
+	// a ref deleted by two policies is returned once
+	candidates, err = Evaluate(ctx, r.dEnv.DoltDB, Policies{ciPolicy, ageOnly}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci/old-merged", "ci/merged", "feature"}, candidateNames(candidates))
+
+	// the branch refs must be merged into is never deleted
+	intoMain := Policy{Name: "merged", RefType: RefTypeBranch, Pattern: "*", MergedInto: env.DefaultInitBranch}
+	candidates, err = Evaluate(ctx, r.dEnv.DoltDB, Policies{intoMain}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci/old-merged", "ci/merged"}, candidateNames(candidates))
+
+	intoMissing := Policy{Name: "missing", RefType: RefTypeBranch, Pattern: "*", MergedInto: "missing"}
+	candidates, err = Evaluate(ctx, r.dEnv.DoltDB, Policies{intoMissing}, now)
+	require.NoError(t, err)
+	assert.Empty(t, candidates)
+}
+
+func TestEvaluateKeepLast(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	now := time.Now()
+
+	for i, name := range []string{"v1", "v2", "v3", "v4"} {
+		r.tag(name, r.init, now.Add(time.Duration(i-10)*day))
+	}
+	r.tag("other", r.init, now.Add(-30*day))
+
+	keep := Policy{Name: "releases", RefType: RefTypeTag, Pattern: "v*", KeepLast: 2}
+	candidates, err := Evaluate(ctx, r.dEnv.DoltDB, Policies{keep}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1", "v2"}, candidateNames(candidates))
+	assert.Equal(t, RefTypeTag, candidates[0].RefType)
+
+	// conditions combine, so only the refs beyond the newest two that are also old enough are deleted
+	keep.MaxAge = 9*day + time.Hour
+	candidates, err = Evaluate(ctx, r.dEnv.DoltDB, Policies{keep}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, candidateNames(candidates))
+
+	keep = Policy{Name: "releases", RefType: RefTypeTag, Pattern: "v*", KeepLast: 10}
+	candidates, err = Evaluate(ctx, r.dEnv.DoltDB, Policies{keep}, now)
+	require.NoError(t, err)
+	assert.Empty(t, candidates)
+}
@@ -0,0 +1,387 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	cdcapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/cdcapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/cdc"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/store/datas"
+)
+
+const (
+	dbName  = "test"
+	timeout = 5 * time.Second
+)
+
+func newTestEnv(t *testing.T) (*env.DoltEnv, *cdc.Hub) {
+	dEnv := sqle.CreateTestEnv()
+	t.Cleanup(func() { dEnv.DoltDB.Close() })
+	hub := cdc.NewHub()
+	t.Cleanup(hub.Close)
+	pub := hub.AddDatabase(dbName, dEnv.DoltDB)
+	dEnv.DoltDB.SetCommitHooks(context.Background(), []doltdb.CommitHook{pub})
+	return dEnv, hub
+}
+
+func headRoot(t *testing.T, dEnv *env.DoltEnv) doltdb.RootValue {
+	ctx := context.Background()
+	head, err := dEnv.DoltDB.ResolveCommitRef(ctx, ref.NewBranchRef(env.DefaultInitBranch))
+	require.NoError(t, err)
+	root, err := head.GetRootValue(ctx)
+	require.NoError(t, err)
+	return root
+}
+
+// commitSql commits the result of running |query| on the head of the default branch, and returns the hash of the
+// new commit.
+func commitSql(t *testing.T, dEnv *env.DoltEnv, query string) string {
+	ctx := context.Background()
+	ddb := dEnv.DoltDB
+	root := executeSql(t, dEnv, query)
+	_, rootHash, err := ddb.WriteRootValue(ctx, root)
+	require.NoError(t, err)
+
+	cs, err := doltdb.NewCommitSpec(env.DefaultInitBranch)
+	require.NoError(t, err)
+	meta, err := datas.NewCommitMeta("Bill Billerson", "bill@billerson.com", query)
+	require.NoError(t, err)
+	cm, err := ddb.CommitWithParentSpecs(ctx, rootHash, ref.NewBranchRef(env.DefaultInitBranch), []*doltdb.CommitSpec{cs}, meta)
+	require.NoError(t, err)
+	h, err := cm.HashOf()
+	require.NoError(t, err)
+	return h.String()
+}
+
+// executeSql runs the statements of |statements|, which are split by `;\n`, on the working set of the default
+// branch, and returns the resulting root.
+func executeSql(t *testing.T, dEnv *env.DoltEnv, statements string) doltdb.RootValue {
+	ctx := context.Background()
+	tmpDir, err := dEnv.TempTableFilesDir()
+	require.NoError(t, err)
+	opts := editor.Options{Deaf: dEnv.DbEaFactory(), Tempdir: tmpDir}
+	db, err := sqle.NewDatabase(ctx, "dolt", dEnv.DbData(), opts)
+	require.NoError(t, err)
+	engine, sqlCtx, err := sqle.NewTestEngine(dEnv, ctx, db)
+	require.NoError(t, err)
+	require.NoError(t, sqlCtx.Session.SetSessionVariable(sqlCtx, sql.AutoCommitSessionVar, false))
+
+	for _, query := range strings.Split(statements, ";\n") {
+		if strings.TrimSpace(query) == "" {
+			continue
+		}
+		_, iter, _, err := engine.Query(sqlCtx, query)
+		require.NoError(t, err)
+		_, err = sql.RowIterToRows(sqlCtx, iter)
+		require.NoError(t, err)
+	}
+	require.NoError(t, dsess.DSessFromSess(sqlCtx.Session).CommitTransaction(sqlCtx, sqlCtx.GetTransaction()))
+	root, err := db.GetRoot(sqlCtx)
+	require.NoError(t, err)
+	return root
+}
+
+// subscribe starts a subscription to |req| and returns the channel its events are sent to, and a function that ends
+// it and returns the error it ended with.
+func subscribe(t *testing.T, hub *cdc.Hub, req cdc.Request) (<-chan cdc.Event, func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan cdc.Event, 1024)
+	done := make(chan error, 1)
+	go func() {
+		done <- hub.Subscribe(ctx, req, func(e cdc.Event) error {
+			events <- e
+			return nil
+		})
+	}()
+	var once sync.Once
+	var err error
+	stop := func() error {
+		once.Do(func() {
+			cancel()
+			select {
+			case err = <-done:
+			case <-time.After(timeout):
+				t.Fatal("subscription did not end")
+			}
+		})
+		return err
+	}
+	t.Cleanup(func() { _ = stop() })
+	return events, stop
+}
+
+// receiveCommit receives the events of the next commit from |events|, including its Commit event.
+func receiveCommit(t *testing.T, events <-chan cdc.Event) []cdc.Event {
+	var ret []cdc.Event
+	for {
+		select {
+		case e := <-events:
+			ret = append(ret, e)
+			if e.Type == cdc.Commit {
+				return ret
+			}
+		case <-time.After(timeout):
+			t.Fatalf("timed out waiting for events, received %v", ret)
+		}
+	}
+}
+
+// awaitSubscribed gives a subscription that starts from the current state of its branch time to resolve it.
+func awaitSubscribed() {
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestDiffRoots(t *testing.T) {
+	dEnv, _ := newTestEnv(t)
+	commitSql(t, dEnv, `create table t (pk int primary key, c varchar(20));
+insert into t values (1, 'one'), (2, 'two');
+create table keyless (c int);
+insert into keyless values (7), (7);
+create table untouched (pk int primary key);`)
+	from := headRoot(t, dEnv)
+	commitSql(t, dEnv, `update t set c = 'uno' where pk = 1;
+delete from t where pk = 2;
+insert into t values (3, null);
+insert into keyless values (7);`)
+	to := headRoot(t, dEnv)
+
+	ctx := sql.NewEmptyContext()
+	var events []cdc.Event
+	err := cdc.DiffRoots(ctx, from, to, nil, func(e cdc.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	assert.Equal(t, cdc.Insert, events[0].Type)
+	assert.Equal(t, "keyless", events[0].Table)
+	assert.Nil(t, events[0].Before)
+	assert.Equal(t, cdc.Row{"c": int32(7)}, events[0].After)
+
+	assert.Equal(t, cdc.Update, events[1].Type)
+	assert.Equal(t, "t", events[1].Table)
+	assert.Equal(t, cdc.Row{"pk": int32(1), "c": "one"}, events[1].Before)
+	assert.Equal(t, cdc.Row{"pk": int32(1), "c": "uno"}, events[1].After)
+	assert.NotEmpty(t, events[1].SchemaVersion)
+
+	assert.Equal(t, cdc.Delete, events[2].Type)
+	assert.Equal(t, cdc.Row{"pk": int32(2), "c": "two"}, events[2].Before)
+	assert.Nil(t, events[2].After)
+
+	assert.Equal(t, cdc.Insert, events[3].Type)
+	assert.Equal(t, cdc.Row{"pk": int32(3), "c": nil}, events[3].After)
+
+	events = nil
+	err = cdc.DiffRoots(ctx, from, to, []string{"keyless"}, func(e cdc.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "keyless", events[0].Table)
+}
+
+func TestSubscribeBranch(t *testing.T) {
+	dEnv, hub := newTestEnv(t)
+	first := commitSql(t, dEnv, `create table t (pk int primary key, c int);
+insert into t values (1, 1);`)
+
+	second := commitSql(t, dEnv, "insert into t values (2, 2), (3, 3);")
+
+	// A subscription resumed from a cursor starts with every commit after it, and then follows the branch.
+	events, stop := subscribe(t, hub, cdc.Request{Database: dbName, Branch: env.DefaultInitBranch, Cursor: first})
+	got := receiveCommit(t, events)
+	require.Len(t, got, 3)
+	for i, e := range got {
+		assert.Equal(t, dbName, e.Database)
+		assert.Equal(t, env.DefaultInitBranch, e.Branch)
+		assert.Equal(t, second, e.Commit)
+		assert.Equal(t, uint64(i), e.Seq)
+	}
+	assert.Equal(t, cdc.Row{"pk": int32(2), "c": int32(2)}, got[0].After)
+	assert.Equal(t, cdc.Row{"pk": int32(3), "c": int32(3)}, got[1].After)
+	assert.Equal(t, cdc.Commit, got[2].Type)
+	assert.Equal(t, second, got[2].Cursor)
+
+	third := commitSql(t, dEnv, "delete from t where pk = 1;")
+	got = receiveCommit(t, events)
+	require.Len(t, got, 2)
+	assert.Equal(t, cdc.Delete, got[0].Type)
+	assert.Equal(t, third, got[1].Cursor)
+	require.ErrorIs(t, stop(), context.Canceled)
+
+	events, _ = subscribe(t, hub, cdc.Request{Database: dbName, Branch: env.DefaultInitBranch, Cursor: second})
+	got = receiveCommit(t, events)
+	require.Len(t, got, 2)
+	assert.Equal(t, third, got[0].Commit)
+	assert.Equal(t, cdc.Row{"pk": int32(1), "c": int32(1)}, got[0].Before)
+}
+
+func TestSubscribeWorkingSet(t *testing.T) {
+	dEnv, hub := newTestEnv(t)
+	ctx := context.Background()
+	commitSql(t, dEnv, "create table t (pk int primary key, c int);")
+	require.NoError(t, dEnv.UpdateWorkingRoot(ctx, headRoot(t, dEnv)))
+
+	events, _ := subscribe(t, hub, cdc.Request{Database: dbName, Branch: env.DefaultInitBranch, WorkingSet: true})
+	awaitSubscribed()
+
+	working, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+	working, err = sqle.ExecuteSql(dEnv, working, "insert into t values (1, 1);")
+	require.NoError(t, err)
+	require.NoError(t, dEnv.UpdateWorkingRoot(ctx, working))
+
+	got := receiveCommit(t, events)
+	require.Len(t, got, 2)
+	assert.Equal(t, cdc.Insert, got[0].Type)
+	assert.Equal(t, cdc.Row{"pk": int32(1), "c": int32(1)}, got[0].After)
+	h, err := working.HashOf()
+	require.NoError(t, err)
+	assert.Equal(t, h.String(), got[1].Commit)
+	assert.Empty(t, got[1].Cursor)
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	dEnv, hub := newTestEnv(t)
+	commitSql(t, dEnv, "create table t (pk int primary key);")
+	ctx := context.Background()
+	noop := func(cdc.Event) error { return nil }
+
+	err := hub.Subscribe(ctx, cdc.Request{Database: "nope", Branch: env.DefaultInitBranch}, noop)
+	assert.ErrorIs(t, err, cdc.ErrDatabaseNotFound)
+	err = hub.Subscribe(ctx, cdc.Request{Database: dbName, Branch: "nope"}, noop)
+	assert.ErrorIs(t, err, cdc.ErrBranchNotFound)
+	err = hub.Subscribe(ctx, cdc.Request{Database: dbName, Branch: env.DefaultInitBranch, Cursor: "nope"}, noop)
+	assert.ErrorIs(t, err, cdc.ErrInvalidRequest)
+	err = hub.Subscribe(ctx, cdc.Request{Database: dbName, Branch: env.DefaultInitBranch, Cursor: "0123456789abcdefghijklmnopqrstuv"}, noop)
+	assert.ErrorIs(t, err, cdc.ErrCursorNotFound)
+	err = hub.Subscribe(ctx, cdc.Request{Database: dbName, Branch: env.DefaultInitBranch, Cursor: "0123456789abcdefghijklmnopqrstuv", WorkingSet: true}, noop)
+	assert.ErrorIs(t, err, cdc.ErrInvalidRequest)
+
+	hub.Close()
+	err = hub.Subscribe(ctx, cdc.Request{Database: dbName, Branch: env.DefaultInitBranch}, noop)
+	assert.NoError(t, err)
+}
+
+func TestHttp(t *testing.T) {
+	dEnv, hub := newTestEnv(t)
+	first := commitSql(t, dEnv, "create table t (pk int primary key, c varchar(10));")
+	second := commitSql(t, dEnv, "insert into t values (1, 'a');")
+
+	srv := httptest.NewServer(cdc.HttpInterceptor(hub, nil)(http.NotFoundHandler()))
+	t.Cleanup(srv.Close)
+
+	get := func(params url.Values) *http.Response {
+		resp, err := http.Get(srv.URL + cdc.HttpPath + "?" + params.Encode())
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get(url.Values{"database": {dbName}, "branch": {env.DefaultInitBranch}, "cursor": {first}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	lines := bufio.NewScanner(resp.Body)
+	var got []map[string]interface{}
+	for len(got) < 2 && lines.Scan() {
+		var e map[string]interface{}
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &e))
+		got = append(got, e)
+	}
+	require.Len(t, got, 2)
+	assert.Equal(t, "insert", got[0]["type"])
+	assert.Equal(t, map[string]interface{}{"pk": float64(1), "c": "a"}, got[0]["after"])
+	assert.Equal(t, "commit", got[1]["type"])
+	assert.Equal(t, second, got[1]["cursor"])
+
+	resp = get(url.Values{"database": {"nope"}, "branch": {env.DefaultInitBranch}})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = get(url.Values{"database": {dbName}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	other, err := http.Get(srv.URL + "/other")
+	require.NoError(t, err)
+	defer other.Body.Close()
+	_, _ = io.Copy(io.Discard, other.Body)
+	assert.Equal(t, http.StatusNotFound, other.StatusCode)
+}
+
+func TestGrpc(t *testing.T) {
+	dEnv, hub := newTestEnv(t)
+	first := commitSql(t, dEnv, "create table t (pk int primary key, c int);")
+	second := commitSql(t, dEnv, "insert into t values (1, 2);")
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	cdcapi.RegisterChangeDataCaptureServiceServer(srv, cdc.NewServer(hub))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := cdcapi.NewChangeDataCaptureServiceClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Subscribe(ctx, &cdcapi.SubscribeRequest{Database: dbName, Branch: env.DefaultInitBranch, Cursor: first})
+	require.NoError(t, err)
+	e, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, cdcapi.ChangeType_CHANGE_TYPE_INSERT, e.Type)
+	assert.Equal(t, "t", e.Table)
+	assert.Nil(t, e.Before)
+	assert.Equal(t, map[string]interface{}{"pk": float64(1), "c": float64(2)}, e.After.AsMap())
+	e, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, cdcapi.ChangeType_CHANGE_TYPE_COMMIT, e.Type)
+	assert.Equal(t, second, e.Cursor)
+
+	stream, err = client.Subscribe(ctx, &cdcapi.SubscribeRequest{Database: "nope", Branch: env.DefaultInitBranch})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,266 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// ChangeType is the kind of change an Event describes.
+type ChangeType string
+
+const (
+	Insert ChangeType = "insert"
+	Update ChangeType = "update"
+	Delete ChangeType = "delete"
+	// Commit marks the end of the events for a commit, or for a working set update.
+	Commit ChangeType = "commit"
+)
+
+// Row is the image of a row, keyed by column name. NULL values are nil, integer and floating point values are Go
+// numbers, binary values are base64 encoded strings and all other values are strings in their SQL representation.
+type Row map[string]interface{}
+
+// Event is a single entry of a change stream. Every inserted, updated and deleted row of a commit has its own event,
+// and the events of a commit are followed by a Commit event, whose Cursor resumes the stream after that commit.
+type Event struct {
+	Database string `json:"database"`
+	Branch   string `json:"branch"`
+	// Commit is the hash of the commit that made the change. For working set subscriptions, it is the hash of the
+	// working root after the change.
+	Commit string `json:"commit"`
+	// Seq is the position of the event among the events of Commit.
+	Seq  uint64     `json:"seq"`
+	Type ChangeType `json:"type"`
+	// Table is the table of the changed row. It is empty for Commit events.
+	Table string `json:"table,omitempty"`
+	// SchemaVersion is the hash of the schema of Table that After, or Before for deletes, conforms to.
+	SchemaVersion string `json:"schema_version,omitempty"`
+	Before        Row    `json:"before,omitempty"`
+	After         Row    `json:"after,omitempty"`
+	// Cursor is only set on the Commit events of branch subscriptions.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// DiffRoots calls |cb| with an Event for every row that differs between |from| and |to|, ordered by table name and
+// then by primary key. If |tables| is not empty, only changes to the tables named in it are reported. Only the Type,
+// Table, SchemaVersion, Before and After fields of the events are set.
+func DiffRoots(ctx *sql.Context, from, to doltdb.RootValue, tables []string, cb func(Event) error) error {
+	deltas, err := diff.GetTableDeltas(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].CurName() < deltas[j].CurName()
+	})
+
+	for _, td := range deltas {
+		if !includesTable(tables, td.CurName()) {
+			continue
+		}
+		if changed, err := td.HasHashChanged(); err != nil {
+			return err
+		} else if !changed {
+			continue
+		}
+		if err = diffTable(ctx, td, cb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func includesTable(tables []string, name string) bool {
+	if len(tables) == 0 {
+		return true
+	}
+	for _, t := range tables {
+		if strings.EqualFold(t, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableSide is one side of the diff of a table.
+type tableSide struct {
+	rows    prolly.Map
+	sch     schema.Schema
+	conv    dtables.ProllyRowConverter
+	version string
+}
+
+func loadTableSide(ctx *sql.Context, tbl *doltdb.Table, sch schema.Schema) (tableSide, error) {
+	side := tableSide{sch: schema.EmptySchema}
+	if tbl == nil {
+		return side, nil
+	}
+
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return tableSide{}, err
+	}
+	h, err := tbl.GetSchemaHash(ctx)
+	if err != nil {
+		return tableSide{}, err
+	}
+	side.rows = durable.ProllyMapFromIndex(idx)
+	side.sch = sch
+	side.version = h.String()
+	side.conv, err = dtables.NewProllyRowConverter(sch, sch, ctx.Warn, tbl.NodeStore())
+	if err != nil {
+		return tableSide{}, err
+	}
+	return side, nil
+}
+
+func diffTable(ctx *sql.Context, td diff.TableDelta, cb func(Event) error) error {
+	from, err := loadTableSide(ctx, td.FromTable, td.FromSch)
+	if err != nil {
+		return err
+	}
+	to, err := loadTableSide(ctx, td.ToTable, td.ToSch)
+	if err != nil {
+		return err
+	}
+
+	name := td.CurName()
+	if td.FromTable != nil && td.ToTable != nil && td.HasPrimaryKeySetChanged() {
+		// rows of the two sides cannot be matched by key, so report every row as deleted and then inserted
+		if err = diffRows(ctx, name, from, tableSide{sch: schema.EmptySchema}, cb); err != nil {
+			return err
+		}
+		return diffRows(ctx, name, tableSide{sch: schema.EmptySchema}, to, cb)
+	}
+	return diffRows(ctx, name, from, to, cb)
+}
+
+func diffRows(ctx *sql.Context, name string, from, to tableSide, cb func(Event) error) error {
+	keyless := schema.IsKeyless(from.sch) && schema.IsKeyless(to.sch)
+	err := prolly.DiffMaps(ctx, from.rows, to.rows, false, func(_ context.Context, d tree.Diff) error {
+		n := uint64(1)
+		if keyless {
+			d, n = keylessDiff(d)
+		}
+
+		e := Event{Table: name}
+		var err error
+		switch d.Type {
+		case tree.AddedDiff:
+			e.Type = Insert
+		case tree.ModifiedDiff:
+			e.Type = Update
+		case tree.RemovedDiff:
+			e.Type = Delete
+		}
+		if e.Type != Insert {
+			e.SchemaVersion = from.version
+			if e.Before, err = rowImage(ctx, from, val.Tuple(d.Key), val.Tuple(d.From)); err != nil {
+				return err
+			}
+		}
+		if e.Type != Delete {
+			e.SchemaVersion = to.version
+			if e.After, err = rowImage(ctx, to, val.Tuple(d.Key), val.Tuple(d.To)); err != nil {
+				return err
+			}
+		}
+
+		for i := uint64(0); i < n; i++ {
+			if err = cb(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// keylessDiff turns a diff of a keyless row into the equivalent number of inserts or deletes, since keyless rows
+// are never updated, only have their cardinality changed.
+func keylessDiff(d tree.Diff) (tree.Diff, uint64) {
+	switch d.Type {
+	case tree.AddedDiff:
+		return d, val.ReadKeylessCardinality(val.Tuple(d.To))
+	case tree.RemovedDiff:
+		return d, val.ReadKeylessCardinality(val.Tuple(d.From))
+	default:
+		fN := val.ReadKeylessCardinality(val.Tuple(d.From))
+		tN := val.ReadKeylessCardinality(val.Tuple(d.To))
+		if fN < tN {
+			d.Type = tree.AddedDiff
+			return d, tN - fN
+		}
+		d.Type = tree.RemovedDiff
+		return d, fN - tN
+	}
+}
+
+func rowImage(ctx *sql.Context, side tableSide, key, value val.Tuple) (Row, error) {
+	cols := side.sch.GetAllCols()
+	r := make(sql.Row, cols.Size())
+	if err := side.conv.PutConverted(ctx, key, value, r); err != nil {
+		return nil, err
+	}
+
+	img := make(Row, len(r))
+	for i, col := range cols.GetColumns() {
+		v, err := jsonValue(ctx, col.TypeInfo.ToSqlType(), r[i])
+		if err != nil {
+			return nil, err
+		}
+		img[col.Name] = v
+	}
+	return img, nil
+}
+
+// jsonValue converts |v| of type |typ| into a value that can be represented in JSON and in a google.protobuf.Value.
+func jsonValue(ctx *sql.Context, typ sql.Type, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, string, int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64:
+		return v, nil
+	}
+
+	sqlVal, err := typ.SQL(ctx, nil, v)
+	if err != nil {
+		return nil, err
+	}
+	if sqlVal.IsNull() {
+		return nil, nil
+	}
+	if sqltypes.IsBinary(sqlVal.Type()) {
+		return base64.StdEncoding.EncodeToString(sqlVal.Raw()), nil
+	}
+	return sqlVal.ToString(), nil
+}
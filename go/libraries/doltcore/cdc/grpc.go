@@ -0,0 +1,106 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	cdcapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/cdcapi/v1alpha1"
+)
+
+// SubscribeRpcMethod is the full name of the Subscribe method of the ChangeDataCaptureService.
+const SubscribeRpcMethod = "/dolt.services.cdcapi.v1alpha1.ChangeDataCaptureService/Subscribe"
+
+// Server implements the ChangeDataCaptureService gRPC service on top of a Hub.
+type Server struct {
+	cdcapi.UnimplementedChangeDataCaptureServiceServer
+	hub *Hub
+}
+
+var _ cdcapi.ChangeDataCaptureServiceServer = (*Server)(nil)
+
+func NewServer(hub *Hub) *Server {
+	return &Server{hub: hub}
+}
+
+func (s *Server) Subscribe(req *cdcapi.SubscribeRequest, stream cdcapi.ChangeDataCaptureService_SubscribeServer) error {
+	r := Request{
+		Database:   req.Database,
+		Branch:     req.Branch,
+		Cursor:     req.Cursor,
+		WorkingSet: req.WorkingSet,
+		Tables:     req.Tables,
+	}
+	err := s.hub.Subscribe(stream.Context(), r, func(e Event) error {
+		msg, err := e.toProto()
+		if err != nil {
+			return err
+		}
+		return stream.Send(msg)
+	})
+	return grpcError(err)
+}
+
+func grpcError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrDatabaseNotFound), errors.Is(err, ErrBranchNotFound), errors.Is(err, ErrCursorNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return err
+	}
+}
+
+var changeTypes = map[ChangeType]cdcapi.ChangeType{
+	Insert: cdcapi.ChangeType_CHANGE_TYPE_INSERT,
+	Update: cdcapi.ChangeType_CHANGE_TYPE_UPDATE,
+	Delete: cdcapi.ChangeType_CHANGE_TYPE_DELETE,
+	Commit: cdcapi.ChangeType_CHANGE_TYPE_COMMIT,
+}
+
+func (e Event) toProto() (*cdcapi.ChangeEvent, error) {
+	msg := &cdcapi.ChangeEvent{
+		Database:      e.Database,
+		Branch:        e.Branch,
+		Commit:        e.Commit,
+		Seq:           e.Seq,
+		Type:          changeTypes[e.Type],
+		Table:         e.Table,
+		SchemaVersion: e.SchemaVersion,
+		Cursor:        e.Cursor,
+	}
+	var err error
+	if e.Before != nil {
+		if msg.Before, err = structpb.NewStruct(e.Before); err != nil {
+			return nil, err
+		}
+	}
+	if e.After != nil {
+		if msg.After, err = structpb.NewStruct(e.After); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
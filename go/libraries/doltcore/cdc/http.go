@@ -0,0 +1,137 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
+)
+
+// HttpPath is the path of the endpoint that streams changes as newline-delimited JSON. The stream is described by
+// the query parameters database, branch, cursor, working_set and table, which can be repeated, with the same meaning
+// as the fields of Request.
+const HttpPath = "/cdc/v1alpha1/subscribe"
+
+const ndjsonContentType = "application/x-ndjson"
+
+// HttpInterceptor returns an interceptor for remotesrv.ServerArgs that serves HttpPath from |hub|, and passes every
+// other request to the intercepted handler. Requests are authenticated and authorized with |ac| in the same way as
+// read requests to the remotesapi gRPC service.
+func HttpInterceptor(hub *Hub, ac remotesrv.AccessControl) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != HttpPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+			serveHttp(hub, ac, w, r)
+		})
+	}
+}
+
+func serveHttp(hub *Hub, ac remotesrv.AccessControl, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if ac != nil {
+		if status, err := authorizeHttp(ac, r); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+
+	q := r.URL.Query()
+	req := Request{
+		Database: q.Get("database"),
+		Branch:   q.Get("branch"),
+		Cursor:   q.Get("cursor"),
+		Tables:   q["table"],
+	}
+	if ws := q.Get("working_set"); ws != "" {
+		var err error
+		if req.WorkingSet, err = strconv.ParseBool(ws); err != nil {
+			http.Error(w, "invalid working_set parameter: "+ws, http.StatusBadRequest)
+			return
+		}
+	}
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	started := false
+	err := hub.Subscribe(r.Context(), req, func(e Event) error {
+		if !started {
+			w.Header().Set("Content-Type", ndjsonContentType)
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		if e.Type == Commit && flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil && !started {
+		http.Error(w, err.Error(), httpStatus(err))
+	}
+}
+
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrDatabaseNotFound), errors.Is(err, ErrBranchNotFound), errors.Is(err, ErrCursorNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidRequest):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// authorizeHttp checks the basic auth credentials of |r| with |ac|, by presenting them to it the way the gRPC server
+// does. It returns the HTTP status to respond with if they are not accepted.
+func authorizeHttp(ac remotesrv.AccessControl, r *http.Request) (int, error) {
+	md := metadata.MD{}
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		md.Set("authorization", auth)
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+	addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+
+	ctx, err = ac.ApiAuthenticate(ctx)
+	if err != nil {
+		return http.StatusUnauthorized, err
+	}
+	if ok, err := ac.ApiAuthorize(ctx, false); !ok {
+		if err == nil {
+			err = errors.New("not authorized")
+		}
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
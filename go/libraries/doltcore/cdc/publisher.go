@@ -0,0 +1,477 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+var ErrDatabaseNotFound = errors.New("database not found")
+var ErrBranchNotFound = errors.New("branch not found")
+var ErrCursorNotFound = errors.New("cursor not found")
+var ErrInvalidRequest = errors.New("invalid subscription request")
+
+// workingSetBacklog is the number of working set updates buffered for a subscriber that has not processed the
+// previous ones yet. Updates that do not fit are coalesced into the next change the subscriber sees.
+const workingSetBacklog = 64
+
+// Request describes a change stream.
+type Request struct {
+	Database string
+	Branch   string
+	// Cursor, if set, is the hash of a commit of Branch. The stream starts with the changes made by every commit after
+	// it. Otherwise, the stream starts with the next commit to Branch.
+	Cursor string
+	// WorkingSet streams the changes made to the working set of Branch by each transaction commit, instead of the
+	// changes made by commits to Branch. Working set streams cannot be resumed, so Cursor must be empty.
+	WorkingSet bool
+	// Tables limits the stream to changes to these tables, if not empty.
+	Tables []string
+}
+
+// Hub routes subscriptions to the Publisher of each database.
+type Hub struct {
+	mu     sync.Mutex
+	pubs   map[string]*Publisher
+	done   chan struct{}
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		pubs: make(map[string]*Publisher),
+		done: make(chan struct{}),
+	}
+}
+
+// AddDatabase creates the Publisher for the database |name|, which must be installed as a commit hook of |ddb|.
+func (h *Hub) AddDatabase(name string, ddb *doltdb.DoltDB) *Publisher {
+	p := &Publisher{
+		dbName: name,
+		ddb:    ddb,
+		subs:   make(map[*subscription]struct{}),
+		done:   h.done,
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pubs[strings.ToLower(name)] = p
+	return p
+}
+
+// RemoveDatabase stops routing subscriptions to the database |name|. Existing subscriptions are not affected.
+func (h *Hub) RemoveDatabase(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.pubs, strings.ToLower(name))
+}
+
+// Close ends every subscription, and causes new subscriptions to end immediately.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+}
+
+// Subscribe streams the changes described by |req| to |send| until |ctx| is canceled, the hub is closed, or an error
+// occurs. It returns nil if the hub was closed.
+func (h *Hub) Subscribe(ctx context.Context, req Request, send func(Event) error) error {
+	h.mu.Lock()
+	p, ok := h.pubs[strings.ToLower(req.Database)]
+	h.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrDatabaseNotFound, req.Database)
+	}
+	return p.Subscribe(ctx, req, send)
+}
+
+// Publisher is a doltdb.CommitHook that wakes the subscribers to the change streams of a database whenever a branch
+// head or working set they watch is updated. Each subscriber computes the changes it sends itself, starting from its
+// own cursor, so a slow subscriber never holds up commits or other subscribers.
+type Publisher struct {
+	dbName string
+	ddb    *doltdb.DoltDB
+	done   <-chan struct{}
+
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+	out  io.Writer
+}
+
+var _ doltdb.CommitHook = (*Publisher)(nil)
+
+type subscription struct {
+	// datasetID is the ID of the branch or working set dataset the subscription watches.
+	datasetID string
+	// notify is signaled when the dataset is updated.
+	notify chan struct{}
+	// roots receives the working root after each update of a working set dataset.
+	roots chan doltdb.RootValue
+}
+
+func (p *Publisher) subscribe(datasetID string, workingSet bool) *subscription {
+	sub := &subscription{
+		datasetID: datasetID,
+		notify:    make(chan struct{}, 1),
+	}
+	if workingSet {
+		sub.roots = make(chan doltdb.RootValue, workingSetBacklog)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subs[sub] = struct{}{}
+	return sub
+}
+
+func (p *Publisher) unsubscribe(sub *subscription) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.subs, sub)
+}
+
+func (p *Publisher) subscribers(datasetID string) []*subscription {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ret []*subscription
+	for sub := range p.subs {
+		if sub.datasetID == datasetID {
+			ret = append(ret, sub)
+		}
+	}
+	return ret
+}
+
+// Execute implements CommitHook
+func (p *Publisher) Execute(ctx context.Context, ds datas.Dataset, db datas.Database) (func(context.Context) error, error) {
+	subs := p.subscribers(ds.ID())
+	if len(subs) == 0 {
+		return nil, nil
+	}
+
+	var root doltdb.RootValue
+	if ref.IsWorkingSet(ds.ID()) {
+		ws, err := p.ddb.ResolveWorkingSet(ctx, ref.NewWorkingSetRef(ds.ID()))
+		if err != nil {
+			return nil, err
+		}
+		root = ws.WorkingRoot()
+	}
+
+	for _, sub := range subs {
+		if sub.roots != nil && root != nil {
+			select {
+			case sub.roots <- root:
+				continue
+			default:
+			}
+		}
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil, nil
+}
+
+// HandleError implements CommitHook
+func (p *Publisher) HandleError(ctx context.Context, err error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.out != nil {
+		fmt.Fprintf(p.out, "error notifying change data capture subscribers of database %s: %v\n", p.dbName, err)
+	}
+	return nil
+}
+
+// SetLogger implements CommitHook
+func (p *Publisher) SetLogger(ctx context.Context, wr io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.out = wr
+	return nil
+}
+
+func (*Publisher) ExecuteForWorkingSets() bool {
+	return true
+}
+
+// Subscribe streams the changes described by |req| to |send| until |ctx| is canceled, the hub of the publisher is
+// closed, or an error occurs. It returns nil if the hub was closed.
+func (p *Publisher) Subscribe(ctx context.Context, req Request, send func(Event) error) error {
+	if req.Branch == "" {
+		return fmt.Errorf("%w: branch is required", ErrInvalidRequest)
+	}
+	if req.WorkingSet {
+		if req.Cursor != "" {
+			return fmt.Errorf("%w: working set subscriptions cannot be resumed from a cursor", ErrInvalidRequest)
+		}
+		return p.subscribeWorkingSet(ctx, req, send)
+	}
+	return p.subscribeBranch(ctx, req, send)
+}
+
+func (p *Publisher) subscribeBranch(ctx context.Context, req Request, send func(Event) error) error {
+	branchRef := ref.NewBranchRef(req.Branch)
+	sub := p.subscribe(branchRef.String(), false)
+	defer p.unsubscribe(sub)
+
+	head, err := p.resolveBranch(ctx, branchRef)
+	if err != nil {
+		return err
+	}
+	cursor := head
+	if req.Cursor != "" {
+		if cursor, err = p.resolveCursor(ctx, req.Cursor); err != nil {
+			return err
+		}
+	}
+
+	sqlCtx := sql.NewContext(ctx)
+	for {
+		if head, err = p.resolveBranch(ctx, branchRef); err != nil {
+			return err
+		}
+		cursorHash, err := cursor.HashOf()
+		if err != nil {
+			return err
+		}
+		headHash, err := head.HashOf()
+		if err != nil {
+			return err
+		}
+		if cursorHash != headHash {
+			if err = p.sendCommits(sqlCtx, req, cursor, head, send); err != nil {
+				return err
+			}
+			cursor = head
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.done:
+			return nil
+		case <-sub.notify:
+		}
+	}
+}
+
+func (p *Publisher) resolveBranch(ctx context.Context, branchRef ref.BranchRef) (*doltdb.Commit, error) {
+	head, err := p.ddb.ResolveCommitRef(ctx, branchRef)
+	if errors.Is(err, doltdb.ErrBranchNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrBranchNotFound, branchRef.GetPath())
+	}
+	return head, err
+}
+
+func (p *Publisher) resolveCursor(ctx context.Context, cursor string) (*doltdb.Commit, error) {
+	h, ok := hash.MaybeParse(cursor)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a commit hash", ErrInvalidRequest, cursor)
+	}
+	optCmt, err := p.ddb.ReadCommit(ctx, h)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCursorNotFound, cursor)
+	}
+	cm, ok := optCmt.ToCommit()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCursorNotFound, cursor)
+	}
+	return cm, nil
+}
+
+// sendCommits sends the changes made by each commit on the first-parent path from |cursor| to |head|. If |cursor| is
+// not a first-parent ancestor of |head|, because the branch was reset or force pushed, it sends the changes between
+// the two commits as if they were made by |head|.
+func (p *Publisher) sendCommits(ctx *sql.Context, req Request, cursor, head *doltdb.Commit, send func(Event) error) error {
+	commits, err := firstParentPath(ctx, cursor, head)
+	if err != nil {
+		return err
+	}
+	if commits == nil {
+		return p.sendCommit(ctx, req, cursor, head, send)
+	}
+
+	parent := cursor
+	for _, cm := range commits {
+		if err = p.sendCommit(ctx, req, parent, cm, send); err != nil {
+			return err
+		}
+		parent = cm
+	}
+	return nil
+}
+
+// firstParentPath returns the commits on the first-parent path from |from|, exclusive, to |to|, inclusive, oldest
+// first. It returns nil if |from| is not on the first-parent path of |to|.
+func firstParentPath(ctx context.Context, from, to *doltdb.Commit) ([]*doltdb.Commit, error) {
+	fromHash, err := from.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	fromHeight, err := from.Height()
+	if err != nil {
+		return nil, err
+	}
+
+	var path []*doltdb.Commit
+	for cm := to; ; {
+		h, err := cm.HashOf()
+		if err != nil {
+			return nil, err
+		}
+		if h == fromHash {
+			break
+		}
+		height, err := cm.Height()
+		if err != nil {
+			return nil, err
+		}
+		if height <= fromHeight || cm.NumParents() == 0 {
+			return nil, nil
+		}
+		path = append(path, cm)
+
+		optCmt, err := cm.GetParent(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if cm, ok = optCmt.ToCommit(); !ok {
+			return nil, nil
+		}
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// sendCommit sends the changes between |parent| and |cm|, followed by the Commit event for |cm|.
+func (p *Publisher) sendCommit(ctx *sql.Context, req Request, parent, cm *doltdb.Commit, send func(Event) error) error {
+	from, err := parent.GetRootValue(ctx)
+	if err != nil {
+		return err
+	}
+	to, err := cm.GetRootValue(ctx)
+	if err != nil {
+		return err
+	}
+	h, err := cm.HashOf()
+	if err != nil {
+		return err
+	}
+	return p.sendChanges(ctx, req, from, to, h.String(), h.String(), send)
+}
+
+func (p *Publisher) subscribeWorkingSet(ctx context.Context, req Request, send func(Event) error) error {
+	wsRef, err := ref.WorkingSetRefForHead(ref.NewBranchRef(req.Branch))
+	if err != nil {
+		return err
+	}
+	sub := p.subscribe(wsRef.String(), true)
+	defer p.unsubscribe(sub)
+
+	prev, err := p.resolveWorkingRoot(ctx, wsRef, req.Branch)
+	if err != nil {
+		return err
+	}
+
+	sqlCtx := sql.NewContext(ctx)
+	for {
+		var root doltdb.RootValue
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.done:
+			return nil
+		case root = <-sub.roots:
+		case <-sub.notify:
+			// updates were dropped because the backlog was full, so catch up to the current working root
+			for len(sub.roots) > 0 {
+				<-sub.roots
+			}
+			if root, err = p.resolveWorkingRoot(ctx, wsRef, req.Branch); err != nil {
+				return err
+			}
+		}
+
+		h, err := root.HashOf()
+		if err != nil {
+			return err
+		}
+		prevHash, err := prev.HashOf()
+		if err != nil {
+			return err
+		}
+		if h == prevHash {
+			continue
+		}
+		if err = p.sendChanges(sqlCtx, req, prev, root, h.String(), "", send); err != nil {
+			return err
+		}
+		prev = root
+	}
+}
+
+func (p *Publisher) resolveWorkingRoot(ctx context.Context, wsRef ref.WorkingSetRef, branch string) (doltdb.RootValue, error) {
+	ws, err := p.ddb.ResolveWorkingSet(ctx, wsRef)
+	if errors.Is(err, doltdb.ErrWorkingSetNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrBranchNotFound, branch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ws.WorkingRoot(), nil
+}
+
+// sendChanges sends an event for each row that differs between |from| and |to|, followed by a Commit event.
+func (p *Publisher) sendChanges(ctx *sql.Context, req Request, from, to doltdb.RootValue, commit, cursor string, send func(Event) error) error {
+	var seq uint64
+	err := DiffRoots(ctx, from, to, req.Tables, func(e Event) error {
+		e.Database = p.dbName
+		e.Branch = req.Branch
+		e.Commit = commit
+		e.Seq = seq
+		seq++
+		return send(e)
+	})
+	if err != nil {
+		return err
+	}
+	return send(Event{
+		Database: p.dbName,
+		Branch:   req.Branch,
+		Commit:   commit,
+		Seq:      seq,
+		Type:     Commit,
+		Cursor:   cursor,
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 )
 
@@ -324,9 +325,19 @@ func cherryPick(ctx *sql.Context, dSess *dsess.DoltSession, roots doltdb.Roots,
 		return nil, "", sql.ErrDatabaseNotFound.New(dbName)
 	}
 
+	fs, err := dSess.Provider().FileSystemForDatabase(dbName)
+	if err != nil {
+		return nil, "", err
+	}
+	resolutions, err := rerere.Load(fs)
+	if err != nil {
+		return nil, "", err
+	}
+
 	mo := merge.MergeOpts{
 		IsCherryPick:        true,
 		KeepSchemaConflicts: false,
+		Resolutions:         resolutions,
 	}
 	result, err := merge.MergeRoots(ctx, roots.Working, cherryRoot, parentRoot, cherryCommit, parentCommit, dbState.EditOpts(), mo)
 	if err != nil {
@@ -141,12 +141,18 @@ func (fact FileFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat,
 		_, useJournal = params[ChunkJournalParam]
 	}
 
+	// storage is encrypted at rest if an encryption key is configured
+	keys, err := nbs.EncryptionKeysFromEnv()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	var newGenSt *nbs.NomsBlockStore
 	q := nbs.NewUnlimitedMemQuotaProvider()
 	if useJournal && chunkJournalFeatureFlag {
-		newGenSt, err = nbs.NewLocalJournalingStore(ctx, nbf.VersionString(), path, q)
+		newGenSt, err = nbs.NewEncryptedLocalJournalingStore(ctx, nbf.VersionString(), path, q, keys)
 	} else {
-		newGenSt, err = nbs.NewLocalStore(ctx, nbf.VersionString(), path, defaultMemTableSize, q)
+		newGenSt, err = nbs.NewEncryptedLocalStore(ctx, nbf.VersionString(), path, defaultMemTableSize, q, keys)
 	}
 
 	if err != nil {
@@ -166,7 +172,7 @@ func (fact FileFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat,
 		}
 	}
 
-	oldGenSt, err := nbs.NewLocalStore(ctx, newGenSt.Version(), oldgenPath, defaultMemTableSize, q)
+	oldGenSt, err := nbs.NewEncryptedLocalStore(ctx, newGenSt.Version(), oldgenPath, defaultMemTableSize, q, keys)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -43,4 +43,6 @@ const (
 	EnvDoltAuthorDate                = "DOLT_AUTHOR_DATE"
 	EnvDoltCommitterDate             = "DOLT_COMMITTER_DATE"
 	EnvDbNameReplace                 = "DOLT_DBNAME_REPLACE"
+	EnvEncryptionKey                 = "DOLT_ENCRYPTION_KEY"
+	EnvEncryptionKeyFile             = "DOLT_ENCRYPTION_KEY_FILE"
 )
@@ -194,23 +194,27 @@ func (ds DiffSplitter) SplitDiffResultRow(row sql.Row) (from, to RowDiff, err er
 			j := ds.queryToTarget[i]
 			to.Row[j] = row[i]
 		}
-		// now do field-wise comparison
-		var cmp int
-		for i, col := range ds.targetSch {
-			cmp, err = col.Type.Compare(from.Row[i], to.Row[i])
-			if err != nil {
-				return RowDiff{}, RowDiff{}, err
-			} else if cmp != 0 {
-				from.ColDiffs[i] = ModifiedOld
-				to.ColDiffs[i] = ModifiedNew
-			} else {
-				from.ColDiffs[i] = None
-				to.ColDiffs[i] = None
-			}
-		}
+		return ModifiedRowDiffs(ds.targetSch, from.Row, to.Row)
 
 	default:
 		panic("unknown diff type " + diffType.(string))
 	}
 	return
 }
+
+// ModifiedRowDiffs returns the RowDiffs of the old and new versions |from| and |to| of a modified row with the schema
+// |sch|, with a field-wise comparison of their columns.
+func ModifiedRowDiffs(sch sql.Schema, from, to sql.Row) (RowDiff, RowDiff, error) {
+	fromDiff := RowDiff{Row: from, RowDiff: ModifiedOld, ColDiffs: make([]ChangeType, len(sch))}
+	toDiff := RowDiff{Row: to, RowDiff: ModifiedNew, ColDiffs: make([]ChangeType, len(sch))}
+	for i, col := range sch {
+		cmp, err := col.Type.Compare(from[i], to[i])
+		if err != nil {
+			return RowDiff{}, RowDiff{}, err
+		} else if cmp != 0 {
+			fromDiff.ColDiffs[i] = ModifiedOld
+			toDiff.ColDiffs[i] = ModifiedNew
+		}
+	}
+	return fromDiff, toDiff, nil
+}
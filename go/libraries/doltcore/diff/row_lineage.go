@@ -0,0 +1,262 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+)
+
+// LineageMatch is how a row of a table before a change was matched with a row of the table after the change.
+type LineageMatch string
+
+const (
+	// PrimaryKeyMatch matches rows with the same primary key.
+	PrimaryKeyMatch LineageMatch = "primary_key"
+	// NaturalKeyMatch matches a removed row with an added row that has the same values for a natural key of the table.
+	NaturalKeyMatch LineageMatch = "natural_key"
+	// SimilarityMatch matches a removed row with the added row most similar to it.
+	SimilarityMatch LineageMatch = "similarity"
+)
+
+// MaxLineageComparisons is the maximum number of pairs of removed and added rows a RowLineageMatcher compares. When
+// there are more, no rows are matched, as the comparisons are quadratic in the number of changed rows.
+const MaxLineageComparisons = 1 << 22
+
+// RowPair is a removed row matched with an added row by a RowLineageMatcher.
+type RowPair struct {
+	// From and To are the indexes of the removed and added rows.
+	From, To int
+	Match    LineageMatch
+	// Similarity is the fraction of the compared columns whose values are the same in both rows.
+	Similarity float64
+}
+
+// lineageCol is a column present in both the from and to schemas of a RowLineageMatcher, with its index in the rows
+// of each.
+type lineageCol struct {
+	from, to int
+	typ      sql.Type
+}
+
+// RowLineageMatcher pairs the rows removed from a table with the rows added to it by the same change, so that a
+// change to the primary key of a row, or to the primary key of the whole table, can be seen as a modification of the
+// row rather than as the removal of one row and the addition of another.
+//
+// Rows are first matched by natural key. The natural keys of a table are its unique indexes that exist with the same
+// name and columns before and after the change, and its primary keys before and after the change, when the primary
+// key was redefined and its columns exist on both sides. Two rows are matched by a natural key if their values for it
+// are equal and not NULL. The remaining rows are matched by similarity, which is the fraction of the non-primary key
+// columns after the change, that also exist before it, whose values are the same in both rows. Rows are paired in
+// order of similarity, and only if their similarity is at least the threshold of the matcher.
+type RowLineageMatcher struct {
+	naturalKeys [][]lineageCol
+	cols        []lineageCol
+	threshold   float64
+}
+
+// NewRowLineageMatcher returns a RowLineageMatcher for rows with the schema |fromSch| before a change and |toSch|
+// after it, which pairs rows by similarity if it is at least |threshold|. Rows are laid out in the order of the
+// schemas' columns. Columns are matched by name, and only if their type didn't change.
+func NewRowLineageMatcher(fromSch, toSch schema.Schema, threshold float64) *RowLineageMatcher {
+	m := &RowLineageMatcher{threshold: threshold}
+
+	findCols := func(names []string) ([]lineageCol, bool) {
+		cols := make([]lineageCol, len(names))
+		for i, name := range names {
+			col, ok := lineageColumn(fromSch, toSch, name)
+			if !ok {
+				return nil, false
+			}
+			cols[i] = col
+		}
+		return cols, true
+	}
+
+	fromPk, toPk := pkColumnNames(fromSch), pkColumnNames(toSch)
+	if !equalColumnNames(fromPk, toPk) {
+		for _, names := range [][]string{fromPk, toPk} {
+			if cols, ok := findCols(names); ok {
+				m.naturalKeys = append(m.naturalKeys, cols)
+			}
+		}
+	}
+	for _, toIdx := range toSch.Indexes().AllIndexes() {
+		fromIdx, ok := fromSch.Indexes().GetByNameCaseInsensitive(toIdx.Name())
+		if !toIdx.IsUnique() || !ok || !fromIdx.IsUnique() || !equalColumnNames(fromIdx.ColumnNames(), toIdx.ColumnNames()) {
+			continue
+		}
+		if cols, ok := findCols(toIdx.ColumnNames()); ok {
+			m.naturalKeys = append(m.naturalKeys, cols)
+		}
+	}
+
+	for _, col := range toSch.GetNonPKCols().GetColumns() {
+		if lc, ok := lineageColumn(fromSch, toSch, col.Name); ok {
+			m.cols = append(m.cols, lc)
+		}
+	}
+	return m
+}
+
+// lineageColumn returns the lineageCol of the column named |name|, or false if it doesn't exist in both |fromSch| and
+// |toSch| with the same type.
+func lineageColumn(fromSch, toSch schema.Schema, name string) (lineageCol, bool) {
+	fromCols, toCols := fromSch.GetAllCols(), toSch.GetAllCols()
+	fromCol, ok := fromCols.GetByNameCaseInsensitive(name)
+	if !ok || fromCol.Virtual {
+		return lineageCol{}, false
+	}
+	toCol, ok := toCols.GetByNameCaseInsensitive(name)
+	if !ok || toCol.Virtual {
+		return lineageCol{}, false
+	}
+	typ := toCol.TypeInfo.ToSqlType()
+	if !fromCol.TypeInfo.ToSqlType().Equals(typ) {
+		return lineageCol{}, false
+	}
+	return lineageCol{from: fromCols.TagToIdx[fromCol.Tag], to: toCols.TagToIdx[toCol.Tag], typ: typ}, true
+}
+
+func pkColumnNames(sch schema.Schema) []string {
+	var names []string
+	for _, col := range sch.GetPKCols().GetColumns() {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+func equalColumnNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match pairs the |removed| rows with the |added| rows, and returns the pairs in the order of their removed rows.
+// Each row is in at most one pair.
+func (m *RowLineageMatcher) Match(removed, added []sql.Row) ([]RowPair, error) {
+	if len(removed) == 0 || len(added) == 0 || len(removed)*len(added) > MaxLineageComparisons {
+		return nil, nil
+	}
+
+	var pairs []RowPair
+	fromMatched := make([]bool, len(removed))
+	toMatched := make([]bool, len(added))
+
+	for _, key := range m.naturalKeys {
+		for i, from := range removed {
+			if fromMatched[i] {
+				continue
+			}
+			for j, to := range added {
+				if toMatched[j] {
+					continue
+				}
+				ok, err := keysEqual(key, from, to)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+				sim, err := m.similarity(from, to)
+				if err != nil {
+					return nil, err
+				}
+				pairs = append(pairs, RowPair{From: i, To: j, Match: NaturalKeyMatch, Similarity: sim})
+				fromMatched[i], toMatched[j] = true, true
+				break
+			}
+		}
+	}
+
+	if len(m.cols) > 0 {
+		var candidates []RowPair
+		for i, from := range removed {
+			if fromMatched[i] {
+				continue
+			}
+			for j, to := range added {
+				if toMatched[j] {
+					continue
+				}
+				sim, err := m.similarity(from, to)
+				if err != nil {
+					return nil, err
+				}
+				if sim >= m.threshold {
+					candidates = append(candidates, RowPair{From: i, To: j, Match: SimilarityMatch, Similarity: sim})
+				}
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Similarity > candidates[j].Similarity
+		})
+		for _, c := range candidates {
+			if !fromMatched[c.From] && !toMatched[c.To] {
+				pairs = append(pairs, c)
+				fromMatched[c.From], toMatched[c.To] = true, true
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].From < pairs[j].From
+	})
+	return pairs, nil
+}
+
+// keysEqual returns whether the rows |from| and |to| have the same values for the natural key |key|, none of which
+// are NULL.
+func keysEqual(key []lineageCol, from, to sql.Row) (bool, error) {
+	for _, col := range key {
+		if from[col.from] == nil || to[col.to] == nil {
+			return false, nil
+		}
+		cmp, err := col.typ.Compare(from[col.from], to[col.to])
+		if err != nil || cmp != 0 {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// similarity returns the fraction of the compared columns of the matcher whose values are the same in |from| and |to|.
+func (m *RowLineageMatcher) similarity(from, to sql.Row) (float64, error) {
+	if len(m.cols) == 0 {
+		return 0, nil
+	}
+	same := 0
+	for _, col := range m.cols {
+		cmp, err := col.typ.Compare(from[col.from], to[col.to])
+		if err != nil {
+			return 0, err
+		}
+		if cmp == 0 {
+			same++
+		}
+	}
+	return float64(same) / float64(len(m.cols)), nil
+}
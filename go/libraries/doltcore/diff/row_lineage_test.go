@@ -0,0 +1,94 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func lineageSchema(t *testing.T, pk string, uniqueCode bool) schema.Schema {
+	sch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("id", 0, types.IntKind, pk == "id"),
+		schema.NewColumn("code", 1, types.StringKind, pk == "code"),
+		schema.NewColumn("name", 2, types.StringKind, false),
+		schema.NewColumn("age", 3, types.IntKind, false),
+	))
+	if uniqueCode {
+		_, err := sch.Indexes().AddIndexByColNames("code", []string{"code"}, nil, schema.IndexProperties{IsUnique: true})
+		require.NoError(t, err)
+	}
+	return sch
+}
+
+func TestRowLineageMatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		fromSch   schema.Schema
+		toSch     schema.Schema
+		threshold float64
+		removed   []sql.Row
+		added     []sql.Row
+		expected  []RowPair
+	}{
+		{
+			name:      "unique index",
+			fromSch:   lineageSchema(t, "id", true),
+			toSch:     lineageSchema(t, "id", true),
+			threshold: 1,
+			removed:   []sql.Row{{int64(1), "a", "alice", int64(30)}, {int64(2), nil, "bob", int64(40)}},
+			added:     []sql.Row{{int64(3), nil, "bob", int64(41)}, {int64(4), "a", "alicia", int64(31)}},
+			expected:  []RowPair{{From: 0, To: 1, Match: NaturalKeyMatch, Similarity: 1.0 / 3}},
+		},
+		{
+			name:      "redefined primary key",
+			fromSch:   lineageSchema(t, "id", false),
+			toSch:     lineageSchema(t, "code", false),
+			threshold: 1,
+			removed:   []sql.Row{{int64(1), "a", "alice", int64(30)}, {int64(2), "b", "bob", int64(40)}},
+			added:     []sql.Row{{int64(2), "b", "bob", int64(40)}, {int64(1), "a", "alice", int64(30)}},
+			expected: []RowPair{
+				{From: 0, To: 1, Match: NaturalKeyMatch, Similarity: 1},
+				{From: 1, To: 0, Match: NaturalKeyMatch, Similarity: 1},
+			},
+		},
+		{
+			name:      "similarity",
+			fromSch:   lineageSchema(t, "id", false),
+			toSch:     lineageSchema(t, "id", false),
+			threshold: 0.5,
+			removed:   []sql.Row{{int64(1), "a", "alice", int64(30)}, {int64(2), "b", "bob", int64(40)}, {int64(3), "c", "carol", int64(50)}},
+			added:     []sql.Row{{int64(4), "x", "bob", int64(40)}, {int64(5), "a", "alice", int64(30)}, {int64(6), "b", "bob", int64(41)}},
+			expected: []RowPair{
+				{From: 0, To: 1, Match: SimilarityMatch, Similarity: 1},
+				{From: 1, To: 0, Match: SimilarityMatch, Similarity: 2.0 / 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pairs, err := NewRowLineageMatcher(test.fromSch, test.toSch, test.threshold).Match(test.removed, test.added)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, pairs)
+		})
+	}
+}
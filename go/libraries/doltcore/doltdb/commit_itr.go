@@ -146,6 +146,58 @@ func (cmItr *commitItr) Next(ctx context.Context) (hash.Hash, *OptionalCommit, e
 	return next, &OptionalCommit{cmItr.curr, next}, nil
 }
 
+// firstParentCommitItr is a CommitItr over the first-parent history of a commit
+type firstParentCommitItr struct {
+	start *Commit
+	curr  *Commit
+	done  bool
+}
+
+// CommitItrForFirstParents returns a CommitItr which will iterate over the first-parent history of |cm|, from |cm| to
+// the first commit. Unlike CommitItrForRoots, commits that were merged into the history are not visited, and the
+// commits are returned in the order they were added to the branch. The iteration ends with a nil commit at a parent
+// that isn't available in a shallow clone.
+func CommitItrForFirstParents(cm *Commit) CommitItr {
+	return &firstParentCommitItr{start: cm}
+}
+
+// Next implements CommitItr
+func (cmItr *firstParentCommitItr) Next(ctx context.Context) (hash.Hash, *OptionalCommit, error) {
+	if cmItr.done {
+		return hash.Hash{}, nil, io.EOF
+	}
+	if cmItr.curr == nil {
+		cmItr.curr = cmItr.start
+	} else {
+		if cmItr.curr.NumParents() == 0 {
+			cmItr.done = true
+			return hash.Hash{}, nil, io.EOF
+		}
+		optCmt, err := cmItr.curr.GetParent(ctx, 0)
+		if err != nil {
+			return hash.Hash{}, nil, err
+		}
+		cm, ok := optCmt.ToCommit()
+		if !ok {
+			cmItr.done = true
+			return optCmt.Addr, optCmt, nil
+		}
+		cmItr.curr = cm
+	}
+	h, err := cmItr.curr.HashOf()
+	if err != nil {
+		return hash.Hash{}, nil, err
+	}
+	return h, &OptionalCommit{cmItr.curr, h}, nil
+}
+
+// Reset implements CommitItr
+func (cmItr *firstParentCommitItr) Reset(ctx context.Context) error {
+	cmItr.curr = nil
+	cmItr.done = false
+	return nil
+}
+
 func HashToCommit(ctx context.Context, vrw types.ValueReadWriter, ns tree.NodeStore, h hash.Hash) (*Commit, error) {
 	dc, err := datas.LoadCommitAddr(ctx, vrw, h)
 	if err != nil {
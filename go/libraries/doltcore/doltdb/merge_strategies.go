@@ -0,0 +1,179 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// MergeStrategy is a rule for automatically resolving a merge conflict on a cell, declared in the
+// dolt_merge_strategies system table.
+type MergeStrategy string
+
+const (
+	// MergeStrategyOurs resolves a conflict by keeping the value from our side of the merge.
+	MergeStrategyOurs MergeStrategy = "ours"
+	// MergeStrategyTheirs resolves a conflict by taking the value from their side of the merge.
+	MergeStrategyTheirs MergeStrategy = "theirs"
+	// MergeStrategyMax resolves a conflict by taking the greater of the two values.
+	MergeStrategyMax MergeStrategy = "max"
+	// MergeStrategyMin resolves a conflict by taking the lesser of the two values.
+	MergeStrategyMin MergeStrategy = "min"
+	// MergeStrategySum resolves a conflict on a numeric value by applying the changes made on both sides of the merge
+	// to the value of the merge base, e.g. a base of 10 changed to 8 on one side and to 15 on the other merges to 13.
+	MergeStrategySum MergeStrategy = "sum"
+	// MergeStrategyLastWriterWins resolves a conflict by taking the value from the side of the merge whose row has
+	// the greater value in the rule's LwwColumn, e.g. an updated_at timestamp.
+	MergeStrategyLastWriterWins MergeStrategy = "lww"
+)
+
+// MergeStrategyAllColumns is the column name of a rule that applies to every column of its table. Such a rule
+// also resolves conflicts between a row deleted on one side of the merge and modified on the other, when its
+// strategy is MergeStrategyOurs or MergeStrategyTheirs.
+const MergeStrategyAllColumns = "*"
+
+var mergeStrategies = []MergeStrategy{
+	MergeStrategyOurs,
+	MergeStrategyTheirs,
+	MergeStrategyMax,
+	MergeStrategyMin,
+	MergeStrategySum,
+	MergeStrategyLastWriterWins,
+}
+
+// ParseMergeStrategy returns the MergeStrategy named by |s|, ignoring case.
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	for _, ms := range mergeStrategies {
+		if strings.EqualFold(s, string(ms)) {
+			return ms, nil
+		}
+	}
+	return "", fmt.Errorf("unknown merge strategy '%s'; expected one of ours, theirs, max, min, sum, lww", s)
+}
+
+// MergeStrategyRule is a row of the dolt_merge_strategies system table. It declares how conflicting changes to
+// Column of Table are resolved during a merge.
+type MergeStrategyRule struct {
+	Table    string
+	Column   string
+	Strategy MergeStrategy
+	// LwwColumn is the column compared by MergeStrategyLastWriterWins rules.
+	LwwColumn string
+}
+
+// NewMergeStrategyRule returns a MergeStrategyRule for the given row values, or an error if they don't describe a
+// valid rule.
+func NewMergeStrategyRule(table, column, strategy, lwwColumn string) (MergeStrategyRule, error) {
+	ms, err := ParseMergeStrategy(strategy)
+	if err != nil {
+		return MergeStrategyRule{}, err
+	}
+	if ms == MergeStrategyLastWriterWins && lwwColumn == "" {
+		return MergeStrategyRule{}, fmt.Errorf("merge strategy '%s' for table '%s' requires an lww_column", ms, table)
+	}
+	if ms != MergeStrategyLastWriterWins && lwwColumn != "" {
+		return MergeStrategyRule{}, fmt.Errorf("lww_column can only be set for the '%s' merge strategy", MergeStrategyLastWriterWins)
+	}
+	return MergeStrategyRule{Table: table, Column: column, Strategy: ms, LwwColumn: lwwColumn}, nil
+}
+
+type MergeStrategyRules []MergeStrategyRule
+
+// GetMergeStrategyRules returns the rules declared in the dolt_merge_strategies table of |root|.
+func GetMergeStrategyRules(ctx context.Context, root RootValue) (MergeStrategyRules, error) {
+	table, found, err := root.GetTable(ctx, TableName{Name: MergeStrategiesTableName})
+	if err != nil {
+		return nil, err
+	}
+	if !found || table.Format() == types.Format_LD_1 {
+		// merge strategies are not supported for the legacy storage format.
+		return nil, nil
+	}
+	index, err := table.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sch, err := table.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyDesc, valueDesc := sch.GetMapDescriptors()
+
+	if !keyDesc.Equals(val.NewTupleDescriptor(val.Type{Enc: val.StringEnc}, val.Type{Enc: val.StringEnc})) {
+		return nil, fmt.Errorf("%s had unexpected key type, this should never happen", MergeStrategiesTableName)
+	}
+	if !valueDesc.Equals(val.NewTupleDescriptor(val.Type{Enc: val.StringEnc, Nullable: true}, val.Type{Enc: val.StringEnc, Nullable: true})) {
+		return nil, fmt.Errorf("%s had unexpected value type, this should never happen", MergeStrategiesTableName)
+	}
+
+	iter, err := durable.ProllyMapFromIndex(index).IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rules MergeStrategyRules
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tbl, _ := keyDesc.GetString(0, k)
+		col, _ := keyDesc.GetString(1, k)
+		strategy, _ := valueDesc.GetString(0, v)
+		lwwColumn, _ := valueDesc.GetString(1, v)
+		rule, err := NewMergeStrategyRule(tbl, col, strategy, lwwColumn)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ForTable returns the rules that apply to the table named |name|.
+func (rs MergeStrategyRules) ForTable(name string) MergeStrategyRules {
+	var res MergeStrategyRules
+	for _, r := range rs {
+		if strings.EqualFold(r.Table, name) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// ForColumn returns the rule that applies to the column named |name|. A rule for the column itself takes precedence
+// over a rule for MergeStrategyAllColumns.
+func (rs MergeStrategyRules) ForColumn(name string) (MergeStrategyRule, bool) {
+	var rule MergeStrategyRule
+	var found bool
+	for _, r := range rs {
+		if strings.EqualFold(r.Column, name) {
+			return r, true
+		} else if r.Column == MergeStrategyAllColumns {
+			rule, found = r, true
+		}
+	}
+	return rule, found
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// RowPolicy is a row of the dolt_row_policies system table. It grants the users matching the User and Host patterns
+// access to the rows of Table for which Predicate is true. User and Host are patterns in the format of the
+// dolt_branch_control table, where '%' matches any number of characters and '_' matches exactly one character.
+type RowPolicy struct {
+	Table     string
+	Name      string
+	User      string
+	Host      string
+	Predicate string
+}
+
+// NewRowPolicy returns a RowPolicy for the given row values, or an error if they don't describe a valid policy.
+func NewRowPolicy(table, name, user, host, predicate string) (RowPolicy, error) {
+	if table == "" || name == "" {
+		return RowPolicy{}, fmt.Errorf("a row policy requires a table_name and a policy_name")
+	}
+	if user == "" || host == "" {
+		return RowPolicy{}, fmt.Errorf("row policy '%s' on table '%s' requires a user and a host pattern; use '%%' to match all", name, table)
+	}
+	if strings.TrimSpace(predicate) == "" {
+		return RowPolicy{}, fmt.Errorf("row policy '%s' on table '%s' requires a predicate", name, table)
+	}
+	return RowPolicy{Table: table, Name: name, User: user, Host: host, Predicate: predicate}, nil
+}
+
+type RowPolicies []RowPolicy
+
+// GetRowPolicies returns the policies declared in the dolt_row_policies table of |root|.
+func GetRowPolicies(ctx context.Context, root RootValue) (RowPolicies, error) {
+	table, found, err := root.GetTable(ctx, TableName{Name: RowPoliciesTableName})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return GetRowPoliciesFromTable(ctx, table)
+}
+
+// GetRowPoliciesFromTable returns the policies declared in |table|, which must be a dolt_row_policies table.
+func GetRowPoliciesFromTable(ctx context.Context, table *Table) (RowPolicies, error) {
+	if table.Format() == types.Format_LD_1 {
+		// row policies are not supported for the legacy storage format.
+		return nil, nil
+	}
+	index, err := table.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sch, err := table.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyDesc, valueDesc := sch.GetMapDescriptors()
+
+	if !keyDesc.Equals(val.NewTupleDescriptor(val.Type{Enc: val.StringEnc}, val.Type{Enc: val.StringEnc})) {
+		return nil, fmt.Errorf("%s had unexpected key type, this should never happen", RowPoliciesTableName)
+	}
+	stringValue := val.Type{Enc: val.StringEnc, Nullable: true}
+	if !valueDesc.Equals(val.NewTupleDescriptor(stringValue, stringValue, stringValue)) {
+		return nil, fmt.Errorf("%s had unexpected value type, this should never happen", RowPoliciesTableName)
+	}
+
+	iter, err := durable.ProllyMapFromIndex(index).IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var policies RowPolicies
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tbl, _ := keyDesc.GetString(0, k)
+		name, _ := keyDesc.GetString(1, k)
+		user, _ := valueDesc.GetString(0, v)
+		host, _ := valueDesc.GetString(1, v)
+		predicate, _ := valueDesc.GetString(2, v)
+		policy, err := NewRowPolicy(tbl, name, user, host, predicate)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// ForTable returns the policies that apply to the table named |name|.
+func (ps RowPolicies) ForTable(name string) RowPolicies {
+	var res RowPolicies
+	for _, p := range ps {
+		if strings.EqualFold(p.Table, name) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
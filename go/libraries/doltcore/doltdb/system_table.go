@@ -65,16 +65,28 @@ func IsFullTextTable(name string) bool {
 		strings.HasSuffix(name, "_fts_row_count"))
 }
 
+// IsVectorIndexTable returns whether the given table is one of the pseudo-index tables storing the buckets of a
+// vector index.
+func IsVectorIndexTable(name string) bool {
+	return HasDoltPrefix(name) && strings.HasSuffix(strings.ToLower(name), "_vec_buckets")
+}
+
+// IsVectorIndexTableOf returns whether the given table is one of the pseudo-index tables storing the buckets of a
+// vector index of |table|. Such tables are named dolt_<table>_<index>_vec_buckets.
+func IsVectorIndexTableOf(name, table string) bool {
+	return IsVectorIndexTable(name) && strings.HasPrefix(strings.ToLower(name), "dolt_"+strings.ToLower(table)+"_")
+}
+
 // IsReadOnlySystemTable returns whether the table name given is a system table that should not be included in command line
 // output (e.g. dolt status) by default.
 func IsReadOnlySystemTable(name string) bool {
-	return HasDoltPrefix(name) && !set.NewStrSet(writeableSystemTables).Contains(name) && !IsFullTextTable(name)
+	return HasDoltPrefix(name) && !set.NewStrSet(writeableSystemTables).Contains(name) && !IsFullTextTable(name) && !IsVectorIndexTable(name)
 }
 
 // IsNonAlterableSystemTable returns whether the table name given is a system table that cannot be dropped or altered
 // by the user.
 func IsNonAlterableSystemTable(name string) bool {
-	return (IsReadOnlySystemTable(name) && !IsFullTextTable(name)) || strings.EqualFold(name, SchemasTableName)
+	return (IsReadOnlySystemTable(name) && !IsFullTextTable(name)) || IsVectorIndexTable(name) || strings.EqualFold(name, SchemasTableName)
 }
 
 // GetNonSystemTableNames gets non-system table names
@@ -144,6 +156,11 @@ var writeableSystemTables = []string{
 	ProceduresTableName,
 	IgnoreTableName,
 	RebaseTableName,
+	MergeStrategiesTableName,
+	RowPoliciesTableName,
+	TestsTableName,
+	MaterializedViewsTableName,
+	VectorIndexesTableName,
 }
 
 var persistedSystemTables = []string{
@@ -152,6 +169,11 @@ var persistedSystemTables = []string{
 	SchemasTableName,
 	ProceduresTableName,
 	IgnoreTableName,
+	MergeStrategiesTableName,
+	RowPoliciesTableName,
+	TestsTableName,
+	MaterializedViewsTableName,
+	VectorIndexesTableName,
 }
 
 var generatedSystemTables = []string{
@@ -232,6 +254,8 @@ const (
 const (
 	// DoltBlameViewPrefix is the prefix assigned to all the generated blame tables
 	DoltBlameViewPrefix = "dolt_blame_"
+	// DoltBlameCellsTablePrefix is the prefix assigned to all the generated cell blame tables
+	DoltBlameCellsTablePrefix = "dolt_blame_cells_"
 	// DoltHistoryTablePrefix is the prefix assigned to all the generated history tables
 	DoltHistoryTablePrefix = "dolt_history_"
 	// DoltDiffTablePrefix is the prefix assigned to all the generated diff tables
@@ -244,6 +268,8 @@ const (
 	DoltConstViolTablePrefix = "dolt_constraint_violations_"
 	// DoltWorkspaceTablePrefix is the prefix assigned to all the generated workspace tables
 	DoltWorkspaceTablePrefix = "dolt_workspace_"
+	// DoltRowLineageTablePrefix is the prefix assigned to all the generated row lineage tables
+	DoltRowLineageTablePrefix = "dolt_row_lineage_"
 )
 
 const (
@@ -289,8 +315,36 @@ const (
 	// TagsTableName is the tags table name
 	TagsTableName = "dolt_tags"
 
+	// AuditLogTableName is the name of the table showing the records of the audit log of the running sql-server.
+	AuditLogTableName = "dolt_audit_log"
+
+	// BranchPoliciesTableName is the name of the table declaring the retention policies of branches and tags.
+	BranchPoliciesTableName = "dolt_branch_policies"
+
+	// QueryHistoryTableName is the name of the table showing the most recent statements run on the sql-server.
+	QueryHistoryTableName = "dolt_query_history"
+
+	// QueryDigestTableName is the name of the table showing the statements of the query history aggregated by digest.
+	QueryDigestTableName = "dolt_query_digest"
+
 	IgnoreTableName = "dolt_ignore"
 
+	// MergeStrategiesTableName is the name of the table declaring how merge conflicts are resolved automatically.
+	MergeStrategiesTableName = "dolt_merge_strategies"
+
+	// RowPoliciesTableName is the name of the table declaring which rows of a table each user can read and write.
+	RowPoliciesTableName = "dolt_row_policies"
+
+	// TestsTableName is the name of the table storing SQL tests, which assert on the results of queries.
+	TestsTableName = "dolt_tests"
+
+	// MaterializedViewsTableName is the name of the table storing the queries of materialized views, and the commits
+	// whose data they reflect.
+	MaterializedViewsTableName = "dolt_materialized_views"
+
+	// VectorIndexesTableName is the name of the table declaring the vector indexes of tables.
+	VectorIndexesTableName = "dolt_vector_indexes"
+
 	// RebaseTableName is the rebase system table name.
 	RebaseTableName = "dolt_rebase"
 
@@ -0,0 +1,231 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TestAssertionType is the kind of assertion a test in the dolt_tests system table makes about the result of its
+// query.
+type TestAssertionType string
+
+const (
+	// TestAssertionNoRows asserts that the query returns no rows. Tests of this type have no assertion value.
+	TestAssertionNoRows TestAssertionType = "no_rows"
+	// TestAssertionExpectedRowCount asserts that the number of rows returned by the query compares to the integer
+	// assertion value with the test's comparator.
+	TestAssertionExpectedRowCount TestAssertionType = "expected_row_count"
+	// TestAssertionExpectedRows asserts that the query returns exactly the rows in the assertion value, in order. The
+	// assertion value is a JSON array of rows, each of which is a JSON array of values, e.g. [[1, "a"], [2, null]].
+	// Values are compared by their string representation.
+	TestAssertionExpectedRows TestAssertionType = "expected_rows"
+)
+
+var testAssertionTypes = []TestAssertionType{
+	TestAssertionNoRows,
+	TestAssertionExpectedRowCount,
+	TestAssertionExpectedRows,
+}
+
+// ParseTestAssertionType returns the TestAssertionType named by |s|, ignoring case.
+func ParseTestAssertionType(s string) (TestAssertionType, error) {
+	for _, t := range testAssertionTypes {
+		if strings.EqualFold(s, string(t)) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown test assertion type '%s'; expected one of no_rows, expected_row_count, expected_rows", s)
+}
+
+// DefaultTestComparator is the comparator used by TestAssertionExpectedRowCount tests that don't declare one.
+const DefaultTestComparator = "=="
+
+var testComparators = []string{"==", "!=", "<", "<=", ">", ">="}
+
+// DoltTest is a row of the dolt_tests system table. It asserts that the result of running Query satisfies its
+// assertion.
+type DoltTest struct {
+	Name string
+	// Group is an optional name used to run related tests together.
+	Group      string
+	Query      string
+	Assertion  TestAssertionType
+	Comparator string
+	Value      string
+
+	// expectedRows are the parsed rows of a TestAssertionExpectedRows test. A nil value is a NULL.
+	expectedRows [][]*string
+	// expectedCount is the parsed row count of a TestAssertionExpectedRowCount test.
+	expectedCount int64
+}
+
+// NewDoltTest returns a DoltTest for the given row values, or an error if they don't describe a valid test.
+func NewDoltTest(name, group, query, assertion, comparator, value string) (DoltTest, error) {
+	if strings.TrimSpace(query) == "" {
+		return DoltTest{}, fmt.Errorf("test '%s' must have a test_query", name)
+	}
+	at, err := ParseTestAssertionType(assertion)
+	if err != nil {
+		return DoltTest{}, err
+	}
+	t := DoltTest{Name: name, Group: group, Query: query, Assertion: at, Comparator: comparator, Value: value}
+
+	if at != TestAssertionExpectedRowCount && comparator != "" {
+		return DoltTest{}, fmt.Errorf("test '%s': assertion_comparator can only be set for the '%s' assertion type", name, TestAssertionExpectedRowCount)
+	}
+
+	switch at {
+	case TestAssertionNoRows:
+		if value != "" {
+			return DoltTest{}, fmt.Errorf("test '%s': the '%s' assertion type does not take an assertion_value", name, at)
+		}
+	case TestAssertionExpectedRowCount:
+		if t.Comparator == "" {
+			t.Comparator = DefaultTestComparator
+		}
+		if !isTestComparator(t.Comparator) {
+			return DoltTest{}, fmt.Errorf("test '%s': unknown assertion_comparator '%s'; expected one of %s", name, t.Comparator, strings.Join(testComparators, ", "))
+		}
+		t.expectedCount, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return DoltTest{}, fmt.Errorf("test '%s': assertion_value of the '%s' assertion type must be an integer, got '%s'", name, at, value)
+		}
+	case TestAssertionExpectedRows:
+		t.expectedRows, err = parseExpectedRows(value)
+		if err != nil {
+			return DoltTest{}, fmt.Errorf("test '%s': assertion_value of the '%s' assertion type must be a JSON array of rows: %w", name, at, err)
+		}
+	}
+	return t, nil
+}
+
+func isTestComparator(s string) bool {
+	for _, c := range testComparators {
+		if s == c {
+			return true
+		}
+	}
+	return false
+}
+
+func parseExpectedRows(value string) ([][]*string, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(value)))
+	dec.UseNumber()
+	var raw [][]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	rows := make([][]*string, len(raw))
+	for i, r := range raw {
+		rows[i] = make([]*string, len(r))
+		for j, v := range r {
+			var s string
+			switch v := v.(type) {
+			case nil:
+				continue
+			case string:
+				s = v
+			case json.Number:
+				s = v.String()
+			case bool:
+				s = "0"
+				if v {
+					s = "1"
+				}
+			default:
+				return nil, fmt.Errorf("row %d has a value that is not a string, number, boolean or null", i+1)
+			}
+			rows[i][j] = &s
+		}
+	}
+	return rows, nil
+}
+
+// Check returns whether |rows|, the result of running the test's query, satisfy the test's assertion. Each value of
+// |rows| is the string representation of a column value, or nil for NULL. If the assertion fails, a message
+// describing the failure is returned.
+func (t DoltTest) Check(rows [][]*string) (bool, string) {
+	switch t.Assertion {
+	case TestAssertionNoRows:
+		if len(rows) > 0 {
+			return false, fmt.Sprintf("expected no rows, got %d", len(rows))
+		}
+	case TestAssertionExpectedRowCount:
+		if !compareTestRowCount(int64(len(rows)), t.Comparator, t.expectedCount) {
+			return false, fmt.Sprintf("expected row count %s %d, got %d", t.Comparator, t.expectedCount, len(rows))
+		}
+	case TestAssertionExpectedRows:
+		if len(rows) != len(t.expectedRows) {
+			return false, fmt.Sprintf("expected %d rows, got %d", len(t.expectedRows), len(rows))
+		}
+		for i := range rows {
+			if !testRowsEqual(t.expectedRows[i], rows[i]) {
+				return false, fmt.Sprintf("row %d: expected %s, got %s", i+1, formatTestRow(t.expectedRows[i]), formatTestRow(rows[i]))
+			}
+		}
+	}
+	return true, ""
+}
+
+func compareTestRowCount(actual int64, comparator string, expected int64) bool {
+	switch comparator {
+	case "==":
+		return actual == expected
+	case "!=":
+		return actual != expected
+	case "<":
+		return actual < expected
+	case "<=":
+		return actual <= expected
+	case ">":
+		return actual > expected
+	case ">=":
+		return actual >= expected
+	default:
+		return false
+	}
+}
+
+func testRowsEqual(expected, actual []*string) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if (expected[i] == nil) != (actual[i] == nil) {
+			return false
+		}
+		if expected[i] != nil && *expected[i] != *actual[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatTestRow(row []*string) string {
+	vals := make([]string, len(row))
+	for i, v := range row {
+		if v == nil {
+			vals[i] = "NULL"
+		} else {
+			vals[i] = *v
+		}
+	}
+	return "[" + strings.Join(vals, ", ") + "]"
+}
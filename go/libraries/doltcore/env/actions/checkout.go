@@ -33,6 +33,11 @@ import (
 // MoveTablesFromHeadToWorking replaces the tables named from the given head to the given working root, overwriting any
 // working changes, and returns the new resulting roots
 func MoveTablesFromHeadToWorking(ctx context.Context, roots doltdb.Roots, tbls []doltdb.TableName) (doltdb.Roots, error) {
+	tbls, err := withVectorIndexTables(ctx, roots, tbls)
+	if err != nil {
+		return doltdb.Roots{}, err
+	}
+
 	var unknownTbls []doltdb.TableName
 	for _, tblName := range tbls {
 		tbl, ok, err := roots.Staged.GetTable(ctx, tblName)
@@ -89,6 +94,32 @@ func MoveTablesFromHeadToWorking(ctx context.Context, roots doltdb.Roots, tbls [
 	return roots, nil
 }
 
+// withVectorIndexTables returns |tbls| along with the pseudo-index tables of their vector indexes found in the staged
+// or head root, so that the indexes are restored along with the tables they index
+func withVectorIndexTables(ctx context.Context, roots doltdb.Roots, tbls []doltdb.TableName) ([]doltdb.TableName, error) {
+	tblSet := doltdb.NewTableNameSet(tbls)
+	for _, root := range []doltdb.RootValue{roots.Staged, roots.Head} {
+		names, err := root.GetTableNames(ctx, doltdb.DefaultSchemaName)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			idxTbl := doltdb.TableName{Name: name}
+			if !doltdb.IsVectorIndexTable(name) || tblSet.Contains(idxTbl) {
+				continue
+			}
+			for _, tbl := range tbls {
+				if doltdb.IsVectorIndexTableOf(name, tbl.Name) {
+					tblSet.Add(idxTbl)
+					tbls = append(tbls, idxTbl)
+					break
+				}
+			}
+		}
+	}
+	return tbls, nil
+}
+
 // FindTableInRoots resolves a table by looking in all three roots (working,
 // staged, head) in that order.
 func FindTableInRoots(ctx *sql.Context, roots doltdb.Roots, name string) (doltdb.TableName, *doltdb.Table, bool, error) {
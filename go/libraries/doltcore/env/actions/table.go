@@ -39,6 +39,7 @@ func MoveTablesBetweenRoots(ctx context.Context, tbls []doltdb.TableName, src, d
 	}
 
 	addFullTextTablesToDelta(tblDeltas, tablesToMove)
+	addVectorIndexTablesToDelta(tblDeltas, tablesToMove)
 
 	destSchemaNames, err := getDatabaseSchemaNames(ctx, dest)
 	if err != nil {
@@ -156,6 +157,31 @@ func addFullTextTablesToDelta(tblDeltas []diff.TableDelta, tblSet *doltdb.TableN
 	}
 }
 
+// addVectorIndexTablesToDelta adds the pseudo-index tables of the vector indexes of the tables in the tableset provided
+// to it, so that they're always moved along with the tables they index
+func addVectorIndexTablesToDelta(tblDeltas []diff.TableDelta, tblSet *doltdb.TableNameSet) {
+	var parents []doltdb.TableName
+	for _, td := range tblDeltas {
+		for _, name := range []doltdb.TableName{td.ToName, td.FromName} {
+			if name.Name != "" && !doltdb.IsVectorIndexTable(name.Name) && tblSet.Contains(name) {
+				parents = append(parents, name)
+			}
+		}
+	}
+	for _, td := range tblDeltas {
+		for _, name := range []doltdb.TableName{td.ToName, td.FromName} {
+			if name.Name == "" || !doltdb.IsVectorIndexTable(name.Name) {
+				continue
+			}
+			for _, parent := range parents {
+				if doltdb.IsVectorIndexTableOf(name.Name, parent.Name) {
+					tblSet.Add(name)
+				}
+			}
+		}
+	}
+}
+
 func validateTablesExist(ctx context.Context, currRoot doltdb.RootValue, unknown []doltdb.TableName) error {
 	var notExist []doltdb.TableName
 	for _, tbl := range unknown {
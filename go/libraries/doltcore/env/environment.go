@@ -169,7 +169,16 @@ func LoadWithoutDB(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys,
 func Load(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys, urlStr string, version string) *DoltEnv {
 	dEnv := LoadWithoutDB(ctx, hdp, fs, version)
 
-	ddb, dbLoadErr := doltdb.LoadDoltDB(ctx, types.Format_Default, urlStr, fs)
+	// A linked worktree loads the chunk store of its main worktree
+	dbFS := fs
+	var ddb *doltdb.DoltDB
+	var dbLoadErr error
+	if urlStr == doltdb.LocalDirDoltDB {
+		dbFS, dbLoadErr = dataFS(fs)
+	}
+	if dbLoadErr == nil {
+		ddb, dbLoadErr = doltdb.LoadDoltDB(ctx, types.Format_Default, urlStr, dbFS)
+	}
 
 	dEnv.DoltDB = ddb
 	dEnv.DBLoadError = dbLoadErr
@@ -316,8 +325,14 @@ func (dEnv *DoltEnv) HasDoltDir() bool {
 	return dEnv.hasDoltDir("./")
 }
 
+// HasDoltDataDir returns true if the .dolt directory has a data directory, or is a linked worktree of a repository
+// that has one
 func (dEnv *DoltEnv) HasDoltDataDir() bool {
-	exists, isDir := dEnv.FS.Exists(dbfactory.DoltDataDir)
+	fs, err := dataFS(dEnv.FS)
+	if err != nil {
+		return false
+	}
+	exists, isDir := fs.Exists(dbfactory.DoltDataDir)
 	return exists && isDir
 }
 
@@ -739,6 +754,11 @@ func (r *repoStateWriter) SetCWBHeadRef(ctx context.Context, marshalableRef ref.
 	if r.RepoState == nil && r.RSLoadErr != nil {
 		return r.RSLoadErr
 	}
+	if marshalableRef.Ref.GetType() == ref.BranchRefType {
+		if err := r.CheckBranchNotInOtherWorktree(marshalableRef.Ref.GetPath()); err != nil {
+			return err
+		}
+	}
 
 	r.RepoState.Head = marshalableRef
 	err := r.RepoState.Save(r.FS)
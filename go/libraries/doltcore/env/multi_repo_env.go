@@ -110,6 +110,11 @@ func GetMultiEnvStorageMetadata(dataDirFS filesys.Filesys) (StorageMetadataMap,
 
 	sms := make(StorageMetadataMap)
 	for _, fs := range dbMap {
+		// The storage of a linked worktree is in its main worktree
+		fs, err := dataFS(fs)
+		if err != nil {
+			return nil, err
+		}
 		fsStr, err := fs.Abs("")
 		if err != nil {
 			return nil, err
@@ -193,7 +198,9 @@ func MultiEnvForDirectory(
 		} else {
 			dbErr := newEnv.DBLoadError
 			if dbErr != nil {
-				if !errors.Is(dbErr, doltdb.ErrMissingDoltDataDir) {
+				if main, ok, _ := loadWorktreeLink(newFs); ok {
+					logrus.Warnf("failed to load linked worktree at %s of the repository at %s with error: %s", path, main, dbErr.Error())
+				} else if !errors.Is(dbErr, doltdb.ErrMissingDoltDataDir) {
 					logrus.Warnf("failed to load database at %s with error: %s", path, dbErr.Error())
 				}
 			}
@@ -25,6 +25,8 @@ import (
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
 	"github.com/dolthub/dolt/go/libraries/utils/config"
 	"github.com/dolthub/dolt/go/libraries/utils/earl"
 	"github.com/dolthub/dolt/go/libraries/utils/filesys"
@@ -190,3 +192,55 @@ func initMultiEnv(t *testing.T, testName string, names []string) (string, HomeDi
 
 	return rootPath, hdp, envs
 }
+
+func TestMultiEnvForDirectoryWithWorktrees(t *testing.T) {
+	ctx := context.Background()
+	rootPath, err := test.ChangeToTestDir("TestMultiEnvForDirectoryWithWorktrees")
+	require.NoError(t, err)
+
+	hdp := func() (string, error) { return rootPath, nil }
+	mainPath := filepath.Join(rootPath, "repo")
+	mainEnv := initRepoWithRelativePath(t, mainPath, hdp)
+	head, err := mainEnv.HeadCommit(ctx)
+	require.NoError(t, err)
+	require.NoError(t, mainEnv.DoltDB.NewBranchAtCommit(ctx, ref.NewBranchRef("feature"), head, nil))
+	mainEnv = Load(ctx, hdp, mainEnv.FS, doltdb.LocalDirDoltDB, "test")
+	wtPath := filepath.Join(rootPath, "feature")
+	require.NoError(t, mainEnv.AddWorktree(ctx, wtPath, "feature"))
+
+	// A directory holding a repository and its linked worktree has a database for each of them, on the branch
+	// checked out in it and backed by the same chunk store
+	rootFS, err := filesys.LocalFilesysWithWorkingDir(rootPath)
+	require.NoError(t, err)
+	mrEnv, err := MultiEnvForDirectory(ctx, mainEnv.Config.WriteableConfig(), rootFS, "test", nil)
+	require.NoError(t, err)
+	require.Len(t, mrEnv.envs, 2)
+	repoEnv, featureEnv := mrEnv.GetEnv("repo"), mrEnv.GetEnv("feature")
+	require.NotNil(t, repoEnv)
+	require.NotNil(t, featureEnv)
+	assert.Equal(t, DefaultInitBranch, repoEnv.RepoState.CWBHeadRef().GetPath())
+	assert.Equal(t, "feature", featureEnv.RepoState.CWBHeadRef().GetPath())
+	assert.Same(t, repoEnv.DoltDB.ValueReadWriter(), featureEnv.DoltDB.ValueReadWriter())
+
+	// Rooted at the linked worktree, the worktree is the first database
+	wtFS, err := filesys.LocalFilesysWithWorkingDir(wtPath)
+	require.NoError(t, err)
+	mrEnv, err = MultiEnvForDirectory(ctx, mainEnv.Config.WriteableConfig(), wtFS, "test", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "feature", mrEnv.GetFirstDatabase())
+	assert.True(t, mrEnv.GetEnv("feature").IsLinkedWorktree())
+
+	// The storage of the linked worktree is the storage of its main worktree
+	sms, err := GetMultiEnvStorageMetadata(wtFS)
+	require.NoError(t, err)
+	absMainPath, err := mainEnv.FS.Abs("")
+	require.NoError(t, err)
+	assert.Contains(t, sms, absMainPath)
+	assert.Len(t, sms, 1)
+
+	// A linked worktree whose repository is gone isn't loaded
+	require.NoError(t, os.RemoveAll(mainPath))
+	mrEnv, err = MultiEnvForDirectory(ctx, mainEnv.Config.WriteableConfig(), rootFS, "test", nil)
+	require.NoError(t, err)
+	assert.Len(t, mrEnv.envs, 0)
+}
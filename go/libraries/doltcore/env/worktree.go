@@ -0,0 +1,328 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	goerrors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const (
+	// worktreeLinkFile is the file in the .dolt directory of a linked worktree that names the repository it belongs to
+	worktreeLinkFile = "worktree.json"
+	// worktreesFile is the file in the .dolt directory of a repository that lists its linked worktrees
+	worktreesFile = "worktrees.json"
+)
+
+var ErrBranchCheckedOutInWorktree = goerrors.NewKind("branch '%s' is already checked out at '%s'")
+var ErrWorktreePathExists = goerrors.NewKind("'%s' already exists")
+var ErrWorktreeNotFound = goerrors.NewKind("'%s' is not a linked worktree")
+var ErrRemoveCurrentWorktree = errors.New("cannot remove the current worktree")
+
+// worktreeLink is the contents of the worktree link file.
+type worktreeLink struct {
+	Main string `json:"main"`
+}
+
+// worktreeList is the contents of the worktrees file.
+type worktreeList struct {
+	Worktrees []string `json:"worktrees"`
+}
+
+// Worktree is a working directory of a repository. Every repository has a main worktree, the directory whose .dolt
+// directory holds the chunk store, and any number of linked worktrees, whose .dolt directories hold only their own
+// repo state and refer to the chunk store of the main worktree. Each worktree has a different branch checked out, and
+// the working set of that branch is its working set.
+type Worktree struct {
+	// Path is the absolute path of the worktree
+	Path string
+	// Branch is the branch checked out in the worktree, or empty if it can't be read
+	Branch string
+	// Main is whether this is the main worktree
+	Main bool
+	// Missing is whether the directory of a linked worktree no longer exists
+	Missing bool
+}
+
+// loadWorktreeLink returns the absolute path of the main worktree that the .dolt directory of |fs| is linked to, or
+// false if it isn't a linked worktree.
+func loadWorktreeLink(fs filesys.ReadableFS) (string, bool, error) {
+	path := filepath.Join(dbfactory.DoltDir, worktreeLinkFile)
+	if exists, isDir := fs.Exists(path); !exists || isDir {
+		return "", false, nil
+	}
+
+	var link worktreeLink
+	if err := filesys.UnmarshalJSONFile(fs, path, &link); err != nil {
+		return "", false, err
+	}
+	return link.Main, true, nil
+}
+
+// dataFS returns the filesystem whose .dolt directory holds the chunk store for the .dolt directory of |fs|, which is
+// the main worktree for linked worktrees and |fs| itself otherwise.
+func dataFS(fs filesys.Filesys) (filesys.Filesys, error) {
+	main, ok, err := loadWorktreeLink(fs)
+	if err != nil || !ok {
+		return fs, err
+	}
+	return fs.WithWorkingDir(main)
+}
+
+// IsLinkedWorktree returns whether this environment is a linked worktree of another repository.
+func (dEnv *DoltEnv) IsLinkedWorktree() bool {
+	_, ok, _ := loadWorktreeLink(dEnv.FS)
+	return ok
+}
+
+// mainWorktreeFS returns the filesystem of the main worktree of this environment's repository.
+func (dEnv *DoltEnv) mainWorktreeFS() (filesys.Filesys, error) {
+	return dataFS(dEnv.FS)
+}
+
+// Worktrees returns the worktrees of this environment's repository, the main worktree first.
+func (dEnv *DoltEnv) Worktrees() ([]Worktree, error) {
+	mainFS, err := dEnv.mainWorktreeFS()
+	if err != nil {
+		return nil, err
+	}
+	mainPath, err := mainFS.Abs("")
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees := []Worktree{{Path: mainPath, Branch: worktreeBranch(mainFS), Main: true}}
+	paths, err := loadWorktreeList(mainFS)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		wt := Worktree{Path: path}
+		if exists, isDir := mainFS.Exists(filepath.Join(path, dbfactory.DoltDir)); !exists || !isDir {
+			wt.Missing = true
+		} else if wtFS, err := mainFS.WithWorkingDir(path); err == nil {
+			wt.Branch = worktreeBranch(wtFS)
+		}
+		worktrees = append(worktrees, wt)
+	}
+	return worktrees, nil
+}
+
+// CheckBranchNotInOtherWorktree returns an error if |branch| is checked out in a worktree of this environment's
+// repository other than this one.
+func (dEnv *DoltEnv) CheckBranchNotInOtherWorktree(branch string) error {
+	if !dEnv.IsLinkedWorktree() {
+		// Without linked worktrees, the only worktree is this one
+		if paths, err := loadWorktreeList(dEnv.FS); err != nil || len(paths) == 0 {
+			return err
+		}
+	}
+
+	cwd, err := dEnv.FS.Abs("")
+	if err != nil {
+		return err
+	}
+	worktrees, err := dEnv.Worktrees()
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Path != cwd && wt.Branch == branch {
+			return ErrBranchCheckedOutInWorktree.New(branch, wt.Path)
+		}
+	}
+	return nil
+}
+
+// AddWorktree creates a linked worktree of this environment's repository at |path| with |branch| checked out.
+// The worktree gets a copy of the repo state and local config of the main worktree, and shares its chunk store.
+func (dEnv *DoltEnv) AddWorktree(ctx context.Context, path, branch string) error {
+	mainFS, err := dEnv.mainWorktreeFS()
+	if err != nil {
+		return err
+	}
+	mainPath, err := mainFS.Abs("")
+	if err != nil {
+		return err
+	}
+	absPath, err := dEnv.FS.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if exists, isDir := dEnv.FS.Exists(absPath); exists {
+		if !isDir || !isEmptyDir(dEnv.FS, absPath) {
+			return ErrWorktreePathExists.New(path)
+		}
+	}
+	if ok, err := dEnv.DoltDB.HasRef(ctx, ref.NewBranchRef(branch)); err != nil {
+		return err
+	} else if !ok {
+		return doltdb.ErrBranchNotFound
+	}
+	worktrees, err := dEnv.Worktrees()
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			return ErrBranchCheckedOutInWorktree.New(branch, wt.Path)
+		}
+	}
+
+	mainRS, err := LoadRepoState(mainFS)
+	if err != nil {
+		return err
+	}
+	if err = dEnv.FS.MkDirs(filepath.Join(absPath, dbfactory.DoltDir)); err != nil {
+		return err
+	}
+	wtFS, err := dEnv.FS.WithWorkingDir(absPath)
+	if err != nil {
+		return err
+	}
+
+	rs := &RepoState{
+		Head:     ref.MarshalableRef{Ref: ref.NewBranchRef(branch)},
+		Remotes:  mainRS.Remotes,
+		Backups:  mainRS.Backups,
+		Branches: mainRS.Branches,
+	}
+	if err = rs.Save(wtFS); err != nil {
+		return err
+	}
+	if exists, _ := mainFS.Exists(getLocalConfigPath()); exists {
+		data, err := mainFS.ReadFile(getLocalConfigPath())
+		if err != nil {
+			return err
+		}
+		if err = wtFS.WriteFile(getLocalConfigPath(), data, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	if err = writeJSONFile(wtFS, filepath.Join(dbfactory.DoltDir, worktreeLinkFile), worktreeLink{Main: mainPath}); err != nil {
+		return err
+	}
+
+	paths, err := loadWorktreeList(mainFS)
+	if err != nil {
+		return err
+	}
+	return saveWorktreeList(mainFS, append(paths, absPath))
+}
+
+// RemoveWorktree deletes the linked worktree at |path| of this environment's repository. The working set of its
+// branch is kept in the database, so checking out the branch elsewhere picks up its uncommitted changes.
+func (dEnv *DoltEnv) RemoveWorktree(path string) error {
+	mainFS, err := dEnv.mainWorktreeFS()
+	if err != nil {
+		return err
+	}
+	absPath, err := dEnv.FS.Abs(path)
+	if err != nil {
+		return err
+	}
+	cwd, err := dEnv.FS.Abs("")
+	if err != nil {
+		return err
+	}
+	if absPath == cwd {
+		return ErrRemoveCurrentWorktree
+	}
+
+	paths, err := loadWorktreeList(mainFS)
+	if err != nil {
+		return err
+	}
+	remaining := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p != absPath {
+			remaining = append(remaining, p)
+		}
+	}
+	if len(remaining) == len(paths) {
+		return ErrWorktreeNotFound.New(path)
+	}
+
+	if exists, _ := dEnv.FS.Exists(absPath); exists {
+		if err = dEnv.FS.Delete(absPath, true); err != nil {
+			return err
+		}
+	}
+	return saveWorktreeList(mainFS, remaining)
+}
+
+// worktreeBranch returns the branch checked out in the worktree of |fs|, or empty if it can't be read.
+func worktreeBranch(fs filesys.ReadWriteFS) string {
+	rs, err := LoadRepoState(fs)
+	if err != nil || rs.Head.Ref == nil || rs.Head.Ref.GetType() != ref.BranchRefType {
+		return ""
+	}
+	return rs.Head.Ref.GetPath()
+}
+
+// loadWorktreeList returns the absolute paths of the linked worktrees of the main worktree of |fs|.
+func loadWorktreeList(fs filesys.ReadableFS) ([]string, error) {
+	path := filepath.Join(dbfactory.DoltDir, worktreesFile)
+	if exists, isDir := fs.Exists(path); !exists || isDir {
+		return nil, nil
+	}
+
+	var list worktreeList
+	if err := filesys.UnmarshalJSONFile(fs, path, &list); err != nil {
+		return nil, err
+	}
+	return list.Worktrees, nil
+}
+
+// saveWorktreeList writes the list of linked worktrees of the main worktree of |fs|, deleting the file when the list
+// is empty.
+func saveWorktreeList(fs filesys.ReadWriteFS, paths []string) error {
+	path := filepath.Join(dbfactory.DoltDir, worktreesFile)
+	if len(paths) == 0 {
+		if exists, _ := fs.Exists(path); exists {
+			return fs.DeleteFile(path)
+		}
+		return nil
+	}
+	return writeJSONFile(fs, path, worktreeList{Worktrees: paths})
+}
+
+func writeJSONFile(fs filesys.WritableFS, path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fs.WriteFile(path, data, os.ModePerm)
+}
+
+func isEmptyDir(fs filesys.Filesys, path string) bool {
+	empty := true
+	_ = fs.Iter(path, false, func(string, int64, bool) bool {
+		empty = false
+		return true
+	})
+	return empty
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/libraries/utils/test"
+)
+
+func TestWorktrees(t *testing.T) {
+	ctx := context.Background()
+	rootPath, err := test.ChangeToTestDir("TestWorktrees")
+	require.NoError(t, err)
+
+	hdp := func() (string, error) { return rootPath, nil }
+	mainPath := filepath.Join(rootPath, "main")
+	mainEnv := initRepoWithRelativePath(t, mainPath, hdp)
+
+	head, err := mainEnv.HeadCommit(ctx)
+	require.NoError(t, err)
+	require.NoError(t, mainEnv.DoltDB.NewBranchAtCommit(ctx, ref.NewBranchRef("feature"), head, nil))
+	require.NoError(t, mainEnv.DoltDB.NewBranchAtCommit(ctx, ref.NewBranchRef("other"), head, nil))
+
+	// The repository is reloaded from its directory, like the CLI does
+	mainEnv = Load(ctx, hdp, mainEnv.FS, doltdb.LocalDirDoltDB, "test")
+	require.True(t, mainEnv.Valid())
+	assert.False(t, mainEnv.IsLinkedWorktree())
+
+	wtPath := filepath.Join(rootPath, "feature")
+	err = mainEnv.AddWorktree(ctx, wtPath, DefaultInitBranch)
+	assert.True(t, ErrBranchCheckedOutInWorktree.Is(err))
+	err = mainEnv.AddWorktree(ctx, wtPath, "missing")
+	assert.Equal(t, doltdb.ErrBranchNotFound, err)
+	require.NoError(t, mainEnv.AddWorktree(ctx, wtPath, "feature"))
+	err = mainEnv.AddWorktree(ctx, wtPath, "other")
+	assert.True(t, ErrWorktreePathExists.Is(err))
+
+	wtFS, err := filesys.LocalFilesysWithWorkingDir(wtPath)
+	require.NoError(t, err)
+	wtEnv := Load(ctx, hdp, wtFS, doltdb.LocalDirDoltDB, "test")
+	require.True(t, wtEnv.Valid())
+	assert.True(t, wtEnv.IsLinkedWorktree())
+	assert.Equal(t, "feature", wtEnv.RepoState.CWBHeadRef().GetPath())
+
+	// Both worktrees see the same database
+	wtHead, err := wtEnv.HeadCommit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, mustHash(head.HashOf()), mustHash(wtHead.HashOf()))
+	assert.Same(t, mainEnv.DoltDB.ValueReadWriter(), wtEnv.DoltDB.ValueReadWriter())
+
+	worktrees, err := wtEnv.Worktrees()
+	require.NoError(t, err)
+	assert.Equal(t, []Worktree{
+		{Path: mainPath, Branch: DefaultInitBranch, Main: true},
+		{Path: wtPath, Branch: "feature"},
+	}, worktrees)
+
+	// A branch can't be checked out in two worktrees
+	assert.True(t, ErrBranchCheckedOutInWorktree.Is(mainEnv.CheckBranchNotInOtherWorktree("feature")))
+	assert.NoError(t, mainEnv.CheckBranchNotInOtherWorktree(DefaultInitBranch))
+	assert.NoError(t, mainEnv.CheckBranchNotInOtherWorktree("other"))
+	err = wtEnv.RepoStateWriter().SetCWBHeadRef(ctx, ref.MarshalableRef{Ref: ref.NewBranchRef(DefaultInitBranch)})
+	assert.True(t, ErrBranchCheckedOutInWorktree.Is(err))
+	require.NoError(t, wtEnv.RepoStateWriter().SetCWBHeadRef(ctx, ref.MarshalableRef{Ref: ref.NewBranchRef("other")}))
+	assert.NoError(t, mainEnv.CheckBranchNotInOtherWorktree("feature"))
+
+	assert.Equal(t, ErrRemoveCurrentWorktree, wtEnv.RemoveWorktree(wtPath))
+	assert.True(t, ErrWorktreeNotFound.Is(wtEnv.RemoveWorktree(mainPath)))
+	require.NoError(t, mainEnv.RemoveWorktree(wtPath))
+	exists, _ := mainEnv.FS.Exists(wtPath)
+	assert.False(t, exists)
+
+	worktrees, err = mainEnv.Worktrees()
+	require.NoError(t, err)
+	assert.Equal(t, []Worktree{{Path: mainPath, Branch: DefaultInitBranch, Main: true}}, worktrees)
+}
@@ -0,0 +1,266 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// matViewRow is a row of the dolt_materialized_views system table.
+type matViewRow struct {
+	name       string
+	key, value val.Tuple
+}
+
+// materializedViewMerge merges the materialized views of the two sides of a merge. The table of a view and its row of
+// dolt_materialized_views must reflect the same commit, so neither is merged row by row. Instead, a view is taken
+// from their side if only their side changed it, and kept from our side otherwise. Either way, the next refresh of
+// the view applies the changes made since the commit it reflects, which include the changes of the merge.
+type materializedViewMerge struct {
+	ours      *doltdb.Table
+	theirs    *doltdb.Table
+	oursRows  map[string]matViewRow
+	theirRows map[string]matViewRow
+	theirRoot doltdb.RootValue
+	// tables are the lower-case names of the tables merged here, which are the tables of the views and the
+	// dolt_materialized_views table itself
+	tables map[string]struct{}
+	// theirViews are the lower-case names of the views taken from their side
+	theirViews []string
+}
+
+// newMaterializedViewMerge returns the merge of the materialized views of the given roots, or nil if none of them
+// has any.
+func newMaterializedViewMerge(ctx context.Context, ourRoot, theirRoot, ancRoot doltdb.RootValue) (*materializedViewMerge, error) {
+	if !types.IsFormat_DOLT(ourRoot.VRW().Format()) {
+		return nil, nil
+	}
+	ours, oursRows, err := loadMatViewRows(ctx, ourRoot)
+	if err != nil {
+		return nil, err
+	}
+	theirs, theirRows, err := loadMatViewRows(ctx, theirRoot)
+	if err != nil {
+		return nil, err
+	}
+	anc, ancRows, err := loadMatViewRows(ctx, ancRoot)
+	if err != nil {
+		return nil, err
+	}
+	if ours == nil && theirs == nil && anc == nil {
+		return nil, nil
+	}
+
+	m := &materializedViewMerge{
+		ours:      ours,
+		theirs:    theirs,
+		oursRows:  oursRows,
+		theirRows: theirRows,
+		theirRoot: theirRoot,
+		tables:    map[string]struct{}{doltdb.MaterializedViewsTableName: {}},
+	}
+	for _, rows := range []map[string]matViewRow{oursRows, theirRows, ancRows} {
+		for name, row := range rows {
+			if _, ok := m.tables[name]; ok {
+				continue
+			}
+			m.tables[name] = struct{}{}
+
+			oursChanged, err := matViewChanged(ctx, ourRoot, ancRoot, row.name, oursRows, ancRows)
+			if err != nil {
+				return nil, err
+			}
+			theirsChanged, err := matViewChanged(ctx, theirRoot, ancRoot, row.name, theirRows, ancRows)
+			if err != nil {
+				return nil, err
+			}
+			if theirsChanged && !oursChanged {
+				m.theirViews = append(m.theirViews, name)
+			}
+		}
+	}
+	return m, nil
+}
+
+// handles returns whether the table |name| is merged by this materialized view merge instead of row by row.
+func (m *materializedViewMerge) handles(name doltdb.TableName) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m.tables[strings.ToLower(name.Name)]
+	return ok
+}
+
+// apply puts the views taken from their side into |mergedRoot|, which holds our side of the views.
+func (m *materializedViewMerge) apply(ctx context.Context, mergedRoot doltdb.RootValue, tblToStats map[string]*MergeStats) (doltdb.RootValue, error) {
+	if m == nil || len(m.theirViews) == 0 {
+		return mergedRoot, nil
+	}
+
+	for _, name := range m.theirViews {
+		tblName := name
+		if row, ok := m.theirRows[name]; ok {
+			tblName = row.name
+		} else if row, ok = m.oursRows[name]; ok {
+			tblName = row.name
+		}
+
+		tbl, theirName, ok, err := doltdb.GetTableInsensitive(ctx, m.theirRoot, doltdb.TableName{Name: tblName})
+		if err != nil {
+			return nil, err
+		}
+		_, ourName, hasOurs, err := doltdb.GetTableInsensitive(ctx, mergedRoot, doltdb.TableName{Name: tblName})
+		if err != nil {
+			return nil, err
+		}
+		if hasOurs && (!ok || ourName != theirName) {
+			if mergedRoot, err = mergedRoot.RemoveTables(ctx, false, false, doltdb.TableName{Name: ourName}); err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			if mergedRoot, err = mergedRoot.PutTable(ctx, doltdb.TableName{Name: theirName}, tbl); err != nil {
+				return nil, err
+			}
+			tblToStats[theirName] = &MergeStats{Operation: TableModified}
+		} else if hasOurs {
+			tblToStats[ourName] = &MergeStats{Operation: TableRemoved}
+		}
+	}
+
+	// The rows of the views taken from their side replace ours
+	base, baseRows := m.ours, m.oursRows
+	if base == nil {
+		base, baseRows = m.theirs, m.theirRows
+	}
+	if base == nil {
+		return mergedRoot, nil
+	}
+	idx, err := base.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mut := durable.ProllyMapFromIndex(idx).Mutate()
+	taken := make(map[string]bool)
+	for _, name := range m.theirViews {
+		taken[name] = true
+	}
+	names := make(map[string]struct{})
+	for name := range m.oursRows {
+		names[name] = struct{}{}
+	}
+	for name := range m.theirRows {
+		names[name] = struct{}{}
+	}
+	for name := range names {
+		want, wantOk := m.oursRows[name]
+		if taken[name] {
+			want, wantOk = m.theirRows[name]
+		}
+		have, haveOk := baseRows[name]
+		if wantOk {
+			if !haveOk || !bytes.Equal(have.value, want.value) {
+				if err = mut.Put(ctx, want.key, want.value); err != nil {
+					return nil, err
+				}
+			}
+		} else if haveOk {
+			if err = mut.Delete(ctx, have.key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	rows, err := mut.Map(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, err := base.UpdateRows(ctx, durable.IndexFromProllyMap(rows))
+	if err != nil {
+		return nil, err
+	}
+	tblToStats[doltdb.MaterializedViewsTableName] = &MergeStats{Operation: TableModified}
+	return mergedRoot.PutTable(ctx, doltdb.TableName{Name: doltdb.MaterializedViewsTableName}, tbl)
+}
+
+// matViewChanged returns whether the view |name| changed between |ancRoot| and |root|, either in its row of
+// dolt_materialized_views or in its table.
+func matViewChanged(ctx context.Context, root, ancRoot doltdb.RootValue, name string, rows, ancRows map[string]matViewRow) (bool, error) {
+	row, ok := rows[strings.ToLower(name)]
+	ancRow, ancOk := ancRows[strings.ToLower(name)]
+	if ok != ancOk || (ok && !bytes.Equal(row.value, ancRow.value)) {
+		return true, nil
+	}
+	h, err := matViewTableHash(ctx, root, name)
+	if err != nil {
+		return false, err
+	}
+	ancH, err := matViewTableHash(ctx, ancRoot, name)
+	if err != nil {
+		return false, err
+	}
+	return h != ancH, nil
+}
+
+// matViewTableHash returns the hash of the table |name| of |root|, or the empty hash if it doesn't exist.
+func matViewTableHash(ctx context.Context, root doltdb.RootValue, name string) (hash.Hash, error) {
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: name})
+	if err != nil || !ok {
+		return hash.Hash{}, err
+	}
+	return tbl.HashOf()
+}
+
+// loadMatViewRows returns the dolt_materialized_views table of |root| and its rows keyed by lower-case view name.
+func loadMatViewRows(ctx context.Context, root doltdb.RootValue) (*doltdb.Table, map[string]matViewRow, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: doltdb.MaterializedViewsTableName})
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDesc, _ := sch.GetMapDescriptors()
+
+	iter, err := durable.ProllyMapFromIndex(idx).IterAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows := make(map[string]matViewRow)
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		name, _ := keyDesc.GetString(0, k)
+		rows[strings.ToLower(name)] = matViewRow{name: name, key: k, value: v}
+	}
+	return tbl, rows, nil
+}
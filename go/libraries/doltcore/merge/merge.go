@@ -23,6 +23,7 @@ import (
 	goerrors "gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -48,7 +49,9 @@ var ErrMultipleViolationsForRow = errors.New("multiple violations for row not su
 
 var ErrSameTblAddedTwice = goerrors.NewKind("table with same name '%s' added in 2 commits can't be merged")
 
-func MergeCommits(ctx *sql.Context, commit, mergeCommit *doltdb.Commit, opts editor.Options) (*Result, error) {
+// MergeCommits merges |mergeCommit| into |commit|. Row conflicts identical to one of the recorded |resolutions|, which
+// may be nil, are resolved the same way.
+func MergeCommits(ctx *sql.Context, commit, mergeCommit *doltdb.Commit, opts editor.Options, resolutions *rerere.Resolutions) (*Result, error) {
 	optCmt, err := doltdb.GetCommitAncestor(ctx, commit, mergeCommit)
 	if err != nil {
 		return nil, err
@@ -77,6 +80,7 @@ func MergeCommits(ctx *sql.Context, commit, mergeCommit *doltdb.Commit, opts edi
 	mo := MergeOpts{
 		IsCherryPick:        false,
 		KeepSchemaConflicts: true,
+		Resolutions:         resolutions,
 	}
 	return MergeRoots(ctx, ourRoot, theirRoot, ancRoot, mergeCommit, ancCommit, opts, mo)
 }
@@ -241,15 +245,34 @@ func MergeRoots(
 		return nil, err
 	}
 
+	// Conflicts are resolved with the merge strategies declared on our side of the merge
+	merger.strategies, err = doltdb.GetMergeStrategyRules(ctx, ourRoot)
+	if err != nil {
+		return nil, err
+	}
+
 	destSchemaNames, err := getDatabaseSchemaNames(ctx, ourRoot)
 	if err != nil {
 		return nil, err
 	}
 
+	// Materialized views are merged as a whole, along with their rows of dolt_materialized_views
+	matViews, err := newMaterializedViewMerge(ctx, ourRoot, theirRoot, ancRoot)
+	if err != nil {
+		return nil, err
+	}
+
 	// visitedTables holds all tables that were added, removed, or modified (basically not "unmodified")
 	visitedTables := make(map[string]struct{})
 	var schConflicts []SchemaConflict
 	for _, tblName := range tblNames {
+		if matViews.handles(tblName) {
+			continue
+		}
+		if doltdb.IsVectorIndexTable(tblName.Name) {
+			// The pseudo-index tables of vector indexes are rebuilt from the merged tables later
+			continue
+		}
 		mergedTable, stats, err := merger.MergeTable(ctx, tblName, opts, mergeOpts)
 
 		if errors.Is(ErrTableDeletedAndModified, err) && doltdb.IsFullTextTable(tblName.Name) {
@@ -336,11 +359,21 @@ func MergeRoots(
 		}
 	}
 
+	mergedRoot, err = matViews.apply(ctx, mergedRoot, tblToStats)
+	if err != nil {
+		return nil, err
+	}
+
 	mergedRoot, err = rebuildFullTextIndexes(ctx, mergedRoot, ourRoot, theirRoot, visitedTables)
 	if err != nil {
 		return nil, err
 	}
 
+	mergedRoot, err = rebuildVectorIndexes(ctx, mergedRoot, ourRoot)
+	if err != nil {
+		return nil, err
+	}
+
 	mergedFKColl, conflicts, err := ForeignKeysMerge(ctx, mergedRoot, ourRoot, theirRoot, ancRoot)
 	if err != nil {
 		return nil, err
@@ -30,6 +30,7 @@ import (
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/expranalysis"
@@ -75,6 +76,9 @@ func mergeProllyTable(
 	}
 	leftRows := durable.ProllyMapFromIndex(lr)
 	valueMerger := newValueMerger(mergedSch, tm.leftSch, tm.rightSch, tm.ancSch, leftRows.Pool(), tm.ns)
+	if err = valueMerger.setStrategies(tm.name.Name, tm.strategies); err != nil {
+		return nil, nil, err
+	}
 
 	if !valueMerger.leftMapping.IsIdentityMapping() {
 		mergeInfo.LeftNeedsRewrite = true
@@ -151,7 +155,7 @@ func mergeProllyTableData(ctx *sql.Context, tm *TableMerger, finalSch schema.Sch
 	if err != nil {
 		return nil, nil, err
 	}
-	conflicts, err := newConflictMerger(ctx, tm, artEditor)
+	conflicts, err := newConflictMerger(ctx, tm, finalSch, mergeInfo, artEditor)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -182,6 +186,12 @@ func mergeProllyTableData(ctx *sql.Context, tm *TableMerger, finalSch schema.Sch
 		} else if err != nil {
 			return nil, nil, err
 		}
+		if diff.Op == tree.DiffOpDivergentDeleteConflict {
+			diff = valueMerger.resolveDeleteConflict(diff)
+		}
+		if diff.Op == tree.DiffOpDivergentModifyConflict || diff.Op == tree.DiffOpDivergentDeleteConflict {
+			diff = conflicts.reuseResolution(diff)
+		}
 		cnt, err := uniq.validateDiff(ctx, diff)
 		if err != nil {
 			return nil, nil, err
@@ -990,9 +1000,18 @@ type conflictMerger struct {
 	ae           *prolly.ArtifactsEditor
 	rightRootish hash.Hash
 	meta         []byte
+
+	// tableName and valDesc identify the conflicts of this table when reusing their recorded resolutions.
+	tableName string
+	valDesc   val.TupleDesc
+	keyless   bool
+	// reusable is false when recorded resolutions can't be applied to this merge, because the rows on the left side
+	// of the merge must be migrated to a new schema.
+	reusable    bool
+	resolutions *rerere.Resolutions
 }
 
-func newConflictMerger(ctx context.Context, tm *TableMerger, ae *prolly.ArtifactsEditor) (*conflictMerger, error) {
+func newConflictMerger(ctx context.Context, tm *TableMerger, finalSch schema.Schema, mergeInfo MergeInfo, ae *prolly.ArtifactsEditor) (*conflictMerger, error) {
 	has, err := tm.leftTbl.HasConflicts(ctx)
 	if err != nil {
 		return nil, err
@@ -1033,6 +1052,11 @@ func newConflictMerger(ctx context.Context, tm *TableMerger, ae *prolly.Artifact
 		meta:         meta,
 		rightRootish: rightHash,
 		ae:           ae,
+		tableName:    tm.name.Name,
+		valDesc:      finalSch.GetValueDescriptor(),
+		keyless:      schema.IsKeyless(finalSch),
+		reusable:     !mergeInfo.LeftNeedsRewrite,
+		resolutions:  tm.resolutions,
 	}, nil
 }
 
@@ -1046,6 +1070,50 @@ func (m *conflictMerger) merge(ctx context.Context, diff tree.ThreeWayDiff, _ sc
 	return m.ae.Add(ctx, diff.Key, m.rightRootish, prolly.ArtifactTypeConflict, m.meta)
 }
 
+// resolutionKey returns the key that a resolution of the row conflict |diff| is recorded under. Conflicts of keyless
+// tables have no key, since their rows can't be identified across merges.
+func (m *conflictMerger) resolutionKey(diff tree.ThreeWayDiff) (hash.Hash, bool) {
+	switch diff.Op {
+	case tree.DiffOpDivergentModifyConflict, tree.DiffOpDivergentDeleteConflict:
+	default:
+		return hash.Hash{}, false
+	}
+	if m.keyless {
+		return hash.Hash{}, false
+	}
+	return rerere.ConflictKey(m.tableName, m.valDesc, diff.Key, diff.Base, diff.Left, diff.Right), true
+}
+
+// reuseResolution resolves the row conflict |diff| with the resolution recorded for an identical conflict, if there is
+// one. Otherwise, |diff| is returned unchanged.
+func (m *conflictMerger) reuseResolution(diff tree.ThreeWayDiff) tree.ThreeWayDiff {
+	if m.resolutions.Len() == 0 || !m.reusable {
+		return diff
+	}
+	rk, ok := m.resolutionKey(diff)
+	if !ok {
+		return diff
+	}
+	res, ok := m.resolutions.Get(rk)
+	if !ok {
+		return diff
+	}
+
+	switch {
+	case res.IsDelete() && diff.Left == nil:
+		diff.Op = tree.DiffOpDivergentDeleteResolved
+	case res.IsDelete():
+		// the row is deleted from the left side as it was modified there, rather than as it was in the base
+		diff.Op = tree.DiffOpRightDelete
+		diff.Base = diff.Left
+		diff.Right = nil
+	default:
+		diff.Op = tree.DiffOpDivergentModifyResolved
+		diff.Merged = val.Tuple(res.Value)
+	}
+	return diff
+}
+
 func (m *conflictMerger) finalize(ctx context.Context) (durable.ArtifactIndex, error) {
 	am, err := m.ae.Flush(ctx)
 	if err != nil {
@@ -1625,6 +1693,11 @@ type valueMerger struct {
 	syncPool                               pool.BuffPool
 	keyless                                bool
 	ns                                     tree.NodeStore
+	// strategies holds the dolt_merge_strategies strategy of each column of the merged schema, or is nil if the
+	// table has none.
+	strategies []columnStrategy
+	// rowStrategy is the strategy declared for all columns of the table, if any.
+	rowStrategy doltdb.MergeStrategy
 }
 
 func newValueMerger(merged, leftSch, rightSch, baseSch schema.Schema, syncPool pool.BuffPool, ns tree.NodeStore) *valueMerger {
@@ -1896,6 +1969,10 @@ func (m *valueMerger) processColumn(ctx *sql.Context, i int, left, right, base v
 			return leftCol, false, nil
 		}
 
+		if m.hasStrategy(i) {
+			return m.applyStrategy(ctx, i, nil, leftCol, rightCol, left, right)
+		}
+
 		// conflicting inserts
 		return nil, true, nil
 	}
@@ -1961,6 +2038,10 @@ func (m *valueMerger) processColumn(ctx *sql.Context, i int, left, right, base v
 			return leftCol, false, nil
 		}
 		// concurrent modification
+		// if the table declares a merge strategy for the column, it takes precedence over merging JSON changes.
+		if m.hasStrategy(i) {
+			return m.applyStrategy(ctx, i, baseCol, leftCol, rightCol, left, right)
+		}
 		// if the result type is JSON, we can attempt to merge the JSON changes.
 		dontMergeJsonVar, err := ctx.Session.GetSessionVariable(ctx, "dolt_dont_merge_json")
 		if err != nil {
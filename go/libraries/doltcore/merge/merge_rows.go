@@ -23,6 +23,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/utils/set"
@@ -52,6 +53,10 @@ type MergeOpts struct {
 	// dolt_verify_constraints() stored procedure to allow callers to verify constraints for a
 	// subset of tables.
 	RecordViolationsForTables map[doltdb.TableName]struct{}
+	// Resolutions are the recorded resolutions of earlier row conflicts. When this merge produces a row conflict
+	// identical to one of them, the conflict is resolved the same way instead of being recorded. When this field is
+	// nil, no conflicts are resolved this way.
+	Resolutions *rerere.Resolutions
 }
 
 type TableMerger struct {
@@ -76,6 +81,12 @@ type TableMerger struct {
 	// exception is for the dolt_verify_constraints() stored procedure, which allows callers to
 	// only record constraint violations for a specified subset of tables.
 	recordViolations bool
+
+	// strategies are the dolt_merge_strategies rules declared for this table.
+	strategies doltdb.MergeStrategyRules
+
+	// resolutions are the recorded conflict resolutions to reuse for this table's row conflicts.
+	resolutions *rerere.Resolutions
 }
 
 func (tm TableMerger) tableHashes() (left, right, anc hash.Hash, err error) {
@@ -107,6 +118,9 @@ type RootMerger struct {
 
 	vrw types.ValueReadWriter
 	ns  tree.NodeStore
+
+	// strategies are the dolt_merge_strategies rules used to resolve conflicts.
+	strategies doltdb.MergeStrategyRules
 }
 
 // NewMerger creates a new merger utility object.
@@ -211,6 +225,8 @@ func (rm *RootMerger) makeTableMerger(ctx context.Context, tblName doltdb.TableN
 		vrw:              rm.vrw,
 		ns:               rm.ns,
 		recordViolations: recordViolations,
+		strategies:       rm.strategies.ForTable(tblName.Name),
+		resolutions:      mergeOpts.Resolutions,
 	}
 
 	var err error
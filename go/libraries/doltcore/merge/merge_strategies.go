@@ -0,0 +1,258 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/shopspring/decimal"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// columnStrategy is the merge strategy that resolves conflicting changes to a column of the merged schema.
+type columnStrategy struct {
+	strategy doltdb.MergeStrategy
+	// lwwIdx is the index of the column compared by doltdb.MergeStrategyLastWriterWins in the merged schema.
+	lwwIdx int
+}
+
+// setStrategies configures |m| to resolve conflicts with the dolt_merge_strategies |rules| of the table |tableName|.
+// Rules for columns that are not in the merged schema are ignored.
+func (m *valueMerger) setStrategies(tableName string, rules doltdb.MergeStrategyRules) error {
+	if len(rules) == 0 || m.keyless {
+		return nil
+	}
+
+	cols := m.resultSchema.GetNonPKCols()
+	m.strategies = make([]columnStrategy, m.numCols)
+	i := 0
+	for _, col := range cols.GetColumns() {
+		if col.Virtual {
+			continue
+		}
+		if rule, ok := rules.ForColumn(col.Name); ok {
+			cs := columnStrategy{strategy: rule.Strategy, lwwIdx: -1}
+			if rule.Strategy == doltdb.MergeStrategyLastWriterWins {
+				lwwCol, ok := cols.GetByNameCaseInsensitive(rule.LwwColumn)
+				if ok {
+					cs.lwwIdx, ok = cols.StoredIndexByTag(lwwCol.Tag)
+				}
+				if !ok {
+					return fmt.Errorf("merge strategy for column '%s' of table '%s' compares unknown column '%s'", col.Name, tableName, rule.LwwColumn)
+				}
+			}
+			m.strategies[i] = cs
+		}
+		i++
+	}
+
+	for _, rule := range rules {
+		if rule.Column == doltdb.MergeStrategyAllColumns {
+			m.rowStrategy = rule.Strategy
+		}
+	}
+	return nil
+}
+
+// hasStrategy returns whether conflicting changes to column |i| of the merged schema are resolved by a merge strategy.
+func (m *valueMerger) hasStrategy(i int) bool {
+	return m.strategies != nil && m.strategies[i].strategy != ""
+}
+
+// applyStrategy resolves conflicting changes to column |i| of the merged schema with its merge strategy. |base|,
+// |left| and |right| are the values of the column, converted to the merged schema, and |leftRow| and |rightRow| are
+// the rows they were taken from. |base| is nil if both sides inserted the row. The returned bool is true if the
+// strategy could not resolve the conflict.
+func (m *valueMerger) applyStrategy(ctx *sql.Context, i int, base, left, right []byte, leftRow, rightRow val.Tuple) ([]byte, bool, error) {
+	cs := m.strategies[i]
+	switch cs.strategy {
+	case doltdb.MergeStrategyOurs:
+		return left, false, nil
+	case doltdb.MergeStrategyTheirs:
+		return right, false, nil
+	case doltdb.MergeStrategyMax, doltdb.MergeStrategyMin:
+		// like the MAX() and MIN() aggregates, prefer any value over NULL
+		if left == nil {
+			return right, false, nil
+		} else if right == nil {
+			return left, false, nil
+		}
+		cmp, err := m.compareValues(ctx, i, left, right)
+		if err != nil {
+			return nil, false, err
+		}
+		if (cmp >= 0) == (cs.strategy == doltdb.MergeStrategyMax) {
+			return left, false, nil
+		}
+		return right, false, nil
+	case doltdb.MergeStrategySum:
+		if base == nil || left == nil || right == nil {
+			return nil, true, nil
+		}
+		return m.sumDeltas(ctx, i, base, left, right)
+	case doltdb.MergeStrategyLastWriterWins:
+		leftTs, err := m.sideValue(ctx, cs.lwwIdx, leftRow, m.leftVD, m.leftMapping)
+		if err != nil {
+			return nil, false, err
+		}
+		rightTs, err := m.sideValue(ctx, cs.lwwIdx, rightRow, m.rightVD, m.rightMapping)
+		if err != nil {
+			return nil, false, err
+		}
+		if leftTs == nil && rightTs == nil {
+			return nil, true, nil
+		} else if rightTs == nil {
+			return left, false, nil
+		} else if leftTs == nil {
+			return right, false, nil
+		}
+		cmp, err := m.compareValues(ctx, cs.lwwIdx, leftTs, rightTs)
+		if err != nil {
+			return nil, false, err
+		}
+		switch {
+		case cmp > 0:
+			return left, false, nil
+		case cmp < 0:
+			return right, false, nil
+		default:
+			// both sides were written at the same time, so neither is the last writer
+			return nil, true, nil
+		}
+	default:
+		return nil, true, nil
+	}
+}
+
+// resolveDeleteConflict applies the merge strategy for all columns of the table, if any, to |diff|, a row that was
+// deleted on one side of the merge and modified on the other. It returns |diff| with the operation that carries out
+// the strategy, or |diff| unchanged if there is no strategy that resolves it.
+func (m *valueMerger) resolveDeleteConflict(diff tree.ThreeWayDiff) tree.ThreeWayDiff {
+	keepLeft := m.rowStrategy == doltdb.MergeStrategyOurs
+	keepRight := m.rowStrategy == doltdb.MergeStrategyTheirs
+	switch {
+	case m.keyless || !(keepLeft || keepRight):
+	case keepLeft && diff.Left == nil:
+		diff.Op = tree.DiffOpDivergentDeleteResolved
+	case keepRight && diff.Right == nil:
+		// the row is deleted from the left side as it was modified there, rather than as it was in the base
+		diff.Op = tree.DiffOpRightDelete
+		diff.Base = diff.Left
+	case keepLeft:
+		// the modified row is already on the left side of the merge
+		diff.Op = tree.DiffOpLeftModify
+	case keepRight:
+		// the left side deleted the row, so the right side's row is added back
+		diff.Op = tree.DiffOpRightAdd
+		diff.Base = nil
+	}
+	return diff
+}
+
+// sideValue returns the value of column |i| of the merged schema in |tup|, a row of one side of the merge with value
+// descriptor |vd| and mapping |mapping| to the merged schema, converted to the merged schema.
+func (m *valueMerger) sideValue(ctx *sql.Context, i int, tup val.Tuple, vd val.TupleDesc, mapping val.OrdinalMapping) ([]byte, error) {
+	col, colIdx, ok := getColumn(&tup, &mapping, i)
+	if !ok {
+		return nil, nil
+	}
+	return convert(ctx, vd, m.resultVD, m.resultSchema, colIdx, i, tup, col, m.ns)
+}
+
+// compareValues compares the values |left| and |right| of column |i| of the merged schema by their SQL type, since
+// the encoded values of some types, such as TEXT, are not ordered.
+func (m *valueMerger) compareValues(ctx *sql.Context, i int, left, right []byte) (int, error) {
+	l, err := m.decodeValue(ctx, i, left)
+	if err != nil {
+		return 0, err
+	}
+	r, err := m.decodeValue(ctx, i, right)
+	if err != nil {
+		return 0, err
+	}
+	return m.resultSchema.GetNonPKCols().GetByStoredIndex(i).TypeInfo.ToSqlType().Compare(l, r)
+}
+
+// sumDeltas merges the values of numeric column |i| of the merged schema by adding the changes that |left| and
+// |right| made to |base|. The returned bool is true if the column is not numeric or the sum is out of range.
+func (m *valueMerger) sumDeltas(ctx *sql.Context, i int, base, left, right []byte) ([]byte, bool, error) {
+	var sum decimal.Decimal
+	for j, cell := range [][]byte{base, left, right} {
+		v, err := m.decodeValue(ctx, i, cell)
+		if err != nil {
+			return nil, false, err
+		}
+		d, ok := decimalValue(v)
+		if !ok {
+			return nil, true, nil
+		}
+		if j == 0 {
+			sum = sum.Sub(d)
+		} else {
+			sum = sum.Add(d)
+		}
+	}
+
+	sqlType := m.resultSchema.GetNonPKCols().GetByStoredIndex(i).TypeInfo.ToSqlType()
+	v, inRange, err := sqlType.Convert(sum)
+	if err != nil || inRange == sql.OutOfRange {
+		return nil, true, nil
+	}
+	tb := val.NewTupleBuilder(val.NewTupleDescriptor(m.resultVD.Types[i]))
+	if err = tree.PutField(ctx, m.ns, tb, 0, v); err != nil {
+		return nil, false, err
+	}
+	return tb.Build(m.syncPool).GetField(0), false, nil
+}
+
+// decodeValue returns the SQL value of |cell|, a value of column |i| of the merged schema.
+func (m *valueMerger) decodeValue(ctx *sql.Context, i int, cell []byte) (interface{}, error) {
+	desc := val.NewTupleDescriptor(m.resultVD.Types[i])
+	return tree.GetField(ctx, desc, 0, val.NewTuple(m.syncPool, cell), m.ns)
+}
+
+func decimalValue(v interface{}) (decimal.Decimal, bool) {
+	switch v := v.(type) {
+	case int8:
+		return decimal.NewFromInt(int64(v)), true
+	case int16:
+		return decimal.NewFromInt(int64(v)), true
+	case int32:
+		return decimal.NewFromInt(int64(v)), true
+	case int64:
+		return decimal.NewFromInt(v), true
+	case uint8:
+		return decimal.NewFromInt(int64(v)), true
+	case uint16:
+		return decimal.NewFromInt(int64(v)), true
+	case uint32:
+		return decimal.NewFromInt(int64(v)), true
+	case uint64:
+		return decimal.NewFromBigInt(new(big.Int).SetUint64(v), 0), true
+	case float32:
+		return decimal.NewFromFloat32(v), true
+	case float64:
+		return decimal.NewFromFloat(v), true
+	case decimal.Decimal:
+		return v, true
+	default:
+		return decimal.Decimal{}, false
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor/creation"
@@ -349,6 +350,77 @@ func TestMergeCommits(t *testing.T) {
 	require.Equal(t, eh.String(), h.String(), "table hashes do not equal")
 }
 
+// TestMergeCommitsReusesResolutions checks that the row conflicts of a merge that are identical to a recorded
+// resolution are resolved the same way, rather than recorded as conflicts.
+func TestMergeCommitsReusesResolutions(t *testing.T) {
+	if !types.IsFormat_DOLT(types.Format_Default) {
+		t.Skip()
+	}
+
+	ddb, vrw, ns, rightCommitHash, ancCommitHash, root, mergeRoot, ancRoot, _, _ := setupMergeTest(t)
+	defer ddb.Close()
+	resolved := map[int]*rowV{
+		5:  {50, 50},
+		7:  nil,
+		9:  nil,
+		15: {15, -15},
+	}
+	resolutions, expectedRows := setupResolutions(t, ns, resolved)
+
+	merger, err := NewMerger(root, mergeRoot, ancRoot, rightCommitHash, ancCommitHash, vrw, ns)
+	require.NoError(t, err)
+	opts := editor.TestEditorOptions(vrw)
+	merged, _, err := merger.MergeTable(sql.NewContext(context.Background()), doltdb.TableName{Name: tableName}, opts, MergeOpts{Resolutions: resolutions})
+	require.NoError(t, err)
+
+	ctx := sql.NewEmptyContext()
+	artIdx, err := merged.table.GetArtifacts(ctx)
+	require.NoError(t, err)
+	cnt, err := durable.ProllyMapFromArtifactIndex(artIdx).Count()
+	require.NoError(t, err)
+	assert.Equal(t, 0, cnt)
+
+	mergedRows, err := merged.table.GetRowData(ctx)
+	require.NoError(t, err)
+	MustEqualProlly(t, tableName, expectedRows, durable.ProllyMapFromIndex(mergedRows))
+}
+
+// setupResolutions returns the recorded resolutions of the conflicting rows of |testRows| in |resolved|, where a nil
+// row is resolved by deleting it, and the rows expected after a merge which reuses them.
+func setupResolutions(t *testing.T, ns tree.NodeStore, resolved map[int]*rowV) (*rerere.Resolutions, prolly.Map) {
+	sideValue := func(testCase testRow, action ActionType, v *rowV) val.Tuple {
+		switch action {
+		case NoopAction:
+			return unwrap(testCase.initialValue)
+		case DeleteAction:
+			return nil
+		default:
+			return unwrap(v)
+		}
+	}
+
+	resolutions := rerere.NewResolutions()
+	var expectedKVs []val.Tuple
+	for _, testCase := range testRows {
+		expected := testCase.expectedValue
+		if testCase.conflict {
+			v, ok := resolved[testCase.key]
+			require.True(t, ok, "no resolution for the conflict of row %d", testCase.key)
+			rk := rerere.ConflictKey(tableName, vD, key(testCase.key), unwrap(testCase.initialValue),
+				sideValue(testCase, testCase.leftAction, testCase.leftValue), sideValue(testCase, testCase.rightAction, testCase.rightValue))
+			resolutions.Record(rk, rerere.Resolution{Table: tableName, Value: unwrap(v)})
+			expected = v
+		}
+		if expected != nil {
+			expectedKVs = append(expectedKVs, key(testCase.key), expected.value())
+		}
+	}
+
+	expectedRows, err := prolly.NewMapFromTuples(context.Background(), ns, kD, vD, expectedKVs...)
+	require.NoError(t, err)
+	return resolutions, expectedRows
+}
+
 func TestNomsMergeCommits(t *testing.T) {
 	if types.IsFormat_DOLT(types.Format_Default) {
 		t.Skip()
@@ -0,0 +1,98 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/vectorindex"
+)
+
+// rebuildVectorIndexes brings the pseudo-index tables of the vector indexes declared in |mergedRoot| up to date with
+// the merged rows of their tables. Pseudo-index tables aren't merged themselves: since the contents of a vector index
+// depend on nothing but the rows of its table, the pseudo-index table of |ourRoot| is kept when the merge left the
+// table and the index unchanged, and the index is rebuilt otherwise. Pseudo-index tables of indexes that are no longer
+// declared are removed.
+func rebuildVectorIndexes(ctx *sql.Context, mergedRoot, ourRoot doltdb.RootValue) (doltdb.RootValue, error) {
+	defs, err := vectorindex.Load(ctx, mergedRoot)
+	if err != nil {
+		return nil, err
+	}
+	ourDefs, err := vectorindex.Load(ctx, ourRoot)
+	if err != nil {
+		return nil, err
+	}
+	ourDefSet := make(map[vectorindex.Definition]struct{}, len(ourDefs))
+	for _, def := range ourDefs {
+		ourDefSet[def] = struct{}{}
+	}
+
+	indexTables := make(map[string]struct{})
+	for _, def := range defs {
+		tblName := doltdb.TableName{Name: def.Table}
+		mergedTbl, ok, err := mergedRoot.GetTable(ctx, tblName)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		indexTables[def.IndexTableName()] = struct{}{}
+
+		rebuild := false
+		if _, ok := ourDefSet[def]; !ok {
+			rebuild = true
+		} else if hasIndex, err := mergedRoot.HasTable(ctx, doltdb.TableName{Name: def.IndexTableName()}); err != nil {
+			return nil, err
+		} else if !hasIndex {
+			rebuild = true
+		} else if ourTbl, ok, err := ourRoot.GetTable(ctx, tblName); err != nil {
+			return nil, err
+		} else if !ok {
+			rebuild = true
+		} else {
+			mergedHash, err := mergedTbl.HashOf()
+			if err != nil {
+				return nil, err
+			}
+			ourHash, err := ourTbl.HashOf()
+			if err != nil {
+				return nil, err
+			}
+			rebuild = mergedHash != ourHash
+		}
+
+		if rebuild {
+			if mergedRoot, err = vectorindex.Build(ctx, mergedRoot, def); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	allTableNames, err := mergedRoot.GetTableNames(ctx, doltdb.DefaultSchemaName)
+	if err != nil {
+		return nil, err
+	}
+	var orphans []doltdb.TableName
+	for _, name := range allTableNames {
+		if _, ok := indexTables[name]; !ok && doltdb.IsVectorIndexTable(name) {
+			orphans = append(orphans, doltdb.TableName{Name: name})
+		}
+	}
+	if len(orphans) == 0 {
+		return mergedRoot, nil
+	}
+	return mergedRoot.RemoveTables(ctx, true, true, orphans...)
+}
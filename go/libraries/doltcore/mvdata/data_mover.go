@@ -48,6 +48,11 @@ type ParquetOptions struct {
 	SchFile   string
 }
 
+type ArrowOptions struct {
+	TableName string
+	SchFile   string
+}
+
 type MoverOptions struct {
 	ContinueOnErr  bool
 	Force          bool
@@ -17,6 +17,7 @@ package mvdata
 import (
 	"context"
 	"fmt"
+	"runtime"
 
 	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/sql"
@@ -50,6 +51,8 @@ func NewSqlEngineReader(ctx context.Context, dEnv *env.DoltEnv, tableName string
 	config := &engine.SqlEngineConfig{
 		ServerUser: "root",
 		Autocommit: true,
+		// an export reads every row of the table, so its partitions are read in parallel
+		QueryParallelism: runtime.GOMAXPROCS(0),
 	}
 	se, err := engine.NewSqlEngine(
 		ctx,
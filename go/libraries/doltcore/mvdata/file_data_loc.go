@@ -28,6 +28,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/arrow"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/json"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/parquet"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/csv"
@@ -51,11 +52,16 @@ func DFFromString(dfStr string) DataFormat {
 		return SqlFile
 	case "parquet", ".parquet":
 		return ParquetFile
+	case "arrow", ".arrow", "feather", featherFileExt:
+		return ArrowFile
 	default:
 		return InvalidDataFormat
 	}
 }
 
+// featherFileExt is the extension of Feather V2 files, which are Arrow IPC files.
+const featherFileExt = ".feather"
+
 // FileDataLocation is a file that that can be imported from or exported to.
 type FileDataLocation struct {
 	// Path is the path of the file on the filesystem
@@ -179,6 +185,37 @@ func (dl FileDataLocation) NewReader(ctx context.Context, dEnv *env.DoltEnv, opt
 		}
 		rd, rErr := parquet.OpenParquetReader(root.VRW(), dl.Path, tableSch)
 		return rd, false, rErr
+
+	case ArrowFile:
+		var tableSch schema.Schema
+		arrowOpts, _ := opts.(ArrowOptions)
+		if arrowOpts.SchFile != "" {
+			tn, s, tnErr := SchAndTableNameFromFile(ctx, arrowOpts.SchFile, dEnv)
+			if tnErr != nil {
+				return nil, false, tnErr
+			}
+			if tn != arrowOpts.TableName {
+				return nil, false, fmt.Errorf("table name '%s' from schema file %s does not match table arg '%s'", tn, arrowOpts.SchFile, arrowOpts.TableName)
+			}
+			tableSch = s
+		} else {
+			if opts == nil {
+				return nil, false, errors.New("Unable to determine table name on Arrow import")
+			}
+			tbl, tableExists, tErr := root.GetTable(ctx, doltdb.TableName{Name: arrowOpts.TableName})
+			if tErr != nil {
+				return nil, false, fmt.Errorf("An error occurred attempting to read the table:\n%v", tErr.Error())
+			}
+			if !tableExists {
+				return nil, false, fmt.Errorf("The following table could not be found:\n%v", arrowOpts.TableName)
+			}
+			tableSch, err = tbl.GetSchema(ctx)
+			if err != nil {
+				return nil, false, fmt.Errorf("An error occurred attempting to read the table schema:\n%v", err.Error())
+			}
+		}
+		rd, rErr := arrow.OpenArrowReader(root.VRW(), dl.Path, tableSch)
+		return rd, false, rErr
 	}
 
 	return nil, false, errors.New("unsupported format")
@@ -204,6 +241,8 @@ func (dl FileDataLocation) NewCreatingWriter(ctx context.Context, mvOpts DataMov
 		}
 	case ParquetFile:
 		return parquet.NewParquetRowWriterForFile(outSch, mvOpts.DestName())
+	case ArrowFile:
+		return arrow.NewArrowRowWriterForFile(outSch, mvOpts.DestName())
 	}
 
 	panic("Invalid Data Format." + string(dl.Format))
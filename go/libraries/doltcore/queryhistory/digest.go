@@ -0,0 +1,125 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryhistory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// placeholder replaces the literals of a statement in its digest text.
+const placeholder = "?"
+
+// collapsedList replaces a parenthesized list of only literals, such as the values of an IN list or of a row being
+// inserted, so that statements differing only in the number of values share a digest.
+const collapsedList = "(...)"
+
+// Digest returns the digest text of |query|, which is the query with its literals replaced by placeholders, its
+// comments removed, its keywords upper-cased and its whitespace normalized, and the digest, which is the SHA-256 hash
+// of the digest text. Statements that differ only in their literals have the same digest.
+func Digest(query string) (digest string, text string) {
+	text = DigestText(query)
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:]), text
+}
+
+// DigestText returns the digest text of |query|. See Digest.
+func DigestText(query string) string {
+	tkn := sqlparser.NewStringTokenizer(query)
+	var tokens []string
+	for {
+		start := tkn.Position
+		typ, val := tkn.Scan()
+		if typ == 0 {
+			break
+		}
+		switch {
+		case typ == sqlparser.LEX_ERROR:
+			// keep the rest of the query as it is
+			rest := strings.Join(strings.Fields(query[max(0, start-1):]), " ")
+			if rest != "" {
+				tokens = append(tokens, rest)
+			}
+			return joinTokens(tokens)
+		case typ == sqlparser.COMMENT:
+			continue
+		case isLiteral(typ):
+			tokens = append(tokens, placeholder)
+		case typ == sqlparser.ID:
+			tokens = append(tokens, "`"+strings.ReplaceAll(string(val), "`", "``")+"`")
+		case sqlparser.KeywordString(typ) != "":
+			tokens = append(tokens, strings.ToUpper(sqlparser.KeywordString(typ)))
+		case typ < 256:
+			tokens = append(tokens, string(rune(typ)))
+		case len(val) > 0:
+			tokens = append(tokens, string(val))
+		default:
+			// operators of more than one character, such as <=
+			tokens = append(tokens, strings.TrimSpace(query[max(0, start-1):max(0, tkn.Position-1)]))
+		}
+		tokens = collapseLists(tokens)
+	}
+	return joinTokens(tokens)
+}
+
+func isLiteral(typ int) bool {
+	switch typ {
+	case sqlparser.STRING, sqlparser.INTEGRAL, sqlparser.FLOAT, sqlparser.HEXNUM, sqlparser.HEX,
+		sqlparser.BIT_LITERAL, sqlparser.VALUE_ARG, sqlparser.LIST_ARG:
+		return true
+	}
+	return false
+}
+
+// collapseLists replaces the list at the end of |tokens|, if it was just closed, it holds only literals and it is an
+// IN list or a row of VALUES, with collapsedList. A collapsed row following another one is dropped, so that inserts of
+// any number of rows share a digest.
+func collapseLists(tokens []string) []string {
+	n := len(tokens)
+	if n == 0 || tokens[n-1] != ")" {
+		return tokens
+	}
+	// the list must alternate literals and commas, from its last literal back to its opening parenthesis
+	i := n - 2
+	for ; i >= 0 && tokens[i] != "("; i-- {
+		if (n-2-i)%2 == 0 {
+			if tokens[i] != placeholder {
+				return tokens
+			}
+		} else if tokens[i] != "," {
+			return tokens
+		}
+	}
+	if i < 1 || (n-2-i)%2 == 0 {
+		return tokens
+	}
+	switch tokens[i-1] {
+	case "IN", "VALUES", "VALUE":
+		return append(tokens[:i], collapsedList)
+	case ",":
+		if i >= 2 && tokens[i-2] == collapsedList {
+			return tokens[:i-1]
+		}
+	}
+	return tokens
+}
+
+// joinTokens joins |tokens| with single spaces, as MySQL digest texts are.
+func joinTokens(tokens []string) string {
+	return strings.Join(tokens, " ")
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryhistory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestText(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"select * from t where a = 1 and b='x'", "SELECT * FROM `t` WHERE `a` = ? AND `b` = ?"},
+		{"SELECT  a,b FROM db1.t WHERE c IN (1,2, 3) -- comment", "SELECT `a` , `b` FROM `db1` . `t` WHERE `c` IN (...)"},
+		{"insert into t values (1,'a'),(2,'b'), (3, 'c')", "INSERT INTO `t` VALUES (...)"},
+		{"insert into t values (1, now())", "INSERT INTO `t` VALUES ( ? , NOW ( ) )"},
+		{"select count(*) from t where x <= 5.5 /* comment */ and y != 0x1F", "SELECT COUNT ( * ) FROM `t` WHERE `x` <= ? AND `y` != ?"},
+		{"update t set a = a + ? where id = :v1", "UPDATE `t` SET `a` = `a` + ? WHERE `id` = ?"},
+		{"select substr(s, 1, 2) from t", "SELECT SUBSTR ( `s` , ? , ? ) FROM `t`"},
+		{"call dolt_commit('-am', 'msg')", "CALL `dolt_commit` ( ? , ? )"},
+		{"select `odd``name` from t", "SELECT `odd``name` FROM `t`"},
+		{"select 'unterminated", "SELECT 'unterminated"},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			assert.Equal(t, test.expected, DigestText(test.query))
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	d1, text := Digest("select * from t where id = 1")
+	d2, _ := Digest("SELECT *\n FROM t WHERE id = 42")
+	d3, _ := Digest("select * from t where id > 1")
+	assert.Equal(t, d1, d2)
+	assert.NotEqual(t, d1, d3)
+	assert.Len(t, d1, 64)
+	assert.Equal(t, "SELECT * FROM `t` WHERE `id` = ?", text)
+}
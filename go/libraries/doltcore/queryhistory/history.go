@@ -0,0 +1,294 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryhistory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/auditlog"
+)
+
+// Entry is the record of a statement that ran on the server.
+type Entry struct {
+	Digest       string    `json:"digest"`
+	DigestText   string    `json:"digest_text"`
+	Query        string    `json:"query"`
+	ConnectionID uint32    `json:"connection_id"`
+	User         string    `json:"user"`
+	Database     string    `json:"database,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	StartTime    time.Time `json:"start_time"`
+	// LatencyMicros is the time the statement took to run, including sending its results, in microseconds.
+	LatencyMicros int64 `json:"latency_micros"`
+	// RowsExamined is the number of rows read by the table scans of the statement.
+	RowsExamined uint64 `json:"rows_examined"`
+	RowsReturned uint64 `json:"rows_returned"`
+	RowsAffected uint64 `json:"rows_affected"`
+	// Plan is the plan the statement was run with, as shown by EXPLAIN PLAN. Statements that aren't planned, such as
+	// those that fail to parse, have no plan.
+	Plan  string `json:"plan,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Config is the configuration of a History.
+type Config struct {
+	// Path is the path of the file the history is persisted to.
+	Path string
+	// Size is the number of entries kept. Once the history is full, each new entry replaces the oldest one.
+	Size int
+	// FlushInterval is the interval at which the history is persisted, if new entries were added since it last was.
+	// If 0, the history is only persisted when it is closed.
+	FlushInterval time.Duration
+}
+
+// Statement is a statement being run, which becomes an Entry of the history when it finishes.
+type Statement struct {
+	Entry
+	start        time.Time
+	rowsExamined atomic.Uint64
+	plan         atomic.Pointer[string]
+}
+
+// SetQuery replaces the text of the statement with |query|, such as when it turns out to be the first statement of a
+// multi-statement query. Passwords set by the statement are redacted from its text, as they are in the audit log.
+func (s *Statement) SetQuery(query string) {
+	s.Digest, s.DigestText = Digest(query)
+	s.Query = auditlog.RedactQuery(query)
+}
+
+// SetPlan sets the plan of the statement.
+func (s *Statement) SetPlan(plan string) {
+	s.plan.Store(&plan)
+}
+
+// AddRowsExamined adds |n| to the rows examined by the statement. It is safe to call concurrently.
+func (s *Statement) AddRowsExamined(n uint64) {
+	s.rowsExamined.Add(n)
+}
+
+// History is a ring buffer of the Entries of the most recent statements run on the server, which is persisted to a
+// file. It is safe for concurrent use.
+type History struct {
+	cfg Config
+
+	mu      sync.Mutex
+	entries []Entry
+	// next is the index of entries that the next entry is written to, once the buffer is full.
+	next    int
+	dirty   bool
+	running map[uint32]*Statement
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open opens the history described by |cfg|, loading the entries persisted to its file, if it exists.
+func Open(cfg Config) (*History, error) {
+	if cfg.Size <= 0 {
+		return nil, errors.New("query history size must be positive")
+	}
+	h := &History{
+		cfg:     cfg,
+		entries: make([]Entry, 0, cfg.Size),
+		running: make(map[uint32]*Statement),
+	}
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	if cfg.FlushInterval > 0 {
+		h.stop, h.done = make(chan struct{}), make(chan struct{})
+		go h.flushPeriodically()
+	}
+	return h, nil
+}
+
+func (h *History) load() error {
+	f, err := os.Open(h.cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// skip entries that can't be read, rather than losing the rest of the history
+			continue
+		}
+		h.add(e)
+	}
+	h.dirty = false
+	return scanner.Err()
+}
+
+// Start records that |query| started running on the connection |connID|. The returned Statement becomes an entry of
+// the history when it's passed to Finish.
+func (h *History) Start(connID uint32, query string, start time.Time) *Statement {
+	s := &Statement{start: start}
+	s.SetQuery(query)
+	s.ConnectionID = connID
+	s.StartTime = start.UTC()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running[connID] = s
+	return s
+}
+
+// Running returns the statement running on the connection |connID|, or nil if there is none.
+func (h *History) Running(connID uint32) *Statement {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.running[connID]
+}
+
+// Finish adds |s| to the history, replacing the oldest entry if the history is full.
+func (h *History) Finish(s *Statement, err error) {
+	e := s.Entry
+	e.LatencyMicros = time.Since(s.start).Microseconds()
+	e.RowsExamined = s.rowsExamined.Load()
+	if plan := s.plan.Load(); plan != nil {
+		e.Plan = *plan
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.running[s.ConnectionID] == s {
+		delete(h.running, s.ConnectionID)
+	}
+	h.add(e)
+}
+
+func (h *History) add(e Entry) {
+	if len(h.entries) < h.cfg.Size {
+		h.entries = append(h.entries, e)
+	} else {
+		h.entries[h.next] = e
+		h.next = (h.next + 1) % h.cfg.Size
+	}
+	h.dirty = true
+}
+
+// Entries returns the entries of the history, oldest first.
+func (h *History) Entries() []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ret := make([]Entry, 0, len(h.entries))
+	ret = append(ret, h.entries[h.next:]...)
+	return append(ret, h.entries[:h.next]...)
+}
+
+// Save persists the history to its file, if new entries were added since it was last persisted. The file is replaced
+// atomically, so that a crash while saving doesn't lose the entries saved before.
+func (h *History) Save() (err error) {
+	h.mu.Lock()
+	if !h.dirty {
+		h.mu.Unlock()
+		return nil
+	}
+	h.dirty = false
+	h.mu.Unlock()
+	defer func() {
+		if err != nil {
+			h.mu.Lock()
+			h.dirty = true
+			h.mu.Unlock()
+		}
+	}()
+	entries := h.Entries()
+
+	// the history holds the text of statements, so only the user running the server may read it
+	if err := os.MkdirAll(filepath.Dir(h.cfg.Path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(h.cfg.Path), filepath.Base(h.cfg.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range entries {
+		if err = enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), h.cfg.Path)
+}
+
+func (h *History) flushPeriodically() {
+	defer close(h.done)
+	ticker := time.NewTicker(h.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = h.Save()
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// Close stops persisting the history periodically, and persists it one last time.
+func (h *History) Close() error {
+	if h.stop != nil {
+		close(h.stop)
+		<-h.done
+		h.stop = nil
+	}
+	return h.Save()
+}
+
+var active *History
+var mutex sync.Mutex
+
+// ActiveHistory returns the query history of the SQL server running in this process, or nil if it has none.
+func ActiveHistory() *History {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return active
+}
+
+// SetActiveHistory sets the query history of the SQL server running in this process. A nil history unsets it.
+func SetActiveHistory(h *History) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	active = h
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryhistory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queries(entries []Entry) []string {
+	var ret []string
+	for _, e := range entries {
+		ret = append(ret, e.Query)
+	}
+	return ret
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "query_history.jsonl")
+	h, err := Open(Config{Path: path, Size: 3})
+	require.NoError(t, err)
+
+	s := h.Start(7, "select * from t where id = 1", time.Now())
+	s.User, s.Database, s.Branch = "root", "db", "main"
+	s.RowsReturned = 1
+	s.SetPlan("Filter\n └─ Table")
+	s.AddRowsExamined(10)
+	s.AddRowsExamined(5)
+	assert.Same(t, s, h.Running(7))
+	h.Finish(s, nil)
+	assert.Nil(t, h.Running(7))
+
+	s = h.Start(7, "select * from missing", time.Now())
+	h.Finish(s, errors.New("table not found: missing"))
+
+	entries := h.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "SELECT * FROM `t` WHERE `id` = ?", entries[0].DigestText)
+	assert.Equal(t, uint32(7), entries[0].ConnectionID)
+	assert.Equal(t, "root", entries[0].User)
+	assert.Equal(t, "main", entries[0].Branch)
+	assert.Equal(t, uint64(15), entries[0].RowsExamined)
+	assert.Equal(t, uint64(1), entries[0].RowsReturned)
+	assert.Equal(t, "Filter\n └─ Table", entries[0].Plan)
+	assert.Equal(t, "table not found: missing", entries[1].Error)
+
+	// the oldest entries are replaced once the history is full
+	for i := 0; i < 4; i++ {
+		h.Finish(h.Start(8, fmt.Sprintf("select %d", i), time.Now()), nil)
+	}
+	assert.Equal(t, []string{"select 1", "select 2", "select 3"}, queries(h.Entries()))
+
+	// the history is persisted when closed, and loaded when opened
+	require.NoError(t, h.Close())
+	h, err = Open(Config{Path: path, Size: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select 2", "select 3"}, queries(h.Entries()))
+	require.NoError(t, h.Close())
+}
+
+func TestHistoryRedactsPasswords(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+	path := filepath.Join(dir, "query_history.jsonl")
+	h, err := Open(Config{Path: path, Size: 3})
+	require.NoError(t, err)
+
+	h.Finish(h.Start(7, "create user bob identified by 'bobs password'", time.Now()), nil)
+	s := h.Start(7, "select 1; alter user bob identified by 'new password'", time.Now())
+	s.SetQuery("alter user bob identified by 'new password'")
+	h.Finish(s, nil)
+	assert.Equal(t, []string{"create user `bob`@`%` identified by '<secret>'", "alter user `bob`@`%` identified by '<secret>'"}, queries(h.Entries()))
+	require.NoError(t, h.Close())
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "create user")
+	assert.NotContains(t, string(contents), "bobs password")
+	assert.NotContains(t, string(contents), "new password")
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+}
+
+func TestHistoryFlushesPeriodically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "query_history.jsonl")
+	h, err := Open(Config{Path: path, Size: 10, FlushInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer h.Close()
+
+	h.Finish(h.Start(1, "select 1", time.Now()), nil)
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var entries []Entry
+	for i := 1; i <= 100; i++ {
+		digest, text := Digest(fmt.Sprintf("select * from t where id = %d", i))
+		entries = append(entries, Entry{
+			Digest:        digest,
+			DigestText:    text,
+			Query:         fmt.Sprintf("select * from t where id = %d", i),
+			StartTime:     start.Add(time.Duration(i) * time.Second),
+			LatencyMicros: int64(i),
+			RowsReturned:  1,
+		})
+	}
+	digest, text := Digest("select 1")
+	entries = append(entries, Entry{Digest: digest, DigestText: text, Query: "select 1", StartTime: start, LatencyMicros: 1000, Error: "oops"})
+
+	summaries := Summarize(entries)
+	require.Len(t, summaries, 2)
+
+	s := summaries[0]
+	assert.Equal(t, "SELECT * FROM `t` WHERE `id` = ?", s.DigestText)
+	assert.Equal(t, "select * from t where id = 100", s.SampleQuery)
+	assert.Equal(t, uint64(100), s.Count)
+	assert.Equal(t, int64(5050), s.TotalMicros)
+	assert.Equal(t, int64(50), s.AvgMicros)
+	assert.Equal(t, int64(50), s.P50Micros)
+	assert.Equal(t, int64(95), s.P95Micros)
+	assert.Equal(t, int64(99), s.P99Micros)
+	assert.Equal(t, int64(100), s.MaxMicros)
+	assert.Equal(t, uint64(100), s.RowsReturned)
+	assert.Equal(t, start.Add(time.Second), s.FirstSeen)
+	assert.Equal(t, start.Add(100*time.Second), s.LastSeen)
+
+	s = summaries[1]
+	assert.Equal(t, uint64(1), s.Count)
+	assert.Equal(t, uint64(1), s.ErrorCount)
+	assert.Equal(t, int64(1000), s.P99Micros)
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryhistory
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DigestSummary aggregates the entries of the history that share a digest.
+type DigestSummary struct {
+	Digest     string
+	DigestText string
+	// SampleQuery is the text of the most recent statement with the digest.
+	SampleQuery  string
+	Count        uint64
+	ErrorCount   uint64
+	TotalMicros  int64
+	AvgMicros    int64
+	P50Micros    int64
+	P95Micros    int64
+	P99Micros    int64
+	MaxMicros    int64
+	RowsExamined uint64
+	RowsReturned uint64
+	RowsAffected uint64
+	FirstSeen    time.Time
+	LastSeen     time.Time
+}
+
+// Summarize aggregates |entries| by digest. The summaries are ordered by their total latency, highest first.
+func Summarize(entries []Entry) []DigestSummary {
+	byDigest := make(map[string]*DigestSummary)
+	latencies := make(map[string][]int64)
+	var order []string
+	for _, e := range entries {
+		s, ok := byDigest[e.Digest]
+		if !ok {
+			s = &DigestSummary{Digest: e.Digest, DigestText: e.DigestText, FirstSeen: e.StartTime}
+			byDigest[e.Digest] = s
+			order = append(order, e.Digest)
+		}
+		s.Count++
+		if e.Error != "" {
+			s.ErrorCount++
+		}
+		s.TotalMicros += e.LatencyMicros
+		if e.LatencyMicros > s.MaxMicros {
+			s.MaxMicros = e.LatencyMicros
+		}
+		s.RowsExamined += e.RowsExamined
+		s.RowsReturned += e.RowsReturned
+		s.RowsAffected += e.RowsAffected
+		if e.StartTime.Before(s.FirstSeen) {
+			s.FirstSeen = e.StartTime
+		}
+		if !e.StartTime.Before(s.LastSeen) {
+			s.LastSeen = e.StartTime
+			s.SampleQuery = e.Query
+		}
+		latencies[e.Digest] = append(latencies[e.Digest], e.LatencyMicros)
+	}
+
+	ret := make([]DigestSummary, len(order))
+	for i, digest := range order {
+		s := byDigest[digest]
+		l := latencies[digest]
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+		s.AvgMicros = s.TotalMicros / int64(s.Count)
+		s.P50Micros = percentile(l, 0.50)
+		s.P95Micros = percentile(l, 0.95)
+		s.P99Micros = percentile(l, 0.99)
+		ret[i] = *s
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].TotalMicros > ret[j].TotalMicros })
+	return ret
+}
+
+// percentile returns the |p| percentile of |sorted| by the nearest-rank method.
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -17,6 +17,7 @@ package rebase
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/shopspring/decimal"
@@ -33,11 +34,18 @@ const (
 	RebaseActionFixup  = "fixup"
 	RebaseActionDrop   = "drop"
 	RebaseActionReword = "reword"
+	RebaseActionEdit   = "edit"
+	RebaseActionExec   = "exec"
+	RebaseActionBreak  = "break"
 )
 
 // ErrInvalidRebasePlanSquashFixupWithoutPick is returned when a rebase plan attempts to squash or
 // fixup a commit without first picking or rewording a commit.
-var ErrInvalidRebasePlanSquashFixupWithoutPick = fmt.Errorf("invalid rebase plan: squash and fixup actions must appear after a pick or reword action")
+var ErrInvalidRebasePlanSquashFixupWithoutPick = fmt.Errorf("invalid rebase plan: squash and fixup actions must appear after a pick, reword or edit action")
+
+// ErrInvalidRebasePlanExecWithoutStatement is returned when a rebase plan contains an exec action without a SQL
+// statement to run.
+var ErrInvalidRebasePlanExecWithoutStatement = fmt.Errorf("invalid rebase plan: exec actions must have a SQL statement in the exec_statement column")
 
 // RebasePlanDatabase is a database that can save and load a rebase plan.
 type RebasePlanDatabase interface {
@@ -54,12 +62,15 @@ type RebasePlan struct {
 }
 
 // RebasePlanStep describes a single step in a rebase plan, such as dropping a
-// commit, squashing a commit into the previous commit, etc.
+// commit, squashing a commit into the previous commit, etc. Exec and break steps
+// don't reference a commit.
 type RebasePlanStep struct {
 	RebaseOrder decimal.Decimal
 	Action      string
 	CommitHash  string
 	CommitMsg   string
+	// ExecStatement is the SQL statement run by an exec step
+	ExecStatement string
 }
 
 // RebaseOrderAsFloat returns the RebaseOrder as a float32. Float32 provides enough scale and precision to hold
@@ -108,7 +119,7 @@ func CreateDefaultRebasePlan(ctx *sql.Context, startCommit, upstreamCommit *dolt
 }
 
 // ValidateRebasePlan returns a validation error for invalid states in a rebase plan, such as
-// squash or fixup actions appearing in the plan before a pick or reword action.
+// squash or fixup actions appearing in the plan before a pick, reword or edit action.
 func ValidateRebasePlan(ctx *sql.Context, plan *RebasePlan) error {
 	seenPick := false
 	seenReword := false
@@ -120,7 +131,7 @@ func ValidateRebasePlan(ctx *sql.Context, plan *RebasePlan) error {
 		}
 
 		switch step.Action {
-		case RebaseActionPick:
+		case RebaseActionPick, RebaseActionEdit:
 			seenPick = true
 
 		case RebaseActionReword:
@@ -130,6 +141,15 @@ func ValidateRebasePlan(ctx *sql.Context, plan *RebasePlan) error {
 			if !seenPick && !seenReword {
 				return ErrInvalidRebasePlanSquashFixupWithoutPick
 			}
+
+		case RebaseActionExec:
+			if strings.TrimSpace(step.ExecStatement) == "" {
+				return ErrInvalidRebasePlanExecWithoutStatement
+			}
+			continue
+
+		case RebaseActionBreak:
+			continue
 		}
 
 		if err := validateCommit(ctx, step.CommitHash); err != nil {
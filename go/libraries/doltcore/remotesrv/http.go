@@ -35,6 +35,7 @@ import (
 
 	"github.com/dolthub/dolt/go/libraries/utils/filesys"
 	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
@@ -49,9 +50,11 @@ type filehandler struct {
 	readOnly bool
 	lgr      *logrus.Entry
 	sealer   Sealer
+	// keys decrypt table files that are encrypted at rest, which are served decrypted
+	keys *nbs.EncryptionKeys
 }
 
-func newFileHandler(lgr *logrus.Entry, dbCache DBCache, fs filesys.Filesys, readOnly bool, sealer Sealer) filehandler {
+func newFileHandler(lgr *logrus.Entry, dbCache DBCache, fs filesys.Filesys, readOnly bool, sealer Sealer, keys *nbs.EncryptionKeys) filehandler {
 	return filehandler{
 		dbCache,
 		fs,
@@ -60,6 +63,7 @@ func newFileHandler(lgr *logrus.Entry, dbCache DBCache, fs filesys.Filesys, read
 			"service": "dolt.services.remotesapi.v1alpha1.HttpFileServer",
 		}),
 		sealer,
+		keys,
 	}
 }
 
@@ -107,7 +111,7 @@ func (fh filehandler) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
 			return
 		}
 		respWr.Header().Add("Accept-Ranges", "bytes")
-		logger, statusCode = readTableFile(logger, abs, respWr, req.Header.Get("Range"))
+		logger, statusCode = readTableFile(logger, fh.keys, abs, respWr, req.Header.Get("Range"))
 
 	case http.MethodPost, http.MethodPut:
 		if fh.readOnly {
@@ -178,14 +182,14 @@ func (fh filehandler) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func readTableFile(logger *logrus.Entry, path string, respWr http.ResponseWriter, rangeStr string) (*logrus.Entry, int) {
+func readTableFile(logger *logrus.Entry, keys *nbs.EncryptionKeys, path string, respWr http.ResponseWriter, rangeStr string) (*logrus.Entry, int) {
 	var r io.ReadCloser
 	var readSize int64
 	var fileErr error
 	{
 		if rangeStr == "" {
 			logger = logger.WithField("whole_file", true)
-			r, readSize, fileErr = getFileReader(path)
+			r, readSize, fileErr = getFileReader(path, keys)
 		} else {
 			offset, length, headerStr, err := offsetAndLenFromRange(rangeStr)
 			if err != nil {
@@ -198,7 +202,7 @@ func readTableFile(logger *logrus.Entry, path string, respWr http.ResponseWriter
 			})
 			readSize = length
 			var fSize int64
-			r, fSize, fileErr = getFileReaderAt(path, offset, length)
+			r, fSize, fileErr = getFileReaderAt(path, keys, offset, length)
 			if fileErr == nil {
 				respWr.Header().Add("Content-Range", headerStr+strconv.Itoa(int(fSize)))
 			}
@@ -358,22 +362,22 @@ func offsetAndLenFromRange(rngStr string) (int64, int64, string, error) {
 
 // getFileReader opens a file at the given path and returns an io.ReadCloser,
 // the corresponding file's filesize, and a http status.
-func getFileReader(path string) (io.ReadCloser, int64, error) {
-	return openFile(path)
-}
-
-func openFile(path string) (*os.File, int64, error) {
-	info, err := os.Stat(path)
+func getFileReader(path string, keys *nbs.EncryptionKeys) (io.ReadCloser, int64, error) {
+	f, fSize, err := openFile(path, keys)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get stats for file at path %s: %w", path, err)
+		return nil, 0, err
 	}
+	return closerReaderWrapper{io.NewSectionReader(f, 0, fSize), f}, fSize, nil
+}
 
-	f, err := os.Open(path)
+// openFile opens the table file at |path|, decrypting it if it is encrypted at rest.
+func openFile(path string, keys *nbs.EncryptionKeys) (nbs.StorageFileReader, int64, error) {
+	f, err := nbs.OpenStorageFile(path, keys)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to open file at path %s: %w", path, err)
 	}
 
-	return f, info.Size(), nil
+	return f, f.Size(), nil
 }
 
 type closerReaderWrapper struct {
@@ -381,22 +385,18 @@ type closerReaderWrapper struct {
 	io.Closer
 }
 
-func getFileReaderAt(path string, offset int64, length int64) (io.ReadCloser, int64, error) {
-	f, fSize, err := openFile(path)
+func getFileReaderAt(path string, keys *nbs.EncryptionKeys, offset int64, length int64) (io.ReadCloser, int64, error) {
+	f, fSize, err := openFile(path, keys)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	if fSize < int64(offset+length) {
+		f.Close()
 		return nil, 0, fmt.Errorf("failed to read file %s at offset %d, length %d: %w", path, offset, length, ErrReadOutOfBounds)
 	}
 
-	_, err = f.Seek(int64(offset), 0)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to seek file at path %s to offset %d: %w", path, offset, err)
-	}
-
-	r := closerReaderWrapper{io.LimitReader(f, length), f}
+	r := closerReaderWrapper{io.NewSectionReader(f, offset, length), f}
 	return r, fSize, nil
 }
 
@@ -30,7 +30,9 @@ import (
 
 	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/tracing"
 	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/nbs"
 )
 
 type Server struct {
@@ -88,6 +90,12 @@ func NewServer(args ServerArgs) (*Server, error) {
 		return nil, errors.New("archive files present. Please run `dolt archive --revert` before running the server.")
 	}
 
+	// table files encrypted at rest are decrypted before they're served
+	keys, err := nbs.EncryptionKeysFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	s := new(Server)
 	s.stopChan = make(chan struct{})
 
@@ -104,7 +112,8 @@ func NewServer(args ServerArgs) (*Server, error) {
 
 	s.wg.Add(2)
 	s.grpcListenAddr = args.GrpcListenAddr
-	s.grpcSrv = grpc.NewServer(append([]grpc.ServerOption{grpc.MaxRecvMsgSize(128 * 1024 * 1024)}, args.Options...)...)
+	opts := append([]grpc.ServerOption{grpc.MaxRecvMsgSize(128 * 1024 * 1024)}, tracing.ServerOptions()...)
+	s.grpcSrv = grpc.NewServer(append(opts, args.Options...)...)
 	var chnkSt remotesapi.ChunkStoreServiceServer = NewHttpFSBackedChunkStore(args.Logger, args.HttpHost, args.DBCache, args.FS, scheme, args.ConcurrencyControl, sealer)
 
 	if args.ReadOnly {
@@ -112,7 +121,7 @@ func NewServer(args ServerArgs) (*Server, error) {
 	}
 	remotesapi.RegisterChunkStoreServiceServer(s.grpcSrv, chnkSt)
 
-	var handler http.Handler = newFileHandler(args.Logger, args.DBCache, args.FS, args.ReadOnly, sealer)
+	var handler http.Handler = newFileHandler(args.Logger, args.DBCache, args.FS, args.ReadOnly, sealer, keys)
 	if args.HttpInterceptor != nil {
 		handler = args.HttpInterceptor(handler)
 	}
@@ -418,6 +418,11 @@ func (gr *GetRange) GetDownloadFunc(ctx context.Context, stats StatsRecorder, he
 		return func() error { return nil }
 	}
 	return func() error {
+		ctx, span := tracer.Start(ctx, "remotestorage.GetRange.Download", trace.WithAttributes(
+			attribute.String("resource_path", gr.ResourcePath()),
+			attribute.Int("num_chunks", len(gr.Ranges)),
+			attribute.Int64("length", int64(gr.RangeLen()))))
+		defer span.End()
 		urlF := func(lastError error) (string, error) {
 			url, err := pathToUrl(ctx, lastError, gr.ResourcePath())
 			if err != nil {
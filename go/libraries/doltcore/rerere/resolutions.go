@@ -0,0 +1,451 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rerere records how row conflicts were resolved, so that the same resolution can be reused ("reuse recorded
+// resolution") when an identical conflict is produced by a later merge, cherry-pick or rebase.
+package rerere
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/fslock"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+const (
+	resolutionsFile     = "rerere.json"
+	resolutionsLockFile = "rerere.lock"
+
+	resolutionsLockTimeout = 10 * time.Second
+)
+
+// ErrResolutionNotFound is returned when forgetting a resolution that has not been recorded.
+var ErrResolutionNotFound = errors.New("no recorded resolution with that id")
+
+// ErrResolutionsLocked is returned when the recorded resolutions can't be updated, because another process holds
+// their lock.
+var ErrResolutionsLocked = errors.New("the recorded conflict resolutions are locked by another process")
+
+// Resolution is the recorded resolution of a row conflict.
+type Resolution struct {
+	Table string `json:"table"`
+	// Key is the primary key of the row in conflict, formatted for display.
+	Key string `json:"key"`
+	// Row is the resolved row, formatted for display. It is empty if the conflict was resolved by deleting the row.
+	Row string `json:"row,omitempty"`
+	// Value is the encoded value of the resolved row, or nil if the conflict was resolved by deleting the row.
+	Value []byte `json:"value,omitempty"`
+}
+
+// IsDelete returns whether the conflict was resolved by deleting the row.
+func (r Resolution) IsDelete() bool {
+	return len(r.Value) == 0
+}
+
+// Resolutions are the recorded conflict resolutions of a repository, keyed by the ConflictKey of each conflict. They
+// are stored as a file in the .dolt directory of the repository, next to repo_state.json.
+type Resolutions struct {
+	byKey map[hash.Hash]Resolution
+}
+
+// NewResolutions returns an empty set of resolutions, to be recorded in a repository with Update.
+func NewResolutions() *Resolutions {
+	return &Resolutions{byKey: make(map[hash.Hash]Resolution)}
+}
+
+// Load loads the recorded resolutions of the repository at the root of |fs|.
+func Load(fs filesys.ReadableFS) (*Resolutions, error) {
+	r := NewResolutions()
+	path := getResolutionsFile()
+	if exists, _ := fs.Exists(path); !exists {
+		return r, nil
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored map[string]Resolution
+	if err = json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	for k, res := range stored {
+		h, ok := hash.MaybeParse(k)
+		if !ok {
+			return nil, errors.New("invalid resolution id in " + resolutionsFile + ": " + k)
+		}
+		r.byKey[h] = res
+	}
+
+	return r, nil
+}
+
+// Update loads the recorded resolutions of the repository at the root of |fs|, calls |update| with them and saves
+// them if it returns true. The resolutions file is locked for the whole update, so that concurrent updates by other
+// sessions or processes are not lost, and it is replaced atomically, so that it is never read half written.
+func Update(fs filesys.Filesys, update func(r *Resolutions) (bool, error)) error {
+	unlock, err := lockResolutions(fs)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	r, err := Load(fs)
+	if err != nil {
+		return err
+	}
+	if changed, err := update(r); err != nil || !changed {
+		return err
+	}
+	return r.save(fs)
+}
+
+// save writes these resolutions to the repository at the root of |fs|, by writing them to a temporary file which
+// replaces the resolutions file.
+func (r *Resolutions) save(fs filesys.Filesys) error {
+	stored := make(map[string]Resolution, len(r.byKey))
+	for k, res := range r.byKey {
+		stored[k.String()] = res
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := getResolutionsFile()
+	tmp := path + ".tmp"
+	if err = fs.WriteFile(tmp, data, os.ModePerm); err != nil {
+		return err
+	}
+	return fs.MoveFile(tmp, path)
+}
+
+// resolutionsMu serializes the updates of resolutions by the sessions of this process, which the file lock doesn't
+// do for the in-memory file systems of tests.
+var resolutionsMu sync.Mutex
+
+// lockResolutions locks the resolutions file of the repository at the root of |fs|, waiting up to
+// resolutionsLockTimeout for another process to release it, and returns the function that unlocks it.
+func lockResolutions(fs filesys.Filesys) (func(), error) {
+	path, err := fs.Abs(filepath.Join(dbfactory.DoltDir, resolutionsLockFile))
+	if err != nil {
+		return nil, err
+	}
+
+	resolutionsMu.Lock()
+	lck := filesys.CreateFilesysLock(fs, path)
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		ok, err := lck.TryLock()
+		if ok && err == nil {
+			break
+		}
+		if err != nil && !errors.Is(err, fslock.ErrLocked) {
+			resolutionsMu.Unlock()
+			return nil, err
+		}
+		if time.Since(start) > resolutionsLockTimeout {
+			resolutionsMu.Unlock()
+			return nil, ErrResolutionsLocked
+		}
+	}
+
+	return func() {
+		_ = lck.Unlock()
+		resolutionsMu.Unlock()
+	}, nil
+}
+
+// Len returns the number of recorded resolutions.
+func (r *Resolutions) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.byKey)
+}
+
+// Get returns the resolution recorded for the conflict with key |k|, if any.
+func (r *Resolutions) Get(k hash.Hash) (Resolution, bool) {
+	if r == nil {
+		return Resolution{}, false
+	}
+	res, ok := r.byKey[k]
+	return res, ok
+}
+
+// Record records |res| as the resolution of the conflict with key |k|, replacing any earlier resolution.
+func (r *Resolutions) Record(k hash.Hash, res Resolution) {
+	r.byKey[k] = res
+}
+
+// Forget removes the resolution recorded for the conflict with key |k|. If there is none, ErrResolutionNotFound is
+// returned.
+func (r *Resolutions) Forget(k hash.Hash) error {
+	if _, ok := r.byKey[k]; !ok {
+		return ErrResolutionNotFound
+	}
+	delete(r.byKey, k)
+	return nil
+}
+
+// RecordAll records each resolution of |o|, replacing any earlier resolution of the same conflict.
+func (r *Resolutions) RecordAll(o *Resolutions) {
+	for k, res := range o.byKey {
+		r.byKey[k] = res
+	}
+}
+
+// Clear removes every recorded resolution.
+func (r *Resolutions) Clear() {
+	r.byKey = make(map[hash.Hash]Resolution)
+}
+
+// Keys returns the keys of every recorded resolution, ordered by table and then by key.
+func (r *Resolutions) Keys() []hash.Hash {
+	keys := make([]hash.Hash, 0, len(r.byKey))
+	for k := range r.byKey {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := r.byKey[keys[i]], r.byKey[keys[j]]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return keys[i].Less(keys[j])
+	})
+	return keys
+}
+
+// ConflictKey returns the key that the resolution of a conflict on the row with key |key| of table |table| is recorded
+// under. A conflict is identified by the values of the row in the merge base and on each side of the merge, and by
+// |vd|, the value descriptor of the table after the merge, since a recorded row can only be reused by a table with the
+// same encoding.
+func ConflictKey(table string, vd val.TupleDesc, key, base, ours, theirs val.Tuple) hash.Hash {
+	buf := make([]byte, 0, len(table)+len(vd.Types)*2+len(key)+len(base)+len(ours)+len(theirs)+32)
+	buf = appendPart(buf, []byte(strings.ToLower(table)))
+	for _, typ := range vd.Types {
+		nullable := byte(0)
+		if typ.Nullable {
+			nullable = 1
+		}
+		buf = append(buf, byte(typ.Enc), nullable)
+	}
+	for _, tup := range []val.Tuple{key, base, ours, theirs} {
+		buf = appendPart(buf, tup)
+	}
+	return hash.Of(buf)
+}
+
+// appendPart appends |part| to |buf| prefixed by its length, so that the parts of a key can't run into each other. A
+// nil part is distinguished from an empty one.
+func appendPart(buf, part []byte) []byte {
+	if part == nil {
+		return binary.AppendUvarint(buf, 0)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(part))+1)
+	return append(buf, part...)
+}
+
+// RecordConflicts records the resolution of each conflict of table |table| in |conflicts| for which |resolved|
+// returns true, or of every conflict if |resolved| is nil. Each conflict is resolved to the current value of its row
+// in |rows|, or to a delete if the row does not exist, and is recorded under the key computed from its rows in
+// |sides|. The conflicts of keyless tables can't be recorded, and callers must not pass them. Returns the number of
+// resolutions recorded.
+func (r *Resolutions) RecordConflicts(
+	ctx context.Context,
+	table string,
+	conflicts prolly.ArtifactMap,
+	rows prolly.Map,
+	sides *MergeSides,
+	resolved func(ctx context.Context, art prolly.ConflictArtifact) (bool, error),
+) (int, error) {
+	iter, err := conflicts.IterAllConflicts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	kd, vd := rows.Descriptors()
+	recorded := 0
+	for {
+		art, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		if resolved != nil {
+			if ok, err := resolved(ctx, art); err != nil {
+				return 0, err
+			} else if !ok {
+				continue
+			}
+		}
+
+		k, err := sides.conflictKey(ctx, table, vd, art)
+		if err != nil {
+			return 0, err
+		}
+		value, err := getRow(ctx, &rows, art.Key)
+		if err != nil {
+			return 0, err
+		}
+
+		res := Resolution{
+			Table: table,
+			Key:   formatTuple(kd, art.Key),
+		}
+		if value != nil {
+			res.Row = formatTuple(vd, value)
+			res.Value = append([]byte(nil), value...)
+		}
+		r.Record(k, res)
+		recorded++
+	}
+
+	return recorded, nil
+}
+
+// MergeSides are the rows of a table on each side of the merges that produced its conflicts, from which the key of
+// each conflict is computed again when it is resolved. The base and their side of a conflict are the roots named by
+// its artifact, and our side is the working root from before the merge, which is kept by the merge state of the
+// working set.
+type MergeSides struct {
+	table doltdb.TableName
+	vrw   types.ValueReadWriter
+	ns    tree.NodeStore
+	// ours is nil if the table did not exist before the merge
+	ours  *prolly.Map
+	roots map[hash.Hash]*prolly.Map
+}
+
+// NewMergeSides returns the MergeSides of the conflicts of table |table| produced by a merge into
+// |preMergeWorking|.
+func NewMergeSides(ctx context.Context, table doltdb.TableName, preMergeWorking doltdb.RootValue) (*MergeSides, error) {
+	s := &MergeSides{
+		table: table,
+		vrw:   preMergeWorking.VRW(),
+		ns:    preMergeWorking.NodeStore(),
+		roots: make(map[hash.Hash]*prolly.Map),
+	}
+	ours, err := s.tableRows(ctx, preMergeWorking)
+	if err != nil {
+		return nil, err
+	}
+	s.ours = ours
+	return s, nil
+}
+
+// conflictKey returns the ConflictKey of the conflict |art| of table |table|, whose rows are described by |vd|.
+func (s *MergeSides) conflictKey(ctx context.Context, table string, vd val.TupleDesc, art prolly.ConflictArtifact) (hash.Hash, error) {
+	baseRows, err := s.rootRows(ctx, art.Metadata.BaseRootIsh)
+	if err != nil {
+		return hash.Hash{}, err
+	}
+	theirRows, err := s.rootRows(ctx, art.TheirRootIsh)
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	var base, ours, theirs val.Tuple
+	if base, err = getRow(ctx, baseRows, art.Key); err != nil {
+		return hash.Hash{}, err
+	}
+	if ours, err = getRow(ctx, s.ours, art.Key); err != nil {
+		return hash.Hash{}, err
+	}
+	if theirs, err = getRow(ctx, theirRows, art.Key); err != nil {
+		return hash.Hash{}, err
+	}
+	return ConflictKey(table, vd, art.Key, base, ours, theirs), nil
+}
+
+// rootRows returns the rows of the table in the commit or working set with hash |h|.
+func (s *MergeSides) rootRows(ctx context.Context, h hash.Hash) (*prolly.Map, error) {
+	if rows, ok := s.roots[h]; ok {
+		return rows, nil
+	}
+	root, err := doltdb.LoadRootValueFromRootIshAddr(ctx, s.vrw, s.ns, h)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.tableRows(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	s.roots[h] = rows
+	return rows, nil
+}
+
+// tableRows returns the rows of the table in |root|, or nil if |root| has no such table.
+func (s *MergeSides) tableRows(ctx context.Context, root doltdb.RootValue) (*prolly.Map, error) {
+	tbl, ok, err := root.GetTable(ctx, s.table)
+	if err != nil || !ok {
+		return nil, err
+	}
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := durable.ProllyMapFromIndex(idx)
+	return &rows, nil
+}
+
+// getRow returns the value of the row with key |key| in |rows|, or nil if there is no such row.
+func getRow(ctx context.Context, rows *prolly.Map, key val.Tuple) (val.Tuple, error) {
+	if rows == nil {
+		return nil, nil
+	}
+	var value val.Tuple
+	err := rows.Get(ctx, key, func(_, v val.Tuple) error {
+		value = v
+		return nil
+	})
+	return value, err
+}
+
+// formatTuple formats the fields of |tup| as a comma separated list.
+func formatTuple(td val.TupleDesc, tup val.Tuple) string {
+	fields := make([]string, len(td.Types))
+	for i := range td.Types {
+		fields[i] = td.FormatValue(i, tup.GetField(i))
+	}
+	return strings.Join(fields, ", ")
+}
+
+func getResolutionsFile() string {
+	return filepath.Join(dbfactory.DoltDir, resolutionsFile)
+}
@@ -115,3 +115,45 @@ const (
 	DoltIgnorePatternTag = iota + SystemTableReservedMin + uint64(8000)
 	DoltIgnoreIgnoredTag
 )
+
+// Tags for the dolt_merge_strategies table
+const (
+	DoltMergeStrategiesTableNameTag = iota + SystemTableReservedMin + uint64(9000)
+	DoltMergeStrategiesColumnNameTag
+	DoltMergeStrategiesStrategyTag
+	DoltMergeStrategiesLwwColumnTag
+)
+
+// Tags for the dolt_row_policies table
+const (
+	DoltRowPoliciesTableNameTag = iota + SystemTableReservedMin + uint64(10000)
+	DoltRowPoliciesPolicyNameTag
+	DoltRowPoliciesUserTag
+	DoltRowPoliciesHostTag
+	DoltRowPoliciesPredicateTag
+)
+
+// Tags for the dolt_tests table
+const (
+	DoltTestsNameTag = iota + SystemTableReservedMin + uint64(11000)
+	DoltTestsGroupTag
+	DoltTestsQueryTag
+	DoltTestsAssertionTypeTag
+	DoltTestsAssertionComparatorTag
+	DoltTestsAssertionValueTag
+)
+
+// Tags for the dolt_materialized_views table
+const (
+	DoltMaterializedViewsNameTag = iota + SystemTableReservedMin + uint64(12000)
+	DoltMaterializedViewsQueryTag
+	DoltMaterializedViewsSourceCommitTag
+	DoltMaterializedViewsRefreshOnCommitTag
+)
+
+// Tags for the dolt_vector_indexes table
+const (
+	DoltVectorIndexesTableNameTag = iota + SystemTableReservedMin + uint64(13000)
+	DoltVectorIndexesIndexNameTag
+	DoltVectorIndexesColumnNameTag
+)
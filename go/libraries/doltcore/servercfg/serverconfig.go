@@ -19,9 +19,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"unicode"
 )
 
 var DefaultUnixSocketFilePath = DefaultMySQLUnixSocketFilePath
@@ -62,6 +65,20 @@ const (
 	DefaultMySQLUnixSocketFilePath = "/tmp/mysql.sock"
 	DefaultMaxLoggedQueryLen       = 0
 	DefaultEncodeLoggedQuery       = false
+	DefaultWebhookMaxRetries       = 3
+	DefaultWebhookTimeoutMillis    = 10 * 1000
+	DefaultAuditLogPath            = "audit_log.jsonl"
+	DefaultAuditLogMaxSizeMB       = 100
+	DefaultAuditLogMaxAgeHours     = 24
+	DefaultAuditLogMaxBackups      = 10
+	DefaultTracingProtocol         = "grpc"
+	DefaultTracingServiceName      = "dolt"
+	DefaultTracingSampleRatio      = 1.0
+	DefaultQueryHistoryPath        = "query_history.jsonl"
+	DefaultQueryHistorySize        = 1000
+	DefaultHeartbeatIntervalMillis = 500
+	DefaultElectionTimeoutMillis   = 5 * 1000
+	DefaultForwardingWaitMillis    = 5 * 1000
 )
 
 const (
@@ -100,6 +117,39 @@ type ClusterConfig interface {
 	BootstrapRole() string
 	BootstrapEpoch() int
 	RemotesAPIConfig() ClusterRemotesAPIConfig
+	// ElectionConfig is the configuration for automatic failover between the servers of the cluster. nil if roles
+	// only change through dolt_assume_cluster_role.
+	ElectionConfig() ClusterElectionConfig
+	// WriteForwardingConfig is the configuration for forwarding the writes a standby receives to the primary. nil if
+	// a standby rejects writes.
+	WriteForwardingConfig() ClusterWriteForwardingConfig
+}
+
+// ClusterElectionConfig is the configuration for electing a primary among the servers of a cluster. When it is
+// present, every server exchanges heartbeats with its standby remotes, and a standby which doesn't hear from a primary
+// within the election timeout asks the other servers to make it the primary at a new epoch.
+type ClusterElectionConfig interface {
+	// HeartbeatIntervalMillis is the interval between two heartbeats sent to each standby remote.
+	HeartbeatIntervalMillis() uint64
+	// ElectionTimeoutMillis is how long a standby waits without hearing from a primary before it starts an
+	// election, and how long a primary keeps its role without hearing from a majority of the cluster.
+	ElectionTimeoutMillis() uint64
+}
+
+// ClusterWriteForwardingConfig is the configuration for forwarding writes from a standby to the primary of the cluster.
+// A standby runs the write transactions of its clients on the primary, over a connection to the sql_address of the
+// standby remote which is the primary, and makes their later reads wait until it has replicated the last Dolt commit
+// they wrote.
+type ClusterWriteForwardingConfig interface {
+	// User is the user which a standby connects to the primary as. Forwarded statements are run on the primary as
+	// the client they're forwarded for, which the primary only allows for connections of its own write_forwarding
+	// user, so every server of the cluster must configure the same user.
+	User() string
+	// Password is the password of User.
+	Password() string
+	// WaitTimeoutMillis is how long a read waits for the standby to replicate the last commit written by its
+	// session before it fails.
+	WaitTimeoutMillis() uint64
 }
 
 type ClusterRemotesAPIConfig interface {
@@ -115,6 +165,78 @@ type ClusterRemotesAPIConfig interface {
 type ClusterStandbyRemoteConfig interface {
 	Name() string
 	RemoteURLTemplate() string
+	// SQLAddress is the host:port of the MySQL listener of the remote, which writes are forwarded to when it is the
+	// primary. "" if it isn't configured.
+	SQLAddress() string
+}
+
+// WebhookConfig is the configuration for a webhook that is notified when a branch head moves.
+type WebhookConfig interface {
+	// Name identifies the webhook, and must be unique.
+	Name() string
+	// URL is the endpoint that notifications are POSTed to.
+	URL() string
+	// Secret is used to sign every notification. "" if notifications are not signed.
+	Secret() string
+	// Databases limits the webhook to the named databases. Empty for every database.
+	Databases() []string
+	// Branches limits the webhook to branches matching these patterns. Empty for every branch.
+	Branches() []string
+	// MaxRetries is the number of times a failed notification is retried before waiting for the next delivery.
+	MaxRetries() int
+	// TimeoutMillis is the timeout for each delivery attempt in milliseconds.
+	TimeoutMillis() uint64
+}
+
+// AuditLogConfig is the configuration for the audit log, which records the connections to the server, the statements
+// run on it and the changes to privileges, one JSON object per line.
+type AuditLogConfig interface {
+	// Path is the path of the audit log file. Relative paths are relative to the cfg_dir.
+	Path() string
+	// Events limits the log to these events: connect, disconnect, statement and privilege. Empty for every event.
+	Events() []string
+	// Users limits the log to the named users. Empty for every user.
+	Users() []string
+	// Databases limits the log to statements run in the named databases. Empty for every database.
+	Databases() []string
+	// MaxSizeMB is the size in megabytes at which the log file is rotated. 0 to never rotate it by size.
+	MaxSizeMB() uint64
+	// MaxAgeHours is the age in hours at which the log file is rotated. 0 to never rotate it by age.
+	MaxAgeHours() uint64
+	// MaxBackups is the number of rotated log files that are kept. 0 to keep all of them.
+	MaxBackups() int
+}
+
+// AuditLogEvents are the events that can be recorded in the audit log.
+var AuditLogEvents = []string{"connect", "disconnect", "statement", "privilege"}
+
+// TracingConfig is the configuration for exporting OpenTelemetry traces of queries, storage reads and remote
+// operations to an OTLP collector.
+type TracingConfig interface {
+	// Endpoint is the host and port of the OTLP collector, such as localhost:4317.
+	Endpoint() string
+	// Protocol is the OTLP protocol used to export spans: grpc or http.
+	Protocol() string
+	// Insecure is true if spans are exported without TLS.
+	Insecure() bool
+	// ServiceName is the service.name resource attribute of the exported spans.
+	ServiceName() string
+	// SampleRatio is the fraction of traces that are sampled, between 0 and 1.
+	SampleRatio() float64
+	// Headers are sent with every export request, such as for authenticating with the collector.
+	Headers() map[string]string
+}
+
+// TracingProtocols are the OTLP protocols that traces can be exported with.
+var TracingProtocols = []string{"grpc", "http"}
+
+// QueryHistoryConfig is the configuration for the query history, which keeps the most recent statements run on the
+// server with their latency, row counts and plan.
+type QueryHistoryConfig interface {
+	// Path is the path of the file the history is persisted to. Relative paths are relative to the cfg_dir.
+	Path() string
+	// Size is the number of statements kept in the history.
+	Size() int
 }
 
 type JwksConfig struct {
@@ -153,7 +275,7 @@ type ServerConfig interface {
 	CfgDir() string
 	// MaxConnections returns the maximum number of simultaneous connections the server will allow.  The default is 1
 	MaxConnections() uint64
-	// QueryParallelism returns the parallelism that should be used by the go-mysql-server analyzer
+	// QueryParallelism returns the number of workers which read a table scan in parallel. Scans are serial unless it is greater than 1
 	QueryParallelism() int
 	// TLSKey returns a path to the servers PEM-encoded private TLS key. "" if there is none.
 	TLSKey() string
@@ -205,6 +327,14 @@ type ServerConfig interface {
 	ClusterConfig() ClusterConfig
 	// EventSchedulerStatus is the configuration for enabling or disabling the event scheduler in this server.
 	EventSchedulerStatus() string
+	// WebhookConfigs is the configuration for the webhooks notified when a branch head moves.
+	WebhookConfigs() []WebhookConfig
+	// AuditLogConfig is the configuration for the audit log. nil if the audit log is disabled.
+	AuditLogConfig() AuditLogConfig
+	// TracingConfig is the configuration for exporting traces. nil if tracing is disabled.
+	TracingConfig() TracingConfig
+	// QueryHistoryConfig is the configuration for the query history. nil if the query history is disabled.
+	QueryHistoryConfig() QueryHistoryConfig
 	// ValueSet returns whether the value string provided was explicitly set in the config
 	ValueSet(value string) bool
 }
@@ -274,6 +404,18 @@ func ValidateConfig(config ServerConfig) error {
 	if config.RequireSecureTransport() && config.TLSCert() == "" && config.TLSKey() == "" {
 		return fmt.Errorf("require_secure_transport can only be `true` when a tls_key and tls_cert are provided.")
 	}
+	if err := ValidateWebhookConfigs(config.WebhookConfigs()); err != nil {
+		return err
+	}
+	if err := ValidateAuditLogConfig(config.AuditLogConfig()); err != nil {
+		return err
+	}
+	if err := ValidateTracingConfig(config.TracingConfig()); err != nil {
+		return err
+	}
+	if err := ValidateQueryHistoryConfig(config.QueryHistoryConfig()); err != nil {
+		return err
+	}
 	return ValidateClusterConfig(config.ClusterConfig())
 }
 
@@ -352,6 +494,118 @@ func ValidateClusterConfig(config ClusterConfig) error {
 	if config.RemotesAPIConfig().TLSKey() != "" && config.RemotesAPIConfig().TLSCert() == "" {
 		return fmt.Errorf("cluster: remotesapi: tls_cert: must supply a tls_cert if you supply a tls_key")
 	}
+	if election := config.ElectionConfig(); election != nil {
+		if election.HeartbeatIntervalMillis() == 0 {
+			return fmt.Errorf("cluster: election: heartbeat_interval_millis: must be greater than 0")
+		}
+		if election.ElectionTimeoutMillis() <= election.HeartbeatIntervalMillis() {
+			return fmt.Errorf("cluster: election: election_timeout_millis: is %d but must be greater than heartbeat_interval_millis (%d)", election.ElectionTimeoutMillis(), election.HeartbeatIntervalMillis())
+		}
+	}
+	if forwarding := config.WriteForwardingConfig(); forwarding != nil {
+		if forwarding.User() == "" {
+			return fmt.Errorf("cluster: write_forwarding: user: Cannot be empty")
+		}
+		for i := range remotes {
+			if remotes[i].SQLAddress() == "" {
+				return fmt.Errorf("cluster: standby_remotes[%d]: sql_address: must be supplied when write_forwarding is configured", i)
+			}
+			if _, _, err := net.SplitHostPort(remotes[i].SQLAddress()); err != nil {
+				return fmt.Errorf("cluster: standby_remotes[%d]: sql_address: is \"%s\" but must be a host:port: %w", i, remotes[i].SQLAddress(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func ValidateWebhookConfigs(configs []WebhookConfig) error {
+	names := make(map[string]struct{}, len(configs))
+	for i, config := range configs {
+		if config.Name() == "" {
+			return fmt.Errorf("webhooks[%d]: name: Cannot be empty", i)
+		}
+		for _, r := range config.Name() {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+				return fmt.Errorf("webhooks[%d]: name: is \"%s\" but may only contain letters, digits, '-' and '_'", i, config.Name())
+			}
+		}
+		if _, ok := names[config.Name()]; ok {
+			return fmt.Errorf("webhooks[%d]: name: \"%s\" is used by more than one webhook", i, config.Name())
+		}
+		names[config.Name()] = struct{}{}
+
+		u, err := url.Parse(config.URL())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhooks[%d]: url: is \"%s\" but must be an http or https URL", i, config.URL())
+		}
+		for _, pattern := range config.Branches() {
+			if _, err = path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("webhooks[%d]: branches: invalid pattern \"%s\": %v", i, pattern, err)
+			}
+		}
+		if config.MaxRetries() < 0 {
+			return fmt.Errorf("webhooks[%d]: max_retries: is %d but must be >= 0", i, config.MaxRetries())
+		}
+	}
+	return nil
+}
+
+func ValidateAuditLogConfig(config AuditLogConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Path() == "" {
+		return fmt.Errorf("audit_log: path: Cannot be empty")
+	}
+	for _, event := range config.Events() {
+		valid := false
+		for _, e := range AuditLogEvents {
+			if strings.EqualFold(event, e) {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("audit_log: events: is \"%s\" but must be one of %s", event, strings.Join(AuditLogEvents, ", "))
+		}
+	}
+	if config.MaxBackups() < 0 {
+		return fmt.Errorf("audit_log: max_backups: is %d but must be >= 0", config.MaxBackups())
+	}
+	return nil
+}
+
+func ValidateTracingConfig(config TracingConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Endpoint() == "" {
+		return fmt.Errorf("tracing: endpoint: Cannot be empty")
+	}
+	valid := false
+	for _, p := range TracingProtocols {
+		if strings.EqualFold(config.Protocol(), p) {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("tracing: protocol: is \"%s\" but must be one of %s", config.Protocol(), strings.Join(TracingProtocols, ", "))
+	}
+	if config.SampleRatio() < 0 || config.SampleRatio() > 1 {
+		return fmt.Errorf("tracing: sample_ratio: is %v but must be between 0 and 1", config.SampleRatio())
+	}
+	return nil
+}
+
+func ValidateQueryHistoryConfig(config QueryHistoryConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Path() == "" {
+		return fmt.Errorf("query_history: path: Cannot be empty")
+	}
+	if config.Size() <= 0 {
+		return fmt.Errorf("query_history: size: is %d but must be > 0", config.Size())
+	}
 	return nil
 }
 
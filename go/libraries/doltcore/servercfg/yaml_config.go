@@ -138,10 +138,14 @@ type YAMLConfig struct {
 	PrivilegeFile     *string               `yaml:"privilege_file,omitempty"`
 	BranchControlFile *string               `yaml:"branch_control_file,omitempty"`
 	// TODO: Rename to UserVars_
-	Vars            []UserSessionVars      `yaml:"user_session_vars"`
-	SystemVars_     map[string]interface{} `yaml:"system_variables,omitempty" minver:"1.11.1"`
-	Jwks            []JwksConfig           `yaml:"jwks"`
-	GoldenMysqlConn *string                `yaml:"golden_mysql_conn,omitempty"`
+	Vars            []UserSessionVars       `yaml:"user_session_vars"`
+	SystemVars_     map[string]interface{}  `yaml:"system_variables,omitempty" minver:"1.11.1"`
+	Jwks            []JwksConfig            `yaml:"jwks"`
+	GoldenMysqlConn *string                 `yaml:"golden_mysql_conn,omitempty"`
+	Webhooks_       []WebhookYAMLConfig     `yaml:"webhooks,omitempty" minver:"TBD"`
+	AuditLog_       *AuditLogYAMLConfig     `yaml:"audit_log,omitempty" minver:"TBD"`
+	Tracing_        *TracingYAMLConfig      `yaml:"tracing,omitempty" minver:"TBD"`
+	QueryHistory_   *QueryHistoryYAMLConfig `yaml:"query_history,omitempty" minver:"TBD"`
 }
 
 var _ ServerConfig = YAMLConfig{}
@@ -223,9 +227,75 @@ func ServerConfigAsYAMLConfig(cfg ServerConfig) *YAMLConfig {
 		SystemVars_:       systemVars,
 		Vars:              cfg.UserVars(),
 		Jwks:              cfg.JwksConfig(),
+		Webhooks_:         webhookConfigsAsYAMLConfig(cfg.WebhookConfigs()),
+		AuditLog_:         auditLogConfigAsYAMLConfig(cfg.AuditLogConfig()),
+		Tracing_:          tracingConfigAsYAMLConfig(cfg.TracingConfig()),
+		QueryHistory_:     queryHistoryConfigAsYAMLConfig(cfg.QueryHistoryConfig()),
 	}
 }
 
+func queryHistoryConfigAsYAMLConfig(config QueryHistoryConfig) *QueryHistoryYAMLConfig {
+	if config == nil {
+		return nil
+	}
+
+	return &QueryHistoryYAMLConfig{
+		Path_: ptr(config.Path()),
+		Size_: ptr(config.Size()),
+	}
+}
+
+func tracingConfigAsYAMLConfig(config TracingConfig) *TracingYAMLConfig {
+	if config == nil {
+		return nil
+	}
+
+	return &TracingYAMLConfig{
+		Endpoint_:    ptr(config.Endpoint()),
+		Protocol_:    ptr(config.Protocol()),
+		Insecure_:    ptr(config.Insecure()),
+		ServiceName_: ptr(config.ServiceName()),
+		SampleRatio_: ptr(config.SampleRatio()),
+		Headers_:     config.Headers(),
+	}
+}
+
+func auditLogConfigAsYAMLConfig(config AuditLogConfig) *AuditLogYAMLConfig {
+	if config == nil {
+		return nil
+	}
+
+	return &AuditLogYAMLConfig{
+		Path_:        ptr(config.Path()),
+		Events_:      config.Events(),
+		Users_:       config.Users(),
+		Databases_:   config.Databases(),
+		MaxSizeMB_:   ptr(config.MaxSizeMB()),
+		MaxAgeHours_: ptr(config.MaxAgeHours()),
+		MaxBackups_:  ptr(config.MaxBackups()),
+	}
+}
+
+func webhookConfigsAsYAMLConfig(configs []WebhookConfig) []WebhookYAMLConfig {
+	if len(configs) == 0 {
+		return nil
+	}
+
+	ret := make([]WebhookYAMLConfig, len(configs))
+	for i, config := range configs {
+		ret[i] = WebhookYAMLConfig{
+			Name_:          ptr(config.Name()),
+			URL_:           ptr(config.URL()),
+			Secret_:        nillableStrPtr(config.Secret()),
+			Databases_:     config.Databases(),
+			Branches_:      config.Branches(),
+			MaxRetries_:    ptr(config.MaxRetries()),
+			TimeoutMillis_: ptr(config.TimeoutMillis()),
+		}
+	}
+	return ret
+}
+
 func clusterConfigAsYAMLConfig(config ClusterConfig) *ClusterYAMLConfig {
 	if config == nil {
 		return nil
@@ -244,6 +314,31 @@ func clusterConfigAsYAMLConfig(config ClusterConfig) *ClusterYAMLConfig {
 			URLMatches: config.RemotesAPIConfig().ServerNameURLMatches(),
 			DNSMatches: config.RemotesAPIConfig().ServerNameDNSMatches(),
 		},
+		Election_:        clusterElectionConfigAsYAMLConfig(config.ElectionConfig()),
+		WriteForwarding_: clusterWriteForwardingConfigAsYAMLConfig(config.WriteForwardingConfig()),
+	}
+}
+
+func clusterWriteForwardingConfigAsYAMLConfig(config ClusterWriteForwardingConfig) *ClusterWriteForwardingYAMLConfig {
+	if config == nil {
+		return nil
+	}
+
+	return &ClusterWriteForwardingYAMLConfig{
+		User_:              ptr(config.User()),
+		Password_:          ptr(config.Password()),
+		WaitTimeoutMillis_: ptr(config.WaitTimeoutMillis()),
+	}
+}
+
+func clusterElectionConfigAsYAMLConfig(config ClusterElectionConfig) *ClusterElectionYAMLConfig {
+	if config == nil {
+		return nil
+	}
+
+	return &ClusterElectionYAMLConfig{
+		HeartbeatIntervalMillis_: ptr(config.HeartbeatIntervalMillis()),
+		ElectionTimeoutMillis_:   ptr(config.ElectionTimeoutMillis()),
 	}
 }
 
@@ -476,7 +571,7 @@ func (cfg YAMLConfig) AllowCleartextPasswords() bool {
 	return *cfg.ListenerConfig.AllowCleartextPasswords
 }
 
-// QueryParallelism returns the parallelism that should be used by the go-mysql-server analyzer
+// QueryParallelism returns the number of workers which read a table scan in parallel. Scans are serial unless it is greater than 1
 func (cfg YAMLConfig) QueryParallelism() int {
 	if cfg.PerformanceConfig.QueryParallelism == nil {
 		return DefaultQueryParallelism
@@ -578,6 +673,39 @@ func (cfg YAMLConfig) ClusterConfig() ClusterConfig {
 	return cfg.ClusterCfg
 }
 
+func (cfg YAMLConfig) WebhookConfigs() []WebhookConfig {
+	if len(cfg.Webhooks_) == 0 {
+		return nil
+	}
+
+	ret := make([]WebhookConfig, len(cfg.Webhooks_))
+	for i := range cfg.Webhooks_ {
+		ret[i] = cfg.Webhooks_[i]
+	}
+	return ret
+}
+
+func (cfg YAMLConfig) AuditLogConfig() AuditLogConfig {
+	if cfg.AuditLog_ == nil {
+		return nil
+	}
+	return cfg.AuditLog_
+}
+
+func (cfg YAMLConfig) TracingConfig() TracingConfig {
+	if cfg.Tracing_ == nil {
+		return nil
+	}
+	return cfg.Tracing_
+}
+
+func (cfg YAMLConfig) QueryHistoryConfig() QueryHistoryConfig {
+	if cfg.QueryHistory_ == nil {
+		return nil
+	}
+	return cfg.QueryHistory_
+}
+
 func (cfg YAMLConfig) EventSchedulerStatus() string {
 	if cfg.BehaviorConfig.EventSchedulerStatus == nil {
 		return "ON"
@@ -593,15 +721,18 @@ func (cfg YAMLConfig) EventSchedulerStatus() string {
 }
 
 type ClusterYAMLConfig struct {
-	StandbyRemotes_ []StandbyRemoteYAMLConfig   `yaml:"standby_remotes"`
-	BootstrapRole_  string                      `yaml:"bootstrap_role"`
-	BootstrapEpoch_ int                         `yaml:"bootstrap_epoch"`
-	RemotesAPI      ClusterRemotesAPIYAMLConfig `yaml:"remotesapi"`
+	StandbyRemotes_  []StandbyRemoteYAMLConfig         `yaml:"standby_remotes"`
+	BootstrapRole_   string                            `yaml:"bootstrap_role"`
+	BootstrapEpoch_  int                               `yaml:"bootstrap_epoch"`
+	RemotesAPI       ClusterRemotesAPIYAMLConfig       `yaml:"remotesapi"`
+	Election_        *ClusterElectionYAMLConfig        `yaml:"election,omitempty" minver:"TBD"`
+	WriteForwarding_ *ClusterWriteForwardingYAMLConfig `yaml:"write_forwarding,omitempty" minver:"TBD"`
 }
 
 type StandbyRemoteYAMLConfig struct {
-	Name_              string `yaml:"name"`
-	RemoteURLTemplate_ string `yaml:"remote_url_template"`
+	Name_              string  `yaml:"name"`
+	RemoteURLTemplate_ string  `yaml:"remote_url_template"`
+	SQLAddress_        *string `yaml:"sql_address,omitempty" minver:"TBD"`
 }
 
 func (c StandbyRemoteYAMLConfig) Name() string {
@@ -612,6 +743,13 @@ func (c StandbyRemoteYAMLConfig) RemoteURLTemplate() string {
 	return c.RemoteURLTemplate_
 }
 
+func (c StandbyRemoteYAMLConfig) SQLAddress() string {
+	if c.SQLAddress_ == nil {
+		return ""
+	}
+	return *c.SQLAddress_
+}
+
 func (c *ClusterYAMLConfig) StandbyRemotes() []ClusterStandbyRemoteConfig {
 	ret := make([]ClusterStandbyRemoteConfig, len(c.StandbyRemotes_))
 	for i := range c.StandbyRemotes_ {
@@ -632,6 +770,66 @@ func (c *ClusterYAMLConfig) RemotesAPIConfig() ClusterRemotesAPIConfig {
 	return c.RemotesAPI
 }
 
+func (c *ClusterYAMLConfig) ElectionConfig() ClusterElectionConfig {
+	if c.Election_ == nil {
+		return nil
+	}
+	return c.Election_
+}
+
+func (c *ClusterYAMLConfig) WriteForwardingConfig() ClusterWriteForwardingConfig {
+	if c.WriteForwarding_ == nil {
+		return nil
+	}
+	return c.WriteForwarding_
+}
+
+type ClusterElectionYAMLConfig struct {
+	HeartbeatIntervalMillis_ *uint64 `yaml:"heartbeat_interval_millis,omitempty" minver:"TBD"`
+	ElectionTimeoutMillis_   *uint64 `yaml:"election_timeout_millis,omitempty" minver:"TBD"`
+}
+
+func (c *ClusterElectionYAMLConfig) HeartbeatIntervalMillis() uint64 {
+	if c.HeartbeatIntervalMillis_ == nil {
+		return DefaultHeartbeatIntervalMillis
+	}
+	return *c.HeartbeatIntervalMillis_
+}
+
+func (c *ClusterElectionYAMLConfig) ElectionTimeoutMillis() uint64 {
+	if c.ElectionTimeoutMillis_ == nil {
+		return DefaultElectionTimeoutMillis
+	}
+	return *c.ElectionTimeoutMillis_
+}
+
+type ClusterWriteForwardingYAMLConfig struct {
+	User_              *string `yaml:"user,omitempty" minver:"TBD"`
+	Password_          *string `yaml:"password,omitempty" minver:"TBD"`
+	WaitTimeoutMillis_ *uint64 `yaml:"wait_timeout_millis,omitempty" minver:"TBD"`
+}
+
+func (c *ClusterWriteForwardingYAMLConfig) User() string {
+	if c.User_ == nil {
+		return ""
+	}
+	return *c.User_
+}
+
+func (c *ClusterWriteForwardingYAMLConfig) Password() string {
+	if c.Password_ == nil {
+		return ""
+	}
+	return *c.Password_
+}
+
+func (c *ClusterWriteForwardingYAMLConfig) WaitTimeoutMillis() uint64 {
+	if c.WaitTimeoutMillis_ == nil {
+		return DefaultForwardingWaitMillis
+	}
+	return *c.WaitTimeoutMillis_
+}
+
 type ClusterRemotesAPIYAMLConfig struct {
 	Addr_      string   `yaml:"address"`
 	Port_      int      `yaml:"port"`
@@ -670,6 +868,176 @@ func (c ClusterRemotesAPIYAMLConfig) ServerNameDNSMatches() []string {
 	return c.DNSMatches
 }
 
+type WebhookYAMLConfig struct {
+	Name_          *string  `yaml:"name,omitempty" minver:"TBD"`
+	URL_           *string  `yaml:"url,omitempty" minver:"TBD"`
+	Secret_        *string  `yaml:"secret,omitempty" minver:"TBD"`
+	Databases_     []string `yaml:"databases,omitempty" minver:"TBD"`
+	Branches_      []string `yaml:"branches,omitempty" minver:"TBD"`
+	MaxRetries_    *int     `yaml:"max_retries,omitempty" minver:"TBD"`
+	TimeoutMillis_ *uint64  `yaml:"timeout_millis,omitempty" minver:"TBD"`
+}
+
+func (c WebhookYAMLConfig) Name() string {
+	if c.Name_ == nil {
+		return ""
+	}
+	return *c.Name_
+}
+
+func (c WebhookYAMLConfig) URL() string {
+	if c.URL_ == nil {
+		return ""
+	}
+	return *c.URL_
+}
+
+func (c WebhookYAMLConfig) Secret() string {
+	if c.Secret_ == nil {
+		return ""
+	}
+	return *c.Secret_
+}
+
+func (c WebhookYAMLConfig) Databases() []string {
+	return c.Databases_
+}
+
+func (c WebhookYAMLConfig) Branches() []string {
+	return c.Branches_
+}
+
+func (c WebhookYAMLConfig) MaxRetries() int {
+	if c.MaxRetries_ == nil {
+		return DefaultWebhookMaxRetries
+	}
+	return *c.MaxRetries_
+}
+
+func (c WebhookYAMLConfig) TimeoutMillis() uint64 {
+	if c.TimeoutMillis_ == nil {
+		return DefaultWebhookTimeoutMillis
+	}
+	return *c.TimeoutMillis_
+}
+
+type AuditLogYAMLConfig struct {
+	Path_        *string  `yaml:"path,omitempty" minver:"TBD"`
+	Events_      []string `yaml:"events,omitempty" minver:"TBD"`
+	Users_       []string `yaml:"users,omitempty" minver:"TBD"`
+	Databases_   []string `yaml:"databases,omitempty" minver:"TBD"`
+	MaxSizeMB_   *uint64  `yaml:"max_size_mb,omitempty" minver:"TBD"`
+	MaxAgeHours_ *uint64  `yaml:"max_age_hours,omitempty" minver:"TBD"`
+	MaxBackups_  *int     `yaml:"max_backups,omitempty" minver:"TBD"`
+}
+
+func (c *AuditLogYAMLConfig) Path() string {
+	if c.Path_ == nil {
+		return DefaultAuditLogPath
+	}
+	return *c.Path_
+}
+
+func (c *AuditLogYAMLConfig) Events() []string {
+	return c.Events_
+}
+
+func (c *AuditLogYAMLConfig) Users() []string {
+	return c.Users_
+}
+
+func (c *AuditLogYAMLConfig) Databases() []string {
+	return c.Databases_
+}
+
+func (c *AuditLogYAMLConfig) MaxSizeMB() uint64 {
+	if c.MaxSizeMB_ == nil {
+		return DefaultAuditLogMaxSizeMB
+	}
+	return *c.MaxSizeMB_
+}
+
+func (c *AuditLogYAMLConfig) MaxAgeHours() uint64 {
+	if c.MaxAgeHours_ == nil {
+		return DefaultAuditLogMaxAgeHours
+	}
+	return *c.MaxAgeHours_
+}
+
+func (c *AuditLogYAMLConfig) MaxBackups() int {
+	if c.MaxBackups_ == nil {
+		return DefaultAuditLogMaxBackups
+	}
+	return *c.MaxBackups_
+}
+
+type TracingYAMLConfig struct {
+	Endpoint_    *string           `yaml:"endpoint,omitempty" minver:"TBD"`
+	Protocol_    *string           `yaml:"protocol,omitempty" minver:"TBD"`
+	Insecure_    *bool             `yaml:"insecure,omitempty" minver:"TBD"`
+	ServiceName_ *string           `yaml:"service_name,omitempty" minver:"TBD"`
+	SampleRatio_ *float64          `yaml:"sample_ratio,omitempty" minver:"TBD"`
+	Headers_     map[string]string `yaml:"headers,omitempty" minver:"TBD"`
+}
+
+func (c *TracingYAMLConfig) Endpoint() string {
+	if c.Endpoint_ == nil {
+		return ""
+	}
+	return *c.Endpoint_
+}
+
+func (c *TracingYAMLConfig) Protocol() string {
+	if c.Protocol_ == nil {
+		return DefaultTracingProtocol
+	}
+	return *c.Protocol_
+}
+
+func (c *TracingYAMLConfig) Insecure() bool {
+	if c.Insecure_ == nil {
+		return false
+	}
+	return *c.Insecure_
+}
+
+func (c *TracingYAMLConfig) ServiceName() string {
+	if c.ServiceName_ == nil {
+		return DefaultTracingServiceName
+	}
+	return *c.ServiceName_
+}
+
+func (c *TracingYAMLConfig) SampleRatio() float64 {
+	if c.SampleRatio_ == nil {
+		return DefaultTracingSampleRatio
+	}
+	return *c.SampleRatio_
+}
+
+func (c *TracingYAMLConfig) Headers() map[string]string {
+	return c.Headers_
+}
+
+type QueryHistoryYAMLConfig struct {
+	Path_ *string `yaml:"path,omitempty" minver:"TBD"`
+	Size_ *int    `yaml:"size,omitempty" minver:"TBD"`
+}
+
+func (c *QueryHistoryYAMLConfig) Path() string {
+	if c.Path_ == nil {
+		return DefaultQueryHistoryPath
+	}
+	return *c.Path_
+}
+
+func (c *QueryHistoryYAMLConfig) Size() int {
+	if c.Size_ == nil {
+		return DefaultQueryHistorySize
+	}
+	return *c.Size_
+}
+
 func (cfg YAMLConfig) ValueSet(value string) bool {
 	switch value {
 	case ReadTimeoutKey:
@@ -179,6 +179,51 @@ cluster:
 	require.Equal(t, 0, config.ClusterConfig().BootstrapEpoch())
 	require.Equal(t, "standby", config.ClusterConfig().StandbyRemotes()[0].Name())
 	require.Equal(t, "http://doltdb-1.doltdb:50051/{database}", config.ClusterConfig().StandbyRemotes()[0].RemoteURLTemplate())
+	require.Nil(t, config.ClusterConfig().ElectionConfig())
+}
+
+func TestUnmarshallClusterElection(t *testing.T) {
+	testStr := `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://doltdb-1.doltdb:50051/{database}
+  remotesapi:
+    port: 50051
+  election:
+    heartbeat_interval_millis: 250
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	election := config.ClusterConfig().ElectionConfig()
+	require.NotNil(t, election)
+	require.Equal(t, uint64(250), election.HeartbeatIntervalMillis())
+	require.Equal(t, uint64(DefaultElectionTimeoutMillis), election.ElectionTimeoutMillis())
+	require.NoError(t, ValidateClusterConfig(config.ClusterConfig()))
+}
+
+func TestUnmarshallClusterWriteForwarding(t *testing.T) {
+	testStr := `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://doltdb-1.doltdb:50051/{database}
+    sql_address: doltdb-1.doltdb:3306
+  remotesapi:
+    port: 50051
+  write_forwarding:
+    user: forwarder
+    password: pass
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	require.Equal(t, "doltdb-1.doltdb:3306", config.ClusterConfig().StandbyRemotes()[0].SQLAddress())
+	forwarding := config.ClusterConfig().WriteForwardingConfig()
+	require.NotNil(t, forwarding)
+	require.Equal(t, "forwarder", forwarding.User())
+	require.Equal(t, "pass", forwarding.Password())
+	require.Equal(t, uint64(DefaultForwardingWaitMillis), forwarding.WaitTimeoutMillis())
+	require.NoError(t, ValidateClusterConfig(config.ClusterConfig()))
 }
 
 func TestValidateClusterConfig(t *testing.T) {
@@ -271,6 +316,64 @@ cluster:
   bootstrap_epoch: 0
   remotesapi:
     port: 50051
+`,
+			Error: true,
+		},
+		{
+			Name: "election timeout shorter than the heartbeat interval",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  election:
+    heartbeat_interval_millis: 1000
+    election_timeout_millis: 500
+`,
+			Error: true,
+		},
+		{
+			Name: "zero heartbeat interval",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  election:
+    heartbeat_interval_millis: 0
+`,
+			Error: true,
+		},
+		{
+			Name: "write forwarding without sql_address",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  write_forwarding:
+    user: forwarder
+`,
+			Error: true,
+		},
+		{
+			Name: "write forwarding without user",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+    sql_address: localhost:3306
+  remotesapi:
+    port: 50051
+  write_forwarding:
+    password: pass
 `,
 			Error: true,
 		},
@@ -288,6 +391,245 @@ cluster:
 	}
 }
 
+func TestUnmarshallWebhooks(t *testing.T) {
+	testStr := `
+webhooks:
+  - name: ci
+    url: https://ci.example.com/dolt
+    secret: s3cret
+    databases: [db1, db2]
+    branches: [main, release/*]
+    max_retries: 5
+    timeout_millis: 2000
+  - name: audit
+    url: http://localhost:8080/hook
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	webhooks := config.WebhookConfigs()
+	require.Len(t, webhooks, 2)
+
+	require.Equal(t, "ci", webhooks[0].Name())
+	require.Equal(t, "https://ci.example.com/dolt", webhooks[0].URL())
+	require.Equal(t, "s3cret", webhooks[0].Secret())
+	require.Equal(t, []string{"db1", "db2"}, webhooks[0].Databases())
+	require.Equal(t, []string{"main", "release/*"}, webhooks[0].Branches())
+	require.Equal(t, 5, webhooks[0].MaxRetries())
+	require.Equal(t, uint64(2000), webhooks[0].TimeoutMillis())
+
+	require.Equal(t, "audit", webhooks[1].Name())
+	require.Equal(t, "", webhooks[1].Secret())
+	require.Empty(t, webhooks[1].Databases())
+	require.Empty(t, webhooks[1].Branches())
+	require.Equal(t, DefaultWebhookMaxRetries, webhooks[1].MaxRetries())
+	require.Equal(t, uint64(DefaultWebhookTimeoutMillis), webhooks[1].TimeoutMillis())
+
+	require.NoError(t, ValidateWebhookConfigs(webhooks))
+}
+
+func TestValidateWebhookConfigs(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config string
+		Error  bool
+	}{
+		{
+			Name:   "no webhooks",
+			Config: "",
+			Error:  false,
+		},
+		{
+			Name: "missing name",
+			Config: `
+webhooks:
+  - url: http://localhost:8080/hook
+`,
+			Error: true,
+		},
+		{
+			Name: "invalid name",
+			Config: `
+webhooks:
+  - name: ../escape
+    url: http://localhost:8080/hook
+`,
+			Error: true,
+		},
+		{
+			Name: "duplicate name",
+			Config: `
+webhooks:
+  - name: hook
+    url: http://localhost:8080/hook
+  - name: hook
+    url: http://localhost:8081/hook
+`,
+			Error: true,
+		},
+		{
+			Name: "not an http url",
+			Config: `
+webhooks:
+  - name: hook
+    url: ftp://localhost/hook
+`,
+			Error: true,
+		},
+		{
+			Name: "bad branch pattern",
+			Config: `
+webhooks:
+  - name: hook
+    url: http://localhost:8080/hook
+    branches: ["[main"]
+`,
+			Error: true,
+		},
+		{
+			Name: "negative retries",
+			Config: `
+webhooks:
+  - name: hook
+    url: http://localhost:8080/hook
+    max_retries: -1
+`,
+			Error: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg, err := NewYamlConfig([]byte(c.Config))
+			require.NoError(t, err)
+			if c.Error {
+				require.Error(t, ValidateWebhookConfigs(cfg.WebhookConfigs()))
+			} else {
+				require.NoError(t, ValidateWebhookConfigs(cfg.WebhookConfigs()))
+			}
+		})
+	}
+}
+
+func TestUnmarshallAuditLog(t *testing.T) {
+	config, err := NewYamlConfig([]byte(""))
+	require.NoError(t, err)
+	require.Nil(t, config.AuditLogConfig())
+
+	config, err = NewYamlConfig([]byte("audit_log: {}\n"))
+	require.NoError(t, err)
+	auditLog := config.AuditLogConfig()
+	require.NotNil(t, auditLog)
+	require.Equal(t, DefaultAuditLogPath, auditLog.Path())
+	require.Empty(t, auditLog.Events())
+	require.Equal(t, uint64(DefaultAuditLogMaxSizeMB), auditLog.MaxSizeMB())
+	require.Equal(t, uint64(DefaultAuditLogMaxAgeHours), auditLog.MaxAgeHours())
+	require.Equal(t, DefaultAuditLogMaxBackups, auditLog.MaxBackups())
+
+	testStr := `
+audit_log:
+  path: /var/log/dolt/audit.jsonl
+  events: [connect, privilege]
+  users: [alice]
+  databases: [db1]
+  max_size_mb: 10
+  max_age_hours: 0
+  max_backups: 3
+`
+	config, err = NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	auditLog = config.AuditLogConfig()
+	require.Equal(t, "/var/log/dolt/audit.jsonl", auditLog.Path())
+	require.Equal(t, []string{"connect", "privilege"}, auditLog.Events())
+	require.Equal(t, []string{"alice"}, auditLog.Users())
+	require.Equal(t, []string{"db1"}, auditLog.Databases())
+	require.Equal(t, uint64(10), auditLog.MaxSizeMB())
+	require.Equal(t, uint64(0), auditLog.MaxAgeHours())
+	require.Equal(t, 3, auditLog.MaxBackups())
+	require.NoError(t, ValidateAuditLogConfig(auditLog))
+
+	config, err = NewYamlConfig([]byte("audit_log:\n  events: [query]\n"))
+	require.NoError(t, err)
+	require.Error(t, ValidateAuditLogConfig(config.AuditLogConfig()))
+
+	config, err = NewYamlConfig([]byte("audit_log:\n  path: \"\"\n"))
+	require.NoError(t, err)
+	require.Error(t, ValidateAuditLogConfig(config.AuditLogConfig()))
+}
+
+func TestUnmarshallTracing(t *testing.T) {
+	config, err := NewYamlConfig([]byte(""))
+	require.NoError(t, err)
+	require.Nil(t, config.TracingConfig())
+
+	config, err = NewYamlConfig([]byte("tracing:\n  endpoint: localhost:4317\n"))
+	require.NoError(t, err)
+	tracing := config.TracingConfig()
+	require.NotNil(t, tracing)
+	require.Equal(t, "localhost:4317", tracing.Endpoint())
+	require.Equal(t, DefaultTracingProtocol, tracing.Protocol())
+	require.False(t, tracing.Insecure())
+	require.Equal(t, DefaultTracingServiceName, tracing.ServiceName())
+	require.Equal(t, DefaultTracingSampleRatio, tracing.SampleRatio())
+	require.Empty(t, tracing.Headers())
+	require.NoError(t, ValidateTracingConfig(tracing))
+
+	testStr := `
+tracing:
+  endpoint: collector:4318
+  protocol: http
+  insecure: true
+  service_name: dolt-primary
+  sample_ratio: 0.25
+  headers:
+    api-key: secret
+`
+	config, err = NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	tracing = config.TracingConfig()
+	require.Equal(t, "collector:4318", tracing.Endpoint())
+	require.Equal(t, "http", tracing.Protocol())
+	require.True(t, tracing.Insecure())
+	require.Equal(t, "dolt-primary", tracing.ServiceName())
+	require.Equal(t, 0.25, tracing.SampleRatio())
+	require.Equal(t, map[string]string{"api-key": "secret"}, tracing.Headers())
+	require.NoError(t, ValidateTracingConfig(tracing))
+
+	config, err = NewYamlConfig([]byte("tracing: {}\n"))
+	require.NoError(t, err)
+	require.Error(t, ValidateTracingConfig(config.TracingConfig()))
+
+	config, err = NewYamlConfig([]byte("tracing:\n  endpoint: localhost:4317\n  protocol: thrift\n"))
+	require.NoError(t, err)
+	require.Error(t, ValidateTracingConfig(config.TracingConfig()))
+
+	config, err = NewYamlConfig([]byte("tracing:\n  endpoint: localhost:4317\n  sample_ratio: 2\n"))
+	require.NoError(t, err)
+	require.Error(t, ValidateTracingConfig(config.TracingConfig()))
+}
+
+func TestUnmarshallQueryHistory(t *testing.T) {
+	config, err := NewYamlConfig([]byte(""))
+	require.NoError(t, err)
+	require.Nil(t, config.QueryHistoryConfig())
+
+	config, err = NewYamlConfig([]byte("query_history: {}\n"))
+	require.NoError(t, err)
+	queryHistory := config.QueryHistoryConfig()
+	require.NotNil(t, queryHistory)
+	require.Equal(t, DefaultQueryHistoryPath, queryHistory.Path())
+	require.Equal(t, DefaultQueryHistorySize, queryHistory.Size())
+	require.NoError(t, ValidateQueryHistoryConfig(queryHistory))
+
+	config, err = NewYamlConfig([]byte("query_history:\n  path: /var/lib/dolt/history.jsonl\n  size: 50\n"))
+	require.NoError(t, err)
+	queryHistory = config.QueryHistoryConfig()
+	require.Equal(t, "/var/lib/dolt/history.jsonl", queryHistory.Path())
+	require.Equal(t, 50, queryHistory.Size())
+
+	config, err = NewYamlConfig([]byte("query_history:\n  size: 0\n"))
+	require.NoError(t, err)
+	require.Error(t, ValidateQueryHistoryConfig(config.QueryHistoryConfig()))
+}
+
 // Tests that a common YAML error (incorrect indentation) throws an error
 func TestUnmarshallError(t *testing.T) {
 	testStr := `
@@ -0,0 +1,59 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/cdc"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// ApplyCDCHooks adds each of |dbs| to |hub|, and installs its cdc.Publisher as a commit hook, so that the changes to
+// the database can be streamed to subscribers.
+func ApplyCDCHooks(ctx context.Context, hub *cdc.Hub, mrEnv *env.MultiRepoEnv, logger io.Writer, dbs ...dsess.SqlDatabase) {
+	for _, db := range dbs {
+		dEnv := mrEnv.GetEnv(db.Name())
+		if dEnv == nil {
+			continue
+		}
+		addCDCHook(ctx, hub, db.Name(), dEnv, logger)
+	}
+}
+
+func addCDCHook(ctx context.Context, hub *cdc.Hub, name string, dEnv *env.DoltEnv, logger io.Writer) {
+	pub := hub.AddDatabase(name, dEnv.DoltDB)
+	_ = pub.SetLogger(ctx, logger)
+	dEnv.DoltDB.PrependCommitHook(ctx, pub)
+}
+
+// NewCDCInitDatabaseHook returns an InitDatabaseHook that adds a newly created database to |hub|.
+func NewCDCInitDatabaseHook(hub *cdc.Hub, logger io.Writer) InitDatabaseHook {
+	return func(ctx *sql.Context, pro *DoltDatabaseProvider, name string, dEnv *env.DoltEnv, db dsess.SqlDatabase) error {
+		addCDCHook(ctx, hub, name, dEnv, logger)
+		return nil
+	}
+}
+
+// NewCDCDropDatabaseHook returns a DropDatabaseHook that removes a dropped database from |hub|.
+func NewCDCDropDatabaseHook(hub *cdc.Hub) DropDatabaseHook {
+	return func(ctx *sql.Context, name string) {
+		hub.RemoveDatabase(name)
+	}
+}
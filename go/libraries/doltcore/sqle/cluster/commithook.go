@@ -19,12 +19,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/store/datas"
@@ -315,7 +317,8 @@ func (h *commithook) attemptReplicate(ctx context.Context) {
 		if err = cs.Rebase(ctx); err == nil {
 			if curRootHash, err = cs.Root(ctx); err == nil {
 				var ok bool
-				ok, err = cs.Commit(ctx, toPush, curRootHash)
+				commitCtx := metadata.AppendToOutgoingContext(ctx, clusterReplicationSequenceHeader, strconv.FormatInt(incomingTime.UnixNano(), 10))
+				ok, err = cs.Commit(commitCtx, toPush, curRootHash)
 				if err == nil && !ok {
 					err = errDestDBRootHashMoved
 				}
@@ -65,6 +65,15 @@ const RoleDetectedBrokenConfig Role = "detected_broken_config"
 
 const PersistentConfigPrefix = "sqlserver.cluster"
 
+// The keys under PersistentConfigPrefix which hold the replicated position of
+// this server, and the last vote it granted in an election.
+const (
+	persistentReplicatedEpochKey    = "replicated_epoch"
+	persistentReplicatedSequenceKey = "replicated_sequence"
+	persistentVotedEpochKey         = "voted_epoch"
+	persistentVotedForKey           = "voted_for"
+)
+
 // State for any ongoing DROP DATABASE replication attempts we have
 // outstanding. When we create a database, we cancel all on going DROP DATABASE
 // replication attempts.
@@ -86,6 +95,11 @@ type Controller struct {
 	cinterceptor  clientinterceptor
 	lgr           *logrus.Logger
 
+	// The position of the latest write this server replicated from a
+	// primary, or made itself as the primary at an earlier epoch. Guarded
+	// by |mu|.
+	position replicationPosition
+
 	standbyCallback IsStandbyCallback
 	iterSessions    IterSessions
 	killQuery       func(uint32)
@@ -99,6 +113,9 @@ type Controller struct {
 
 	replicationClients []*replicationServiceClient
 
+	// nil when the cluster does not run elections.
+	elector *elector
+
 	mysqlDb          *mysql_db.MySQLDb
 	mysqlDbPersister *replicatingMySQLDbPersister
 	mysqlDbReplicas  []*mysqlDbReplica
@@ -140,11 +157,16 @@ func NewController(lgr *logrus.Logger, cfg servercfg.ClusterConfig, pCfg config.
 	if err != nil {
 		return nil, err
 	}
+	position, err := loadReplicatedPosition(pCfg)
+	if err != nil {
+		return nil, err
+	}
 	ret := &Controller{
 		cfg:           cfg,
 		persistentCfg: pCfg,
 		role:          role,
 		epoch:         epoch,
+		position:      position,
 		commithooks:   make([]*commithook, 0),
 		lgr:           lgr,
 	}
@@ -188,6 +210,20 @@ func NewController(lgr *logrus.Logger, cfg servercfg.ClusterConfig, pCfg config.
 	if err != nil {
 		return nil, err
 	}
+	if electionCfg := cfg.ElectionConfig(); electionCfg != nil {
+		votedEpoch, votedFor, err := loadVote(pCfg)
+		if err != nil {
+			return nil, err
+		}
+		ret.elector = newElector(lgr.WithFields(logrus.Fields{"component": "elector"}), electionCfg, ret.replicationClients, ret.roleAndEpoch, func(role Role, epoch int) error {
+			_, err := ret.setRoleAndEpoch(string(role), epoch, roleTransitionOptions{
+				graceful: false,
+			})
+			return err
+		}, ret.replicatedPosition, votedEpoch, votedFor, ret.persistVote)
+		ret.sinterceptor.fenceStaleEpochs = true
+		ret.cinterceptor.fenceStaleEpochs = true
+	}
 	ret.mysqlDbReplicas = make([]*mysqlDbReplica, len(ret.replicationClients))
 	for i := range ret.mysqlDbReplicas {
 		bo := backoff.NewExponentialBackOff()
@@ -224,6 +260,13 @@ func (c *Controller) Run() {
 		defer wg.Done()
 		c.bcReplication.Run()
 	}()
+	if c.elector != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.elector.Run()
+		}()
+	}
 	wg.Wait()
 	for _, client := range c.replicationClients {
 		client.closer()
@@ -234,6 +277,9 @@ func (c *Controller) GracefulStop() error {
 	c.jwks.GracefulStop()
 	c.mysqlDbPersister.GracefulStop()
 	c.bcReplication.GracefulStop()
+	if c.elector != nil {
+		c.elector.GracefulStop()
+	}
 	return nil
 }
 
@@ -483,9 +529,66 @@ func (c *Controller) persistVariables() error {
 	toset := make(map[string]string)
 	toset[dsess.DoltClusterRoleVariable] = string(c.role)
 	toset[dsess.DoltClusterRoleEpochVariable] = strconv.Itoa(c.epoch)
+	toset[persistentReplicatedEpochKey] = strconv.Itoa(c.position.epoch)
+	toset[persistentReplicatedSequenceKey] = strconv.FormatInt(c.position.sequence, 10)
 	return c.persistentCfg.SetStrings(toset)
 }
 
+func loadReplicatedPosition(pCfg config.ReadWriteConfig) (replicationPosition, error) {
+	var position replicationPosition
+	var err error
+	if position.epoch, err = strconv.Atoi(pCfg.GetStringOrDefault(persistentReplicatedEpochKey, "0")); err != nil {
+		return replicationPosition{}, fmt.Errorf("persisted replicated epoch %s.%s must be an integer: %w", PersistentConfigPrefix, persistentReplicatedEpochKey, err)
+	}
+	if position.sequence, err = strconv.ParseInt(pCfg.GetStringOrDefault(persistentReplicatedSequenceKey, "0"), 10, 64); err != nil {
+		return replicationPosition{}, fmt.Errorf("persisted replicated sequence %s.%s must be an integer: %w", PersistentConfigPrefix, persistentReplicatedSequenceKey, err)
+	}
+	return position, nil
+}
+
+func loadVote(pCfg config.ReadWriteConfig) (int, string, error) {
+	epoch, err := strconv.Atoi(pCfg.GetStringOrDefault(persistentVotedEpochKey, "0"))
+	if err != nil {
+		return 0, "", fmt.Errorf("persisted vote epoch %s.%s must be an integer: %w", PersistentConfigPrefix, persistentVotedEpochKey, err)
+	}
+	return epoch, pCfg.GetStringOrDefault(persistentVotedForKey, ""), nil
+}
+
+// persistVote persists that this server voted for |candidate| at |epoch|.
+func (c *Controller) persistVote(epoch int, candidate string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.persistentCfg.SetStrings(map[string]string{
+		persistentVotedEpochKey: strconv.Itoa(epoch),
+		persistentVotedForKey:   candidate,
+	})
+}
+
+// replicatedPosition returns the replicated position of this server. A
+// primary has every write made at its epoch so far.
+func (c *Controller) replicatedPosition() replicationPosition {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.role == RolePrimary {
+		return replicationPosition{epoch: c.epoch, sequence: time.Now().UnixNano()}
+	}
+	return c.position
+}
+
+// recordReplicatedPosition records that this server applied a write
+// replicated from the primary at |position|.
+func (c *Controller) recordReplicatedPosition(position replicationPosition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.position.less(position) {
+		return
+	}
+	c.position = position
+	if err := c.persistVariables(); err != nil {
+		c.lgr.Warnf("cluster: failed to persist the replicated position %d:%d: %v", position.epoch, position.sequence, err)
+	}
+}
+
 func applyBootstrapClusterConfig(lgr *logrus.Logger, cfg servercfg.ClusterConfig, pCfg config.ReadWriteConfig) (Role, int, error) {
 	toset := make(map[string]string)
 	persistentRole := pCfg.GetStringOrDefault(dsess.DoltClusterRoleVariable, "")
@@ -612,6 +715,11 @@ func (c *Controller) setRoleAndEpoch(role string, epoch int, opts roleTransition
 		}
 	}
 
+	if c.role == RolePrimary && role != string(RolePrimary) {
+		// This server has every write it made as the primary at its
+		// epoch, and makes none after this transition.
+		c.position = replicationPosition{epoch: c.epoch, sequence: time.Now().UnixNano()}
+	}
 	c.role = Role(role)
 	c.epoch = epoch
 
@@ -653,17 +761,31 @@ func (c *Controller) GetClusterStatus() []clusterdb.ReplicaStatus {
 	commithooks := make([]*commithook, len(c.commithooks))
 	copy(commithooks, c.commithooks)
 	c.mu.Unlock()
+	var electionState *string
+	if c.elector != nil {
+		state := c.elector.electionState(role)
+		electionState = &state
+	}
 	ret := make([]clusterdb.ReplicaStatus, len(commithooks))
-	for i, c := range commithooks {
-		lag, lastUpdate, currentErrorStr := c.status()
+	for i, ch := range commithooks {
+		lag, lastUpdate, currentErrorStr := ch.status()
 		ret[i] = clusterdb.ReplicaStatus{
-			Database:       c.dbname,
-			Remote:         c.remotename,
+			Database:       ch.dbname,
+			Remote:         ch.remotename,
 			Role:           string(role),
 			Epoch:          epoch,
 			ReplicationLag: lag,
 			LastUpdate:     lastUpdate,
 			CurrentError:   currentErrorStr,
+			ElectionState:  electionState,
+		}
+		if c.elector != nil {
+			if peerRole, peerEpoch, lastHeartbeat, ok := c.elector.peerStatus(ch.remotename); ok {
+				peerRoleStr := string(peerRole)
+				ret[i].PeerRole = &peerRoleStr
+				ret[i].PeerEpoch = &peerEpoch
+				ret[i].LastHeartbeat = &lastHeartbeat
+			}
 		}
 	}
 	return ret
@@ -762,6 +884,8 @@ func (c *Controller) RegisterGrpcServices(ctxFactory func(context.Context) (*sql
 		branchControlFilesys: c.branchControlFilesys,
 		dropDatabase:         c.dropDatabase,
 		lgr:                  c.lgr.WithFields(logrus.Fields{}),
+		elector:              c.elector,
+		roleAndEpoch:         c.roleAndEpoch,
 	})
 }
 
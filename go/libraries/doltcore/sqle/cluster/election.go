@@ -0,0 +1,480 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+)
+
+const (
+	electionStateLeader    = "leader"
+	electionStateFollower  = "follower"
+	electionStateCandidate = "candidate"
+	electionStateHalted    = "halted"
+)
+
+// An elector runs automatic failover for the servers of a cluster. Every
+// server sends a heartbeat carrying its role and epoch to each of its standby
+// remotes on every heartbeat interval, and learns their roles and epochs from
+// the responses.
+//
+// * A standby which has not heard from a primary for a randomized duration
+// between one and one and a half election timeouts asks the other servers to
+// vote for it at an epoch higher than any it has seen. A server grants at most
+// one vote per epoch, and only when it is a standby which has not heard from a
+// primary within the election timeout itself, and the replicated position of
+// the candidate is at least as current as its own. A server persists its vote
+// before replying, so that it does not vote twice at an epoch across a
+// restart. The candidate becomes the primary at the new epoch when a majority
+// of the cluster, itself included, granted it a vote.
+//
+// * A primary which has not exchanged heartbeats with a majority of the
+// cluster for an election timeout steps down to standby at its current epoch,
+// since the rest of the cluster may have elected another primary already.
+//
+// * A primary which learns that another server is primary, or is standby, at a
+// higher epoch than itself steps down to standby at that epoch. The
+// interceptors do the same for replication traffic, so that a replaced
+// primary is fenced as soon as it reaches any other server.
+//
+// Since a majority is needed both to elect and to keep a primary, a cluster
+// needs at least three servers to survive the loss of one of them. An elected
+// standby does not catch up on the writes which the previous primary did not
+// replicate to it before it became unreachable, but it has the latest write
+// of every server which voted for it.
+type elector struct {
+	lgr *logrus.Entry
+
+	// Identifies this server as a candidate.
+	id string
+
+	heartbeatInterval time.Duration
+	electionTimeout   time.Duration
+
+	peers []*electionPeer
+
+	roleAndEpoch    func() (Role, int)
+	setRoleAndEpoch func(role Role, epoch int) error
+	// The replicated position of this server.
+	position func() replicationPosition
+	// Persists the vote of this server before it is acted on.
+	persistVote func(epoch int, candidate string) error
+
+	mu sync.Mutex
+	// The last time this server heard from a primary at its epoch or a
+	// higher one.
+	lastPrimaryContact time.Time
+	// The last time a majority of the cluster exchanged heartbeats with this
+	// server while it was the primary.
+	lastQuorum time.Time
+	// The election timer of a standby starts from |timerStart| and fires
+	// after |timeout|, which is randomized every time the timer is reset.
+	timerStart time.Time
+	timeout    time.Duration
+	// The highest epoch this server voted at, and the candidate it voted for.
+	// |votedFor| is empty if this server refused to vote at that epoch.
+	votedEpoch int
+	votedFor   string
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// A replicationPosition identifies the latest write which a server has: the
+// epoch of the primary which made it, and its sequence among the writes of
+// that primary. The sequence of a write is the time at which the primary
+// began replicating it, which increases with every write since there is only
+// one primary at an epoch.
+type replicationPosition struct {
+	epoch    int
+	sequence int64
+}
+
+// less returns true if |p| is less current than |o|.
+func (p replicationPosition) less(o replicationPosition) bool {
+	if p.epoch != o.epoch {
+		return p.epoch < o.epoch
+	}
+	return p.sequence < o.sequence
+}
+
+type electionPeer struct {
+	remote string
+	client replicationapi.ReplicationServiceClient
+
+	// Guarded by the elector's |mu|.
+	role        Role
+	epoch       int
+	lastContact time.Time
+}
+
+// newElector returns an elector for a server which last voted for |votedFor|
+// at |votedEpoch|, as persisted by |persistVote|.
+func newElector(lgr *logrus.Entry, cfg servercfg.ClusterElectionConfig, clients []*replicationServiceClient, roleAndEpoch func() (Role, int), setRoleAndEpoch func(Role, int) error, position func() replicationPosition, votedEpoch int, votedFor string, persistVote func(int, string) error) *elector {
+	peers := make([]*electionPeer, len(clients))
+	for i, c := range clients {
+		peers[i] = &electionPeer{remote: c.remote, client: c.client}
+	}
+	e := &elector{
+		lgr:               lgr,
+		id:                uuid.New().String(),
+		heartbeatInterval: time.Duration(cfg.HeartbeatIntervalMillis()) * time.Millisecond,
+		electionTimeout:   time.Duration(cfg.ElectionTimeoutMillis()) * time.Millisecond,
+		peers:             peers,
+		roleAndEpoch:      roleAndEpoch,
+		setRoleAndEpoch:   setRoleAndEpoch,
+		position:          position,
+		persistVote:       persistVote,
+		stopCh:            make(chan struct{}),
+		doneCh:            make(chan struct{}),
+	}
+	_, e.votedEpoch = roleAndEpoch()
+	if votedEpoch >= e.votedEpoch {
+		e.votedEpoch, e.votedFor = votedEpoch, votedFor
+	}
+	// A server which just started gives the cluster an election timeout to
+	// let it hear from the current primary.
+	now := time.Now()
+	e.lastPrimaryContact = now
+	e.lastQuorum = now
+	e.resetTimer(now)
+	return e
+}
+
+func (e *elector) Run() {
+	defer close(e.doneCh)
+	ticker := time.NewTicker(e.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+		}
+		e.tick()
+	}
+}
+
+func (e *elector) GracefulStop() {
+	close(e.stopCh)
+	<-e.doneCh
+}
+
+// quorum is the number of servers which make up a majority of the cluster,
+// this server included.
+func (e *elector) quorum() int {
+	return (len(e.peers)+1)/2 + 1
+}
+
+// resetTimer restarts the election timer of a standby with a new randomized
+// timeout. Called with |mu| held.
+func (e *elector) resetTimer(now time.Time) {
+	e.timerStart = now
+	e.timeout = e.electionTimeout + time.Duration(rand.Int63n(int64(e.electionTimeout)/2+1))
+}
+
+func (e *elector) tick() {
+	role, epoch := e.roleAndEpoch()
+	switch role {
+	case RolePrimary:
+		acks := e.sendHeartbeats(role, epoch)
+		now := time.Now()
+		e.mu.Lock()
+		if acks+1 >= e.quorum() {
+			e.lastQuorum = now
+		}
+		lost := now.Sub(e.lastQuorum) > e.electionTimeout
+		e.mu.Unlock()
+		if lost {
+			e.lgr.Warnf("cluster: elector: this server is primary at epoch %d but did not hear from a majority of the cluster for %v. transitioning to standby.", epoch, e.electionTimeout)
+			e.setRole(RoleStandby, epoch)
+		}
+	case RoleStandby:
+		e.sendHeartbeats(role, epoch)
+		e.mu.Lock()
+		expired := time.Since(e.timerStart) > e.timeout
+		e.mu.Unlock()
+		if expired {
+			e.campaign()
+		}
+	default:
+		// In detected_broken_config an operator needs to get involved.
+	}
+}
+
+// sendHeartbeats sends a heartbeat to every peer and processes the responses.
+// Returns the number of peers which responded.
+func (e *elector) sendHeartbeats(role Role, epoch int) int {
+	resps := make([]*replicationapi.HeartbeatResponse, len(e.peers))
+	var wg sync.WaitGroup
+	for i, p := range e.peers {
+		i, p := i, p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), e.heartbeatInterval)
+			defer cancel()
+			resp, err := p.client.Heartbeat(ctx, &replicationapi.HeartbeatRequest{
+				Role:  string(role),
+				Epoch: int64(epoch),
+			})
+			if err != nil {
+				e.lgr.Tracef("cluster: elector: heartbeat to %s failed: %v", p.remote, err)
+				return
+			}
+			resps[i] = resp
+		}()
+	}
+	wg.Wait()
+
+	acks := 0
+	now := time.Now()
+	highestPrimary, highestEpoch := -1, -1
+	for i, resp := range resps {
+		if resp == nil {
+			continue
+		}
+		acks += 1
+		respRole, respEpoch := Role(resp.Role), int(resp.Epoch)
+		e.mu.Lock()
+		p := e.peers[i]
+		p.role, p.epoch, p.lastContact = respRole, respEpoch, now
+		if respRole == RolePrimary && respEpoch >= epoch {
+			e.lastPrimaryContact = now
+			e.resetTimer(now)
+		}
+		e.mu.Unlock()
+		if respRole == RolePrimary && respEpoch > highestPrimary {
+			highestPrimary = respEpoch
+		}
+		if respRole != RoleDetectedBrokenConfig && respEpoch > highestEpoch {
+			highestEpoch = respEpoch
+		}
+	}
+
+	if role == RolePrimary && highestEpoch > epoch {
+		e.lgr.Warnf("cluster: elector: this server is primary at epoch %d. a standby remote is at epoch %d. transitioning to standby.", epoch, highestEpoch)
+		e.setRole(RoleStandby, highestEpoch)
+	} else if role == RoleStandby && highestPrimary > epoch {
+		e.setRole(RoleStandby, highestPrimary)
+	}
+	return acks
+}
+
+// campaign asks every peer to vote for this server at a new epoch, and
+// transitions this server to primary at that epoch if a majority of the
+// cluster granted its vote.
+func (e *elector) campaign() {
+	_, epoch := e.roleAndEpoch()
+	e.mu.Lock()
+	next := epoch
+	if e.votedEpoch > next {
+		next = e.votedEpoch
+	}
+	for _, p := range e.peers {
+		if p.epoch > next {
+			next = p.epoch
+		}
+	}
+	next += 1
+	e.resetTimer(time.Now())
+	if err := e.persistVote(next, e.id); err != nil {
+		e.mu.Unlock()
+		e.lgr.Warnf("cluster: elector: failed to persist the vote for this server at epoch %d: %v", next, err)
+		return
+	}
+	e.votedEpoch = next
+	e.votedFor = e.id
+	e.mu.Unlock()
+	position := e.position()
+
+	e.lgr.Infof("cluster: elector: did not hear from a primary. requesting votes to become primary at epoch %d.", next)
+	granted := make([]bool, len(e.peers))
+	var wg sync.WaitGroup
+	for i, p := range e.peers {
+		i, p := i, p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), e.heartbeatInterval)
+			defer cancel()
+			resp, err := p.client.RequestVote(ctx, &replicationapi.RequestVoteRequest{
+				Epoch:              int64(next),
+				CandidateId:        e.id,
+				ReplicatedEpoch:    int64(position.epoch),
+				ReplicatedSequence: position.sequence,
+			})
+			if err != nil {
+				e.lgr.Tracef("cluster: elector: vote request to %s failed: %v", p.remote, err)
+				return
+			}
+			granted[i] = resp.Granted
+		}()
+	}
+	wg.Wait()
+
+	votes := 1
+	for _, g := range granted {
+		if g {
+			votes += 1
+		}
+	}
+	if votes < e.quorum() {
+		e.lgr.Infof("cluster: elector: received %d of the %d votes needed to become primary at epoch %d.", votes, e.quorum(), next)
+		return
+	}
+	role, epoch := e.roleAndEpoch()
+	if role != RoleStandby || epoch >= next {
+		// Our role changed while we were campaigning.
+		return
+	}
+	e.lgr.Warnf("cluster: elector: elected primary at epoch %d with %d votes. transitioning to primary.", next, votes)
+	e.mu.Lock()
+	e.lastQuorum = time.Now()
+	e.mu.Unlock()
+	e.setRole(RolePrimary, next)
+}
+
+func (e *elector) setRole(role Role, epoch int) {
+	if err := e.setRoleAndEpoch(role, epoch); err != nil {
+		e.lgr.Warnf("cluster: elector: failed to transition to %s at epoch %d: %v", role, epoch, err)
+	}
+}
+
+func (e *elector) handleHeartbeat(req *replicationapi.HeartbeatRequest) *replicationapi.HeartbeatResponse {
+	reqRole, reqEpoch := Role(req.Role), int(req.Epoch)
+	role, epoch := e.roleAndEpoch()
+	if reqRole == RolePrimary && (reqEpoch > epoch || (reqEpoch == epoch && role == RoleStandby)) {
+		now := time.Now()
+		e.mu.Lock()
+		e.lastPrimaryContact = now
+		e.resetTimer(now)
+		e.mu.Unlock()
+		if reqEpoch > epoch {
+			if role == RolePrimary {
+				e.lgr.Warnf("cluster: elector: this server is primary at epoch %d. received a heartbeat from a primary at epoch %d. transitioning to standby.", epoch, reqEpoch)
+			}
+			e.setRole(RoleStandby, reqEpoch)
+		}
+	}
+	role, epoch = e.roleAndEpoch()
+	return &replicationapi.HeartbeatResponse{
+		Role:  string(role),
+		Epoch: int64(epoch),
+	}
+}
+
+func (e *elector) handleRequestVote(req *replicationapi.RequestVoteRequest) *replicationapi.RequestVoteResponse {
+	reqEpoch := int(req.Epoch)
+	reqPosition := replicationPosition{epoch: int(req.ReplicatedEpoch), sequence: req.ReplicatedSequence}
+	role, epoch := e.roleAndEpoch()
+	position := e.position()
+	now := time.Now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	granted := false
+	switch {
+	case role != RoleStandby:
+	case reqEpoch <= epoch:
+	case now.Sub(e.lastPrimaryContact) < e.electionTimeout:
+	case reqEpoch < e.votedEpoch:
+	case reqEpoch == e.votedEpoch && e.votedFor != req.CandidateId:
+	case reqPosition.less(position):
+		e.lgr.Infof("cluster: elector: refusing the vote at epoch %d to a candidate at replicated position %d:%d, behind this server at %d:%d.", reqEpoch, reqPosition.epoch, reqPosition.sequence, position.epoch, position.sequence)
+		// This server doesn't vote at the candidate's epoch, so that its own
+		// campaign is at a later epoch, which the candidate can vote for.
+		// Otherwise both could keep campaigning at the same epoch.
+		if reqEpoch > e.votedEpoch {
+			if err := e.persistVote(reqEpoch, ""); err != nil {
+				e.lgr.Warnf("cluster: elector: failed to persist the refused vote at epoch %d: %v", reqEpoch, err)
+				break
+			}
+			e.votedEpoch = reqEpoch
+			e.votedFor = ""
+		}
+	default:
+		// The vote must be durable before the candidate can count on it.
+		if err := e.persistVote(reqEpoch, req.CandidateId); err != nil {
+			e.lgr.Warnf("cluster: elector: failed to persist the vote at epoch %d: %v", reqEpoch, err)
+			break
+		}
+		granted = true
+		e.votedEpoch = reqEpoch
+		e.votedFor = req.CandidateId
+		// Give the candidate a chance to win before competing with it.
+		e.resetTimer(now)
+	}
+	e.lgr.Debugf("cluster: elector: vote request at epoch %d; granted: %v", reqEpoch, granted)
+	return &replicationapi.RequestVoteResponse{
+		Granted: granted,
+		Epoch:   int64(epoch),
+	}
+}
+
+// electionState describes the state of this server in elections, as reported
+// in dolt_cluster_status.
+func (e *elector) electionState(role Role) string {
+	switch role {
+	case RolePrimary:
+		return electionStateLeader
+	case RoleStandby:
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if time.Since(e.lastPrimaryContact) < e.electionTimeout {
+			return electionStateFollower
+		}
+		return electionStateCandidate
+	default:
+		return electionStateHalted
+	}
+}
+
+// peerStatus returns the role and epoch which |remote| reported in the last
+// heartbeat exchanged with it, and when that was. Returns false if no
+// heartbeat was exchanged with it yet.
+func (e *elector) peerStatus(remote string) (Role, int, time.Time, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, p := range e.peers {
+		if p.remote == remote && !p.lastContact.IsZero() {
+			return p.role, p.epoch, p.lastContact, true
+		}
+	}
+	return "", 0, time.Time{}, false
+}
+
+// knownPrimary returns the peer which reported being the primary at |epoch|
+// or a later one in a heartbeat exchanged within the last election timeout.
+// Returns "" if there is no such peer.
+func (e *elector) knownPrimary(epoch int) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	primary, highest := "", epoch-1
+	for _, p := range e.peers {
+		if p.role == RolePrimary && p.epoch > highest && time.Since(p.lastContact) < e.electionTimeout {
+			primary, highest = p.remote, p.epoch
+		}
+	}
+	return primary
+}
@@ -0,0 +1,421 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+)
+
+// electionTestNode is a cluster member serving the replication service on
+// loopback, with the role and epoch bookkeeping of a Controller.
+type electionTestNode struct {
+	name    string
+	elector *elector
+	running bool
+
+	ci  clientinterceptor
+	si  serverinterceptor
+	lis net.Listener
+	srv *grpc.Server
+
+	clients []*replicationServiceClient
+
+	mu       sync.Mutex
+	role     Role
+	epoch    int
+	position replicationPosition
+	// The vote as persisted by the elector.
+	votedEpoch int
+	votedFor   string
+}
+
+func (n *electionTestNode) roleAndEpoch() (Role, int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.role, n.epoch
+}
+
+func (n *electionTestNode) replicatedPosition() replicationPosition {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.position
+}
+
+func (n *electionTestNode) persistVote(epoch int, candidate string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.votedEpoch, n.votedFor = epoch, candidate
+	return nil
+}
+
+func (n *electionTestNode) setRoleAndEpoch(role Role, epoch int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if epoch < n.epoch || (epoch == n.epoch && role == RolePrimary && n.role != RolePrimary) {
+		return fmt.Errorf("cannot become %s at epoch %d; already %s at epoch %d", role, epoch, n.role, n.epoch)
+	}
+	n.role, n.epoch = role, epoch
+	n.ci.setRole(role, epoch)
+	n.si.setRole(role, epoch)
+	return nil
+}
+
+// electionTestCluster simulates network partitions by failing the requests
+// sent by or to partitioned nodes.
+type electionTestCluster struct {
+	nodes map[string]*electionTestNode
+
+	mu          sync.Mutex
+	partitioned map[string]bool
+}
+
+func (c *electionTestCluster) partition(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range names {
+		c.partitioned[name] = true
+	}
+}
+
+func (c *electionTestCluster) heal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.partitioned = make(map[string]bool)
+}
+
+func (c *electionTestCluster) interceptor(from, to string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		c.mu.Lock()
+		down := c.partitioned[from] || c.partitioned[to]
+		c.mu.Unlock()
+		if down {
+			return status.Error(codes.Unavailable, "partitioned")
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+newJWT())
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (c *electionTestCluster) roles() map[string]string {
+	ret := make(map[string]string)
+	for name, n := range c.nodes {
+		role, epoch := n.roleAndEpoch()
+		ret[name] = fmt.Sprintf("%s@%d", role, epoch)
+	}
+	return ret
+}
+
+func newElectionTestCluster(t *testing.T, primary string, names ...string) *electionTestCluster {
+	return newElectionTestClusterWithPositions(t, primary, nil, names...)
+}
+
+// newElectionTestClusterWithPositions creates a cluster in which the nodes
+// named in |positions| start at the given replicated positions.
+func newElectionTestClusterWithPositions(t *testing.T, primary string, positions map[string]replicationPosition, names ...string) *electionTestCluster {
+	c := &electionTestCluster{
+		nodes:       make(map[string]*electionTestNode),
+		partitioned: make(map[string]bool),
+	}
+	for _, name := range names {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		n := &electionTestNode{name: name, lis: lis, role: RoleStandby, epoch: 1, position: positions[name]}
+		if name == primary {
+			n.role = RolePrimary
+		}
+		n.ci.lgr = lgr
+		n.ci.fenceStaleEpochs = true
+		n.ci.roleSetter = func(role string, epoch int) { _ = n.setRoleAndEpoch(Role(role), epoch) }
+		n.ci.setRole(n.role, n.epoch)
+		n.si.lgr = lgr
+		n.si.keyProvider = kp
+		n.si.fenceStaleEpochs = true
+		n.si.roleSetter = func(role string, epoch int) { _ = n.setRoleAndEpoch(Role(role), epoch) }
+		n.si.setRole(n.role, n.epoch)
+		c.nodes[name] = n
+	}
+
+	election := &servercfg.ClusterElectionYAMLConfig{
+		HeartbeatIntervalMillis_: ptrUint64(20),
+		ElectionTimeoutMillis_:   ptrUint64(200),
+	}
+	for _, name := range names {
+		n := c.nodes[name]
+		for _, other := range names {
+			if other == name {
+				continue
+			}
+			cc, err := grpc.Dial(c.nodes[other].lis.Addr().String(),
+				grpc.WithInsecure(),
+				grpc.WithChainUnaryInterceptor(c.interceptor(name, other), n.ci.Unary()))
+			require.NoError(t, err)
+			n.clients = append(n.clients, &replicationServiceClient{
+				remote: other,
+				client: replicationapi.NewReplicationServiceClient(cc),
+				closer: cc.Close,
+			})
+		}
+		n.elector = newElector(lgr.WithField("node", name), election, n.clients, n.roleAndEpoch, n.setRoleAndEpoch, n.replicatedPosition, 0, "", n.persistVote)
+		n.srv = grpc.NewServer(n.si.Options()...)
+		replicationapi.RegisterReplicationServiceServer(n.srv, &replicationServiceServer{
+			lgr:     lgr,
+			elector: n.elector,
+		})
+		go n.srv.Serve(n.lis)
+	}
+	for _, n := range c.nodes {
+		n.running = true
+		go n.elector.Run()
+	}
+
+	t.Cleanup(func() {
+		for _, n := range c.nodes {
+			if n.running {
+				n.elector.GracefulStop()
+			}
+			n.srv.Stop()
+			for _, client := range n.clients {
+				client.closer()
+			}
+		}
+	})
+	return c
+}
+
+func ptrUint64(v uint64) *uint64 {
+	return &v
+}
+
+func TestElectionStablePrimary(t *testing.T) {
+	c := newElectionTestCluster(t, "a", "a", "b", "c")
+	time.Sleep(time.Second)
+	assert.Equal(t, map[string]string{
+		"a": "primary@1",
+		"b": "standby@1",
+		"c": "standby@1",
+	}, c.roles())
+	assert.Equal(t, electionStateLeader, c.nodes["a"].elector.electionState(RolePrimary))
+	assert.Equal(t, electionStateFollower, c.nodes["b"].elector.electionState(RoleStandby))
+
+	role, epoch, lastHeartbeat, ok := c.nodes["a"].elector.peerStatus("b")
+	require.True(t, ok)
+	assert.Equal(t, RoleStandby, role)
+	assert.Equal(t, 1, epoch)
+	assert.WithinDuration(t, time.Now(), lastHeartbeat, time.Second)
+
+	assert.Equal(t, "a", c.nodes["b"].elector.knownPrimary(1))
+	assert.Equal(t, "", c.nodes["b"].elector.knownPrimary(2))
+}
+
+func TestElectionFailover(t *testing.T) {
+	c := newElectionTestCluster(t, "a", "a", "b", "c")
+	time.Sleep(100 * time.Millisecond)
+
+	c.partition("a")
+	var epoch int
+	require.Eventually(t, func() bool {
+		_, epoch = c.elected("b", "c")
+		return epoch > 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The partitioned primary lost its quorum and stepped down.
+	require.Eventually(t, func() bool {
+		return c.roles()["a"] == "standby@1"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Once it can reach the cluster again, it follows the new primary.
+	c.heal()
+	require.Eventually(t, func() bool {
+		return c.roles()["a"] == fmt.Sprintf("standby@%d", epoch)
+	}, 5*time.Second, 10*time.Millisecond)
+	_, after := c.elected("a", "b", "c")
+	assert.Equal(t, epoch, after)
+}
+
+func TestElectionFencesStalePrimary(t *testing.T) {
+	c := newElectionTestCluster(t, "a", "a", "b", "c")
+	time.Sleep(100 * time.Millisecond)
+
+	// The primary pauses, and does not notice that the cluster moves on
+	// without it.
+	a := c.nodes["a"]
+	a.elector.GracefulStop()
+	a.running = false
+	c.partition("a")
+	var epoch int
+	require.Eventually(t, func() bool {
+		_, epoch = c.elected("b", "c")
+		return epoch > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	c.heal()
+	assert.Equal(t, "primary@1", c.roles()["a"])
+
+	// Its replication traffic is rejected by the rest of the cluster, and
+	// it learns that it was replaced.
+	for _, client := range a.clients {
+		_, err := client.client.DropDatabase(context.Background(), &replicationapi.DropDatabaseRequest{Name: "db"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, fmt.Sprintf("standby@%d", epoch), c.roles()["a"])
+	}
+}
+
+func TestElectionMinorityDoesNotElect(t *testing.T) {
+	c := newElectionTestCluster(t, "a", "a", "b", "c")
+	time.Sleep(100 * time.Millisecond)
+
+	c.partition("c")
+	require.Eventually(t, func() bool {
+		return c.nodes["c"].elector.electionState(RoleStandby) == electionStateCandidate
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(time.Second)
+	assert.Equal(t, map[string]string{
+		"a": "primary@1",
+		"b": "standby@1",
+		"c": "standby@1",
+	}, c.roles())
+
+	// The failed campaigns of the partitioned standby do not disrupt the
+	// primary when it comes back.
+	c.heal()
+	require.Eventually(t, func() bool {
+		return c.nodes["c"].elector.electionState(RoleStandby) == electionStateFollower
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]string{
+		"a": "primary@1",
+		"b": "standby@1",
+		"c": "standby@1",
+	}, c.roles())
+}
+
+func TestElectionElectsMostCurrentStandby(t *testing.T) {
+	c := newElectionTestClusterWithPositions(t, "a", map[string]replicationPosition{
+		"b": {epoch: 1, sequence: 10},
+		"c": {epoch: 1, sequence: 5},
+	}, "a", "b", "c")
+	time.Sleep(100 * time.Millisecond)
+
+	// c is behind b, so b refuses to vote for it, and only b can win.
+	c.partition("a")
+	require.Eventually(t, func() bool {
+		_, epoch := c.elected("b", "c")
+		return epoch > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	primary, _ := c.elected("b", "c")
+	assert.Equal(t, "b", primary)
+}
+
+func TestElectionVotes(t *testing.T) {
+	election := &servercfg.ClusterElectionYAMLConfig{
+		HeartbeatIntervalMillis_: ptrUint64(20),
+		ElectionTimeoutMillis_:   ptrUint64(200),
+	}
+	// newVoter returns the elector of a standby at epoch 1 which has not heard
+	// from a primary in a while, and which persists its votes to |n|.
+	newVoter := func(n *electionTestNode, persistVote func(int, string) error) *elector {
+		n.role, n.epoch = RoleStandby, 1
+		e := newElector(lgr.WithField("node", "voter"), election, nil, n.roleAndEpoch, n.setRoleAndEpoch, n.replicatedPosition, n.votedEpoch, n.votedFor, persistVote)
+		e.lastPrimaryContact = time.Now().Add(-time.Second)
+		return e
+	}
+	request := func(epoch int, candidate string, position replicationPosition) *replicationapi.RequestVoteRequest {
+		return &replicationapi.RequestVoteRequest{
+			Epoch:              int64(epoch),
+			CandidateId:        candidate,
+			ReplicatedEpoch:    int64(position.epoch),
+			ReplicatedSequence: position.sequence,
+		}
+	}
+
+	t.Run("CandidatePosition", func(t *testing.T) {
+		n := &electionTestNode{position: replicationPosition{epoch: 2, sequence: 10}}
+		e := newVoter(n, n.persistVote)
+		assert.False(t, e.handleRequestVote(request(3, "x", replicationPosition{epoch: 1, sequence: 20})).Granted)
+		assert.False(t, e.handleRequestVote(request(3, "x", replicationPosition{epoch: 2, sequence: 9})).Granted)
+		// The voter doesn't vote at the epoch of a candidate it refused, so
+		// that its own campaign is at a later epoch.
+		assert.Equal(t, 3, n.votedEpoch)
+		assert.Equal(t, "", n.votedFor)
+		assert.False(t, e.handleRequestVote(request(3, "x", replicationPosition{epoch: 2, sequence: 10})).Granted)
+		assert.True(t, e.handleRequestVote(request(4, "x", replicationPosition{epoch: 2, sequence: 10})).Granted)
+		assert.True(t, e.handleRequestVote(request(5, "y", replicationPosition{epoch: 3, sequence: 1})).Granted)
+	})
+	t.Run("PersistedBeforeReplying", func(t *testing.T) {
+		n := &electionTestNode{}
+		var e *elector
+		e = newVoter(n, func(epoch int, candidate string) error {
+			// The vote is not granted yet when it is persisted.
+			assert.NotEqual(t, epoch, e.votedEpoch)
+			return n.persistVote(epoch, candidate)
+		})
+		assert.True(t, e.handleRequestVote(request(2, "x", replicationPosition{})).Granted)
+		assert.Equal(t, 2, n.votedEpoch)
+		assert.Equal(t, "x", n.votedFor)
+
+		// After a restart, the voter remembers its vote.
+		e = newVoter(n, n.persistVote)
+		assert.False(t, e.handleRequestVote(request(2, "y", replicationPosition{})).Granted)
+		assert.True(t, e.handleRequestVote(request(2, "x", replicationPosition{})).Granted)
+	})
+	t.Run("NotGrantedIfNotPersisted", func(t *testing.T) {
+		n := &electionTestNode{}
+		e := newVoter(n, func(int, string) error {
+			return errors.New("disk full")
+		})
+		assert.False(t, e.handleRequestVote(request(2, "x", replicationPosition{})).Granted)
+
+		// It is free to vote for another candidate at the epoch.
+		e.persistVote = n.persistVote
+		assert.True(t, e.handleRequestVote(request(2, "y", replicationPosition{})).Granted)
+	})
+}
+
+// elected returns the primary among |names| and its epoch if it is the only
+// primary among them, and all the others are standbys at its epoch. Returns
+// an epoch of 0 otherwise.
+func (c *electionTestCluster) elected(names ...string) (string, int) {
+	primary, epoch := "", 0
+	for _, name := range names {
+		role, e := c.nodes[name].roleAndEpoch()
+		if role == RolePrimary {
+			if primary != "" {
+				return "", 0
+			}
+			primary, epoch = name, e
+		}
+	}
+	for _, name := range names {
+		role, e := c.nodes[name].roleAndEpoch()
+		if e != epoch || (name != primary && role != RoleStandby) {
+			return "", 0
+		}
+	}
+	return primary, epoch
+}
@@ -34,13 +34,26 @@ import (
 const clusterRoleHeader = "x-dolt-cluster-role"
 const clusterRoleEpochHeader = "x-dolt-cluster-role-epoch"
 
+// clusterReplicationSequenceHeader carries the sequence of the write which a
+// primary replicates in a Commit to a standby. See replicationPosition.
+const clusterReplicationSequenceHeader = "x-dolt-cluster-replication-sequence"
+
 var writeEndpoints map[string]bool
 
+// electionEndpoints are exchanged between cluster members whatever their
+// roles, so they are not subject to the role checks which gate replication
+// traffic.
+var electionEndpoints map[string]bool
+
 func init() {
 	writeEndpoints = make(map[string]bool)
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/Commit"] = true
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/AddTableFiles"] = true
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/GetUploadLocations"] = true
+
+	electionEndpoints = make(map[string]bool)
+	electionEndpoints["/dolt.services.replicationapi.v1alpha1.ReplicationService/Heartbeat"] = true
+	electionEndpoints["/dolt.services.replicationapi.v1alpha1.ReplicationService/RequestVote"] = true
 }
 
 func isLikelyServerResponse(err error) bool {
@@ -71,12 +84,18 @@ func isLikelyServerResponse(err error) bool {
 // response header asserts that the standby replica is a primary at a higher
 // epoch than this server, this incterceptor coordinates with the Controller to
 // immediately transition to standby and to stop replicating to the standby.
+// When |fenceStaleEpochs| is set, which it is when the cluster runs
+// elections, a standby at a higher epoch than this primary also causes the
+// transition, since it means another server was elected in the meantime.
+//
+// Requests to electionEndpoints are sent whatever the role of this server.
 type clientinterceptor struct {
-	lgr        *logrus.Entry
-	role       Role
-	epoch      int
-	mu         sync.Mutex
-	roleSetter func(role string, epoch int)
+	lgr              *logrus.Entry
+	role             Role
+	epoch            int
+	mu               sync.Mutex
+	roleSetter       func(role string, epoch int)
+	fenceStaleEpochs bool
 }
 
 func (ci *clientinterceptor) setRole(role Role, epoch int) {
@@ -96,7 +115,7 @@ func (ci *clientinterceptor) Stream() grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		role, epoch := ci.getRole()
 		ci.lgr.Tracef("cluster: clientinterceptor: processing request to %s, role %s", method, string(role))
-		if role == RoleStandby {
+		if role == RoleStandby && !electionEndpoints[method] {
 			return nil, status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is a standby and is not currently replicating to its standby")
 		}
 		if role == RoleDetectedBrokenConfig {
@@ -114,7 +133,7 @@ func (ci *clientinterceptor) Unary() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		role, epoch := ci.getRole()
 		ci.lgr.Tracef("cluster: clientinterceptor: processing request to %s, role %s", method, string(role))
-		if role == RoleStandby {
+		if role == RoleStandby && !electionEndpoints[method] {
 			return status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is a standby and is not currently replicating to its standby")
 		}
 		if role == RoleDetectedBrokenConfig {
@@ -152,6 +171,9 @@ func (ci *clientinterceptor) handleResponseHeaders(header metadata.MD, err error
 			} else if respRole == string(RoleDetectedBrokenConfig) && respEpoch >= epoch {
 				ci.lgr.Errorf("cluster: clientinterceptor: this server learned from its standby that the standby is in detected_broken_config at the same or higher epoch. force transitioning to detected_broken_config.")
 				ci.roleSetter(string(RoleDetectedBrokenConfig), respEpoch)
+			} else if respRole == string(RoleStandby) && respEpoch > epoch && ci.fenceStaleEpochs {
+				ci.lgr.Warnf("cluster: clientinterceptor: this server is primary at epoch %d. a server it attempted to replicate to is standby at epoch %d, so a new primary was elected. force transitioning to standby.", epoch, respEpoch)
+				ci.roleSetter(string(RoleStandby), respEpoch)
 			}
 		} else {
 			ci.lgr.Errorf("cluster: clientinterceptor: failed to parse epoch in response header; something is wrong: %v", err)
@@ -191,12 +213,19 @@ func (ci *clientinterceptor) Options() []grpc.DialOption {
 // from standby replicas. It is instantiated with a jwtauth.KeyProvider and
 // some jwt.Expected. Incoming requests must have a valid, unexpired, signed
 // JWT, signed by a key accessible in the KeyProvider.
+//
+// Requests to electionEndpoints from cluster members are handled whatever the
+// role of this server. When |fenceStaleEpochs| is set, other requests from a
+// primary at a lower epoch than this server are failed with
+// codes.FailedPrecondition, so that a primary which was replaced through an
+// election can not replicate to the rest of the cluster.
 type serverinterceptor struct {
-	lgr        *logrus.Entry
-	role       Role
-	epoch      int
-	mu         sync.Mutex
-	roleSetter func(role string, epoch int)
+	lgr              *logrus.Entry
+	role             Role
+	epoch            int
+	mu               sync.Mutex
+	roleSetter       func(role string, epoch int)
+	fenceStaleEpochs bool
 
 	keyProvider jwtauth.KeyProvider
 	jwtExpected jwt.Expected
@@ -217,6 +246,12 @@ func (si *serverinterceptor) Stream() grpc.StreamServerInterceptor {
 			if err := grpc.SetHeader(ss.Context(), metadata.Pairs(clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))); err != nil {
 				return err
 			}
+			if electionEndpoints[info.FullMethod] {
+				return handler(srv, ss)
+			}
+			if si.isStalePrimary(ss.Context(), epoch) {
+				return status.Error(codes.FailedPrecondition, "this server is at a higher epoch than the primary replicating to it and is not accepting its replication")
+			}
 			if role == RolePrimary {
 				// As a primary, we do not accept replication requests.
 				return status.Error(codes.FailedPrecondition, "this server is a primary and is not currently accepting replication")
@@ -249,6 +284,12 @@ func (si *serverinterceptor) Unary() grpc.UnaryServerInterceptor {
 			if err := grpc.SetHeader(ctx, metadata.Pairs(clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))); err != nil {
 				return nil, err
 			}
+			if electionEndpoints[info.FullMethod] {
+				return handler(ctx, req)
+			}
+			if si.isStalePrimary(ctx, epoch) {
+				return nil, status.Error(codes.FailedPrecondition, "this server is at a higher epoch than the primary replicating to it and is not accepting its replication")
+			}
 			if role == RolePrimary {
 				// As a primary, we do not accept replication requests.
 				return nil, status.Error(codes.FailedPrecondition, "this server is a primary and is not currently accepting replication")
@@ -298,6 +339,28 @@ func (si *serverinterceptor) handleRequestHeaders(header metadata.MD) bool {
 	return false
 }
 
+// isStalePrimary returns true if |fenceStaleEpochs| is set and the incoming
+// request comes from a primary at a lower epoch than |epoch|.
+func (si *serverinterceptor) isStalePrimary(ctx context.Context, epoch int) bool {
+	if !si.fenceStaleEpochs {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	roles, epochs := md.Get(clusterRoleHeader), md.Get(clusterRoleEpochHeader)
+	if len(roles) == 0 || len(epochs) == 0 || roles[0] != string(RolePrimary) {
+		return false
+	}
+	reqepoch, err := strconv.Atoi(epochs[0])
+	if err != nil || reqepoch >= epoch {
+		return false
+	}
+	si.lgr.Warnf("cluster: serverinterceptor: this server is at epoch %d. rejecting replication from a primary at epoch %d.", epoch, reqepoch)
+	return true
+}
+
 func (si *serverinterceptor) Options() []grpc.ServerOption {
 	return []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(si.Unary()),
@@ -0,0 +1,101 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
+)
+
+// ErrNoKnownPrimary is returned by PrimarySQLAddress when none of the
+// standby remotes reports being the primary at the epoch of this server or a
+// later one.
+var ErrNoKnownPrimary = errors.New("cluster: none of the standby remotes is known to be the primary")
+
+// How long a standby waits for its standby remotes to report their roles
+// when looking for the primary.
+const primaryProbeTimeout = 2 * time.Second
+
+// IsStandby returns true if this server is currently a standby.
+func (c *Controller) IsStandby() bool {
+	role, _ := c.roleAndEpoch()
+	return role == RoleStandby
+}
+
+// PrimarySQLAddress returns the sql_address of the standby remote which is
+// currently the primary of the cluster. When the cluster runs elections, the
+// roles which the standby remotes reported in the last heartbeats are used if
+// they are recent enough. Otherwise the standby remotes are asked for their
+// roles, and the one which is primary at the highest epoch, which must not be
+// lower than the epoch of this server, is returned.
+func (c *Controller) PrimarySQLAddress(ctx context.Context) (string, error) {
+	_, epoch := c.roleAndEpoch()
+	remote := ""
+	if c.elector != nil {
+		remote = c.elector.knownPrimary(epoch)
+	}
+	if remote == "" {
+		remote = c.probePrimary(ctx, epoch)
+	}
+	if remote == "" {
+		return "", ErrNoKnownPrimary
+	}
+	for _, r := range c.cfg.StandbyRemotes() {
+		if r.Name() == remote && r.SQLAddress() != "" {
+			return r.SQLAddress(), nil
+		}
+	}
+	return "", ErrNoKnownPrimary
+}
+
+// probePrimary sends a heartbeat to every standby remote, and returns the one
+// which reported being the primary at the highest epoch no lower than
+// |epoch|. Returns "" if none of them did.
+func (c *Controller) probePrimary(ctx context.Context, epoch int) string {
+	role, _ := c.roleAndEpoch()
+	ctx, cancel := context.WithTimeout(ctx, primaryProbeTimeout)
+	defer cancel()
+	resps := make([]*replicationapi.HeartbeatResponse, len(c.replicationClients))
+	var wg sync.WaitGroup
+	for i, client := range c.replicationClients {
+		i, client := i, client
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.client.Heartbeat(ctx, &replicationapi.HeartbeatRequest{
+				Role:  string(role),
+				Epoch: int64(epoch),
+			})
+			if err != nil {
+				c.lgr.Tracef("cluster: looking for the primary: heartbeat to %s failed: %v", client.remote, err)
+				return
+			}
+			resps[i] = resp
+		}()
+	}
+	wg.Wait()
+
+	primary, highest := "", epoch-1
+	for i, resp := range resps {
+		if resp != nil && Role(resp.Role) == RolePrimary && int(resp.Epoch) > highest {
+			primary, highest = c.replicationClients[i].remote, int(resp.Epoch)
+		}
+	}
+	return primary
+}
@@ -16,6 +16,9 @@ package cluster
 
 import (
 	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -44,6 +47,32 @@ func (rss remotesrvStore) Commit(ctx context.Context, current, last hash.Hash) (
 	res, err := rss.RemoteSrvStore.Commit(ctx, current, last)
 	if err == nil && res {
 		rss.controller.recordSuccessfulRemoteSrvCommit(rss.path)
+		if position, ok := replicatedPositionFromContext(ctx); ok {
+			rss.controller.recordReplicatedPosition(position)
+		}
 	}
 	return res, err
 }
+
+// replicatedPositionFromContext returns the position of the write which a
+// primary replicated in the Commit request of |ctx|. Returns false if the
+// request did not come from a primary which sent its position.
+func replicatedPositionFromContext(ctx context.Context) (replicationPosition, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return replicationPosition{}, false
+	}
+	roles, epochs, sequences := md.Get(clusterRoleHeader), md.Get(clusterRoleEpochHeader), md.Get(clusterReplicationSequenceHeader)
+	if len(roles) != 1 || Role(roles[0]) != RolePrimary || len(epochs) != 1 || len(sequences) != 1 {
+		return replicationPosition{}, false
+	}
+	epoch, err := strconv.Atoi(epochs[0])
+	if err != nil {
+		return replicationPosition{}, false
+	}
+	sequence, err := strconv.ParseInt(sequences[0], 10, 64)
+	if err != nil {
+		return replicationPosition{}, false
+	}
+	return replicationPosition{epoch: epoch, sequence: sequence}, true
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestReplicatedPositionFromContext(t *testing.T) {
+	incoming := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+	}
+
+	position, ok := replicatedPositionFromContext(incoming(clusterRoleHeader, "primary", clusterRoleEpochHeader, "3", clusterReplicationSequenceHeader, "42"))
+	assert.True(t, ok)
+	assert.Equal(t, replicationPosition{epoch: 3, sequence: 42}, position)
+
+	_, ok = replicatedPositionFromContext(context.Background())
+	assert.False(t, ok)
+	_, ok = replicatedPositionFromContext(incoming(clusterRoleHeader, "primary", clusterRoleEpochHeader, "3"))
+	assert.False(t, ok)
+	_, ok = replicatedPositionFromContext(incoming(clusterRoleHeader, "standby", clusterRoleEpochHeader, "3", clusterReplicationSequenceHeader, "42"))
+	assert.False(t, ok)
+	_, ok = replicatedPositionFromContext(incoming(clusterRoleHeader, "primary", clusterRoleEpochHeader, "3", clusterReplicationSequenceHeader, "x"))
+	assert.False(t, ok)
+}
@@ -44,6 +44,12 @@ type replicationServiceServer struct {
 	branchControlFilesys filesys.Filesys
 
 	dropDatabase func(*sql.Context, string) error
+
+	// nil when the cluster does not run elections.
+	elector *elector
+	// Answers the heartbeats of standbys looking for the primary when the
+	// cluster does not run elections.
+	roleAndEpoch func() (Role, int)
 }
 
 func (s *replicationServiceServer) UpdateUsersAndGrants(ctx context.Context, req *replicationapi.UpdateUsersAndGrantsRequest) (*replicationapi.UpdateUsersAndGrantsResponse, error) {
@@ -99,3 +105,24 @@ func (s *replicationServiceServer) DropDatabase(ctx context.Context, req *replic
 	}
 	return &replicationapi.DropDatabaseResponse{}, nil
 }
+
+func (s *replicationServiceServer) Heartbeat(ctx context.Context, req *replicationapi.HeartbeatRequest) (*replicationapi.HeartbeatResponse, error) {
+	if s.elector != nil {
+		return s.elector.handleHeartbeat(req), nil
+	}
+	if s.roleAndEpoch == nil {
+		return nil, status.Error(codes.Unimplemented, "unimplemented")
+	}
+	role, epoch := s.roleAndEpoch()
+	return &replicationapi.HeartbeatResponse{
+		Role:  string(role),
+		Epoch: int64(epoch),
+	}, nil
+}
+
+func (s *replicationServiceServer) RequestVote(ctx context.Context, req *replicationapi.RequestVoteRequest) (*replicationapi.RequestVoteResponse, error) {
+	if s.elector == nil {
+		return nil, status.Error(codes.Unimplemented, "unimplemented")
+	}
+	return s.elector.handleRequestVote(req), nil
+}
@@ -38,6 +38,19 @@ type ReplicaStatus struct {
 	// A string describing the last encountered error.  NULL when we are a
 	// standby. NULL when our last replication attempt succeeded.
 	CurrentError *string
+	// When the cluster runs elections, "leader" as a primary, "follower" as
+	// a standby which recently heard from a primary, "candidate" as a
+	// standby which did not, or "halted" in detected_broken_config. NULL
+	// when elections are not enabled.
+	ElectionState *string
+	// The role and epoch the standby remote reported in the last heartbeat
+	// exchanged with it. NULL when elections are not enabled or no heartbeat
+	// succeeded yet.
+	PeerRole  *string
+	PeerEpoch *int
+	// The last time a heartbeat was successfully exchanged with the standby
+	// remote.
+	LastHeartbeat *time.Time
 }
 
 type ClusterStatusProvider interface {
@@ -93,7 +106,7 @@ func replicaStatusesToRows(rss []ReplicaStatus) []sql.Row {
 }
 
 func replicaStatusToRow(rs ReplicaStatus) sql.Row {
-	ret := make(sql.Row, 7)
+	ret := make(sql.Row, 11)
 	ret[0] = rs.Database
 	ret[1] = rs.Remote
 	ret[2] = rs.Role
@@ -107,6 +120,18 @@ func replicaStatusToRow(rs ReplicaStatus) sql.Row {
 	if rs.CurrentError != nil {
 		ret[6] = *rs.CurrentError
 	}
+	if rs.ElectionState != nil {
+		ret[7] = *rs.ElectionState
+	}
+	if rs.PeerRole != nil {
+		ret[8] = *rs.PeerRole
+	}
+	if rs.PeerEpoch != nil {
+		ret[9] = int64(*rs.PeerEpoch)
+	}
+	if rs.LastHeartbeat != nil {
+		ret[10] = *rs.LastHeartbeat
+	}
 	return ret
 }
 
@@ -119,5 +144,9 @@ func (t ClusterStatusTable) Schema() sql.Schema {
 		{Name: "replication_lag_millis", Type: types.Int64, Source: StatusTableName, PrimaryKey: false, Nullable: true},
 		{Name: "last_update", Type: types.Datetime, Source: StatusTableName, PrimaryKey: false, Nullable: true},
 		{Name: "current_error", Type: types.Text, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "election_state", Type: types.Text, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "peer_role", Type: types.Text, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "peer_epoch", Type: types.Int64, Source: StatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "last_heartbeat", Type: types.Datetime, Source: StatusTableName, PrimaryKey: false, Nullable: true},
 	}
 }
@@ -49,6 +49,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/globalstate"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/resolve"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/vectorindex"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/utils/concurrentmap"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -330,8 +331,13 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 			}
 		}
 
+		policyRoot, err := dsess.RowPoliciesRoot(ctx, db)
+		if err != nil {
+			return nil, false, err
+		}
+
 		tableName := tblName[len(doltdb.DoltDiffTablePrefix):]
-		dt, err := dtables.NewDiffTable(ctx, db.Name(), tableName, db.ddb, root, head)
+		dt, err := dtables.NewDiffTable(ctx, db.Name(), tableName, db.ddb, root, policyRoot, head)
 		if err != nil {
 			return nil, false, err
 		}
@@ -343,7 +349,11 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 		if err != nil {
 			return nil, false, err
 		}
-		dt, err := dtables.NewCommitDiffTable(ctx, db.Name(), suffix, db.ddb, root, ws.StagedRoot())
+		policyRoot, err := dsess.RowPoliciesRoot(ctx, db)
+		if err != nil {
+			return nil, false, err
+		}
+		dt, err := dtables.NewCommitDiffTable(ctx, db.Name(), suffix, db.ddb, root, ws.StagedRoot(), policyRoot)
 		if err != nil {
 			return nil, false, err
 		}
@@ -376,6 +386,44 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 			return nil, false, fmt.Errorf("expected Alterable or WritableDoltTable, found %T", baseTable)
 		}
 
+	case strings.HasPrefix(lwrName, doltdb.DoltBlameCellsTablePrefix):
+		if head == nil {
+			var err error
+			head, err = ds.GetHeadCommit(ctx, db.RevisionQualifiedName())
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		policyRoot, err := dsess.RowPoliciesRoot(ctx, db)
+		if err != nil {
+			return nil, false, err
+		}
+
+		baseTableName := tblName[len(doltdb.DoltBlameCellsTablePrefix):]
+		return dtables.NewBlameCellsTable(ctx, baseTableName, db.ddb, head, policyRoot, dtables.BlameCellsOptions{})
+
+	case strings.HasPrefix(lwrName, doltdb.DoltRowLineageTablePrefix):
+		if head == nil {
+			var err error
+			head, err = ds.GetHeadCommit(ctx, db.RevisionQualifiedName())
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		policyRoot, err := dsess.RowPoliciesRoot(ctx, db)
+		if err != nil {
+			return nil, false, err
+		}
+		threshold, err := ctx.GetSessionVariable(ctx, dsess.RowLineageSimilarity)
+		if err != nil {
+			return nil, false, err
+		}
+
+		baseTableName := tblName[len(doltdb.DoltRowLineageTablePrefix):]
+		return dtables.NewRowLineageTable(ctx, baseTableName, db.ddb, head, policyRoot, threshold.(float64))
+
 	case strings.HasPrefix(lwrName, doltdb.DoltConfTablePrefix):
 		suffix := tblName[len(doltdb.DoltConfTablePrefix):]
 		srcTable, ok, err := db.getTableInsensitive(ctx, head, ds, root, suffix, asOf)
@@ -384,7 +432,11 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 		} else if !ok {
 			return nil, false, nil
 		}
-		dt, err := dtables.NewConflictsTable(ctx, suffix, srcTable, root, dtables.RootSetter(db))
+		policyRoot, err := dsess.RowPoliciesRoot(ctx, db)
+		if err != nil {
+			return nil, false, err
+		}
+		dt, err := dtables.NewConflictsTable(ctx, suffix, srcTable, root, policyRoot, dtables.RootSetter(db))
 		if err != nil {
 			return nil, false, err
 		}
@@ -392,7 +444,11 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 
 	case strings.HasPrefix(lwrName, doltdb.DoltConstViolTablePrefix):
 		suffix := tblName[len(doltdb.DoltConstViolTablePrefix):]
-		dt, err := dtables.NewConstraintViolationsTable(ctx, suffix, root, dtables.RootSetter(db))
+		policyRoot, err := dsess.RowPoliciesRoot(ctx, db)
+		if err != nil {
+			return nil, false, err
+		}
+		dt, err := dtables.NewConstraintViolationsTable(ctx, suffix, root, policyRoot, dtables.RootSetter(db))
 		if err != nil {
 			return nil, false, err
 		}
@@ -481,8 +537,16 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 		dt, found = dtables.NewStatusTable(ctx, db.ddb, ws, adapter), true
 	case doltdb.MergeStatusTableName:
 		dt, found = dtables.NewMergeStatusTable(db.RevisionQualifiedName()), true
+	case doltdb.AuditLogTableName:
+		dt, found = dtables.NewAuditLogTable(db.RevisionQualifiedName()), true
+	case doltdb.QueryHistoryTableName:
+		dt, found = dtables.NewQueryHistoryTable(db.RevisionQualifiedName()), true
+	case doltdb.QueryDigestTableName:
+		dt, found = dtables.NewQueryDigestTable(db.RevisionQualifiedName()), true
 	case doltdb.TagsTableName:
 		dt, found = dtables.NewTagsTable(ctx, db.ddb), true
+	case doltdb.BranchPoliciesTableName:
+		dt, found = dtables.NewBranchPoliciesTable(db.AliasedName()), true
 	case dtables.AccessTableName:
 		basCtx := branch_control.GetBranchAwareSession(ctx)
 		if basCtx != nil {
@@ -497,6 +561,13 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 				dt, found = dtables.NewBranchNamespaceControlTable(controller.Namespace), true
 			}
 		}
+	case dtables.ProtectionTableName:
+		basCtx := branch_control.GetBranchAwareSession(ctx)
+		if basCtx != nil {
+			if controller := basCtx.GetController(); controller != nil {
+				dt, found = dtables.NewBranchProtectionTable(controller), true
+			}
+		}
 	case doltdb.IgnoreTableName:
 		backingTable, _, err := db.getTable(ctx, root, doltdb.IgnoreTableName)
 		if err != nil {
@@ -508,6 +579,61 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 			versionableTable := backingTable.(dtables.VersionableTable)
 			dt, found = dtables.NewIgnoreTable(ctx, versionableTable), true
 		}
+	case doltdb.MergeStrategiesTableName:
+		backingTable, _, err := db.getTable(ctx, root, doltdb.MergeStrategiesTableName)
+		if err != nil {
+			return nil, false, err
+		}
+		if backingTable == nil {
+			dt, found = dtables.NewEmptyMergeStrategiesTable(ctx, db.RevisionQualifiedName()), true
+		} else {
+			versionableTable := backingTable.(dtables.VersionableTable)
+			dt, found = dtables.NewMergeStrategiesTable(ctx, db.RevisionQualifiedName(), versionableTable), true
+		}
+	case doltdb.RowPoliciesTableName:
+		backingTable, _, err := db.getTable(ctx, root, doltdb.RowPoliciesTableName)
+		if err != nil {
+			return nil, false, err
+		}
+		if backingTable == nil {
+			dt, found = dtables.NewEmptyRowPoliciesTable(ctx, db.RevisionQualifiedName()), true
+		} else {
+			versionableTable := backingTable.(dtables.VersionableTable)
+			dt, found = dtables.NewRowPoliciesTable(ctx, db.RevisionQualifiedName(), versionableTable), true
+		}
+	case doltdb.TestsTableName:
+		backingTable, _, err := db.getTable(ctx, root, doltdb.TestsTableName)
+		if err != nil {
+			return nil, false, err
+		}
+		if backingTable == nil {
+			dt, found = dtables.NewEmptyTestsTable(ctx, db.RevisionQualifiedName()), true
+		} else {
+			versionableTable := backingTable.(dtables.VersionableTable)
+			dt, found = dtables.NewTestsTable(ctx, db.RevisionQualifiedName(), versionableTable), true
+		}
+	case doltdb.MaterializedViewsTableName:
+		backingTable, _, err := db.getTable(ctx, root, doltdb.MaterializedViewsTableName)
+		if err != nil {
+			return nil, false, err
+		}
+		if backingTable == nil {
+			dt, found = dtables.NewEmptyMaterializedViewsTable(ctx, db.RevisionQualifiedName()), true
+		} else {
+			versionableTable := backingTable.(dtables.VersionableTable)
+			dt, found = dtables.NewMaterializedViewsTable(ctx, db.RevisionQualifiedName(), versionableTable), true
+		}
+	case doltdb.VectorIndexesTableName:
+		backingTable, _, err := db.getTable(ctx, root, doltdb.VectorIndexesTableName)
+		if err != nil {
+			return nil, false, err
+		}
+		if backingTable == nil {
+			dt, found = dtables.NewEmptyVectorIndexesTable(ctx), true
+		} else {
+			versionableTable := backingTable.(dtables.VersionableTable)
+			dt, found = dtables.NewVectorIndexesTable(ctx, versionableTable), true
+		}
 	case doltdb.DocTableName:
 		backingTable, _, err := db.getTable(ctx, root, doltdb.DocTableName)
 		if err != nil {
@@ -875,7 +1001,7 @@ func (db Database) newDoltTable(tableName string, sch schema.Schema, tbl *doltdb
 		return nil, err
 	}
 	var table sql.Table
-	if doltdb.IsReadOnlySystemTable(tableName) {
+	if doltdb.IsReadOnlySystemTable(tableName) || doltdb.IsVectorIndexTable(tableName) {
 		table = readonlyTable
 	} else if doltdb.HasDoltPrefix(tableName) && !doltdb.IsFullTextTable(tableName) {
 		table = &WritableDoltTable{DoltTable: readonlyTable, db: db}
@@ -1062,6 +1188,10 @@ func (db Database) dropTable(ctx *sql.Context, tableName string) error {
 	if err != nil {
 		return err
 	}
+	newRoot, err = vectorindex.DropTable(ctx, newRoot, tblName.Name)
+	if err != nil {
+		return err
+	}
 
 	sch, err := tbl.GetSchema(ctx)
 	if err != nil {
@@ -1508,6 +1638,11 @@ func (db Database) RenameTable(ctx *sql.Context, oldName, newName string) error
 		return err
 	}
 
+	newRoot, err = vectorindex.RenameTable(ctx, newRoot, oldName, newName)
+	if err != nil {
+		return err
+	}
+
 	return db.SetRoot(ctx, newRoot)
 }
 
@@ -1526,6 +1661,9 @@ func (db Database) GetViewDefinition(ctx *sql.Context, viewName string) (sql.Vie
 
 	lwrViewName := strings.ToLower(viewName)
 	switch {
+	case strings.HasPrefix(lwrViewName, doltdb.DoltBlameCellsTablePrefix):
+		// dolt_blame_cells_<table> is a system table, not a blame view
+		return sql.ViewDefinition{}, false, nil
 	case strings.HasPrefix(lwrViewName, doltdb.DoltBlameViewPrefix):
 		tableName := lwrViewName[len(doltdb.DoltBlameViewPrefix):]
 
@@ -2171,11 +2309,18 @@ func (db Database) LoadRebasePlan(ctx *sql.Context) (*rebase.RebasePlan, error)
 			return nil, fmt.Errorf("invalid enum value in rebase plan: %v (%T)", row[1], row[1])
 		}
 
+		// Plans saved before the exec_statement column was added don't have it
+		var execStatement string
+		if len(row) > 4 && row[4] != nil {
+			execStatement = row[4].(string)
+		}
+
 		rebasePlan.Steps = append(rebasePlan.Steps, rebase.RebasePlanStep{
-			RebaseOrder: row[0].(decimal.Decimal),
-			Action:      rebaseAction,
-			CommitHash:  row[2].(string),
-			CommitMsg:   row[3].(string),
+			RebaseOrder:   row[0].(decimal.Decimal),
+			Action:        rebaseAction,
+			CommitHash:    row[2].(string),
+			CommitMsg:     row[3].(string),
+			ExecStatement: execStatement,
 		})
 	}
 
@@ -2210,11 +2355,16 @@ func (db Database) SaveRebasePlan(ctx *sql.Context, plan *rebase.RebasePlan) err
 		if actionEnumValue == -1 {
 			return fmt.Errorf("invalid rebase action: %s", planMember.Action)
 		}
+		var execStatement interface{}
+		if planMember.ExecStatement != "" {
+			execStatement = planMember.ExecStatement
+		}
 		err = inserter.Insert(ctx, sql.Row{
 			planMember.RebaseOrder,
 			uint16(actionEnumValue),
 			planMember.CommitHash,
 			planMember.CommitMsg,
+			execStatement,
 		})
 		if err != nil {
 			return err
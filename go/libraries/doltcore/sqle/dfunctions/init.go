@@ -26,6 +26,8 @@ var DoltFunctions = []sql.Function{
 	sql.Function2{Name: HasAncestorFuncName, Fn: NewHasAncestor},
 	sql.Function1{Name: HashOfTableFuncName, Fn: NewHashOfTable},
 	sql.FunctionN{Name: HashOfDatabaseFuncName, Fn: NewHashOfDatabase},
+	sql.Function2{Name: VecDistanceL2FuncName, Fn: NewVecDistanceL2},
+	sql.Function2{Name: VecDistanceCosineFuncName, Fn: NewVecDistanceCosine},
 }
 
 // DolthubApiFunctions are the DoltFunctions that get exposed to Dolthub Api.
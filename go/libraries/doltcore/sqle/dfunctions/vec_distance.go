@@ -0,0 +1,94 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfunctions
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/vectorindex"
+)
+
+const VecDistanceL2FuncName = "vec_distance_l2"
+const VecDistanceCosineFuncName = "vec_distance_cosine"
+
+// NewVecDistanceL2 creates an expression for the euclidean distance between two vectors. The analyzer can use a vector
+// index to order rows by this distance from a constant vector.
+func NewVecDistanceL2(left, right sql.Expression) sql.Expression {
+	return expression.NewDistance(vectorindex.DistanceL2{}, newVectorArg(left), newVectorArg(right))
+}
+
+// NewVecDistanceCosine creates an expression for the cosine distance between two vectors. The analyzer can use a
+// vector index to order rows by this distance from a constant vector.
+func NewVecDistanceCosine(left, right sql.Expression) sql.Expression {
+	return expression.NewDistance(vectorindex.DistanceCosine{}, newVectorArg(left), newVectorArg(right))
+}
+
+// vectorArg converts the values of an argument of a distance function to vectors. Constant arguments are converted
+// once, to a JSON literal, since the analyzer only uses a vector index when one side of the distance is a literal.
+type vectorArg struct {
+	expression.UnaryExpression
+}
+
+var _ sql.Expression = (*vectorArg)(nil)
+
+func newVectorArg(child sql.Expression) sql.Expression {
+	if lit, ok := child.(*expression.Literal); ok {
+		if vec, err := vectorindex.ToVector(lit.Value()); err == nil {
+			if vec == nil {
+				return expression.NewLiteral(nil, types.Null)
+			}
+			array := make([]interface{}, len(vec))
+			for i, f := range vec {
+				array[i] = f
+			}
+			return expression.NewLiteral(types.JSONDocument{Val: array}, types.JSON)
+		}
+	}
+	return &vectorArg{UnaryExpression: expression.UnaryExpression{Child: child}}
+}
+
+// Eval implements the sql.Expression interface.
+func (v *vectorArg) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	val, err := v.Child.Eval(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	vec, err := vectorindex.ToVector(val)
+	if err != nil || vec == nil {
+		return nil, err
+	}
+	return vec, nil
+}
+
+// String implements the sql.Expression interface. It's the string of the argument, so that the analyzer matches a
+// distance from a column to the vector indexes of the column.
+func (v *vectorArg) String() string {
+	return v.Child.String()
+}
+
+// Type implements the sql.Expression interface.
+func (v *vectorArg) Type() sql.Type {
+	return types.JSON
+}
+
+// WithChildren implements the sql.Expression interface.
+func (v *vectorArg) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(v, len(children), 1)
+	}
+	return newVectorArg(children[0]), nil
+}
@@ -0,0 +1,397 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/bisect"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	bisectReset = "reset"
+	bisectLog   = "log"
+	bisectRun   = "run"
+)
+
+var doltBisectSchema = []*sql.Column{
+	{
+		Name:     "status",
+		Type:     types.Int64,
+		Nullable: false,
+	},
+	{
+		Name:     "commit",
+		Type:     types.LongText,
+		Nullable: true,
+	},
+	{
+		Name:     "message",
+		Type:     types.LongText,
+		Nullable: true,
+	},
+}
+
+// doltBisect is the stored procedure version for the CLI command `dolt bisect`. The bisect state is stored in the
+// .dolt directory of the database, so a bisect can be driven across many calls and sessions. Rather than checking
+// out each commit, the procedure returns the commit to test next, which can be queried with AS OF.
+func doltBisect(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	commit, message, err := doDoltBisect(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitVal interface{}
+	if !commit.IsEmpty() {
+		commitVal = commit.String()
+	}
+	return rowToIter(int64(0), commitVal, message), nil
+}
+
+func doDoltBisect(ctx *sql.Context, args []string) (hash.Hash, string, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return hash.Hash{}, "", sql.ErrNoDatabaseSelected.New()
+	}
+
+	apr, err := cli.CreateBisectArgParser().Parse(args)
+	if err != nil {
+		return hash.Hash{}, "", err
+	}
+	if apr.NArg() == 0 {
+		return hash.Hash{}, "", fmt.Errorf("error: missing bisect subcommand; expected one of %s",
+			strings.Join([]string{bisect.TermStart, bisect.TermGood, bisect.TermBad, bisect.TermSkip, bisectReset, bisectLog, bisectRun}, ", "))
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	dbData, ok := dSess.GetDbData(ctx, dbName)
+	if !ok {
+		return hash.Hash{}, "", fmt.Errorf("could not load database %s", dbName)
+	}
+	fs, err := dSess.Provider().FileSystemForDatabase(dbName)
+	if err != nil {
+		return hash.Hash{}, "", err
+	}
+
+	subcommand, revs := apr.Arg(0), apr.Args[1:]
+	switch subcommand {
+	case bisect.TermStart:
+		return startBisect(ctx, dbData, fs, revs)
+	case bisect.TermGood, bisect.TermBad, bisect.TermSkip:
+		state, err := bisect.LoadState(fs)
+		if err != nil {
+			return hash.Hash{}, "", err
+		}
+		if err = markBisectRevisions(ctx, dbData, state, subcommand, revs); err != nil {
+			return hash.Hash{}, "", err
+		}
+		return nextBisectStep(ctx, dbData, fs, state)
+	case bisectReset:
+		if !bisect.InProgress(fs) {
+			return hash.Hash{}, "", bisect.ErrNoBisectInProgress
+		}
+		if err = bisect.ClearState(fs); err != nil {
+			return hash.Hash{}, "", err
+		}
+		return hash.Hash{}, "bisect reset", nil
+	case bisectLog:
+		state, err := bisect.LoadState(fs)
+		if err != nil {
+			return hash.Hash{}, "", err
+		}
+		return hash.Hash{}, formatBisectLog(state), nil
+	case bisectRun:
+		if len(revs) != 1 {
+			return hash.Hash{}, "", fmt.Errorf("error: bisect run requires exactly one query")
+		}
+		return runBisect(ctx, dbData, fs, revs[0])
+	default:
+		return hash.Hash{}, "", fmt.Errorf("error: unknown bisect subcommand '%s'", subcommand)
+	}
+}
+
+// startBisect starts a new bisect. The first revision given, if any, is marked bad and the rest are marked good.
+func startBisect(ctx *sql.Context, dbData env.DbData, fs filesys.Filesys, revs []string) (hash.Hash, string, error) {
+	if bisect.InProgress(fs) {
+		return hash.Hash{}, "", bisect.ErrBisectInProgress
+	}
+
+	branch, err := currentBranch(ctx)
+	if err != nil {
+		return hash.Hash{}, "", err
+	}
+
+	state := bisect.NewState(branch)
+	if len(revs) > 0 {
+		if err = markBisectRevisions(ctx, dbData, state, bisect.TermBad, revs[:1]); err != nil {
+			return hash.Hash{}, "", err
+		}
+		if err = markBisectRevisions(ctx, dbData, state, bisect.TermGood, revs[1:]); err != nil {
+			return hash.Hash{}, "", err
+		}
+	}
+
+	return nextBisectStep(ctx, dbData, fs, state)
+}
+
+// markBisectRevisions marks each of |revs| with |term| in |state|. If no revisions are given, the commit currently
+// being tested is marked, or HEAD if there is none.
+func markBisectRevisions(ctx *sql.Context, dbData env.DbData, state *bisect.State, term string, revs []string) error {
+	if len(revs) == 0 {
+		if state.Current != "" {
+			revs = []string{state.Current}
+		} else {
+			revs = []string{"HEAD"}
+		}
+	}
+
+	if term == bisect.TermBad && len(revs) > 1 {
+		return fmt.Errorf("error: only one commit can be marked bad at a time")
+	}
+
+	for _, rev := range revs {
+		h, err := resolveBisectRevision(ctx, dbData, rev)
+		if err != nil {
+			return err
+		}
+
+		switch term {
+		case bisect.TermGood:
+			state.MarkGood(h)
+		case bisect.TermBad:
+			state.MarkBad(h)
+		case bisect.TermSkip:
+			state.MarkSkip(h)
+		}
+	}
+
+	return nil
+}
+
+// nextBisectStep computes the next commit to test for |state|, saves the state, and returns the next commit along with
+// a message describing it.
+func nextBisectStep(ctx *sql.Context, dbData env.DbData, fs filesys.Filesys, state *bisect.State) (hash.Hash, string, error) {
+	res, err := bisect.Next(ctx, dbData.Ddb, state)
+	if err != nil {
+		return hash.Hash{}, "", err
+	}
+
+	state.Current = ""
+	if !res.Next.IsEmpty() {
+		state.Current = res.Next.String()
+	}
+	if err = state.Save(fs); err != nil {
+		return hash.Hash{}, "", err
+	}
+
+	return describeBisectResult(ctx, dbData.Ddb, state, res)
+}
+
+func describeBisectResult(ctx *sql.Context, ddb *doltdb.DoltDB, state *bisect.State, res bisect.Result) (hash.Hash, string, error) {
+	switch {
+	case res.Waiting():
+		switch {
+		case state.Bad == "" && len(state.Good) == 0:
+			return hash.Hash{}, "status: waiting for both good and bad commits", nil
+		case state.Bad == "":
+			return hash.Hash{}, fmt.Sprintf("status: waiting for bad commit, %d good commit(s) known", len(state.Good)), nil
+		default:
+			return hash.Hash{}, "status: waiting for good commit(s), bad commit known", nil
+		}
+
+	case !res.FirstBad.IsEmpty():
+		summary, err := describeBisectCommit(ctx, ddb, res.FirstBad)
+		if err != nil {
+			return hash.Hash{}, "", err
+		}
+		return res.FirstBad, fmt.Sprintf("%s is the first bad commit\n%s", res.FirstBad.String(), summary), nil
+
+	case len(res.Suspects) > 0:
+		var sb strings.Builder
+		sb.WriteString("There are only 'skip'ped commits left to test.\nThe first bad commit could be any of:\n")
+		for _, h := range res.Suspects {
+			sb.WriteString(h.String())
+			sb.WriteString("\n")
+		}
+		sb.WriteString("We cannot bisect more!")
+		return hash.Hash{}, sb.String(), nil
+
+	default:
+		summary, err := describeBisectCommit(ctx, ddb, res.Next)
+		if err != nil {
+			return hash.Hash{}, "", err
+		}
+		if res.IsMergeBase {
+			return res.Next, fmt.Sprintf("Bisecting: a merge base must be tested\n%s", summary), nil
+		}
+		return res.Next, fmt.Sprintf("Bisecting: %d revision(s) left to test after this (roughly %d step(s))\n%s",
+			res.Remaining, res.Steps, summary), nil
+	}
+}
+
+func describeBisectCommit(ctx *sql.Context, ddb *doltdb.DoltDB, h hash.Hash) (string, error) {
+	cm, err := doltdb.HashToCommit(ctx, ddb.ValueReadWriter(), ddb.NodeStore(), h)
+	if err != nil {
+		return "", err
+	}
+	meta, err := cm.GetCommitMeta(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("[%s] %s", h.String(), meta.Description), nil
+}
+
+func formatBisectLog(state *bisect.State) string {
+	var sb strings.Builder
+	for i, entry := range state.Log {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("dolt bisect ")
+		sb.WriteString(entry.Term)
+		if entry.Commit != "" {
+			sb.WriteString(" ")
+			sb.WriteString(entry.Commit)
+		}
+	}
+	return sb.String()
+}
+
+// runBisect tests commits automatically until the first bad commit is found. |query| is evaluated AS OF each commit
+// under test, and must return a single boolean value: true marks the commit good, false marks it bad, and NULL skips
+// it.
+func runBisect(ctx *sql.Context, dbData env.DbData, fs filesys.Filesys, query string) (hash.Hash, string, error) {
+	state, err := bisect.LoadState(fs)
+	if err != nil {
+		return hash.Hash{}, "", err
+	}
+
+	for {
+		res, err := bisect.Next(ctx, dbData.Ddb, state)
+		if err != nil {
+			return hash.Hash{}, "", err
+		}
+		if res.Waiting() {
+			return hash.Hash{}, "", fmt.Errorf("error: bisect run requires both a good and a bad commit to be marked")
+		}
+		if res.Done() {
+			state.Current = ""
+			if err = state.Save(fs); err != nil {
+				return hash.Hash{}, "", err
+			}
+			return describeBisectResult(ctx, dbData.Ddb, state, res)
+		}
+
+		good, err := evalBisectQuery(ctx, query, res.Next)
+		if err != nil {
+			return hash.Hash{}, "", err
+		}
+
+		switch {
+		case good == nil:
+			state.MarkSkip(res.Next)
+		case *good:
+			state.MarkGood(res.Next)
+		default:
+			state.MarkBad(res.Next)
+		}
+
+		if err = state.Save(fs); err != nil {
+			return hash.Hash{}, "", err
+		}
+	}
+}
+
+// evalBisectQuery evaluates |query| with every table it references read AS OF the commit |h|. It returns nil if the
+// query returns NULL, which skips the commit.
+func evalBisectQuery(ctx *sql.Context, query string, h hash.Hash) (*bool, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := stmt.(sqlparser.SelectStatement); !ok {
+		return nil, fmt.Errorf("bisect query must be a SELECT statement")
+	}
+
+	asOf := &sqlparser.AsOf{Time: sqlparser.NewStrVal([]byte(h.String()))}
+	err = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if tableExpr, ok := node.(*sqlparser.AliasedTableExpr); ok {
+			if _, isTable := tableExpr.Expr.(sqlparser.TableName); isTable && tableExpr.AsOf == nil {
+				tableExpr.AsOf = asOf
+			}
+		}
+		return true, nil
+	}, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	// The query runs on the engine of the session, so that it's analyzed like the client's own queries
+	engine := dsess.DSessFromSess(ctx.Session).QueryRunner()
+	if engine == nil {
+		return nil, dsess.ErrNoQueryRunner
+	}
+	_, iter, _, err := engine.Query(ctx, sqlparser.String(stmt))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sql.RowIterToRows(ctx, iter)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 {
+		return nil, fmt.Errorf("bisect query must return exactly one row with one column")
+	}
+	if rows[0][0] == nil {
+		return nil, nil
+	}
+
+	good, err := sql.ConvertToBool(ctx, rows[0][0])
+	if err != nil {
+		return nil, err
+	}
+	return &good, nil
+}
+
+func resolveBisectRevision(ctx *sql.Context, dbData env.DbData, rev string) (hash.Hash, error) {
+	cs, err := doltdb.NewCommitSpec(rev)
+	if err != nil {
+		return hash.Hash{}, err
+	}
+	headRef, err := dbData.Rsr.CWBHeadRef()
+	if err != nil {
+		return hash.Hash{}, err
+	}
+	optCmt, err := dbData.Ddb.Resolve(ctx, cs, headRef)
+	if err != nil {
+		return hash.Hash{}, err
+	}
+	cm, ok := optCmt.ToCommit()
+	if !ok {
+		return hash.Hash{}, doltdb.ErrGhostCommitEncountered
+	}
+	return cm.HashOf()
+}
@@ -122,6 +122,9 @@ func renameBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParseRe
 	if err := branch_control.CanDeleteBranch(ctx, oldBranchName); err != nil {
 		return err
 	}
+	if err := dsess.ValidateBranchDelete(ctx, dbName, oldBranchName); err != nil {
+		return err
+	}
 	if err := branch_control.CanCreateBranch(ctx, newBranchName); err != nil {
 		return err
 	}
@@ -149,6 +152,10 @@ func renameBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParseRe
 		// If force is enabled, we can overwrite the destination branch, so we require a permission check here, even if the
 		// destination branch doesn't exist. An unauthorized user could simply rerun the command without the force flag.
 		return err
+	} else if err := dsess.ValidateBranchDelete(ctx, dbName, newBranchName); err != nil {
+		return err
+	} else if err := validateBranchOverwrite(ctx, dbData, oldBranchName, newBranchName); err != nil {
+		return err
 	}
 
 	headRef, err := dbData.Rsr.CWBHeadRef()
@@ -223,6 +230,9 @@ func deleteBranches(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParse
 		if err = branch_control.CanDeleteBranch(ctx, branchName); err != nil {
 			return err
 		}
+		if err = dsess.ValidateBranchDelete(ctx, dbName, branchName); err != nil {
+			return err
+		}
 	}
 
 	dSess := dsess.DSessFromSess(ctx.Session)
@@ -406,6 +416,12 @@ func createNewBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgPars
 		return err
 	}
 
+	if apr.Contains(cli.ForceFlag) {
+		if err = validateBranchOverwrite(ctx, dbData, startPt, branchName); err != nil {
+			return err
+		}
+	}
+
 	err = actions.CreateBranchWithStartPt(ctx, dbData, branchName, startPt, apr.Contains(cli.ForceFlag), rsc)
 	if err != nil {
 		return err
@@ -441,6 +457,48 @@ func copyBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParseResu
 	return copyABranch(ctx, dbData, srcBr, destBr, force, rsc)
 }
 
+// validateBranchOverwrite returns an error if the rules of dolt_branch_protection forbid overwriting the branch
+// |destBr|, if it exists, with the commit |startPt| resolves to, or if doing so changes its dolt_row_policies and the
+// user may not change row policies. Errors resolving |startPt| are left to the caller.
+func validateBranchOverwrite(ctx *sql.Context, dbData env.DbData, startPt string, destBr string) error {
+	destRef := ref.NewBranchRef(destBr)
+	if ok, err := dbData.Ddb.HasRef(ctx, destRef); err != nil || !ok {
+		return err
+	}
+	headRef, err := dbData.Rsr.CWBHeadRef()
+	if err != nil {
+		return err
+	}
+	cs, err := doltdb.NewCommitSpec(startPt)
+	if err != nil {
+		return nil
+	}
+	optCmt, err := dbData.Ddb.Resolve(ctx, cs, headRef)
+	if err != nil {
+		return nil
+	}
+	newHead, ok := optCmt.ToCommit()
+	if !ok {
+		return nil
+	}
+	oldHead, err := dbData.Ddb.ResolveCommitRef(ctx, destRef)
+	if err != nil {
+		return err
+	}
+	oldRoot, err := oldHead.GetRootValue(ctx)
+	if err != nil {
+		return err
+	}
+	newRoot, err := newHead.GetRootValue(ctx)
+	if err != nil {
+		return err
+	}
+	if err = dsess.ValidateRowPoliciesChange(ctx, oldRoot, newRoot); err != nil {
+		return err
+	}
+	return dsess.ValidateBranchUpdate(ctx, dbData.Ddb, ctx.GetCurrentDatabase(), destBr, oldHead, newHead)
+}
+
 func copyABranch(ctx *sql.Context, dbData env.DbData, srcBr string, destBr string, force bool, rsc *doltdb.ReplicationStatusController) error {
 	if err := branch_control.CanCreateBranch(ctx, destBr); err != nil {
 		return err
@@ -451,6 +509,9 @@ func copyABranch(ctx *sql.Context, dbData env.DbData, srcBr string, destBr strin
 		if err := branch_control.CanDeleteBranch(ctx, destBr); err != nil {
 			return err
 		}
+		if err := validateBranchOverwrite(ctx, dbData, srcBr, destBr); err != nil {
+			return err
+		}
 	}
 	err := actions.CopyBranchOnDB(ctx, dbData.Ddb, srcBr, destBr, force, rsc)
 	if err != nil {
@@ -0,0 +1,150 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/branchpolicy"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+var doltBranchPruneSchema = []*sql.Column{
+	{
+		Name:     "ref_type",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "name",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "hash",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "policy",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+}
+
+// doltBranchPrune is the stored procedure that enforces the retention policies of the dolt_branch_policies table,
+// deleting the branches and tags they select, or only listing them with --dry-run.
+func doltBranchPrune(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return nil, fmt.Errorf("Empty database name.")
+	}
+
+	apr, err := cli.CreateBranchPruneArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned, err := PruneBranches(ctx, dbName, apr.Contains(cli.DryRunFlag))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sql.Row, len(pruned))
+	for i, c := range pruned {
+		rows[i] = sql.Row{c.RefType, c.Name, c.Hash.String(), c.Policy.Name}
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// PruneBranches deletes the branches and tags of the database |dbName| selected by its retention policies, and returns
+// them. If |dryRun| is true, they are only returned. A branch is skipped if it is checked out by any session or on the
+// command line, or if dolt_branch_control doesn't allow either the user of |ctx| or the creator of the policy to
+// delete it. Each ref is deleted in its own update of the database, so that it remains in the reflog with the commit
+// it pointed to when it was deleted.
+func PruneBranches(ctx *sql.Context, dbName string, dryRun bool) ([]branchpolicy.Candidate, error) {
+	dSess := dsess.DSessFromSess(ctx.Session)
+	dbData, ok := dSess.GetDbData(ctx, dbName)
+	if !ok {
+		return nil, fmt.Errorf("Could not load database %s", dbName)
+	}
+	fs, err := dSess.Provider().FileSystemForDatabase(dbName)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := branchpolicy.Load(fs)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+
+	candidates, err := branchpolicy.Evaluate(ctx, dbData.Ddb, policies, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	baseName, _ := dsess.SplitRevisionDbName(dbName)
+	var headOnCLI string
+	if repoState, err := env.LoadRepoState(fs); err == nil {
+		headOnCLI = repoState.Head.Ref.GetPath()
+	}
+	sessionHead, err := dSess.CWBHeadRef(ctx, baseName)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsc doltdb.ReplicationStatusController
+	var pruned []branchpolicy.Candidate
+	for _, c := range candidates {
+		if c.RefType == branchpolicy.RefTypeBranch {
+			if c.Name == headOnCLI || ref.Equals(sessionHead, ref.NewBranchRef(c.Name)) {
+				continue
+			}
+			if validateBranchNotActiveInAnySession(ctx, c.Name) != nil {
+				continue
+			}
+			if branch_control.CanDeleteBranch(ctx, c.Name) != nil ||
+				branch_control.CanUserDeleteBranch(ctx, baseName, c.Name, c.Policy.User, c.Policy.Host) != nil ||
+				dsess.ValidateBranchDelete(ctx, baseName, c.Name) != nil {
+				continue
+			}
+			if !dryRun {
+				err = actions.DeleteBranch(ctx, dbData, c.Name, actions.DeleteOptions{Force: true}, dSess.Provider(), &rsc)
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else if !dryRun {
+			if err = actions.DeleteTagsOnDB(ctx, dbData.Ddb, c.Name); err != nil {
+				return nil, err
+			}
+		}
+		pruned = append(pruned, c)
+	}
+
+	if dryRun || len(pruned) == 0 {
+		return pruned, nil
+	}
+	return pruned, commitTransaction(ctx, dSess, &rsc)
+}
@@ -218,6 +218,11 @@ func doDoltCommit(ctx *sql.Context, args []string) (string, bool, error) {
 		return "", false, err
 	}
 
+	// The commit has been made, so failing to refresh the views that refresh on commit only warrants a warning
+	if err = RefreshMaterializedViewsOnCommit(ctx, dbName); err != nil {
+		ctx.Warn(DoltMergeWarningCode, "unable to refresh materialized views: %s", err.Error())
+	}
+
 	return h.String(), false, nil
 }
 
@@ -28,6 +28,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
@@ -401,6 +402,12 @@ func ResolveSchemaConflicts(ctx *sql.Context, ddb *doltdb.DoltDB, ws *doltdb.Wor
 }
 
 func ResolveDataConflicts(ctx *sql.Context, dSess *dsess.DoltSession, root doltdb.RootValue, dbName string, ours bool, tblNames []doltdb.TableName) error {
+	preMergeWorking, err := preMergeWorkingRoot(ctx, dbName)
+	if err != nil {
+		return err
+	}
+
+	resolutions := rerere.NewResolutions()
 	for _, tblName := range tblNames {
 		tbl, ok, err := root.GetTable(ctx, tblName)
 		if err != nil {
@@ -450,6 +457,14 @@ func ResolveDataConflicts(ctx *sql.Context, dSess *dsess.DoltSession, root doltd
 			}
 		}
 
+		// The resolved rows are recorded so that the same conflicts are resolved the same way in later merges
+		if tbl.Format() == types.Format_DOLT && preMergeWorking != nil && !schema.IsKeyless(sch) {
+			err = recordTableConflictResolutions(ctx, resolutions, tbl, tblName, preMergeWorking)
+			if err != nil {
+				return err
+			}
+		}
+
 		newRoot, err := clearTableAndUpdateRoot(ctx, root, tbl, tblName)
 		if err != nil {
 			return err
@@ -462,7 +477,11 @@ func ResolveDataConflicts(ctx *sql.Context, dSess *dsess.DoltSession, root doltd
 
 		root = newRoot
 	}
-	return dSess.SetWorkingRoot(ctx, dbName, root)
+
+	if err = dSess.SetWorkingRoot(ctx, dbName, root); err != nil {
+		return err
+	}
+	return saveConflictResolutions(ctx, dbName, resolutions)
 }
 
 func DoDoltConflictsResolve(ctx *sql.Context, args []string) (int, error) {
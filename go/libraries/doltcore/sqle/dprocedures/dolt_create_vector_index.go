@@ -0,0 +1,64 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/vectorindex"
+)
+
+// doltCreateVectorIndex is the stored procedure backing CREATE VECTOR INDEX. It takes the name of a table, the name
+// of the index and the name of the indexed column, declares the index in dolt_vector_indexes and builds it from the
+// rows of the table.
+func doltCreateVectorIndex(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	res, err := doDoltCreateVectorIndex(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return rowToIter(int64(res)), nil
+}
+
+func doDoltCreateVectorIndex(ctx *sql.Context, args []string) (int, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return 1, sql.ErrNoDatabaseSelected.New()
+	}
+	if err := branch_control.CheckAccess(ctx, branch_control.Permissions_Write); err != nil {
+		return 1, err
+	}
+	if len(args) != 3 {
+		return 1, fmt.Errorf("error: dolt_create_vector_index takes a table name, an index name and a column name")
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return 1, fmt.Errorf("Could not load database %s", dbName)
+	}
+	def := vectorindex.Definition{Table: args[0], Name: args[1], Column: args[2]}
+	newRoot, err := vectorindex.Create(ctx, roots.Working, def)
+	if err != nil {
+		return 1, err
+	}
+	if err = dSess.SetWorkingRoot(ctx, dbName, newRoot); err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
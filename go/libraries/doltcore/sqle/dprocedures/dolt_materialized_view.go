@@ -0,0 +1,473 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	goerrors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	matViewCreate  = "create"
+	matViewRefresh = "refresh"
+	matViewDrop    = "drop"
+
+	matViewRefreshIncremental = "incremental"
+	matViewRefreshFull        = "full"
+	matViewRefreshUpToDate    = "up to date"
+)
+
+var ErrMaterializedViewExists = goerrors.NewKind("materialized view %s already exists")
+var ErrMaterializedViewNotFound = goerrors.NewKind("materialized view %s does not exist")
+
+var doltMaterializedViewSchema = []*sql.Column{
+	{
+		Name:     "name",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "refresh",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "source_commit",
+		Type:     types.LongText,
+		Nullable: true,
+	},
+}
+
+// materializedView is a row of the dolt_materialized_views system table.
+type materializedView struct {
+	name            string
+	query           string
+	sourceCommit    string
+	refreshOnCommit bool
+}
+
+// doltMaterializedView is the stored procedure backing CREATE, REFRESH and DROP MATERIALIZED VIEW. A materialized
+// view is a table holding the result of its query, and a row of the dolt_materialized_views system table recording
+// the query and the commit whose data the table reflects. Refreshing a view whose query is a filter and projection
+// of a single table, or a group-by of a single table with COUNT and SUM aggregates, applies the diff between that
+// commit and the working set to the table. Any other view is rebuilt from scratch. It returns a row for each view
+// that was created or refreshed, with the kind of refresh that was done.
+func doltMaterializedView(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	rows, err := doDoltMaterializedView(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func doDoltMaterializedView(ctx *sql.Context, args []string) ([]sql.Row, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return nil, sql.ErrNoDatabaseSelected.New()
+	}
+	if err := branch_control.CheckAccess(ctx, branch_control.Permissions_Write); err != nil {
+		return nil, err
+	}
+
+	apr, err := cli.CreateMaterializedViewArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if apr.NArg() == 0 {
+		return nil, fmt.Errorf("error: expected one of %s, %s or %s", matViewCreate, matViewRefresh, matViewDrop)
+	}
+
+	subcommand := strings.ToLower(apr.Arg(0))
+	if apr.Contains(cli.RefreshOnCommitFlag) && subcommand != matViewCreate {
+		return nil, fmt.Errorf("error: --%s can only be used with %s", cli.RefreshOnCommitFlag, matViewCreate)
+	}
+	if apr.Contains(cli.FullFlag) && subcommand != matViewRefresh {
+		return nil, fmt.Errorf("error: --%s can only be used with %s", cli.FullFlag, matViewRefresh)
+	}
+	if apr.Contains(cli.IfExistsFlag) && subcommand != matViewDrop {
+		return nil, fmt.Errorf("error: --%s can only be used with %s", cli.IfExistsFlag, matViewDrop)
+	}
+
+	switch subcommand {
+	case matViewCreate:
+		if apr.NArg() != 3 {
+			return nil, fmt.Errorf("error: %s takes a view name and a query", matViewCreate)
+		}
+		row, err := createMaterializedView(ctx, dbName, apr.Arg(1), apr.Arg(2), apr.Contains(cli.RefreshOnCommitFlag))
+		if err != nil {
+			return nil, err
+		}
+		return []sql.Row{row}, nil
+	case matViewRefresh:
+		return refreshMaterializedViews(ctx, dbName, apr.Args[1:], apr.Contains(cli.FullFlag))
+	case matViewDrop:
+		if apr.NArg() != 2 {
+			return nil, fmt.Errorf("error: %s takes a view name", matViewDrop)
+		}
+		return nil, dropMaterializedView(ctx, apr.Arg(1), apr.Contains(cli.IfExistsFlag))
+	default:
+		return nil, fmt.Errorf("error: unknown materialized view subcommand '%s'; expected one of %s, %s or %s",
+			subcommand, matViewCreate, matViewRefresh, matViewDrop)
+	}
+}
+
+// RefreshMaterializedViewsOnCommit refreshes the materialized views of the database |dbName| that were created to
+// refresh on commit. It's called once a commit has been made, and leaves the refreshed views in the working set.
+func RefreshMaterializedViewsOnCommit(ctx *sql.Context, dbName string) error {
+	roots, ok := dsess.DSessFromSess(ctx.Session).GetRoots(ctx, dbName)
+	if !ok {
+		return fmt.Errorf("Could not load database %s", dbName)
+	}
+	if ok, err := roots.Working.HasTable(ctx, doltdb.TableName{Name: doltdb.MaterializedViewsTableName}); err != nil || !ok {
+		return err
+	}
+
+	views, err := loadMaterializedViews(ctx)
+	if err != nil {
+		return err
+	}
+	for _, view := range views {
+		if !view.refreshOnCommit {
+			continue
+		}
+		if _, err = refreshMaterializedView(ctx, dbName, view, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createMaterializedView(ctx *sql.Context, dbName, name, query string, refreshOnCommit bool) (sql.Row, error) {
+	views, err := loadMaterializedViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if findMaterializedView(views, name) >= 0 {
+		return nil, ErrMaterializedViewExists.New(name)
+	}
+
+	roots, ok := dsess.DSessFromSess(ctx.Session).GetRoots(ctx, dbName)
+	if !ok {
+		return nil, fmt.Errorf("Could not load database %s", dbName)
+	}
+	if ok, err := roots.Working.HasTable(ctx, doltdb.TableName{Name: name}); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, sql.ErrTableAlreadyExists.New(name)
+	}
+	if _, err = parseMaterializedViewQuery(query); err != nil {
+		return nil, err
+	}
+
+	view := materializedView{name: name, query: query, refreshOnCommit: refreshOnCommit}
+	if _, err = runMaterializedViewQuery(ctx, fmt.Sprintf("INSERT INTO %s (name, view_query, refresh_on_commit) VALUES (%s, %s, %t)",
+		doltdb.MaterializedViewsTableName, quoteString(name), quoteString(query), refreshOnCommit)); err != nil {
+		return nil, err
+	}
+	return refreshMaterializedView(ctx, dbName, view, true)
+}
+
+func refreshMaterializedViews(ctx *sql.Context, dbName string, names []string, full bool) ([]sql.Row, error) {
+	views, err := loadMaterializedViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := views
+	if len(names) > 0 {
+		selected = make([]materializedView, 0, len(names))
+		for _, name := range names {
+			i := findMaterializedView(views, name)
+			if i < 0 {
+				return nil, ErrMaterializedViewNotFound.New(name)
+			}
+			selected = append(selected, views[i])
+		}
+	}
+
+	rows := make([]sql.Row, 0, len(selected))
+	for _, view := range selected {
+		row, err := refreshMaterializedView(ctx, dbName, view, full)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func dropMaterializedView(ctx *sql.Context, name string, ifExists bool) error {
+	views, err := loadMaterializedViews(ctx)
+	if err != nil {
+		return err
+	}
+	i := findMaterializedView(views, name)
+	if i < 0 {
+		if ifExists {
+			return nil
+		}
+		return ErrMaterializedViewNotFound.New(name)
+	}
+
+	if _, err = runMaterializedViewQuery(ctx, "DROP TABLE IF EXISTS "+sqlfmt.QuoteIdentifier(views[i].name)); err != nil {
+		return err
+	}
+	_, err = runMaterializedViewQuery(ctx, fmt.Sprintf("DELETE FROM %s WHERE name = %s",
+		doltdb.MaterializedViewsTableName, quoteString(views[i].name)))
+	return err
+}
+
+// refreshMaterializedView brings the table of |view| up to date with the working set, and records the commit it
+// reflects. The commit is HEAD if the tables the view reads are unchanged in the working set, and is NULL otherwise,
+// in which case the next refresh of the view is a full rebuild.
+func refreshMaterializedView(ctx *sql.Context, dbName string, view materializedView, full bool) (sql.Row, error) {
+	sess := dsess.DSessFromSess(ctx.Session)
+	roots, ok := sess.GetRoots(ctx, dbName)
+	if !ok {
+		return nil, fmt.Errorf("Could not load database %s", dbName)
+	}
+	plan, err := planMaterializedView(ctx, roots.Working, view.query)
+	if err != nil {
+		return nil, err
+	}
+
+	hasTable, err := roots.Working.HasTable(ctx, doltdb.TableName{Name: view.name})
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := matViewRefreshFull
+	if !full && hasTable && plan.sources != nil && view.sourceCommit != "" {
+		baseRoot, err := resolveMaterializedViewCommit(ctx, dbName, view.sourceCommit)
+		if err != nil {
+			return nil, err
+		}
+		if baseRoot != nil {
+			if upToDate, err := tablesAreEqual(ctx, baseRoot, roots.Working, plan.sources); err != nil {
+				return nil, err
+			} else if upToDate {
+				refresh = matViewRefreshUpToDate
+			} else if plan.kind != matViewFullRebuild {
+				incremental, err := sourceSchemaIsUnchanged(ctx, baseRoot, roots.Working, plan.sources[0])
+				if err != nil {
+					return nil, err
+				} else if incremental {
+					refresh = matViewRefreshIncremental
+				}
+			}
+		}
+	}
+
+	var sourceCommit interface{}
+	if refresh == matViewRefreshUpToDate {
+		sourceCommit = view.sourceCommit
+		return sql.Row{view.name, refresh, sourceCommit}, nil
+	}
+
+	var queries []string
+	if refresh == matViewRefreshIncremental {
+		mvTable, _, err := roots.Working.GetTable(ctx, doltdb.TableName{Name: view.name})
+		if err != nil {
+			return nil, err
+		}
+		mvSch, err := mvTable.GetSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if queries, err = plan.incrementalQueries(view.name, view.sourceCommit, mvSch.GetAllCols().GetColumnNames()); err != nil {
+			return nil, err
+		}
+	} else {
+		queries = plan.rebuildQueries(view.name)
+	}
+	for _, query := range queries {
+		if _, err = runMaterializedViewQuery(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	// A view whose tables are unchanged in the working set reflects the HEAD commit
+	roots, _ = sess.GetRoots(ctx, dbName)
+	head, err := sess.GetHeadCommit(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	headRoot, err := head.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if plan.sources != nil {
+		if clean, err := tablesAreEqual(ctx, headRoot, roots.Working, plan.sources); err != nil {
+			return nil, err
+		} else if clean {
+			h, err := head.HashOf()
+			if err != nil {
+				return nil, err
+			}
+			sourceCommit = h.String()
+		}
+	}
+
+	commitVal := "NULL"
+	if sourceCommit != nil {
+		commitVal = quoteString(sourceCommit.(string))
+	}
+	if _, err = runMaterializedViewQuery(ctx, fmt.Sprintf("UPDATE %s SET source_commit = %s WHERE name = %s",
+		doltdb.MaterializedViewsTableName, commitVal, quoteString(view.name))); err != nil {
+		return nil, err
+	}
+	return sql.Row{view.name, refresh, sourceCommit}, nil
+}
+
+// resolveMaterializedViewCommit returns the root of the commit a materialized view reflects, or nil if the commit
+// can't be found, like when it was garbage collected or belongs to a branch that was never fetched.
+func resolveMaterializedViewCommit(ctx *sql.Context, dbName, commit string) (doltdb.RootValue, error) {
+	h, ok := hash.MaybeParse(commit)
+	if !ok {
+		return nil, nil
+	}
+	ddb, ok := dsess.DSessFromSess(ctx.Session).GetDoltDB(ctx, dbName)
+	if !ok {
+		return nil, sql.ErrDatabaseNotFound.New(dbName)
+	}
+	optCmt, err := ddb.ReadCommit(ctx, h)
+	if err != nil {
+		return nil, nil
+	}
+	cm, ok := optCmt.ToCommit()
+	if !ok {
+		return nil, nil
+	}
+	return cm.GetRootValue(ctx)
+}
+
+// tablesAreEqual returns whether each of the tables named |names| has the same contents in both roots.
+func tablesAreEqual(ctx *sql.Context, left, right doltdb.RootValue, names []string) (bool, error) {
+	for _, name := range names {
+		l, lok, err := left.GetTable(ctx, doltdb.TableName{Name: name})
+		if err != nil {
+			return false, err
+		}
+		r, rok, err := right.GetTable(ctx, doltdb.TableName{Name: name})
+		if err != nil {
+			return false, err
+		}
+		if !lok || !rok {
+			if lok != rok {
+				return false, nil
+			}
+			continue
+		}
+		lh, err := l.HashOf()
+		if err != nil {
+			return false, err
+		}
+		rh, err := r.HashOf()
+		if err != nil {
+			return false, err
+		}
+		if lh != rh {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// sourceSchemaIsUnchanged returns whether the table |source| has the same schema in both roots, which is required to
+// apply its diff between them to the table of a materialized view.
+func sourceSchemaIsUnchanged(ctx *sql.Context, baseRoot, workingRoot doltdb.RootValue, source string) (bool, error) {
+	baseTable, ok, err := baseRoot.GetTable(ctx, doltdb.TableName{Name: source})
+	if err != nil || !ok {
+		return false, err
+	}
+	workingTable, ok, err := workingRoot.GetTable(ctx, doltdb.TableName{Name: source})
+	if err != nil || !ok {
+		return false, err
+	}
+	baseSch, err := baseTable.GetSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+	workingSch, err := workingTable.GetSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+	return schema.SchemasAreEqual(baseSch, workingSch), nil
+}
+
+// loadMaterializedViews returns the rows of the dolt_materialized_views table of the current database.
+func loadMaterializedViews(ctx *sql.Context) ([]materializedView, error) {
+	rows, err := runMaterializedViewQuery(ctx, fmt.Sprintf("SELECT name, view_query, source_commit, refresh_on_commit FROM %s ORDER BY name",
+		doltdb.MaterializedViewsTableName))
+	if err != nil {
+		return nil, err
+	}
+	views := make([]materializedView, len(rows))
+	for i, row := range rows {
+		views[i].name = row[0].(string)
+		views[i].query = row[1].(string)
+		if row[2] != nil {
+			views[i].sourceCommit = row[2].(string)
+		}
+		if row[3] != nil {
+			refreshOnCommit, _, err := types.Boolean.Convert(row[3])
+			if err != nil {
+				return nil, err
+			}
+			views[i].refreshOnCommit = refreshOnCommit.(int8) != 0
+		}
+	}
+	return views, nil
+}
+
+func findMaterializedView(views []materializedView, name string) int {
+	for i, view := range views {
+		if strings.EqualFold(view.name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// runMaterializedViewQuery runs |query| on the engine of the session of |ctx| and returns its rows.
+func runMaterializedViewQuery(ctx *sql.Context, query string) ([]sql.Row, error) {
+	engine := dsess.DSessFromSess(ctx.Session).QueryRunner()
+	if engine == nil {
+		return nil, dsess.ErrNoQueryRunner
+	}
+	_, iter, _, err := engine.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowIterToRows(ctx, iter)
+}
+
+// quoteString returns |s| as a SQL string literal.
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `'` + strings.ReplaceAll(s, `'`, `\'`) + `'`
+}
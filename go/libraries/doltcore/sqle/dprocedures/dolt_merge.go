@@ -319,7 +319,12 @@ func executeMerge(
 	opts editor.Options,
 	workingDiffs map[string]hash.Hash,
 ) (*doltdb.WorkingSet, error) {
-	result, err := merge.MergeCommits(ctx, head, cm, opts)
+	resolutions, err := loadConflictResolutions(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := merge.MergeCommits(ctx, head, cm, opts, resolutions)
 	if err != nil {
 		switch err {
 		case doltdb.ErrUpToDate:
@@ -353,6 +358,10 @@ func executeFFMerge(ctx *sql.Context, dbName string, squash bool, ws *doltdb.Wor
 		if err != nil {
 			return nil, err
 		}
+		err = validateHeadUpdate(ctx, dbData, dbName, cm2)
+		if err != nil {
+			return ws, err
+		}
 		err = dbData.Ddb.FastForward(ctx, headRef, cm2)
 		if err != nil {
 			return ws, err
@@ -53,7 +53,10 @@ var RebaseActionEnumType = types.MustCreateEnumType([]string{
 	rebase.RebaseActionPick,
 	rebase.RebaseActionReword,
 	rebase.RebaseActionSquash,
-	rebase.RebaseActionFixup}, sql.Collation_Default)
+	rebase.RebaseActionFixup,
+	rebase.RebaseActionEdit,
+	rebase.RebaseActionExec,
+	rebase.RebaseActionBreak}, sql.Collation_Default)
 
 var DoltRebaseSystemTableSchema = []*sql.Column{
 	{
@@ -77,6 +80,11 @@ var DoltRebaseSystemTableSchema = []*sql.Column{
 		Type:     types.Text,
 		Nullable: false,
 	},
+	{
+		Name:     "exec_statement",
+		Type:     types.Text,
+		Nullable: true,
+	},
 }
 
 // ErrRebaseUncommittedChanges is used when a rebase is started, but there are uncommitted (and not
@@ -117,12 +125,30 @@ var ErrRebaseConflictWithAbortError = goerrors.NewKind(
 	"merge conflict detected while rebasing commit %s. " +
 		"attempted to abort rebase operation, but encountered error: %w")
 
+// ErrRebaseExecFailed is used when the SQL statement of an exec step in a rebase plan fails. The rebase is aborted
+// when this happens.
+var ErrRebaseExecFailed = goerrors.NewKind("exec statement failed while rebasing: %s. the rebase has been automatically aborted")
+
+// ErrRebaseExecLeftChanges is used when the SQL statement of an exec step in a rebase plan succeeds, but leaves
+// uncommitted changes in the working set. The rebase is aborted when this happens.
+var ErrRebaseExecLeftChanges = goerrors.NewKind("exec statement left uncommitted changes while rebasing: %s. " +
+	"the rebase has been automatically aborted")
+
+// ErrRebaseStagedChangesAtBreak is used when a rebase is continued after stopping at a break step, but there are
+// staged changes in the working set that don't belong to any commit.
+var ErrRebaseStagedChangesAtBreak = goerrors.NewKind("cannot continue a rebase with staged changes after a break. " +
+	"Commit the changes with dolt_commit() and then continue the rebase")
+
 // SuccessfulRebaseMessage is used when a rebase finishes successfully. The branch that was rebased should be appended
 // to the end of the message.
 var SuccessfulRebaseMessage = "Successfully rebased and updated refs/heads/"
 
 var RebaseAbortedMessage = "Interactive rebase aborted"
 
+// RebaseStoppedMessage is used when a rebase stops at an edit or break step, so that the caller can make changes
+// before continuing the rebase. A description of where the rebase stopped is appended to the message.
+var RebaseStoppedMessage = "Interactive rebase stopped at "
+
 func doltRebase(ctx *sql.Context, args ...string) (sql.RowIter, error) {
 	res, message, err := doDoltRebase(ctx, args)
 	if err != nil {
@@ -151,11 +177,11 @@ func doDoltRebase(ctx *sql.Context, args []string) (int, string, error) {
 		}
 
 	case apr.Contains(cli.ContinueFlag):
-		rebaseBranch, err := continueRebase(ctx)
+		message, err := continueRebase(ctx)
 		if err != nil {
 			return 1, "", err
 		} else {
-			return 0, SuccessfulRebaseMessage + rebaseBranch, nil
+			return 0, message, nil
 		}
 
 	default:
@@ -583,6 +609,9 @@ func recordCurrentStep(ctx *sql.Context, step rebase.RebasePlanStep) error {
 	return nil
 }
 
+// continueRebase executes the rebase plan, starting after the last attempted step, and returns a message describing
+// the result. If an edit or break step is reached, the rebase stops and a RebaseStoppedMessage is returned, otherwise
+// the rebased branch is updated and a SuccessfulRebaseMessage is returned.
 func continueRebase(ctx *sql.Context) (string, error) {
 	// Validate that we are in an interactive rebase
 	if err := validateActiveRebase(ctx); err != nil {
@@ -666,12 +695,22 @@ func continueRebase(ctx *sql.Context) (string, error) {
 				return "", err
 			}
 
-			err = processRebasePlanStep(ctx, &step,
+			stopped, err := processRebasePlanStep(ctx, &step,
 				workingSet.RebaseState().CommitBecomesEmptyHandling(),
 				workingSet.RebaseState().EmptyCommitHandling())
 			if err != nil {
 				return "", err
 			}
+
+			// Stop the rebase for an edit or break step, so that the caller can make changes before continuing
+			if stopped {
+				if doltSession.GetTransaction() == nil {
+					if _, err = doltSession.StartTransaction(ctx, sql.ReadWrite); err != nil {
+						return "", err
+					}
+				}
+				return rebaseStoppedMessage(step), nil
+			}
 		}
 
 		// Ensure a transaction has been started, so that the session is in sync with the latest changes
@@ -733,14 +772,28 @@ func continueRebase(ctx *sql.Context) (string, error) {
 	if !ok {
 		return "", fmt.Errorf("unable to lookup dbdata")
 	}
-	return rebaseBranch, actions.DeleteBranch(ctx, dbData, rebaseWorkingBranch, actions.DeleteOptions{
+	err = actions.DeleteBranch(ctx, dbData, rebaseWorkingBranch, actions.DeleteOptions{
 		Force: true,
 	}, doltSession.Provider(), nil)
+	if err != nil {
+		return "", err
+	}
+	return SuccessfulRebaseMessage + rebaseBranch, nil
+}
+
+// rebaseStoppedMessage returns the message describing a rebase that stopped at the edit or break plan |step|.
+func rebaseStoppedMessage(step rebase.RebasePlanStep) string {
+	if step.Action == rebase.RebaseActionBreak {
+		return RebaseStoppedMessage + "break; continue rebasing by calling dolt_rebase('--continue')"
+	}
+	return fmt.Sprintf("%scommit %s (%s); make any changes and stage them with dolt_add() to amend the commit, "+
+		"then continue rebasing by calling dolt_rebase('--continue')", RebaseStoppedMessage, step.CommitHash, step.CommitMsg)
 }
 
 // commitManuallyStagedChangesForStep handles committing staged changes after a conflict has been manually
 // resolved by the caller before rebasing has been continued. This involves building the correct commit
-// message based on the details of the rebase plan |step| and then creating the commit.
+// message based on the details of the rebase plan |step| and then creating the commit. If the rebase stopped
+// at an edit step, the staged changes are amended into the edited commit instead.
 func commitManuallyStagedChangesForStep(ctx *sql.Context, step rebase.RebasePlanStep) error {
 	doltSession := dsess.DSessFromSess(ctx.Session)
 	workingSet, err := doltSession.WorkingSet(ctx, ctx.GetCurrentDatabase())
@@ -748,8 +801,23 @@ func commitManuallyStagedChangesForStep(ctx *sql.Context, step rebase.RebasePlan
 		return err
 	}
 
+	// Break and exec steps don't have a commit that the changes could belong to
+	if step.Action == rebase.RebaseActionBreak || step.Action == rebase.RebaseActionExec {
+		return ErrRebaseStagedChangesAtBreak.New()
+	}
+
 	options, err := createCherryPickOptionsForRebaseStep(ctx, &step, workingSet.RebaseState().CommitBecomesEmptyHandling(),
 		workingSet.RebaseState().EmptyCommitHandling())
+	if err != nil {
+		return err
+	}
+
+	// If an edit step's commit was replayed without conflicts, then the rebase stopped after committing it, and the
+	// staged changes amend that commit.
+	amendEditedCommit := step.Action == rebase.RebaseActionEdit && !workingSet.MergeActive()
+	if amendEditedCommit {
+		options.Amend = true
+	}
 
 	commitProps, err := cherry_pick.CreateCommitStagedPropsFromCherryPickOptions(ctx, *options)
 	if err != nil {
@@ -757,9 +825,9 @@ func commitManuallyStagedChangesForStep(ctx *sql.Context, step rebase.RebasePlan
 	}
 
 	// If the commit message wasn't set when we created the cherry-pick options, then set it to the step's commit
-	// message. For fixup commits, we don't use their commit message, so we keep it empty, and let the amend commit
-	// codepath use the previous commit's message.
-	if commitProps.Message == "" && step.Action != rebase.RebaseActionFixup {
+	// message. For fixup commits and amended edit commits, we don't use the step's commit message, so we keep it
+	// empty, and let the amend commit codepath use the previous commit's message.
+	if commitProps.Message == "" && step.Action != rebase.RebaseActionFixup && !amendEditedCommit {
 		commitProps.Message = step.CommitMsg
 	}
 
@@ -782,8 +850,10 @@ func commitManuallyStagedChangesForStep(ctx *sql.Context, step rebase.RebasePlan
 	return err
 }
 
+// processRebasePlanStep executes the rebase plan step |planStep| and returns whether the rebase should stop after it,
+// so that the caller can make changes before continuing the rebase.
 func processRebasePlanStep(ctx *sql.Context, planStep *rebase.RebasePlanStep,
-	commitBecomesEmptyHandling doltdb.EmptyCommitHandling, emptyCommitHandling doltdb.EmptyCommitHandling) error {
+	commitBecomesEmptyHandling doltdb.EmptyCommitHandling, emptyCommitHandling doltdb.EmptyCommitHandling) (bool, error) {
 	// Make sure we have a transaction opened for the session
 	// NOTE: After our first call to cherry-pick, the tx is committed, so a new tx needs to be started
 	//       as we process additional rebase actions.
@@ -791,21 +861,65 @@ func processRebasePlanStep(ctx *sql.Context, planStep *rebase.RebasePlanStep,
 	if doltSession.GetTransaction() == nil {
 		_, err := doltSession.StartTransaction(ctx, sql.ReadWrite)
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
 
-	// If the action is "drop", then we don't need to do anything
-	if planStep.Action == rebase.RebaseActionDrop {
-		return nil
+	switch planStep.Action {
+	case rebase.RebaseActionDrop:
+		// If the action is "drop", then we don't need to do anything
+		return false, nil
+	case rebase.RebaseActionBreak:
+		return true, nil
+	case rebase.RebaseActionExec:
+		return false, execRebasePlanStep(ctx, planStep)
 	}
 
 	options, err := createCherryPickOptionsForRebaseStep(ctx, planStep, commitBecomesEmptyHandling, emptyCommitHandling)
+	if err != nil {
+		return false, err
+	}
+
+	newCommit, err := handleRebaseCherryPick(ctx, planStep, *options)
+	if err != nil {
+		return false, err
+	}
+
+	// An edit step stops once its commit has been replayed, unless the commit became empty and was dropped
+	return planStep.Action == rebase.RebaseActionEdit && newCommit != "", nil
+}
+
+// execRebasePlanStep runs the SQL statement of the exec plan step |planStep|. If the statement fails, or leaves
+// uncommitted changes in the working set, the rebase is aborted and an error is returned.
+func execRebasePlanStep(ctx *sql.Context, planStep *rebase.RebasePlanStep) error {
+	// The statement runs on the engine of the session, so that it's parsed and analyzed like the client's statements
+	err := dsess.ErrNoQueryRunner
+	if engine := dsess.DSessFromSess(ctx.Session).QueryRunner(); engine != nil {
+		var iter sql.RowIter
+		_, iter, _, err = engine.Query(ctx, planStep.ExecStatement)
+		if err == nil {
+			_, err = sql.RowIterToRows(ctx, iter)
+		}
+	}
+	if err != nil {
+		if abortErr := abortRebase(ctx); abortErr != nil {
+			return fmt.Errorf("%s: unable to cleanly abort rebase: %s", err.Error(), abortErr.Error())
+		}
+		return ErrRebaseExecFailed.New(err.Error())
+	}
+
+	hasStagedChanges, hasUnstagedChanges, err := workingSetStatus(ctx)
 	if err != nil {
 		return err
 	}
+	if hasStagedChanges || hasUnstagedChanges {
+		if abortErr := abortRebase(ctx); abortErr != nil {
+			return fmt.Errorf("exec statement left uncommitted changes: unable to cleanly abort rebase: %s", abortErr.Error())
+		}
+		return ErrRebaseExecLeftChanges.New(planStep.ExecStatement)
+	}
 
-	return handleRebaseCherryPick(ctx, planStep, *options)
+	return nil
 }
 
 func createCherryPickOptionsForRebaseStep(ctx *sql.Context, planStep *rebase.RebasePlanStep, commitBecomesEmptyHandling doltdb.EmptyCommitHandling, emptyCommitHandling doltdb.EmptyCommitHandling) (*cherry_pick.CherryPickOptions, error) {
@@ -816,9 +930,9 @@ func createCherryPickOptionsForRebaseStep(ctx *sql.Context, planStep *rebase.Reb
 	options.EmptyCommitHandling = emptyCommitHandling
 
 	switch planStep.Action {
-	case rebase.RebaseActionDrop, rebase.RebaseActionPick:
-		// Nothing to do – the drop action doesn't result in a cherry pick and the pick action
-		// doesn't require any special options (i.e. no amend, no custom commit message).
+	case rebase.RebaseActionDrop, rebase.RebaseActionPick, rebase.RebaseActionEdit:
+		// Nothing to do – the drop action doesn't result in a cherry pick and the pick and edit
+		// actions don't require any special options (i.e. no amend, no custom commit message).
 
 	case rebase.RebaseActionReword:
 		options.CommitMessage = planStep.CommitMsg
@@ -844,9 +958,10 @@ func createCherryPickOptionsForRebaseStep(ctx *sql.Context, planStep *rebase.Reb
 // handleRebaseCherryPick runs a cherry-pick for the specified |commitHash|, using the specified
 // cherry-pick |options| and checks the results for any errors or merge conflicts. If a data conflict
 // is detected, then the ErrRebaseDataConflict error is returned. If a schema conflict is detected,
-// then the ErrRebaseSchemaConflict error is returned.
-func handleRebaseCherryPick(ctx *sql.Context, planStep *rebase.RebasePlanStep, options cherry_pick.CherryPickOptions) error {
-	_, mergeResult, err := cherry_pick.CherryPick(ctx, planStep.CommitHash, options)
+// then the ErrRebaseSchemaConflict error is returned. If successful, the hash of the new commit is
+// returned, or the empty string if no commit was created.
+func handleRebaseCherryPick(ctx *sql.Context, planStep *rebase.RebasePlanStep, options cherry_pick.CherryPickOptions) (string, error) {
+	newCommit, mergeResult, err := cherry_pick.CherryPick(ctx, planStep.CommitHash, options)
 
 	// TODO: rebase doesn't support schema conflict resolution yet. Ideally, when a schema conflict
 	//       is detected, the rebase would be paused and the user would resolve the conflict just
@@ -854,15 +969,15 @@ func handleRebaseCherryPick(ctx *sql.Context, planStep *rebase.RebasePlanStep, o
 	var schemaConflict merge.SchemaConflict
 	if errors.As(err, &schemaConflict) {
 		if abortErr := abortRebase(ctx); abortErr != nil {
-			return ErrRebaseConflictWithAbortError.New(planStep.CommitHash, abortErr)
+			return "", ErrRebaseConflictWithAbortError.New(planStep.CommitHash, abortErr)
 		}
-		return ErrRebaseSchemaConflict.New(planStep.CommitHash)
+		return "", ErrRebaseSchemaConflict.New(planStep.CommitHash)
 	}
 
 	doltSession := dsess.DSessFromSess(ctx.Session)
 	if mergeResult != nil && mergeResult.HasMergeArtifacts() {
 		if err := validateConflictsCanBeResolved(ctx, planStep); err != nil {
-			return err
+			return "", err
 		}
 
 		// If @@dolt_allow_commit_conflicts is enabled, then we need to make a SQL commit here, which
@@ -871,27 +986,27 @@ func handleRebaseCherryPick(ctx *sql.Context, planStep *rebase.RebasePlanStep, o
 		// conflicts within the same session, so in that case, we do NOT make a SQL commit.
 		allowCommitConflictsEnabled, err := isAllowCommitConflictsEnabled(ctx)
 		if err != nil {
-			return err
+			return "", err
 		}
 		if allowCommitConflictsEnabled {
 			if doltSession.GetTransaction() == nil {
 				_, err := doltSession.StartTransaction(ctx, sql.ReadWrite)
 				if err != nil {
-					return err
+					return "", err
 				}
 			}
 
 			err = doltSession.CommitTransaction(ctx, doltSession.GetTransaction())
 			if err != nil {
-				return err
+				return "", err
 			}
 		}
 
 		// Otherwise, let the caller know about the conflict and how to resolve
-		return ErrRebaseDataConflict.New(planStep.CommitHash, planStep.CommitMsg)
+		return "", ErrRebaseDataConflict.New(planStep.CommitHash, planStep.CommitMsg)
 	}
 
-	return err
+	return newCommit, err
 }
 
 // squashCommitMessage looks up the commit at HEAD and the commit identified by |nextCommitHash| and squashes their two
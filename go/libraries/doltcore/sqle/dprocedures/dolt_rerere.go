@@ -0,0 +1,216 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	rerereList   = "list"
+	rerereForget = "forget"
+)
+
+var doltRerereSchema = []*sql.Column{
+	{
+		Name:     "id",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "table_name",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "row_key",
+		Type:     types.LongText,
+		Nullable: false,
+	},
+	{
+		Name:     "resolved_row",
+		Type:     types.LongText,
+		Nullable: true,
+	},
+}
+
+// doltRerere is the stored procedure version for the CLI command `dolt rerere`. It lists the recorded resolutions of
+// row conflicts, or forgets some of them. A resolution is recorded when a conflict is resolved with
+// dolt_conflicts_resolve() or by deleting it from a dolt_conflicts_<table> table, and is reused when a later merge,
+// cherry-pick or rebase produces an identical conflict. The resolved_row of a conflict resolved by deleting its row is
+// NULL.
+func doltRerere(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	rows, err := doDoltRerere(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func doDoltRerere(ctx *sql.Context, args []string) ([]sql.Row, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return nil, sql.ErrNoDatabaseSelected.New()
+	}
+
+	apr, err := cli.CreateRerereArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	resolutions, err := loadConflictResolutions(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	subcommand := rerereList
+	if apr.NArg() > 0 {
+		subcommand = apr.Arg(0)
+	}
+
+	switch subcommand {
+	case rerereList:
+		if apr.NArg() > 1 {
+			return nil, fmt.Errorf("error: rerere list takes no arguments")
+		}
+		if apr.Contains(cli.AllFlag) {
+			return nil, fmt.Errorf("error: --%s can only be used with rerere forget", cli.AllFlag)
+		}
+		return resolutionRows(resolutions, resolutions.Keys()), nil
+	case rerereForget:
+		ids := apr.Args[1:]
+		if apr.Contains(cli.AllFlag) == (len(ids) > 0) {
+			return nil, fmt.Errorf("error: rerere forget requires either resolution ids or --%s", cli.AllFlag)
+		}
+
+		var forgotten []hash.Hash
+		if apr.Contains(cli.AllFlag) {
+			forgotten = resolutions.Keys()
+		} else {
+			for _, id := range ids {
+				h, ok := hash.MaybeParse(id)
+				if !ok {
+					return nil, fmt.Errorf("error: invalid resolution id '%s'", id)
+				}
+				if _, ok = resolutions.Get(h); !ok {
+					return nil, fmt.Errorf("error: no recorded resolution with id '%s'", id)
+				}
+				forgotten = append(forgotten, h)
+			}
+		}
+
+		rows := resolutionRows(resolutions, forgotten)
+		fs, err := dsess.DSessFromSess(ctx.Session).Provider().FileSystemForDatabase(dbName)
+		if err != nil {
+			return nil, err
+		}
+		err = rerere.Update(fs, func(r *rerere.Resolutions) (bool, error) {
+			for _, h := range forgotten {
+				// a resolution forgotten by another session since it was listed is already gone
+				if err := r.Forget(h); err != nil && err != rerere.ErrResolutionNotFound {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("error: unknown rerere subcommand '%s'; expected one of %s, %s", subcommand, rerereList, rerereForget)
+	}
+}
+
+// resolutionRows returns a row of the dolt_rerere() schema for each of the resolutions with keys |keys|.
+func resolutionRows(resolutions *rerere.Resolutions, keys []hash.Hash) []sql.Row {
+	rows := make([]sql.Row, 0, len(keys))
+	for _, k := range keys {
+		res, _ := resolutions.Get(k)
+		var resolved interface{}
+		if !res.IsDelete() {
+			resolved = res.Row
+		}
+		rows = append(rows, sql.Row{k.String(), res.Table, res.Key, resolved})
+	}
+	return rows
+}
+
+// loadConflictResolutions loads the recorded conflict resolutions of the database |dbName|.
+func loadConflictResolutions(ctx *sql.Context, dbName string) (*rerere.Resolutions, error) {
+	fs, err := dsess.DSessFromSess(ctx.Session).Provider().FileSystemForDatabase(dbName)
+	if err != nil {
+		return nil, err
+	}
+	return rerere.Load(fs)
+}
+
+// saveConflictResolutions records |resolutions| in the recorded conflict resolutions of the database |dbName|.
+func saveConflictResolutions(ctx *sql.Context, dbName string, resolutions *rerere.Resolutions) error {
+	if resolutions.Len() == 0 {
+		return nil
+	}
+	fs, err := dsess.DSessFromSess(ctx.Session).Provider().FileSystemForDatabase(dbName)
+	if err != nil {
+		return err
+	}
+	return rerere.Update(fs, func(r *rerere.Resolutions) (bool, error) {
+		r.RecordAll(resolutions)
+		return true, nil
+	})
+}
+
+// preMergeWorkingRoot returns the working root of the database |dbName| from before the merge in progress, which
+// is our side of the merge's conflicts, or nil if no merge is in progress. The resolutions of conflicts can only be
+// recorded while the merge that produced them is in progress.
+func preMergeWorkingRoot(ctx *sql.Context, dbName string) (doltdb.RootValue, error) {
+	ws, err := dsess.DSessFromSess(ctx.Session).WorkingSet(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	if !ws.MergeActive() {
+		return nil, nil
+	}
+	return ws.MergeState().PreMergeWorkingRoot(), nil
+}
+
+// recordTableConflictResolutions records in |resolutions| the resolution of every row conflict of |tbl| as the
+// current value of its row. |preMergeWorking| is the working root from before the merge which produced them.
+func recordTableConflictResolutions(ctx *sql.Context, resolutions *rerere.Resolutions, tbl *doltdb.Table, tblName doltdb.TableName, preMergeWorking doltdb.RootValue) error {
+	arts, err := tbl.GetArtifacts(ctx)
+	if err != nil {
+		return err
+	}
+	rows, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return err
+	}
+	sides, err := rerere.NewMergeSides(ctx, tblName, preMergeWorking)
+	if err != nil {
+		return err
+	}
+	_, err = resolutions.RecordConflicts(ctx, tblName.Name, durable.ProllyMapFromArtifactIndex(arts), durable.ProllyMapFromIndex(rows), sides, nil)
+	return err
+}
@@ -134,6 +134,9 @@ func resetSoftToRef(
 	dSess *dsess.DoltSession,
 	dbName string,
 ) error {
+	if err := validateResetToRef(ctx, dbData, dbName, firstArg); err != nil {
+		return err
+	}
 	roots, err := actions.ResetSoftToRef(ctx, dbData, firstArg)
 	if err != nil {
 		return err
@@ -215,6 +218,9 @@ func resetSoft(
 
 	// If ref is "" that means HEAD, which makes reset --soft a no-op
 	if arg != "" {
+		if err := validateResetToRef(ctx, dbData, dbName, arg); err != nil {
+			return err
+		}
 		roots, err := actions.ResetSoftToRef(ctx, dbData, arg)
 		if err != nil {
 			return err
@@ -249,12 +255,17 @@ func resetHard(
 		arg = apr.Arg(0)
 	}
 
+	oldWorking := roots.Working
 	var newHead *doltdb.Commit
 	newHead, roots, err := actions.ResetHardTables(ctx, dbData, arg, roots)
 
 	if err != nil {
 		return err
 	}
+	// the head of the branch is moved outside the transaction, so row policies are checked before it's moved
+	if err := dsess.ValidateRowPoliciesChange(ctx, oldWorking, roots.Working); err != nil {
+		return err
+	}
 
 	// TODO: this overrides the transaction setting, needs to happen at commit, not here
 	if newHead != nil {
@@ -262,6 +273,9 @@ func resetHard(
 		if err != nil {
 			return err
 		}
+		if err := validateHeadUpdate(ctx, dbData, dbName, newHead); err != nil {
+			return err
+		}
 		if err := dbData.Ddb.SetHeadToCommit(ctx, headRef, newHead); err != nil {
 			return err
 		}
@@ -283,3 +297,39 @@ func resetHard(
 
 	return nil
 }
+
+// validateResetToRef returns an error if the rules of dolt_branch_protection forbid moving the checked-out branch of
+// |dbData| to the commit |cSpecStr|.
+func validateResetToRef(ctx *sql.Context, dbData env.DbData, dbName string, cSpecStr string) error {
+	cs, err := doltdb.NewCommitSpec(cSpecStr)
+	if err != nil {
+		return err
+	}
+	headRef, err := dbData.Rsr.CWBHeadRef()
+	if err != nil {
+		return err
+	}
+	optCmt, err := dbData.Ddb.Resolve(ctx, cs, headRef)
+	if err != nil {
+		return err
+	}
+	newHead, ok := optCmt.ToCommit()
+	if !ok {
+		return doltdb.ErrGhostCommitEncountered
+	}
+	return validateHeadUpdate(ctx, dbData, dbName, newHead)
+}
+
+// validateHeadUpdate returns an error if the rules of dolt_branch_protection forbid moving the checked-out branch of
+// |dbData| to |newHead|.
+func validateHeadUpdate(ctx *sql.Context, dbData env.DbData, dbName string, newHead *doltdb.Commit) error {
+	headRef, err := dbData.Rsr.CWBHeadRef()
+	if err != nil {
+		return err
+	}
+	oldHead, err := dbData.Ddb.ResolveCommitRef(ctx, headRef)
+	if err != nil {
+		return err
+	}
+	return dsess.ValidateBranchUpdate(ctx, dbData.Ddb, dbName, headRef.GetPath(), oldHead, newHead)
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	goerrors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// DefaultWaitForCommitTimeout is how long dolt_wait_for_commit waits when it isn't given a timeout.
+const DefaultWaitForCommitTimeout = 10 * time.Second
+
+// waitForCommitPollInterval is how often the branches of the database are checked for the commit being waited for.
+const waitForCommitPollInterval = 10 * time.Millisecond
+
+// ErrWaitForCommitTimeout is returned when a commit doesn't show up in a database before the timeout.
+var ErrWaitForCommitTimeout = goerrors.NewKind("timed out waiting for commit %s in database %s after %v")
+
+// doltWaitForCommit implements the stored procedure `dolt_wait_for_commit(hash [, timeout_secs])`. It returns once
+// the commit is on a branch of the current database, which lets a client of a cluster standby or a read replica read
+// what it wrote through another server.
+func doltWaitForCommit(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("dolt_wait_for_commit takes a commit hash and an optional timeout in seconds")
+	}
+	h, ok := hash.MaybeParse(args[0])
+	if !ok {
+		return nil, fmt.Errorf("invalid commit hash: %s", args[0])
+	}
+	timeout := DefaultWaitForCommitTimeout
+	if len(args) == 2 {
+		secs, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || secs < 0 {
+			return nil, fmt.Errorf("invalid timeout: %s", args[1])
+		}
+		timeout = time.Duration(secs * float64(time.Second))
+	}
+
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return nil, fmt.Errorf("empty database name")
+	}
+	if err := WaitForCommit(ctx, dbName, h, timeout); err != nil {
+		return nil, err
+	}
+	return rowToIter(int64(0)), nil
+}
+
+// WaitForCommit waits until the commit |h| is the head of a branch of the database |dbName|, or one of its
+// ancestors, and returns ErrWaitForCommitTimeout if it isn't after |timeout|. The branches are read from the database
+// rather than from the session, so that commits written to the database by replication since the transaction of the
+// session started are seen.
+func WaitForCommit(ctx *sql.Context, dbName string, h hash.Hash, timeout time.Duration) error {
+	baseName, _ := dsess.SplitRevisionDbName(dbName)
+	ddb, ok := dsess.DSessFromSess(ctx.Session).GetDoltDB(ctx, baseName)
+	if !ok {
+		return sql.ErrDatabaseNotFound.New(baseName)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		onBranch, err := isOnBranch(ctx, ddb, h)
+		if err != nil {
+			return err
+		}
+		if onBranch {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrWaitForCommitTimeout.New(h.String(), baseName, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitForCommitPollInterval):
+		}
+	}
+}
+
+// isOnBranch returns whether the commit |h| is the head of a branch of |ddb|, or one of its ancestors.
+func isOnBranch(ctx *sql.Context, ddb *doltdb.DoltDB, h hash.Hash) (bool, error) {
+	branches, err := ddb.GetBranchesWithHashes(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, b := range branches {
+		if b.Hash == h {
+			return true, nil
+		}
+	}
+
+	optCmt, err := ddb.ReadCommit(ctx, h)
+	if errors.Is(err, datas.ErrCommitNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	cm, ok := optCmt.ToCommit()
+	if !ok {
+		return false, nil
+	}
+	for _, b := range branches {
+		optHead, err := ddb.ReadCommit(ctx, b.Hash)
+		if err != nil {
+			return false, err
+		}
+		head, ok := optHead.ToCommit()
+		if !ok {
+			continue
+		}
+		optAnc, err := doltdb.GetCommitAncestor(ctx, cm, head)
+		if errors.Is(err, doltdb.ErrNoCommonAncestor) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if optAnc.Addr == h {
+			return true, nil
+		}
+	}
+	return false, nil
+}
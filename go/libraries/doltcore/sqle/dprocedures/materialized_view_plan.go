@@ -0,0 +1,442 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	ast "github.com/dolthub/vitess/go/vt/sqlparser"
+	goerrors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+)
+
+var ErrInvalidMaterializedViewQuery = goerrors.NewKind("materialized view query must be a SELECT statement: %s")
+
+// matViewKind is the way a materialized view is maintained when the tables it reads change.
+type matViewKind int
+
+const (
+	// matViewFullRebuild views are rebuilt from scratch by running their query
+	matViewFullRebuild matViewKind = iota
+	// matViewProjection views filter and project the rows of a single table, and are keyed by its primary key
+	matViewProjection
+	// matViewAggregate views group the rows of a single table and aggregate them with COUNT(*), COUNT(expr) and
+	// SUM(column), and are keyed by the grouping columns
+	matViewAggregate
+)
+
+// matViewDiffSign is the column of the diff of a table that is 1 for the rows added to it and -1 for the rows removed
+// from it, which the aggregates of a view are weighted by.
+const matViewDiffSign = "dolt_diff_sign"
+
+// matViewPlan is how a materialized view is refreshed.
+type matViewPlan struct {
+	kind  matViewKind
+	query string
+	// sources are the names of the tables the view reads, which is nil when they can't all be determined, like when
+	// the view reads system tables, other databases or other revisions
+	sources []string
+
+	sel     *ast.Select
+	alias   string
+	srcCols []string
+	// keys are the positions of the primary key columns of the view in its SELECT
+	keys []int
+	// srcKeys are the primary key columns of the source table of a projection, matching |keys|
+	srcKeys []string
+	// counter is the position of the COUNT(*) of an aggregate view
+	counter int
+}
+
+// parseMaterializedViewQuery parses the query of a materialized view.
+func parseMaterializedViewQuery(query string) (ast.SelectStatement, error) {
+	stmt, err := ast.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(ast.SelectStatement)
+	if !ok {
+		return nil, ErrInvalidMaterializedViewQuery.New(query)
+	}
+	return sel, nil
+}
+
+// planMaterializedView returns the plan for refreshing the materialized view with the query |query| from |root|.
+func planMaterializedView(ctx *sql.Context, root doltdb.RootValue, query string) (*matViewPlan, error) {
+	stmt, err := parseMaterializedViewQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	plan := &matViewPlan{kind: matViewFullRebuild, query: query}
+	if plan.sources, err = materializedViewSources(ctx, root, stmt); err != nil || len(plan.sources) != 1 {
+		return plan, err
+	}
+
+	sel, ok := stmt.(*ast.Select)
+	if !ok || !isIncrementalSelect(sel) {
+		return plan, nil
+	}
+	aliased := sel.From[0].(*ast.AliasedTableExpr)
+	plan.sel = sel
+	plan.alias = aliased.As.String()
+	if plan.alias == "" {
+		plan.alias = aliased.Expr.(ast.TableName).Name.String()
+	}
+
+	tbl, _, err := root.GetTable(ctx, doltdb.TableName{Name: plan.sources[0]})
+	if err != nil {
+		return nil, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schema.IsKeyless(sch) {
+		return plan, nil
+	}
+	plan.srcCols = sch.GetAllCols().GetColumnNames()
+
+	if len(sel.GroupBy) == 0 && !hasAggregate(sel.SelectExprs) {
+		plan.planProjection(sch)
+	} else if len(sel.GroupBy) > 0 {
+		plan.planAggregate(sch)
+	}
+	return plan, nil
+}
+
+// materializedViewSources returns the names of the tables of |root| read by |stmt|, or nil if it reads anything else.
+func materializedViewSources(ctx *sql.Context, root doltdb.RootValue, stmt ast.SelectStatement) ([]string, error) {
+	var names []string
+	volatile := false
+	err := ast.Walk(func(node ast.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *ast.AliasedTableExpr:
+			tableName, ok := node.Expr.(ast.TableName)
+			if !ok {
+				return true, nil
+			}
+			if node.AsOf != nil || !tableName.DbQualifier.IsEmpty() || !tableName.SchemaQualifier.IsEmpty() {
+				volatile = true
+				return false, nil
+			}
+			names = append(names, tableName.Name.String())
+		case *ast.TableFuncExpr:
+			volatile = true
+			return false, nil
+		}
+		return true, nil
+	}, stmt)
+	if err != nil || volatile {
+		return nil, err
+	}
+
+	sources := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if doltdb.HasDoltPrefix(name) {
+			return nil, nil
+		}
+		// Views and common table expressions aren't tables of the root
+		_, tblName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: name})
+		if err != nil || !ok {
+			return nil, err
+		}
+		if !seen[strings.ToLower(tblName)] {
+			seen[strings.ToLower(tblName)] = true
+			sources = append(sources, tblName)
+		}
+	}
+	return sources, nil
+}
+
+// isIncrementalSelect returns whether |sel| reads a single table without any of the clauses that prevent maintaining
+// its results from the diff of the table.
+func isIncrementalSelect(sel *ast.Select) bool {
+	if sel.With != nil || sel.QueryOpts.Distinct || sel.Having != nil || sel.Limit != nil || len(sel.Window) > 0 ||
+		sel.Into != nil || sel.Lock != "" || len(sel.From) != 1 {
+		return false
+	}
+	if aliased, ok := sel.From[0].(*ast.AliasedTableExpr); !ok {
+		return false
+	} else if _, ok = aliased.Expr.(ast.TableName); !ok {
+		return false
+	}
+
+	incremental := true
+	_ = ast.Walk(func(node ast.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *ast.Subquery, *ast.ExistsExpr:
+			incremental = false
+		case *ast.FuncExpr:
+			if node.Over != nil {
+				incremental = false
+			}
+		}
+		return incremental, nil
+	}, sel.SelectExprs, sel.Where, sel.GroupBy)
+	return incremental
+}
+
+func hasAggregate(exprs ast.SelectExprs) bool {
+	found := false
+	_ = ast.Walk(func(node ast.SQLNode) (bool, error) {
+		if f, ok := node.(*ast.FuncExpr); ok && f.IsAggregate() {
+			found = true
+		}
+		if _, ok := node.(*ast.GroupConcatExpr); ok {
+			found = true
+		}
+		return !found, nil
+	}, exprs)
+	return found
+}
+
+// planProjection plans a view that filters and projects the rows of its source table, which must select the primary
+// key columns of the table.
+func (p *matViewPlan) planProjection(sch schema.Schema) {
+	pkCols := sch.GetPKCols().GetColumnNames()
+	if len(p.sel.SelectExprs) == 1 {
+		if star, ok := p.sel.SelectExprs[0].(*ast.StarExpr); ok && p.refersToSource(star.TableName) {
+			for _, pk := range pkCols {
+				p.keys = append(p.keys, indexOfFold(p.srcCols, pk))
+			}
+			p.srcKeys = pkCols
+			p.kind = matViewProjection
+			return
+		}
+	}
+
+	for _, pk := range pkCols {
+		i := p.indexOfColumn(pk)
+		if i < 0 {
+			return
+		}
+		p.keys = append(p.keys, i)
+	}
+	p.srcKeys = pkCols
+	p.kind = matViewProjection
+}
+
+// planAggregate plans a view that groups the rows of its source table by non-null columns, which must all be
+// selected, and selects COUNT(*) along with any number of COUNT(expr) and SUM(column) aggregates.
+func (p *matViewPlan) planAggregate(sch schema.Schema) {
+	keys := make(map[int]bool)
+	for _, expr := range p.sel.GroupBy {
+		col, ok := expr.(*ast.ColName)
+		if !ok || !p.refersToSource(col.Qualifier) || !p.isNonNullColumn(sch, col.Name.String()) {
+			return
+		}
+		i := p.indexOfColumn(col.Name.String())
+		if i < 0 {
+			return
+		}
+		p.keys = append(p.keys, i)
+		keys[i] = true
+	}
+
+	p.counter = -1
+	for i, expr := range p.sel.SelectExprs {
+		if keys[i] {
+			continue
+		}
+		aliased, ok := expr.(*ast.AliasedExpr)
+		if !ok {
+			return
+		}
+		f, ok := aliased.Expr.(*ast.FuncExpr)
+		if !ok || f.Distinct || f.Over != nil || !f.Qualifier.IsEmpty() || len(f.Exprs) != 1 {
+			return
+		}
+		switch {
+		case f.Name.EqualString("count"):
+			if _, ok := f.Exprs[0].(*ast.StarExpr); ok && p.counter < 0 {
+				p.counter = i
+			}
+		case f.Name.EqualString("sum"):
+			arg, ok := f.Exprs[0].(*ast.AliasedExpr)
+			if !ok {
+				return
+			}
+			col, ok := arg.Expr.(*ast.ColName)
+			if !ok || !p.refersToSource(col.Qualifier) || !p.isNonNullColumn(sch, col.Name.String()) {
+				return
+			}
+		default:
+			return
+		}
+	}
+	if p.counter >= 0 {
+		p.kind = matViewAggregate
+	}
+}
+
+// refersToSource returns whether a column qualified with |qualifier| is a column of the source table.
+func (p *matViewPlan) refersToSource(qualifier ast.TableName) bool {
+	return qualifier.IsEmpty() || (qualifier.DbQualifier.IsEmpty() && strings.EqualFold(qualifier.Name.String(), p.alias))
+}
+
+func (p *matViewPlan) isNonNullColumn(sch schema.Schema, name string) bool {
+	col, ok := sch.GetAllCols().LowerNameToCol[strings.ToLower(name)]
+	return ok && !col.IsNullable()
+}
+
+// indexOfColumn returns the position in the SELECT of the view of the source column |name|, or -1 if it isn't
+// selected.
+func (p *matViewPlan) indexOfColumn(name string) int {
+	for i, expr := range p.sel.SelectExprs {
+		aliased, ok := expr.(*ast.AliasedExpr)
+		if !ok {
+			continue
+		}
+		if col, ok := aliased.Expr.(*ast.ColName); ok && p.refersToSource(col.Qualifier) && col.Name.EqualString(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// keyColumnNames returns the names the primary key columns of the view have in the table created from its query.
+func (p *matViewPlan) keyColumnNames() []string {
+	if _, ok := p.sel.SelectExprs[0].(*ast.StarExpr); ok {
+		return p.srcKeys
+	}
+	names := make([]string, len(p.keys))
+	for i, k := range p.keys {
+		aliased := p.sel.SelectExprs[k].(*ast.AliasedExpr)
+		if aliased.As.IsEmpty() {
+			names[i] = aliased.Expr.(*ast.ColName).Name.String()
+		} else {
+			names[i] = aliased.As.String()
+		}
+	}
+	return names
+}
+
+// rebuildQueries returns the queries replacing the table |view| with the results of the view's query.
+func (p *matViewPlan) rebuildQueries(view string) []string {
+	queries := []string{
+		"DROP TABLE IF EXISTS " + sqlfmt.QuoteIdentifier(view),
+		fmt.Sprintf("CREATE TABLE %s AS %s", sqlfmt.QuoteIdentifier(view), p.query),
+	}
+	if p.kind != matViewFullRebuild {
+		queries = append(queries, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)",
+			sqlfmt.QuoteIdentifier(view), quoteIdentifiers(p.keyColumnNames())))
+	}
+	return queries
+}
+
+// incrementalQueries returns the queries applying the diff of the source table between the commit |base| and the
+// working set to the table |view|, whose columns are |viewCols|.
+func (p *matViewPlan) incrementalQueries(view, base string, viewCols []string) ([]string, error) {
+	if _, ok := p.sel.SelectExprs[0].(*ast.StarExpr); !ok && len(viewCols) != len(p.sel.SelectExprs) {
+		return nil, fmt.Errorf("materialized view %s has %d columns but its query selects %d", view, len(viewCols), len(p.sel.SelectExprs))
+	}
+	diff := fmt.Sprintf("dolt_diff(%s, 'WORKING', %s)", quoteString(base), quoteString(p.sources[0]))
+	where := ""
+	if p.sel.Where != nil {
+		where = " WHERE " + ast.String(p.sel.Where.Expr)
+	}
+
+	if p.kind == matViewProjection {
+		// Delete the rows of the view for the removed and modified rows of the source, then select the added and
+		// modified rows back into it
+		keys := make([]string, len(p.keys))
+		for i, k := range p.keys {
+			keys[i] = sqlfmt.QuoteIdentifier(viewCols[k])
+		}
+		deleted := fmt.Sprintf("DELETE FROM %s WHERE (%s) IN (SELECT %s FROM %s WHERE diff_type IN ('removed', 'modified'))",
+			sqlfmt.QuoteIdentifier(view), strings.Join(keys, ", "), p.diffColumns("from_", p.srcKeys, false), diff)
+		inserted := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM (SELECT %s FROM %s WHERE diff_type IN ('added', 'modified')) AS %s%s",
+			sqlfmt.QuoteIdentifier(view), quoteIdentifiers(viewCols), ast.String(p.sel.SelectExprs),
+			p.diffColumns("to_", p.srcCols, true), diff, sqlfmt.QuoteIdentifier(p.alias), where)
+		return []string{deleted, inserted}, nil
+	}
+
+	// Aggregate the added rows of the source positively and its removed rows negatively, and add the aggregates to
+	// those of the view, then delete the groups left without rows
+	keys := make(map[int]bool)
+	for _, k := range p.keys {
+		keys[k] = true
+	}
+	deltas := make([]string, len(p.sel.SelectExprs))
+	var updates []string
+	for i, expr := range p.sel.SelectExprs {
+		aliased := expr.(*ast.AliasedExpr)
+		delta := ast.String(aliased.Expr)
+		if !keys[i] {
+			f := aliased.Expr.(*ast.FuncExpr)
+			arg := f.Exprs[0]
+			switch {
+			case f.Name.EqualString("sum"):
+				delta = fmt.Sprintf("SUM(%s * %s)", ast.String(arg), matViewDiffSign)
+			case isStar(arg):
+				delta = fmt.Sprintf("SUM(%s)", matViewDiffSign)
+			default:
+				delta = fmt.Sprintf("SUM(CASE WHEN %s IS NULL THEN 0 ELSE %s END)", ast.String(arg), matViewDiffSign)
+			}
+			col := sqlfmt.QuoteIdentifier(viewCols[i])
+			updates = append(updates, fmt.Sprintf("%s = %s + dolt_delta.d%d", col, col, i))
+		}
+		deltas[i] = fmt.Sprintf("%s AS d%d", delta, i)
+	}
+
+	rows := fmt.Sprintf("SELECT %s, 1 AS %s FROM %s WHERE diff_type IN ('added', 'modified') UNION ALL SELECT %s, -1 FROM %s WHERE diff_type IN ('removed', 'modified')",
+		p.diffColumns("to_", p.srcCols, true), matViewDiffSign, diff, p.diffColumns("from_", p.srcCols, false), diff)
+	upserted := fmt.Sprintf("INSERT INTO %s (%s) SELECT * FROM (SELECT %s FROM (%s) AS %s%s%s) AS dolt_delta ON DUPLICATE KEY UPDATE %s",
+		sqlfmt.QuoteIdentifier(view), quoteIdentifiers(viewCols), strings.Join(deltas, ", "), rows,
+		sqlfmt.QuoteIdentifier(p.alias), where, ast.String(p.sel.GroupBy), strings.Join(updates, ", "))
+	emptied := fmt.Sprintf("DELETE FROM %s WHERE %s = 0", sqlfmt.QuoteIdentifier(view), sqlfmt.QuoteIdentifier(viewCols[p.counter]))
+	return []string{upserted, emptied}, nil
+}
+
+// diffColumns returns the columns |cols| of the diff of the source table with the prefix |prefix|, aliased to their
+// names in the source table if |alias| is set.
+func (p *matViewPlan) diffColumns(prefix string, cols []string, alias bool) string {
+	exprs := make([]string, len(cols))
+	for i, col := range cols {
+		exprs[i] = sqlfmt.QuoteIdentifier(prefix + col)
+		if alias {
+			exprs[i] += " AS " + sqlfmt.QuoteIdentifier(col)
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func isStar(expr ast.SelectExpr) bool {
+	_, ok := expr.(*ast.StarExpr)
+	return ok
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = sqlfmt.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func indexOfFold(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return -1
+}
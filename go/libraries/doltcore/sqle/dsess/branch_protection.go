@@ -0,0 +1,335 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// protectedHead is the new head of a protected branch, as seen by the rules of dolt_branch_protection. The head of a
+// pending commit isn't written yet, so it has no hash.
+type protectedHead struct {
+	hash      hash.Hash
+	parents   []hash.Hash
+	signature string
+	root      doltdb.RootValue
+	// descends is whether the head descends from the old head of the branch
+	descends bool
+}
+
+// ValidateBranchUpdate returns an error if the rules of dolt_branch_protection forbid moving the branch |branch| of the
+// database |dbName| from the commit |oldHead| to the commit |newHead|. Creating a branch is never forbidden, so a nil
+// |oldHead| is always valid.
+func ValidateBranchUpdate(ctx *sql.Context, ddb *doltdb.DoltDB, dbName string, branch string, oldHead, newHead *doltdb.Commit) error {
+	if oldHead == nil {
+		return nil
+	}
+	dbName, _ = SplitRevisionDbName(dbName)
+	rules, err := branch_control.GetProtectionRules(ctx, dbName, branch)
+	if err != nil || rules == branch_control.ProtectionRules_None {
+		return err
+	}
+	branchHeads, err := ddb.GetBranchesWithHashes(ctx)
+	if err != nil {
+		return err
+	}
+	return validateBranchUpdate(ctx, ddb, branch, rules, oldHead, newHead, branchHeads)
+}
+
+// ValidateBranchDelete returns an error if the rules of dolt_branch_protection forbid deleting the branch |branch| of
+// the database |dbName|.
+func ValidateBranchDelete(ctx context.Context, dbName string, branch string) error {
+	dbName, _ = SplitRevisionDbName(dbName)
+	rules, err := branch_control.GetProtectionRules(ctx, dbName, branch)
+	if err != nil {
+		return err
+	}
+	if rules&branch_control.ProtectionRules_NoDelete != 0 {
+		return branch_control.ErrProtectedBranch.New(branch, "it cannot be deleted")
+	}
+	return nil
+}
+
+// ValidateRootUpdate returns an error if the rules of dolt_branch_protection forbid any of the changes to the branches
+// of the database |dbName| made by moving the root of |ddb| from |last| to |current|, such as those written by a push.
+func ValidateRootUpdate(ctx *sql.Context, ddb *doltdb.DoltDB, dbName string, last, current hash.Hash) error {
+	dbName, _ = SplitRevisionDbName(dbName)
+	lastHeads, err := ddb.GetBranchesByRootHash(ctx, last)
+	if err != nil {
+		return err
+	}
+	currentHeads, err := ddb.GetBranchesByRootHash(ctx, current)
+	if err != nil {
+		return err
+	}
+	currentByRef := make(map[string]hash.Hash, len(currentHeads))
+	for _, head := range currentHeads {
+		currentByRef[head.Ref.String()] = head.Hash
+	}
+
+	for _, lastHead := range lastHeads {
+		branch := lastHead.Ref.GetPath()
+		newHash, ok := currentByRef[lastHead.Ref.String()]
+		if ok && newHash == lastHead.Hash {
+			continue
+		}
+		if !ok {
+			if err = ValidateBranchDelete(ctx, dbName, branch); err != nil {
+				return err
+			}
+			continue
+		}
+		rules, err := branch_control.GetProtectionRules(ctx, dbName, branch)
+		if err != nil {
+			return err
+		}
+		if rules == branch_control.ProtectionRules_None {
+			continue
+		}
+		oldHead, err := readCommit(ctx, ddb, lastHead.Hash)
+		if err != nil {
+			return err
+		}
+		newHead, err := readCommit(ctx, ddb, newHash)
+		if err != nil {
+			return err
+		}
+		if err = validateBranchUpdate(ctx, ddb, branch, rules, oldHead, newHead, currentHeads); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePendingCommit returns an error if the rules of dolt_branch_protection forbid committing |pending| on top of
+// |curHead|, the head of the branch |branch| of the database |dbName|.
+func validatePendingCommit(ctx *sql.Context, ddb *doltdb.DoltDB, dbName string, branch string, curHead *doltdb.Commit, pending *doltdb.PendingCommit) error {
+	if curHead == nil {
+		return nil
+	}
+	rules, err := branch_control.GetProtectionRules(ctx, dbName, branch)
+	if err != nil || rules == branch_control.ProtectionRules_None {
+		return err
+	}
+	curHash, err := curHead.HashOf()
+	if err != nil {
+		return err
+	}
+	newHead := protectedHead{
+		parents:  append([]hash.Hash{curHash}, pending.CommitOptions.Parents...),
+		root:     pending.Roots.Staged,
+		descends: true,
+	}
+	if pending.CommitOptions.Meta != nil {
+		newHead.signature = pending.CommitOptions.Meta.Signature
+	}
+	return checkProtectionRules(ctx, ddb, branch, rules, curHead, newHead, nil)
+}
+
+// validateBranchUpdate returns an error if |rules| forbid moving the branch |branch| from |oldHead| to |newHead|, which
+// are both commits of |ddb|. |branchHeads| are the heads of the branches of the database once the branch is updated.
+func validateBranchUpdate(ctx *sql.Context, ddb *doltdb.DoltDB, branch string, rules branch_control.ProtectionRules, oldHead, newHead *doltdb.Commit, branchHeads []doltdb.RefWithHash) error {
+	oldHash, err := oldHead.HashOf()
+	if err != nil {
+		return err
+	}
+	newHash, err := newHead.HashOf()
+	if err != nil {
+		return err
+	}
+	if oldHash == newHash {
+		return nil
+	}
+
+	head := protectedHead{hash: newHash}
+	if head.parents, err = newHead.ParentHashes(ctx); err != nil {
+		return err
+	}
+	meta, err := newHead.GetCommitMeta(ctx)
+	if err != nil {
+		return err
+	}
+	head.signature = meta.Signature
+	if head.root, err = newHead.GetRootValue(ctx); err != nil {
+		return err
+	}
+	optAncestor, err := doltdb.GetCommitAncestor(ctx, oldHead, newHead)
+	if err != nil && err != doltdb.ErrNoCommonAncestor {
+		return err
+	}
+	if optAncestor != nil {
+		if ancestor, ok := optAncestor.ToCommit(); ok && ancestor != nil {
+			ancestorHash, err := ancestor.HashOf()
+			if err != nil {
+				return err
+			}
+			head.descends = ancestorHash == oldHash
+		}
+	}
+
+	branchRef := ref.NewBranchRef(branch)
+	otherHeads := make(map[hash.Hash]struct{})
+	for _, branchHead := range branchHeads {
+		if !ref.Equals(branchHead.Ref, branchRef) {
+			otherHeads[branchHead.Hash] = struct{}{}
+		}
+	}
+	return checkProtectionRules(ctx, ddb, branch, rules, oldHead, head, otherHeads)
+}
+
+// checkProtectionRules returns an error if |rules| forbid moving the branch |branch| from |oldHead| to |newHead|.
+// |otherHeads| are the heads of the other branches of the database, which a new head that has a hash may be a
+// fast-forward merge of.
+func checkProtectionRules(ctx *sql.Context, ddb *doltdb.DoltDB, branch string, rules branch_control.ProtectionRules, oldHead *doltdb.Commit, newHead protectedHead, otherHeads map[hash.Hash]struct{}) error {
+	oldHash, err := oldHead.HashOf()
+	if err != nil {
+		return err
+	}
+	_, isBranchHead := otherHeads[newHead.hash]
+	isMerge := len(newHead.parents) > 1 && newHead.parents[0] == oldHash
+	isChild := len(newHead.parents) > 0 && newHead.parents[0] == oldHash
+
+	if rules&branch_control.ProtectionRules_NoForcePush != 0 && !newHead.descends {
+		return branch_control.ErrProtectedBranch.New(branch, "its new head must descend from its current head")
+	}
+	if rules&branch_control.ProtectionRules_NoDirectCommits != 0 && !isMerge && !isBranchHead {
+		return branch_control.ErrProtectedBranch.New(branch, "changes must be merged into it from another branch")
+	}
+	if rules&branch_control.ProtectionRules_FastForwardOnly != 0 && isMerge {
+		return branch_control.ErrProtectedBranch.New(branch, "branches must be merged into it with a fast-forward")
+	}
+	if rules&branch_control.ProtectionRules_MergeCommitsOnly != 0 && (isBranchHead || !isChild) {
+		return branch_control.ErrProtectedBranch.New(branch, "branches must be merged into it with a merge commit")
+	}
+	if rules&branch_control.ProtectionRules_SignedCommits != 0 {
+		if err = checkSignedCommits(ctx, ddb, branch, oldHash, newHead); err != nil {
+			return err
+		}
+	}
+	if rules&branch_control.ProtectionRules_VerifyConstraints != 0 {
+		if err = checkConstraints(ctx, branch, oldHead, newHead.root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSignedCommits returns an error if any commit that moving the branch |branch| from |oldHash| to |newHead| adds
+// to it is not signed. Signatures are verified by the clients that read them, as the server doesn't have the keys of
+// the signers, so only their presence is checked.
+func checkSignedCommits(ctx context.Context, ddb *doltdb.DoltDB, branch string, oldHash hash.Hash, newHead protectedHead) error {
+	start := []hash.Hash{newHead.hash}
+	if newHead.hash.IsEmpty() {
+		if len(newHead.signature) == 0 {
+			return branch_control.ErrProtectedBranch.New(branch, "its commits must be signed")
+		}
+		start = newHead.parents
+	}
+	itr, err := commitwalk.GetDotDotRevisionsIterator(ctx, ddb, start, ddb, []hash.Hash{oldHash}, nil)
+	if err != nil {
+		return err
+	}
+	for {
+		h, optCmt, err := itr.Next(ctx)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		commit, ok := optCmt.ToCommit()
+		if !ok {
+			return doltdb.ErrGhostCommitEncountered
+		}
+		meta, err := commit.GetCommitMeta(ctx)
+		if err != nil {
+			return err
+		}
+		if len(meta.Signature) == 0 {
+			return branch_control.ErrProtectedBranch.New(branch, fmt.Sprintf("its commits must be signed, and commit %s is not", h.String()))
+		}
+	}
+}
+
+// checkConstraints returns an error if |newRoot| has constraint violations, in the same way as dolt_verify_constraints
+// with the old head of the branch |branch| as the head.
+func checkConstraints(ctx *sql.Context, branch string, oldHead *doltdb.Commit, newRoot doltdb.RootValue) error {
+	oldRoot, err := oldHead.GetRootValue(ctx)
+	if err != nil {
+		return err
+	}
+	mergeOpts := merge.MergeOpts{
+		KeepSchemaConflicts:    true,
+		ReverifyAllConstraints: true,
+	}
+	result, err := merge.MergeRoots(ctx, oldRoot, newRoot, oldRoot, newRoot, oldRoot, editor.Options{}, mergeOpts)
+	if err != nil {
+		return fmt.Errorf("error calculating constraint violations: %w", err)
+	}
+	tableNames, err := doltdb.UnionTableNames(ctx, result.Root)
+	if err != nil {
+		return err
+	}
+	var tablesWithViolations []string
+	for _, tableName := range tableNames {
+		table, ok, err := result.Root.GetTable(ctx, tableName)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		artifacts, err := table.GetArtifacts(ctx)
+		if err != nil {
+			return err
+		}
+		count, err := artifacts.ConstraintViolationCount(ctx)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			tablesWithViolations = append(tablesWithViolations, tableName.String())
+		}
+	}
+	if len(tablesWithViolations) > 0 {
+		return branch_control.ErrProtectedBranch.New(branch, "its new head has constraint violations in "+strings.Join(tablesWithViolations, ", "))
+	}
+	return nil
+}
+
+// readCommit returns the commit with the hash |h| in |ddb|.
+func readCommit(ctx context.Context, ddb *doltdb.DoltDB, h hash.Hash) (*doltdb.Commit, error) {
+	optCmt, err := ddb.ReadCommit(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	commit, ok := optCmt.ToCommit()
+	if !ok {
+		return nil, doltdb.ErrGhostCommitEncountered
+	}
+	return commit, nil
+}
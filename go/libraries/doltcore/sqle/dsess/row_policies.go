@@ -0,0 +1,347 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/expranalysis"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+var ErrRowPolicyViolation = errors.NewKind("row policies of table `%s` do not allow `%s`@`%s` to write this row")
+
+// ErrRowPoliciesWriteDenied is returned when a user without the SUPER or GRANT OPTION privilege changes
+// dolt_row_policies.
+var ErrRowPoliciesWriteDenied = errors.NewKind("user `%s` must have the SUPER or GRANT OPTION privilege to change row policies")
+
+// rowPolicyCacheSize is the number of compiled row policy filters that are cached.
+const rowPolicyCacheSize = 1024
+
+// rowPolicyCache caches compiled RowPolicyFilters, as resolving their predicates requires parsing them.
+var rowPolicyCache, _ = lru.New[rowPolicyCacheKey, *RowPolicyFilter](rowPolicyCacheSize)
+
+// rowPolicyCacheKey identifies a compiled RowPolicyFilter. A filter is valid as long as the dolt_row_policies table
+// and the schema of the table it filters are unchanged.
+type rowPolicyCacheKey struct {
+	policies hash.Hash
+	schema   hash.Hash
+	table    doltdb.TableName
+	user     string
+	host     string
+}
+
+// RowPolicyFilter enforces the dolt_row_policies of a table for a user. Rows are visible to the user, and may be
+// written by the user, when they satisfy the predicate of any policy whose user and host patterns match the user.
+// When a table has policies but none of them match the user, no rows are visible.
+type RowPolicyFilter struct {
+	tableName string
+	user      string
+	host      string
+	// expr is the disjunction of the predicates of the policies matching the user, or nil if none match.
+	expr sql.Expression
+}
+
+// AuthorizeRowPoliciesWrite returns an error unless the user of |ctx| has the global SUPER or GRANT OPTION
+// privilege, which is required to change dolt_row_policies.
+func AuthorizeRowPoliciesWrite(ctx *sql.Context) error {
+	privs, counter := ctx.GetPrivilegeSet()
+	if counter == 0 {
+		return fmt.Errorf("unable to check user privileges for %s", doltdb.RowPoliciesTableName)
+	}
+	if privs.Has(sql.PrivilegeType_Super) || privs.Has(sql.PrivilegeType_GrantOption) {
+		return nil
+	}
+	return ErrRowPoliciesWriteDenied.New(ctx.Session.Client().User)
+}
+
+// ValidateRowPoliciesChange returns an error if dolt_row_policies differs between |before| and |after| and the user
+// of |ctx| may not change row policies.
+func ValidateRowPoliciesChange(ctx *sql.Context, before, after doltdb.RootValue) error {
+	policiesHash := func(root doltdb.RootValue) (hash.Hash, error) {
+		tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: doltdb.RowPoliciesTableName})
+		if err != nil || !ok {
+			return hash.Hash{}, err
+		}
+		return tbl.HashOf()
+	}
+	beforeHash, err := policiesHash(before)
+	if err != nil {
+		return err
+	}
+	afterHash, err := policiesHash(after)
+	if err != nil {
+		return err
+	}
+	if beforeHash == afterHash {
+		return nil
+	}
+	return AuthorizeRowPoliciesWrite(ctx)
+}
+
+// RowPoliciesRoot returns the root that the row policies of |db| are read from: the persisted working root of the
+// database's default branch. Policies aren't read from the root being queried, since revision databases, AS OF
+// queries, branches created before a policy and uncommitted resets of the session's working set could otherwise
+// rewind them to expose hidden rows. If the default branch can't be resolved, the session's root is used.
+func RowPoliciesRoot(ctx *sql.Context, db SqlDatabase) (doltdb.RootValue, error) {
+	// the default branch is read from the base database, as revision databases report their revision as checked out
+	baseName, _ := SplitRevisionDbName(db.Name())
+	baseDb := db
+	if sess, ok := ctx.Session.(*DoltSession); ok {
+		if bdb, ok := sess.Provider().BaseDatabase(ctx, baseName); ok {
+			baseDb = bdb
+		}
+	}
+	head, err := DefaultHead(baseName, baseDb)
+	if err != nil {
+		return nil, err
+	}
+	ddb := db.DbData().Ddb
+	if head == "" || ddb == nil {
+		return db.GetRoot(ctx)
+	}
+
+	branchRef := ref.NewBranchRef(head)
+	wsRef, err := ref.WorkingSetRefForHead(branchRef)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := ddb.ResolveWorkingSet(ctx, wsRef)
+	if err == nil {
+		return ws.WorkingRoot(), nil
+	} else if err != doltdb.ErrWorkingSetNotFound {
+		return nil, err
+	}
+
+	// a branch has no working set until it's checked out, so its policies are those of its head
+	cm, err := ddb.ResolveCommitRef(ctx, branchRef)
+	if err == doltdb.ErrBranchNotFound {
+		return db.GetRoot(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+	return cm.GetRootValue(ctx)
+}
+
+// GetRowPolicyFilter returns the RowPolicyFilter of the table |tableName| for the user of |ctx|, or nil if the table
+// has no row policies. Policies are read from |policyRoot|, which should be the root returned by RowPoliciesRoot,
+// and the table's schema is read from |dataRoot|, which holds the rows being filtered.
+func GetRowPolicyFilter(ctx *sql.Context, policyRoot, dataRoot doltdb.RootValue, tableName doltdb.TableName) (*RowPolicyFilter, error) {
+	schemaHash := func() (hash.Hash, error) {
+		return dataRoot.GetTableSchemaHash(ctx, tableName)
+	}
+	loadSchema := func() (schema.Schema, error) {
+		tbl, ok, err := dataRoot.GetTable(ctx, tableName)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, doltdb.ErrTableNotFound
+		}
+		return tbl.GetSchema(ctx)
+	}
+	return getRowPolicyFilter(ctx, policyRoot, tableName, schemaHash, loadSchema)
+}
+
+// GetRowPolicyFilterForTable returns the RowPolicyFilter of the table |tableName| for the user of |ctx|, or nil if
+// the table has no row policies, for the rows of |tbl|, which may be a version of the table from any root, such as
+// either side of a diff. Policies are read from |policyRoot|, as for GetRowPolicyFilter.
+func GetRowPolicyFilterForTable(ctx *sql.Context, policyRoot doltdb.RootValue, tableName doltdb.TableName, tbl *doltdb.Table) (*RowPolicyFilter, error) {
+	schemaHash := func() (hash.Hash, error) {
+		return tbl.GetSchemaHash(ctx)
+	}
+	loadSchema := func() (schema.Schema, error) {
+		return tbl.GetSchema(ctx)
+	}
+	return getRowPolicyFilter(ctx, policyRoot, tableName, schemaHash, loadSchema)
+}
+
+// GetRowPolicyFilterForSchema returns the RowPolicyFilter of the table |tableName| for the user of |ctx|, or nil if
+// the table has no row policies, for rows with the schema |sch|, such as the base and their rows of merge conflicts.
+// Policies are read from |policyRoot|, as for GetRowPolicyFilter. Since a schema isn't addressed by a hash, the
+// returned filter isn't cached.
+func GetRowPolicyFilterForSchema(ctx *sql.Context, policyRoot doltdb.RootValue, tableName doltdb.TableName, sch schema.Schema) (*RowPolicyFilter, error) {
+	loadSchema := func() (schema.Schema, error) {
+		return sch, nil
+	}
+	return getRowPolicyFilter(ctx, policyRoot, tableName, nil, loadSchema)
+}
+
+// getRowPolicyFilter returns the RowPolicyFilter of the table |tableName| for the user of |ctx| and the rows of the
+// schema returned by |loadSchema|, which is only called if the table has policies. If |schemaHash| is non-nil, it
+// returns the hash of that schema, and the filter is cached.
+func getRowPolicyFilter(
+	ctx *sql.Context,
+	policyRoot doltdb.RootValue,
+	tableName doltdb.TableName,
+	schemaHash func() (hash.Hash, error),
+	loadSchema func() (schema.Schema, error),
+) (*RowPolicyFilter, error) {
+	branchAwareSession := branch_control.GetBranchAwareSession(ctx)
+	// A nil session means we're not in the SQL context, so rows aren't filtered
+	if branchAwareSession == nil {
+		return nil, nil
+	}
+
+	policyTable, ok, err := policyRoot.GetTable(ctx, doltdb.TableName{Name: doltdb.RowPoliciesTableName})
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	key := rowPolicyCacheKey{
+		table: tableName,
+		user:  branchAwareSession.GetUser(),
+		host:  branchAwareSession.GetHost(),
+	}
+	if schemaHash != nil {
+		key.policies, err = policyTable.HashOf()
+		if err != nil {
+			return nil, err
+		}
+		key.schema, err = schemaHash()
+		if err != nil {
+			return nil, err
+		}
+		if filter, ok := rowPolicyCache.Get(key); ok {
+			return filter, nil
+		}
+	}
+
+	policies, err := doltdb.GetRowPoliciesFromTable(ctx, policyTable)
+	if err != nil {
+		return nil, err
+	}
+	policies = policies.ForTable(tableName.Name)
+
+	var filter *RowPolicyFilter
+	if len(policies) > 0 {
+		sch, err := loadSchema()
+		if err != nil {
+			return nil, err
+		}
+		filter, err = newRowPolicyFilter(ctx, tableName.Name, sch, policies, key.user, key.host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if schemaHash != nil {
+		rowPolicyCache.Add(key, filter)
+	}
+	return filter, nil
+}
+
+// newRowPolicyFilter returns a RowPolicyFilter for the rows of the table |tableName| with the schema |sch|, which
+// enforces the |policies| matching |user| and |host|.
+func newRowPolicyFilter(ctx *sql.Context, tableName string, sch schema.Schema, policies doltdb.RowPolicies, user, host string) (*RowPolicyFilter, error) {
+	var exprs []sql.Expression
+	for _, policy := range policies {
+		// users are matched case-sensitively and hosts case-insensitively, as in the branch control tables
+		if !branch_control.MatchesExpression(policy.User, user, sql.Collation_utf8mb4_0900_bin) ||
+			!branch_control.MatchesExpression(strings.ToLower(policy.Host), host, sql.Collation_utf8mb4_0900_ai_ci) {
+			continue
+		}
+		expr, err := expranalysis.ResolveRowPolicyExpression(ctx, tableName, sch, policy.Predicate)
+		if err != nil {
+			return nil, fmt.Errorf("row policy '%s' on table '%s' has an invalid predicate: %w", policy.Name, tableName, err)
+		}
+		exprs = append(exprs, expr)
+	}
+
+	return &RowPolicyFilter{
+		tableName: tableName,
+		user:      user,
+		host:      host,
+		expr:      expression.JoinOr(exprs...),
+	}, nil
+}
+
+// Allows returns whether |row|, which contains every column of the filtered table, is visible to the user.
+func (f *RowPolicyFilter) Allows(ctx *sql.Context, row sql.Row) (bool, error) {
+	if f.expr == nil {
+		return false, nil
+	}
+	res, err := sql.EvaluateCondition(ctx, f.expr, row)
+	if err != nil {
+		return false, err
+	}
+	return sql.IsTrue(res), nil
+}
+
+// CheckWrite returns an error if the user may not write |row|, which contains every column of the filtered table.
+func (f *RowPolicyFilter) CheckWrite(ctx *sql.Context, row sql.Row) error {
+	ok, err := f.Allows(ctx, row)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRowPolicyViolation.New(f.tableName, f.user, f.host)
+	}
+	return nil
+}
+
+// NewRowPolicyIter returns a sql.RowIter of the rows of |iter| that are allowed by |filter|. The rows of |iter| must
+// contain every column of the filtered table. If |projection| is non-nil, the returned rows contain only the columns
+// at those indexes, in that order.
+func NewRowPolicyIter(filter *RowPolicyFilter, iter sql.RowIter, projection []int) sql.RowIter {
+	return &rowPolicyIter{filter: filter, child: iter, projection: projection}
+}
+
+type rowPolicyIter struct {
+	filter     *RowPolicyFilter
+	child      sql.RowIter
+	projection []int
+}
+
+var _ sql.RowIter = (*rowPolicyIter)(nil)
+
+// Next implements the sql.RowIter interface
+func (itr *rowPolicyIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		row, err := itr.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := itr.filter.Allows(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if itr.projection == nil {
+			return row, nil
+		}
+		projected := make(sql.Row, len(itr.projection))
+		for i, idx := range itr.projection {
+			projected[i] = row[idx]
+		}
+		return projected, nil
+	}
+}
+
+// Close implements the sql.RowIter interface
+func (itr *rowPolicyIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
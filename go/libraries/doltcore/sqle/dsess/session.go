@@ -47,6 +47,9 @@ const (
 
 var ErrSessionNotPersistable = errors.New("session is not persistable")
 
+// ErrNoQueryRunner is used when a session wasn't created by an engine that statements can be run on.
+var ErrNoQueryRunner = errors.New("the session has no engine to run statements on")
+
 // DoltSession is the sql.Session implementation used by dolt. It is accessible through a *sql.Context instance
 type DoltSession struct {
 	sql.Session
@@ -63,6 +66,8 @@ type DoltSession struct {
 	mu               *sync.Mutex
 	fs               filesys.Filesys
 	writeSessProv    WriteSessFunc
+	// queryRunner is the engine the session was created for, or nil if the engine didn't set it
+	queryRunner QueryRunner
 
 	// If non-nil, this will be returned from ValidateSession.
 	// Used by sqle/cluster to put a session into a terminal err state.
@@ -124,6 +129,22 @@ func NewDoltSession(
 	return sess, nil
 }
 
+// QueryRunner runs queries on the engine of a session. Stored procedures that run statements of their own use it, so
+// that the statements are parsed and analyzed like the client's own.
+type QueryRunner interface {
+	Query(ctx *sql.Context, query string) (sql.Schema, sql.RowIter, *sql.QueryFlags, error)
+}
+
+// SetQueryRunner sets the engine this session was created for.
+func (d *DoltSession) SetQueryRunner(queryRunner QueryRunner) {
+	d.queryRunner = queryRunner
+}
+
+// QueryRunner returns the engine this session was created for, or nil if it wasn't set.
+func (d *DoltSession) QueryRunner() QueryRunner {
+	return d.queryRunner
+}
+
 // Provider returns the RevisionDatabaseProvider for this session.
 func (d *DoltSession) Provider() DoltDatabaseProvider {
 	return d.provider
@@ -752,6 +773,15 @@ func (d *DoltSession) newPendingCommit(ctx *sql.Context, branchState *branchStat
 			props.Message = meta.Description
 		}
 
+		// Amending replaces the head of the branch with a commit on top of its parent, so it is validated as moving the
+		// branch back to that parent
+		if len(mergeParentCommits) > 0 {
+			err := ValidateBranchUpdate(ctx, branchState.dbData.Ddb, branchState.dbState.dbName, branchState.head, headCommit, mergeParentCommits[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		// TODO: This is not the correct way to write this commit as an amend. While this commit is running
 		//  the branch head moves backwards and concurrency control here is not principled.
 		newRoots, err := actions.ResetSoftToRef(ctx, branchState.dbData, "HEAD~1")
@@ -26,6 +26,8 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/vitess/go/mysql"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
@@ -176,6 +178,7 @@ func (tx *DoltTransaction) Commit(ctx *sql.Context, workingSet *doltdb.WorkingSe
 // transactionWrite is the logic to write an updated working set (and optionally a commit) to the database
 type transactionWrite func(ctx *sql.Context,
 	tx *DoltTransaction, // the transaction being written
+	dbName string, // the name of the database to write to
 	doltDb *doltdb.DoltDB, // the database to write to
 	startState *doltdb.WorkingSet, // the starting working set
 	commit *doltdb.PendingCommit, // optional
@@ -187,6 +190,7 @@ type transactionWrite func(ctx *sql.Context,
 // doltCommit is a transactionWrite function that updates the working set and commits a pending commit atomically
 func doltCommit(ctx *sql.Context,
 	tx *DoltTransaction, // the transaction being written
+	dbName string, // the name of the database to write to
 	doltDb *doltdb.DoltDB, // the database to write to
 	startState *doltdb.WorkingSet, // the starting working set
 	commit *doltdb.PendingCommit, // optional
@@ -261,6 +265,10 @@ func doltCommit(ctx *sql.Context,
 		}
 	}
 
+	if err = validatePendingCommit(ctx, doltDb, dbName, headRef.GetPath(), curHead, &pending); err != nil {
+		return nil, nil, err
+	}
+
 	workingSet = workingSet.ClearMerge()
 
 	var rsc doltdb.ReplicationStatusController
@@ -272,6 +280,7 @@ func doltCommit(ctx *sql.Context,
 // txCommit is a transactionWrite function that updates the working set
 func txCommit(ctx *sql.Context,
 	tx *DoltTransaction, // the transaction being written
+	_ string, // the name of the database to write to
 	doltDb *doltdb.DoltDB, // the database to write to
 	_ *doltdb.WorkingSet, // the starting working set
 	_ *doltdb.PendingCommit, // optional
@@ -370,6 +379,9 @@ func (tx *DoltTransaction) doCommit(
 	writeFn transactionWrite,
 	dbName string,
 ) (*doltdb.WorkingSet, *doltdb.Commit, error) {
+	span, ctx := ctx.Span("dsess.DoltTransaction.doCommit", trace.WithAttributes(attribute.String("database", dbName)))
+	defer span.End()
+
 	sess := DSessFromSess(ctx.Session)
 	branchState, ok, err := sess.lookupDbState(ctx, dbName)
 	if err != nil {
@@ -391,11 +403,16 @@ func (tx *DoltTransaction) doCommit(
 		return nil, nil, err
 	}
 
+	if err = tx.validateRowPoliciesForCommit(ctx, startState, workingSet); err != nil {
+		return nil, nil, err
+	}
+
 	// TODO: no-op if the working set hasn't changed since the transaction started
 
 	mergeOpts := branchState.EditOpts()
 
 	for i := 0; i < maxTxCommitRetries; i++ {
+		span.SetAttributes(attribute.Int("attempts", i+1))
 		updatedWs, newCommit, err := func() (*doltdb.WorkingSet, *doltdb.Commit, error) {
 			// Serialize commits, since only one can possibly succeed at a time anyway
 			txLock.Lock()
@@ -426,7 +443,7 @@ func (tx *DoltTransaction) doCommit(
 				}
 
 				var newCommit *doltdb.Commit
-				workingSet, newCommit, err = writeFn(ctx, tx, startPoint.db, startState, commit, workingSet, existingWSHash, mergeOpts)
+				workingSet, newCommit, err = writeFn(ctx, tx, startPoint.dbName, startPoint.db, startState, commit, workingSet, existingWSHash, mergeOpts)
 				if err == datas.ErrOptimisticLockFailed {
 					// this is effectively a `continue` in the loop
 					return nil, nil, nil
@@ -451,7 +468,7 @@ func (tx *DoltTransaction) doCommit(
 			}
 
 			var newCommit *doltdb.Commit
-			mergedWorkingSet, newCommit, err = writeFn(ctx, tx, startPoint.db, startState, commit, mergedWorkingSet, existingWSHash, mergeOpts)
+			mergedWorkingSet, newCommit, err = writeFn(ctx, tx, startPoint.dbName, startPoint.db, startState, commit, mergedWorkingSet, existingWSHash, mergeOpts)
 			if err == datas.ErrOptimisticLockFailed {
 				// this is effectively a `continue` in the loop
 				return nil, nil, nil
@@ -483,6 +500,8 @@ func (tx *DoltTransaction) mergeRoots(
 	workingSet *doltdb.WorkingSet,
 	mergeOpts editor.Options,
 ) (*doltdb.WorkingSet, error) {
+	span, ctx := ctx.Span("dsess.DoltTransaction.mergeRoots")
+	defer span.End()
 
 	if !rootsEqual(existingWorkingSet.WorkingRoot(), workingSet.WorkingRoot()) {
 		result, err := merge.MergeRoots(
@@ -536,6 +555,23 @@ func (tx *DoltTransaction) rollback(ctx *sql.Context) error {
 	return nil
 }
 
+// validateRowPoliciesForCommit returns an error, and rolls back the transaction, if |workingSet| changes
+// dolt_row_policies from |startState| and the user of |ctx| may not change row policies. This covers every operation
+// that changes them in the working set, such as resets, merges and reverts, not only writes to the table. Sessions
+// that haven't been authorized for any statement, such as internal sessions, aren't checked.
+func (tx *DoltTransaction) validateRowPoliciesForCommit(ctx *sql.Context, startState, workingSet *doltdb.WorkingSet) error {
+	if _, counter := ctx.GetPrivilegeSet(); counter == 0 {
+		return nil
+	}
+	err := ValidateRowPoliciesChange(ctx, startState.WorkingRoot(), workingSet.WorkingRoot())
+	if err != nil && ErrRowPoliciesWriteDenied.Is(err) {
+		if rollbackErr := tx.rollback(ctx); rollbackErr != nil {
+			return rollbackErr
+		}
+	}
+	return err
+}
+
 type ffMerge bool
 
 const (
@@ -0,0 +1,225 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtablefunctions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+const blameCellsDefaultRowCount = 100
+
+var _ sql.TableFunction = (*BlameCellsTableFunction)(nil)
+var _ sql.ExecSourceRel = (*BlameCellsTableFunction)(nil)
+
+// BlameCellsTableFunction is the dolt_blame_cells() table function. It returns the same rows as the
+// dolt_blame_cells_<table> system table, and accepts the same options as `dolt blame --columns`: --since and --until
+// revisions that bound the history searched, and --follow to follow columns across renames.
+type BlameCellsTableFunction struct {
+	ctx      *sql.Context
+	database sql.Database
+
+	argExprs   []sql.Expression
+	tableName  string
+	blameTable sql.Table
+	sqlSch     sql.Schema
+}
+
+// NewInstance creates a new instance of TableFunction interface
+func (btf *BlameCellsTableFunction) NewInstance(ctx *sql.Context, db sql.Database, expressions []sql.Expression) (sql.Node, error) {
+	newInstance := &BlameCellsTableFunction{
+		ctx:      ctx,
+		database: db,
+	}
+	node, err := newInstance.WithExpressions(expressions...)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Database implements the sql.Databaser interface
+func (btf *BlameCellsTableFunction) Database() sql.Database {
+	return btf.database
+}
+
+// WithDatabase implements the sql.Databaser interface
+func (btf *BlameCellsTableFunction) WithDatabase(database sql.Database) (sql.Node, error) {
+	nbtf := *btf
+	nbtf.database = database
+	return &nbtf, nil
+}
+
+func (btf *BlameCellsTableFunction) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(btf.Schema())
+	numRows, _, err := btf.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (btf *BlameCellsTableFunction) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return blameCellsDefaultRowCount, false, nil
+}
+
+// Name implements the sql.TableFunction interface
+func (btf *BlameCellsTableFunction) Name() string {
+	return "dolt_blame_cells"
+}
+
+// String implements the Stringer interface
+func (btf *BlameCellsTableFunction) String() string {
+	args := make([]string, len(btf.argExprs))
+	for i, expr := range btf.argExprs {
+		args[i] = expr.String()
+	}
+	return fmt.Sprintf("DOLT_BLAME_CELLS(%s)", strings.Join(args, ", "))
+}
+
+// Resolved implements the sql.Resolvable interface
+func (btf *BlameCellsTableFunction) Resolved() bool {
+	for _, expr := range btf.argExprs {
+		if !expr.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsReadOnly implements the sql.Node interface
+func (btf *BlameCellsTableFunction) IsReadOnly() bool {
+	return true
+}
+
+// Schema implements the sql.Node interface
+func (btf *BlameCellsTableFunction) Schema() sql.Schema {
+	return btf.sqlSch
+}
+
+// Children implements the sql.Node interface
+func (btf *BlameCellsTableFunction) Children() []sql.Node {
+	return nil
+}
+
+// WithChildren implements the sql.Node interface
+func (btf *BlameCellsTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, fmt.Errorf("unexpected children")
+	}
+	return btf, nil
+}
+
+// CheckPrivileges implements the sql.Node interface
+func (btf *BlameCellsTableFunction) CheckPrivileges(ctx *sql.Context, opChecker sql.PrivilegedOperationChecker) bool {
+	subject := sql.PrivilegeCheckSubject{Database: btf.database.Name(), Table: btf.tableName}
+	return opChecker.UserHasPrivileges(ctx, sql.NewPrivilegedOperation(subject, sql.PrivilegeType_Select))
+}
+
+// Expressions implements the sql.Expressioner interface
+func (btf *BlameCellsTableFunction) Expressions() []sql.Expression {
+	return btf.argExprs
+}
+
+// WithExpressions implements the sql.Expressioner interface
+func (btf *BlameCellsTableFunction) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	for _, expr := range exprs {
+		if !expr.Resolved() {
+			return nil, ErrInvalidNonLiteralArgument.New(btf.Name(), expr.String())
+		}
+		// prepared statements resolve functions beforehand, so above check fails
+		if _, ok := expr.(sql.FunctionExpression); ok {
+			return nil, ErrInvalidNonLiteralArgument.New(btf.Name(), expr.String())
+		}
+	}
+
+	args, err := getDoltArgs(btf.ctx, exprs, btf.Name())
+	if err != nil {
+		return nil, err
+	}
+	apr, err := cli.CreateBlameArgParser(true).Parse(args)
+	if err != nil {
+		return nil, sql.ErrInvalidArgumentDetails.New(btf.Name(), err.Error())
+	}
+	if apr.NArg() != 1 {
+		return nil, sql.ErrInvalidArgumentNumber.New(btf.Name(), 1, apr.NArg())
+	}
+
+	newBtf := *btf
+	newBtf.argExprs = exprs
+	newBtf.tableName = apr.Arg(0)
+
+	sqledb, ok := btf.database.(dsess.SqlDatabase)
+	if !ok {
+		return nil, fmt.Errorf("unexpected database type: %T", btf.database)
+	}
+	ddb := sqledb.DbData().Ddb
+	sess := dsess.DSessFromSess(btf.ctx.Session)
+	headRef, err := sess.CWBHeadRef(btf.ctx, sqledb.RevisionQualifiedName())
+	if err != nil {
+		return nil, err
+	}
+
+	var head *doltdb.Commit
+	if until, ok := apr.GetValue(cli.UntilFlag); ok {
+		head, err = resolveCommit(btf.ctx, ddb, headRef, until)
+	} else {
+		head, err = sess.GetHeadCommit(btf.ctx, sqledb.RevisionQualifiedName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opts := dtables.BlameCellsOptions{Follow: apr.Contains(cli.FollowFlag)}
+	if since, ok := apr.GetValue(cli.SinceFlag); ok {
+		if opts.Since, err = resolveCommit(btf.ctx, ddb, headRef, since); err != nil {
+			return nil, err
+		}
+	}
+
+	policyRoot, err := dsess.RowPoliciesRoot(btf.ctx, sqledb)
+	if err != nil {
+		return nil, err
+	}
+	tbl, ok, err := dtables.NewBlameCellsTable(btf.ctx, newBtf.tableName, ddb, head, policyRoot, opts)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(newBtf.tableName)
+	}
+	newBtf.blameTable = tbl
+
+	// the columns of a table function's schema have no source, as there is no table they come from
+	newBtf.sqlSch = make(sql.Schema, len(tbl.Schema()))
+	for i, col := range tbl.Schema() {
+		c := *col
+		c.Source = ""
+		newBtf.sqlSch[i] = &c
+	}
+	return &newBtf, nil
+}
+
+// RowIter implements the sql.Node interface
+func (btf *BlameCellsTableFunction) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter, error) {
+	return btf.blameTable.PartitionRows(ctx, nil)
+}
@@ -187,7 +187,11 @@ func (dtf *DiffTableFunction) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter,
 	ddb := sqledb.DbData().Ddb
 	dp := dtables.NewDiffPartition(dtf.tableDelta.ToTable, dtf.tableDelta.FromTable, toCommitStr, fromCommitStr, dtf.toDate, dtf.fromDate, dtf.tableDelta.ToSch, dtf.tableDelta.FromSch)
 
-	return dtables.NewDiffPartitionRowIter(dp, ddb, dtf.joiner), nil
+	policyRoot, err := dsess.RowPoliciesRoot(ctx, sqledb)
+	if err != nil {
+		return nil, err
+	}
+	return dtables.NewRowPolicyTableDeltaIter(ctx, policyRoot, dtf.tableDelta, dtables.NewDiffPartitionRowIter(dp, ddb, dtf.joiner))
 }
 
 // findMatchingDelta returns the best matching table delta for the table name
@@ -0,0 +1,300 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtablefunctions
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+const (
+	historyWindowDefaultRowCount = 1000
+
+	// HistoryWindowBetween, HistoryWindowFrom and HistoryWindowContainedIn are the modes of the dolt_history_window()
+	// table function, named after the FOR SYSTEM_TIME clauses they implement
+	HistoryWindowBetween     = "between"
+	HistoryWindowFrom        = "from"
+	HistoryWindowContainedIn = "contained in"
+)
+
+var ErrInvalidHistoryWindowBound = errors.NewKind("invalid bound for %s: %v is neither a date nor a commit")
+var ErrInvalidHistoryWindowMode = errors.NewKind("invalid mode for %s: %s, expected one of 'between', 'from' or 'contained in'")
+
+var _ sql.TableFunction = (*HistoryWindowTableFunction)(nil)
+var _ sql.ExecSourceRel = (*HistoryWindowTableFunction)(nil)
+
+// HistoryWindowTableFunction is the dolt_history_window() table function, which returns the versions of the rows of a
+// table that were valid within a window of its commit history, along with the period each version was valid for.
+// Its arguments are the name of the table, the start and the end of the window, and an optional mode, which selects
+// the versions like the FOR SYSTEM_TIME clause of the same name. The bounds of the window are dates, or commits whose
+// dates are used, and a NULL bound leaves that side of the window unbounded. Queries of a table FOR SYSTEM_TIME
+// BETWEEN, FROM ... TO, CONTAINED IN or ALL are rewritten to this table function.
+type HistoryWindowTableFunction struct {
+	ctx      *sql.Context
+	database sql.Database
+
+	argExprs  []sql.Expression
+	tableName string
+	sqlSch    sql.Schema
+}
+
+// NewInstance creates a new instance of TableFunction interface
+func (htf *HistoryWindowTableFunction) NewInstance(ctx *sql.Context, db sql.Database, expressions []sql.Expression) (sql.Node, error) {
+	newInstance := &HistoryWindowTableFunction{
+		ctx:      ctx,
+		database: db,
+	}
+	node, err := newInstance.WithExpressions(expressions...)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Database implements the sql.Databaser interface
+func (htf *HistoryWindowTableFunction) Database() sql.Database {
+	return htf.database
+}
+
+// WithDatabase implements the sql.Databaser interface
+func (htf *HistoryWindowTableFunction) WithDatabase(database sql.Database) (sql.Node, error) {
+	nhtf := *htf
+	nhtf.database = database
+	return &nhtf, nil
+}
+
+func (htf *HistoryWindowTableFunction) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(htf.Schema())
+	numRows, _, err := htf.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (htf *HistoryWindowTableFunction) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return historyWindowDefaultRowCount, false, nil
+}
+
+// Name implements the sql.TableFunction interface
+func (htf *HistoryWindowTableFunction) Name() string {
+	return "dolt_history_window"
+}
+
+// String implements the Stringer interface
+func (htf *HistoryWindowTableFunction) String() string {
+	args := make([]string, len(htf.argExprs))
+	for i, expr := range htf.argExprs {
+		args[i] = expr.String()
+	}
+	return fmt.Sprintf("DOLT_HISTORY_WINDOW(%s)", strings.Join(args, ", "))
+}
+
+// Resolved implements the sql.Resolvable interface
+func (htf *HistoryWindowTableFunction) Resolved() bool {
+	for _, expr := range htf.argExprs {
+		if !expr.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsReadOnly implements the sql.Node interface
+func (htf *HistoryWindowTableFunction) IsReadOnly() bool {
+	return true
+}
+
+// Schema implements the sql.Node interface
+func (htf *HistoryWindowTableFunction) Schema() sql.Schema {
+	return htf.sqlSch
+}
+
+// Children implements the sql.Node interface
+func (htf *HistoryWindowTableFunction) Children() []sql.Node {
+	return nil
+}
+
+// WithChildren implements the sql.Node interface
+func (htf *HistoryWindowTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, fmt.Errorf("unexpected children")
+	}
+	return htf, nil
+}
+
+// CheckPrivileges implements the sql.Node interface
+func (htf *HistoryWindowTableFunction) CheckPrivileges(ctx *sql.Context, opChecker sql.PrivilegedOperationChecker) bool {
+	subject := sql.PrivilegeCheckSubject{Database: htf.database.Name(), Table: htf.tableName}
+	return opChecker.UserHasPrivileges(ctx, sql.NewPrivilegedOperation(subject, sql.PrivilegeType_Select))
+}
+
+// Expressions implements the sql.Expressioner interface
+func (htf *HistoryWindowTableFunction) Expressions() []sql.Expression {
+	return htf.argExprs
+}
+
+// WithExpressions implements the sql.Expressioner interface
+func (htf *HistoryWindowTableFunction) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) < 3 || len(exprs) > 4 {
+		return nil, sql.ErrInvalidArgumentNumber.New(htf.Name(), "3 to 4", len(exprs))
+	}
+	for _, expr := range exprs {
+		if !expr.Resolved() {
+			return nil, ErrInvalidNonLiteralArgument.New(htf.Name(), expr.String())
+		}
+	}
+	// The schema depends on the table, so its name must be known before the query is executed, but the bounds of
+	// the window may be any expression, like NOW() - INTERVAL 1 DAY
+	tableNameExpr := exprs[0]
+	if _, ok := tableNameExpr.(sql.FunctionExpression); ok {
+		return nil, ErrInvalidNonLiteralArgument.New(htf.Name(), tableNameExpr.String())
+	}
+	if !gmstypes.IsText(tableNameExpr.Type()) {
+		return nil, sql.ErrInvalidArgumentDetails.New(htf.Name(), tableNameExpr.String())
+	}
+	tableNameVal, err := tableNameExpr.Eval(htf.ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	tableName, ok := tableNameVal.(string)
+	if !ok {
+		return nil, ErrInvalidTableName.New(tableNameExpr.String())
+	}
+
+	newHtf := *htf
+	newHtf.argExprs = exprs
+	newHtf.tableName = tableName
+
+	sqledb, ok := htf.database.(dsess.SqlDatabase)
+	if !ok {
+		return nil, fmt.Errorf("unexpected database type: %T", htf.database)
+	}
+	sess := dsess.DSessFromSess(htf.ctx.Session)
+	head, err := sess.GetHeadCommit(htf.ctx, sqledb.RevisionQualifiedName())
+	if err != nil {
+		return nil, err
+	}
+	root, err := head.GetRootValue(htf.ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, _, ok, err := doltdb.GetTableInsensitive(htf.ctx, root, doltdb.TableName{Name: tableName})
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(tableName)
+	}
+	sch, err := tbl.GetSchema(htf.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if newHtf.sqlSch, err = dtables.HistoryWindowSchema(sch); err != nil {
+		return nil, err
+	}
+	return &newHtf, nil
+}
+
+// RowIter implements the sql.Node interface
+func (htf *HistoryWindowTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	sqledb, ok := htf.database.(dsess.SqlDatabase)
+	if !ok {
+		return nil, fmt.Errorf("unexpected database type: %T", htf.database)
+	}
+	ddb := sqledb.DbData().Ddb
+	sess := dsess.DSessFromSess(ctx.Session)
+	head, err := sess.GetHeadCommit(ctx, sqledb.RevisionQualifiedName())
+	if err != nil {
+		return nil, err
+	}
+
+	var window dtables.HistoryWindow
+	if window.Start, err = htf.evaluateBound(ctx, sqledb, htf.argExprs[1], row); err != nil {
+		return nil, err
+	}
+	if window.End, err = htf.evaluateBound(ctx, sqledb, htf.argExprs[2], row); err != nil {
+		return nil, err
+	}
+	if len(htf.argExprs) == 4 {
+		modeVal, err := htf.argExprs[3].Eval(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		mode, _ := modeVal.(string)
+		switch strings.ToLower(mode) {
+		case HistoryWindowBetween:
+			window.Mode = dtables.HistoryWindowBetween
+		case HistoryWindowFrom:
+			window.Mode = dtables.HistoryWindowFromTo
+		case HistoryWindowContainedIn:
+			window.Mode = dtables.HistoryWindowContainedIn
+		default:
+			return nil, ErrInvalidHistoryWindowMode.New(htf.Name(), modeVal)
+		}
+	}
+
+	policyRoot, err := dsess.RowPoliciesRoot(ctx, sqledb)
+	if err != nil {
+		return nil, err
+	}
+	return dtables.NewHistoryWindowRowIter(ctx, ddb, head, htf.tableName, policyRoot, window)
+}
+
+// evaluateBound returns the time of a bound of the window, which is nil if the bound is NULL. A string that isn't a
+// date is resolved as a commit, and the date of the commit is used.
+func (htf *HistoryWindowTableFunction) evaluateBound(ctx *sql.Context, sqledb dsess.SqlDatabase, expr sql.Expression, row sql.Row) (*time.Time, error) {
+	val, err := expr.Eval(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &v, nil
+	case string:
+		if t, _, err := gmstypes.DatetimeMaxPrecision.Convert(v); err == nil {
+			tm := t.(time.Time)
+			return &tm, nil
+		}
+		sess := dsess.DSessFromSess(ctx.Session)
+		headRef, err := sess.CWBHeadRef(ctx, sqledb.RevisionQualifiedName())
+		if err != nil {
+			return nil, err
+		}
+		cm, err := resolveCommit(ctx, sqledb.DbData().Ddb, headRef, v)
+		if err != nil {
+			return nil, ErrInvalidHistoryWindowBound.New(htf.Name(), v)
+		}
+		meta, err := cm.GetCommitMeta(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t := meta.Time()
+		return &t, nil
+	default:
+		return nil, ErrInvalidHistoryWindowBound.New(htf.Name(), v)
+	}
+}
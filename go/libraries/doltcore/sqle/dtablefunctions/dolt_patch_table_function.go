@@ -167,7 +167,12 @@ func (p *PatchTableFunction) PartitionRows(ctx *sql.Context, partition sql.Parti
 	includeSchemaDiff := bytes.Equal(partition.Key(), schemaAndDataChangePartitionKey) || bytes.Equal(partition.Key(), schemaChangePartitionKey)
 	includeDataDiff := bytes.Equal(partition.Key(), schemaAndDataChangePartitionKey) || bytes.Equal(partition.Key(), dataChangePartitionKey)
 
-	patches, err := getPatchNodes(ctx, sqledb.DbData(), tableDeltas, fromRefDetails, toRefDetails, includeSchemaDiff, includeDataDiff)
+	policyRoot, err := dsess.RowPoliciesRoot(ctx, sqledb)
+	if err != nil {
+		return nil, err
+	}
+
+	patches, err := getPatchNodes(ctx, sqledb.DbData(), policyRoot, tableDeltas, fromRefDetails, toRefDetails, includeSchemaDiff, includeDataDiff)
 	if err != nil {
 		return nil, err
 	}
@@ -483,7 +488,7 @@ type patchNode struct {
 	dataPatchStmts   []string
 }
 
-func getPatchNodes(ctx *sql.Context, dbData env.DbData, tableDeltas []diff.TableDelta, fromRefDetails, toRefDetails *refDetails, includeSchemaDiff, includeDataDiff bool) (patches []*patchNode, err error) {
+func getPatchNodes(ctx *sql.Context, dbData env.DbData, policyRoot doltdb.RootValue, tableDeltas []diff.TableDelta, fromRefDetails, toRefDetails *refDetails, includeSchemaDiff, includeDataDiff bool) (patches []*patchNode, err error) {
 	for _, td := range tableDeltas {
 		if td.FromTable == nil && td.ToTable == nil {
 			// no diff
@@ -526,7 +531,7 @@ func getPatchNodes(ctx *sql.Context, dbData env.DbData, tableDeltas []diff.Table
 		// Get DATA DIFF
 		var dataStmts []string
 		if includeDataDiff && canGetDataDiff(ctx, td) {
-			dataStmts, err = getUserTableDataSqlPatch(ctx, dbData, td, fromRefDetails, toRefDetails)
+			dataStmts, err = getUserTableDataSqlPatch(ctx, dbData, policyRoot, td, fromRefDetails, toRefDetails)
 			if err != nil {
 				return nil, err
 			}
@@ -556,9 +561,9 @@ func canGetDataDiff(ctx *sql.Context, td diff.TableDelta) bool {
 	return true
 }
 
-func getUserTableDataSqlPatch(ctx *sql.Context, dbData env.DbData, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) ([]string, error) {
+func getUserTableDataSqlPatch(ctx *sql.Context, dbData env.DbData, policyRoot doltdb.RootValue, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) ([]string, error) {
 	// ToTable is used as target table as it cannot be nil at this point
-	diffSch, projections, ri, err := getDiffQuery(ctx, dbData, td, fromRefDetails, toRefDetails)
+	diffSch, projections, ri, err := getDiffQuery(ctx, dbData, policyRoot, td, fromRefDetails, toRefDetails)
 	if err != nil {
 		return nil, err
 	}
@@ -620,8 +625,9 @@ func getDataSqlPatchResults(ctx *sql.Context, diffQuerySch, targetSch sql.Schema
 // getDiffQuery returns diff schema for specified columns and array of sql.Expression as projection to be used
 // on diff table function row iter. This function attempts to imitate running a query
 // fmt.Sprintf("select %s, %s from dolt_diff('%s', '%s', '%s')", columnsWithDiff, "diff_type", fromRef, toRef, tableName)
-// on sql engine, which returns the schema and rowIter of the final data diff result.
-func getDiffQuery(ctx *sql.Context, dbData env.DbData, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) (sql.Schema, []sql.Expression, sql.RowIter, error) {
+// on sql engine, which returns the schema and rowIter of the final data diff result. The rows are filtered by the row
+// policies in |policyRoot|.
+func getDiffQuery(ctx *sql.Context, dbData env.DbData, policyRoot doltdb.RootValue, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) (sql.Schema, []sql.Expression, sql.RowIter, error) {
 	diffTableSchema, j, err := dtables.GetDiffTableSchemaAndJoiner(td.ToTable.Format(), td.FromSch, td.ToSch)
 	if err != nil {
 		return nil, nil, nil, err
@@ -635,7 +641,10 @@ func getDiffQuery(ctx *sql.Context, dbData env.DbData, td diff.TableDelta, fromR
 	diffQuerySqlSch, projections := getDiffQuerySqlSchemaAndProjections(diffPKSch.Schema, columnsWithDiff)
 
 	dp := dtables.NewDiffPartition(td.ToTable, td.FromTable, toRefDetails.hashStr, fromRefDetails.hashStr, toRefDetails.commitTime, fromRefDetails.commitTime, td.ToSch, td.FromSch)
-	ri := dtables.NewDiffPartitionRowIter(dp, dbData.Ddb, j)
+	ri, err := dtables.NewRowPolicyTableDeltaIter(ctx, policyRoot, td, dtables.NewDiffPartitionRowIter(dp, dbData.Ddb, j))
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
 	return diffQuerySqlSch, projections, ri, nil
 }
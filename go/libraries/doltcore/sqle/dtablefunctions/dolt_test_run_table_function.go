@@ -0,0 +1,376 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtablefunctions
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+const testRunDefaultRowCount = 10
+
+const (
+	// TestStatusPass is the status of a test whose assertion held.
+	TestStatusPass = "PASS"
+	// TestStatusFail is the status of a test whose assertion didn't hold, or whose query failed.
+	TestStatusFail = "FAIL"
+)
+
+var _ sql.TableFunction = (*TestRunTableFunction)(nil)
+var _ sql.CatalogTableFunction = (*TestRunTableFunction)(nil)
+var _ sql.ExecSourceRel = (*TestRunTableFunction)(nil)
+
+// TestRunTableFunction is the dolt_test_run() table function. It runs the tests stored in the dolt_tests system table
+// of a revision against the same revision, and returns a row with the status of each test. It accepts the same
+// arguments as `dolt test run`: an optional --group to run, and an optional revision, which defaults to the current
+// revision.
+type TestRunTableFunction struct {
+	ctx      *sql.Context
+	database sql.Database
+	catalog  sql.Catalog
+
+	argExprs []sql.Expression
+	group    string
+	revision string
+}
+
+var testRunTableSchema = sql.Schema{
+	&sql.Column{Name: "test_name", Type: types.Text},
+	&sql.Column{Name: "test_group", Type: types.Text, Nullable: true},
+	&sql.Column{Name: "test_query", Type: types.Text},
+	&sql.Column{Name: "status", Type: types.Text},
+	&sql.Column{Name: "message", Type: types.Text, Nullable: true},
+}
+
+// NewInstance creates a new instance of TableFunction interface
+func (trtf *TestRunTableFunction) NewInstance(ctx *sql.Context, db sql.Database, expressions []sql.Expression) (sql.Node, error) {
+	newInstance := &TestRunTableFunction{
+		ctx:      ctx,
+		database: db,
+	}
+	node, err := newInstance.WithExpressions(expressions...)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// WithCatalog implements the sql.CatalogTableFunction interface
+func (trtf *TestRunTableFunction) WithCatalog(c sql.Catalog) (sql.TableFunction, error) {
+	newInstance := *trtf
+	newInstance.catalog = c
+	return &newInstance, nil
+}
+
+// Database implements the sql.Databaser interface
+func (trtf *TestRunTableFunction) Database() sql.Database {
+	return trtf.database
+}
+
+// WithDatabase implements the sql.Databaser interface
+func (trtf *TestRunTableFunction) WithDatabase(database sql.Database) (sql.Node, error) {
+	ntf := *trtf
+	ntf.database = database
+	return &ntf, nil
+}
+
+func (trtf *TestRunTableFunction) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(trtf.Schema())
+	numRows, _, err := trtf.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (trtf *TestRunTableFunction) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return testRunDefaultRowCount, false, nil
+}
+
+// Name implements the sql.TableFunction interface
+func (trtf *TestRunTableFunction) Name() string {
+	return "dolt_test_run"
+}
+
+// String implements the Stringer interface
+func (trtf *TestRunTableFunction) String() string {
+	args := make([]string, len(trtf.argExprs))
+	for i, expr := range trtf.argExprs {
+		args[i] = expr.String()
+	}
+	return fmt.Sprintf("DOLT_TEST_RUN(%s)", strings.Join(args, ", "))
+}
+
+// Resolved implements the sql.Resolvable interface
+func (trtf *TestRunTableFunction) Resolved() bool {
+	for _, expr := range trtf.argExprs {
+		if !expr.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsReadOnly implements the sql.Node interface. Test queries are required to be SELECT statements.
+func (trtf *TestRunTableFunction) IsReadOnly() bool {
+	return true
+}
+
+// Schema implements the sql.Node interface
+func (trtf *TestRunTableFunction) Schema() sql.Schema {
+	return testRunTableSchema
+}
+
+// Children implements the sql.Node interface
+func (trtf *TestRunTableFunction) Children() []sql.Node {
+	return nil
+}
+
+// WithChildren implements the sql.Node interface
+func (trtf *TestRunTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, fmt.Errorf("unexpected children")
+	}
+	return trtf, nil
+}
+
+// CheckPrivileges implements the sql.Node interface
+func (trtf *TestRunTableFunction) CheckPrivileges(ctx *sql.Context, opChecker sql.PrivilegedOperationChecker) bool {
+	subject := sql.PrivilegeCheckSubject{Database: trtf.database.Name()}
+	return opChecker.UserHasPrivileges(ctx, sql.NewPrivilegedOperation(subject, sql.PrivilegeType_Select))
+}
+
+// Expressions implements the sql.Expressioner interface
+func (trtf *TestRunTableFunction) Expressions() []sql.Expression {
+	return trtf.argExprs
+}
+
+// WithExpressions implements the sql.Expressioner interface
+func (trtf *TestRunTableFunction) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	for _, expr := range exprs {
+		if !expr.Resolved() {
+			return nil, ErrInvalidNonLiteralArgument.New(trtf.Name(), expr.String())
+		}
+		// prepared statements resolve functions beforehand, so above check fails
+		if _, ok := expr.(sql.FunctionExpression); ok {
+			return nil, ErrInvalidNonLiteralArgument.New(trtf.Name(), expr.String())
+		}
+	}
+
+	args, err := getDoltArgs(trtf.ctx, exprs, trtf.Name())
+	if err != nil {
+		return nil, err
+	}
+	apr, err := cli.CreateTestRunArgParser().Parse(args)
+	if err != nil {
+		return nil, sql.ErrInvalidArgumentDetails.New(trtf.Name(), err.Error())
+	}
+
+	newTrtf := *trtf
+	newTrtf.argExprs = exprs
+	newTrtf.group = apr.GetValueOrDefault(cli.GroupFlag, "")
+	newTrtf.revision = ""
+	if apr.NArg() > 0 {
+		newTrtf.revision = apr.Arg(0)
+	}
+	return &newTrtf, nil
+}
+
+// RowIter implements the sql.Node interface
+func (trtf *TestRunTableFunction) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter, error) {
+	if trtf.catalog == nil {
+		return nil, fmt.Errorf("%s has no catalog", trtf.Name())
+	}
+	// tests run on the engine of the session, so that their results are the results users see for the same queries
+	engine := dsess.DSessFromSess(ctx.Session).QueryRunner()
+	if engine == nil {
+		return nil, dsess.ErrNoQueryRunner
+	}
+
+	// tests and their queries are run with the revision's database as the current database, so that the
+	// unqualified table names of test queries refer to the revision's tables
+	dbName := trtf.database.Name()
+	if trtf.revision != "" {
+		var err error
+		if dbName, err = trtf.revisionDbName(ctx, dbName); err != nil {
+			return nil, err
+		}
+	}
+	prevDb := ctx.GetCurrentDatabase()
+	ctx.SetCurrentDatabase(dbName)
+	defer ctx.SetCurrentDatabase(prevDb)
+
+	tests, err := trtf.loadTests(ctx, engine)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, lt := range tests {
+		t := lt.test
+		status, msg := TestStatusFail, ""
+		if lt.err != nil {
+			msg = lt.err.Error()
+		} else {
+			status, msg = trtf.runTest(ctx, engine, t)
+		}
+		var group, message interface{}
+		if t.Group != "" {
+			group = t.Group
+		}
+		if msg != "" {
+			message = msg
+		}
+		rows = append(rows, sql.Row{t.Name, group, t.Query, status, message})
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// revisionDbName returns the name of the revision database of |dbName| for the revision being tested. Branches, tags
+// and commit hashes name revision databases directly, and other revisions, such as HEAD~1, are resolved to a commit.
+func (trtf *TestRunTableFunction) revisionDbName(ctx *sql.Context, dbName string) (string, error) {
+	baseName, _ := dsess.SplitRevisionDbName(dbName)
+	revDbName := dsess.RevisionDbName(baseName, trtf.revision)
+	if trtf.catalog.HasDatabase(ctx, revDbName) {
+		return revDbName, nil
+	}
+
+	sess := dsess.DSessFromSess(ctx.Session)
+	ddb, ok := sess.GetDoltDB(ctx, dbName)
+	if !ok {
+		return "", sql.ErrDatabaseNotFound.New(dbName)
+	}
+	headRef, err := sess.CWBHeadRef(ctx, dbName)
+	if err != nil {
+		return "", err
+	}
+	cs, err := doltdb.NewCommitSpec(trtf.revision)
+	if err != nil {
+		return "", err
+	}
+	optCmt, err := ddb.Resolve(ctx, cs, headRef)
+	if err != nil {
+		return "", err
+	}
+	cm, ok := optCmt.ToCommit()
+	if !ok {
+		return "", doltdb.ErrGhostCommitEncountered
+	}
+	h, err := cm.HashOf()
+	if err != nil {
+		return "", err
+	}
+	return dsess.RevisionDbName(baseName, h.String()), nil
+}
+
+// loadedTest is a test read from the dolt_tests table, and the reason it's invalid if it is.
+type loadedTest struct {
+	test doltdb.DoltTest
+	err  error
+}
+
+// loadTests reads the tests in the dolt_tests table of the current database that belong to the group being run.
+// Tests that aren't valid are returned with the error that makes them invalid, and fail without being run.
+func (trtf *TestRunTableFunction) loadTests(ctx *sql.Context, engine dsess.QueryRunner) ([]loadedTest, error) {
+	query := fmt.Sprintf("SELECT test_name, test_group, test_query, assertion_type, assertion_comparator, assertion_value FROM %s ORDER BY test_name", doltdb.TestsTableName)
+	_, iter, _, err := engine.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sql.RowIterToRows(ctx, iter)
+	if err != nil {
+		return nil, err
+	}
+
+	var tests []loadedTest
+	for _, r := range rows {
+		vals := make([]string, len(r))
+		for i := range r {
+			vals[i], _ = r[i].(string)
+		}
+		if trtf.group != "" && !strings.EqualFold(vals[1], trtf.group) {
+			continue
+		}
+		t, err := doltdb.NewDoltTest(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5])
+		if err != nil {
+			t = doltdb.DoltTest{Name: vals[0], Group: vals[1], Query: vals[2]}
+		}
+		tests = append(tests, loadedTest{test: t, err: err})
+	}
+	return tests, nil
+}
+
+// runTest runs the query of |t| and checks its assertion, returning the test's status and a message describing why
+// it failed.
+func (trtf *TestRunTableFunction) runTest(ctx *sql.Context, engine dsess.QueryRunner, t doltdb.DoltTest) (string, string) {
+	q := strings.TrimSpace(t.Query)
+	if lower := strings.ToLower(q); !strings.HasPrefix(lower, "select") && !strings.HasPrefix(lower, "with") {
+		return TestStatusFail, "test query must be a SELECT statement"
+	}
+
+	sch, iter, _, err := engine.Query(ctx, q)
+	if err != nil {
+		return TestStatusFail, err.Error()
+	}
+	result, err := testResultStrings(ctx, sch, iter)
+	if err != nil {
+		return TestStatusFail, err.Error()
+	}
+
+	if ok, msg := t.Check(result); !ok {
+		return TestStatusFail, msg
+	}
+	return TestStatusPass, ""
+}
+
+// testResultStrings drains |iter| and returns the string representation of each of its values, with nil for NULL.
+func testResultStrings(ctx *sql.Context, sch sql.Schema, iter sql.RowIter) (result [][]*string, err error) {
+	defer func() {
+		if cerr := iter.Close(ctx); err == nil {
+			err = cerr
+		}
+	}()
+	for {
+		row, err := iter.Next(ctx)
+		if err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		strs := make([]*string, len(row))
+		for i, v := range row {
+			if v == nil {
+				continue
+			}
+			sqlVal, err := sch[i].Type.SQL(ctx, nil, v)
+			if err != nil {
+				return nil, err
+			}
+			s := sqlVal.ToString()
+			strs[i] = &s
+		}
+		result = append(result, strs)
+	}
+}
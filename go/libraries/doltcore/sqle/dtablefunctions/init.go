@@ -25,4 +25,7 @@ var DoltTableFunctions = []sql.TableFunction{
 	&SchemaDiffTableFunction{},
 	&ReflogTableFunction{},
 	&QueryDiffTableFunction{},
+	&TestRunTableFunction{},
+	&BlameCellsTableFunction{},
+	&HistoryWindowTableFunction{},
 }
@@ -0,0 +1,142 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/auditlog"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+// AuditLogTable is a sql.Table implementation that implements a system table which shows the records of the audit
+// log of the sql-server running in this process, oldest first, including those in rotated log files that are still
+// kept. It is empty when no audit log is configured. Since the records cover every database and user, reading them
+// requires the SUPER privilege.
+type AuditLogTable struct {
+	dbName string
+}
+
+var _ sql.Table = (*AuditLogTable)(nil)
+
+// NewAuditLogTable creates an AuditLogTable
+func NewAuditLogTable(dbName string) sql.Table {
+	return &AuditLogTable{dbName: dbName}
+}
+
+func (t *AuditLogTable) Name() string {
+	return doltdb.AuditLogTableName
+}
+
+func (t *AuditLogTable) String() string {
+	return doltdb.AuditLogTableName
+}
+
+func (t *AuditLogTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "time", Type: types.DatetimeMaxPrecision, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "event", Type: types.Text, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "connection_id", Type: types.Uint32, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "user", Type: types.Text, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "host", Type: types.Text, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "database", Type: types.Text, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "branch", Type: types.Text, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "query", Type: types.LongText, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "commit_hash", Type: types.Text, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "rows_affected", Type: types.Uint64, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "rows_returned", Type: types.Uint64, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "duration_millis", Type: types.Int64, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "error", Type: types.Text, Source: doltdb.AuditLogTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+	}
+}
+
+func (t *AuditLogTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+func (t *AuditLogTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+func (t *AuditLogTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	if err := checkSuper(ctx, doltdb.AuditLogTableName); err != nil {
+		return nil, err
+	}
+
+	log := auditlog.ActiveLog()
+	if log == nil {
+		return sql.RowsToRowIter(), nil
+	}
+	r, err := log.NewReader()
+	if err != nil {
+		return nil, err
+	}
+	return &auditLogIter{r: r}, nil
+}
+
+type auditLogIter struct {
+	r *auditlog.Reader
+}
+
+var _ sql.RowIter = (*auditLogIter)(nil)
+
+func (itr *auditLogIter) Next(*sql.Context) (sql.Row, error) {
+	rec, err := itr.r.Next()
+	if err != nil {
+		return nil, err
+	}
+	return sql.NewRow(
+		rec.Time,
+		string(rec.Event),
+		rec.ConnectionID,
+		rec.User,
+		rec.Host,
+		nullIfEmpty(rec.Database),
+		nullIfEmpty(rec.Branch),
+		nullIfEmpty(rec.Query),
+		nullIfEmpty(rec.CommitHash),
+		rec.RowsAffected,
+		rec.RowsReturned,
+		rec.DurationMillis,
+		nullIfEmpty(rec.Error),
+	), nil
+}
+
+func (itr *auditLogIter) Close(*sql.Context) error {
+	return itr.r.Close()
+}
+
+// checkSuper returns an error unless the user of |ctx| has the SUPER privilege, which is required to read |tableName|.
+func checkSuper(ctx *sql.Context, tableName string) error {
+	privs, counter := ctx.GetPrivilegeSet()
+	if counter == 0 {
+		return fmt.Errorf("unable to check user privileges for %s", tableName)
+	}
+	if !privs.Has(sql.PrivilegeType_Super) {
+		return sql.ErrPrivilegeCheckFailed.New(ctx.Session.Client().User)
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
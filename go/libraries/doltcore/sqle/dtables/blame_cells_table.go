@@ -0,0 +1,511 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+var errUnblameableCellsTable = errors.New("unable to blame the cells of a table without a primary key")
+
+// BlameCellsOptions configure how the history of a table is searched by a BlameCellsTable.
+type BlameCellsOptions struct {
+	// Since is the oldest commit searched, if any. Cells that weren't modified after it are attributed to it.
+	Since *doltdb.Commit
+	// Follow matches the columns of the table across commits by their tags, rather than their names, so that cells
+	// keep their history when their column is renamed.
+	Follow bool
+}
+
+// BlameCellsTable is the dolt_blame_cells_<table> system table, and the result of the dolt_blame_cells() table
+// function. It has a row for every cell of the table at a commit, with the commit that last modified the cell.
+//
+// Blame is computed by walking the first-parent history of the commit. For each commit and its parent, the rows of
+// the table are diffed, and every cell that has no commit yet and differs between the two is attributed to the
+// commit. A cell whose column doesn't exist in the parent is attributed to the commit, as are all the remaining cells
+// when the table doesn't exist in the parent or its primary key changed. The walk stops when every cell has a commit,
+// or when it reaches the first commit, Since, or a parent that isn't available in a shallow clone, which are
+// attributed all the remaining cells.
+type BlameCellsTable struct {
+	tableName  string
+	ddb        *doltdb.DoltDB
+	head       *doltdb.Commit
+	policyRoot doltdb.RootValue
+	opts       BlameCellsOptions
+
+	sch     schema.Schema
+	rows    prolly.Map
+	sqlSch  sql.Schema
+	columns []blameColumn
+}
+
+var _ sql.Table = (*BlameCellsTable)(nil)
+
+// blameColumn is a column of a table whose cells are blamed.
+type blameColumn struct {
+	col schema.Column
+	pk  bool
+}
+
+// NewBlameCellsTable returns a BlameCellsTable for the table |tableName| as of the commit |head|, which searches the
+// history of |head| as configured by |opts|. Row policies of the table are read from |policyRoot|. Returns false if
+// the table doesn't exist at |head|.
+func NewBlameCellsTable(ctx *sql.Context, tableName string, ddb *doltdb.DoltDB, head *doltdb.Commit, policyRoot doltdb.RootValue, opts BlameCellsOptions) (sql.Table, bool, error) {
+	root, err := head.GetRootValue(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	tbl, tblName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tableName})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if schema.IsKeyless(sch) {
+		return nil, false, errUnblameableCellsTable
+	}
+	if opts.Since != nil {
+		if err = checkInFirstParentHistory(ctx, ddb, head, opts.Since); err != nil {
+			return nil, false, err
+		}
+	}
+
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	bt := &BlameCellsTable{
+		tableName:  tblName,
+		ddb:        ddb,
+		head:       head,
+		policyRoot: policyRoot,
+		opts:       opts,
+		sch:        sch,
+		rows:       durable.ProllyMapFromIndex(idx),
+	}
+	for _, col := range sch.GetAllCols().GetColumns() {
+		if !col.Virtual {
+			bt.columns = append(bt.columns, blameColumn{col: col, pk: col.IsPartOfPK})
+		}
+	}
+
+	pkSch, err := sqlutil.FromDoltSchema("", bt.Name(), schema.MustSchemaFromCols(sch.GetPKCols()))
+	if err != nil {
+		return nil, false, err
+	}
+	bt.sqlSch = append(pkSch.Schema,
+		&sql.Column{Name: "column_name", Type: types.Text, Source: bt.Name()},
+		&sql.Column{Name: "commit", Type: types.Text, Source: bt.Name()},
+		&sql.Column{Name: "commit_date", Type: types.Datetime, Source: bt.Name()},
+		&sql.Column{Name: "committer", Type: types.Text, Source: bt.Name()},
+		&sql.Column{Name: "email", Type: types.Text, Source: bt.Name()},
+		&sql.Column{Name: "message", Type: types.Text, Source: bt.Name()},
+	)
+	return bt, true, nil
+}
+
+// checkInFirstParentHistory returns an error unless |since| is |head|, or one of the commits in its first-parent
+// history.
+func checkInFirstParentHistory(ctx context.Context, ddb *doltdb.DoltDB, head, since *doltdb.Commit) error {
+	sinceHash, err := since.HashOf()
+	if err != nil {
+		return err
+	}
+	sinceHeight, err := since.Height()
+	if err != nil {
+		return err
+	}
+
+	cm := head
+	for {
+		h, err := cm.HashOf()
+		if err != nil {
+			return err
+		}
+		if h == sinceHash {
+			return nil
+		}
+		height, err := cm.Height()
+		if err != nil {
+			return err
+		}
+		if height <= sinceHeight || cm.NumParents() == 0 {
+			break
+		}
+		optCmt, err := ddb.ResolveParent(ctx, cm, 0)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if cm, ok = optCmt.ToCommit(); !ok {
+			break
+		}
+	}
+	return fmt.Errorf("commit %s is not in the first-parent history of the blamed commit", sinceHash.String())
+}
+
+// Name implements the sql.Table interface
+func (bt *BlameCellsTable) Name() string {
+	return doltdb.DoltBlameCellsTablePrefix + bt.tableName
+}
+
+// String implements the sql.Table interface
+func (bt *BlameCellsTable) String() string {
+	return bt.Name()
+}
+
+// Schema implements the sql.Table interface. The table has the primary key columns of the blamed table, followed by
+// the name of the cell's column and the commit that last modified it.
+func (bt *BlameCellsTable) Schema() sql.Schema {
+	return bt.sqlSch
+}
+
+// Collation implements the sql.Table interface
+func (bt *BlameCellsTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions implements the sql.Table interface
+func (bt *BlameCellsTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows implements the sql.Table interface
+func (bt *BlameCellsTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	headRoot, err := bt.head.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := dsess.GetRowPolicyFilter(ctx, bt.policyRoot, headRoot, doltdb.TableName{Name: bt.tableName})
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := bt.blame(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := bt.rows.IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ki := &keyRecordingIter{MapIter: iter}
+	rowIter := index.NewProllyRowIterForMap(bt.sch, bt.rows, ki, nil)
+
+	var pkOrds []int
+	for i, col := range bt.sch.GetAllCols().GetColumns() {
+		if col.IsPartOfPK {
+			pkOrds = append(pkOrds, i)
+		}
+	}
+
+	var result []sql.Row
+	for {
+		row, err := rowIter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if filter != nil {
+			if ok, err := filter.Allows(ctx, row); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+
+		commits := b.cells[string(ki.key)]
+		for i, col := range bt.columns {
+			c := b.commits[commits[i]]
+			cell := make(sql.Row, 0, len(bt.sqlSch))
+			for _, ord := range pkOrds {
+				cell = append(cell, row[ord])
+			}
+			cell = append(cell, col.col.Name, c.hash, c.meta.Time(), c.meta.Name, c.meta.Email, c.meta.Description)
+			result = append(result, cell)
+		}
+	}
+	return sql.RowsToRowIter(result...), nil
+}
+
+// keyRecordingIter is a prolly.MapIter that records the key of the last row it returned.
+type keyRecordingIter struct {
+	prolly.MapIter
+	key val.Tuple
+}
+
+func (it *keyRecordingIter) Next(ctx context.Context) (val.Tuple, val.Tuple, error) {
+	k, v, err := it.MapIter.Next(ctx)
+	it.key = k
+	return k, v, err
+}
+
+// cellBlame is the commit that last modified each cell of a table.
+type cellBlame struct {
+	// cells maps the key of each row to the index in commits of the commit of each of its blamed columns, or to
+	// unblamed for the cells that haven't been attributed to a commit yet
+	cells map[string][]int
+	// commits are the commits that cells are attributed to
+	commits []blameCommit
+	// remaining is the number of cells that haven't been attributed to a commit yet
+	remaining int
+}
+
+const unblamed = -1
+
+type blameCommit struct {
+	hash string
+	meta *datas.CommitMeta
+}
+
+// attribute attributes the cell of |row| for the blamed column |col| to the commit |c|, if it hasn't been attributed
+// to a commit already.
+func (b *cellBlame) attribute(row []int, col, c int) {
+	if row[col] == unblamed {
+		row[col] = c
+		b.remaining--
+	}
+}
+
+// attributeAll attributes every remaining cell of |row|, or of every row if |row| is nil, to the commit |c|.
+func (b *cellBlame) attributeAll(row []int, c int) {
+	if row != nil {
+		for i := range row {
+			b.attribute(row, i, c)
+		}
+		return
+	}
+	for _, r := range b.cells {
+		b.attributeAll(r, c)
+	}
+}
+
+// addCommit adds |cm| to the commits that cells are attributed to, and returns its index.
+func (b *cellBlame) addCommit(ctx context.Context, cm *doltdb.Commit) (int, error) {
+	h, err := cm.HashOf()
+	if err != nil {
+		return 0, err
+	}
+	meta, err := cm.GetCommitMeta(ctx)
+	if err != nil {
+		return 0, err
+	}
+	b.commits = append(b.commits, blameCommit{hash: h.String(), meta: meta})
+	return len(b.commits) - 1, nil
+}
+
+// blame walks the history of the table, and returns the commit that last modified each of its cells.
+func (bt *BlameCellsTable) blame(ctx *sql.Context) (*cellBlame, error) {
+	b := &cellBlame{cells: make(map[string][]int)}
+	iter, err := bt.rows.IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		k, _, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		row := make([]int, len(bt.columns))
+		for i := range row {
+			row[i] = unblamed
+		}
+		b.cells[string(k)] = row
+		b.remaining += len(row)
+	}
+
+	var sinceHash string
+	if bt.opts.Since != nil {
+		h, err := bt.opts.Since.HashOf()
+		if err != nil {
+			return nil, err
+		}
+		sinceHash = h.String()
+	}
+
+	cm, sch, rows := bt.head, bt.sch, bt.rows
+	for b.remaining > 0 {
+		c, err := b.addCommit(ctx, cm)
+		if err != nil {
+			return nil, err
+		}
+
+		var parent *doltdb.Commit
+		if cm.NumParents() > 0 && b.commits[c].hash != sinceHash {
+			optCmt, err := bt.ddb.ResolveParent(ctx, cm, 0)
+			if err != nil {
+				return nil, err
+			}
+			parent, _ = optCmt.ToCommit()
+		}
+		if parent == nil {
+			b.attributeAll(nil, c)
+			break
+		}
+
+		parentSch, parentRows, ok, err := bt.tableAt(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			b.attributeAll(nil, c)
+			break
+		}
+		if err = bt.blameDiff(ctx, b, c, sch, rows, parentSch, parentRows); err != nil {
+			return nil, err
+		}
+		cm, sch, rows = parent, parentSch, parentRows
+	}
+	return b, nil
+}
+
+// tableAt returns the schema and rows of the blamed table at |cm|, or false if the table doesn't exist at |cm|.
+func (bt *BlameCellsTable) tableAt(ctx context.Context, cm *doltdb.Commit) (schema.Schema, prolly.Map, bool, error) {
+	root, err := cm.GetRootValue(ctx)
+	if err != nil {
+		return nil, prolly.Map{}, false, err
+	}
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: bt.tableName})
+	if err != nil || !ok {
+		return nil, prolly.Map{}, false, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, prolly.Map{}, false, err
+	}
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, prolly.Map{}, false, err
+	}
+	return sch, durable.ProllyMapFromIndex(idx), true, nil
+}
+
+// findColumn returns the column of |sch| that the blamed column |col| is in, matched by tag when following renames
+// and by name otherwise.
+func (bt *BlameCellsTable) findColumn(sch schema.Schema, col schema.Column) (schema.Column, bool) {
+	if bt.opts.Follow {
+		return sch.GetAllCols().GetByTag(col.Tag)
+	}
+	return sch.GetAllCols().GetByNameCaseInsensitive(col.Name)
+}
+
+// blameDiff attributes to the commit |c| the remaining cells that differ between the table in |c|, which has the
+// schema |sch| and the rows |rows|, and the table in the commit's parent.
+func (bt *BlameCellsTable) blameDiff(ctx context.Context, b *cellBlame, c int, sch schema.Schema, rows prolly.Map, parentSch schema.Schema, parentRows prolly.Map) error {
+	kd, vd := rows.Descriptors()
+	parentKd, parentVd := parentRows.Descriptors()
+	if !kd.Equals(parentKd) {
+		b.attributeAll(nil, c)
+		return nil
+	}
+
+	// the value tuple index of each blamed non-pk column in the table and its parent, or -1 if its cells all changed
+	valIdx := make([]int, len(bt.columns))
+	parentValIdx := make([]int, len(bt.columns))
+	for i, bc := range bt.columns {
+		valIdx[i], parentValIdx[i] = -1, -1
+		col, ok := bt.findColumn(sch, bc.col)
+		if !ok {
+			// columns of the blamed table that don't exist in the table of this commit had all their cells blamed
+			// on a later commit
+			continue
+		}
+		parentCol, ok := bt.findColumn(parentSch, bc.col)
+		if bc.pk {
+			pkIdx, _ := sch.GetPKCols().StoredIndexByTag(col.Tag)
+			parentPkIdx, inPk := parentSch.GetPKCols().StoredIndexByTag(parentCol.Tag)
+			if !ok || !inPk || pkIdx != parentPkIdx {
+				b.attributeAll(nil, c)
+				return nil
+			}
+			continue
+		}
+
+		if !ok || parentCol.IsPartOfPK || parentCol.Virtual {
+			for _, row := range b.cells {
+				b.attribute(row, i, c)
+			}
+			continue
+		}
+		idx, _ := sch.GetNonPKCols().StoredIndexByTag(col.Tag)
+		parentIdx, _ := parentSch.GetNonPKCols().StoredIndexByTag(parentCol.Tag)
+		if vd.Types[idx].Enc != parentVd.Types[parentIdx].Enc {
+			for _, row := range b.cells {
+				b.attribute(row, i, c)
+			}
+			continue
+		}
+		valIdx[i], parentValIdx[i] = idx, parentIdx
+	}
+	if b.remaining == 0 {
+		return nil
+	}
+
+	err := prolly.DiffMaps(ctx, parentRows, rows, false, func(ctx context.Context, diff tree.Diff) error {
+		if diff.Type == tree.RemovedDiff {
+			return nil
+		}
+		row, ok := b.cells[string(diff.Key)]
+		if !ok {
+			return nil
+		}
+		if diff.Type == tree.AddedDiff {
+			b.attributeAll(row, c)
+			return nil
+		}
+		from, to := val.Tuple(diff.From), val.Tuple(diff.To)
+		for i := range row {
+			if row[i] != unblamed || valIdx[i] < 0 {
+				continue
+			}
+			if !bytes.Equal(parentVd.GetField(parentValIdx[i], from), vd.GetField(valIdx[i], to)) {
+				b.attribute(row, i, c)
+			}
+		}
+		if b.remaining == 0 {
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/branchpolicy"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// branchPoliciesRefType is the type of the ref_type column of the dolt_branch_policies table.
+var branchPoliciesRefType = types.MustCreateEnumType([]string{branchpolicy.RefTypeBranch, branchpolicy.RefTypeTag}, sql.Collation_Default)
+
+var _ sql.Table = (*BranchPoliciesTable)(nil)
+var _ sql.InsertableTable = (*BranchPoliciesTable)(nil)
+var _ sql.ReplaceableTable = (*BranchPoliciesTable)(nil)
+var _ sql.UpdatableTable = (*BranchPoliciesTable)(nil)
+var _ sql.DeletableTable = (*BranchPoliciesTable)(nil)
+
+// BranchPoliciesTable is the system table that declares the retention policies of the branches and tags of a
+// database. Unlike most writable system tables, it isn't versioned: like dolt_branch_control, it applies to every
+// branch of the database, so it is stored in a file of the .dolt directory of the database. The user and host of a
+// policy are always those of the session that last wrote it. A NULL ref_type is a branch policy.
+type BranchPoliciesTable struct {
+	dbName string
+}
+
+// NewBranchPoliciesTable creates a BranchPoliciesTable
+func NewBranchPoliciesTable(dbName string) sql.Table {
+	return &BranchPoliciesTable{dbName: dbName}
+}
+
+func (t *BranchPoliciesTable) Name() string {
+	return doltdb.BranchPoliciesTableName
+}
+
+func (t *BranchPoliciesTable) String() string {
+	return doltdb.BranchPoliciesTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the dolt_branch_policies system table.
+func (t *BranchPoliciesTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "name", Type: types.MustCreateString(sqltypes.VarChar, 1024, sql.Collation_utf8mb4_0900_ai_ci), Source: doltdb.BranchPoliciesTableName, PrimaryKey: true, DatabaseSource: t.dbName},
+		{Name: "ref_type", Type: branchPoliciesRefType, Source: doltdb.BranchPoliciesTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "pattern", Type: types.Text, Source: doltdb.BranchPoliciesTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "merged_into", Type: types.Text, Source: doltdb.BranchPoliciesTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "max_age", Type: types.Text, Source: doltdb.BranchPoliciesTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "keep_last", Type: types.Uint32, Source: doltdb.BranchPoliciesTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "user", Type: types.Text, Source: doltdb.BranchPoliciesTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "host", Type: types.Text, Source: doltdb.BranchPoliciesTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+	}
+}
+
+func (t *BranchPoliciesTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+func (t *BranchPoliciesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+func (t *BranchPoliciesTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	fs, err := t.fs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := branchpolicy.Load(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sql.Row, len(policies))
+	for i, p := range policies {
+		var maxAge, keepLast interface{}
+		if p.MaxAge > 0 {
+			maxAge = branchpolicy.FormatMaxAge(p.MaxAge)
+		}
+		if p.KeepLast > 0 {
+			keepLast = uint32(p.KeepLast)
+		}
+		rows[i] = sql.NewRow(p.Name, uint16(branchPoliciesRefType.IndexOf(p.RefType)), p.Pattern, nullIfEmpty(p.MergedInto), maxAge, keepLast, p.User, p.Host)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (t *BranchPoliciesTable) fs(ctx *sql.Context) (filesys.Filesys, error) {
+	return dsess.DSessFromSess(ctx.Session).Provider().FileSystemForDatabase(t.dbName)
+}
+
+// Inserter implements sql.InsertableTable.
+func (t *BranchPoliciesTable) Inserter(*sql.Context) sql.RowInserter {
+	return &branchPoliciesWriter{t: t}
+}
+
+// Replacer implements sql.ReplaceableTable.
+func (t *BranchPoliciesTable) Replacer(*sql.Context) sql.RowReplacer {
+	return &branchPoliciesWriter{t: t}
+}
+
+// Updater implements sql.UpdatableTable.
+func (t *BranchPoliciesTable) Updater(*sql.Context) sql.RowUpdater {
+	return &branchPoliciesWriter{t: t}
+}
+
+// Deleter implements sql.DeletableTable.
+func (t *BranchPoliciesTable) Deleter(*sql.Context) sql.RowDeleter {
+	return &branchPoliciesWriter{t: t}
+}
+
+// branchPoliciesWriter edits the policies of a BranchPoliciesTable, and saves them when it is closed.
+type branchPoliciesWriter struct {
+	t        *BranchPoliciesTable
+	fs       filesys.Filesys
+	policies branchpolicy.Policies
+	loaded   bool
+	err      error
+}
+
+var _ sql.RowInserter = (*branchPoliciesWriter)(nil)
+var _ sql.RowReplacer = (*branchPoliciesWriter)(nil)
+var _ sql.RowUpdater = (*branchPoliciesWriter)(nil)
+var _ sql.RowDeleter = (*branchPoliciesWriter)(nil)
+
+func (w *branchPoliciesWriter) load(ctx *sql.Context) error {
+	if w.loaded {
+		return nil
+	}
+	fs, err := w.t.fs(ctx)
+	if err != nil {
+		return err
+	}
+	policies, err := branchpolicy.Load(fs)
+	if err != nil {
+		return err
+	}
+	w.fs, w.policies, w.loaded = fs, policies, true
+	return nil
+}
+
+// StatementBegin implements sql.TableEditor.
+func (w *branchPoliciesWriter) StatementBegin(*sql.Context) {}
+
+// DiscardChanges implements sql.TableEditor.
+func (w *branchPoliciesWriter) DiscardChanges(_ *sql.Context, errorEncountered error) error {
+	w.err = errorEncountered
+	return nil
+}
+
+// StatementComplete implements sql.TableEditor.
+func (w *branchPoliciesWriter) StatementComplete(*sql.Context) error {
+	return nil
+}
+
+// Insert implements sql.RowInserter.
+func (w *branchPoliciesWriter) Insert(ctx *sql.Context, row sql.Row) error {
+	if err := w.load(ctx); err != nil {
+		return err
+	}
+	p, err := w.policyFromRow(ctx, row)
+	if err != nil {
+		return err
+	}
+	if _, ok := w.policies.Get(p.Name); ok {
+		return sql.NewUniqueKeyErr(fmt.Sprintf("[%s]", p.Name), true, sql.Row{p.Name})
+	}
+	w.policies = w.policies.Put(p)
+	return nil
+}
+
+// Update implements sql.RowUpdater.
+func (w *branchPoliciesWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := w.load(ctx); err != nil {
+		return err
+	}
+	oldName := old[0].(string)
+	if err := w.checkOwner(ctx, oldName); err != nil {
+		return err
+	}
+	p, err := w.policyFromRow(ctx, new)
+	if err != nil {
+		return err
+	}
+	if _, ok := w.policies.Get(p.Name); ok && !strings.EqualFold(oldName, p.Name) {
+		return sql.NewUniqueKeyErr(fmt.Sprintf("[%s]", p.Name), true, sql.Row{p.Name})
+	}
+	w.policies = w.policies.Remove(oldName).Put(p)
+	return nil
+}
+
+// Delete implements sql.RowDeleter.
+func (w *branchPoliciesWriter) Delete(ctx *sql.Context, row sql.Row) error {
+	if err := w.load(ctx); err != nil {
+		return err
+	}
+	name := row[0].(string)
+	if err := w.checkOwner(ctx, name); err != nil {
+		return err
+	}
+	w.policies = w.policies.Remove(name)
+	return nil
+}
+
+// Close implements sql.Closer. The policies are saved unless the statement failed.
+func (w *branchPoliciesWriter) Close(*sql.Context) error {
+	if !w.loaded || w.err != nil {
+		return nil
+	}
+	return branchpolicy.Save(w.fs, w.policies)
+}
+
+// checkOwner returns an error unless the user of |ctx| may change the policy named |name|, which requires either
+// having created it or having admin privileges on the database.
+func (w *branchPoliciesWriter) checkOwner(ctx *sql.Context, name string) error {
+	p, ok := w.policies.Get(name)
+	if !ok {
+		return nil
+	}
+	bas := branch_control.GetBranchAwareSession(ctx)
+	if bas == nil || branch_control.HasDatabasePrivileges(bas, w.t.dbName) {
+		return nil
+	}
+	if bas.GetUser() == p.User && bas.GetHost() == p.Host {
+		return nil
+	}
+	return fmt.Errorf("`%s`@`%s` cannot change the branch policy '%s' created by `%s`@`%s`", bas.GetUser(), bas.GetHost(), p.Name, p.User, p.Host)
+}
+
+// policyFromRow returns the policy described by |row|, owned by the user of |ctx|.
+func (w *branchPoliciesWriter) policyFromRow(ctx *sql.Context, row sql.Row) (branchpolicy.Policy, error) {
+	p := branchpolicy.Policy{
+		Name:    row[0].(string),
+		RefType: branchpolicy.RefTypeBranch,
+		Pattern: row[2].(string),
+	}
+	if row[1] != nil {
+		refType, _ := branchPoliciesRefType.At(int(row[1].(uint16)))
+		p.RefType = refType
+	}
+	if row[3] != nil {
+		p.MergedInto = row[3].(string)
+	}
+	if row[4] != nil {
+		maxAge, err := branchpolicy.ParseMaxAge(row[4].(string))
+		if err != nil {
+			return branchpolicy.Policy{}, err
+		}
+		p.MaxAge = maxAge
+	}
+	if row[5] != nil {
+		p.KeepLast = int(row[5].(uint32))
+	}
+	if bas := branch_control.GetBranchAwareSession(ctx); bas != nil {
+		p.User, p.Host = bas.GetUser(), bas.GetHost()
+	}
+	return p, p.Validate()
+}
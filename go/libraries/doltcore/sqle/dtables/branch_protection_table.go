@@ -0,0 +1,284 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+const (
+	ProtectionTableName = "dolt_branch_protection"
+)
+
+var (
+	ErrModifyingProtectionRow    = errors.NewKind("`%s`@`%s` cannot modify the protection of the branches [%q, %q]")
+	ErrConflictingProtectionRule = errors.NewKind("the rules fast_forward_only and merge_commits_only cannot both protect [%q, %q]")
+)
+
+// ProtectionRulesStrings is a slice of strings representing the available branch_control.ProtectionRules. The order of
+// the strings should exactly match the order of the branch_control.ProtectionRules according to their flag value.
+var ProtectionRulesStrings = []string{"no_direct_commits", "fast_forward_only", "merge_commits_only", "verify_constraints",
+	"signed_commits", "no_force_push", "no_delete"}
+
+// protectionSchema is the schema for the "dolt_branch_protection" table.
+var protectionSchema = sql.Schema{
+	&sql.Column{
+		Name:       "database",
+		Type:       types.MustCreateString(sqltypes.VarChar, 16383, sql.Collation_utf8mb4_0900_ai_ci),
+		Source:     ProtectionTableName,
+		PrimaryKey: true,
+	},
+	&sql.Column{
+		Name:       "branch",
+		Type:       types.MustCreateString(sqltypes.VarChar, 16383, sql.Collation_utf8mb4_0900_ai_ci),
+		Source:     ProtectionTableName,
+		PrimaryKey: true,
+	},
+	&sql.Column{
+		Name:       "rules",
+		Type:       types.MustCreateSetType(ProtectionRulesStrings, sql.Collation_utf8mb4_0900_ai_ci),
+		Source:     ProtectionTableName,
+		PrimaryKey: false,
+	},
+}
+
+// BranchProtectionTable provides a layer over the branch_control.Protection structure, exposing it as a system table.
+// A row may only be modified by users with admin permissions on its branches in the "dolt_branch_control" table.
+type BranchProtectionTable struct {
+	*branch_control.Protection
+	access *branch_control.Access
+}
+
+var _ sql.Table = BranchProtectionTable{}
+var _ sql.InsertableTable = BranchProtectionTable{}
+var _ sql.ReplaceableTable = BranchProtectionTable{}
+var _ sql.UpdatableTable = BranchProtectionTable{}
+var _ sql.DeletableTable = BranchProtectionTable{}
+var _ sql.RowInserter = BranchProtectionTable{}
+var _ sql.RowReplacer = BranchProtectionTable{}
+var _ sql.RowUpdater = BranchProtectionTable{}
+var _ sql.RowDeleter = BranchProtectionTable{}
+
+// NewBranchProtectionTable returns a new BranchProtectionTable.
+func NewBranchProtectionTable(controller *branch_control.Controller) BranchProtectionTable {
+	return BranchProtectionTable{controller.Protection, controller.Access}
+}
+
+// Name implements the interface sql.Table.
+func (tbl BranchProtectionTable) Name() string {
+	return ProtectionTableName
+}
+
+// String implements the interface sql.Table.
+func (tbl BranchProtectionTable) String() string {
+	return ProtectionTableName
+}
+
+// Schema implements the interface sql.Table.
+func (tbl BranchProtectionTable) Schema() sql.Schema {
+	return protectionSchema
+}
+
+// Collation implements the interface sql.Table.
+func (tbl BranchProtectionTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions implements the interface sql.Table.
+func (tbl BranchProtectionTable) Partitions(context *sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows implements the interface sql.Table.
+func (tbl BranchProtectionTable) PartitionRows(context *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	tbl.RWMutex.RLock()
+	defer tbl.RWMutex.RUnlock()
+
+	var rows []sql.Row
+	for _, value := range tbl.Values {
+		rows = append(rows, sql.Row{
+			value.Database,
+			value.Branch,
+			uint64(value.Rules),
+		})
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// Inserter implements the interface sql.InsertableTable.
+func (tbl BranchProtectionTable) Inserter(context *sql.Context) sql.RowInserter {
+	return tbl
+}
+
+// Replacer implements the interface sql.ReplaceableTable.
+func (tbl BranchProtectionTable) Replacer(ctx *sql.Context) sql.RowReplacer {
+	return tbl
+}
+
+// Updater implements the interface sql.UpdatableTable.
+func (tbl BranchProtectionTable) Updater(ctx *sql.Context) sql.RowUpdater {
+	return tbl
+}
+
+// Deleter implements the interface sql.DeletableTable.
+func (tbl BranchProtectionTable) Deleter(context *sql.Context) sql.RowDeleter {
+	return tbl
+}
+
+// StatementBegin implements the interface sql.TableEditor.
+func (tbl BranchProtectionTable) StatementBegin(ctx *sql.Context) {}
+
+// DiscardChanges implements the interface sql.TableEditor.
+func (tbl BranchProtectionTable) DiscardChanges(ctx *sql.Context, errorEncountered error) error {
+	return nil
+}
+
+// StatementComplete implements the interface sql.TableEditor.
+func (tbl BranchProtectionTable) StatementComplete(ctx *sql.Context) error {
+	return nil
+}
+
+// Insert implements the interface sql.RowInserter.
+func (tbl BranchProtectionTable) Insert(ctx *sql.Context, row sql.Row) error {
+	tbl.RWMutex.Lock()
+	defer tbl.RWMutex.Unlock()
+
+	database, branch, rules, err := tbl.rowValues(row)
+	if err != nil {
+		return err
+	}
+	if err = tbl.checkAdmin(ctx, database, branch); err != nil {
+		return err
+	}
+	if i := tbl.Get(database, branch); i >= 0 {
+		rulesStr, _ := protectionSchema[2].Type.(sql.SetType).BitsToString(uint64(tbl.Values[i].Rules))
+		return sql.NewUniqueKeyErr(
+			fmt.Sprintf(`[%q, %q, %q]`, database, branch, rulesStr),
+			true,
+			sql.Row{database, branch, uint64(tbl.Values[i].Rules)})
+	}
+
+	tbl.Protection.Insert(database, branch, rules)
+	return nil
+}
+
+// Update implements the interface sql.RowUpdater.
+func (tbl BranchProtectionTable) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	tbl.RWMutex.Lock()
+	defer tbl.RWMutex.Unlock()
+
+	oldDatabase, oldBranch, _, err := tbl.rowValues(old)
+	if err != nil {
+		return err
+	}
+	newDatabase, newBranch, newRules, err := tbl.rowValues(new)
+	if err != nil {
+		return err
+	}
+	if err = tbl.checkAdmin(ctx, oldDatabase, oldBranch); err != nil {
+		return err
+	}
+	if err = tbl.checkAdmin(ctx, newDatabase, newBranch); err != nil {
+		return err
+	}
+
+	// If we're not updating the same row, then we check for a row violation
+	if oldDatabase != newDatabase || oldBranch != newBranch {
+		if i := tbl.Get(newDatabase, newBranch); i >= 0 {
+			rulesStr, _ := protectionSchema[2].Type.(sql.SetType).BitsToString(uint64(tbl.Values[i].Rules))
+			return sql.NewUniqueKeyErr(
+				fmt.Sprintf(`[%q, %q, %q]`, newDatabase, newBranch, rulesStr),
+				true,
+				sql.Row{newDatabase, newBranch, uint64(tbl.Values[i].Rules)})
+		}
+	}
+
+	tbl.Protection.Delete(oldDatabase, oldBranch)
+	tbl.Protection.Insert(newDatabase, newBranch, newRules)
+	return nil
+}
+
+// Delete implements the interface sql.RowDeleter.
+func (tbl BranchProtectionTable) Delete(ctx *sql.Context, row sql.Row) error {
+	tbl.RWMutex.Lock()
+	defer tbl.RWMutex.Unlock()
+
+	database, branch, _, err := tbl.rowValues(row)
+	if err != nil {
+		return err
+	}
+	if err = tbl.checkAdmin(ctx, database, branch); err != nil {
+		return err
+	}
+
+	tbl.Protection.Delete(database, branch)
+	return nil
+}
+
+// Close implements the interface sql.Closer.
+func (tbl BranchProtectionTable) Close(context *sql.Context) error {
+	return branch_control.SaveData(context)
+}
+
+// rowValues returns the folded expressions and the rules of the given row, verifying that they're valid.
+func (tbl BranchProtectionTable) rowValues(row sql.Row) (database string, branch string, rules branch_control.ProtectionRules, err error) {
+	// Database and Branch are case-insensitive
+	database = strings.ToLower(branch_control.FoldExpression(row[0].(string)))
+	branch = strings.ToLower(branch_control.FoldExpression(row[1].(string)))
+	rules = branch_control.ProtectionRules(row[2].(uint64))
+
+	// Verify that the lengths of each expression fit within an uint16
+	if len(database) > math.MaxUint16 || len(branch) > math.MaxUint16 {
+		return "", "", 0, branch_control.ErrExpressionsTooLong.New(database, branch, "", "")
+	}
+	conflicting := branch_control.ProtectionRules_FastForwardOnly | branch_control.ProtectionRules_MergeCommitsOnly
+	if rules&conflicting == conflicting {
+		return "", "", 0, ErrConflictingProtectionRule.New(database, branch)
+	}
+	return database, branch, rules, nil
+}
+
+// checkAdmin returns an error if the user of the given context is not allowed to modify the protection of the branches
+// matching the given expressions, which requires admin permissions on them.
+func (tbl BranchProtectionTable) checkAdmin(ctx *sql.Context, database string, branch string) error {
+	// A nil session means we're not in the SQL context, so we allow the modification in such a case
+	branchAwareSession := branch_control.GetBranchAwareSession(ctx)
+	// Having the correct database privileges also allows the modification
+	if branchAwareSession == nil || branch_control.HasDatabasePrivileges(branchAwareSession, database) {
+		return nil
+	}
+	tbl.access.RWMutex.RLock()
+	defer tbl.access.RWMutex.RUnlock()
+
+	user := branchAwareSession.GetUser()
+	host := branchAwareSession.GetHost()
+	// As we've folded the branch expression, we can use it directly as though it were a normal branch name to
+	// determine if the user has admin permissions on the branches it matches.
+	_, perms := tbl.access.Match(database, branch, user, host)
+	if perms&branch_control.Permissions_Admin != branch_control.Permissions_Admin {
+		return ErrModifyingProtectionRow.New(user, host, database, branch)
+	}
+	return nil
+}
@@ -46,6 +46,9 @@ type CommitDiffTable struct {
 	sqlSch      sql.PrimaryKeySchema
 	workingRoot doltdb.RootValue
 	stagedRoot  doltdb.RootValue
+	// policyRoot is the working root of the default branch of the database, which the table's row policies are read from
+	policyRoot        doltdb.RootValue
+	resolvedTableName doltdb.TableName
 	// toCommit and fromCommit are set via the
 	// sql.IndexAddressable interface
 	toCommit          string
@@ -58,10 +61,10 @@ var _ sql.Table = (*CommitDiffTable)(nil)
 var _ sql.IndexAddressable = (*CommitDiffTable)(nil)
 var _ sql.StatisticsTable = (*CommitDiffTable)(nil)
 
-func NewCommitDiffTable(ctx *sql.Context, dbName, tblName string, ddb *doltdb.DoltDB, wRoot, sRoot doltdb.RootValue) (sql.Table, error) {
+func NewCommitDiffTable(ctx *sql.Context, dbName, tblName string, ddb *doltdb.DoltDB, wRoot, sRoot, policyRoot doltdb.RootValue) (sql.Table, error) {
 	diffTblName := doltdb.DoltCommitDiffTablePrefix + tblName
 
-	_, table, tableExists, err := resolve.Table(ctx, wRoot, tblName)
+	resolvedTableName, table, tableExists, err := resolve.Table(ctx, wRoot, tblName)
 	if err != nil {
 		return nil, err
 	}
@@ -85,14 +88,16 @@ func NewCommitDiffTable(ctx *sql.Context, dbName, tblName string, ddb *doltdb.Do
 	}
 
 	return &CommitDiffTable{
-		dbName:       dbName,
-		name:         tblName,
-		ddb:          ddb,
-		workingRoot:  wRoot,
-		stagedRoot:   sRoot,
-		joiner:       j,
-		sqlSch:       sqlSch,
-		targetSchema: sch,
+		dbName:            dbName,
+		name:              tblName,
+		ddb:               ddb,
+		workingRoot:       wRoot,
+		stagedRoot:        sRoot,
+		policyRoot:        policyRoot,
+		resolvedTableName: resolvedTableName,
+		joiner:            j,
+		sqlSch:            sqlSch,
+		targetSchema:      sch,
 	}, nil
 }
 
@@ -305,5 +310,9 @@ func (dt *CommitDiffTable) rootValForHash(ctx *sql.Context, hashStr string) (dol
 
 func (dt *CommitDiffTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
 	dp := part.(DiffPartition)
-	return dp.GetRowIter(ctx, dt.ddb, dt.joiner, sql.IndexLookup{})
+	iter, err := dp.GetRowIter(ctx, dt.ddb, dt.joiner, sql.IndexLookup{})
+	if err != nil {
+		return nil, err
+	}
+	return newRowPolicyDiffIter(ctx, dt.policyRoot, dt.workingRoot, dt.resolvedTableName, dt.targetSchema, iter)
 }
@@ -28,8 +28,9 @@ import (
 	"github.com/dolthub/dolt/go/store/types"
 )
 
-// NewConflictsTable returns a new ConflictsTable instance
-func NewConflictsTable(ctx *sql.Context, tblName string, srcTbl sql.Table, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+// NewConflictsTable returns a new ConflictsTable instance. The conflicts are read from |root|, and are filtered by the
+// row policies in |policyRoot|.
+func NewConflictsTable(ctx *sql.Context, tblName string, srcTbl sql.Table, root, policyRoot doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	resolvedTableName, tbl, ok, err := resolve.Table(ctx, root, tblName)
 	if err != nil {
 		return nil, err
@@ -42,7 +43,7 @@ func NewConflictsTable(ctx *sql.Context, tblName string, srcTbl sql.Table, root
 		if !ok {
 			return nil, fmt.Errorf("%s can not have conflicts because it is not updateable", tblName)
 		}
-		return newProllyConflictsTable(ctx, tbl, upd, resolvedTableName, root, rs)
+		return newProllyConflictsTable(ctx, tbl, upd, resolvedTableName, root, policyRoot, rs)
 	}
 
 	return newNomsConflictsTable(ctx, tbl, resolvedTableName.Name, root, rs)
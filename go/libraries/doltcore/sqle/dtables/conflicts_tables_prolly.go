@@ -15,6 +15,7 @@
 package dtables
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 
@@ -24,7 +25,9 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rerere"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -40,7 +43,7 @@ func newProllyConflictsTable(
 	tbl *doltdb.Table,
 	sourceUpdatableTbl sql.UpdatableTable,
 	tblName doltdb.TableName,
-	root doltdb.RootValue,
+	root, policyRoot doltdb.RootValue,
 	rs RootSetter,
 ) (sql.Table, error) {
 	arts, err := tbl.GetArtifacts(ctx)
@@ -69,6 +72,7 @@ func newProllyConflictsTable(
 		ourSch:          ourSch,
 		theirSch:        theirSch,
 		root:            root,
+		policyRoot:      policyRoot,
 		tbl:             tbl,
 		rs:              rs,
 		artM:            m,
@@ -84,6 +88,7 @@ type ProllyConflictsTable struct {
 	sqlSch                    sql.PrimaryKeySchema
 	baseSch, ourSch, theirSch schema.Schema
 	root                      doltdb.RootValue
+	policyRoot                doltdb.RootValue
 	tbl                       *doltdb.Table
 	rs                        RootSetter
 	artM                      prolly.ArtifactMap
@@ -115,7 +120,11 @@ func (ct ProllyConflictsTable) Partitions(ctx *sql.Context) (sql.PartitionIter,
 }
 
 func (ct ProllyConflictsTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
-	return newProllyConflictRowIter(ctx, ct)
+	itr, err := newProllyConflictRowIter(ctx, ct)
+	if err != nil {
+		return nil, err
+	}
+	return newRowPolicyConflictIter(ctx, ct.policyRoot, ct, itr)
 }
 
 func (ct ProllyConflictsTable) Updater(ctx *sql.Context) sql.RowUpdater {
@@ -646,6 +655,11 @@ func (cd *prollyConflictDeleter) StatementComplete(ctx *sql.Context) error {
 
 // Close finalizes the delete operation, persisting the result.
 func (cd *prollyConflictDeleter) Close(ctx *sql.Context) error {
+	resolutions, err := cd.resolutions(ctx)
+	if err != nil {
+		return err
+	}
+
 	arts, err := cd.ed.Flush(ctx)
 	if err != nil {
 		return err
@@ -665,7 +679,66 @@ func (cd *prollyConflictDeleter) Close(ctx *sql.Context) error {
 		return err
 	}
 
-	return cd.ct.rs.SetRoot(ctx, updatedRoot)
+	err = cd.ct.rs.SetRoot(ctx, updatedRoot)
+	if err != nil {
+		return err
+	}
+	return cd.recordResolutions(ctx, resolutions)
+}
+
+// resolutions returns the resolution of each conflict deleted by this deleter, which is the current value of its
+// row. Resolutions are only returned while the merge that produced the conflicts is in progress, since our side of
+// each conflict is the working root from before that merge.
+func (cd *prollyConflictDeleter) resolutions(ctx *sql.Context) (*rerere.Resolutions, error) {
+	resolutions := rerere.NewResolutions()
+	db, ok := cd.ct.rs.(sql.Database)
+	if !ok || schema.IsKeyless(cd.ct.ourSch) {
+		return resolutions, nil
+	}
+
+	ws, err := dsess.DSessFromSess(ctx.Session).WorkingSet(ctx, db.Name())
+	if err != nil {
+		return nil, err
+	}
+	if !ws.MergeActive() {
+		return resolutions, nil
+	}
+	sides, err := rerere.NewMergeSides(ctx, cd.ct.tblName, ws.MergeState().PreMergeWorkingRoot())
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := cd.ct.tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deleted := func(ctx context.Context, art prolly.ConflictArtifact) (bool, error) {
+		key := cd.ed.BuildArtifactKey(ctx, art.Key, art.TheirRootIsh, prolly.ArtifactTypeConflict)
+		has, err := cd.ed.Has(ctx, key)
+		return !has, err
+	}
+	_, err = resolutions.RecordConflicts(ctx, cd.ct.tblName.Name, cd.ct.artM, durable.ProllyMapFromIndex(idx), sides, deleted)
+	if err != nil {
+		return nil, err
+	}
+	return resolutions, nil
+}
+
+// recordResolutions records |resolutions| in the repository, so that identical conflicts are resolved the same way
+// by later merges.
+func (cd *prollyConflictDeleter) recordResolutions(ctx *sql.Context, resolutions *rerere.Resolutions) error {
+	if resolutions.Len() == 0 {
+		return nil
+	}
+	db := cd.ct.rs.(sql.Database)
+	fs, err := dsess.DSessFromSess(ctx.Session).Provider().FileSystemForDatabase(db.Name())
+	if err != nil {
+		return err
+	}
+	return rerere.Update(fs, func(r *rerere.Resolutions) (bool, error) {
+		r.RecordAll(resolutions)
+		return true, nil
+	})
 }
 
 type versionMappings struct {
@@ -26,10 +26,11 @@ import (
 	"github.com/dolthub/dolt/go/store/types"
 )
 
-// NewConstraintViolationsTable returns a sql.Table that lists constraint violations.
-func NewConstraintViolationsTable(ctx *sql.Context, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+// NewConstraintViolationsTable returns a sql.Table that lists constraint violations. The violations are read from
+// |root|, and are filtered by the row policies in |policyRoot|.
+func NewConstraintViolationsTable(ctx *sql.Context, tblName string, root, policyRoot doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	if root.VRW().Format() == types.Format_DOLT {
-		return newProllyCVTable(ctx, tblName, root, rs)
+		return newProllyCVTable(ctx, tblName, root, policyRoot, rs)
 	}
 
 	return newNomsCVTable(ctx, tblName, root, rs)
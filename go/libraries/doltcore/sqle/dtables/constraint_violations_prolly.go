@@ -33,7 +33,7 @@ import (
 	"github.com/dolthub/dolt/go/store/val"
 )
 
-func newProllyCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func newProllyCVTable(ctx *sql.Context, tblName string, root, policyRoot doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	resolvedName, tbl, ok, err := resolve.Table(ctx, root, tblName)
 	if err != nil {
 		return nil, err
@@ -55,24 +55,26 @@ func newProllyCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, r
 	}
 	m := durable.ProllyMapFromArtifactIndex(arts)
 	return &prollyConstraintViolationsTable{
-		tblName: resolvedName,
-		root:    root,
-		sqlSch:  sqlSch,
-		tbl:     tbl,
-		rs:      rs,
-		artM:    m,
+		tblName:    resolvedName,
+		root:       root,
+		policyRoot: policyRoot,
+		sqlSch:     sqlSch,
+		tbl:        tbl,
+		rs:         rs,
+		artM:       m,
 	}, nil
 }
 
 // prollyConstraintViolationsTable is a sql.Table implementation that provides access to the constraint violations that exist
 // for a user table for the v1 format.
 type prollyConstraintViolationsTable struct {
-	tblName doltdb.TableName
-	root    doltdb.RootValue
-	sqlSch  sql.PrimaryKeySchema
-	tbl     *doltdb.Table
-	rs      RootSetter
-	artM    prolly.ArtifactMap
+	tblName    doltdb.TableName
+	root       doltdb.RootValue
+	policyRoot doltdb.RootValue
+	sqlSch     sql.PrimaryKeySchema
+	tbl        *doltdb.Table
+	rs         RootSetter
+	artM       prolly.ArtifactMap
 }
 
 var _ sql.Table = (*prollyConstraintViolationsTable)(nil)
@@ -124,13 +126,14 @@ func (cvt *prollyConstraintViolationsTable) PartitionRows(ctx *sql.Context, part
 	kd = kd.WithoutFixedAccess()
 	vd = vd.WithoutFixedAccess()
 
-	return prollyCVIter{
+	cvItr := prollyCVIter{
 		itr: itr,
 		sch: sch,
 		kd:  kd,
 		vd:  vd,
 		ns:  cvt.artM.NodeStore(),
-	}, nil
+	}
+	return newRowPolicyCVIter(ctx, cvt.policyRoot, cvt.tblName, cvt.tbl, sch, cvItr)
 }
 
 func (cvt *prollyConstraintViolationsTable) Deleter(context *sql.Context) sql.RowDeleter {
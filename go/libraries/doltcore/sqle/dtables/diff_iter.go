@@ -17,9 +17,11 @@ package dtables
 import (
 	"context"
 	"io"
+	"runtime"
 	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
@@ -195,6 +197,14 @@ func (itr *ldDiffRowItr) Close(*sql.Context) (err error) {
 	return nil
 }
 
+// diffRangesPerCPU is the number of key ranges a large diff is split into
+// for each CPU, so that ranges with few changes don't leave CPUs idle
+const diffRangesPerCPU = 4
+
+// MinParallelDiffRows is the number of rows above which the key ranges of a
+// diff are diffed concurrently
+var MinParallelDiffRows = 64 * 1024
+
 type commitInfo2 struct {
 	name string
 	ts   *time.Time
@@ -332,9 +342,115 @@ func (itr prollyDiffIter) Close(ctx *sql.Context) error {
 }
 
 func (itr prollyDiffIter) queueRows(ctx context.Context) {
-	// TODO: Determine whether or not the schema has changed. If it has, then all rows should count as modifications in the diff.
-	considerAllRowsModified := false
-	err := prolly.DiffMaps(ctx, itr.from, itr.to, considerAllRowsModified, func(ctx context.Context, d tree.Diff) error {
+	err := itr.diffRows(ctx)
+	if err != nil && err != io.EOF {
+		select {
+		case <-ctx.Done():
+		case itr.errChan <- err:
+		}
+		return
+	}
+	// we need to drain itr.rows before returning io.EOF
+	close(itr.rows)
+}
+
+// diffRows sends the rows of the diff to |itr.rows| in key order. When
+// both maps are large, the key ranges between their split keys are diffed
+// concurrently, and the rows of each range are sent after those of the
+// ranges before it.
+func (itr prollyDiffIter) diffRows(ctx context.Context) error {
+	keys, err := itr.splitKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		// TODO: Determine whether or not the schema has changed. If it has, then all rows should count as modifications in the diff.
+		considerAllRowsModified := false
+		return prolly.DiffMaps(ctx, itr.from, itr.to, considerAllRowsModified, itr.sendDiffRows(itr.rows))
+	}
+
+	bounds := append(append([]val.Tuple{nil}, keys...), nil)
+	results := make([]chan sql.Row, len(bounds)-1)
+	for i := range results {
+		results[i] = make(chan sql.Row, 256)
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	// ranges are started in order, so the range being sent is always running
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	eg.Go(func() error {
+		for i := range results {
+			select {
+			case sem <- struct{}{}:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+			eg.Go(func() error {
+				defer func() { <-sem }()
+				defer close(results[i])
+				err := prolly.DiffMapsKeyRange(egCtx, itr.from, itr.to, bounds[i], bounds[i+1], itr.sendDiffRows(results[i]))
+				if err == io.EOF {
+					// the end of a range must not cancel the others
+					return nil
+				}
+				return err
+			})
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		for i := range results {
+			for {
+				var r sql.Row
+				var ok bool
+				select {
+				case r, ok = <-results[i]:
+				case <-egCtx.Done():
+					return egCtx.Err()
+				}
+				if !ok {
+					break
+				}
+				select {
+				case itr.rows <- r:
+				case <-egCtx.Done():
+					return egCtx.Err()
+				}
+			}
+		}
+		return nil
+	})
+	return eg.Wait()
+}
+
+// splitKeys returns the keys which split the diff into key ranges, or nil
+// if it should be diffed serially. The keys are taken from the larger map.
+func (itr prollyDiffIter) splitKeys(ctx context.Context) ([]val.Tuple, error) {
+	if itr.from.NodeStore() == nil || itr.to.NodeStore() == nil {
+		return nil, nil
+	}
+	fromCnt, err := itr.from.Count()
+	if err != nil {
+		return nil, err
+	}
+	toCnt, err := itr.to.Count()
+	if err != nil {
+		return nil, err
+	}
+	m, cnt := itr.to, toCnt
+	if fromCnt > toCnt {
+		m, cnt = itr.from, fromCnt
+	}
+	if cnt < MinParallelDiffRows {
+		return nil, nil
+	}
+	return m.SplitKeys(ctx, diffRangesPerCPU*runtime.GOMAXPROCS(0))
+}
+
+// sendDiffRows returns a callback which sends the rows of each diff to
+// |out|.
+func (itr prollyDiffIter) sendDiffRows(out chan<- sql.Row) tree.DiffFn {
+	return func(ctx context.Context, d tree.Diff) error {
 		dItr, err := itr.makeDiffRowItr(ctx, d)
 		if err != nil {
 			return err
@@ -350,20 +466,11 @@ func (itr prollyDiffIter) queueRows(ctx context.Context) {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case itr.rows <- r:
+			case out <- r:
 				continue
 			}
 		}
-	})
-	if err != nil && err != io.EOF {
-		select {
-		case <-ctx.Done():
-		case itr.errChan <- err:
-		}
-		return
 	}
-	// we need to drain itr.rows before returning io.EOF
-	close(itr.rows)
 }
 
 // todo(andy): copy string fields
@@ -63,6 +63,8 @@ type DiffTable struct {
 	ddb         *doltdb.DoltDB
 	workingRoot doltdb.RootValue
 	head        *doltdb.Commit
+	// policyRoot is the working root of the default branch of the database, which the table's row policies are read from
+	policyRoot doltdb.RootValue
 
 	headHash          hash.Hash
 	headCommitClosure *prolly.CommitClosure
@@ -88,7 +90,7 @@ var PrimaryKeyChangeWarning = "cannot render full diff between commits %s and %s
 
 const PrimaryKeyChangeWarningCode int = 1105 // Since this is our own custom warning we'll use 1105, the code for an unknown error
 
-func NewDiffTable(ctx *sql.Context, dbName, tblName string, ddb *doltdb.DoltDB, root doltdb.RootValue, head *doltdb.Commit) (sql.Table, error) {
+func NewDiffTable(ctx *sql.Context, dbName, tblName string, ddb *doltdb.DoltDB, root, policyRoot doltdb.RootValue, head *doltdb.Commit) (sql.Table, error) {
 	diffTblName := doltdb.DoltDiffTablePrefix + tblName
 
 	resolvedTableName, table, tableExists, err := resolve.Table(ctx, root, tblName)
@@ -118,6 +120,7 @@ func NewDiffTable(ctx *sql.Context, dbName, tblName string, ddb *doltdb.DoltDB,
 		ddb:              ddb,
 		workingRoot:      root,
 		head:             head,
+		policyRoot:       policyRoot,
 		targetSch:        sch,
 		diffTableSch:     diffTableSchema,
 		sqlSch:           sqlSch,
@@ -243,7 +246,11 @@ func (dt *DiffTable) HeadHash() (hash.Hash, error) {
 
 func (dt *DiffTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
 	dp := part.(DiffPartition)
-	return dp.GetRowIter(ctx, dt.ddb, dt.joiner, dt.lookup)
+	iter, err := dp.GetRowIter(ctx, dt.ddb, dt.joiner, dt.lookup)
+	if err != nil {
+		return nil, err
+	}
+	return newRowPolicyDiffIter(ctx, dt.policyRoot, dt.workingRoot, doltdb.TableName{Name: dt.name}, dt.targetSch, iter)
 }
 
 func (dt *DiffTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLookup) (sql.PartitionIter, error) {
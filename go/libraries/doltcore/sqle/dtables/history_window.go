@@ -0,0 +1,507 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+const (
+	// ValidFromCol is the name of the column containing the date of the commit that added a row version
+	ValidFromCol = "valid_from"
+	// ValidToCol is the name of the column containing the date of the commit that replaced or removed a row version
+	ValidToCol = "valid_to"
+	// ValidFromCommitCol is the name of the column containing the hash of the commit that added a row version
+	ValidFromCommitCol = "valid_from_commit"
+	// ValidToCommitCol is the name of the column containing the hash of the commit that replaced or removed a row version
+	ValidToCommitCol = "valid_to_commit"
+)
+
+// HistoryWindowMode is how a HistoryWindow selects the row versions whose period of validity overlaps it.
+type HistoryWindowMode int
+
+const (
+	// HistoryWindowBetween selects the versions valid at any time from the start of the window through its end, like
+	// FOR SYSTEM_TIME BETWEEN
+	HistoryWindowBetween HistoryWindowMode = iota
+	// HistoryWindowFromTo selects the versions valid at any time from the start of the window until before its end,
+	// like FOR SYSTEM_TIME FROM ... TO
+	HistoryWindowFromTo
+	// HistoryWindowContainedIn selects the versions that were both added and replaced within the window, like
+	// FOR SYSTEM_TIME CONTAINED IN
+	HistoryWindowContainedIn
+)
+
+// HistoryWindow is a window of time over the commit history of a table. A nil Start or End leaves that side of the
+// window unbounded.
+type HistoryWindow struct {
+	Start *time.Time
+	End   *time.Time
+	Mode  HistoryWindowMode
+}
+
+// selects returns whether the window selects a row version valid from |from| until |to|, or still valid if |to| is
+// nil.
+func (w HistoryWindow) selects(from time.Time, to *time.Time) bool {
+	switch w.Mode {
+	case HistoryWindowContainedIn:
+		if w.Start != nil && from.Before(*w.Start) {
+			return false
+		}
+		return w.End == nil || (to != nil && !to.After(*w.End))
+	case HistoryWindowFromTo:
+		if w.End != nil && !from.Before(*w.End) {
+			return false
+		}
+	default:
+		if w.End != nil && from.After(*w.End) {
+			return false
+		}
+	}
+	return w.Start == nil || to == nil || to.After(*w.Start)
+}
+
+// HistoryWindowSchema returns the schema of the row versions of a table with the schema |sch|, which is the schema
+// of the table followed by the period of validity of each version.
+func HistoryWindowSchema(sch schema.Schema) (sql.Schema, error) {
+	pkSch, err := sqlutil.FromDoltSchema("", "", sch)
+	if err != nil {
+		return nil, err
+	}
+	sqlSch := make(sql.Schema, 0, len(pkSch.Schema)+4)
+	for _, col := range pkSch.Schema {
+		// A row has a version for every period of time it was valid, so its primary key isn't unique
+		col.PrimaryKey = false
+		sqlSch = append(sqlSch, col)
+	}
+	return append(sqlSch,
+		&sql.Column{Name: ValidFromCol, Type: types.Datetime},
+		&sql.Column{Name: ValidToCol, Type: types.Datetime, Nullable: true},
+		&sql.Column{Name: ValidFromCommitCol, Type: types.Text},
+		&sql.Column{Name: ValidToCommitCol, Type: types.Text, Nullable: true},
+	), nil
+}
+
+// NewHistoryWindowRowIter returns the versions of the rows of the table |tableName| in the first-parent history of
+// the commit |head| that are selected by |window|, in the order they were added. Each version is a row of the schema
+// of the table at |head|, followed by the dates and hashes of the commits that added it and replaced or removed it,
+// which are nil if it's still valid at |head|. Changes made on other branches and merged into the history are
+// attributed to the merge commit. Row policies of the table are read from |policyRoot|.
+//
+// The window is pushed down to the commit walk: the rows of the table are only read in full at the last commit made
+// before the window starts, and from there, only the rows changed by each commit are read, until the versions valid
+// at the end of the window are replaced or the history ends. The commits that added the versions valid at the start
+// of the window are found by walking the history backwards, reading only the rows changed by each commit.
+func NewHistoryWindowRowIter(ctx *sql.Context, ddb *doltdb.DoltDB, head *doltdb.Commit, tableName string, policyRoot doltdb.RootValue, window HistoryWindow) (sql.RowIter, error) {
+	headRoot, err := head.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, tblName, ok, err := doltdb.GetTableInsensitive(ctx, headRoot, doltdb.TableName{Name: tableName})
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(tableName)
+	}
+	headSch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := dsess.GetRowPolicyFilter(ctx, policyRoot, headRoot, doltdb.TableName{Name: tblName})
+	if err != nil {
+		return nil, err
+	}
+
+	if window.Start != nil && window.End != nil && window.Start.After(*window.End) {
+		return sql.RowsToRowIter(), nil
+	}
+
+	w := &historyWindowWalk{
+		tableName: tblName,
+		headSch:   headSch,
+		window:    window,
+		open:      make(map[string]*rowVersion),
+	}
+	commits, baseline, err := w.commitsInWindow(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *windowTable
+	if baseline != nil {
+		if parent, err = w.tableAt(ctx, baseline); err != nil {
+			return nil, err
+		}
+		if err = w.openBaseline(ctx, baseline, parent); err != nil {
+			return nil, err
+		}
+	}
+	// Commit dates don't have to increase along the history, so the walk may only stop after the last commit made
+	// before the window ends
+	last := len(commits) - 1
+	for window.End != nil && last >= 0 && commits[last].time.After(*window.End) {
+		last--
+	}
+	for i, c := range commits {
+		if i > last && len(w.open) == 0 {
+			break
+		}
+		table, err := w.tableAt(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if err = w.step(ctx, c, parent, table); err != nil {
+			return nil, err
+		}
+		parent = table
+	}
+
+	sort.SliceStable(w.versions, func(i, j int) bool {
+		if w.versions[i].from.seq != w.versions[j].from.seq {
+			return w.versions[i].from.seq < w.versions[j].from.seq
+		}
+		return w.versions[i].order < w.versions[j].order
+	})
+	var rows []sql.Row
+	for _, v := range w.versions {
+		var toTime *time.Time
+		if v.to != nil {
+			toTime = &v.to.time
+		}
+		if !window.selects(v.from.time, toTime) {
+			continue
+		}
+		if filter != nil {
+			if ok, err := filter.Allows(ctx, v.row); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+		row := append(v.row.Copy(), v.from.time, nil, v.from.hash.String(), nil)
+		if v.to != nil {
+			row[len(row)-3] = v.to.time
+			row[len(row)-1] = v.to.hash.String()
+		}
+		rows = append(rows, row)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// windowCommit is a commit in the first-parent history walked by a historyWindowWalk. Its seq orders it in the
+// history.
+type windowCommit struct {
+	cm   *doltdb.Commit
+	hash hash.Hash
+	time time.Time
+	seq  int
+}
+
+// windowTable is the table at a commit, with a converter of its rows to the schema of the table at the head commit.
+type windowTable struct {
+	rows prolly.Map
+	conv ProllyRowConverter
+	size int
+}
+
+// rowVersion is a version of a row, valid from the commit |from| until the commit |to|, or still valid if |to| is nil.
+type rowVersion struct {
+	row   sql.Row
+	from  *windowCommit
+	to    *windowCommit
+	order int
+}
+
+// historyWindowWalk tracks the versions of the rows of a table as its history is walked.
+type historyWindowWalk struct {
+	tableName string
+	headSch   schema.Schema
+	window    HistoryWindow
+	versions  []*rowVersion
+	// open maps the key of every row of the table at the last commit walked to its current version
+	open map[string]*rowVersion
+}
+
+// commitsInWindow walks the first-parent history of |head| back to the last commit made before the window starts,
+// and returns the commits after it, from the oldest to |head|, along with that commit, which is nil if the window
+// starts before the history.
+func (w *historyWindowWalk) commitsInWindow(ctx context.Context, head *doltdb.Commit) ([]*windowCommit, *windowCommit, error) {
+	var commits []*windowCommit
+	var baseline *windowCommit
+	iter := doltdb.CommitItrForFirstParents(head)
+	for {
+		h, optCmt, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		cm, ok := optCmt.ToCommit()
+		if !ok {
+			break
+		}
+		meta, err := cm.GetCommitMeta(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		c := &windowCommit{cm: cm, hash: h, time: meta.Time()}
+		if w.window.Start != nil && !c.time.After(*w.window.Start) {
+			baseline = c
+			break
+		}
+		commits = append(commits, c)
+	}
+
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	for i := range commits {
+		commits[i].seq = i + 1
+	}
+	return commits, baseline, nil
+}
+
+// tableAt returns the table at the commit |c|, or nil if the table doesn't exist at |c|.
+func (w *historyWindowWalk) tableAt(ctx *sql.Context, c *windowCommit) (*windowTable, error) {
+	root, err := c.cm.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: w.tableName})
+	if err != nil || !ok {
+		return nil, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &windowTable{rows: durable.ProllyMapFromIndex(idx), size: w.headSch.GetAllCols().Size()}
+	if t.conv, err = NewProllyRowConverter(sch, w.headSch, ctx.Warn, tbl.NodeStore()); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *windowTable) row(ctx context.Context, key, value val.Tuple) (sql.Row, error) {
+	r := make(sql.Row, t.size)
+	err := t.conv.PutConverted(ctx, key, value, r)
+	return r, err
+}
+
+// iterAll calls |cb| with every row of the table.
+func (t *windowTable) iterAll(ctx context.Context, cb func(key val.Tuple, row sql.Row) error) error {
+	iter, err := t.rows.IterAll(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		r, err := t.row(ctx, k, v)
+		if err != nil {
+			return err
+		}
+		if err = cb(k, r); err != nil {
+			return err
+		}
+	}
+}
+
+// sameKeys returns whether the rows of |t| and |other| are keyed the same way, so that they can be diffed.
+func (t *windowTable) sameKeys(other *windowTable) bool {
+	return t.rows.KeyDesc().Equals(other.rows.KeyDesc())
+}
+
+// openVersion adds a version of the row with the key |key| that is valid from the commit |c|.
+func (w *historyWindowWalk) openVersion(key val.Tuple, row sql.Row, c *windowCommit) *rowVersion {
+	v := &rowVersion{row: row, from: c, order: len(w.versions)}
+	w.versions = append(w.versions, v)
+	w.open[string(key)] = v
+	return v
+}
+
+// closeVersion ends the current version of the row with the key |key| at the commit |c|, if it has one.
+func (w *historyWindowWalk) closeVersion(key val.Tuple, c *windowCommit) {
+	if v, ok := w.open[string(key)]; ok {
+		v.to = c
+		delete(w.open, string(key))
+	}
+}
+
+// closeAll ends the current version of every row at the commit |c|.
+func (w *historyWindowWalk) closeAll(c *windowCommit) {
+	for _, v := range w.open {
+		v.to = c
+	}
+	w.open = make(map[string]*rowVersion)
+}
+
+// step walks the changes of the commit |c| to the table, which is |table| at |c| and |parent| at its first parent.
+// Either is nil if the table doesn't exist at that commit.
+func (w *historyWindowWalk) step(ctx *sql.Context, c *windowCommit, parent, table *windowTable) error {
+	switch {
+	case parent == nil && table == nil:
+		return nil
+	case table == nil:
+		w.closeAll(c)
+		return nil
+	case parent == nil || !parent.sameKeys(table):
+		// the table was added, or its primary key was redefined, so every row was removed and added again
+		w.closeAll(c)
+		return table.iterAll(ctx, func(key val.Tuple, row sql.Row) error {
+			w.openVersion(key, row, c)
+			return nil
+		})
+	}
+
+	err := prolly.DiffMaps(ctx, parent.rows, table.rows, false, func(_ context.Context, d tree.Diff) error {
+		key := val.Tuple(d.Key)
+		switch d.Type {
+		case tree.RemovedDiff:
+			w.closeVersion(key, c)
+			return nil
+		case tree.AddedDiff:
+			row, err := table.row(ctx, key, val.Tuple(d.To))
+			if err != nil {
+				return err
+			}
+			w.openVersion(key, row, c)
+			return nil
+		default:
+			row, err := table.row(ctx, key, val.Tuple(d.To))
+			if err != nil {
+				return err
+			}
+			// A change to columns that were dropped from the table doesn't make a new version
+			if v, ok := w.open[string(key)]; ok && rowsEqual(ctx, w.headSch, v.row, row) {
+				return nil
+			}
+			w.closeVersion(key, c)
+			w.openVersion(key, row, c)
+			return nil
+		}
+	})
+	if err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// openBaseline adds a version for every row of the table at the commit |baseline|, which is |table| at |baseline|,
+// and walks the history backwards from |baseline| to find the commits that added them.
+func (w *historyWindowWalk) openBaseline(ctx *sql.Context, baseline *windowCommit, table *windowTable) error {
+	if table == nil {
+		return nil
+	}
+	unresolved := make(map[string]*rowVersion)
+	err := table.iterAll(ctx, func(key val.Tuple, row sql.Row) error {
+		unresolved[string(key)] = w.openVersion(key, row, baseline)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c := baseline
+	for seq := -1; len(unresolved) > 0; seq-- {
+		resolveAt := func(key string) {
+			unresolved[key].from = c
+			delete(unresolved, key)
+		}
+		if c.cm.NumParents() == 0 {
+			break
+		}
+		optCmt, err := c.cm.GetParent(ctx, 0)
+		if err != nil {
+			return err
+		}
+		cm, ok := optCmt.ToCommit()
+		if !ok {
+			break
+		}
+		meta, err := cm.GetCommitMeta(ctx)
+		if err != nil {
+			return err
+		}
+		p := &windowCommit{cm: cm, hash: optCmt.Addr, time: meta.Time(), seq: seq}
+		parent, err := w.tableAt(ctx, p)
+		if err != nil {
+			return err
+		}
+		if parent == nil || !parent.sameKeys(table) {
+			break
+		}
+		err = prolly.DiffMaps(ctx, parent.rows, table.rows, false, func(_ context.Context, d tree.Diff) error {
+			v, ok := unresolved[string(d.Key)]
+			if !ok {
+				return nil
+			}
+			if d.Type == tree.ModifiedDiff {
+				row, err := parent.row(ctx, val.Tuple(d.Key), val.Tuple(d.From))
+				if err != nil {
+					return err
+				}
+				if rowsEqual(ctx, w.headSch, v.row, row) {
+					return nil
+				}
+			}
+			resolveAt(string(d.Key))
+			return nil
+		})
+		if err != nil && err != io.EOF {
+			return err
+		}
+		c, table = p, parent
+	}
+	// The remaining rows were added with the table, or before the history available in a shallow clone
+	for _, v := range unresolved {
+		v.from = c
+	}
+	return nil
+}
+
+// rowsEqual returns whether |a| and |b|, which are rows of the schema |sch|, are equal.
+func rowsEqual(ctx *sql.Context, sch schema.Schema, a, b sql.Row) bool {
+	for i, col := range sch.GetAllCols().GetColumns() {
+		if cmp, err := col.TypeInfo.ToSqlType().Compare(a[i], b[i]); err != nil || cmp != 0 {
+			return false
+		}
+	}
+	return true
+}
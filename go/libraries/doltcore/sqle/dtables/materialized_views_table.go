@@ -0,0 +1,77 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+var _ sql.Table = (*MaterializedViewsTable)(nil)
+var _ sql.UpdatableTable = (*MaterializedViewsTable)(nil)
+var _ sql.DeletableTable = (*MaterializedViewsTable)(nil)
+var _ sql.InsertableTable = (*MaterializedViewsTable)(nil)
+var _ sql.ReplaceableTable = (*MaterializedViewsTable)(nil)
+var _ sql.IndexAddressableTable = (*MaterializedViewsTable)(nil)
+
+// MaterializedViewsTable is the system table that stores materialized views. Each row names a view, whose rows are
+// stored in the table of the same name, and records its query, the commit whose data its rows reflect, and whether
+// it's refreshed after every commit. A NULL source_commit means the view's rows don't reflect a commit, and the next
+// refresh of the view rebuilds it in full.
+type MaterializedViewsTable struct {
+	*writableSystemTable
+}
+
+var materializedViewsTableColumns = []systemTableColumn{
+	{name: "name", tag: schema.DoltMaterializedViewsNameTag, kind: types.StringKind, pk: true},
+	{name: "view_query", tag: schema.DoltMaterializedViewsQueryTag, kind: types.StringKind},
+	{name: "source_commit", tag: schema.DoltMaterializedViewsSourceCommitTag, kind: types.StringKind, nullable: true},
+	{name: "refresh_on_commit", tag: schema.DoltMaterializedViewsRefreshOnCommitTag, kind: types.BoolKind},
+}
+
+// NewMaterializedViewsTable creates a MaterializedViewsTable for the database |dbName|, stored in |backingTable|
+func NewMaterializedViewsTable(_ *sql.Context, dbName string, backingTable VersionableTable) sql.Table {
+	return &MaterializedViewsTable{&writableSystemTable{
+		name:         doltdb.MaterializedViewsTableName,
+		dbName:       dbName,
+		columns:      materializedViewsTableColumns,
+		backingTable: backingTable,
+		validate:     validateMaterializedViewRow,
+	}}
+}
+
+// NewEmptyMaterializedViewsTable creates a MaterializedViewsTable for the database |dbName|, which doesn't have any
+// materialized views yet
+func NewEmptyMaterializedViewsTable(ctx *sql.Context, dbName string) sql.Table {
+	return NewMaterializedViewsTable(ctx, dbName, nil)
+}
+
+// validateMaterializedViewRow returns an error if |r| doesn't declare a valid materialized view.
+func validateMaterializedViewRow(r sql.Row) error {
+	name, _ := r[0].(string)
+	if name == "" {
+		return fmt.Errorf("name of a materialized view must not be empty")
+	}
+	if query, _ := r[1].(string); strings.TrimSpace(query) == "" {
+		return fmt.Errorf("materialized view '%s' must have a view_query", name)
+	}
+	return nil
+}
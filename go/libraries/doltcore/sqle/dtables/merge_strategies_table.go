@@ -0,0 +1,76 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+var _ sql.Table = (*MergeStrategiesTable)(nil)
+var _ sql.UpdatableTable = (*MergeStrategiesTable)(nil)
+var _ sql.DeletableTable = (*MergeStrategiesTable)(nil)
+var _ sql.InsertableTable = (*MergeStrategiesTable)(nil)
+var _ sql.ReplaceableTable = (*MergeStrategiesTable)(nil)
+var _ sql.IndexAddressableTable = (*MergeStrategiesTable)(nil)
+
+// MergeStrategiesTable is the system table that declares how conflicting changes to the cells of a table are resolved
+// automatically during a merge. Each row names a table, a column of it or * for all of its columns, the merge
+// strategy to apply and, for the lww strategy, the column that decides which side of the merge was written last.
+type MergeStrategiesTable struct {
+	*writableSystemTable
+}
+
+var mergeStrategiesTableColumns = []systemTableColumn{
+	{name: "table_name", tag: schema.DoltMergeStrategiesTableNameTag, kind: types.StringKind, pk: true},
+	{name: "column_name", tag: schema.DoltMergeStrategiesColumnNameTag, kind: types.StringKind, pk: true},
+	{name: "strategy", tag: schema.DoltMergeStrategiesStrategyTag, kind: types.StringKind},
+	{name: "lww_column", tag: schema.DoltMergeStrategiesLwwColumnTag, kind: types.StringKind, nullable: true},
+}
+
+// NewMergeStrategiesTable creates a MergeStrategiesTable for the database |dbName|, stored in |backingTable|
+func NewMergeStrategiesTable(_ *sql.Context, dbName string, backingTable VersionableTable) sql.Table {
+	return &MergeStrategiesTable{&writableSystemTable{
+		name:         doltdb.MergeStrategiesTableName,
+		dbName:       dbName,
+		columns:      mergeStrategiesTableColumns,
+		backingTable: backingTable,
+		validate:     validateMergeStrategyRow,
+	}}
+}
+
+// NewEmptyMergeStrategiesTable creates a MergeStrategiesTable for the database |dbName|, which doesn't declare any
+// merge strategies yet
+func NewEmptyMergeStrategiesTable(ctx *sql.Context, dbName string) sql.Table {
+	return NewMergeStrategiesTable(ctx, dbName, nil)
+}
+
+// validateMergeStrategyRow returns an error if |r| doesn't declare a valid merge strategy rule.
+func validateMergeStrategyRow(r sql.Row) error {
+	table, _ := r[0].(string)
+	column, _ := r[1].(string)
+	strategy, _ := r[2].(string)
+	lwwColumn, _ := r[3].(string)
+	if column == "" {
+		return fmt.Errorf("column_name of a merge strategy must name a column of table '%s' or be '%s'", table, doltdb.MergeStrategyAllColumns)
+	}
+	_, err := doltdb.NewMergeStrategyRule(table, column, strategy, lwwColumn)
+	return err
+}
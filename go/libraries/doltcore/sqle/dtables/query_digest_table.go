@@ -0,0 +1,109 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/queryhistory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+// QueryDigestTable is a sql.Table implementation that implements a system table which aggregates the statements of
+// the query history of the sql-server running in this process by digest, with their latency percentiles, most time
+// consuming first. Like the query history, reading it requires the SUPER privilege.
+type QueryDigestTable struct {
+	dbName string
+}
+
+var _ sql.Table = (*QueryDigestTable)(nil)
+
+// NewQueryDigestTable creates a QueryDigestTable
+func NewQueryDigestTable(dbName string) sql.Table {
+	return &QueryDigestTable{dbName: dbName}
+}
+
+func (t *QueryDigestTable) Name() string {
+	return doltdb.QueryDigestTableName
+}
+
+func (t *QueryDigestTable) String() string {
+	return doltdb.QueryDigestTableName
+}
+
+func (t *QueryDigestTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "digest", Type: types.Text, Source: doltdb.QueryDigestTableName, PrimaryKey: true, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "digest_text", Type: types.LongText, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "sample_query", Type: types.LongText, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "count", Type: types.Uint64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "error_count", Type: types.Uint64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "total_latency_micros", Type: types.Int64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "avg_latency_micros", Type: types.Int64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "p50_latency_micros", Type: types.Int64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "p95_latency_micros", Type: types.Int64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "p99_latency_micros", Type: types.Int64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "max_latency_micros", Type: types.Int64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "rows_examined", Type: types.Uint64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "rows_returned", Type: types.Uint64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "rows_affected", Type: types.Uint64, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "first_seen", Type: types.DatetimeMaxPrecision, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "last_seen", Type: types.DatetimeMaxPrecision, Source: doltdb.QueryDigestTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+	}
+}
+
+func (t *QueryDigestTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+func (t *QueryDigestTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+func (t *QueryDigestTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	if err := checkSuper(ctx, doltdb.QueryDigestTableName); err != nil {
+		return nil, err
+	}
+
+	h := queryhistory.ActiveHistory()
+	if h == nil {
+		return sql.RowsToRowIter(), nil
+	}
+	summaries := queryhistory.Summarize(h.Entries())
+	rows := make([]sql.Row, len(summaries))
+	for i, s := range summaries {
+		rows[i] = sql.NewRow(
+			s.Digest,
+			s.DigestText,
+			s.SampleQuery,
+			s.Count,
+			s.ErrorCount,
+			s.TotalMicros,
+			s.AvgMicros,
+			s.P50Micros,
+			s.P95Micros,
+			s.P99Micros,
+			s.MaxMicros,
+			s.RowsExamined,
+			s.RowsReturned,
+			s.RowsAffected,
+			s.FirstSeen,
+			s.LastSeen,
+		)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
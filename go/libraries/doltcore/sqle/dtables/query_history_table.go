@@ -0,0 +1,105 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/queryhistory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+// QueryHistoryTable is a sql.Table implementation that implements a system table which shows the most recent
+// statements run on the sql-server running in this process, oldest first. It is empty when no query history is
+// configured. Since the statements cover every database and user, reading them requires the SUPER privilege.
+type QueryHistoryTable struct {
+	dbName string
+}
+
+var _ sql.Table = (*QueryHistoryTable)(nil)
+
+// NewQueryHistoryTable creates a QueryHistoryTable
+func NewQueryHistoryTable(dbName string) sql.Table {
+	return &QueryHistoryTable{dbName: dbName}
+}
+
+func (t *QueryHistoryTable) Name() string {
+	return doltdb.QueryHistoryTableName
+}
+
+func (t *QueryHistoryTable) String() string {
+	return doltdb.QueryHistoryTableName
+}
+
+func (t *QueryHistoryTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "start_time", Type: types.DatetimeMaxPrecision, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "connection_id", Type: types.Uint32, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "user", Type: types.Text, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "database", Type: types.Text, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "branch", Type: types.Text, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "digest", Type: types.Text, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "digest_text", Type: types.LongText, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "query", Type: types.LongText, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "latency_micros", Type: types.Int64, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "rows_examined", Type: types.Uint64, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "rows_returned", Type: types.Uint64, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "rows_affected", Type: types.Uint64, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: false, DatabaseSource: t.dbName},
+		{Name: "plan", Type: types.LongText, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+		{Name: "error", Type: types.Text, Source: doltdb.QueryHistoryTableName, PrimaryKey: false, Nullable: true, DatabaseSource: t.dbName},
+	}
+}
+
+func (t *QueryHistoryTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+func (t *QueryHistoryTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+func (t *QueryHistoryTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	if err := checkSuper(ctx, doltdb.QueryHistoryTableName); err != nil {
+		return nil, err
+	}
+
+	h := queryhistory.ActiveHistory()
+	if h == nil {
+		return sql.RowsToRowIter(), nil
+	}
+	entries := h.Entries()
+	rows := make([]sql.Row, len(entries))
+	for i, e := range entries {
+		rows[i] = sql.NewRow(
+			e.StartTime,
+			e.ConnectionID,
+			e.User,
+			nullIfEmpty(e.Database),
+			nullIfEmpty(e.Branch),
+			e.Digest,
+			e.DigestText,
+			e.Query,
+			e.LatencyMicros,
+			e.RowsExamined,
+			e.RowsReturned,
+			e.RowsAffected,
+			nullIfEmpty(e.Plan),
+			nullIfEmpty(e.Error),
+		)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
@@ -0,0 +1,447 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+var errKeylessRowLineageTable = errors.New("unable to track the lineage of the rows of a table without a primary key")
+
+// RowLineageTable is the dolt_row_lineage_<table> system table. It has a row for every change to a row of the table
+// in the first-parent history of a commit, ordered from the oldest commit to the newest, and gives every row a stable
+// identity that survives changes to its primary key.
+//
+// Each commit is diffed with its first parent. Rows with the same primary key on both sides keep their identity.
+// Rows that were removed and added by the same commit, including every row of the table when its primary key was
+// redefined, are paired by a diff.RowLineageMatcher, and an added row paired with a removed row takes over its
+// identity. The remaining added rows are given a new identity, derived from the commit that added them and their
+// primary key. Changes made on other branches and merged into the history are attributed to the merge commit.
+type RowLineageTable struct {
+	tableName  string
+	ddb        *doltdb.DoltDB
+	head       *doltdb.Commit
+	policyRoot doltdb.RootValue
+	threshold  float64
+
+	headRoot doltdb.RootValue
+	headSch  schema.Schema
+	sqlSch   sql.Schema
+}
+
+var _ sql.Table = (*RowLineageTable)(nil)
+
+// NewRowLineageTable returns a RowLineageTable for the table |tableName| as of the commit |head|, which pairs rows
+// by similarity if it is at least |threshold|. Row policies of the table are read from |policyRoot|. Returns false if
+// the table doesn't exist at |head|.
+func NewRowLineageTable(ctx *sql.Context, tableName string, ddb *doltdb.DoltDB, head *doltdb.Commit, policyRoot doltdb.RootValue, threshold float64) (sql.Table, bool, error) {
+	root, err := head.GetRootValue(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	tbl, tblName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tableName})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if schema.IsKeyless(sch) {
+		return nil, false, errKeylessRowLineageTable
+	}
+
+	lt := &RowLineageTable{
+		tableName:  tblName,
+		ddb:        ddb,
+		head:       head,
+		policyRoot: policyRoot,
+		threshold:  threshold,
+		headRoot:   root,
+		headSch:    sch,
+	}
+	lt.sqlSch = sql.Schema{
+		&sql.Column{Name: "row_id", Type: types.Text, Source: lt.Name()},
+		&sql.Column{Name: "commit_hash", Type: types.Text, Source: lt.Name()},
+		&sql.Column{Name: "committer", Type: types.Text, Source: lt.Name()},
+		&sql.Column{Name: "commit_date", Type: types.Datetime, Source: lt.Name()},
+		&sql.Column{Name: "diff_type", Type: types.Text, Source: lt.Name()},
+		&sql.Column{Name: "match", Type: types.Text, Source: lt.Name(), Nullable: true},
+		&sql.Column{Name: "similarity", Type: types.Float64, Source: lt.Name(), Nullable: true},
+		&sql.Column{Name: "from_key", Type: types.JSON, Source: lt.Name(), Nullable: true},
+		&sql.Column{Name: "to_key", Type: types.JSON, Source: lt.Name(), Nullable: true},
+	}
+	return lt, true, nil
+}
+
+// Name implements the sql.Table interface
+func (lt *RowLineageTable) Name() string {
+	return doltdb.DoltRowLineageTablePrefix + lt.tableName
+}
+
+// String implements the sql.Table interface
+func (lt *RowLineageTable) String() string {
+	return lt.Name()
+}
+
+// Schema implements the sql.Table interface. The match of a modified row is how it was matched with its previous
+// version, and its similarity is the fraction of its non-primary key columns that didn't change, unless it was
+// matched by primary key. The keys of a row are JSON objects of its primary key values, keyed by column name.
+func (lt *RowLineageTable) Schema() sql.Schema {
+	return lt.sqlSch
+}
+
+// Collation implements the sql.Table interface
+func (lt *RowLineageTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions implements the sql.Table interface
+func (lt *RowLineageTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows implements the sql.Table interface
+func (lt *RowLineageTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	filter, err := dsess.GetRowPolicyFilter(ctx, lt.policyRoot, lt.headRoot, doltdb.TableName{Name: lt.tableName})
+	if err != nil {
+		return nil, err
+	}
+	commits, err := lt.history(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &lineageWalk{lt: lt, filter: filter, ids: make(map[string]string)}
+	var parent *lineageVersion
+	for _, cm := range commits {
+		version, err := lt.versionAt(ctx, cm)
+		if err != nil {
+			return nil, err
+		}
+		if err = w.step(ctx, cm, parent, version); err != nil {
+			return nil, err
+		}
+		parent = version
+	}
+	return sql.RowsToRowIter(w.rows...), nil
+}
+
+// history returns the first-parent history of the head commit, from the oldest commit to the head. The history ends
+// at the first commit, or at a parent that isn't available in a shallow clone.
+func (lt *RowLineageTable) history(ctx context.Context) ([]*doltdb.Commit, error) {
+	var commits []*doltdb.Commit
+	cm := lt.head
+	for {
+		commits = append(commits, cm)
+		if cm.NumParents() == 0 {
+			break
+		}
+		optCmt, err := lt.ddb.ResolveParent(ctx, cm, 0)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if cm, ok = optCmt.ToCommit(); !ok {
+			break
+		}
+	}
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+// lineageVersion is the table at a commit.
+type lineageVersion struct {
+	sch  schema.Schema
+	rows prolly.Map
+	conv ProllyRowConverter
+	// policyOrds are the indexes in the rows of the table of the columns of the table at the head commit, or -1 for
+	// the columns that don't exist in the table, for row policies
+	policyOrds []int
+}
+
+// lineageRow is a row of a lineageVersion.
+type lineageRow struct {
+	key val.Tuple
+	row sql.Row
+}
+
+// versionAt returns the table at |cm|, or nil if the table doesn't exist at |cm|.
+func (lt *RowLineageTable) versionAt(ctx *sql.Context, cm *doltdb.Commit) (*lineageVersion, error) {
+	root, err := cm.GetRootValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: lt.tableName})
+	if err != nil || !ok {
+		return nil, err
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v := &lineageVersion{sch: sch, rows: durable.ProllyMapFromIndex(idx)}
+	if v.conv, err = NewProllyRowConverter(sch, sch, ctx.Warn, tbl.NodeStore()); err != nil {
+		return nil, err
+	}
+	for _, col := range lt.headSch.GetAllCols().GetColumns() {
+		ord := -1
+		if c, ok := sch.GetAllCols().GetByNameCaseInsensitive(col.Name); ok {
+			ord = sch.GetAllCols().TagToIdx[c.Tag]
+		}
+		v.policyOrds = append(v.policyOrds, ord)
+	}
+	return v, nil
+}
+
+func (v *lineageVersion) row(ctx context.Context, key, value val.Tuple) (lineageRow, error) {
+	r := make(sql.Row, v.sch.GetAllCols().Size())
+	if err := v.conv.PutConverted(ctx, key, value, r); err != nil {
+		return lineageRow{}, err
+	}
+	return lineageRow{key: key, row: r}, nil
+}
+
+// allRows returns every row of the table.
+func (v *lineageVersion) allRows(ctx context.Context) ([]lineageRow, error) {
+	iter, err := v.rows.IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rows []lineageRow
+	for {
+		k, val, err := iter.Next(ctx)
+		if err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+		r, err := v.row(ctx, k, val)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r)
+	}
+}
+
+// primaryKey returns the JSON object of the primary key values of |r|.
+func (v *lineageVersion) primaryKey(r lineageRow) (interface{}, error) {
+	pk := make(map[string]interface{})
+	for i, col := range v.sch.GetAllCols().GetColumns() {
+		if col.IsPartOfPK {
+			pk[col.Name] = r.row[i]
+		}
+	}
+	doc, _, err := types.JSON.Convert(pk)
+	return doc, err
+}
+
+// lineageWalk tracks the identity of the rows of a table as its history is walked.
+type lineageWalk struct {
+	lt     *RowLineageTable
+	filter *dsess.RowPolicyFilter
+	// ids maps the key of every row of the table at the last commit walked to the identity of the row
+	ids  map[string]string
+	rows []sql.Row
+}
+
+// lineageCommit is a commit whose changes to the table are being walked.
+type lineageCommit struct {
+	hash hash.Hash
+	meta *datas.CommitMeta
+}
+
+// step walks the changes of the commit |cm| to the table, which is |version| at |cm| and |parent| at its first parent.
+// Either is nil if the table doesn't exist at that commit.
+func (w *lineageWalk) step(ctx *sql.Context, cm *doltdb.Commit, parent, version *lineageVersion) error {
+	if parent == nil && version == nil {
+		return nil
+	}
+	h, err := cm.HashOf()
+	if err != nil {
+		return err
+	}
+	meta, err := cm.GetCommitMeta(ctx)
+	if err != nil {
+		return err
+	}
+	c := lineageCommit{hash: h, meta: meta}
+
+	var removed, added []lineageRow
+	ids := w.ids
+	switch {
+	case parent == nil:
+		if added, err = version.allRows(ctx); err != nil {
+			return err
+		}
+	case version == nil:
+		if removed, err = parent.allRows(ctx); err != nil {
+			return err
+		}
+		w.ids = make(map[string]string)
+	case !parent.rows.KeyDesc().Equals(version.rows.KeyDesc()):
+		// the primary key was redefined, so every row was removed and added again
+		if removed, err = parent.allRows(ctx); err != nil {
+			return err
+		}
+		if added, err = version.allRows(ctx); err != nil {
+			return err
+		}
+		w.ids = make(map[string]string)
+	default:
+		err = prolly.DiffMaps(ctx, parent.rows, version.rows, false, func(_ context.Context, d tree.Diff) error {
+			switch d.Type {
+			case tree.RemovedDiff:
+				r, err := parent.row(ctx, val.Tuple(d.Key), val.Tuple(d.From))
+				removed = append(removed, r)
+				return err
+			case tree.AddedDiff:
+				r, err := version.row(ctx, val.Tuple(d.Key), val.Tuple(d.To))
+				added = append(added, r)
+				return err
+			default:
+				from, err := parent.row(ctx, val.Tuple(d.Key), val.Tuple(d.From))
+				if err != nil {
+					return err
+				}
+				to, err := version.row(ctx, val.Tuple(d.Key), val.Tuple(d.To))
+				if err != nil {
+					return err
+				}
+				return w.emit(ctx, c, ids[string(d.Key)], diffTypeModified, diff.PrimaryKeyMatch, nil, parent, &from, version, &to)
+			}
+		})
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+
+	var pairs []diff.RowPair
+	if len(removed) > 0 && len(added) > 0 {
+		removedRows := make([]sql.Row, len(removed))
+		for i := range removed {
+			removedRows[i] = removed[i].row
+		}
+		addedRows := make([]sql.Row, len(added))
+		for i := range added {
+			addedRows[i] = added[i].row
+		}
+		pairs, err = diff.NewRowLineageMatcher(parent.sch, version.sch, w.lt.threshold).Match(removedRows, addedRows)
+		if err != nil {
+			return err
+		}
+	}
+
+	fromMatched := make([]bool, len(removed))
+	toIds := make([]string, len(added))
+	for _, p := range pairs {
+		id := ids[string(removed[p.From].key)]
+		similarity := p.Similarity
+		if err = w.emit(ctx, c, id, diffTypeModified, p.Match, &similarity, parent, &removed[p.From], version, &added[p.To]); err != nil {
+			return err
+		}
+		fromMatched[p.From] = true
+		toIds[p.To] = id
+	}
+	for i := range removed {
+		if !fromMatched[i] {
+			if err = w.emit(ctx, c, ids[string(removed[i].key)], diffTypeRemoved, "", nil, parent, &removed[i], nil, nil); err != nil {
+				return err
+			}
+		}
+		delete(w.ids, string(removed[i].key))
+	}
+	for i := range added {
+		id := toIds[i]
+		if id == "" {
+			id = hash.Of(append(c.hash[:], added[i].key...)).String()
+			if err = w.emit(ctx, c, id, diffTypeAdded, "", nil, nil, nil, version, &added[i]); err != nil {
+				return err
+			}
+		}
+		w.ids[string(added[i].key)] = id
+	}
+	return nil
+}
+
+// emit adds a row for the change of commit |c| to the row with the identity |id| from |from| in |parent| to |to| in
+// |version|, unless the row policies of the table hide either side of the change.
+func (w *lineageWalk) emit(ctx *sql.Context, c lineageCommit, id, diffType string, match diff.LineageMatch, similarity *float64, parent *lineageVersion, from *lineageRow, version *lineageVersion, to *lineageRow) error {
+	var fromKey, toKey interface{}
+	var err error
+	if from != nil {
+		if ok, err := w.allows(ctx, parent, *from); err != nil || !ok {
+			return err
+		}
+		if fromKey, err = parent.primaryKey(*from); err != nil {
+			return err
+		}
+	}
+	if to != nil {
+		if ok, err := w.allows(ctx, version, *to); err != nil || !ok {
+			return err
+		}
+		if toKey, err = version.primaryKey(*to); err != nil {
+			return err
+		}
+	}
+
+	row := sql.Row{id, c.hash.String(), c.meta.Name, c.meta.Time(), diffType, nil, nil, fromKey, toKey}
+	if match != "" {
+		row[5] = string(match)
+	}
+	if similarity != nil {
+		row[6] = *similarity
+	}
+	w.rows = append(w.rows, row)
+	return nil
+}
+
+// allows returns whether the row policies of the table allow the user to see |r|, a row of |v|.
+func (w *lineageWalk) allows(ctx *sql.Context, v *lineageVersion, r lineageRow) (bool, error) {
+	if w.filter == nil {
+		return true, nil
+	}
+	row := make(sql.Row, len(v.policyOrds))
+	for i, ord := range v.policyOrds {
+		if ord >= 0 {
+			row[i] = r.row[ord]
+		}
+	}
+	return w.filter.Allows(ctx, row)
+}
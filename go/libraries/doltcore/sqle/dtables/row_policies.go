@@ -0,0 +1,281 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// newRowPolicyDiffIter returns |iter|, a row iterator of a dolt_diff or dolt_commit_diff table of the table
+// |tableName|, filtered to the rows allowed by the table's row policies for the current user. Policies are read from
+// |policyRoot|, and the from and to rows of |iter| must be converted to |targetSch|, the schema of the table in
+// |dataRoot|. A diff row is visible only if every side of it that's present is visible.
+func newRowPolicyDiffIter(ctx *sql.Context, policyRoot, dataRoot doltdb.RootValue, tableName doltdb.TableName, targetSch schema.Schema, iter sql.RowIter) (sql.RowIter, error) {
+	filter, err := dsess.GetRowPolicyFilter(ctx, policyRoot, dataRoot, tableName)
+	if err != nil {
+		_ = iter.Close(ctx)
+		return nil, err
+	}
+	if filter == nil {
+		return iter, nil
+	}
+	n := targetSch.GetAllCols().Size()
+	return &rowPolicyDiffIter{toFilter: filter, fromFilter: filter, toCols: n, fromCols: n, child: iter}, nil
+}
+
+// NewRowPolicyTableDeltaIter returns |iter|, a row iterator of the diff of |td| as returned by
+// NewDiffPartitionRowIter, filtered to the rows allowed by the table's row policies for the current user. Policies
+// are read from |policyRoot|, and each side of a diff row is checked against the schema of its side of |td|. A diff
+// row is visible only if every side of it that's present is visible.
+func NewRowPolicyTableDeltaIter(ctx *sql.Context, policyRoot doltdb.RootValue, td diff.TableDelta, iter sql.RowIter) (sql.RowIter, error) {
+	tableName := td.ToName
+	if td.ToTable == nil {
+		tableName = td.FromName
+	}
+
+	itr := &rowPolicyDiffIter{toCols: schemaSize(td.ToSch), fromCols: schemaSize(td.FromSch), child: iter}
+	var err error
+	if td.ToTable != nil {
+		if itr.toFilter, err = dsess.GetRowPolicyFilterForTable(ctx, policyRoot, tableName, td.ToTable); err != nil {
+			_ = iter.Close(ctx)
+			return nil, err
+		}
+	}
+	if td.FromTable != nil {
+		if itr.fromFilter, err = dsess.GetRowPolicyFilterForTable(ctx, policyRoot, tableName, td.FromTable); err != nil {
+			_ = iter.Close(ctx)
+			return nil, err
+		}
+	}
+	if itr.toFilter == nil && itr.fromFilter == nil {
+		return iter, nil
+	}
+	return itr, nil
+}
+
+type rowPolicyDiffIter struct {
+	// toFilter and fromFilter filter the to and from sides of a diff row, and are nil if that side isn't filtered
+	toFilter, fromFilter *dsess.RowPolicyFilter
+	// toCols and fromCols are the number of columns in the to and from sides of a diff row
+	toCols, fromCols int
+	child            sql.RowIter
+}
+
+var _ sql.RowIter = (*rowPolicyDiffIter)(nil)
+
+// Next implements the sql.RowIter interface
+func (itr *rowPolicyDiffIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		row, err := itr.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := itr.allows(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return row, nil
+		}
+	}
+}
+
+// allows returns whether the diff row |row| is visible to the user. Diff rows are laid out as the to columns, the to
+// commit and date, the from columns, the from commit and date, and the diff type.
+func (itr *rowPolicyDiffIter) allows(ctx *sql.Context, row sql.Row) (bool, error) {
+	diffType := row[len(row)-1]
+	if diffType != diffTypeRemoved && itr.toFilter != nil {
+		if ok, err := itr.toFilter.Allows(ctx, row[:itr.toCols]); err != nil || !ok {
+			return false, err
+		}
+	}
+	if diffType != diffTypeAdded && itr.fromFilter != nil {
+		fromEnd := len(row) - 3
+		if ok, err := itr.fromFilter.Allows(ctx, row[fromEnd-itr.fromCols:fromEnd]); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Close implements the sql.RowIter interface
+func (itr *rowPolicyDiffIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
+
+// newRowPolicyConflictIter returns |iter|, a row iterator of the dolt_conflicts table |ct|, filtered to the conflicts
+// allowed by the row policies of its table for the current user. Policies are read from |policyRoot|. A conflict is
+// visible only if each of its base, our and their rows that's present is visible.
+func newRowPolicyConflictIter(ctx *sql.Context, policyRoot doltdb.RootValue, ct ProllyConflictsTable, iter sql.RowIter) (sql.RowIter, error) {
+	itr := &rowPolicyConflictIter{
+		child:            iter,
+		ourDiffTypeIdx:   ct.sqlSch.Schema.IndexOfColName("our_diff_type"),
+		theirDiffTypeIdx: ct.sqlSch.Schema.IndexOfColName("their_diff_type"),
+		baseMapping:      ct.versionMappings.baseMapping,
+		ourMapping:       ct.versionMappings.ourMapping,
+		theirMapping:     ct.versionMappings.theirMapping,
+	}
+	var err error
+	if itr.baseFilter, err = dsess.GetRowPolicyFilterForSchema(ctx, policyRoot, ct.tblName, ct.baseSch); err != nil {
+		_ = iter.Close(ctx)
+		return nil, err
+	}
+	if itr.baseFilter == nil {
+		// the table has no row policies for the user, so none of its versions are filtered
+		return iter, nil
+	}
+	if itr.ourFilter, err = dsess.GetRowPolicyFilterForSchema(ctx, policyRoot, ct.tblName, ct.ourSch); err != nil {
+		_ = iter.Close(ctx)
+		return nil, err
+	}
+	if itr.theirFilter, err = dsess.GetRowPolicyFilterForSchema(ctx, policyRoot, ct.tblName, ct.theirSch); err != nil {
+		_ = iter.Close(ctx)
+		return nil, err
+	}
+	return itr, nil
+}
+
+type rowPolicyConflictIter struct {
+	baseFilter, ourFilter, theirFilter    *dsess.RowPolicyFilter
+	baseMapping, ourMapping, theirMapping val.OrdinalMapping
+	ourDiffTypeIdx, theirDiffTypeIdx      int
+	child                                 sql.RowIter
+}
+
+var _ sql.RowIter = (*rowPolicyConflictIter)(nil)
+
+// Next implements the sql.RowIter interface
+func (itr *rowPolicyConflictIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		row, err := itr.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := itr.allows(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return row, nil
+		}
+	}
+}
+
+// allows returns whether the conflict row |row| is visible to the user. The base row of a conflict is missing if
+// the row was added on both sides, and our or their row is missing if that side removed the row.
+func (itr *rowPolicyConflictIter) allows(ctx *sql.Context, row sql.Row) (bool, error) {
+	ourDiffType := row[itr.ourDiffTypeIdx]
+	theirDiffType := row[itr.theirDiffTypeIdx]
+	if ourDiffType != merge.ConflictDiffTypeAdded {
+		if ok, err := itr.baseFilter.Allows(ctx, mappedRow(row, itr.baseMapping)); err != nil || !ok {
+			return false, err
+		}
+	}
+	if ourDiffType != merge.ConflictDiffTypeRemoved {
+		if ok, err := itr.ourFilter.Allows(ctx, mappedRow(row, itr.ourMapping)); err != nil || !ok {
+			return false, err
+		}
+	}
+	if theirDiffType != merge.ConflictDiffTypeRemoved {
+		if ok, err := itr.theirFilter.Allows(ctx, mappedRow(row, itr.theirMapping)); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Close implements the sql.RowIter interface
+func (itr *rowPolicyConflictIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
+
+// newRowPolicyCVIter returns |iter|, a row iterator of the dolt_constraint_violations table of the table |tableName|,
+// filtered to the violations whose rows are allowed by the table's row policies for the current user. Policies are
+// read from |policyRoot|, and the rows of |iter| are those of |tbl|, whose schema is |sch|.
+func newRowPolicyCVIter(ctx *sql.Context, policyRoot doltdb.RootValue, tableName doltdb.TableName, tbl *doltdb.Table, sch schema.Schema, iter sql.RowIter) (sql.RowIter, error) {
+	filter, err := dsess.GetRowPolicyFilterForTable(ctx, policyRoot, tableName, tbl)
+	if err != nil {
+		_ = iter.Close(ctx)
+		return nil, err
+	}
+	if filter == nil {
+		return iter, nil
+	}
+
+	// violation rows are laid out as from_root_ish and violation_type, the primary key columns of the table, or the
+	// row hash of a keyless table, and the non-primary key columns of the table
+	allCols := sch.GetAllCols()
+	mapping := make(val.OrdinalMapping, allCols.Size())
+	o := 2
+	if schema.IsKeyless(sch) {
+		o++
+	}
+	for _, cols := range []*schema.ColCollection{sch.GetPKCols(), sch.GetNonPKCols()} {
+		_ = cols.Iter(func(tag uint64, _ schema.Column) (stop bool, err error) {
+			mapping[allCols.TagToIdx[tag]] = o
+			o++
+			return false, nil
+		})
+	}
+	return &rowPolicyCVIter{filter: filter, mapping: mapping, child: iter}, nil
+}
+
+type rowPolicyCVIter struct {
+	filter *dsess.RowPolicyFilter
+	// mapping maps the index of each column of the table to its index in a violation row
+	mapping val.OrdinalMapping
+	child   sql.RowIter
+}
+
+var _ sql.RowIter = (*rowPolicyCVIter)(nil)
+
+// Next implements the sql.RowIter interface
+func (itr *rowPolicyCVIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		row, err := itr.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := itr.filter.Allows(ctx, mappedRow(row, itr.mapping))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return row, nil
+		}
+	}
+}
+
+// Close implements the sql.RowIter interface
+func (itr *rowPolicyCVIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
+
+// mappedRow returns the row of a table contained in |row|, where |mapping| maps the index of each column of the
+// table to its index in |row|.
+func mappedRow(row sql.Row, mapping val.OrdinalMapping) sql.Row {
+	r := make(sql.Row, len(mapping))
+	for i, j := range mapping {
+		r[i] = row[j]
+	}
+	return r
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+var _ sql.Table = (*RowPoliciesTable)(nil)
+var _ sql.UpdatableTable = (*RowPoliciesTable)(nil)
+var _ sql.DeletableTable = (*RowPoliciesTable)(nil)
+var _ sql.InsertableTable = (*RowPoliciesTable)(nil)
+var _ sql.ReplaceableTable = (*RowPoliciesTable)(nil)
+var _ sql.IndexAddressableTable = (*RowPoliciesTable)(nil)
+
+// RowPoliciesTable is the system table that declares the row-level security policies of the tables in a database.
+// Each row names a table and a policy, the user and host patterns of the users the policy applies to, and the
+// predicate that the rows visible to, and writable by, those users must satisfy. Only users with the SUPER or GRANT
+// OPTION privilege may change it, as the policies restrict every other user.
+type RowPoliciesTable struct {
+	*writableSystemTable
+}
+
+var rowPoliciesTableColumns = []systemTableColumn{
+	{name: "table_name", tag: schema.DoltRowPoliciesTableNameTag, kind: types.StringKind, pk: true},
+	{name: "policy_name", tag: schema.DoltRowPoliciesPolicyNameTag, kind: types.StringKind, pk: true},
+	{name: "user", tag: schema.DoltRowPoliciesUserTag, kind: types.StringKind},
+	{name: "host", tag: schema.DoltRowPoliciesHostTag, kind: types.StringKind},
+	{name: "predicate", tag: schema.DoltRowPoliciesPredicateTag, kind: types.StringKind},
+}
+
+// NewRowPoliciesTable creates a RowPoliciesTable for the database |dbName|, stored in |backingTable|
+func NewRowPoliciesTable(_ *sql.Context, dbName string, backingTable VersionableTable) sql.Table {
+	return &RowPoliciesTable{&writableSystemTable{
+		name:         doltdb.RowPoliciesTableName,
+		dbName:       dbName,
+		columns:      rowPoliciesTableColumns,
+		backingTable: backingTable,
+		validate:     validateRowPolicyRow,
+		authorize:    dsess.AuthorizeRowPoliciesWrite,
+	}}
+}
+
+// NewEmptyRowPoliciesTable creates a RowPoliciesTable for the database |dbName|, which doesn't declare any row
+// policies yet
+func NewEmptyRowPoliciesTable(ctx *sql.Context, dbName string) sql.Table {
+	return NewRowPoliciesTable(ctx, dbName, nil)
+}
+
+// validateRowPolicyRow returns an error if |r| doesn't declare a valid row policy.
+func validateRowPolicyRow(r sql.Row) error {
+	table, _ := r[0].(string)
+	name, _ := r[1].(string)
+	user, _ := r[2].(string)
+	host, _ := r[3].(string)
+	predicate, _ := r[4].(string)
+	_, err := doltdb.NewRowPolicy(table, name, user, host, predicate)
+	return err
+}
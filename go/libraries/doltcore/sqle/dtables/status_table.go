@@ -173,7 +173,7 @@ func newStatusItr(ctx *sql.Context, st *StatusTable) (*StatusItr, error) {
 
 	for _, td := range stagedTables {
 		tblName := tableName(td)
-		if doltdb.IsFullTextTable(tblName) {
+		if doltdb.IsFullTextTable(tblName) || doltdb.IsVectorIndexTable(tblName) {
 			continue
 		}
 		if containsTableName(tblName, cvTables) {
@@ -187,7 +187,7 @@ func newStatusItr(ctx *sql.Context, st *StatusTable) (*StatusItr, error) {
 	}
 	for _, td := range unstagedTables {
 		tblName := tableName(td)
-		if doltdb.IsFullTextTable(tblName) {
+		if doltdb.IsFullTextTable(tblName) || doltdb.IsVectorIndexTable(tblName) {
 			continue
 		}
 		if containsTableName(tblName, cvTables) {
@@ -0,0 +1,76 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+var _ sql.Table = (*TestsTable)(nil)
+var _ sql.UpdatableTable = (*TestsTable)(nil)
+var _ sql.DeletableTable = (*TestsTable)(nil)
+var _ sql.InsertableTable = (*TestsTable)(nil)
+var _ sql.ReplaceableTable = (*TestsTable)(nil)
+var _ sql.IndexAddressableTable = (*TestsTable)(nil)
+
+// TestsTable is the system table that stores SQL tests. Each row names a test and its optional group, and declares a
+// query along with an assertion about the query's result, which dolt_test_run() checks against any revision.
+type TestsTable struct {
+	*writableSystemTable
+}
+
+var testsTableColumns = []systemTableColumn{
+	{name: "test_name", tag: schema.DoltTestsNameTag, kind: types.StringKind, pk: true},
+	{name: "test_group", tag: schema.DoltTestsGroupTag, kind: types.StringKind, nullable: true},
+	{name: "test_query", tag: schema.DoltTestsQueryTag, kind: types.StringKind},
+	{name: "assertion_type", tag: schema.DoltTestsAssertionTypeTag, kind: types.StringKind},
+	{name: "assertion_comparator", tag: schema.DoltTestsAssertionComparatorTag, kind: types.StringKind, nullable: true},
+	{name: "assertion_value", tag: schema.DoltTestsAssertionValueTag, kind: types.StringKind, nullable: true},
+}
+
+// NewTestsTable creates a TestsTable for the database |dbName|, stored in |backingTable|
+func NewTestsTable(_ *sql.Context, dbName string, backingTable VersionableTable) sql.Table {
+	return &TestsTable{&writableSystemTable{
+		name:         doltdb.TestsTableName,
+		dbName:       dbName,
+		columns:      testsTableColumns,
+		backingTable: backingTable,
+		validate:     validateTestRow,
+	}}
+}
+
+// NewEmptyTestsTable creates a TestsTable for the database |dbName|, which doesn't have any tests yet
+func NewEmptyTestsTable(ctx *sql.Context, dbName string) sql.Table {
+	return NewTestsTable(ctx, dbName, nil)
+}
+
+// validateTestRow returns an error if |r| doesn't declare a valid test.
+func validateTestRow(r sql.Row) error {
+	vals := make([]string, len(r))
+	for i := range r {
+		vals[i], _ = r[i].(string)
+	}
+	if vals[0] == "" {
+		return fmt.Errorf("test_name of a test must not be empty")
+	}
+	_, err := doltdb.NewDoltTest(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5])
+	return err
+}
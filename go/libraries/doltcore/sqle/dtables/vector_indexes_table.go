@@ -0,0 +1,104 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	sqlTypes "github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+var _ sql.Table = (*VectorIndexesTable)(nil)
+var _ sql.IndexAddressableTable = (*VectorIndexesTable)(nil)
+
+// VectorIndexesTable is the system table that declares vector indexes. Each row names a table, one of its vector
+// indexes, and the column it indexes. The table is read-only; vector indexes are created with CREATE VECTOR INDEX and
+// removed with DROP INDEX, which also build and remove the tables storing their buckets.
+type VectorIndexesTable struct {
+	backingTable VersionableTable
+}
+
+func (i *VectorIndexesTable) Name() string {
+	return doltdb.VectorIndexesTableName
+}
+
+func (i *VectorIndexesTable) String() string {
+	return doltdb.VectorIndexesTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the dolt_vector_indexes system table.
+func (i *VectorIndexesTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "table_name", Type: sqlTypes.Text, Source: doltdb.VectorIndexesTableName, PrimaryKey: true},
+		{Name: "index_name", Type: sqlTypes.Text, Source: doltdb.VectorIndexesTableName, PrimaryKey: true},
+		{Name: "column_name", Type: sqlTypes.Text, Source: doltdb.VectorIndexesTableName, PrimaryKey: false, Nullable: true},
+	}
+}
+
+func (i *VectorIndexesTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.
+func (i *VectorIndexesTable) Partitions(context *sql.Context) (sql.PartitionIter, error) {
+	if i.backingTable == nil {
+		// no backing table; return an empty iter.
+		return index.SinglePartitionIterFromNomsMap(nil), nil
+	}
+	return i.backingTable.Partitions(context)
+}
+
+func (i *VectorIndexesTable) PartitionRows(context *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	if i.backingTable == nil {
+		// no backing table; return an empty iter.
+		return sql.RowsToRowIter(), nil
+	}
+
+	return i.backingTable.PartitionRows(context, partition)
+}
+
+// NewVectorIndexesTable creates a VectorIndexesTable
+func NewVectorIndexesTable(_ *sql.Context, backingTable VersionableTable) sql.Table {
+	return &VectorIndexesTable{backingTable: backingTable}
+}
+
+// NewEmptyVectorIndexesTable creates a VectorIndexesTable
+func NewEmptyVectorIndexesTable(_ *sql.Context) sql.Table {
+	return &VectorIndexesTable{}
+}
+
+func (i *VectorIndexesTable) LockedToRoot(ctx *sql.Context, root doltdb.RootValue) (sql.IndexAddressableTable, error) {
+	if i.backingTable == nil {
+		return i, nil
+	}
+	return i.backingTable.LockedToRoot(ctx, root)
+}
+
+// IndexedAccess implements IndexAddressableTable, but VectorIndexesTable has no indexes.
+// Thus, this should never be called.
+func (i *VectorIndexesTable) IndexedAccess(lookup sql.IndexLookup) sql.IndexedTable {
+	panic("Unreachable")
+}
+
+// GetIndexes implements IndexAddressableTable, but VectorIndexesTable has no indexes.
+func (i *VectorIndexesTable) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
+	return nil, nil
+}
+
+func (i *VectorIndexesTable) PreciseMatch() bool {
+	return true
+}
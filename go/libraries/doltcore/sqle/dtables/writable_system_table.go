@@ -0,0 +1,325 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	sqlTypes "github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// systemTableColumn declares a column of a writableSystemTable. Both the SQL schema of the system table and the
+// schema of the table that stores it are derived from it.
+type systemTableColumn struct {
+	name     string
+	tag      uint64
+	kind     types.NomsKind
+	pk       bool
+	nullable bool
+}
+
+// writableSystemTable implements a system table that is stored as a table in the root of its database, so that it's
+// versioned like a user table, and that users edit with SQL. The stored table is created by the first write to the
+// system table. Tables such as dolt_tests embed it, and supply their columns and the checks their writes must pass.
+type writableSystemTable struct {
+	name    string
+	dbName  string
+	columns []systemTableColumn
+	// backingTable is the stored table, or nil if it hasn't been created yet
+	backingTable VersionableTable
+	// validate returns an error if a row inserted into, or updated in, the table isn't valid
+	validate func(r sql.Row) error
+	// authorize returns an error if the user of |ctx| may not write to the table, or is nil if any user may
+	authorize func(ctx *sql.Context) error
+}
+
+var _ sql.Table = (*writableSystemTable)(nil)
+var _ sql.UpdatableTable = (*writableSystemTable)(nil)
+var _ sql.DeletableTable = (*writableSystemTable)(nil)
+var _ sql.InsertableTable = (*writableSystemTable)(nil)
+var _ sql.ReplaceableTable = (*writableSystemTable)(nil)
+var _ sql.IndexAddressableTable = (*writableSystemTable)(nil)
+
+func (st *writableSystemTable) Name() string {
+	return st.name
+}
+
+func (st *writableSystemTable) String() string {
+	return st.name
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the system table.
+func (st *writableSystemTable) Schema() sql.Schema {
+	sch := make(sql.Schema, len(st.columns))
+	for i, col := range st.columns {
+		sch[i] = &sql.Column{
+			Name:       col.name,
+			Type:       sqlTypeForKind(col.kind),
+			Source:     st.name,
+			PrimaryKey: col.pk,
+			Nullable:   col.nullable,
+		}
+	}
+	return sch
+}
+
+// sqlTypeForKind returns the SQL type of a system table column of kind |kind|.
+func sqlTypeForKind(kind types.NomsKind) sql.Type {
+	switch kind {
+	case types.BoolKind:
+		return sqlTypes.Boolean
+	case types.StringKind:
+		return sqlTypes.Text
+	default:
+		panic(fmt.Sprintf("unsupported system table column kind %s", kind.String()))
+	}
+}
+
+// storedSchema returns the schema of the table that stores the system table.
+func (st *writableSystemTable) storedSchema() (schema.Schema, error) {
+	cols := make([]schema.Column, len(st.columns))
+	for i, col := range st.columns {
+		cols[i] = schema.Column{
+			Name:       col.name,
+			Tag:        col.tag,
+			Kind:       col.kind,
+			IsPartOfPK: col.pk,
+			TypeInfo:   typeinfo.FromKind(col.kind),
+		}
+	}
+	return schema.NewSchema(schema.NewColCollection(cols...), nil, schema.Collation_Default, nil, nil)
+}
+
+func (st *writableSystemTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.
+func (st *writableSystemTable) Partitions(context *sql.Context) (sql.PartitionIter, error) {
+	if st.backingTable == nil {
+		// no backing table; return an empty iter.
+		return index.SinglePartitionIterFromNomsMap(nil), nil
+	}
+	return st.backingTable.Partitions(context)
+}
+
+func (st *writableSystemTable) PartitionRows(context *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	if st.backingTable == nil {
+		// no backing table; return an empty iter.
+		return sql.RowsToRowIter(), nil
+	}
+	return st.backingTable.PartitionRows(context, partition)
+}
+
+// Replacer returns a RowReplacer for this table. The RowReplacer will have Insert and optionally Delete called once
+// for each row, followed by a call to Close() when all rows have been processed.
+func (st *writableSystemTable) Replacer(*sql.Context) sql.RowReplacer {
+	return &systemTableWriter{st: st}
+}
+
+// Updater returns a RowUpdater for this table. The RowUpdater will have Update called once for each row to be
+// updated, followed by a call to Close() when all rows have been processed.
+func (st *writableSystemTable) Updater(*sql.Context) sql.RowUpdater {
+	return &systemTableWriter{st: st}
+}
+
+// Inserter returns an Inserter for this table. The Inserter will get one call to Insert() for each row to be
+// inserted, and will end with a call to Close() to finalize the insert operation.
+func (st *writableSystemTable) Inserter(*sql.Context) sql.RowInserter {
+	return &systemTableWriter{st: st}
+}
+
+// Deleter returns a RowDeleter for this table. The RowDeleter will get one call to Delete for each row to be deleted,
+// and will end with a call to Close() to finalize the delete operation.
+func (st *writableSystemTable) Deleter(*sql.Context) sql.RowDeleter {
+	return &systemTableWriter{st: st}
+}
+
+func (st *writableSystemTable) LockedToRoot(ctx *sql.Context, root doltdb.RootValue) (sql.IndexAddressableTable, error) {
+	if st.backingTable == nil {
+		return st, nil
+	}
+	return st.backingTable.LockedToRoot(ctx, root)
+}
+
+// IndexedAccess implements IndexAddressableTable, but system tables have no indexes.
+// Thus, this should never be called.
+func (st *writableSystemTable) IndexedAccess(lookup sql.IndexLookup) sql.IndexedTable {
+	panic("Unreachable")
+}
+
+// GetIndexes implements IndexAddressableTable, but system tables have no indexes.
+func (st *writableSystemTable) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
+	return nil, nil
+}
+
+func (st *writableSystemTable) PreciseMatch() bool {
+	return true
+}
+
+var _ sql.RowReplacer = (*systemTableWriter)(nil)
+var _ sql.RowUpdater = (*systemTableWriter)(nil)
+var _ sql.RowInserter = (*systemTableWriter)(nil)
+var _ sql.RowDeleter = (*systemTableWriter)(nil)
+
+// systemTableWriter writes the rows of a writableSystemTable to the table that stores it, creating that table if
+// it doesn't exist yet.
+type systemTableWriter struct {
+	st                      *writableSystemTable
+	errDuringStatementBegin error
+	tableWriter             dsess.TableWriter
+}
+
+// Insert inserts the row given, returning an error if it cannot. Insert will be called once for each row to process
+// for the insert operation, which may involve many rows. After all rows in an operation have been processed, Close
+// is called.
+func (w *systemTableWriter) Insert(ctx *sql.Context, r sql.Row) error {
+	if err := w.errDuringStatementBegin; err != nil {
+		return err
+	}
+	if err := w.st.validate(r); err != nil {
+		return err
+	}
+	return w.tableWriter.Insert(ctx, r)
+}
+
+// Update the given row. Provides both the old and new rows.
+func (w *systemTableWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := w.errDuringStatementBegin; err != nil {
+		return err
+	}
+	if err := w.st.validate(new); err != nil {
+		return err
+	}
+	return w.tableWriter.Update(ctx, old, new)
+}
+
+// Delete deletes the given row. Returns ErrDeleteRowNotFound if the row was not found. Delete will be called once for
+// each row to process for the delete operation, which may involve many rows. After all rows have been processed,
+// Close is called.
+func (w *systemTableWriter) Delete(ctx *sql.Context, r sql.Row) error {
+	if err := w.errDuringStatementBegin; err != nil {
+		return err
+	}
+	return w.tableWriter.Delete(ctx, r)
+}
+
+// StatementBegin is called before the first operation of a statement. Integrators should mark the state of the data
+// in some way that it may be returned to in the case of an error.
+func (w *systemTableWriter) StatementBegin(ctx *sql.Context) {
+	w.errDuringStatementBegin = w.statementBegin(ctx)
+}
+
+func (w *systemTableWriter) statementBegin(ctx *sql.Context) error {
+	if w.st.authorize != nil {
+		if err := w.st.authorize(ctx); err != nil {
+			return err
+		}
+	}
+
+	dbName := w.st.dbName
+	tableName := doltdb.TableName{Name: w.st.name}
+	dSess := dsess.DSessFromSess(ctx.Session)
+	dbState, ok, err := dSess.LookupDbState(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no root value found in session")
+	}
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return fmt.Errorf("no root value found in session")
+	}
+
+	found, err := roots.Working.HasTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		sch, err := w.st.storedSchema()
+		if err != nil {
+			return err
+		}
+
+		// underlying table doesn't exist. Record this, then create the table.
+		newRootValue, err := doltdb.CreateEmptyTable(ctx, roots.Working, tableName, sch)
+		if err != nil {
+			return err
+		}
+
+		if dbState.WorkingSet() == nil {
+			return doltdb.ErrOperationNotSupportedInDetachedHead
+		}
+
+		// We use WriteSession.SetWorkingSet instead of DoltSession.SetWorkingRoot because we want to avoid modifying the root
+		// until the end of the transaction, but we still want the WriteSession to be able to find the newly
+		// created table.
+		if ws := dbState.WriteSession(); ws != nil {
+			err = ws.SetWorkingSet(ctx, dbState.WorkingSet().WithWorkingRoot(newRootValue))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err = dSess.SetWorkingRoot(ctx, dbName, newRootValue); err != nil {
+			return err
+		}
+	}
+
+	if ws := dbState.WriteSession(); ws != nil {
+		tableWriter, err := ws.GetTableWriter(ctx, tableName, dbName, dSess.SetWorkingRoot, false)
+		if err != nil {
+			return err
+		}
+		w.tableWriter = tableWriter
+		tableWriter.StatementBegin(ctx)
+	}
+	return nil
+}
+
+// DiscardChanges is called if a statement encounters an error, and all current changes since the statement beginning
+// should be discarded.
+func (w *systemTableWriter) DiscardChanges(ctx *sql.Context, errorEncountered error) error {
+	if w.tableWriter != nil {
+		return w.tableWriter.DiscardChanges(ctx, errorEncountered)
+	}
+	return nil
+}
+
+// StatementComplete is called after the last operation of the statement, indicating that it has successfully completed.
+// The mark set in StatementBegin may be removed, and a new one should be created on the next StatementBegin.
+func (w *systemTableWriter) StatementComplete(ctx *sql.Context) error {
+	if w.tableWriter != nil {
+		return w.tableWriter.StatementComplete(ctx)
+	}
+	return nil
+}
+
+// Close finalizes the write operation, persisting the result.
+func (w *systemTableWriter) Close(ctx *sql.Context) error {
+	if w.tableWriter != nil {
+		return w.tableWriter.Close(ctx)
+	}
+	return nil
+}
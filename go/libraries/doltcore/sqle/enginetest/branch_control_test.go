@@ -27,6 +27,7 @@ import (
 	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 )
 
 // BranchControlTest is used to define a test using the branch control system. The root account is used with any queries
@@ -800,6 +801,64 @@ var BranchControlTests = []BranchControlTest{
 			},
 		},
 	},
+	{
+		Name: "Require admin to modify the protection table",
+		SetUpScript: []string{
+			"DELETE FROM dolt_branch_control WHERE user = '%';",
+			"INSERT INTO dolt_branch_control VALUES ('%', '%', 'root', 'localhost', 'admin');",
+			"CREATE USER a@localhost;",
+			"CREATE USER b@localhost;",
+			"GRANT ALL ON *.* TO a@localhost;",
+			"REVOKE SUPER ON *.* FROM a@localhost;",
+			"GRANT ALL ON *.* TO b@localhost;",
+			"REVOKE SUPER ON *.* FROM b@localhost;",
+			"INSERT INTO dolt_branch_control VALUES ('%', 'prefix%', 'a', 'localhost', 'admin'), ('%', 'prefix%', 'b', 'localhost', 'write');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:  "a",
+				Host:  "localhost",
+				Query: "INSERT INTO dolt_branch_protection VALUES ('%', 'prefix1', 'no_delete');",
+				Expected: []sql.Row{
+					{types.NewOkResult(1)},
+				},
+			},
+			{
+				User:        "a",
+				Host:        "localhost",
+				Query:       "INSERT INTO dolt_branch_protection VALUES ('%', 'main', 'no_delete');",
+				ExpectedErr: dtables.ErrModifyingProtectionRow,
+			},
+			{
+				User:        "b",
+				Host:        "localhost",
+				Query:       "DELETE FROM dolt_branch_protection WHERE branch = 'prefix1';",
+				ExpectedErr: dtables.ErrModifyingProtectionRow,
+			},
+			{
+				User:  "b",
+				Host:  "localhost",
+				Query: "CALL DOLT_BRANCH('prefix1');",
+				Expected: []sql.Row{
+					{0},
+				},
+			},
+			{ // Protection rules apply to admins as well
+				User:        "a",
+				Host:        "localhost",
+				Query:       "CALL DOLT_BRANCH('-d', 'prefix1');",
+				ExpectedErr: branch_control.ErrProtectedBranch,
+			},
+			{
+				User:  "a",
+				Host:  "localhost",
+				Query: "DELETE FROM dolt_branch_protection WHERE branch = 'prefix1';",
+				Expected: []sql.Row{
+					{types.NewOkResult(1)},
+				},
+			},
+		},
+	},
 	{
 		Name: "Deleting entries works",
 		SetUpScript: []string{
@@ -1361,10 +1420,84 @@ var BranchControlTests = []BranchControlTest{
 			},
 		},
 	},
+	{
+		Name: "Branch policies only delete branches that both the caller and the policy creator can delete",
+		SetUpScript: []string{
+			"DELETE FROM dolt_branch_control WHERE user = '%';",
+			"INSERT INTO dolt_branch_control VALUES ('%', '%', 'root', 'localhost', 'admin');",
+			"CREATE USER testuser@localhost;",
+			"GRANT ALL ON *.* TO testuser@localhost;",
+			"REVOKE SUPER ON *.* FROM testuser@localhost;",
+			"INSERT INTO dolt_branch_control VALUES ('%', 'ci/own%', 'testuser', 'localhost', 'write');",
+			"CALL DOLT_BRANCH('ci/own');",
+			"CALL DOLT_BRANCH('ci/other');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "INSERT INTO dolt_branch_policies (name, pattern, merged_into) VALUES ('ci', 'ci/*', 'main');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "CALL DOLT_BRANCH_PRUNE('--dry-run');",
+				Expected: []sql.Row{{"branch", "ci/own", doltCommit, "ci"}},
+			},
+			{ // root may delete every branch, but testuser created the policy
+				User:     "root",
+				Host:     "localhost",
+				Query:    "CALL DOLT_BRANCH_PRUNE('--dry-run');",
+				Expected: []sql.Row{{"branch", "ci/own", doltCommit, "ci"}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "UPDATE dolt_branch_policies SET pattern = 'ci/o*';",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{ // root now owns the policy
+				User:     "root",
+				Host:     "localhost",
+				Query:    "UPDATE dolt_branch_policies SET pattern = 'ci/*';",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				User:           "testuser",
+				Host:           "localhost",
+				Query:          "DELETE FROM dolt_branch_policies;",
+				ExpectedErrStr: "`testuser`@`localhost` cannot change the branch policy 'ci' created by `root`@`localhost`",
+			},
+			{ // testuser still may not delete ci/other
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "CALL DOLT_BRANCH_PRUNE('--dry-run');",
+				Expected: []sql.Row{{"branch", "ci/own", doltCommit, "ci"}},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "CALL DOLT_BRANCH_PRUNE();",
+				Expected: []sql.Row{{"branch", "ci/other", doltCommit, "ci"}, {"branch", "ci/own", doltCommit, "ci"}},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT name FROM dolt_branches;",
+				Expected: []sql.Row{{"main"}},
+			},
+		},
+	},
 }
 
 func TestBranchControl(t *testing.T) {
-	for _, test := range BranchControlTests {
+	runBranchControlTests(t, BranchControlTests)
+}
+
+// runBranchControlTests runs each of |tests| against a new engine, running every assertion as its user.
+func runBranchControlTests(t *testing.T, tests []BranchControlTest) {
+	for _, test := range tests {
 		harness := newDoltHarness(t)
 		defer harness.Close()
 		t.Run(test.Name, func(t *testing.T) {
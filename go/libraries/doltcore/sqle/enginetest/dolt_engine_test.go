@@ -1264,6 +1264,66 @@ func TestDoltRebasePrepared(t *testing.T) {
 	RunDoltRebasePreparedTests(t, h)
 }
 
+func TestDoltMergeStrategies(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltMergeStrategiesTests(t, h)
+}
+
+func TestDoltTestsTable(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltTestsTableTests(t, h)
+}
+
+func TestDoltBisect(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltBisectTests(t, h)
+}
+
+func TestDoltBlameCells(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltBlameCellsTests(t, h)
+}
+
+func TestDoltRowLineage(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltRowLineageTests(t, h)
+}
+
+func TestDoltBranchPolicies(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltBranchPoliciesTests(t, h)
+}
+
+func TestDoltBranchProtection(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltBranchProtectionTests(t, h)
+}
+
+func TestDoltHistoryWindow(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltHistoryWindowTests(t, h)
+}
+
+func TestDoltMaterializedView(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltMaterializedViewTests(t, h)
+}
+
+func TestDoltVectorIndexes(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltVectorIndexTests(t, h)
+}
+
+func TestDoltWaitForCommit(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltWaitForCommitTests(t, h)
+}
+
+func TestDoltRerere(t *testing.T) {
+	h := newDoltEnginetestHarness(t)
+	RunDoltRerereTests(t, h)
+}
+
 func TestDoltRevert(t *testing.T) {
 	h := newDoltEnginetestHarness(t)
 	RunDoltRevertTests(t, h)
@@ -39,6 +39,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 	"github.com/dolthub/dolt/go/store/datas"
 	"github.com/dolthub/dolt/go/store/types"
 )
@@ -50,6 +51,7 @@ var skipPrepared bool
 func init() {
 	sqle.MinRowsPerPartition = 8
 	sqle.MaxRowsPerPartition = 1024
+	dtables.MinParallelDiffRows = 64
 
 	if v := os.Getenv(skipPreparedFlag); v != "" {
 		skipPrepared = true
@@ -894,6 +896,126 @@ func RunDoltRebasePreparedTests(t *testing.T, h DoltEnginetestHarness) {
 	}
 }
 
+func RunDoltMergeStrategiesTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range MergeStrategiesScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltTestsTableTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltTestsScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltBisectTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltBisectScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltBlameCellsTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltBlameCellsScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltRowLineageTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltRowLineageScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltBranchPoliciesTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltBranchPoliciesScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltBranchProtectionTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltBranchProtectionScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltHistoryWindowTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltHistoryWindowScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltMaterializedViewTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltMaterializedViewScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltVectorIndexTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltVectorIndexScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltWaitForCommitTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltWaitForCommitScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
+func RunDoltRerereTests(t *testing.T, h DoltEnginetestHarness) {
+	for _, script := range DoltRerereScriptTests {
+		func() {
+			h := h.NewHarness(t)
+			defer h.Close()
+			enginetest.TestScript(t, h, script)
+		}()
+	}
+}
+
 func RunDoltRevertTests(t *testing.T, h DoltEnginetestHarness) {
 	for _, script := range RevertScripts {
 		// harness can't reset effectively. Use a new harness for each script
@@ -253,6 +253,7 @@ func (d *DoltHarness) NewEngine(t *testing.T) (enginetest.QueryEngine, error) {
 			return nil, err
 		}
 		e.Analyzer.ExecBuilder = rowexec.NewOverrideBuilder(kvexec.Builder{})
+		e.Parser = sqle.NewMaterializedViewParser(sqle.NewVectorIndexParser(sqle.NewHistoryWindowParser(e.Parser)))
 		d.engine = e
 
 		ctx := enginetest.NewContext(d)
@@ -377,9 +378,18 @@ func (d *DoltHarness) Parallelism() int {
 }
 
 func (d *DoltHarness) NewContext() *sql.Context {
+	d.setQueryRunner(d.session)
 	return sql.NewContext(context.Background(), sql.WithSession(d.session))
 }
 
+// setQueryRunner makes |sess| run the statements of stored procedures on the harness's engine, like the sessions of
+// a SqlEngine do.
+func (d *DoltHarness) setQueryRunner(sess *dsess.DoltSession) {
+	if d.engine != nil {
+		sess.SetQueryRunner(d.engine)
+	}
+}
+
 func (d *DoltHarness) NewContextWithClient(client sql.Client) *sql.Context {
 	return sql.NewContext(context.Background(), sql.WithSession(d.newSessionWithClient(client)))
 }
@@ -394,8 +404,9 @@ func (d *DoltHarness) newSessionWithClient(client sql.Client) *dsess.DoltSession
 	pro := d.session.Provider()
 
 	dSession, err := dsess.NewDoltSession(sql.NewBaseSessionWithClientServer("address", client, 1), pro.(dsess.DoltDatabaseProvider), localConfig, d.branchControl, d.statsPro, writer.NewWriteSession)
-	dSession.SetCurrentDatabase("mydb")
 	require.NoError(d.t, err)
+	dSession.SetCurrentDatabase("mydb")
+	d.setQueryRunner(dSession)
 	return dSession
 }
 
@@ -433,6 +444,7 @@ func (d *DoltHarness) NewDatabases(names ...string) []sql.Database {
 
 	e := enginetest.NewEngineWithProvider(d.t, d, d.provider)
 	require.NoError(d.t, err)
+	e.Parser = sqle.NewMaterializedViewParser(sqle.NewVectorIndexParser(sqle.NewHistoryWindowParser(e.Parser)))
 	d.engine = e
 
 	for _, name := range names {
@@ -0,0 +1,226 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/enginetest"
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/bisect"
+)
+
+// bisectMessageValidator validates a dolt_bisect message that embeds commit hashes, by matching the start and end of
+// the message.
+type bisectMessageValidator struct {
+	prefix string
+	suffix string
+}
+
+var _ enginetest.CustomValueValidator = &bisectMessageValidator{}
+
+func (v *bisectMessageValidator) Validate(val interface{}) (bool, error) {
+	msg, ok := val.(string)
+	if !ok {
+		return false, nil
+	}
+	return strings.HasPrefix(msg, v.prefix) && strings.HasSuffix(msg, v.suffix), nil
+}
+
+var bisectSetUpScript = []string{
+	"create table t (pk int primary key, v int);",
+	"call dolt_commit('-Am', 'create table t');",
+	"insert into t values (1, 1);",
+	"call dolt_commit('-am', 'row 1');",
+	"insert into t values (2, 2);",
+	"call dolt_commit('-am', 'row 2');",
+	"insert into t values (3, -3);",
+	"call dolt_commit('-am', 'add bad row');",
+	"insert into t values (4, 4);",
+	"call dolt_commit('-am', 'row 4');",
+	"insert into t values (5, 5);",
+	"call dolt_commit('-am', 'row 5');",
+}
+
+var DoltBisectScriptTests = []queries.ScriptTest{
+	{
+		Name:        "dolt_bisect errors",
+		SetUpScript: bisectSetUpScript,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "call dolt_bisect();",
+				ExpectedErrStr: "error: missing bisect subcommand; expected one of start, good, bad, skip, reset, log, run",
+			},
+			{
+				Query:          "call dolt_bisect('log');",
+				ExpectedErrStr: bisect.ErrNoBisectInProgress.Error(),
+			},
+			{
+				Query:          "call dolt_bisect('good');",
+				ExpectedErrStr: bisect.ErrNoBisectInProgress.Error(),
+			},
+			{
+				Query:          "call dolt_bisect('reset');",
+				ExpectedErrStr: bisect.ErrNoBisectInProgress.Error(),
+			},
+			{
+				Query:    "call dolt_bisect('start');",
+				Expected: []sql.Row{{0, nil, "status: waiting for both good and bad commits"}},
+			},
+			{
+				Query:          "call dolt_bisect('start');",
+				ExpectedErrStr: bisect.ErrBisectInProgress.Error(),
+			},
+			{
+				Query:          "call dolt_bisect('bad', 'HEAD', 'HEAD~1');",
+				ExpectedErrStr: "error: only one commit can be marked bad at a time",
+			},
+			{
+				Query:          "call dolt_bisect('good', 'doesnotexist');",
+				ExpectedErrStr: "branch not found: doesnotexist",
+			},
+			{
+				Query:          "call dolt_bisect('run', 'select true');",
+				ExpectedErrStr: "error: bisect run requires both a good and a bad commit to be marked",
+			},
+			{
+				Query:          "call dolt_bisect('frobnicate');",
+				ExpectedErrStr: "error: unknown bisect subcommand 'frobnicate'",
+			},
+			{
+				Query:    "call dolt_bisect('reset');",
+				Expected: []sql.Row{{0, nil, "bisect reset"}},
+			},
+		},
+	},
+	{
+		Name:        "dolt_bisect: marking commits by hand",
+		SetUpScript: bisectSetUpScript,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_bisect('start');",
+				Expected: []sql.Row{{0, nil, "status: waiting for both good and bad commits"}},
+			},
+			{
+				Query:    "call dolt_bisect('bad');",
+				Expected: []sql.Row{{0, nil, "status: waiting for good commit(s), bad commit known"}},
+			},
+			{
+				Query: "call dolt_bisect('good', 'HEAD~4');",
+				Expected: []sql.Row{{0, doltCommit, &bisectMessageValidator{
+					prefix: "Bisecting: 1 revision(s) left to test after this (roughly 1 step(s))\n[",
+					suffix: "] add bad row",
+				}}},
+			},
+			{
+				Query:    "set @bad = (select commit_hash from dolt_log where message = 'add bad row');",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "select count(*) from t as of @bad where v < 0;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query: "call dolt_bisect('bad');",
+				Expected: []sql.Row{{0, doltCommit, &bisectMessageValidator{
+					prefix: "Bisecting: 0 revision(s) left to test after this (roughly 0 step(s))\n[",
+					suffix: "] row 2",
+				}}},
+			},
+			{
+				Query: "call dolt_bisect('good');",
+				Expected: []sql.Row{{0, doltCommit, &bisectMessageValidator{
+					suffix: "] add bad row",
+				}}},
+			},
+			{
+				Query: "call dolt_bisect('log');",
+				Expected: []sql.Row{{0, nil, &bisectMessageValidator{
+					prefix: "dolt bisect start\ndolt bisect bad ",
+				}}},
+			},
+			{
+				Query:    "call dolt_bisect('reset');",
+				Expected: []sql.Row{{0, nil, "bisect reset"}},
+			},
+		},
+	},
+	{
+		Name:        "dolt_bisect: run a query against each commit",
+		SetUpScript: bisectSetUpScript,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_bisect('start', 'HEAD', 'HEAD~4');",
+				Expected: []sql.Row{{0, doltCommit, &bisectMessageValidator{prefix: "Bisecting: "}}},
+			},
+			{
+				Query:          "call dolt_bisect('run', 'select * from t');",
+				ExpectedErrStr: "bisect query must return exactly one row with one column",
+			},
+			{
+				Query: "call dolt_bisect('run', 'select count(*) = 0 from t where v < 0');",
+				Expected: []sql.Row{{0, doltCommit, &bisectMessageValidator{
+					suffix: "] add bad row",
+				}}},
+			},
+			{
+				Query:    "select count(*) from t where v < 0;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "call dolt_bisect('reset');",
+				Expected: []sql.Row{{0, nil, "bisect reset"}},
+			},
+		},
+	},
+	{
+		Name: "dolt_bisect: bug introduced on a merged branch",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int);",
+			"call dolt_commit('-Am', 'create table t');",
+			"call dolt_branch('feature');",
+			"insert into t values (1, 1);",
+			"call dolt_commit('-am', 'main row 1');",
+			"insert into t values (2, 2);",
+			"call dolt_commit('-am', 'main row 2');",
+			"call dolt_checkout('feature');",
+			"insert into t values (10, 10);",
+			"call dolt_commit('-am', 'feature row 10');",
+			"insert into t values (11, -11);",
+			"call dolt_commit('-am', 'feature bad row');",
+			"insert into t values (12, 12);",
+			"call dolt_commit('-am', 'feature row 12');",
+			"call dolt_checkout('main');",
+			"call dolt_merge('feature', '--no-ff', '-m', 'merge feature');",
+			"insert into t values (3, 3);",
+			"call dolt_commit('-am', 'main row 3');",
+			"set @good = (select commit_hash from dolt_log where message = 'create table t');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_bisect('start', 'main', @good);",
+				Expected: []sql.Row{{0, doltCommit, &bisectMessageValidator{prefix: "Bisecting: "}}},
+			},
+			{
+				Query: "call dolt_bisect('run', 'select count(*) = 0 from t where v < 0');",
+				Expected: []sql.Row{{0, doltCommit, &bisectMessageValidator{
+					suffix: "] feature bad row",
+				}}},
+			},
+		},
+	},
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var blameCellsSetUpScript = []string{
+	"create table t (pk int primary key, a int, b varchar(20));",
+	"insert into t values (1, 1, 'one'), (2, 2, 'two');",
+	"call dolt_commit('-Am', 'create t');",
+	"update t set a = 10 where pk = 1;",
+	"call dolt_commit('-am', 'update a');",
+	"alter table t rename column b to c;",
+	"call dolt_commit('-am', 'rename b to c');",
+	"insert into t values (3, 3, 'three');",
+	"update t set c = 'TWO' where pk = 2;",
+	"call dolt_commit('-am', 'insert 3 and update c');",
+}
+
+var DoltBlameCellsScriptTests = []queries.ScriptTest{
+	{
+		Name:        "dolt_blame_cells system table",
+		SetUpScript: blameCellsSetUpScript,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select pk, column_name, message from dolt_blame_cells_t;",
+				Expected: []sql.Row{
+					{1, "pk", "create t"},
+					{1, "a", "update a"},
+					{1, "c", "rename b to c"},
+					{2, "pk", "create t"},
+					{2, "a", "create t"},
+					{2, "c", "insert 3 and update c"},
+					{3, "pk", "insert 3 and update c"},
+					{3, "a", "insert 3 and update c"},
+					{3, "c", "insert 3 and update c"},
+				},
+			},
+			{
+				Query:    "select commit, committer, email from dolt_blame_cells_t where pk = 1 and column_name = 'a';",
+				Expected: []sql.Row{{doltCommit, "root", "root@localhost"}},
+			},
+			{
+				Query:    "select pk, column_name, message from dolt_blame_cells_t as of 'HEAD~2' where column_name = 'b';",
+				Expected: []sql.Row{{1, "b", "create t"}, {2, "b", "create t"}},
+			},
+			{
+				Query:    "select pk, column_name, message from DOLT_BLAME_CELLS_T where pk = 2 and column_name = 'a';",
+				Expected: []sql.Row{{2, "a", "create t"}},
+			},
+			{
+				// working set changes aren't blamed
+				Query:            "update t set a = 100 where pk = 2;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select message from dolt_blame_cells_t where pk = 2 and column_name = 'a';",
+				Expected: []sql.Row{{"create t"}},
+			},
+		},
+	},
+	{
+		Name:        "dolt_blame_cells table function",
+		SetUpScript: blameCellsSetUpScript,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select pk, column_name, message from dolt_blame_cells('t', '--follow') where column_name = 'c';",
+				Expected: []sql.Row{{1, "c", "create t"}, {2, "c", "insert 3 and update c"}, {3, "c", "insert 3 and update c"}},
+			},
+			{
+				Query:    "select pk, column_name, message from dolt_blame_cells('t', '--since', 'HEAD~2') where pk = 1;",
+				Expected: []sql.Row{{1, "pk", "update a"}, {1, "a", "update a"}, {1, "c", "rename b to c"}},
+			},
+			{
+				Query:    "select pk, column_name, message from dolt_blame_cells('t', '--until', 'HEAD~1', '--since', 'HEAD~2', '--follow');",
+				Expected: []sql.Row{{1, "pk", "update a"}, {1, "a", "update a"}, {1, "c", "update a"}, {2, "pk", "update a"}, {2, "a", "update a"}, {2, "c", "update a"}},
+			},
+			{
+				Query:          "select * from dolt_blame_cells('t', 'u');",
+				ExpectedErrStr: "function 'dolt_blame_cells' expected 1 arguments, 2 received",
+			},
+			{
+				Query:       "select * from dolt_blame_cells('nope');",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+		},
+	},
+	{
+		Name: "dolt_blame_cells attributes all cells to the commit that changed the primary key",
+		SetUpScript: []string{
+			"create table t (pk int primary key, a int);",
+			"insert into t values (1, 1);",
+			"call dolt_commit('-Am', 'create t');",
+			"alter table t modify column pk bigint;",
+			"call dolt_commit('-am', 'change pk type');",
+			"create table keyless (a int);",
+			"call dolt_commit('-Am', 'create keyless');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select pk, column_name, message from dolt_blame_cells_t;",
+				Expected: []sql.Row{{int64(1), "pk", "change pk type"}, {int64(1), "a", "change pk type"}},
+			},
+			{
+				Query:          "select * from dolt_blame_cells_keyless;",
+				ExpectedErrStr: "unable to blame the cells of a table without a primary key",
+			},
+		},
+	},
+}
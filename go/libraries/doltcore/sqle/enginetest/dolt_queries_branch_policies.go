@@ -0,0 +1,186 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+)
+
+var DoltBranchPoliciesScriptTests = []queries.ScriptTest{
+	{
+		Name: "dolt_branch_policies stores policies",
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_branch_policies;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "insert into dolt_branch_policies (name, pattern, merged_into, max_age) values ('ci', 'ci/*', 'main', '7d');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "insert into dolt_branch_policies (name, ref_type, pattern, keep_last) values ('releases', 'tag', 'v*', 3);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query: "select * from dolt_branch_policies;",
+				Expected: []sql.Row{
+					{"ci", "branch", "ci/*", "main", "7d", nil, "root", "localhost"},
+					{"releases", "tag", "v*", nil, nil, uint32(3), "root", "localhost"},
+				},
+			},
+			{
+				Query:          "insert into dolt_branch_policies (name, pattern, max_age) values ('ci', 'other/*', '1d');",
+				ExpectedErrStr: "duplicate primary key given: [ci]",
+			},
+			{
+				Query:          "insert into dolt_branch_policies (name, pattern) values ('all', '*');",
+				ExpectedErrStr: "branch policy 'all' requires at least one of merged_into, max_age or keep_last",
+			},
+			{
+				Query:          "insert into dolt_branch_policies (name, ref_type, pattern, merged_into) values ('tags', 'tag', '*', 'main');",
+				ExpectedErrStr: "branch policy 'tags': merged_into can only be set for branch policies",
+			},
+			{
+				Query:          "insert into dolt_branch_policies (name, pattern, max_age) values ('old', '*', 'a week');",
+				ExpectedErrStr: "invalid max_age 'a week'",
+			},
+			{
+				Query:    "update dolt_branch_policies set max_age = '36h' where name = 'ci';",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "select name, max_age from dolt_branch_policies where name = 'ci';",
+				Expected: []sql.Row{{"ci", "36h0m0s"}},
+			},
+			{
+				Query:    "delete from dolt_branch_policies where name = 'releases';",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select name from dolt_branch_policies;",
+				Expected: []sql.Row{{"ci"}},
+			},
+		},
+	},
+	{
+		Name: "dolt_branch_prune deletes merged branches",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create t');",
+			"call dolt_branch('ci/merged');",
+			"call dolt_branch('ci/unmerged');",
+			"call dolt_branch('ci/checked-out');",
+			"call dolt_branch('feature');",
+			"call dolt_checkout('ci/unmerged');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'unmerged change');",
+			"call dolt_checkout('main');",
+			"insert into dolt_branch_policies (name, pattern, merged_into) values ('ci', 'ci/*', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "call dolt_branch_prune('--dry-run');",
+				Expected: []sql.Row{
+					{"branch", "ci/checked-out", doltCommit, "ci"},
+					{"branch", "ci/merged", doltCommit, "ci"},
+				},
+			},
+			{
+				Query:    "select name from dolt_branches order by name;",
+				Expected: []sql.Row{{"ci/checked-out"}, {"ci/merged"}, {"ci/unmerged"}, {"feature"}, {"main"}},
+			},
+			{
+				Query:    "call dolt_checkout('ci/checked-out');",
+				Expected: []sql.Row{{0, "Switched to branch 'ci/checked-out'"}},
+			},
+			{
+				// the branch of the session is never deleted
+				Query:    "call dolt_branch_prune();",
+				Expected: []sql.Row{{"branch", "ci/merged", doltCommit, "ci"}},
+			},
+			{
+				Query:    "select name from dolt_branches order by name;",
+				Expected: []sql.Row{{"ci/checked-out"}, {"ci/unmerged"}, {"feature"}, {"main"}},
+			},
+			{
+				Query:    "call dolt_branch_prune();",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+	{
+		Name: "dolt_branch_prune deletes old branches",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create t', '--date', '2020-01-01T00:00:00');",
+			"call dolt_branch('old');",
+			"call dolt_checkout('-b', 'new');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'new change');",
+			"call dolt_checkout('main');",
+			"insert into dolt_branch_policies (name, pattern, max_age) values ('stale', '*', '30d');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				// main is checked out, so only old is deleted
+				Query:    "call dolt_branch_prune();",
+				Expected: []sql.Row{{"branch", "old", doltCommit, "stale"}},
+			},
+			{
+				Query:    "select name from dolt_branches order by name;",
+				Expected: []sql.Row{{"main"}, {"new"}},
+			},
+		},
+	},
+	{
+		Name: "dolt_branch_prune keeps the newest tags",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create t');",
+			"call dolt_tag('v1');",
+			"call dolt_tag('v2');",
+			"call dolt_tag('v3');",
+			"call dolt_tag('other');",
+			"insert into dolt_branch_policies (name, ref_type, pattern, keep_last) values ('releases', 'tag', 'v*', 3);",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_branch_prune();",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "update dolt_branch_policies set keep_last = 1;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				// the tags were created in quick succession, so which of them is the newest isn't checked
+				Query:            "call dolt_branch_prune();",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select count(*) from dolt_tags where tag_name like 'v%';",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select count(*) from dolt_tags where tag_name = 'other';",
+				Expected: []sql.Row{{1}},
+			},
+		},
+	},
+}
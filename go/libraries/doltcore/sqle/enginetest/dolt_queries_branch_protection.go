@@ -0,0 +1,280 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+)
+
+var DoltBranchProtectionScriptTests = []queries.ScriptTest{
+	{
+		Name: "dolt_branch_protection stores rules",
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_branch_protection;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "insert into dolt_branch_protection values ('%', 'main', 'no_delete,no_force_push');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "insert into dolt_branch_protection values ('MYDB', 'Release/%', 'signed_commits');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query: "select * from dolt_branch_protection;",
+				Expected: []sql.Row{
+					{"%", "main", "no_force_push,no_delete"},
+					{"mydb", "release/%", "signed_commits"},
+				},
+			},
+			{
+				Query:          "insert into dolt_branch_protection values ('%', 'MAIN', 'no_delete');",
+				ExpectedErrStr: `duplicate primary key given: ["%", "main", "no_force_push,no_delete"]`,
+			},
+			{
+				Query:          "insert into dolt_branch_protection values ('%', 'dev', 'fast_forward_only,merge_commits_only');",
+				ExpectedErrStr: `the rules fast_forward_only and merge_commits_only cannot both protect ["%", "dev"]`,
+			},
+			{
+				Query:    "update dolt_branch_protection set rules = 'no_delete' where branch = 'main';",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "delete from dolt_branch_protection where `database` = 'mydb';",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from dolt_branch_protection;",
+				Expected: []sql.Row{{"%", "main", "no_delete"}},
+			},
+			{
+				Query:    "delete from dolt_branch_protection;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+		},
+	},
+	{
+		Name: "no_direct_commits only allows merges",
+		SetUpScript: []string{
+			"create table t (pk int primary key, c int);",
+			"call dolt_commit('-Am', 'create table');",
+			"call dolt_branch('feature');",
+			"insert into dolt_branch_protection values ('%', 'main', 'no_direct_commits');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "insert into t values (1, 1);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:          "call dolt_commit('-am', 'direct commit');",
+				ExpectedErrStr: "branch `main` is protected: changes must be merged into it from another branch",
+			},
+			{
+				Query:            "call dolt_reset('--hard');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "call dolt_checkout('feature');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "insert into t values (2, 2);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "call dolt_commit('-am', 'feature commit');",
+				Expected: []sql.Row{{doltCommit}},
+			},
+			{
+				Query:            "call dolt_checkout('main');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "call dolt_merge('feature');",
+				Expected: []sql.Row{{doltCommit, 1, 0, "merge successful"}},
+			},
+			{
+				Query:    "select * from t;",
+				Expected: []sql.Row{{2, 2}},
+			},
+			{
+				Query:    "delete from dolt_branch_protection;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+		},
+	},
+	{
+		Name: "no_delete and no_force_push",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create table');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'insert row');",
+			"call dolt_branch('release');",
+			"call dolt_branch('other');",
+			"insert into dolt_branch_protection values ('%', 'release', 'no_delete,no_force_push');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "call dolt_branch('-D', 'release');",
+				ExpectedErrStr: "branch `release` is protected: it cannot be deleted",
+			},
+			{
+				Query:          "call dolt_branch('-m', 'release', 'renamed');",
+				ExpectedErrStr: "branch `release` is protected: it cannot be deleted",
+			},
+			{
+				Query:    "call dolt_branch('-D', 'other');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:            "call dolt_checkout('release');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:          "call dolt_reset('--hard', 'HEAD~1');",
+				ExpectedErrStr: "branch `release` is protected: its new head must descend from its current head",
+			},
+			{
+				Query:    "select count(*) from t;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:            "call dolt_checkout('main');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:          "call dolt_branch('-f', 'release', 'HEAD~1');",
+				ExpectedErrStr: "branch `release` is protected: its new head must descend from its current head",
+			},
+			{
+				Query:    "delete from dolt_branch_protection;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "call dolt_branch('-D', 'release');",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "fast_forward_only and merge_commits_only",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create table');",
+			"call dolt_branch('ff');",
+			"call dolt_branch('mc');",
+			"call dolt_checkout('-b', 'feature');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'feature commit');",
+			"call dolt_checkout('main');",
+			"insert into dolt_branch_protection values ('%', 'ff', 'fast_forward_only'), ('%', 'mc', 'merge_commits_only');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "call dolt_checkout('ff');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:          "call dolt_merge('--no-ff', 'feature');",
+				ExpectedErrStr: "branch `ff` is protected: branches must be merged into it with a fast-forward",
+			},
+			{
+				Query:    "call dolt_merge('feature');",
+				Expected: []sql.Row{{doltCommit, 1, 0, "merge successful"}},
+			},
+			{
+				Query:            "call dolt_checkout('mc');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:          "call dolt_merge('feature');",
+				ExpectedErrStr: "branch `mc` is protected: branches must be merged into it with a merge commit",
+			},
+			{
+				Query:    "call dolt_merge('--no-ff', 'feature');",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "delete from dolt_branch_protection;",
+				Expected: []sql.Row{{types.NewOkResult(2)}},
+			},
+		},
+	},
+	{
+		Name: "verify_constraints rejects constraint violations",
+		SetUpScript: []string{
+			"create table parent (pk int primary key);",
+			"create table child (pk int primary key, parent_pk int, foreign key (parent_pk) references parent (pk));",
+			"insert into parent values (1);",
+			"call dolt_commit('-Am', 'create tables');",
+			"insert into dolt_branch_protection values ('%', 'main', 'verify_constraints');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "insert into child values (1, 1);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "call dolt_commit('-am', 'valid row');",
+				Expected: []sql.Row{{doltCommit}},
+			},
+			{
+				Query:    "set foreign_key_checks = 0;",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "insert into child values (2, 2);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:          "call dolt_commit('-am', 'invalid row');",
+				ExpectedErrStr: "branch `main` is protected: its new head has constraint violations in child",
+			},
+			{
+				Query:    "delete from dolt_branch_protection;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+		},
+	},
+	{
+		Name: "signed_commits rejects unsigned commits",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create table');",
+			"insert into dolt_branch_protection values ('%', 'main', 'signed_commits');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "insert into t values (1);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:          "call dolt_commit('-am', 'unsigned commit');",
+				ExpectedErrStr: "branch `main` is protected: its commits must be signed",
+			},
+			{
+				Query:    "delete from dolt_branch_protection;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+		},
+	},
+}
@@ -1453,6 +1453,48 @@ on a.to_pk = b.to_pk;`,
 			},
 		},
 	},
+	{
+		Name: "diff of large tables is split into key ranges",
+		SetUpScript: []string{
+			"create table digits (d int primary key);",
+			"insert into digits values (0), (1), (2), (3), (4), (5), (6), (7), (8), (9);",
+			"create table t (pk int primary key, c int);",
+			"insert into t select a.d*1000 + b.d*100 + c.d*10 + d.d, a.d*1000 + b.d*100 + c.d*10 + d.d from digits a, digits b, digits c, digits d;",
+			"call dolt_add('.')",
+			"set @Commit1 = '';",
+			"call dolt_commit_hash_out(@Commit1, '-am', 'creating table t');",
+
+			"update t set c = c + 1 where pk % 7 = 0;",
+			"delete from t where pk % 11 = 0 and pk % 7 != 0;",
+			"insert into t select 10000 + a.d*10 + b.d, 0 from digits a, digits b;",
+			"set @Commit2 = '';",
+			"call dolt_commit_hash_out(@Commit2, '-am', 'updating table t');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select diff_type, count(*) from dolt_diff(@Commit1, @Commit2, 't') group by diff_type order by diff_type;",
+				Expected: []sql.Row{
+					{"added", 100},
+					{"modified", 1429},
+					{"removed", 780},
+				},
+			},
+			{
+				Query:    "select sum(to_c - from_c) from dolt_diff(@Commit1, @Commit2, 't') where diff_type = 'modified';",
+				Expected: []sql.Row{{float64(1429)}},
+			},
+			{
+				Query: "select to_pk, from_pk, diff_type from dolt_diff(@Commit1, @Commit2, 't') limit 5;",
+				Expected: []sql.Row{
+					{0, 0, "modified"},
+					{7, 7, "modified"},
+					{nil, 11, "removed"},
+					{14, 14, "modified"},
+					{21, 21, "modified"},
+				},
+			},
+		},
+	},
 }
 
 var DiffStatTableFunctionScriptTests = []queries.ScriptTest{
@@ -0,0 +1,167 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// historyWindowSetup creates a table with four commits, ten seconds apart:
+// c1 adds (1, 10) and (2, 20), c2 updates (1, 11) and adds (3, 30), c3 deletes 2, and c4 updates (3, 31).
+var historyWindowSetup = []string{
+	"create table t (pk int primary key, v int);",
+	"insert into t values (1, 10), (2, 20);",
+	"call dolt_commit('-Am', 'c1', '--date', '2022-08-06T12:00:00');",
+	"update t set v = 11 where pk = 1;",
+	"insert into t values (3, 30);",
+	"call dolt_commit('-am', 'c2', '--date', '2022-08-06T12:00:10');",
+	"delete from t where pk = 2;",
+	"call dolt_commit('-am', 'c3', '--date', '2022-08-06T12:00:20');",
+	"update t set v = 31 where pk = 3;",
+	"call dolt_commit('-am', 'c4', '--date', '2022-08-06T12:00:30');",
+}
+
+var DoltHistoryWindowScriptTests = []queries.ScriptTest{
+	{
+		Name:        "FOR SYSTEM_TIME ALL returns every version",
+		SetUpScript: historyWindowSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select h.pk, h.v, f.message, o.message from t for system_time all as h " +
+					"join dolt_log f on h.valid_from_commit = f.commit_hash " +
+					"left join dolt_log o on h.valid_to_commit = o.commit_hash order by h.pk, h.valid_from;",
+				Expected: []sql.Row{
+					{1, 10, "c1", "c2"},
+					{1, 11, "c2", nil},
+					{2, 20, "c1", "c3"},
+					{3, 30, "c2", "c4"},
+					{3, 31, "c4", nil},
+				},
+			},
+			{
+				Query:    "select pk, v, valid_to is null, valid_to_commit is null from t for system_time all where valid_from = '2022-08-06 12:00:10' order by pk;",
+				Expected: []sql.Row{{1, 11, true, true}, {3, 30, false, false}},
+			},
+			{
+				Query:    "select t.pk, t.v from t for system_time all where t.pk = 3 order by t.valid_from;",
+				Expected: []sql.Row{{3, 30}, {3, 31}},
+			},
+			{
+				Query:    "select count(*) from dolt_history_window('t', null, null);",
+				Expected: []sql.Row{{5}},
+			},
+			{
+				Query:    "select * from t as of 'HEAD~1' order by pk;",
+				Expected: []sql.Row{{1, 11}, {3, 30}},
+			},
+		},
+	},
+	{
+		Name:        "FOR SYSTEM_TIME windows",
+		SetUpScript: historyWindowSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select pk, v from t for system_time between '2022-08-06 12:00:12' and '2022-08-06 12:00:25' order by pk, valid_from;",
+				Expected: []sql.Row{{1, 11}, {2, 20}, {3, 30}},
+			},
+			{
+				// BETWEEN includes the versions added at the end of the window
+				Query:    "select pk, v from t for system_time between '2022-08-06 12:00:00' and '2022-08-06 12:00:10' order by pk, valid_from;",
+				Expected: []sql.Row{{1, 10}, {1, 11}, {2, 20}, {3, 30}},
+			},
+			{
+				// FROM ... TO excludes them
+				Query:    "select pk, v from t for system_time from '2022-08-06 12:00:00' to '2022-08-06 12:00:10' order by pk, valid_from;",
+				Expected: []sql.Row{{1, 10}, {2, 20}},
+			},
+			{
+				Query:    "select pk, v from t for system_time contained in ('2022-08-06 12:00:05', '2022-08-06 12:00:35') order by pk, valid_from;",
+				Expected: []sql.Row{{3, 30}},
+			},
+			{
+				Query:    "select pk, v from t for system_time between '2022-08-06 12:01:00' and '2022-08-06 12:02:00' order by pk, valid_from;",
+				Expected: []sql.Row{{1, 11}, {3, 31}},
+			},
+			{
+				Query:    "select pk, v from t for system_time between '2022-08-06 12:00:20' and '2022-08-06 12:00:10';",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select pk, v from dolt_history_window('t', '2022-08-06 12:00:05', '2022-08-06 12:00:35', 'CONTAINED IN');",
+				Expected: []sql.Row{{3, 30}},
+			},
+		},
+	},
+	{
+		Name:        "FOR SYSTEM_TIME windows bounded by commits",
+		SetUpScript: historyWindowSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select pk, v from t for system_time between 'HEAD~2' and 'HEAD~1' order by pk, valid_from;",
+				Expected: []sql.Row{{1, 11}, {2, 20}, {3, 30}},
+			},
+			{
+				Query:    "select pk, v from dolt_history_window('t', null, 'HEAD~3') order by pk, valid_from;",
+				Expected: []sql.Row{{1, 10}, {2, 20}},
+			},
+			{
+				Query:    "select pk, v from t for system_time from 'HEAD~1' to 'HEAD' order by pk, valid_from;",
+				Expected: []sql.Row{{1, 11}, {3, 30}},
+			},
+			{
+				Query: "select a.pk, a.v, b.v from t for system_time all as a " +
+					"join t for system_time all as b on a.pk = b.pk and a.valid_to_commit = b.valid_from_commit order by a.pk;",
+				Expected: []sql.Row{{1, 10, 11}, {3, 30, 31}},
+			},
+		},
+	},
+	{
+		Name: "FOR SYSTEM_TIME windows across schema changes",
+		SetUpScript: append(append([]string{}, historyWindowSetup...),
+			"alter table t add column w int;",
+			"update t set w = 1 where pk = 1;",
+			"call dolt_commit('-am', 'c5', '--date', '2022-08-06T12:00:40');",
+		),
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select pk, v, w from t for system_time from '2022-08-06 12:00:25' to '2022-08-06 12:01:00' order by pk, valid_from;",
+				Expected: []sql.Row{{1, 11, nil}, {1, 11, 1}, {3, 30, nil}, {3, 31, nil}},
+			},
+		},
+	},
+	{
+		Name:        "FOR SYSTEM_TIME errors",
+		SetUpScript: historyWindowSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "select * from mydb.t for system_time all;",
+				ExpectedErrStr: "FOR SYSTEM_TIME ranges are not supported on tables of other databases: mydb.t",
+			},
+			{
+				Query:          "select * from t for system_time between 'nope' and null;",
+				ExpectedErrStr: "invalid bound for dolt_history_window: nope is neither a date nor a commit",
+			},
+			{
+				Query:          "select * from dolt_history_window('t', null, null, 'sideways');",
+				ExpectedErrStr: "invalid mode for dolt_history_window: sideways, expected one of 'between', 'from' or 'contained in'",
+			},
+			{
+				Query:          "select * from nope for system_time all;",
+				ExpectedErrStr: "table not found: nope",
+			},
+		},
+	},
+}
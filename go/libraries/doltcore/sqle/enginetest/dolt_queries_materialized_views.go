@@ -0,0 +1,365 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dprocedures"
+)
+
+// materializedViewSetup creates and commits a table of orders, each with a customer and an amount.
+var materializedViewSetup = []string{
+	"create table orders (id int primary key, customer varchar(20) not null, amount int not null, note varchar(20));",
+	"insert into orders values (1, 'alice', 10, null), (2, 'alice', 20, 'gift'), (3, 'bob', 5, null), (4, 'carol', 7, 'rush');",
+	"call dolt_commit('-Am', 'orders');",
+}
+
+var DoltMaterializedViewScriptTests = []queries.ScriptTest{
+	{
+		Name:        "create, refresh and drop materialized views",
+		SetUpScript: materializedViewSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "create materialized view totals as select customer, count(*) as orders, sum(amount) as total from orders group by customer;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select * from totals order by customer;",
+				Expected: []sql.Row{{"alice", 2, 30.0}, {"bob", 1, 5.0}, {"carol", 1, 7.0}},
+			},
+			{
+				Query:    "select name, source_commit = hashof('HEAD'), refresh_on_commit from dolt_materialized_views;",
+				Expected: []sql.Row{{"totals", true, 0}},
+			},
+			{
+				Query:            "refresh materialized view totals;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select * from dolt_status;",
+				Expected: []sql.Row{{"dolt_materialized_views", false, "new table"}, {"totals", false, "new table"}},
+			},
+			{
+				Query:          "create materialized view totals as select * from orders;",
+				ExpectedErrStr: "materialized view totals already exists",
+			},
+			{
+				Query:       "create materialized view orders as select * from orders;",
+				ExpectedErr: sql.ErrTableAlreadyExists,
+			},
+			{
+				Query:       "call dolt_materialized_view('create', 'bad', 'delete from orders');",
+				ExpectedErr: dprocedures.ErrInvalidMaterializedViewQuery,
+			},
+			{
+				Query:       "refresh materialized view nope;",
+				ExpectedErr: dprocedures.ErrMaterializedViewNotFound,
+			},
+			{
+				Query:    "drop materialized view totals;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "drop materialized view if exists totals;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select count(*) from dolt_materialized_views;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:       "select * from totals;",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+		},
+	},
+	{
+		Name:        "incremental refresh of a projection",
+		SetUpScript: materializedViewSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "create materialized view big as select id, amount * 2 as doubled, customer from orders where amount > 6;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "update orders set amount = 1 where id = 1;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "update orders set amount = 30 where id = 3;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "delete from orders where id = 4;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "insert into orders values (5, 'dave', 9, null);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				// The view reflects the working set, which isn't a commit
+				Query:    "refresh materialized view big;",
+				Expected: []sql.Row{{"big", "incremental", nil}},
+			},
+			{
+				Query:    "select * from big order by id;",
+				Expected: []sql.Row{{2, 40, "alice"}, {3, 60, "bob"}, {5, 18, "dave"}},
+			},
+			{
+				Query:            "call dolt_commit('-am', 'changes');",
+				SkipResultsCheck: true,
+			},
+			{
+				// Without a source commit, the view is rebuilt
+				Query:            "refresh materialized view big;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select source_commit = hashof('HEAD') from dolt_materialized_views;",
+				Expected: []sql.Row{{true}},
+			},
+			{
+				Query:    "select * from big order by id;",
+				Expected: []sql.Row{{2, 40, "alice"}, {3, 60, "bob"}, {5, 18, "dave"}},
+			},
+		},
+	},
+	{
+		Name:        "incremental refresh of a group by",
+		SetUpScript: materializedViewSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "create materialized view totals as select customer, count(*) as orders, sum(amount) as total, count(note) as notes from orders where amount < 100 group by customer;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "update orders set amount = 11, note = 'late' where id = 1;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "delete from orders where id = 3;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "insert into orders values (5, 'dave', 9, null), (6, 'carol', 1, null), (7, 'carol', 500, null);",
+				Expected: []sql.Row{{types.NewOkResult(3)}},
+			},
+			{
+				Query:    "call dolt_materialized_view('refresh');",
+				Expected: []sql.Row{{"totals", "incremental", nil}},
+			},
+			{
+				Query: "select * from totals order by customer;",
+				Expected: []sql.Row{
+					{"alice", 2, 31.0, 2},
+					{"carol", 2, 8.0, 1},
+					{"dave", 1, 9.0, 0},
+				},
+			},
+			{
+				Query: "select customer, count(*), sum(amount), count(note) from orders where amount < 100 group by customer order by customer;",
+				Expected: []sql.Row{
+					{"alice", 2, 31.0, 2},
+					{"carol", 2, 8.0, 1},
+					{"dave", 1, 9.0, 0},
+				},
+			},
+		},
+	},
+	{
+		Name:        "views that can't be maintained incrementally are rebuilt",
+		SetUpScript: materializedViewSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "create materialized view customers as select distinct customer from orders;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "create materialized view top_order as select customer, amount from orders order by amount desc limit 1;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "insert into orders values (5, 'dave', 90, null);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "call dolt_materialized_view('refresh', 'customers', 'top_order');",
+				Expected: []sql.Row{{"customers", "full", nil}, {"top_order", "full", nil}},
+			},
+			{
+				Query:    "select * from customers order by customer;",
+				Expected: []sql.Row{{"alice"}, {"bob"}, {"carol"}, {"dave"}},
+			},
+			{
+				Query:    "select * from top_order;",
+				Expected: []sql.Row{{"dave", 90}},
+			},
+			{
+				Query:            "call dolt_commit('-Am', 'views');",
+				SkipResultsCheck: true,
+			},
+			{
+				// A change to the schema of the source table also forces a rebuild
+				Query:            "create materialized view notes as select id, note from orders where note is not null;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "alter table orders add column shipped bool;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "call dolt_materialized_view('refresh', 'notes');",
+				Expected: []sql.Row{{"notes", "full", nil}},
+			},
+			{
+				Query:    "call dolt_materialized_view('refresh', '--full', 'customers');",
+				Expected: []sql.Row{{"customers", "full", nil}},
+			},
+		},
+	},
+	{
+		Name:        "materialized view statement syntax",
+		SetUpScript: materializedViewSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "CREATE /* quoted */ MATERIALIZED VIEW `my view` AS select id, 'a;b' as s from orders where note = 'gift';",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select * from `my view`;",
+				Expected: []sql.Row{{2, "a;b"}},
+			},
+			{
+				Query:    "select view_query from dolt_materialized_views;",
+				Expected: []sql.Row{{"select id, 'a;b' as s from orders where note = 'gift'"}},
+			},
+			{
+				Query:            "create materialized view other as select id from orders;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "Refresh Materialized Views `my view`, other;",
+				Expected: []sql.Row{{"my view", "up to date", doltCommit}, {"other", "up to date", doltCommit}},
+			},
+			{
+				Query:       "create materialized view bad as delete from orders;",
+				ExpectedErr: dprocedures.ErrInvalidMaterializedViewQuery,
+			},
+			{
+				Query:       "refresh materialized view other extra;",
+				ExpectedErr: sql.ErrSyntaxError,
+			},
+			{
+				Query:    "drop materialized view if exists `my view`;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select name from dolt_materialized_views;",
+				Expected: []sql.Row{{"other"}},
+			},
+		},
+	},
+	{
+		Name:        "refresh on commit",
+		SetUpScript: materializedViewSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "create materialized view totals refresh on commit as select customer, count(*) as orders from orders group by customer;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "create materialized view manual as select customer, count(*) as orders from orders group by customer;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "call dolt_commit('-Am', 'views');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "insert into orders values (5, 'dave', 9, null);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:            "call dolt_commit('-am', 'dave');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select * from totals where customer = 'dave';",
+				Expected: []sql.Row{{"dave", 1}},
+			},
+			{
+				Query:    "select * from manual where customer = 'dave';",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select name, source_commit = hashof('HEAD'), refresh_on_commit from dolt_materialized_views order by name;",
+				Expected: []sql.Row{{"manual", false, 0}, {"totals", true, 1}},
+			},
+			{
+				// The refreshed view is left in the working set
+				Query:    "select table_name from dolt_status order by table_name;",
+				Expected: []sql.Row{{"dolt_materialized_views"}, {"totals"}},
+			},
+		},
+	},
+	{
+		Name: "merging materialized views",
+		SetUpScript: append(materializedViewSetup,
+			"create materialized view totals as select customer, count(*) as orders, sum(amount) as total from orders group by customer;",
+			"call dolt_commit('-Am', 'view');",
+			"call dolt_branch('other');",
+			"insert into orders values (5, 'alice', 1, null);",
+			"call dolt_commit('-am', 'main order');",
+			"call dolt_checkout('other');",
+			"insert into orders values (6, 'dave', 9, null), (7, 'bob', 2, null);",
+			"call dolt_commit('-am', 'other orders');",
+			"refresh materialized view totals;",
+			"call dolt_commit('-am', 'refresh');",
+			"call dolt_checkout('main');",
+		),
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "call dolt_merge('other');",
+				SkipResultsCheck: true,
+			},
+			{
+				// The view is taken from the branch that refreshed it, along with the commit it reflects
+				Query:    "select * from totals order by customer;",
+				Expected: []sql.Row{{"alice", 2, 30.0}, {"bob", 2, 7.0}, {"carol", 1, 7.0}, {"dave", 1, 9.0}},
+			},
+			{
+				Query:    "select source_commit = hashof('other~1') from dolt_materialized_views;",
+				Expected: []sql.Row{{true}},
+			},
+			{
+				Query:    "select count(*) from dolt_conflicts;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:            "refresh materialized view totals;",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select * from totals order by customer;",
+				Expected: []sql.Row{{"alice", 3, 31.0}, {"bob", 2, 7.0}, {"carol", 1, 7.0}, {"dave", 1, 9.0}},
+			},
+		},
+	},
+}
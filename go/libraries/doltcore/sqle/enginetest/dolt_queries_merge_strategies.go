@@ -0,0 +1,341 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"time"
+
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+)
+
+var MergeStrategiesScriptTests = []queries.ScriptTest{
+	{
+		Name: "dolt_merge_strategies is versioned and validates its rows",
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_merge_strategies;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:          "insert into dolt_merge_strategies values ('t', 'v', 'newest', null);",
+				ExpectedErrStr: "unknown merge strategy 'newest'; expected one of ours, theirs, max, min, sum, lww",
+			},
+			{
+				Query:          "insert into dolt_merge_strategies values ('t', 'v', 'lww', null);",
+				ExpectedErrStr: "merge strategy 'lww' for table 't' requires an lww_column",
+			},
+			{
+				Query:          "insert into dolt_merge_strategies values ('t', 'v', 'sum', 'updated_at');",
+				ExpectedErrStr: "lww_column can only be set for the 'lww' merge strategy",
+			},
+			{
+				Query:          "insert into dolt_merge_strategies values ('t', '', 'sum', null);",
+				ExpectedErrStr: "column_name of a merge strategy must name a column of table 't' or be '*'",
+			},
+			{
+				Query:    "insert into dolt_merge_strategies values ('t', 'v', 'SUM', null), ('t', '*', 'lww', 'updated_at');",
+				Expected: []sql.Row{{types.NewOkResult(2)}},
+			},
+			{
+				Query:          "update dolt_merge_strategies set strategy = 'newest' where column_name = 'v';",
+				ExpectedErrStr: "unknown merge strategy 'newest'; expected one of ours, theirs, max, min, sum, lww",
+			},
+			{
+				Query:    "select * from dolt_merge_strategies order by column_name;",
+				Expected: []sql.Row{{"t", "*", "lww", "updated_at"}, {"t", "v", "SUM", nil}},
+			},
+			{
+				Query:    "select * from dolt_status;",
+				Expected: []sql.Row{{"dolt_merge_strategies", false, "new table"}},
+			},
+			{
+				Query:    "call dolt_commit('-Am', 'add merge strategies');",
+				Expected: []sql.Row{{doltCommit}},
+			},
+			{
+				Query:    "select count(*) from dolt_merge_strategies as of 'HEAD~1';",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "sum strategy merges concurrent changes to a counter",
+		SetUpScript: []string{
+			"create table inventory (id int primary key, qty int, price decimal(10,2), name varchar(20));",
+			"insert into inventory values (1, 10, 1.50, 'apple'), (2, 5, 2.00, 'pear');",
+			"insert into dolt_merge_strategies values ('inventory', 'qty', 'sum', null), ('inventory', 'price', 'sum', null);",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update inventory set qty = 15, price = 2.00 where id = 1;",
+			"update inventory set qty = 1, name = 'nashi' where id = 2;",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update inventory set qty = 8, price = 1.25 where id = 1;",
+			"update inventory set qty = 7 where id = 2;",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "select * from inventory order by id;",
+				Expected: []sql.Row{{1, 13, "1.75", "apple"}, {2, 3, "2.00", "nashi"}},
+			},
+		},
+	},
+	{
+		Name: "sum strategy leaves a conflict when the sum is out of range",
+		SetUpScript: []string{
+			"set dolt_allow_commit_conflicts = on;",
+			"create table t (pk int primary key, v tinyint unsigned);",
+			"insert into t values (1, 10);",
+			"insert into dolt_merge_strategies values ('t', 'v', 'sum', null);",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update t set v = 0;",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update t set v = 5;",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{"", 0, 1, "conflicts found"}},
+			},
+			{
+				Query:    "select base_v, our_v, their_v from dolt_conflicts_t;",
+				Expected: []sql.Row{{uint64(10), uint64(5), uint64(0)}},
+			},
+		},
+	},
+	{
+		Name: "last writer wins strategy picks the row updated last",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v varchar(20), w int, updated_at datetime);",
+			"insert into t values (1, 'a', 1, '2024-01-01'), (2, 'b', 2, '2024-01-01'), (3, 'c', 3, '2024-01-01');",
+			"insert into dolt_merge_strategies values ('t', '*', 'lww', 'updated_at');",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update t set v = 'theirs', w = 10, updated_at = '2024-03-01' where pk = 1;",
+			"update t set v = 'theirs', updated_at = '2024-02-01' where pk = 2;",
+			"insert into t values (4, 'theirs', 4, '2024-02-01');",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update t set v = 'ours', updated_at = '2024-02-01' where pk = 1;",
+			"update t set v = 'ours', w = 20, updated_at = '2024-03-01' where pk = 2;",
+			"insert into t values (4, 'ours', 4, '2024-03-01');",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query: "select pk, v, w, updated_at from t order by pk;",
+				Expected: []sql.Row{
+					{1, "theirs", 10, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+					{2, "ours", 20, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+					{3, "c", 3, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+					{4, "ours", 4, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+	},
+	{
+		Name: "last writer wins strategy leaves a conflict for a tie",
+		SetUpScript: []string{
+			"set dolt_allow_commit_conflicts = on;",
+			"create table t (pk int primary key, v int, updated_at datetime);",
+			"insert into t values (1, 1, '2024-01-01');",
+			"insert into dolt_merge_strategies values ('t', 'v', 'lww', 'updated_at');",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update t set v = 2, updated_at = '2024-02-01';",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update t set v = 3, updated_at = '2024-02-01';",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{"", 0, 1, "conflicts found"}},
+			},
+			{
+				Query:    "select our_v, their_v from dolt_conflicts_t;",
+				Expected: []sql.Row{{3, 2}},
+			},
+		},
+	},
+	{
+		Name: "last writer wins strategy with an unknown column fails the merge",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int);",
+			"insert into t values (1, 1);",
+			"insert into dolt_merge_strategies values ('t', 'v', 'lww', 'updated_at');",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update t set v = 2;",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update t set v = 3;",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "call dolt_merge('other');",
+				ExpectedErrStr: "merge strategy for column 'v' of table 't' compares unknown column 'updated_at'",
+			},
+		},
+	},
+	{
+		Name: "ours, theirs, max and min strategies",
+		SetUpScript: []string{
+			"create table t (pk int primary key, o int, th int, mx varchar(10), mn int, other int);",
+			"insert into t values (1, 0, 0, 'b', 5, 0), (2, 0, 0, 'b', 5, 0);",
+			"insert into dolt_merge_strategies values ('t', 'o', 'ours', null), ('t', 'th', 'theirs', null), ('t', 'mx', 'max', null), ('t', 'mn', 'min', null);",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update t set o = 2, th = 2, mx = 'c', mn = 3 where pk = 1;",
+			"update t set o = 2, th = 2, mx = 'a', mn = null where pk = 2;",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update t set o = 1, th = 1, mx = 'bb', mn = 4, other = 1 where pk = 1;",
+			"update t set o = 1, th = 1, mx = 'ab', mn = 9 where pk = 2;",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "select * from t order by pk;",
+				Expected: []sql.Row{{1, 1, 2, "c", 3, 1}, {2, 1, 2, "ab", 9, 0}},
+			},
+		},
+	},
+	{
+		Name: "strategies use the stored order of columns after a virtual column",
+		SetUpScript: []string{
+			"create table t (pk int primary key, name varchar(20), mx int, n int);",
+			"alter table t add column g varchar(20) as (concat('g', mx)) virtual after pk;",
+			"insert into t (pk, name, mx, n) values (1, 'a', 5, 100);",
+			"insert into dolt_merge_strategies values ('t', 'mx', 'max', null), ('t', 'n', 'sum', null);",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update t set mx = 10, n = 150;",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update t set mx = 9, n = 90;",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "select * from t;",
+				Expected: []sql.Row{{1, "g10", "a", 10, 140}},
+			},
+		},
+	},
+	{
+		Name: "columns without a strategy still conflict",
+		SetUpScript: []string{
+			"set dolt_allow_commit_conflicts = on;",
+			"create table t (pk int primary key, v int, w int);",
+			"create table u (pk int primary key, v int);",
+			"insert into t values (1, 0, 0);",
+			"insert into u values (1, 0);",
+			"insert into dolt_merge_strategies values ('t', 'v', 'max', null);",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"update t set v = 2, w = 2;",
+			"update u set v = 2;",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update t set v = 1, w = 1;",
+			"update u set v = 1;",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{"", 0, 1, "conflicts found"}},
+			},
+			{
+				Query:    "select `table`, num_conflicts from dolt_conflicts order by `table`;",
+				Expected: []sql.Row{{"t", uint64(1)}, {"u", uint64(1)}},
+			},
+			{
+				Query:    "select our_v, our_w, their_v, their_w from dolt_conflicts_t;",
+				Expected: []sql.Row{{1, 1, 2, 2}},
+			},
+		},
+	},
+	{
+		Name: "strategies for all columns resolve delete and modify conflicts",
+		SetUpScript: []string{
+			"create table ours_t (pk int primary key, v int, index (v));",
+			"create table theirs_t (pk int primary key, v int, index (v));",
+			"insert into ours_t values (1, 1), (2, 2);",
+			"insert into theirs_t values (1, 1), (2, 2);",
+			"insert into dolt_merge_strategies values ('ours_t', '*', 'ours', null), ('theirs_t', '*', 'theirs', null);",
+			"call dolt_commit('-Am', 'init');",
+			"call dolt_checkout('-b', 'other');",
+			"delete from ours_t where pk = 1;",
+			"update ours_t set v = 20 where pk = 2;",
+			"delete from theirs_t where pk = 1;",
+			"update theirs_t set v = 20 where pk = 2;",
+			"call dolt_commit('-am', 'other');",
+			"call dolt_checkout('main');",
+			"update ours_t set v = 10 where pk = 1;",
+			"delete from ours_t where pk = 2;",
+			"update theirs_t set v = 10 where pk = 1;",
+			"delete from theirs_t where pk = 2;",
+			"call dolt_commit('-am', 'main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "select * from ours_t;",
+				Expected: []sql.Row{{1, 10}},
+			},
+			{
+				Query:    "select * from theirs_t;",
+				Expected: []sql.Row{{2, 20}},
+			},
+			{
+				Query:    "select * from theirs_t where v = 20;",
+				Expected: []sql.Row{{2, 20}},
+			},
+			{
+				Query:    "select * from theirs_t where v = 10;",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+}
@@ -271,7 +271,7 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"2", "pick", doltCommit, "updating row 1 on branch1"},
+					{"2", "pick", doltCommit, "updating row 1 on branch1", nil},
 				},
 			},
 			{
@@ -356,8 +356,8 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"2", "pick", doltCommit, "updating row 1 on branch1"},
-					{"3.00", "pick", doltCommit, "inserting row 1 on branch1"},
+					{"2", "pick", doltCommit, "updating row 1 on branch1", nil},
+					{"3.00", "pick", doltCommit, "inserting row 1 on branch1", nil},
 				},
 			},
 			{
@@ -621,12 +621,12 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "inserting row 1"},
-					{"2", "pick", doltCommit, "inserting row 10"},
-					{"3", "pick", doltCommit, "inserting row 100"},
-					{"4", "pick", doltCommit, "inserting row 1000"},
-					{"5", "pick", doltCommit, "inserting row 10000"},
-					{"6", "pick", doltCommit, "inserting row 100000"},
+					{"1", "pick", doltCommit, "inserting row 1", nil},
+					{"2", "pick", doltCommit, "inserting row 10", nil},
+					{"3", "pick", doltCommit, "inserting row 100", nil},
+					{"4", "pick", doltCommit, "inserting row 1000", nil},
+					{"5", "pick", doltCommit, "inserting row 10000", nil},
+					{"6", "pick", doltCommit, "inserting row 100000", nil},
 				},
 			},
 			{
@@ -667,12 +667,12 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "inserting row 1"},
-					{"2", "squash", doltCommit, "inserting row 10"},
-					{"3", "squash", doltCommit, "inserting row 100"},
-					{"4", "drop", doltCommit, "inserting row 1000"},
-					{"5", "reword", doltCommit, "reworded!"},
-					{"6.10", "fixup", doltCommit, "inserting row 100000"},
+					{"1", "pick", doltCommit, "inserting row 1", nil},
+					{"2", "squash", doltCommit, "inserting row 10", nil},
+					{"3", "squash", doltCommit, "inserting row 100", nil},
+					{"4", "drop", doltCommit, "inserting row 1000", nil},
+					{"5", "reword", doltCommit, "reworded!", nil},
+					{"6.10", "fixup", doltCommit, "inserting row 100000", nil},
 				},
 			},
 			{
@@ -745,9 +745,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"-11.34", "pick", doltCommit, "inserting row 1 on branch1"},
-					{"-10.34", "pick", doltCommit, "updating row 1 on branch1"},
-					{"-9.34", "pick", doltCommit, "updating row 1, again, on branch1"},
+					{"-11.34", "pick", doltCommit, "inserting row 1 on branch1", nil},
+					{"-10.34", "pick", doltCommit, "updating row 1 on branch1", nil},
+					{"-9.34", "pick", doltCommit, "updating row 1, again, on branch1", nil},
 				},
 			},
 			{
@@ -805,9 +805,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "inserting row 1 on branch1"},
-					{"2", "pick", doltCommit, "updating row 1 on branch1"},
-					{"3", "pick", doltCommit, "updating row 1, again, on branch1"},
+					{"1", "pick", doltCommit, "inserting row 1 on branch1", nil},
+					{"2", "pick", doltCommit, "updating row 1 on branch1", nil},
+					{"3", "pick", doltCommit, "updating row 1, again, on branch1", nil},
 				},
 			},
 			{
@@ -970,9 +970,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "inserting row 1 on branch1"},
-					{"2", "pick", doltCommit, "updating row 1 on branch1"},
-					{"3", "pick", doltCommit, "updating row 1, again, on branch1"},
+					{"1", "pick", doltCommit, "inserting row 1 on branch1", nil},
+					{"2", "pick", doltCommit, "updating row 1 on branch1", nil},
+					{"3", "pick", doltCommit, "updating row 1, again, on branch1", nil},
 				},
 			},
 			{
@@ -1128,9 +1128,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "updating row -1 on branch1"},
-					{"2", "pick", doltCommit, "deleting -1 on branch1"},
-					{"3", "pick", doltCommit, "inserting row 999 on branch1"},
+					{"1", "pick", doltCommit, "updating row -1 on branch1", nil},
+					{"2", "pick", doltCommit, "deleting -1 on branch1", nil},
+					{"3", "pick", doltCommit, "inserting row 999 on branch1", nil},
 				},
 			},
 			{
@@ -1143,9 +1143,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"2", "pick", doltCommit, "deleting -1 on branch1"},
-					{"3", "pick", doltCommit, "inserting row 999 on branch1"},
-					{"3.50", "fixup", doltCommit, "updating row -1 on branch1"},
+					{"2", "pick", doltCommit, "deleting -1 on branch1", nil},
+					{"3", "pick", doltCommit, "inserting row 999 on branch1", nil},
+					{"3.50", "fixup", doltCommit, "updating row -1 on branch1", nil},
 				},
 			},
 			{
@@ -1284,9 +1284,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "updating row -1 on branch1"},
-					{"2", "pick", doltCommit, "deleting -1 on branch1"},
-					{"3", "pick", doltCommit, "inserting row 999 on branch1"},
+					{"1", "pick", doltCommit, "updating row -1 on branch1", nil},
+					{"2", "pick", doltCommit, "deleting -1 on branch1", nil},
+					{"3", "pick", doltCommit, "inserting row 999 on branch1", nil},
 				},
 			},
 			{
@@ -1299,9 +1299,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"2", "pick", doltCommit, "deleting -1 on branch1"},
-					{"3", "pick", doltCommit, "inserting row 999 on branch1"},
-					{"3.50", "reword", doltCommit, "reworded message!"},
+					{"2", "pick", doltCommit, "deleting -1 on branch1", nil},
+					{"3", "pick", doltCommit, "inserting row 999 on branch1", nil},
+					{"3.50", "reword", doltCommit, "reworded message!", nil},
 				},
 			},
 			{
@@ -1435,9 +1435,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order ASC;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "inserting row 1"},
-					{"2", "pick", doltCommit, "adding column c1"},
-					{"3", "pick", doltCommit, "altering column c1"},
+					{"1", "pick", doltCommit, "inserting row 1", nil},
+					{"2", "pick", doltCommit, "adding column c1", nil},
+					{"3", "pick", doltCommit, "altering column c1", nil},
 				},
 			},
 			{
@@ -1512,9 +1512,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "inserting row 1"},
-					{"2", "pick", doltCommit, "inserting row 2"},
-					{"3", "pick", doltCommit, "inserting row 3"},
+					{"1", "pick", doltCommit, "inserting row 1", nil},
+					{"2", "pick", doltCommit, "inserting row 2", nil},
+					{"3", "pick", doltCommit, "inserting row 3", nil},
 				},
 			},
 			{
@@ -1524,7 +1524,7 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				// NOTE: This uses "pick", not reword, so we expect the commit message from the commit to be
 				//       used, and not the custom commit message inserted into the table.
-				Query:    "insert into dolt_rebase values (2.12, 'pick', hashof('branch2'), 'inserting row 0');",
+				Query:    "insert into dolt_rebase values (2.12, 'pick', hashof('branch2'), 'inserting row 0', null);",
 				Expected: []sql.Row{{gmstypes.NewOkResult(1)}},
 			},
 			{
@@ -1589,9 +1589,9 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			{
 				Query: "select * from dolt_rebase order by rebase_order;",
 				Expected: []sql.Row{
-					{"1", "pick", doltCommit, "inserting row 1"},
-					{"2", "pick", doltCommit, "inserting row 2"},
-					{"3", "pick", doltCommit, "inserting row 3"},
+					{"1", "pick", doltCommit, "inserting row 1", nil},
+					{"2", "pick", doltCommit, "inserting row 2", nil},
+					{"3", "pick", doltCommit, "inserting row 3", nil},
 				},
 			},
 			{
@@ -1611,6 +1611,207 @@ var DoltRebaseScriptTests = []queries.ScriptTest{
 			},
 		},
 	},
+	{
+		Name: "dolt_rebase: edit, exec and break actions",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'creating table t');",
+			"call dolt_branch('branch1');",
+
+			"insert into t values (0);",
+			"call dolt_commit('-am', 'inserting row 0');",
+
+			"call dolt_checkout('branch1');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'inserting row 1');",
+			"insert into t values (2);",
+			"call dolt_commit('-am', 'inserting row 2');",
+			"insert into t values (3);",
+			"call dolt_commit('-am', 'inserting row 3');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "call dolt_rebase('-i', 'main');",
+				Expected: []sql.Row{{0, "interactive rebase started on branch dolt_rebase_branch1; " +
+					"adjust the rebase plan in the dolt_rebase table, then " +
+					"continue rebasing by calling dolt_rebase('--continue')"}},
+			},
+			{
+				Query: "update dolt_rebase set action='edit' where rebase_order = 1;",
+				Expected: []sql.Row{{gmstypes.OkResult{RowsAffected: uint64(1), Info: plan.UpdateInfo{
+					Matched: 1,
+					Updated: 1,
+				}}}},
+			},
+			{
+				// Exec statements run on the session's engine, so they may use syntax only Dolt's parser accepts
+				Query:    "insert into dolt_rebase values (1.5, 'exec', '', '', 'select count(*) from t for system_time all'), (2.5, 'break', '', '', null);",
+				Expected: []sql.Row{{gmstypes.NewOkResult(2)}},
+			},
+			{
+				// The rebase stops after replaying the commit for the edit step
+				Query:            "call dolt_rebase('--continue');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select active_branch();",
+				Expected: []sql.Row{{"dolt_rebase_branch1"}},
+			},
+			{
+				Query:    "select message from dolt_log limit 1;",
+				Expected: []sql.Row{{"inserting row 1"}},
+			},
+			{
+				Query:    "insert into t values (11);",
+				Expected: []sql.Row{{gmstypes.NewOkResult(1)}},
+			},
+			{
+				Query:       "call dolt_rebase('--continue');",
+				ExpectedErr: dprocedures.ErrRebaseUnstagedChanges,
+			},
+			{
+				Query:    "call dolt_add('t');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				// The staged changes are amended into the edited commit, and the rebase stops again at the break
+				Query: "call dolt_rebase('--continue');",
+				Expected: []sql.Row{{0, "Interactive rebase stopped at break; " +
+					"continue rebasing by calling dolt_rebase('--continue')"}},
+			},
+			{
+				Query:    "select message from dolt_log limit 3;",
+				Expected: []sql.Row{{"inserting row 2"}, {"inserting row 1"}, {"inserting row 0"}},
+			},
+			{
+				Query:    "select * from t as of 'HEAD~1';",
+				Expected: []sql.Row{{0}, {1}, {11}},
+			},
+			{
+				Query:    "call dolt_rebase('--continue');",
+				Expected: []sql.Row{{0, "Successfully rebased and updated refs/heads/branch1"}},
+			},
+			{
+				Query:    "select active_branch();",
+				Expected: []sql.Row{{"branch1"}},
+			},
+			{
+				Query: "select message from dolt_log;",
+				Expected: []sql.Row{
+					{"inserting row 3"},
+					{"inserting row 2"},
+					{"inserting row 1"},
+					{"inserting row 0"},
+					{"creating table t"},
+					{"Initialize data repository"},
+				},
+			},
+			{
+				Query:    "select * from t;",
+				Expected: []sql.Row{{0}, {1}, {2}, {3}, {11}},
+			},
+		},
+	},
+	{
+		Name: "dolt_rebase: failing exec action aborts the rebase",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'creating table t');",
+			"call dolt_branch('branch1');",
+
+			"insert into t values (0);",
+			"call dolt_commit('-am', 'inserting row 0');",
+
+			"call dolt_checkout('branch1');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'inserting row 1');",
+			"insert into t values (2);",
+			"call dolt_commit('-am', 'inserting row 2');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "call dolt_rebase('-i', 'main');",
+				Expected: []sql.Row{{0, "interactive rebase started on branch dolt_rebase_branch1; " +
+					"adjust the rebase plan in the dolt_rebase table, then " +
+					"continue rebasing by calling dolt_rebase('--continue')"}},
+			},
+			{
+				Query:    "insert into dolt_rebase values (1.5, 'exec', '', '', null);",
+				Expected: []sql.Row{{gmstypes.NewOkResult(1)}},
+			},
+			{
+				Query:          "call dolt_rebase('--continue');",
+				ExpectedErrStr: rebase.ErrInvalidRebasePlanExecWithoutStatement.Error(),
+			},
+			{
+				Query: "update dolt_rebase set exec_statement = 'select * from doesnotexist' where rebase_order = 1.5;",
+				Expected: []sql.Row{{gmstypes.OkResult{RowsAffected: uint64(1), Info: plan.UpdateInfo{
+					Matched: 1,
+					Updated: 1,
+				}}}},
+			},
+			{
+				Query:       "call dolt_rebase('--continue');",
+				ExpectedErr: dprocedures.ErrRebaseExecFailed,
+			},
+			{
+				// The rebase state has been cleared after the exec statement failed
+				Query:          "call dolt_rebase('--continue');",
+				ExpectedErrStr: "no rebase in progress",
+			},
+			{
+				Query:    "select active_branch();",
+				Expected: []sql.Row{{"branch1"}},
+			},
+			{
+				Query:    "select message from dolt_log limit 2;",
+				Expected: []sql.Row{{"inserting row 2"}, {"inserting row 1"}},
+			},
+			{
+				Query:    "select name from dolt_branches",
+				Expected: []sql.Row{{"main"}, {"branch1"}},
+			},
+		},
+	},
+	{
+		Name: "dolt_rebase: exec action that leaves uncommitted changes aborts the rebase",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'creating table t');",
+			"call dolt_branch('branch1');",
+
+			"insert into t values (0);",
+			"call dolt_commit('-am', 'inserting row 0');",
+
+			"call dolt_checkout('branch1');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'inserting row 1');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "call dolt_rebase('-i', 'main');",
+				Expected: []sql.Row{{0, "interactive rebase started on branch dolt_rebase_branch1; " +
+					"adjust the rebase plan in the dolt_rebase table, then " +
+					"continue rebasing by calling dolt_rebase('--continue')"}},
+			},
+			{
+				Query:    "insert into dolt_rebase values (2, 'exec', '', '', 'insert into t values (100)');",
+				Expected: []sql.Row{{gmstypes.NewOkResult(1)}},
+			},
+			{
+				Query:       "call dolt_rebase('--continue');",
+				ExpectedErr: dprocedures.ErrRebaseExecLeftChanges,
+			},
+			{
+				Query:    "select active_branch();",
+				Expected: []sql.Row{{"branch1"}},
+			},
+			{
+				Query:    "select * from t;",
+				Expected: []sql.Row{{1}},
+			},
+		},
+	},
 }
 
 var DoltRebaseMultiSessionScriptTests = []queries.ScriptTest{
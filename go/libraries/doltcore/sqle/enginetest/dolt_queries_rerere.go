@@ -0,0 +1,208 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+)
+
+var rerereSetUpScript = []string{
+	"create table t (pk int primary key, c int, key (c));",
+	"insert into t values (1, 1), (2, 2), (3, 3);",
+	"call dolt_commit('-Am', 'create table t');",
+	"call dolt_branch('other');",
+	"update t set c = 10 where pk = 1;",
+	"update t set c = 20 where pk = 2;",
+	"call dolt_commit('-am', 'ours');",
+	"call dolt_checkout('other');",
+	"update t set c = 11 where pk = 1;",
+	"delete from t where pk = 2;",
+	"update t set c = 33 where pk = 3;",
+	"call dolt_commit('-am', 'theirs');",
+	"call dolt_checkout('main');",
+}
+
+var DoltRerereScriptTests = []queries.ScriptTest{
+	{
+		Name:        "dolt_rerere errors",
+		SetUpScript: rerereSetUpScript,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_rerere();",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "call dolt_rerere('list');",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:          "call dolt_rerere('list', '--all');",
+				ExpectedErrStr: "error: --all can only be used with rerere forget",
+			},
+			{
+				Query:          "call dolt_rerere('forget');",
+				ExpectedErrStr: "error: rerere forget requires either resolution ids or --all",
+			},
+			{
+				Query:          "call dolt_rerere('forget', 'notahash');",
+				ExpectedErrStr: "error: invalid resolution id 'notahash'",
+			},
+			{
+				Query:          "call dolt_rerere('forget', 'u8g2r39gl0cbnlusr7rqqb8bqo1ncuk0');",
+				ExpectedErrStr: "error: no recorded resolution with id 'u8g2r39gl0cbnlusr7rqqb8bqo1ncuk0'",
+			},
+			{
+				Query:          "call dolt_rerere('bogus');",
+				ExpectedErrStr: "error: unknown rerere subcommand 'bogus'; expected one of list, forget",
+			},
+		},
+	},
+	{
+		Name:        "resolutions made with dolt_conflicts_resolve are reused by later merges",
+		SetUpScript: append(rerereSetUpScript, "set dolt_allow_commit_conflicts = on;"),
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{"", 0, 1, "conflicts found"}},
+			},
+			{
+				Query:    "select our_pk, our_c, their_pk, their_c from dolt_conflicts_t order by our_pk;",
+				Expected: []sql.Row{{1, 10, 1, 11}, {2, 20, nil, nil}},
+			},
+			{
+				Query:    "call dolt_conflicts_resolve('--theirs', 't');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "call dolt_rerere('list');",
+				Expected: []sql.Row{{doltCommit, "t", "1", "11"}, {doltCommit, "t", "2", nil}},
+			},
+			{
+				Query:    "call dolt_merge('--abort');",
+				Expected: []sql.Row{{"", 0, 0, "merge aborted"}},
+			},
+			{
+				Query:    "call dolt_merge('other', '--no-commit');",
+				Expected: []sql.Row{{"", 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "select count(*) from dolt_conflicts_t;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select * from t order by pk;",
+				Expected: []sql.Row{{1, 11}, {3, 33}},
+			},
+			{
+				// the secondary index reflects the reused resolutions
+				Query:    "select pk from t where c in (10, 11, 20);",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "call dolt_merge('--abort');",
+				Expected: []sql.Row{{"", 0, 0, "merge aborted"}},
+			},
+			{
+				Query:    "call dolt_rerere('forget', '--all');",
+				Expected: []sql.Row{{doltCommit, "t", "1", "11"}, {doltCommit, "t", "2", nil}},
+			},
+			{
+				Query:    "call dolt_rerere();",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{"", 0, 1, "conflicts found"}},
+			},
+			{
+				Query:    "select count(*) from dolt_conflicts_t;",
+				Expected: []sql.Row{{2}},
+			},
+		},
+	},
+	{
+		Name: "resolutions made by editing dolt_conflicts tables are reused by later cherry-picks",
+		SetUpScript: append(rerereSetUpScript,
+			"set @@autocommit = 0;",
+		),
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_cherry_pick(hashof('other'));",
+				Expected: []sql.Row{{"", 1, 0, 0}},
+			},
+			{
+				Query:    "update t set c = 100 where pk = 1;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "delete from dolt_conflicts_t where our_pk = 1;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				// only the deleted conflict is recorded
+				Query:    "call dolt_rerere();",
+				Expected: []sql.Row{{doltCommit, "t", "1", "100"}},
+			},
+			{
+				Query:    "call dolt_cherry_pick('--abort');",
+				Expected: []sql.Row{{"", 0, 0, 0}},
+			},
+			{
+				Query:    "call dolt_cherry_pick(hashof('other'));",
+				Expected: []sql.Row{{"", 1, 0, 0}},
+			},
+			{
+				Query:    "select our_pk, our_c, their_pk, their_c from dolt_conflicts_t;",
+				Expected: []sql.Row{{2, 20, nil, nil}},
+			},
+			{
+				Query:    "select * from t order by pk;",
+				Expected: []sql.Row{{1, 100}, {2, 20}, {3, 33}},
+			},
+		},
+	},
+	{
+		Name: "resolutions are not reused when a side of the conflict differs",
+		SetUpScript: append(rerereSetUpScript,
+			"set dolt_allow_commit_conflicts = on;",
+			"call dolt_merge('other');",
+			"call dolt_conflicts_resolve('--ours', 't');",
+			"call dolt_merge('--abort');",
+			"update t set c = 12 where pk = 1;",
+			"call dolt_commit('-am', 'ours again');",
+		),
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_rerere();",
+				Expected: []sql.Row{{doltCommit, "t", "1", "10"}, {doltCommit, "t", "2", "20"}},
+			},
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{"", 0, 1, "conflicts found"}},
+			},
+			{
+				Query:    "select our_pk, our_c, their_pk, their_c from dolt_conflicts_t;",
+				Expected: []sql.Row{{1, 12, 1, 11}},
+			},
+			{
+				Query:    "select * from t order by pk;",
+				Expected: []sql.Row{{1, 12}, {2, 20}, {3, 33}},
+			},
+		},
+	},
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+)
+
+var DoltRowLineageScriptTests = []queries.ScriptTest{
+	{
+		Name: "dolt_row_lineage tracks rows across primary key updates",
+		SetUpScript: []string{
+			"create table t (pk int primary key, name varchar(20), age int);",
+			"insert into t values (1, 'alice', 30), (2, 'bob', 40);",
+			"call dolt_commit('-Am', 'create t');",
+			"update t set age = 31 where pk = 1;",
+			"call dolt_commit('-am', 'update alice');",
+			"update t set pk = 10 where pk = 1;",
+			"call dolt_commit('-am', 'rekey alice');",
+			"delete from t where pk = 2;",
+			"insert into t values (3, 'bob', 41);",
+			"call dolt_commit('-am', 'rekey and update bob');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select diff_type, `match`, similarity, from_key, to_key from dolt_row_lineage_t;",
+				Expected: []sql.Row{
+					{"added", nil, nil, nil, types.MustJSON(`{"pk": 1}`)},
+					{"added", nil, nil, nil, types.MustJSON(`{"pk": 2}`)},
+					{"modified", "primary_key", nil, types.MustJSON(`{"pk": 1}`), types.MustJSON(`{"pk": 1}`)},
+					{"modified", "similarity", 1.0, types.MustJSON(`{"pk": 1}`), types.MustJSON(`{"pk": 10}`)},
+					{"removed", nil, nil, types.MustJSON(`{"pk": 2}`), nil},
+					{"added", nil, nil, nil, types.MustJSON(`{"pk": 3}`)},
+				},
+			},
+			{
+				Query:    "select count(distinct row_id) from dolt_row_lineage_t;",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				Query:    "select count(*) from dolt_row_lineage_t where row_id = (select row_id from dolt_row_lineage_t where to_key = cast('{\"pk\": 10}' as json));",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				Query:    "select l.committer, g.message from dolt_row_lineage_t l join dolt_log g on l.commit_hash = g.commit_hash where l.diff_type = 'removed';",
+				Expected: []sql.Row{{"root", "rekey and update bob"}},
+			},
+			{
+				Query:    "set @@dolt_row_lineage_similarity = 0.5;",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "select diff_type, `match`, similarity, to_key from dolt_row_lineage_t where from_key = cast('{\"pk\": 2}' as json);",
+				Expected: []sql.Row{{"modified", "similarity", 0.5, types.MustJSON(`{"pk": 3}`)}},
+			},
+		},
+	},
+	{
+		Name: "dolt_row_lineage pairs rows across a redefined primary key",
+		SetUpScript: []string{
+			"create table t (id int primary key, code varchar(10) not null, name varchar(20), unique key code_idx (code));",
+			"insert into t values (1, 'a', 'alice'), (2, 'b', 'bob');",
+			"call dolt_commit('-Am', 'create t');",
+			"alter table t drop primary key;",
+			"alter table t add primary key (code);",
+			"call dolt_commit('-am', 'rekey t');",
+			"update t set code = 'c' where code = 'b';",
+			"call dolt_commit('-am', 'update bob');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select diff_type, `match`, from_key, to_key from dolt_row_lineage_t where commit_hash = hashof('HEAD~1');",
+				Expected: []sql.Row{
+					{"modified", "natural_key", types.MustJSON(`{"id": 1}`), types.MustJSON(`{"code": "a"}`)},
+					{"modified", "natural_key", types.MustJSON(`{"id": 2}`), types.MustJSON(`{"code": "b"}`)},
+				},
+			},
+			{
+				Query:    "select count(distinct row_id) from dolt_row_lineage_t;",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select diff_type, `match`, similarity, to_key from dolt_row_lineage_t where commit_hash = hashof('HEAD');",
+				Expected: []sql.Row{{"modified", "similarity", 1.0, types.MustJSON(`{"code": "c"}`)}},
+			},
+		},
+	},
+	{
+		Name: "dolt_row_lineage errors",
+		SetUpScript: []string{
+			"create table keyless (a int);",
+			"call dolt_commit('-Am', 'create keyless');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:          "select * from dolt_row_lineage_keyless;",
+				ExpectedErrStr: "unable to track the lineage of the rows of a table without a primary key",
+			},
+			{
+				Query:       "select * from dolt_row_lineage_nope;",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+		},
+	},
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+)
+
+var DoltTestsScriptTests = []queries.ScriptTest{
+	{
+		Name: "dolt_tests is versioned and validates its rows",
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_tests;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:          "insert into dolt_tests values ('t1', null, 'select 1', 'some_rows', null, null);",
+				ExpectedErrStr: "unknown test assertion type 'some_rows'; expected one of no_rows, expected_row_count, expected_rows",
+			},
+			{
+				Query:          "insert into dolt_tests values ('t1', null, '', 'no_rows', null, null);",
+				ExpectedErrStr: "test 't1' must have a test_query",
+			},
+			{
+				Query:          "insert into dolt_tests values ('t1', null, 'select 1', 'no_rows', null, '1');",
+				ExpectedErrStr: "test 't1': the 'no_rows' assertion type does not take an assertion_value",
+			},
+			{
+				Query:          "insert into dolt_tests values ('t1', null, 'select 1', 'expected_row_count', null, 'one');",
+				ExpectedErrStr: "test 't1': assertion_value of the 'expected_row_count' assertion type must be an integer, got 'one'",
+			},
+			{
+				Query:          "insert into dolt_tests values ('t1', null, 'select 1', 'expected_row_count', '=', '1');",
+				ExpectedErrStr: "test 't1': unknown assertion_comparator '='; expected one of ==, !=, <, <=, >, >=",
+			},
+			{
+				Query:          "insert into dolt_tests values ('t1', null, 'select 1', 'no_rows', '>', null);",
+				ExpectedErrStr: "test 't1': assertion_comparator can only be set for the 'expected_row_count' assertion type",
+			},
+			{
+				Query:          "insert into dolt_tests values ('t1', null, 'select 1', 'expected_rows', null, '[[1]');",
+				ExpectedErrStr: "test 't1': assertion_value of the 'expected_rows' assertion type must be a JSON array of rows: unexpected EOF",
+			},
+			{
+				Query:    "insert into dolt_tests values ('t1', null, 'select 1', 'expected_row_count', null, '1');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from dolt_status;",
+				Expected: []sql.Row{{"dolt_tests", false, "new table"}},
+			},
+			{
+				Query:    "call dolt_commit('-Am', 'add tests');",
+				Expected: []sql.Row{{doltCommit}},
+			},
+			{
+				Query:    "select count(*) from dolt_tests as of 'HEAD~1';",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "dolt_tests of another database",
+		SetUpScript: []string{
+			"create database other;",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "insert into other.dolt_tests values ('t1', null, 'select 1', 'expected_row_count', null, '1');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select test_name from other.dolt_tests;",
+				Expected: []sql.Row{{"t1"}},
+			},
+			{
+				Query:    "select * from dolt_tests;",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "insert into `other/main`.dolt_tests values ('t2', null, 'select 1', 'no_rows', null, null);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select test_name from other.dolt_tests order by test_name;",
+				Expected: []sql.Row{{"t1"}, {"t2"}},
+			},
+		},
+	},
+	{
+		Name: "dolt_test_run runs each assertion type",
+		SetUpScript: []string{
+			"create table users (id int primary key, name varchar(20), email varchar(50));",
+			"insert into users values (1, 'alice', 'alice@example.com'), (2, 'bob', null);",
+			"insert into dolt_tests values " +
+				"('emails_present', 'users', 'select * from users where email is null', 'no_rows', null, null), " +
+				"('user_count', 'users', 'select * from users', 'expected_row_count', null, '2'), " +
+				"('at_least_one', null, 'select * from users', 'expected_row_count', '>=', '1'), " +
+				"('names', 'users', 'select id, name, email from users order by id', 'expected_rows', null, '[[1, \"alice\", \"alice@example.com\"], [2, \"bob\", null]]'), " +
+				"('wrong_names', null, 'select name from users order by id', 'expected_rows', null, '[[\"alice\"], [\"carol\"]]'), " +
+				"('not_a_select', null, 'delete from users', 'no_rows', null, null), " +
+				"('bad_query', null, 'select * from no_such_table', 'no_rows', null, null);",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "select test_name, test_group, status, message from dolt_test_run();",
+				Expected: []sql.Row{
+					{"at_least_one", nil, "PASS", nil},
+					{"bad_query", nil, "FAIL", "table not found: no_such_table"},
+					{"emails_present", "users", "FAIL", "expected no rows, got 1"},
+					{"names", "users", "PASS", nil},
+					{"not_a_select", nil, "FAIL", "test query must be a SELECT statement"},
+					{"user_count", "users", "PASS", nil},
+					{"wrong_names", nil, "FAIL", "row 2: expected [carol], got [bob]"},
+				},
+			},
+			{
+				Query:    "select count(*) from users;",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query: "select test_name, status from dolt_test_run('--group', 'users');",
+				Expected: []sql.Row{
+					{"emails_present", "FAIL"},
+					{"names", "PASS"},
+					{"user_count", "PASS"},
+				},
+			},
+			{
+				Query:    "select test_name from dolt_test_run('--group', 'nope');",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+	{
+		Name: "dolt_test_run runs the tests of a revision",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"insert into t values (1);",
+			"insert into dolt_tests values ('one_row', null, 'select * from t', 'expected_row_count', null, '1');",
+			"call dolt_commit('-Am', 'one row');",
+			"insert into t values (2);",
+			"call dolt_commit('-am', 'two rows');",
+			"call dolt_branch('other', 'HEAD~1');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select test_name, status, message from dolt_test_run();",
+				Expected: []sql.Row{{"one_row", "FAIL", "expected row count == 1, got 2"}},
+			},
+			{
+				Query:    "select test_name, status, message from dolt_test_run('HEAD~1');",
+				Expected: []sql.Row{{"one_row", "PASS", nil}},
+			},
+			{
+				Query:    "select test_name, status, message from dolt_test_run('other');",
+				Expected: []sql.Row{{"one_row", "PASS", nil}},
+			},
+			{
+				Query:          "select * from dolt_test_run('nope');",
+				ExpectedErrStr: "branch not found: nope",
+			},
+			{
+				Query:          "select * from dolt_test_run('HEAD', 'other');",
+				ExpectedErrStr: "Invalid argument to dolt_test_run: error: run has too many positional arguments. Expected at most 1, found 2: HEAD, other",
+			},
+		},
+	},
+}
@@ -0,0 +1,337 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/vectorindex"
+)
+
+// vectorIndexSetup creates and commits a table of two dimensional embeddings with a vector index on them.
+var vectorIndexSetup = []string{
+	"create table items (id int primary key, name varchar(20), emb json);",
+	"insert into items values (1, 'origin', '[0, 0]'), (2, 'near', '[1, 1]'), (3, 'far', '[10, 10]'), (4, 'up', '[0, 5]'), (5, 'none', null);",
+	"create vector index emb_idx on items(emb);",
+	"call dolt_commit('-Am', 'items');",
+}
+
+var DoltVectorIndexScriptTests = []queries.ScriptTest{
+	{
+		Name:        "nearest neighbor searches",
+		SetUpScript: vectorIndexSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from dolt_vector_indexes;",
+				Expected: []sql.Row{{"items", "emb_idx", "emb"}},
+			},
+			{
+				Query:    "select id from items order by vec_distance_l2(emb, '[1, 1.5]') limit 2;",
+				Expected: []sql.Row{{2}, {1}},
+			},
+			{
+				Query:    "select id from items order by vec_distance_l2('[9, 9]', emb) limit 1;",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				Query:          "select id from items order by vec_distance_cosine(emb, '[0, 2]') limit 1;",
+				ExpectedErrStr: "cosine distance is undefined for a zero vector",
+			},
+			{
+				Query:    "select id, vec_distance_l2(emb, '[0, 1]') from items where id in (1, 4) order by id;",
+				Expected: []sql.Row{{1, 1.0}, {4, 4.0}},
+			},
+			{
+				Query:    "select vec_distance_l2(emb, '[0, 1]') from items where id = 5;",
+				Expected: []sql.Row{{nil}},
+			},
+			{
+				Query: "explain select id from items order by vec_distance_l2(emb, '[1, 1]') limit 2;",
+				Expected: []sql.Row{
+					{"Limit(2)"},
+					{" └─ Project"},
+					{"     ├─ columns: [items.id]"},
+					{"     └─ IndexedTableAccess(items)"},
+					{"         ├─ index: [items.emb]"},
+					{"         ├─ order: VEC_DISTANCE_L2(items.emb, [1, 1])"},
+					{"         └─ columns: [id emb]"},
+				},
+			},
+			{
+				Query:    "select id from items where emb = cast('[0, 5]' as json);",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				Query:    "show indexes from items;",
+				Expected: []sql.Row{{"items", 0, "PRIMARY", 1, "id", nil, 0, nil, nil, "", "BTREE", "", "", "YES", nil}},
+			},
+			{
+				Query:    "show tables;",
+				Expected: []sql.Row{{"items"}},
+			},
+		},
+	},
+	{
+		Name:        "the cached indexes of a table follow its vector indexes",
+		SetUpScript: vectorIndexSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select id from items order by vec_distance_l2(emb, '[1, 1]') limit 1;",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "alter table items drop index emb_idx;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query: "explain select id from items order by vec_distance_l2(emb, '[1, 1]') limit 1;",
+				Expected: []sql.Row{
+					{"Limit(1)"},
+					{" └─ Project"},
+					{"     ├─ columns: [items.id]"},
+					{"     └─ Sort(VEC_DISTANCE_L2(items.emb, [1, 1]) ASC)"},
+					{"         └─ Table"},
+					{"             ├─ name: items"},
+					{"             └─ columns: [id emb]"},
+				},
+			},
+			{
+				Query:    "call dolt_reset('--hard');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query: "explain select id from items order by vec_distance_l2(emb, '[1, 1]') limit 1;",
+				Expected: []sql.Row{
+					{"Limit(1)"},
+					{" └─ Project"},
+					{"     ├─ columns: [items.id]"},
+					{"     └─ IndexedTableAccess(items)"},
+					{"         ├─ index: [items.emb]"},
+					{"         ├─ order: VEC_DISTANCE_L2(items.emb, [1, 1])"},
+					{"         └─ columns: [id emb]"},
+				},
+			},
+			{
+				Query:    "alter table items drop index emb_idx;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "create vector index other_idx on items(emb);",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query: "explain select id from items order by vec_distance_l2(emb, '[1, 1]') limit 1;",
+				Expected: []sql.Row{
+					{"Limit(1)"},
+					{" └─ Project"},
+					{"     ├─ columns: [items.id]"},
+					{"     └─ IndexedTableAccess(items)"},
+					{"         ├─ index: [items.emb]"},
+					{"         ├─ order: VEC_DISTANCE_L2(items.emb, [1, 1])"},
+					{"         └─ columns: [id emb]"},
+				},
+			},
+		},
+	},
+	{
+		Name:        "vector indexes are maintained by writes",
+		SetUpScript: vectorIndexSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "insert into items values (6, 'close', '[2, 2]');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "update items set emb = '[20, 20]' where id = 2;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "delete from items where id = 1;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id from items order by vec_distance_l2(emb, '[1, 1]') limit 2;",
+				Expected: []sql.Row{{6}, {4}},
+			},
+			{
+				Query:    "select count(*) from dolt_items_emb_idx_vec_buckets;",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				Query:    "select * from dolt_status;",
+				Expected: []sql.Row{{"items", false, "modified"}},
+			},
+			{
+				Query:    "truncate table items;",
+				Expected: []sql.Row{{types.NewOkResult(5)}},
+			},
+			{
+				Query:    "select count(*) from dolt_items_emb_idx_vec_buckets;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "insert into items values (7, 'again', '[3, 3]');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select id from items order by vec_distance_l2(emb, '[1, 1]') limit 1;",
+				Expected: []sql.Row{{7}},
+			},
+			{
+				Query:       "insert into dolt_items_emb_idx_vec_buckets values (1, 1);",
+				ExpectedErr: plan.ErrInsertIntoNotSupported,
+			},
+		},
+	},
+	{
+		Name:        "vector index errors and schema changes",
+		SetUpScript: vectorIndexSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:       "create vector index emb_idx on items(emb);",
+				ExpectedErr: sql.ErrDuplicateKey,
+			},
+			{
+				Query:       "create vector index name_idx on items(name);",
+				ExpectedErr: vectorindex.ErrUnsupportedColumnType,
+			},
+			{
+				Query:          "create vector index bad_idx on items(name, emb);",
+				ExpectedErrStr: "vector index 'bad_idx' must index exactly one column",
+			},
+			{
+				Query:       "create vector index missing_idx on items(nope);",
+				ExpectedErr: sql.ErrTableColumnNotFound,
+			},
+			{
+				Query:            "create table keyless (emb json);",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:       "create vector index emb_idx on keyless(emb);",
+				ExpectedErr: vectorindex.ErrKeylessTable,
+			},
+			{
+				Query:       "alter table items drop column emb;",
+				ExpectedErr: vectorindex.ErrIndexedColumn,
+			},
+			{
+				Query:       "alter table items rename column emb to embedding;",
+				ExpectedErr: vectorindex.ErrIndexedColumn,
+			},
+			{
+				Query:       "alter table items drop primary key;",
+				ExpectedErr: vectorindex.ErrPrimaryKeyChange,
+			},
+			{
+				Query:    "alter table items add column extra int;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "rename table items to things;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "select * from dolt_vector_indexes;",
+				Expected: []sql.Row{{"things", "emb_idx", "emb"}},
+			},
+			{
+				Query:    "select id from things order by vec_distance_l2(emb, '[0, 4]') limit 1;",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				Query:    "alter table things drop index emb_idx;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "select count(*) from dolt_vector_indexes;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select count(*) from information_schema.tables where table_name like 'dolt_%_vec_buckets';",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name:        "vector indexes are rebuilt on merge",
+		SetUpScript: vectorIndexSetup,
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:            "call dolt_checkout('-b', 'other');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "insert into items values (6, 'other', '[2, 1]');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "create table points (id int primary key, p varbinary(64));",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "insert into points values (1, x'0000803f00000000'), (2, x'000000000000803f');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "alter table points add vector index p_idx (p);",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "call dolt_commit('-Am', 'other');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "call dolt_checkout('main');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "insert into items values (7, 'main', '[1, 2]');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "call dolt_commit('-am', 'main');",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "call dolt_merge('other');",
+				Expected: []sql.Row{{doltCommit, 0, 0, "merge successful"}},
+			},
+			{
+				Query:    "select * from dolt_vector_indexes order by table_name;",
+				Expected: []sql.Row{{"items", "emb_idx", "emb"}, {"points", "p_idx", "p"}},
+			},
+			{
+				Query:    "select id from items order by vec_distance_l2(emb, '[2, 1]') limit 3;",
+				Expected: []sql.Row{{6}, {2}, {7}},
+			},
+			{
+				Query:    "select count(*) from dolt_items_emb_idx_vec_buckets;",
+				Expected: []sql.Row{{6}},
+			},
+			{
+				Query:    "select id from points order by vec_distance_cosine(p, x'000000000000a040') limit 1;",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select a.id, b.id from points a join points b on a.p = b.p order by a.id;",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+		},
+	},
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/enginetest/queries"
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dprocedures"
+)
+
+var DoltWaitForCommitScriptTests = []queries.ScriptTest{
+	{
+		Name: "dolt_wait_for_commit returns for commits on a branch",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create table');",
+			"set @first = hashof('HEAD');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'insert');",
+			"set @head = hashof('HEAD');",
+			"call dolt_branch('other');",
+			"call dolt_checkout('other');",
+			"insert into t values (2);",
+			"call dolt_commit('-am', 'insert on other');",
+			"set @other = hashof('HEAD');",
+			"call dolt_checkout('main');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "call dolt_wait_for_commit(@head);",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "call dolt_wait_for_commit(@first, 0);",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				// Commits on any branch of the database are found.
+				Query:    "call dolt_wait_for_commit(@other, '0.5');",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "dolt_wait_for_commit times out for commits which are not on a branch",
+		SetUpScript: []string{
+			"create table t (pk int primary key);",
+			"call dolt_commit('-Am', 'create table');",
+			"call dolt_branch('other');",
+			"call dolt_checkout('other');",
+			"insert into t values (1);",
+			"call dolt_commit('-am', 'insert on other');",
+			"set @other = hashof('HEAD');",
+			"call dolt_checkout('main');",
+			"call dolt_branch('-D', 'other');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:       "call dolt_wait_for_commit(@other, 0.1);",
+				ExpectedErr: dprocedures.ErrWaitForCommitTimeout,
+			},
+			{
+				Query:          "call dolt_wait_for_commit('0123456789abcdefghijklmnopqrstuv', 0);",
+				ExpectedErrStr: "timed out waiting for commit 0123456789abcdefghijklmnopqrstuv in database mydb after 0s",
+			},
+			{
+				Query:          "call dolt_wait_for_commit('not a hash');",
+				ExpectedErrStr: "invalid commit hash: not a hash",
+			},
+			{
+				Query:          "call dolt_wait_for_commit('0123456789abcdefghijklmnopqrstuv', -1);",
+				ExpectedErrStr: "invalid timeout: -1",
+			},
+			{
+				Query:          "call dolt_wait_for_commit();",
+				ExpectedErrStr: "dolt_wait_for_commit takes a commit hash and an optional timeout in seconds",
+			},
+		},
+	},
+}
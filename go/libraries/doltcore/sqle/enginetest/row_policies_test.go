@@ -0,0 +1,545 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// RowPolicyTests are BranchControlTests whose assertions check the enforcement of dolt_row_policies for each user.
+var RowPolicyTests = []BranchControlTest{
+	{
+		Name: "row policies filter reads and check writes",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk INT PRIMARY KEY, owner VARCHAR(20), v INT, INDEX idx_owner (owner));",
+			"INSERT INTO t VALUES (1, 'alice', 10), (2, 'bob', 20), (3, 'alice', 30), (4, 'carol', 40);",
+			"CALL dolt_commit('-Am', 'create t');",
+			"CREATE USER alice@localhost;",
+			"GRANT ALL ON *.* TO alice@localhost WITH GRANT OPTION;",
+			"CREATE USER bob@localhost;",
+			"GRANT ALL ON *.* TO bob@localhost WITH GRANT OPTION;",
+			"CREATE USER carol@localhost;",
+			"GRANT ALL ON *.* TO carol@localhost WITH GRANT OPTION;",
+			"INSERT INTO dolt_row_policies VALUES ('t', 'alice_owns', 'alice', '%', 'owner = ''alice'''), ('t', 'bob_small', 'bob', 'localhost', 'v < 25'), ('t', 'admin', 'root', '%', 'true');",
+			"CALL dolt_commit('-Am', 'add row policies');",
+			"UPDATE t SET owner = 'alice' WHERE pk = 4;",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t ORDER BY pk;",
+				Expected: []sql.Row{{1, "alice", 10}, {3, "alice", 30}, {4, "alice", 40}},
+			},
+			{
+				User:     "bob",
+				Host:     "localhost",
+				Query:    "SELECT pk, v FROM t ORDER BY pk;",
+				Expected: []sql.Row{{1, 10}, {2, 20}},
+			},
+			{
+				User:     "carol",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t;",
+				Expected: []sql.Row{},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM t;",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM t;",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM t WHERE owner = 'bob';",
+				Expected: []sql.Row{},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT owner FROM t WHERE pk = 2;",
+				Expected: []sql.Row{},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM t AS OF 'HEAD~1' ORDER BY pk;",
+				Expected: []sql.Row{{1}, {3}},
+			},
+			{
+				User:     "bob",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM t AS OF 'HEAD~1' ORDER BY pk;",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk, count(*) FROM dolt_history_t GROUP BY pk ORDER BY pk;",
+				Expected: []sql.Row{{1, 2}, {3, 2}},
+			},
+			{
+				User:     "carol",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM dolt_history_t;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT to_pk, from_pk, diff_type FROM dolt_diff_t ORDER BY to_pk, diff_type;",
+				Expected: []sql.Row{{1, nil, "added"}, {3, nil, "added"}},
+			},
+			{
+				User:  "root",
+				Host:  "localhost",
+				Query: "SELECT to_pk, from_pk, diff_type FROM dolt_diff_t ORDER BY to_pk, diff_type;",
+				Expected: []sql.Row{
+					{1, nil, "added"},
+					{2, nil, "added"},
+					{3, nil, "added"},
+					{4, nil, "added"},
+					{4, 4, "modified"},
+				},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT DISTINCT pk FROM dolt_blame_cells_t ORDER BY pk;",
+				Expected: []sql.Row{{1}, {3}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT to_key FROM dolt_row_lineage_t;",
+				Expected: []sql.Row{{types.MustJSON(`{"pk": 1}`)}, {types.MustJSON(`{"pk": 3}`)}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT to_pk, from_pk, diff_type FROM dolt_commit_diff_t WHERE from_commit = HASHOF('HEAD') AND to_commit = 'WORKING';",
+				Expected: []sql.Row{},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT to_pk, from_pk, diff_type FROM dolt_commit_diff_t WHERE from_commit = HASHOF('HEAD') AND to_commit = 'WORKING';",
+				Expected: []sql.Row{{4, 4, "modified"}},
+			},
+			{
+				User:        "alice",
+				Host:        "localhost",
+				Query:       "INSERT INTO t VALUES (5, 'bob', 50);",
+				ExpectedErr: dsess.ErrRowPolicyViolation,
+			},
+			{
+				User:        "alice",
+				Host:        "localhost",
+				Query:       "UPDATE t SET owner = 'bob' WHERE pk = 1;",
+				ExpectedErr: dsess.ErrRowPolicyViolation,
+			},
+			{
+				User:        "alice",
+				Host:        "localhost",
+				Query:       "REPLACE INTO t VALUES (2, 'alice', 0);",
+				ExpectedErr: dsess.ErrRowPolicyViolation,
+			},
+			{
+				User:  "alice",
+				Host:  "localhost",
+				Query: "INSERT INTO t VALUES (5, 'alice', 50);",
+				Expected: []sql.Row{
+					{types.NewOkResult(1)},
+				},
+			},
+			{
+				User:  "alice",
+				Host:  "localhost",
+				Query: "UPDATE t SET v = v + 1;",
+				Expected: []sql.Row{
+					{types.OkResult{RowsAffected: 4, Info: plan.UpdateInfo{Matched: 4, Updated: 4}}},
+				},
+			},
+			{
+				User:  "bob",
+				Host:  "localhost",
+				Query: "DELETE FROM t;",
+				Expected: []sql.Row{
+					{types.NewOkResult(2)},
+				},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT pk, owner, v FROM t ORDER BY pk;",
+				Expected: []sql.Row{{3, "alice", 31}, {4, "alice", 41}, {5, "alice", 51}},
+			},
+		},
+	},
+	{
+		Name: "row policies must be valid",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk INT PRIMARY KEY, v INT);",
+			"INSERT INTO t VALUES (1, 1), (2, 2);",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:           "root",
+				Host:           "localhost",
+				Query:          "INSERT INTO dolt_row_policies VALUES ('t', 'empty', '%', '%', ' ');",
+				ExpectedErrStr: "row policy 'empty' on table 't' requires a predicate",
+			},
+			{
+				User:           "root",
+				Host:           "localhost",
+				Query:          "INSERT INTO dolt_row_policies VALUES ('t', 'no_user', '', '%', 'v > 1');",
+				ExpectedErrStr: "row policy 'no_user' on table 't' requires a user and a host pattern; use '%' to match all",
+			},
+			{
+				User:  "root",
+				Host:  "localhost",
+				Query: "INSERT INTO dolt_row_policies VALUES ('t', 'bad_column', '%', '%', 'missing > 1');",
+				Expected: []sql.Row{
+					{types.NewOkResult(1)},
+				},
+			},
+			{
+				User:           "root",
+				Host:           "localhost",
+				Query:          "SELECT * FROM t;",
+				ExpectedErrStr: "row policy 'bad_column' on table 't' has an invalid predicate: column \"missing\" could not be found in any table in scope",
+			},
+			{
+				User:  "root",
+				Host:  "localhost",
+				Query: "UPDATE dolt_row_policies SET predicate = 'v > 1';",
+				Expected: []sql.Row{
+					{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}},
+				},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t;",
+				Expected: []sql.Row{{2, 2}},
+			},
+		},
+	},
+	{
+		Name: "row policies filter diff table functions",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk INT PRIMARY KEY, owner VARCHAR(20), v INT);",
+			"INSERT INTO t VALUES (1, 'alice', 10), (2, 'bob', 20);",
+			"CREATE USER alice@localhost;",
+			"GRANT ALL ON *.* TO alice@localhost WITH GRANT OPTION;",
+			"INSERT INTO dolt_row_policies VALUES ('t', 'alice_owns', 'alice', '%', 'owner = ''alice'''), ('t', 'admin', 'root', '%', 'true');",
+			"CALL dolt_commit('-Am', 'create t');",
+			"INSERT INTO t VALUES (3, 'alice', 30), (4, 'bob', 40);",
+			"UPDATE t SET v = v + 1 WHERE pk < 3;",
+			"UPDATE t SET owner = 'alice' WHERE pk = 2;",
+			"CALL dolt_commit('-am', 'change t');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT to_pk, from_pk, diff_type FROM dolt_diff('HEAD~1', 'HEAD', 't') ORDER BY to_pk;",
+				Expected: []sql.Row{{1, 1, "modified"}, {3, nil, "added"}},
+			},
+			{
+				User:  "root",
+				Host:  "localhost",
+				Query: "SELECT to_pk, from_pk, diff_type FROM dolt_diff('HEAD~1', 'HEAD', 't') ORDER BY to_pk;",
+				Expected: []sql.Row{
+					{1, 1, "modified"},
+					{2, 2, "modified"},
+					{3, nil, "added"},
+					{4, nil, "added"},
+				},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM dolt_patch('HEAD~1', 'HEAD', 't') WHERE diff_type = 'data';",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM dolt_patch('HEAD~1', 'HEAD', 't') WHERE diff_type = 'data';",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT from_pk, to_pk, diff_type FROM dolt_query_diff('SELECT * FROM t AS OF ''HEAD~1''', 'SELECT * FROM t') ORDER BY to_pk;",
+				Expected: []sql.Row{{1, 1, "modified"}, {nil, 2, "added"}, {nil, 3, "added"}},
+			},
+			{
+				User:  "root",
+				Host:  "localhost",
+				Query: "SELECT from_pk, to_pk, diff_type FROM dolt_query_diff('SELECT * FROM t AS OF ''HEAD~1''', 'SELECT * FROM t') ORDER BY to_pk;",
+				Expected: []sql.Row{
+					{1, 1, "modified"},
+					{2, 2, "modified"},
+					{nil, 3, "added"},
+					{nil, 4, "added"},
+				},
+			},
+		},
+	},
+	{
+		Name: "row policies filter conflicts and constraint violations",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk INT PRIMARY KEY, owner VARCHAR(20), v INT);",
+			"INSERT INTO t VALUES (1, 'alice', 10), (2, 'alice', 20), (3, 'bob', 30);",
+			"CREATE TABLE parent (pk INT PRIMARY KEY);",
+			"CREATE TABLE child (pk INT PRIMARY KEY, owner VARCHAR(20), parent_id INT, FOREIGN KEY (parent_id) REFERENCES parent (pk));",
+			"SET foreign_key_checks = 0;",
+			"INSERT INTO child VALUES (1, 'alice', 10), (2, 'bob', 20);",
+			"SET foreign_key_checks = 1;",
+			"CREATE USER alice@localhost;",
+			"GRANT ALL ON *.* TO alice@localhost WITH GRANT OPTION;",
+			"INSERT INTO dolt_row_policies VALUES ('t', 'alice_owns', 'alice', '%', 'owner = ''alice'''), ('t', 'admin', 'root', '%', 'true'), " +
+				"('child', 'alice_owns', 'alice', '%', 'owner = ''alice'''), ('child', 'admin', 'root', '%', 'true');",
+			"CALL dolt_commit('-Am', 'create tables');",
+			"CALL dolt_branch('other');",
+			"UPDATE t SET v = 100;",
+			"CALL dolt_commit('-am', 'update t on main');",
+			"CALL dolt_checkout('other');",
+			"UPDATE t SET v = 200 WHERE pk IN (1, 3);",
+			"UPDATE t SET owner = 'bob', v = 200 WHERE pk = 2;",
+			"CALL dolt_commit('-am', 'update t on other');",
+			"CALL dolt_checkout('main');",
+			"SET @@autocommit = 0;",
+			"CALL dolt_merge('other');",
+			"CALL dolt_verify_constraints('--all', 'child');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT base_pk, our_pk, our_v, their_pk, their_v FROM dolt_conflicts_t ORDER BY base_pk;",
+				Expected: []sql.Row{{1, 1, 100, 1, 200}},
+			},
+			{
+				User:  "root",
+				Host:  "localhost",
+				Query: "SELECT base_pk, our_pk, our_v, their_pk, their_v FROM dolt_conflicts_t ORDER BY base_pk;",
+				Expected: []sql.Row{
+					{1, 1, 100, 1, 200},
+					{2, 2, 100, 2, 200},
+					{3, 3, 100, 3, 200},
+				},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk, owner FROM dolt_constraint_violations_child;",
+				Expected: []sql.Row{{1, "alice"}},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT pk, owner FROM dolt_constraint_violations_child ORDER BY pk;",
+				Expected: []sql.Row{{1, "alice"}, {2, "bob"}},
+			},
+		},
+	},
+	{
+		Name: "only privileged users may change row policies",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk INT PRIMARY KEY, owner VARCHAR(20));",
+			"INSERT INTO t VALUES (1, 'dave'), (2, 'erin');",
+			"CREATE USER dave@localhost;",
+			"GRANT ALL ON mydb.* TO dave@localhost;",
+			"CREATE USER erin@localhost;",
+			"GRANT ALL ON mydb.* TO erin@localhost;",
+			"GRANT SUPER ON *.* TO erin@localhost;",
+			"INSERT INTO dolt_row_policies VALUES ('t', 'dave_owns', 'dave', '%', 'owner = ''dave''');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:        "dave",
+				Host:        "localhost",
+				Query:       "DELETE FROM dolt_row_policies WHERE policy_name = 'dave_owns';",
+				ExpectedErr: dsess.ErrRowPoliciesWriteDenied,
+			},
+			{
+				User:        "dave",
+				Host:        "localhost",
+				Query:       "UPDATE dolt_row_policies SET predicate = 'true';",
+				ExpectedErr: dsess.ErrRowPoliciesWriteDenied,
+			},
+			{
+				User:        "dave",
+				Host:        "localhost",
+				Query:       "INSERT INTO dolt_row_policies VALUES ('t', 'dave_all', 'dave', '%', 'true');",
+				ExpectedErr: dsess.ErrRowPoliciesWriteDenied,
+			},
+			{
+				User:     "dave",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t;",
+				Expected: []sql.Row{{1, "dave"}},
+			},
+			{
+				User:  "erin",
+				Host:  "localhost",
+				Query: "UPDATE dolt_row_policies SET predicate = 'true';",
+				Expected: []sql.Row{
+					{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}},
+				},
+			},
+			{
+				User:     "dave",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t ORDER BY pk;",
+				Expected: []sql.Row{{1, "dave"}, {2, "erin"}},
+			},
+		},
+	},
+	{
+		Name: "row policies can't be rewound by reading older revisions",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk INT PRIMARY KEY, owner VARCHAR(20));",
+			"INSERT INTO t VALUES (1, 'alice'), (2, 'bob');",
+			"CALL dolt_commit('-Am', 'create t');",
+			"CALL dolt_tag('before_policies');",
+			"CALL dolt_branch('old');",
+			"CREATE USER alice@localhost;",
+			"GRANT ALL ON *.* TO alice@localhost;",
+			"INSERT INTO dolt_row_policies VALUES ('t', 'alice_owns', 'alice', '%', 'owner = ''alice'''), ('t', 'admin', 'root', '%', 'true');",
+			"CALL dolt_commit('-Am', 'add row policies');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM `mydb/old`.t;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM `mydb/before_policies`.t;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM t AS OF 'HEAD~1';",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM `mydb/old`.t AS OF 'before_policies';",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM `mydb/old`.dolt_history_t;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "alice",
+				Host:     "localhost",
+				Query:    "SELECT to_pk FROM `mydb/old`.dolt_diff_t;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "SELECT pk FROM `mydb/old`.t ORDER BY pk;",
+				Expected: []sql.Row{{1}, {2}},
+			},
+		},
+	},
+	{
+		Name: "only privileged users may change row policies through procedures",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk INT PRIMARY KEY, owner VARCHAR(20));",
+			"INSERT INTO t VALUES (1, 'dave'), (2, 'erin');",
+			"CALL dolt_commit('-Am', 'create t');",
+			"CREATE USER dave@localhost;",
+			"GRANT ALL ON mydb.* TO dave@localhost;",
+			"INSERT INTO dolt_row_policies VALUES ('t', 'dave_owns', 'dave', '%', 'owner = ''dave''');",
+			"CALL dolt_commit('-Am', 'add row policies');",
+		},
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:        "dave",
+				Host:        "localhost",
+				Query:       "CALL dolt_reset('--hard', 'HEAD~1');",
+				ExpectedErr: dsess.ErrRowPoliciesWriteDenied,
+			},
+			{
+				User:     "dave",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t;",
+				Expected: []sql.Row{{1, "dave"}},
+			},
+			{
+				User:        "dave",
+				Host:        "localhost",
+				Query:       "CALL dolt_revert('HEAD');",
+				ExpectedErr: dsess.ErrRowPoliciesWriteDenied,
+			},
+			{
+				User:     "dave",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t;",
+				Expected: []sql.Row{{1, "dave"}},
+			},
+			{
+				User:     "dave",
+				Host:     "localhost",
+				Query:    "SELECT count(*) FROM dolt_row_policies;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "root",
+				Host:     "localhost",
+				Query:    "CALL dolt_reset('--hard', 'HEAD~1');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "dave",
+				Host:     "localhost",
+				Query:    "SELECT * FROM t ORDER BY pk;",
+				Expected: []sql.Row{{1, "dave"}, {2, "erin"}},
+			},
+		},
+	},
+}
+
+func TestRowPolicies(t *testing.T) {
+	runBranchControlTests(t, RowPolicyTests)
+}
@@ -71,6 +71,29 @@ func ResolveCheckExpression(ctx *sql.Context, tableName string, sch schema.Schem
 	return nil, fmt.Errorf("unable to find check expression")
 }
 
+// ResolveRowPolicyExpression returns a sql.Expression for the row policy predicate provided. Like a check constraint,
+// the predicate may only refer to the columns of the table, and the returned expression is evaluated against rows
+// containing every column of |sch|.
+func ResolveRowPolicyExpression(ctx *sql.Context, tableName string, sch schema.Schema, predicate string) (sql.Expression, error) {
+	checks := schema.NewCheckCollection()
+	if _, err := checks.AddCheck("row_policy", predicate, true); err != nil {
+		return nil, err
+	}
+	policySch, err := schema.NewSchema(sch.GetAllCols(), sch.GetPkOrdinals(), sch.GetCollation(), nil, checks)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := parseCreateTable(ctx, tableName, policySch)
+	if err != nil {
+		return nil, err
+	}
+	if len(ct.Checks()) != 1 {
+		return nil, fmt.Errorf("unable to find row policy expression")
+	}
+	return ct.Checks()[0].Expr, nil
+}
+
 func stripTableNamesFromExpression(expr sql.Expression) sql.Expression {
 	e, _, _ := transform.Expr(expr, func(e sql.Expression) (sql.Expression, transform.TreeIdentity, error) {
 		if col, ok := e.(*expression.GetField); ok {
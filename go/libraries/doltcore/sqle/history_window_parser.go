@@ -0,0 +1,149 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	ast "github.com/dolthub/vitess/go/vt/sqlparser"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtablefunctions"
+)
+
+var ErrQualifiedHistoryWindow = errors.NewKind("FOR SYSTEM_TIME ranges are not supported on tables of other databases: %s")
+
+// HistoryWindowParser is a sql.Parser that rewrites the tables of a query that are read FOR SYSTEM_TIME BETWEEN,
+// FROM ... TO, CONTAINED IN or ALL into calls of the dolt_history_window() table function, which returns the versions
+// of their rows within the window. A table read AS OF a single point in time is left to the engine.
+type HistoryWindowParser struct {
+	sql.Parser
+}
+
+var _ sql.Parser = HistoryWindowParser{}
+
+// NewHistoryWindowParser returns a HistoryWindowParser wrapping the given parser.
+func NewHistoryWindowParser(parser sql.Parser) HistoryWindowParser {
+	return HistoryWindowParser{Parser: parser}
+}
+
+// ParseSimple implements the sql.Parser interface.
+func (p HistoryWindowParser) ParseSimple(query string) (ast.Statement, error) {
+	stmt, err := p.Parser.ParseSimple(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt, RewriteHistoryWindows(stmt)
+}
+
+// Parse implements the sql.Parser interface.
+func (p HistoryWindowParser) Parse(ctx *sql.Context, query string, multi bool) (ast.Statement, string, string, error) {
+	stmt, parsed, remainder, err := p.Parser.Parse(ctx, query, multi)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return stmt, parsed, remainder, RewriteHistoryWindows(stmt)
+}
+
+// ParseWithOptions implements the sql.Parser interface.
+func (p HistoryWindowParser) ParseWithOptions(ctx context.Context, query string, delimiter rune, multi bool, options ast.ParserOptions) (ast.Statement, string, string, error) {
+	stmt, parsed, remainder, err := p.Parser.ParseWithOptions(ctx, query, delimiter, multi, options)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return stmt, parsed, remainder, RewriteHistoryWindows(stmt)
+}
+
+// ParseOneWithOptions implements the sql.Parser interface.
+func (p HistoryWindowParser) ParseOneWithOptions(ctx context.Context, query string, options ast.ParserOptions) (ast.Statement, int, error) {
+	stmt, ri, err := p.Parser.ParseOneWithOptions(ctx, query, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	return stmt, ri, RewriteHistoryWindows(stmt)
+}
+
+// RewriteHistoryWindows replaces, in place, every table of the statement that is read over a window of its history.
+// Statements parsed without a HistoryWindowParser must be rewritten with it before they're given to the engine.
+func RewriteHistoryWindows(stmt ast.Statement) error {
+	if stmt == nil {
+		return nil
+	}
+	return ast.Walk(func(node ast.SQLNode) (bool, error) {
+		var err error
+		switch node := node.(type) {
+		case ast.TableExprs:
+			for i := range node {
+				if node[i], err = rewriteHistoryWindow(node[i]); err != nil {
+					return false, err
+				}
+			}
+		case *ast.JoinTableExpr:
+			if node.LeftExpr, err = rewriteHistoryWindow(node.LeftExpr); err != nil {
+				return false, err
+			}
+			if node.RightExpr, err = rewriteHistoryWindow(node.RightExpr); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}, stmt)
+}
+
+// rewriteHistoryWindow returns the dolt_history_window() call reading the given table expression, if it's a table read
+// over a window of its history, and the table expression itself otherwise.
+func rewriteHistoryWindow(expr ast.TableExpr) (ast.TableExpr, error) {
+	aliased, ok := expr.(*ast.AliasedTableExpr)
+	if !ok || aliased.AsOf == nil || aliased.AsOf.Time != nil {
+		return expr, nil
+	}
+	tableName, ok := aliased.Expr.(ast.TableName)
+	if !ok {
+		return expr, nil
+	}
+	if !tableName.DbQualifier.IsEmpty() || !tableName.SchemaQualifier.IsEmpty() {
+		return nil, ErrQualifiedHistoryWindow.New(ast.String(tableName))
+	}
+
+	// BETWEEN includes the versions valid at any point of the window, FROM ... TO excludes the versions starting at
+	// its end, and CONTAINED IN only includes the versions that start and end within it. ALL is an unbounded window.
+	asOf := aliased.AsOf
+	mode := dtablefunctions.HistoryWindowBetween
+	start, end := ast.Expr(&ast.NullVal{}), ast.Expr(&ast.NullVal{})
+	if !asOf.All {
+		start, end = asOf.Start, asOf.End
+		if asOf.StartInclusive && asOf.EndInclusive {
+			mode = dtablefunctions.HistoryWindowContainedIn
+		} else if !asOf.EndInclusive {
+			mode = dtablefunctions.HistoryWindowFrom
+		}
+	}
+
+	alias := aliased.As
+	if alias.IsEmpty() {
+		alias = tableName.Name
+	}
+	return &ast.TableFuncExpr{
+		Name: "dolt_history_window",
+		Exprs: ast.SelectExprs{
+			&ast.AliasedExpr{Expr: ast.NewStrVal([]byte(tableName.Name.String()))},
+			&ast.AliasedExpr{Expr: start},
+			&ast.AliasedExpr{Expr: end},
+			&ast.AliasedExpr{Expr: ast.NewStrVal([]byte(mode))},
+		},
+		Alias: alias,
+	}, nil
+}
@@ -582,8 +582,8 @@ type LookupMeta struct {
 func GetStrictLookups(schCols *schema.ColCollection, indexes []sql.Index) []LookupMeta {
 	var lookups []LookupMeta
 	for _, i := range indexes {
-		idx := i.(*doltIndex)
-		if !idx.IsUnique() {
+		idx, ok := i.(*doltIndex)
+		if !ok || !idx.IsUnique() {
 			continue
 		}
 		var nullAccepting bool
@@ -79,6 +79,11 @@ func (idt *IndexedDoltTable) PartitionRows(ctx *sql.Context, part sql.Partition)
 		return nil, err
 	}
 
+	if filter, err := idt.rowPolicyFilter(ctx); err != nil {
+		return nil, err
+	} else if filter != nil {
+		return idt.rowPolicyIndexedRows(ctx, filter, idt.idx, key, idt.isDoltFormat, part)
+	}
 	if idt.lb == nil || !canCache || idt.lb.Key() != key {
 		idt.lb, err = index.NewIndexReaderBuilder(ctx, idt.DoltTable, idt.idx, key, idt.DoltTable.projectedCols, idt.DoltTable.sqlSch, idt.isDoltFormat)
 		if err != nil {
@@ -96,6 +101,11 @@ func (idt *IndexedDoltTable) PartitionRows2(ctx *sql.Context, part sql.Partition
 	if err != nil {
 		return nil, err
 	}
+	if filter, err := idt.rowPolicyFilter(ctx); err != nil {
+		return nil, err
+	} else if filter != nil {
+		return idt.rowPolicyIndexedRows(ctx, filter, idt.idx, key, idt.isDoltFormat, part)
+	}
 	if idt.lb == nil || !canCache || idt.lb.Key() != key {
 		idt.lb, err = index.NewIndexReaderBuilder(ctx, idt.DoltTable, idt.idx, key, idt.DoltTable.projectedCols, idt.DoltTable.sqlSch, idt.isDoltFormat)
 		if err != nil {
@@ -162,6 +172,11 @@ func (t *WritableIndexedDoltTable) PartitionRows(ctx *sql.Context, part sql.Part
 	if err != nil {
 		return nil, err
 	}
+	if filter, err := t.rowPolicyFilter(ctx); err != nil {
+		return nil, err
+	} else if filter != nil {
+		return t.rowPolicyIndexedRows(ctx, filter, t.idx, key, t.isDoltFormat, part)
+	}
 	if t.lb == nil || !canCache || t.lb.Key() != key {
 		t.lb, err = index.NewIndexReaderBuilder(ctx, t.DoltTable, t.idx, key, t.projectedCols, t.sqlSch, t.isDoltFormat)
 		if err != nil {
@@ -16,6 +16,7 @@ package kvexec
 
 import (
 	"context"
+	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
@@ -32,7 +33,11 @@ import (
 	"github.com/dolthub/dolt/go/store/val"
 )
 
-type Builder struct{}
+type Builder struct {
+	// ScanParallelism is the number of workers which read the partitions of
+	// a table scan. Scans are serial if it is less than 2.
+	ScanParallelism int
+}
 
 var _ sql.NodeExecBuilder = (*Builder)(nil)
 
@@ -58,7 +63,55 @@ func (b Builder) Build(ctx *sql.Context, n sql.Node, r sql.Row) (sql.RowIter, er
 				}
 			}
 		}
+		if (n.Op == plan.JoinTypeMerge || n.Op == plan.JoinTypeLeftOuterMerge) && len(r) == 0 {
+			if _, leftIter, _, leftSchema, leftTags, leftFilter, err := getSourceKv(ctx, n.Left(), true); err == nil && leftSchema != nil {
+				if rightMap, rightIter, _, rightSchema, rightTags, rightFilter, err := getSourceKv(ctx, n.Right(), true); err == nil && rightSchema != nil {
+					if leftField, rightField, ok := mergeJoinFields(ctx, n, leftSchema, rightSchema); ok {
+						// conditions:
+						// (1) merge or left merge join
+						// (2) both sides are something we read KVs from (table or indexscan, ex: no subqueries)
+						// (3) the first join filter is an equality between a column of each side, which both sides
+						//     are ordered by
+						// (4) the join columns have encodings that compare like their values (ex: no collations)
+						rowJoiner := newRowJoiner([]schema.Schema{leftSchema, rightSchema}, []int{len(leftTags)}, concatTags(leftTags, rightTags), rightMap.NodeStore())
+						// the first filter is the join column equality, which every match satisfies
+						joinFilter := expression.JoinAnd(expression.SplitConjunction(n.Filter)[1:]...)
+						return newMergeJoinKvIter(leftIter, rightIter, leftField, rightField, rowJoiner, leftFilter, rightFilter, joinFilter, n.Op.IsLeftOuter()), nil
+					}
+				}
+			}
+		}
+		if (n.Op == plan.JoinTypeHash || n.Op == plan.JoinTypeLeftOuterHash) && len(r) == 0 {
+			if hl, ok := n.Right().(*plan.HashLookup); ok {
+				if _, leftIter, _, leftSchema, leftTags, leftFilter, err := getSourceKv(ctx, n.Left(), true); err == nil && leftSchema != nil {
+					if rightMap, rightIter, _, rightSchema, rightTags, rightFilter, err := getSourceKv(ctx, unwrapCachedResults(hl.Child), true); err == nil && rightSchema != nil {
+						if leftFields, rightFields, ok := hashJoinFields(hl, n.Filter, leftSchema, rightSchema); ok {
+							// conditions:
+							// (1) hash or left hash join
+							// (2) both sides are something we read KVs from (table or indexscan, ex: no subqueries)
+							// (3) the hash keys are columns (ex: no arithmetic yet)
+							// (4) the key columns have encodings that compare like their values (ex: no collations)
+							rowJoiner := newRowJoiner([]schema.Schema{leftSchema, rightSchema}, []int{len(leftTags)}, concatTags(leftTags, rightTags), rightMap.NodeStore())
+							return newHashJoinKvIter(leftIter, rightIter, leftFields, rightFields, rowJoiner, leftFilter, rightFilter, n.Filter, n.Op.IsLeftOuter()), nil
+						}
+					}
+				}
+			}
+		}
 	case *plan.GroupBy:
+		if len(n.GroupByExprs) > 0 && len(r) == 0 {
+			if srcMap, srcIter, _, srcSchema, _, srcFilter, err := getSourceKv(ctx, n.Child, true); err == nil && srcSchema != nil && srcFilter == nil {
+				if ordering, _ := sourceOrdering(ctx, n.Child, srcSchema); len(ordering) > 0 {
+					iter, ok, err := newGroupByKvIter(srcIter, srcSchema, ordering, n.GroupByExprs, n.SelectedExprs, srcMap.NodeStore())
+					if ok && err == nil {
+						// (1) the grouping expressions are columns which are a prefix of the order of the child
+						// (2) selected expressions are grouping columns, or COUNT, SUM, AVG, MIN and MAX of columns
+						// (3) table or ita as child (no filters)
+						return iter, nil
+					}
+				}
+			}
+		}
 		if len(n.GroupByExprs) == 0 && len(n.SelectedExprs) == 1 {
 			if cnt, ok := n.SelectedExprs[0].(*aggregation.Count); ok {
 				if _, srcIter, _, srcSchema, _, srcFilter, err := getSourceKv(ctx, n.Child, true); err == nil && srcSchema != nil && srcFilter == nil {
@@ -72,6 +125,20 @@ func (b Builder) Build(ctx *sql.Context, n sql.Node, r sql.Row) (sql.RowIter, er
 				}
 			}
 		}
+	case *plan.ResolvedTable:
+		if b.ScanParallelism > 1 && len(r) == 0 {
+			if ok, err := hasRowPolicies(ctx, n.UnderlyingTable()); err == nil && !ok {
+				iter, ok, err := newParallelScanIter(ctx, n, b.ScanParallelism)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					// (1) Dolt table without virtual columns
+					// (2) more than one partition
+					return iter, nil
+				}
+			}
+		}
 	default:
 	}
 	return nil, nil
@@ -282,6 +349,10 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 		return m, mIter, destIter, s, t, n.Expression, nil
 	case *plan.IndexedTableAccess:
 		var lb index.IndexScanBuilder
+		if ok, err := hasRowPolicies(ctx, n.UnderlyingTable()); err != nil || ok {
+			// rows hidden by row policies are filtered by the table's row iterators, which we bypass
+			return prolly.Map{}, nil, nil, nil, nil, nil, err
+		}
 		switch dt := n.UnderlyingTable().(type) {
 		case *sqle.WritableIndexedDoltTable:
 			tags = dt.ProjectedTags()
@@ -337,6 +408,10 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 		}
 
 	case *plan.ResolvedTable:
+		if ok, err := hasRowPolicies(ctx, n.UnderlyingTable()); err != nil || ok {
+			// rows hidden by row policies are filtered by the table's row iterators, which we bypass
+			return prolly.Map{}, nil, nil, nil, nil, nil, err
+		}
 		switch dt := n.UnderlyingTable().(type) {
 		case *sqle.WritableDoltTable:
 			tags = dt.ProjectedTags()
@@ -390,3 +465,125 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 
 	return indexMap, srcIter, dstIter, sch, tags, nil, nil
 }
+
+// sourceOrdering returns the names of the columns that the KV pairs read
+// from |n| by getSourceKv are ordered by, and whether they are in reverse
+// order. Returns nil if the pairs aren't ordered by columns.
+func sourceOrdering(ctx *sql.Context, n sql.Node, sch schema.Schema) ([]string, bool) {
+	switch n := n.(type) {
+	case *plan.TableAlias:
+		return sourceOrdering(ctx, n.Child, sch)
+	case *plan.Filter:
+		return sourceOrdering(ctx, n.Child, sch)
+	case *plan.IndexedTableAccess:
+		l, err := n.GetLookup(ctx, nil)
+		if err != nil {
+			return nil, false
+		}
+		var ret []string
+		for _, e := range n.Index().Expressions() {
+			ret = append(ret, strings.ToLower(e[strings.LastIndex(e, ".")+1:]))
+		}
+		return ret, l.IsReverse
+	case *plan.ResolvedTable:
+		if schema.IsKeyless(sch) {
+			// keyless rows are ordered by their hash
+			return nil, false
+		}
+		var ret []string
+		for _, col := range sch.GetPKCols().GetColumns() {
+			ret = append(ret, strings.ToLower(col.Name))
+		}
+		return ret, false
+	default:
+		return nil, false
+	}
+}
+
+// kvField locates a column in the KV pairs read from a source.
+type kvField struct {
+	isKey bool
+	idx   int
+	typ   val.Type
+}
+
+// newKvField returns the kvField of the column |name| of |sch|, or false
+// if it isn't a stored column of |sch|.
+func newKvField(sch schema.Schema, name string) (kvField, bool) {
+	col, ok := sch.GetAllCols().LowerNameToCol[strings.ToLower(name)]
+	if !ok || col.Virtual {
+		return kvField{}, false
+	}
+	if col.IsPartOfPK {
+		idx, ok := sch.GetPKCols().StoredIndexByTag(col.Tag)
+		if !ok {
+			return kvField{}, false
+		}
+		return kvField{isKey: true, idx: idx, typ: sch.GetKeyDescriptor().Types[idx]}, true
+	}
+	idx, ok := sch.GetNonPKCols().StoredIndexByTag(col.Tag)
+	if !ok {
+		return kvField{}, false
+	}
+	if schema.IsKeyless(sch) {
+		// skip the cardinality column
+		idx++
+	}
+	return kvField{idx: idx, typ: sch.GetValueDescriptor().Types[idx]}, true
+}
+
+// get returns the encoded value of the field in |key| or |value|, or nil
+// if it is NULL.
+func (f kvField) get(key, value val.Tuple) []byte {
+	if f.isKey {
+		return key.GetField(f.idx)
+	}
+	return value.GetField(f.idx)
+}
+
+// desc returns the descriptor of the tuple the field is read from.
+func (f kvField) desc(sch schema.Schema) val.TupleDesc {
+	if f.isKey {
+		return sch.GetKeyDescriptor()
+	}
+	return sch.GetValueDescriptor()
+}
+
+// bytewiseComparable returns whether values of |enc| are equal exactly when
+// their encodings are, so that they can be joined and grouped on without
+// converting them to SQL values. Strings are not, because of collations, and
+// neither are enums and sets, whose encodings depend on the column's members.
+func bytewiseComparable(enc val.Encoding) bool {
+	switch enc {
+	case val.Int8Enc, val.Uint8Enc, val.Int16Enc, val.Uint16Enc, val.Int32Enc, val.Uint32Enc, val.Int64Enc, val.Uint64Enc,
+		val.YearEnc, val.DateEnc, val.TimeEnc, val.DatetimeEnc, val.Bit64Enc:
+		return true
+	default:
+		return false
+	}
+}
+
+// concatTags returns the projections of a join of two sources.
+func concatTags(left, right []uint64) []uint64 {
+	ret := make([]uint64, 0, len(left)+len(right))
+	ret = append(ret, left...)
+	return append(ret, right...)
+}
+
+func unwrapCachedResults(n sql.Node) sql.Node {
+	if cr, ok := n.(*plan.CachedResults); ok {
+		return cr.Child
+	}
+	return n
+}
+
+// hasRowPolicies returns whether the rows of |t| are filtered by dolt_row_policies for the current user.
+func hasRowPolicies(ctx *sql.Context, t sql.Table) (bool, error) {
+	rpt, ok := t.(interface {
+		HasRowPolicies(*sql.Context) (bool, error)
+	})
+	if !ok {
+		return false, nil
+	}
+	return rpt.HasRowPolicies(ctx)
+}
@@ -0,0 +1,374 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
+	"github.com/shopspring/decimal"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// newGroupByKvIter returns an iterator which aggregates the rows of
+// |srcIter| one group at a time, if the grouping columns |groupBy| are a
+// prefix of |ordering|, which |srcIter| is ordered by, so that the rows of
+// each group are next to each other.
+func newGroupByKvIter(srcIter prolly.MapIter, sch schema.Schema, ordering []string, groupBy, selected []sql.Expression, ns tree.NodeStore) (sql.RowIter, bool, error) {
+	if schema.IsKeyless(sch) || len(groupBy) > len(ordering) {
+		return nil, false, nil
+	}
+
+	prefix := make(map[string]bool, len(groupBy))
+	for _, name := range ordering[:len(groupBy)] {
+		prefix[name] = true
+	}
+	groupFields := make([]kvField, len(groupBy))
+	groupNames := make(map[string]bool, len(groupBy))
+	for i, e := range groupBy {
+		gf, ok := e.(*expression.GetField)
+		if !ok || !prefix[strings.ToLower(gf.Name())] {
+			return nil, false, nil
+		}
+		groupFields[i], ok = newKvField(sch, gf.Name())
+		if !ok || !bytewiseComparable(groupFields[i].typ.Enc) {
+			return nil, false, nil
+		}
+		groupNames[strings.ToLower(gf.Name())] = true
+	}
+	if len(groupNames) != len(groupBy) {
+		return nil, false, nil
+	}
+
+	aggs := make([]*kvAggregation, len(selected))
+	for i, e := range selected {
+		agg, ok := newKvAggregation(e, sch, groupNames, ns)
+		if !ok {
+			return nil, false, nil
+		}
+		aggs[i] = agg
+	}
+
+	return &groupByKvIter{
+		srcIter:     srcIter,
+		groupFields: groupFields,
+		aggs:        aggs,
+	}, true, nil
+}
+
+// groupByKvIter aggregates rows which are ordered by their grouping
+// columns, and returns a row for each group as soon as it reads the first
+// row of the next one.
+type groupByKvIter struct {
+	srcIter     prolly.MapIter
+	groupFields []kvField
+	aggs        []*kvAggregation
+
+	// the first row of the next group
+	nextKey, nextVal val.Tuple
+	started          bool
+	done             bool
+}
+
+var _ sql.RowIter = (*groupByKvIter)(nil)
+
+func (g *groupByKvIter) Close(_ *sql.Context) error {
+	return nil
+}
+
+func (g *groupByKvIter) Next(ctx *sql.Context) (sql.Row, error) {
+	if !g.started {
+		g.started = true
+		if err := g.readNext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if g.done {
+		return nil, io.EOF
+	}
+
+	groupKey, groupVal := g.nextKey, g.nextVal
+	for _, agg := range g.aggs {
+		agg.reset()
+	}
+	for {
+		for _, agg := range g.aggs {
+			agg.update(g.nextKey, g.nextVal)
+		}
+		if err := g.readNext(ctx); err != nil {
+			return nil, err
+		}
+		if g.done || !g.sameGroup(groupKey, groupVal, g.nextKey, g.nextVal) {
+			break
+		}
+	}
+
+	row := make(sql.Row, len(g.aggs))
+	for i, agg := range g.aggs {
+		v, err := agg.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		row[i] = v
+	}
+	return row, nil
+}
+
+func (g *groupByKvIter) readNext(ctx *sql.Context) error {
+	k, v, err := g.srcIter.Next(ctx)
+	if err == io.EOF || (err == nil && k == nil) {
+		g.done = true
+		g.nextKey, g.nextVal = nil, nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	g.nextKey, g.nextVal = k, v
+	return nil
+}
+
+func (g *groupByKvIter) sameGroup(key, value, otherKey, otherValue val.Tuple) bool {
+	for _, f := range g.groupFields {
+		if !bytes.Equal(f.get(key, value), f.get(otherKey, otherValue)) {
+			return false
+		}
+	}
+	return true
+}
+
+type kvAggregationKind uint8
+
+const (
+	// kvGroupColumn is a grouping column, which has the same value in every
+	// row of a group
+	kvGroupColumn kvAggregationKind = iota
+	kvCount
+	kvCountRows
+	kvSum
+	kvAvg
+	kvMin
+	kvMax
+)
+
+// kvAggregation computes a selected expression of a group from the fields
+// of its rows. The results match those of the aggregation buffers of
+// go-mysql-server: SUM and AVG are float64, unless the column is a
+// decimal, and MIN and MAX have the type of the column.
+type kvAggregation struct {
+	kind  kvAggregationKind
+	field kvField
+	desc  val.TupleDesc
+	ns    tree.NodeStore
+
+	rows     int64
+	floatSum float64
+	decSum   decimal.Decimal
+	// the row holding the current MIN, MAX or grouping column value
+	key, val val.Tuple
+}
+
+func newKvAggregation(e sql.Expression, sch schema.Schema, groupNames map[string]bool, ns tree.NodeStore) (*kvAggregation, bool) {
+	if a, ok := e.(*expression.Alias); ok {
+		e = a.Child
+	}
+
+	var kind kvAggregationKind
+	var child sql.Expression
+	switch e := e.(type) {
+	case *expression.GetField:
+		if !groupNames[strings.ToLower(e.Name())] {
+			return nil, false
+		}
+		kind, child = kvGroupColumn, e
+	case *aggregation.Count:
+		kind, child = kvCount, e.Child
+		if lit, ok := child.(*expression.Literal); ok && lit.Value() != nil {
+			kind = kvCountRows
+		}
+	case *aggregation.Sum:
+		kind, child = kvSum, e.Child
+	case *aggregation.Avg:
+		kind, child = kvAvg, e.Child
+	case *aggregation.Min:
+		kind, child = kvMin, e.Child
+	case *aggregation.Max:
+		kind, child = kvMax, e.Child
+	default:
+		return nil, false
+	}
+	if kind == kvCountRows {
+		return &kvAggregation{kind: kind, ns: ns}, true
+	}
+
+	gf, ok := child.(*expression.GetField)
+	if !ok {
+		return nil, false
+	}
+	field, ok := newKvField(sch, gf.Name())
+	if !ok {
+		return nil, false
+	}
+	switch kind {
+	case kvSum, kvAvg:
+		if !summable(field.typ.Enc) {
+			return nil, false
+		}
+	case kvMin, kvMax:
+		if !summable(field.typ.Enc) && !bytewiseComparable(field.typ.Enc) {
+			return nil, false
+		}
+	}
+	return &kvAggregation{kind: kind, field: field, desc: field.desc(sch), ns: ns}, true
+}
+
+// summable returns whether SUM and AVG of values of |enc| are computed
+// from the tuple fields.
+func summable(enc val.Encoding) bool {
+	switch enc {
+	case val.Int8Enc, val.Uint8Enc, val.Int16Enc, val.Uint16Enc, val.Int32Enc, val.Uint32Enc, val.Int64Enc, val.Uint64Enc,
+		val.Float32Enc, val.Float64Enc, val.DecimalEnc:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *kvAggregation) reset() {
+	a.rows = 0
+	a.floatSum = 0
+	a.decSum = decimal.Zero
+	a.key, a.val = nil, nil
+}
+
+func (a *kvAggregation) update(key, value val.Tuple) {
+	if a.kind == kvCountRows {
+		a.rows++
+		return
+	}
+	if a.kind == kvGroupColumn {
+		if a.key == nil {
+			a.key, a.val = key, value
+		}
+		return
+	}
+
+	v := a.field.get(key, value)
+	if v == nil {
+		return
+	}
+	switch a.kind {
+	case kvCount:
+	case kvSum, kvAvg:
+		tup := a.tuple(key, value)
+		if a.field.typ.Enc == val.DecimalEnc {
+			d, _ := a.desc.GetDecimal(a.field.idx, tup)
+			a.decSum = a.decSum.Add(d)
+		} else {
+			a.floatSum += a.float(tup)
+		}
+	case kvMin, kvMax:
+		if a.key == nil {
+			a.key, a.val = key, value
+		} else {
+			cmp := val.DefaultTupleComparator{}.CompareValues(0, v, a.field.get(a.key, a.val), a.field.typ)
+			if (a.kind == kvMin && cmp < 0) || (a.kind == kvMax && cmp > 0) {
+				a.key, a.val = key, value
+			}
+		}
+	}
+	a.rows++
+}
+
+func (a *kvAggregation) eval(ctx *sql.Context) (interface{}, error) {
+	switch a.kind {
+	case kvCount, kvCountRows:
+		return a.rows, nil
+	case kvSum:
+		if a.rows == 0 {
+			return nil, nil
+		}
+		if a.field.typ.Enc == val.DecimalEnc {
+			return a.decSum, nil
+		}
+		return a.floatSum, nil
+	case kvAvg:
+		if a.rows == 0 {
+			return nil, nil
+		}
+		if a.field.typ.Enc == val.DecimalEnc {
+			scale := (a.decSum.Exponent() * -1) + 4
+			return a.decSum.DivRound(decimal.NewFromInt(a.rows), scale), nil
+		}
+		return a.floatSum / float64(a.rows), nil
+	default:
+		if a.key == nil {
+			return nil, nil
+		}
+		return tree.GetField(ctx, a.desc, a.field.idx, a.tuple(a.key, a.val), a.ns)
+	}
+}
+
+func (a *kvAggregation) tuple(key, value val.Tuple) val.Tuple {
+	if a.field.isKey {
+		return key
+	}
+	return value
+}
+
+func (a *kvAggregation) float(tup val.Tuple) float64 {
+	i := a.field.idx
+	switch a.field.typ.Enc {
+	case val.Int8Enc:
+		v, _ := a.desc.GetInt8(i, tup)
+		return float64(v)
+	case val.Uint8Enc:
+		v, _ := a.desc.GetUint8(i, tup)
+		return float64(v)
+	case val.Int16Enc:
+		v, _ := a.desc.GetInt16(i, tup)
+		return float64(v)
+	case val.Uint16Enc:
+		v, _ := a.desc.GetUint16(i, tup)
+		return float64(v)
+	case val.Int32Enc:
+		v, _ := a.desc.GetInt32(i, tup)
+		return float64(v)
+	case val.Uint32Enc:
+		v, _ := a.desc.GetUint32(i, tup)
+		return float64(v)
+	case val.Int64Enc:
+		v, _ := a.desc.GetInt64(i, tup)
+		return float64(v)
+	case val.Uint64Enc:
+		v, _ := a.desc.GetUint64(i, tup)
+		return float64(v)
+	case val.Float32Enc:
+		v, _ := a.desc.GetFloat32(i, tup)
+		return float64(v)
+	case val.Float64Enc:
+		v, _ := a.desc.GetFloat64(i, tup)
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var groupByTestData = []string{
+	"insert into xyz values (1, 1, 10, 1.5), (1, 2, null, 2.5), (1, 3, 30, null), (2, 1, -5, 0.25), (3, 1, null, null), (3, 2, 7, 1.75)",
+}
+
+// TestGroupBy ensures that we trigger the operator replacement for
+// expected query patterns, and that it returns the same rows as the
+// generic row path.
+func TestGroupBy(t *testing.T) {
+	tests := []kvexecTest{
+		{
+			name: "accept group by primary key prefix",
+			setup: append([]string{
+				"create table xyz (x int, y int, z int, d decimal(10,2), primary key (x, y))",
+			}, groupByTestData...),
+			query:     "select x, count(*), count(z), sum(z), avg(z), min(z), max(z) from xyz group by x",
+			doRowexec: true,
+		},
+		{
+			name: "accept decimal aggregations",
+			setup: append([]string{
+				"create table xyz (x int, y int, z int, d decimal(10,2), primary key (x, y))",
+			}, groupByTestData...),
+			query:     "select x, sum(d), avg(d), min(d), max(d) from xyz group by x",
+			doRowexec: true,
+		},
+		{
+			name: "accept group by whole primary key",
+			setup: append([]string{
+				"create table xyz (x int, y int, z int, d decimal(10,2), primary key (x, y))",
+			}, groupByTestData...),
+			query:     "select y, x, sum(z) from xyz group by y, x",
+			doRowexec: true,
+		},
+		{
+			name: "reject group by non-prefix",
+			setup: append([]string{
+				"create table xyz (x int, y int, z int, d decimal(10,2), primary key (x, y))",
+			}, groupByTestData...),
+			query:     "select y, sum(z) from xyz group by y",
+			doRowexec: false,
+		},
+		{
+			name: "reject keyless table",
+			setup: []string{
+				"create table xyz (x int, y int, z int, d decimal(10,2))",
+			},
+			query:     "select x, sum(z) from xyz group by x",
+			doRowexec: false,
+		},
+		{
+			name: "reject filter child",
+			setup: []string{
+				"create table xyz (x int, y int, z int, d decimal(10,2), primary key (x, y))",
+			},
+			query:     "select x, sum(z) from xyz where z > 1 group by x",
+			doRowexec: false,
+		},
+		{
+			name: "reject unsupported aggregation",
+			setup: []string{
+				"create table xyz (x int, y int, z int, d decimal(10,2), primary key (x, y))",
+			},
+			query:     "select x, group_concat(z) from xyz group by x",
+			doRowexec: false,
+		},
+		{
+			name: "reject complex aggregation expression",
+			setup: []string{
+				"create table xyz (x int, y int, z int, d decimal(10,2), primary key (x, y))",
+			},
+			query:     "select x, sum(z+1) from xyz group by x",
+			doRowexec: false,
+		},
+		{
+			name: "reject string group keys",
+			setup: []string{
+				"create table xyz (x varchar(10), y int, z int, primary key (x, y))",
+			},
+			query:     "select x, sum(z) from xyz group by x",
+			doRowexec: false,
+		},
+	}
+
+	runKvexecTests(t, tests, getAgg, func(iter sql.RowIter) bool {
+		_, ok := iter.(*groupByKvIter)
+		return ok
+	})
+}
@@ -0,0 +1,243 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// hashJoinFields returns the key columns of the hash join of |hl|. The
+// keys must be columns with the same bytewise comparable encodings on both
+// sides. NULL keys are never matched, so null-safe equality is rejected, and
+// so are sources with virtual columns.
+func hashJoinFields(hl *plan.HashLookup, joinFilter sql.Expression, leftSch, rightSch schema.Schema) ([]kvField, []kvField, bool) {
+	if schema.IsVirtual(leftSch) || schema.IsVirtual(rightSch) {
+		return nil, nil, false
+	}
+	for _, f := range expression.SplitConjunction(joinFilter) {
+		if _, ok := f.(*expression.NullSafeEquals); ok {
+			return nil, nil, false
+		}
+	}
+	leftFields, ok := hashKeyFields(hl.LeftProbeKey, leftSch)
+	if !ok {
+		return nil, nil, false
+	}
+	rightFields, ok := hashKeyFields(hl.RightEntryKey, rightSch)
+	if !ok || len(leftFields) != len(rightFields) {
+		return nil, nil, false
+	}
+	for i := range leftFields {
+		if leftFields[i].typ.Enc != rightFields[i].typ.Enc || !bytewiseComparable(leftFields[i].typ.Enc) {
+			return nil, nil, false
+		}
+	}
+	return leftFields, rightFields, true
+}
+
+func hashKeyFields(key sql.Expression, sch schema.Schema) ([]kvField, bool) {
+	exprs := []sql.Expression{key}
+	if tup, ok := key.(expression.Tuple); ok {
+		exprs = tup
+	}
+	ret := make([]kvField, len(exprs))
+	for i, e := range exprs {
+		gf, ok := e.(*expression.GetField)
+		if !ok {
+			return nil, false
+		}
+		ret[i], ok = newKvField(sch, gf.Name())
+		if !ok {
+			return nil, false
+		}
+	}
+	return ret, true
+}
+
+// hashJoinKvIter joins two sources by reading the right side into a hash
+// table keyed on the encoded join columns, and probing it with every row of
+// the left side.
+type hashJoinKvIter struct {
+	leftIter    prolly.MapIter
+	rightIter   prolly.MapIter
+	leftFields  []kvField
+	rightFields []kvField
+
+	table  map[string][]sql.Row
+	built  bool
+	keyBuf []byte
+
+	// the left row being matched
+	leftRow     sql.Row
+	leftMatched bool
+	candidates  []sql.Row
+
+	// projections
+	joiner *prollyToSqlJoiner
+
+	leftFilter  sql.Expression
+	rightFilter sql.Expression
+	joinFilter  sql.Expression
+
+	isLeftJoin bool
+}
+
+var _ sql.RowIter = (*hashJoinKvIter)(nil)
+
+func newHashJoinKvIter(
+	leftIter, rightIter prolly.MapIter,
+	leftFields, rightFields []kvField,
+	joiner *prollyToSqlJoiner,
+	leftFilter, rightFilter, joinFilter sql.Expression,
+	isLeftJoin bool,
+) *hashJoinKvIter {
+	if lit, ok := joinFilter.(*expression.Literal); ok {
+		if lit.Value() == true {
+			joinFilter = nil
+		}
+	}
+	return &hashJoinKvIter{
+		leftIter:    leftIter,
+		rightIter:   rightIter,
+		leftFields:  leftFields,
+		rightFields: rightFields,
+		table:       make(map[string][]sql.Row),
+		joiner:      joiner,
+		leftFilter:  leftFilter,
+		rightFilter: rightFilter,
+		joinFilter:  joinFilter,
+		isLeftJoin:  isLeftJoin,
+	}
+}
+
+func (h *hashJoinKvIter) Close(_ *sql.Context) error {
+	h.table = nil
+	return nil
+}
+
+func (h *hashJoinKvIter) Next(ctx *sql.Context) (sql.Row, error) {
+	if !h.built {
+		if err := h.build(ctx); err != nil {
+			return nil, err
+		}
+		h.built = true
+	}
+	for {
+		if h.leftRow == nil {
+			if err := h.probe(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(h.candidates) > 0 {
+			ret := joinRows(h.leftRow, h.candidates[0], h.joiner.kvSplits[0])
+			h.candidates = h.candidates[1:]
+			ok, err := acceptRow(ctx, h.joinFilter, ret)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				h.leftMatched = true
+				return ret, nil
+			}
+			continue
+		}
+
+		// the left row has no more matches
+		leftRow := h.leftRow
+		h.leftRow = nil
+		if h.isLeftJoin && !h.leftMatched {
+			return leftRow, nil
+		}
+	}
+}
+
+// build reads the right rows which pass the right filter into the hash
+// table.
+func (h *hashJoinKvIter) build(ctx *sql.Context) error {
+	for {
+		k, v, err := h.rightIter.Next(ctx)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if k == nil {
+			return nil
+		}
+		key, ok := h.hashKey(h.rightFields, k, v)
+		if !ok {
+			continue
+		}
+		row, ok, err := buildSourceRow(ctx, h.joiner, h.rightFilter, false, k, v)
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.table[string(key)] = append(h.table[string(key)], row)
+		}
+	}
+}
+
+// probe reads the next left row which passes the left filter, and looks up
+// the right rows it is matched with.
+func (h *hashJoinKvIter) probe(ctx *sql.Context) error {
+	for {
+		k, v, err := h.leftIter.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if k == nil {
+			return io.EOF
+		}
+		row, ok, err := buildSourceRow(ctx, h.joiner, h.leftFilter, true, k, v)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		h.leftRow = row
+		h.leftMatched = false
+		h.candidates = nil
+		if key, ok := h.hashKey(h.leftFields, k, v); ok {
+			h.candidates = h.table[string(key)]
+		}
+		return nil
+	}
+}
+
+// hashKey encodes the key columns |fields| of a row. Returns false if one
+// of them is NULL, since NULL doesn't equal anything. The encodings of the
+// key columns have fixed sizes, so they are concatenated.
+func (h *hashJoinKvIter) hashKey(fields []kvField, key, value val.Tuple) ([]byte, bool) {
+	h.keyBuf = h.keyBuf[:0]
+	for _, f := range fields {
+		v := f.get(key, value)
+		if v == nil {
+			return nil, false
+		}
+		h.keyBuf = append(h.keyBuf, v...)
+	}
+	return h.keyBuf, true
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// TestHashJoin ensures that we trigger the operator replacement for
+// expected query patterns, and that it returns the same rows as the
+// generic row path.
+func TestHashJoin(t *testing.T) {
+	tests := []kvexecTest{
+		{
+			name: "accept hash join on non-indexed columns",
+			setup: append([]string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			}, joinTestData...),
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y = b",
+			doRowexec: true,
+		},
+		{
+			name: "accept left hash join",
+			setup: append([]string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			}, joinTestData...),
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy left join ab on y = b",
+			doRowexec: true,
+		},
+		{
+			name: "accept hash join on multiple columns",
+			setup: append([]string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			}, joinTestData...),
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y = b and x = a",
+			doRowexec: true,
+		},
+		{
+			name: "accept hash join with filters",
+			setup: append([]string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			}, joinTestData...),
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y = b where a > 2 and x < 4",
+			doRowexec: true,
+		},
+		{
+			name: "accept keyless hash join",
+			setup: append([]string{
+				"create table xy (x int, y int)",
+				"create table ab (a int, b int)",
+			}, append(joinTestData, "insert into ab values (6, 60)")...),
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y = b",
+			doRowexec: true,
+		},
+		{
+			name: "reject null safe equality",
+			setup: append([]string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			}, joinTestData...),
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y <=> b",
+			doRowexec: false,
+		},
+		{
+			name: "reject complex join expression",
+			setup: []string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			},
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y+1 = b",
+			doRowexec: false,
+		},
+		{
+			name: "reject string join keys",
+			setup: []string{
+				"create table xy (x int primary key, y varchar(10))",
+				"create table ab (a int primary key, b varchar(10))",
+			},
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y = b",
+			doRowexec: false,
+		},
+		{
+			name: "reject enum join keys",
+			setup: []string{
+				"create table xy (x int primary key, y enum('a', 'b'))",
+				"create table ab (a int primary key, b enum('b', 'a'))",
+			},
+			query:     "select /*+ HASH_JOIN(xy,ab) */ * from xy join ab on y = b",
+			doRowexec: false,
+		},
+	}
+
+	runKvexecTests(t, tests, getJoin, func(iter sql.RowIter) bool {
+		_, ok := iter.(*hashJoinKvIter)
+		return ok
+	})
+}
@@ -0,0 +1,311 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// mergeJoinFields returns the join columns of the merge join |n|, which
+// are the columns compared by the first join filter. Both sides must be
+// ordered by their join column, and the join columns must have the same
+// bytewise comparable encoding. Sources with virtual columns are rejected,
+// since their rows don't line up with the join's schema.
+func mergeJoinFields(ctx *sql.Context, n *plan.JoinNode, leftSch, rightSch schema.Schema) (kvField, kvField, bool) {
+	if schema.IsVirtual(leftSch) || schema.IsVirtual(rightSch) {
+		return kvField{}, kvField{}, false
+	}
+	eq, ok := expression.SplitConjunction(n.Filter)[0].(*expression.Equals)
+	if !ok {
+		return kvField{}, kvField{}, false
+	}
+	l, ok := eq.Left().(*expression.GetField)
+	if !ok {
+		return kvField{}, kvField{}, false
+	}
+	r, ok := eq.Right().(*expression.GetField)
+	if !ok {
+		return kvField{}, kvField{}, false
+	}
+	leftLen := len(n.Left().Schema())
+	if l.Index() >= leftLen && r.Index() < leftLen {
+		l, r = r, l
+	} else if l.Index() >= leftLen || r.Index() < leftLen {
+		return kvField{}, kvField{}, false
+	}
+
+	leftField, ok := newKvField(leftSch, l.Name())
+	if !ok {
+		return kvField{}, kvField{}, false
+	}
+	rightField, ok := newKvField(rightSch, r.Name())
+	if !ok {
+		return kvField{}, kvField{}, false
+	}
+	if leftField.typ.Enc != rightField.typ.Enc || !bytewiseComparable(leftField.typ.Enc) {
+		return kvField{}, kvField{}, false
+	}
+
+	leftOrder, leftReverse := sourceOrdering(ctx, n.Left(), leftSch)
+	rightOrder, rightReverse := sourceOrdering(ctx, n.Right(), rightSch)
+	if len(leftOrder) == 0 || len(rightOrder) == 0 || leftReverse || rightReverse {
+		return kvField{}, kvField{}, false
+	}
+	if leftOrder[0] != strings.ToLower(l.Name()) || rightOrder[0] != strings.ToLower(r.Name()) {
+		return kvField{}, kvField{}, false
+	}
+	return leftField, rightField, true
+}
+
+// mergeJoinKvIter joins two sources which are both ordered by their join
+// column. Every left row is matched with the run of right rows whose join
+// column is equal to its own, which is buffered so that the following left
+// rows with the same join value can reuse it.
+type mergeJoinKvIter struct {
+	leftIter   prolly.MapIter
+	rightIter  prolly.MapIter
+	leftField  kvField
+	rightField kvField
+
+	// the left row being matched
+	leftRow     sql.Row
+	leftMatched bool
+
+	// the next right row, which is past the buffered |matches|
+	rightKey, rightVal val.Tuple
+	rightDone          bool
+	started            bool
+
+	// matches are the right rows whose join value is |matchValue|
+	matches    []sql.Row
+	matchValue []byte
+	// candidates are the right rows for the current left row
+	candidates []sql.Row
+
+	// projections
+	joiner *prollyToSqlJoiner
+
+	leftFilter  sql.Expression
+	rightFilter sql.Expression
+	joinFilter  sql.Expression
+
+	isLeftJoin bool
+}
+
+var _ sql.RowIter = (*mergeJoinKvIter)(nil)
+
+func newMergeJoinKvIter(
+	leftIter, rightIter prolly.MapIter,
+	leftField, rightField kvField,
+	joiner *prollyToSqlJoiner,
+	leftFilter, rightFilter, joinFilter sql.Expression,
+	isLeftJoin bool,
+) *mergeJoinKvIter {
+	if lit, ok := joinFilter.(*expression.Literal); ok {
+		if lit.Value() == true {
+			joinFilter = nil
+		}
+	}
+	return &mergeJoinKvIter{
+		leftIter:    leftIter,
+		rightIter:   rightIter,
+		leftField:   leftField,
+		rightField:  rightField,
+		joiner:      joiner,
+		leftFilter:  leftFilter,
+		rightFilter: rightFilter,
+		joinFilter:  joinFilter,
+		isLeftJoin:  isLeftJoin,
+	}
+}
+
+func (m *mergeJoinKvIter) Close(_ *sql.Context) error {
+	return nil
+}
+
+func (m *mergeJoinKvIter) Next(ctx *sql.Context) (sql.Row, error) {
+	if !m.started {
+		m.started = true
+		if err := m.nextRight(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if m.leftRow == nil {
+			if err := m.nextLeft(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(m.candidates) > 0 {
+			ret := joinRows(m.leftRow, m.candidates[0], m.joiner.kvSplits[0])
+			m.candidates = m.candidates[1:]
+			ok, err := acceptRow(ctx, m.joinFilter, ret)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				m.leftMatched = true
+				return ret, nil
+			}
+			continue
+		}
+
+		// the left row has no more matches
+		leftRow := m.leftRow
+		m.leftRow = nil
+		if m.isLeftJoin && !m.leftMatched {
+			return leftRow, nil
+		}
+	}
+}
+
+// nextLeft reads the next left row which passes the left filter, and finds
+// the right rows it is matched with.
+func (m *mergeJoinKvIter) nextLeft(ctx *sql.Context) error {
+	var value []byte
+	for {
+		k, v, err := m.leftIter.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if k == nil {
+			return io.EOF
+		}
+		row, ok, err := buildSourceRow(ctx, m.joiner, m.leftFilter, true, k, v)
+		if err != nil {
+			return err
+		}
+		if ok {
+			m.leftRow = row
+			m.leftMatched = false
+			value = m.leftField.get(k, v)
+			break
+		}
+	}
+
+	if value == nil {
+		// NULL doesn't equal anything
+		m.candidates = nil
+		return nil
+	}
+	if m.matchValue != nil && m.compare(value, m.matchValue) == 0 {
+		m.candidates = m.matches
+		return nil
+	}
+
+	// skip the right rows before |value|, and buffer the ones equal to it
+	m.matches = m.matches[:0]
+	m.matchValue = nil
+	for !m.rightDone {
+		rightValue := m.rightField.get(m.rightKey, m.rightVal)
+		if rightValue != nil {
+			cmp := m.compare(value, rightValue)
+			if cmp < 0 {
+				break
+			} else if cmp == 0 {
+				row, ok, err := buildSourceRow(ctx, m.joiner, m.rightFilter, false, m.rightKey, m.rightVal)
+				if err != nil {
+					return err
+				}
+				if ok {
+					m.matches = append(m.matches, row)
+				}
+			}
+		}
+		if err := m.nextRight(ctx); err != nil {
+			return err
+		}
+	}
+	// the join value is kept even if the right filter rejected every match,
+	// so that the following left rows don't read past their matches
+	m.matchValue = value
+	m.candidates = m.matches
+	return nil
+}
+
+func (m *mergeJoinKvIter) nextRight(ctx *sql.Context) error {
+	k, v, err := m.rightIter.Next(ctx)
+	if err == io.EOF || (err == nil && k == nil) {
+		m.rightDone = true
+		m.rightKey, m.rightVal = nil, nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	m.rightKey, m.rightVal = k, v
+	return nil
+}
+
+func (m *mergeJoinKvIter) compare(left, right []byte) int {
+	return val.DefaultTupleComparator{}.CompareValues(0, left, right, m.leftField.typ)
+}
+
+// buildSourceRow converts the row |key|, |value| of the first or second
+// source of |joiner| into a joined row whose other columns are NULL. Returns
+// false if the row does not pass |filter|.
+func buildSourceRow(ctx *sql.Context, joiner *prollyToSqlJoiner, filter sql.Expression, first bool, key, value val.Tuple) (sql.Row, bool, error) {
+	var row sql.Row
+	var err error
+	split := joiner.kvSplits[0]
+	if first {
+		row, err = joiner.buildRow(ctx, key, value, nil, nil)
+	} else {
+		row, err = joiner.buildRow(ctx, nil, nil, key, value)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if first {
+		ok, err = acceptRow(ctx, filter, row[:split])
+	} else {
+		ok, err = acceptRow(ctx, filter, row[split:])
+	}
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return row, true, nil
+}
+
+// joinRows returns a row with the columns before |split| from |left|, and
+// the rest from |right|.
+func joinRows(left, right sql.Row, split int) sql.Row {
+	row := make(sql.Row, len(left))
+	copy(row, left[:split])
+	copy(row[split:], right[split:])
+	return row
+}
+
+// acceptRow returns whether |row| passes |filter|, if there is one.
+func acceptRow(ctx *sql.Context, filter sql.Expression, row sql.Row) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	res, err := sql.EvaluateCondition(ctx, filter, row)
+	if err != nil {
+		return false, err
+	}
+	return sql.IsTrue(res), nil
+}
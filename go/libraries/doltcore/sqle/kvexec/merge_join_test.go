@@ -0,0 +1,193 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/planbuilder"
+	"github.com/dolthub/go-mysql-server/sql/rowexec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+)
+
+type kvexecTest struct {
+	name      string
+	setup     []string
+	query     string
+	doRowexec bool
+}
+
+var joinTestData = []string{
+	"insert into xy values (1, 10), (2, 20), (3, null), (4, 20), (5, 50)",
+	"insert into ab values (0, 10), (2, 20), (3, 20), (4, null), (6, 60)",
+}
+
+// TestMergeJoin ensures that we trigger the operator replacement for
+// expected query patterns, and that it returns the same rows as the
+// generic row path.
+func TestMergeJoin(t *testing.T) {
+	tests := []kvexecTest{
+		{
+			name: "accept merge join on primary keys",
+			setup: append([]string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			}, joinTestData...),
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ * from xy join ab on x = a",
+			doRowexec: true,
+		},
+		{
+			name: "accept merge join on secondary indexes with duplicates",
+			setup: append([]string{
+				"create table xy (x int primary key, y int, key y_idx(y))",
+				"create table ab (a int primary key, b int, key b_idx(b))",
+			}, joinTestData...),
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ * from xy join ab on y = b",
+			doRowexec: true,
+		},
+		{
+			name: "accept left merge join",
+			setup: append([]string{
+				"create table xy (x int primary key, y int, key y_idx(y))",
+				"create table ab (a int primary key, b int, key b_idx(b))",
+			}, joinTestData...),
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ * from xy left join ab on y = b",
+			doRowexec: true,
+		},
+		{
+			name: "accept merge join with extra join filters",
+			setup: append([]string{
+				"create table xy (x int primary key, y int, key y_idx(y))",
+				"create table ab (a int primary key, b int, key b_idx(b))",
+			}, joinTestData...),
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ * from xy left join ab on y = b and x < a",
+			doRowexec: true,
+		},
+		{
+			name: "accept merge join with projections",
+			setup: append([]string{
+				"create table xy (x int primary key, y int, key y_idx(y))",
+				"create table ab (a int primary key, b int, key b_idx(b))",
+			}, joinTestData...),
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ a, y from xy join ab on y = b",
+			doRowexec: true,
+		},
+		{
+			name: "accept merge join with filters",
+			setup: append([]string{
+				"create table xy (x int primary key, y int, key y_idx(y))",
+				"create table ab (a int primary key, b int, key b_idx(b))",
+			}, joinTestData...),
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ * from xy left join ab on y = b where (a is null or a <> 2) and x <> 1",
+			doRowexec: true,
+		},
+		{
+			name: "reject string join keys",
+			setup: []string{
+				"create table xy (x varchar(10) primary key, y int)",
+				"create table ab (a varchar(10) primary key, b int)",
+				"insert into xy values ('a', 1), ('B', 2)",
+				"insert into ab values ('A', 1), ('b', 2)",
+			},
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ * from xy join ab on x = a",
+			doRowexec: false,
+		},
+		{
+			name: "reject type incompatibility",
+			setup: []string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a bigint primary key, b int)",
+			},
+			query:     "select /*+ MERGE_JOIN(xy,ab) */ * from xy join ab on x = a",
+			doRowexec: false,
+		},
+		{
+			name: "reject join in subquery with non-nil scope",
+			setup: []string{
+				"create table xy (x int primary key, y int)",
+				"create table ab (a int primary key, b int)",
+			},
+			query:     "select 1, (select /*+ MERGE_JOIN(xy,ab) */ count(*) from xy join ab on x = a) cnt",
+			doRowexec: false,
+		},
+	}
+
+	runKvexecTests(t, tests, getJoin, func(iter sql.RowIter) bool {
+		_, ok := iter.(*mergeJoinKvIter)
+		return ok
+	})
+}
+
+// runKvexecTests plans the query of each test, and checks whether the node
+// found by |getNode| is replaced by a kvexec operator, with |isKvexec|. The
+// rows of the operator are compared with those of the generic row path.
+func runKvexecTests(t *testing.T, tests []kvexecTest, getNode func(sql.Node) sql.Node, isKvexec func(sql.RowIter) bool) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dEnv := dtestutils.CreateTestEnv()
+			defer dEnv.DoltDB.Close()
+
+			tmpDir, err := dEnv.TempTableFilesDir()
+			require.NoError(t, err)
+
+			opts := editor.Options{Deaf: dEnv.DbEaFactory(), Tempdir: tmpDir}
+			db, err := sqle.NewDatabase(context.Background(), "dolt", dEnv.DbData(), opts)
+			require.NoError(t, err)
+
+			engine, ctx, err := sqle.NewTestEngine(dEnv, context.Background(), db)
+			require.NoError(t, err)
+
+			err = ctx.Session.SetSessionVariable(ctx, sql.AutoCommitSessionVar, false)
+			require.NoError(t, err)
+
+			for _, q := range tt.setup {
+				_, iter, _, err := engine.Query(ctx, q)
+				require.NoError(t, err)
+				_, err = sql.RowIterToRows(ctx, iter)
+				require.NoError(t, err)
+			}
+
+			binder := planbuilder.New(ctx, engine.EngineAnalyzer().Catalog, engine.Parser)
+			node, _, _, qFlags, err := binder.Parse(tt.query, nil, false)
+			require.NoError(t, err)
+			node, err = engine.EngineAnalyzer().Analyze(ctx, node, nil, qFlags)
+			require.NoError(t, err)
+
+			n := getNode(node)
+			require.NotNil(t, n)
+
+			iter, err := Builder{}.Build(ctx, n, nil)
+			require.NoError(t, err)
+			require.Equalf(t, tt.doRowexec, isKvexec(iter), "expected do row exec: %t", tt.doRowexec)
+			if iter == nil {
+				return
+			}
+
+			rows, err := sql.RowIterToRows(ctx, iter)
+			require.NoError(t, err)
+			expectedIter, err := rowexec.DefaultBuilder.Build(ctx, n, nil)
+			require.NoError(t, err)
+			expected, err := sql.RowIterToRows(ctx, expectedIter)
+			require.NoError(t, err)
+			require.ElementsMatch(t, expected, rows)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+)
+
+const (
+	// parallelScanBatchSize is the number of rows a worker sends at a time
+	parallelScanBatchSize = 256
+	// parallelScanBufferedBatches is the number of batches of a partition
+	// which are read ahead of the consumer
+	parallelScanBufferedBatches = 4
+)
+
+// newParallelScanIter returns an iterator which reads the partitions of the
+// table of |n| with up to |parallelism| workers. Returns false if the table
+// is not a Dolt table, or if it has fewer than two partitions. The rows are
+// returned in the order of the partitions, which is the order of a serial
+// scan, so that the parallel scan can replace any table scan.
+func newParallelScanIter(ctx *sql.Context, n *plan.ResolvedTable, parallelism int) (sql.RowIter, bool, error) {
+	switch n.UnderlyingTable().(type) {
+	case *sqle.DoltTable, *sqle.WritableDoltTable, *sqle.AlterableDoltTable:
+	default:
+		return nil, false, nil
+	}
+	if _, ok := plan.FindVirtualColumnTable(n.Table); ok {
+		return nil, false, nil
+	}
+
+	partIter, err := n.Table.Partitions(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var partitions []sql.Partition
+	for {
+		p, err := partIter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			_ = partIter.Close(ctx)
+			return nil, false, err
+		}
+		partitions = append(partitions, p)
+	}
+	if err := partIter.Close(ctx); err != nil {
+		return nil, false, err
+	}
+	if len(partitions) < 2 {
+		return nil, false, nil
+	}
+
+	if parallelism > len(partitions) {
+		parallelism = len(partitions)
+	}
+	results := make([]chan []sql.Row, len(partitions))
+	for i := range results {
+		results[i] = make(chan []sql.Row, parallelScanBufferedBatches)
+	}
+	return &parallelScanIter{
+		table:       n.Table,
+		partitions:  partitions,
+		parallelism: parallelism,
+		results:     results,
+		errs:        make([]error, len(partitions)),
+	}, true, nil
+}
+
+// parallelScanIter reads the partitions of a table concurrently, and
+// returns their rows in partition order. Workers claim partitions in
+// increasing order, and the consumer reads the lowest partition which isn't
+// exhausted, so the partition it waits on is always being read by a worker.
+type parallelScanIter struct {
+	table       sql.Table
+	partitions  []sql.Partition
+	parallelism int
+
+	// results[i] receives the rows of partitions[i], and is closed after
+	// errs[i] is set
+	results []chan []sql.Row
+	errs    []error
+	// next is the next partition to be claimed by a worker
+	next atomic.Int64
+
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	// the partition being returned, and the rest of its current batch
+	cur   int
+	batch []sql.Row
+}
+
+var _ sql.RowIter = (*parallelScanIter)(nil)
+
+func (p *parallelScanIter) Next(ctx *sql.Context) (sql.Row, error) {
+	if !p.started {
+		p.start(ctx)
+	}
+	for {
+		if len(p.batch) > 0 {
+			row := p.batch[0]
+			p.batch = p.batch[1:]
+			return row, nil
+		}
+		if p.cur >= len(p.partitions) {
+			return nil, io.EOF
+		}
+		select {
+		case batch, ok := <-p.results[p.cur]:
+			if !ok {
+				if err := p.errs[p.cur]; err != nil {
+					return nil, err
+				}
+				p.cur++
+				continue
+			}
+			p.batch = batch
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (p *parallelScanIter) Close(_ *sql.Context) error {
+	if p.started {
+		p.cancel()
+		p.wg.Wait()
+	}
+	return nil
+}
+
+// start launches the workers, which stop when the iterator is closed. Each
+// worker reads with its own copy of |ctx|, so that it never shares a
+// *sql.Context with the consumer or the other workers.
+func (p *parallelScanIter) start(ctx *sql.Context) {
+	p.started = true
+	workersCtx, cancel := context.WithCancel(ctx.Context)
+	p.cancel = cancel
+	p.wg.Add(p.parallelism)
+	for w := 0; w < p.parallelism; w++ {
+		workerCtx := ctx.WithContext(workersCtx)
+		go func() {
+			defer p.wg.Done()
+			for {
+				i := int(p.next.Add(1) - 1)
+				if i >= len(p.partitions) {
+					return
+				}
+				err := p.readPartition(workerCtx, i)
+				p.errs[i] = err
+				close(p.results[i])
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// readPartition sends the rows of the |i|th partition to its result
+// channel in batches.
+func (p *parallelScanIter) readPartition(ctx *sql.Context, i int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	rows, err := p.table.PartitionRows(ctx, p.partitions[i])
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := rows.Close(ctx); err == nil {
+			err = cerr
+		}
+	}()
+
+	batch := make([]sql.Row, 0, parallelScanBatchSize)
+	for {
+		row, err := rows.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		batch = append(batch, row)
+		if len(batch) == parallelScanBatchSize {
+			select {
+			case p.results[i] <- batch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			batch = make([]sql.Row, 0, parallelScanBatchSize)
+		}
+	}
+	if len(batch) > 0 {
+		select {
+		case p.results[i] <- batch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
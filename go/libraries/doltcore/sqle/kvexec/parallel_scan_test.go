@@ -0,0 +1,350 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/planbuilder"
+	"github.com/dolthub/go-mysql-server/sql/rowexec"
+	"github.com/dolthub/go-mysql-server/sql/transform"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+)
+
+// scanTestData fills |xy| with 10,000 rows, which span many leaf nodes
+var scanTestData = []string{
+	"create table digits (d int primary key)",
+	"insert into digits values (0), (1), (2), (3), (4), (5), (6), (7), (8), (9)",
+	"insert into xy select a.d*1000 + b.d*100 + c.d*10 + d.d, a.d + b.d + c.d + d.d from digits a, digits b, digits c, digits d",
+}
+
+// TestParallelScan ensures that we trigger the operator replacement for
+// table scans, and that it returns the same rows in the same order as the
+// generic row path.
+func TestParallelScan(t *testing.T) {
+	parallel := Builder{ScanParallelism: 4}
+	tests := []struct {
+		name      string
+		setup     []string
+		query     string
+		builder   Builder
+		doRowexec bool
+	}{
+		{
+			name:      "accept full table scan",
+			setup:     append([]string{"create table xy (x int primary key, y int)"}, scanTestData...),
+			query:     "select * from xy",
+			builder:   parallel,
+			doRowexec: true,
+		},
+		{
+			name:      "accept table scan with projection",
+			setup:     append([]string{"create table xy (x int primary key, y int)"}, scanTestData...),
+			query:     "select y from xy",
+			builder:   parallel,
+			doRowexec: true,
+		},
+		{
+			name:      "accept keyless table scan",
+			setup:     append([]string{"create table xy (x int, y int)"}, scanTestData...),
+			query:     "select * from xy",
+			builder:   parallel,
+			doRowexec: true,
+		},
+		{
+			name:      "accept more workers than partitions",
+			setup:     append([]string{"create table xy (x int primary key, y int)"}, scanTestData...),
+			query:     "select * from xy",
+			builder:   Builder{ScanParallelism: 1024},
+			doRowexec: true,
+		},
+		{
+			name:      "reject default builder",
+			setup:     append([]string{"create table xy (x int primary key, y int)"}, scanTestData...),
+			query:     "select * from xy",
+			builder:   Builder{},
+			doRowexec: false,
+		},
+		{
+			name:      "reject serial scan parallelism",
+			setup:     append([]string{"create table xy (x int primary key, y int)"}, scanTestData...),
+			query:     "select * from xy",
+			builder:   Builder{ScanParallelism: 1},
+			doRowexec: false,
+		},
+		{
+			name: "reject table with one partition",
+			setup: []string{
+				"create table xy (x int primary key, y int)",
+				"insert into xy values (1, 10), (2, 20), (3, 30)",
+			},
+			query:     "select * from xy",
+			builder:   parallel,
+			doRowexec: false,
+		},
+		{
+			name:      "reject table with virtual columns",
+			setup:     append([]string{"create table xy (x int primary key, y int, z int as (x + y) virtual)"}, scanTestData[:2]...),
+			query:     "select * from xy",
+			builder:   parallel,
+			doRowexec: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dEnv := dtestutils.CreateTestEnv()
+			defer dEnv.DoltDB.Close()
+
+			tmpDir, err := dEnv.TempTableFilesDir()
+			require.NoError(t, err)
+
+			opts := editor.Options{Deaf: dEnv.DbEaFactory(), Tempdir: tmpDir}
+			db, err := sqle.NewDatabase(context.Background(), "dolt", dEnv.DbData(), opts)
+			require.NoError(t, err)
+
+			engine, ctx, err := sqle.NewTestEngine(dEnv, context.Background(), db)
+			require.NoError(t, err)
+
+			err = ctx.Session.SetSessionVariable(ctx, sql.AutoCommitSessionVar, false)
+			require.NoError(t, err)
+
+			for _, q := range tt.setup {
+				_, iter, _, err := engine.Query(ctx, q)
+				require.NoError(t, err)
+				_, err = sql.RowIterToRows(ctx, iter)
+				require.NoError(t, err)
+			}
+
+			binder := planbuilder.New(ctx, engine.EngineAnalyzer().Catalog, engine.Parser)
+			node, _, _, qFlags, err := binder.Parse(tt.query, nil, false)
+			require.NoError(t, err)
+			node, err = engine.EngineAnalyzer().Analyze(ctx, node, nil, qFlags)
+			require.NoError(t, err)
+
+			rt := getResolvedTable(node)
+			require.NotNil(t, rt)
+
+			iter, err := tt.builder.Build(ctx, rt, nil)
+			require.NoError(t, err)
+			_, ok := iter.(*parallelScanIter)
+			require.Equalf(t, tt.doRowexec, ok, "expected do row exec: %t", tt.doRowexec)
+			if iter == nil {
+				return
+			}
+
+			rows, err := sql.RowIterToRows(ctx, iter)
+			require.NoError(t, err)
+			expectedIter, err := rowexec.DefaultBuilder.Build(ctx, rt, nil)
+			require.NoError(t, err)
+			expected, err := sql.RowIterToRows(ctx, expectedIter)
+			require.NoError(t, err)
+			require.Equal(t, expected, rows)
+		})
+	}
+}
+
+func getResolvedTable(n sql.Node) sql.Node {
+	var ret sql.Node
+	transform.Inspect(n, func(n sql.Node) bool {
+		if rt, ok := n.(*plan.ResolvedTable); ok && ret == nil {
+			ret = rt
+		}
+		return ret == nil
+	})
+	return ret
+}
+
+// TestParallelScanIter checks the iterator itself over a table whose
+// partitions are read concurrently, independently of the Dolt storage.
+func TestParallelScanIter(t *testing.T) {
+	const numPartitions = 16
+	const rowsPerPartition = 1000
+
+	newIter := func(table *scanTestTable, parallelism int) *parallelScanIter {
+		partitions := make([]sql.Partition, numPartitions)
+		results := make([]chan []sql.Row, numPartitions)
+		for i := range partitions {
+			partitions[i] = &scanTestPartition{i: i}
+			results[i] = make(chan []sql.Row, parallelScanBufferedBatches)
+		}
+		return &parallelScanIter{
+			table:       table,
+			partitions:  partitions,
+			parallelism: parallelism,
+			results:     results,
+			errs:        make([]error, numPartitions),
+		}
+	}
+
+	t.Run("rows in partition order", func(t *testing.T) {
+		ctx := sql.NewEmptyContext()
+		table := &scanTestTable{rowsPerPartition: rowsPerPartition}
+		rows, err := sql.RowIterToRows(ctx, newIter(table, 4))
+		require.NoError(t, err)
+		require.Len(t, rows, numPartitions*rowsPerPartition)
+		for i, r := range rows {
+			require.Equal(t, sql.Row{i}, r)
+		}
+	})
+
+	t.Run("each worker has its own context", func(t *testing.T) {
+		ctx := sql.NewEmptyContext()
+		table := &scanTestTable{rowsPerPartition: rowsPerPartition}
+		// each worker reads one of the first partitions
+		table.startReads = 4
+		table.start.Add(4)
+		_, err := sql.RowIterToRows(ctx, newIter(table, 4))
+		require.NoError(t, err)
+		table.mu.Lock()
+		defer table.mu.Unlock()
+		require.Len(t, table.ctxs, 4)
+		for _, c := range table.ctxs {
+			assert.True(t, c != ctx, "a worker read with the context of the consumer")
+		}
+	})
+
+	t.Run("error of a partition", func(t *testing.T) {
+		ctx := sql.NewEmptyContext()
+		table := &scanTestTable{rowsPerPartition: rowsPerPartition, failPartition: 5}
+		iter := newIter(table, 4)
+		var n int
+		var err error
+		for {
+			_, err = iter.Next(ctx)
+			if err != nil {
+				break
+			}
+			n++
+		}
+		assert.EqualError(t, err, "partition 5 failed")
+		// the rows before the failed partition are returned
+		assert.Equal(t, 5*rowsPerPartition, n)
+		require.NoError(t, iter.Close(ctx))
+	})
+
+	t.Run("close before the end", func(t *testing.T) {
+		ctx := sql.NewEmptyContext()
+		table := &scanTestTable{rowsPerPartition: rowsPerPartition}
+		iter := newIter(table, 4)
+		for i := 0; i < 10; i++ {
+			_, err := iter.Next(ctx)
+			require.NoError(t, err)
+		}
+		// the workers blocked on sending rows stop
+		require.NoError(t, iter.Close(ctx))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := sql.NewEmptyContext().NewSubContext()
+		table := &scanTestTable{rowsPerPartition: rowsPerPartition}
+		iter := newIter(table, 4)
+		_, err := iter.Next(ctx)
+		require.NoError(t, err)
+		cancel()
+		for err == nil {
+			_, err = iter.Next(ctx)
+		}
+		assert.ErrorIs(t, err, context.Canceled)
+		require.NoError(t, iter.Close(ctx))
+	})
+}
+
+// scanTestTable is a table whose |i|th partition holds the rows from
+// i*rowsPerPartition to (i+1)*rowsPerPartition-1. It records the contexts
+// its partitions are read with.
+type scanTestTable struct {
+	rowsPerPartition int
+	// the partition whose rows fail to be read, if positive
+	failPartition int
+
+	// the first |startReads| reads wait for each other with |start|
+	startReads int
+	start      sync.WaitGroup
+
+	mu    sync.Mutex
+	ctxs  []*sql.Context
+	reads int
+}
+
+var _ sql.Table = (*scanTestTable)(nil)
+
+func (t *scanTestTable) Name() string {
+	return "scan_test"
+}
+
+func (t *scanTestTable) String() string {
+	return t.Name()
+}
+
+func (t *scanTestTable) Schema() sql.Schema {
+	return sql.Schema{{Name: "i", Type: types.Int64, Source: t.Name()}}
+}
+
+func (t *scanTestTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+func (t *scanTestTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return nil, errors.New("unused")
+}
+
+func (t *scanTestTable) PartitionRows(ctx *sql.Context, p sql.Partition) (sql.RowIter, error) {
+	t.mu.Lock()
+	found := false
+	for _, c := range t.ctxs {
+		found = found || c == ctx
+	}
+	if !found {
+		t.ctxs = append(t.ctxs, ctx)
+	}
+	wait := t.reads < t.startReads
+	t.reads++
+	t.mu.Unlock()
+	if wait {
+		t.start.Done()
+		t.start.Wait()
+	}
+
+	i := p.(*scanTestPartition).i
+	if t.failPartition > 0 && i == t.failPartition {
+		return nil, fmt.Errorf("partition %d failed", i)
+	}
+	rows := make([]sql.Row, t.rowsPerPartition)
+	for j := range rows {
+		rows[j] = sql.Row{i*t.rowsPerPartition + j}
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+type scanTestPartition struct {
+	i int
+}
+
+func (p *scanTestPartition) Key() []byte {
+	return []byte{byte(p.i)}
+}
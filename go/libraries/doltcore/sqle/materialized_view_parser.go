@@ -0,0 +1,226 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	ast "github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// MaterializedViewParser is a sql.Parser that parses CREATE MATERIALIZED VIEW, REFRESH MATERIALIZED VIEW and DROP
+// MATERIALIZED VIEW statements, which the MySQL dialect doesn't have, as calls of the dolt_materialized_view()
+// stored procedure. Every other statement is parsed by the wrapped parser.
+type MaterializedViewParser struct {
+	sql.Parser
+}
+
+var _ sql.Parser = MaterializedViewParser{}
+
+// NewMaterializedViewParser returns a MaterializedViewParser wrapping the given parser.
+func NewMaterializedViewParser(parser sql.Parser) MaterializedViewParser {
+	return MaterializedViewParser{Parser: parser}
+}
+
+// ParseSimple implements the sql.Parser interface.
+func (p MaterializedViewParser) ParseSimple(query string) (ast.Statement, error) {
+	call, n, err := p.parseMaterializedView(context.Background(), query, ast.ParserOptions{})
+	if err != nil {
+		return nil, err
+	} else if call == nil || strings.TrimSpace(query[n:]) != "" {
+		return p.Parser.ParseSimple(query)
+	}
+	return call, nil
+}
+
+// Parse implements the sql.Parser interface.
+func (p MaterializedViewParser) Parse(ctx *sql.Context, query string, multi bool) (ast.Statement, string, string, error) {
+	return p.ParseWithOptions(ctx, query, ';', multi, sql.LoadSqlMode(ctx).ParserOptions())
+}
+
+// ParseWithOptions implements the sql.Parser interface.
+func (p MaterializedViewParser) ParseWithOptions(ctx context.Context, query string, delimiter rune, multi bool, options ast.ParserOptions) (ast.Statement, string, string, error) {
+	s := sql.RemoveSpaceAndDelimiter(query, delimiter)
+	call, n, err := p.parseMaterializedView(ctx, s, options)
+	if err != nil {
+		return nil, "", "", err
+	} else if call == nil || (!multi && strings.TrimSpace(s[n:]) != "") {
+		return p.Parser.ParseWithOptions(ctx, query, delimiter, multi, options)
+	} else if !multi {
+		return call, s, "", nil
+	}
+	return call, sql.RemoveSpaceAndDelimiter(s[:n], delimiter), s[n:], nil
+}
+
+// ParseOneWithOptions implements the sql.Parser interface.
+func (p MaterializedViewParser) ParseOneWithOptions(ctx context.Context, query string, options ast.ParserOptions) (ast.Statement, int, error) {
+	call, n, err := p.parseMaterializedView(ctx, query, options)
+	if err != nil {
+		return nil, 0, err
+	} else if call == nil {
+		return p.Parser.ParseOneWithOptions(ctx, query, options)
+	}
+	return call, n, nil
+}
+
+// ParseMaterializedViewStatement returns the call of dolt_materialized_view() equivalent to |query| if it's a
+// CREATE, REFRESH or DROP MATERIALIZED VIEW statement. Clients that parse statements before sending them to the
+// engine must send the call in its place.
+func ParseMaterializedViewStatement(ctx context.Context, query string, options ast.ParserOptions) (*ast.Call, bool, error) {
+	call, _, err := NewMaterializedViewParser(sql.NewMysqlParser()).parseMaterializedView(ctx, query, options)
+	return call, call != nil, err
+}
+
+// parseMaterializedView parses the MATERIALIZED VIEW statement at the start of |query|, which may be followed by
+// other statements, as a call of dolt_materialized_view(). It returns the call and the index of the end of the
+// statement, or a nil call if |query| doesn't start with a well formed MATERIALIZED VIEW statement.
+func (p MaterializedViewParser) parseMaterializedView(ctx context.Context, query string, options ast.ParserOptions) (*ast.Call, int, error) {
+	t := matViewTokenizer{Tokenizer: ast.NewStringTokenizer(query)}
+	var params []ast.Expr
+	switch typ, val := t.next(); {
+	case typ == ast.CREATE:
+		if !t.nextIsWord("materialized") || !t.nextIs(ast.VIEW) {
+			return nil, 0, nil
+		}
+		name, ok := t.nextName()
+		if !ok {
+			return nil, 0, nil
+		}
+		params = append(params, matViewParam("create"))
+		typ, val = t.next()
+		if isMatViewWord(typ, val, "refresh") {
+			if !t.nextIs(ast.ON) || !t.nextIs(ast.COMMIT) {
+				return nil, 0, nil
+			}
+			params = append(params, matViewParam("--refresh-on-commit"))
+			typ, _ = t.next()
+		}
+		if typ != ast.AS {
+			return nil, 0, nil
+		}
+
+		// The end of the query of the view is found by parsing it
+		start := t.end()
+		_, n, err := p.Parser.ParseOneWithOptions(ctx, query[start:], options)
+		if err != nil {
+			return nil, 0, err
+		}
+		if n == 0 {
+			n = len(query) - start
+		}
+		viewQuery := sql.RemoveSpaceAndDelimiter(query[start:start+n], ';')
+		params = append(params, matViewParam(name), matViewParam(viewQuery))
+		return materializedViewCall(params), start + n, nil
+
+	case isMatViewWord(typ, val, "refresh"):
+		if !t.nextIsWord("materialized") {
+			return nil, 0, nil
+		}
+		if typ, val = t.next(); typ != ast.VIEW && !isMatViewWord(typ, val, "views") {
+			return nil, 0, nil
+		}
+		params = append(params, matViewParam("refresh"))
+		for {
+			name, ok := t.nextName()
+			if !ok {
+				return nil, 0, nil
+			}
+			params = append(params, matViewParam(name))
+			if typ, _ = t.next(); typ != ',' {
+				break
+			}
+		}
+		if typ != ';' && typ != 0 {
+			return nil, 0, nil
+		}
+		return materializedViewCall(params), t.end(), nil
+
+	case typ == ast.DROP:
+		if !t.nextIsWord("materialized") || !t.nextIs(ast.VIEW) {
+			return nil, 0, nil
+		}
+		params = append(params, matViewParam("drop"))
+		typ, val = t.next()
+		if typ == ast.IF {
+			if !t.nextIs(ast.EXISTS) {
+				return nil, 0, nil
+			}
+			params = append(params, matViewParam("--if-exists"))
+			typ, val = t.next()
+		}
+		if typ != ast.ID {
+			return nil, 0, nil
+		}
+		params = append(params, matViewParam(val))
+		if typ, _ = t.next(); typ != ';' && typ != 0 {
+			return nil, 0, nil
+		}
+		return materializedViewCall(params), t.end(), nil
+	}
+	return nil, 0, nil
+}
+
+func materializedViewCall(params []ast.Expr) *ast.Call {
+	return &ast.Call{
+		ProcName: ast.ProcedureName{Name: ast.NewColIdent("dolt_materialized_view")},
+		Params:   params,
+	}
+}
+
+func matViewParam(s string) ast.Expr {
+	return ast.NewStrVal([]byte(s))
+}
+
+// matViewTokenizer reads the tokens of a MATERIALIZED VIEW statement, skipping comments.
+type matViewTokenizer struct {
+	*ast.Tokenizer
+}
+
+func (t matViewTokenizer) next() (int, string) {
+	for {
+		typ, val := t.Scan()
+		if typ != ast.COMMENT {
+			return typ, string(val)
+		}
+	}
+}
+
+// end returns the index of the end of the last token read.
+func (t matViewTokenizer) end() int {
+	// the tokenizer reads one character past the end of a token
+	return t.Position - 1
+}
+
+func (t matViewTokenizer) nextIs(typ int) bool {
+	next, _ := t.next()
+	return next == typ
+}
+
+func (t matViewTokenizer) nextIsWord(word string) bool {
+	typ, val := t.next()
+	return isMatViewWord(typ, val, word)
+}
+
+func (t matViewTokenizer) nextName() (string, bool) {
+	typ, val := t.next()
+	return val, typ == ast.ID
+}
+
+// isMatViewWord returns whether a token is |word|, which isn't a keyword of the MySQL dialect.
+func isMatViewWord(typ int, val string, word string) bool {
+	return typ == ast.ID && strings.EqualFold(val, word)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/utils/filesys"
 	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
 )
 
 type remotesrvStore struct {
@@ -65,7 +66,31 @@ func (s remotesrvStore) Get(ctx context.Context, path, _ string) (remotesrv.Remo
 	if !ok {
 		return nil, remotesrv.ErrUnimplemented
 	}
-	return rss, nil
+	if s.createDBs {
+		// Cluster replication writes the roots of the primary, which validated the changes to its branches itself
+		return rss, nil
+	}
+	return protectedBranchesStore{rss, s.ctxFactory, path, sdb.DbData().Ddb}, nil
+}
+
+// protectedBranchesStore is a remotesrv.RemoteSrvStore that validates the changes that a push makes to the branches of
+// its database against the rules of dolt_branch_protection, before committing the new root.
+type protectedBranchesStore struct {
+	remotesrv.RemoteSrvStore
+	ctxFactory func(context.Context) (*sql.Context, error)
+	dbName     string
+	ddb        *doltdb.DoltDB
+}
+
+func (s protectedBranchesStore) Commit(ctx context.Context, current, last hash.Hash) (bool, error) {
+	sqlCtx, err := s.ctxFactory(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err = dsess.ValidateRootUpdate(sqlCtx, s.ddb, s.dbName, last, current); err != nil {
+		return false, err
+	}
+	return s.RemoteSrvStore.Commit(ctx, current, last)
 }
 
 // In the SQL context, the database provider that we use to expose the
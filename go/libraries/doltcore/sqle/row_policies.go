@@ -0,0 +1,155 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+)
+
+// rowPolicyFilter returns the filter enforcing the dolt_row_policies of this table for the current user, or nil if
+// the table has no row policies. Policies are always read from the working root of the database's default branch, so
+// that revision databases and tables locked to a historical root, such as for AS OF queries and the dolt_history
+// tables, enforce current policies.
+func (t *DoltTable) rowPolicyFilter(ctx *sql.Context) (*dsess.RowPolicyFilter, error) {
+	policyRoot, err := dsess.RowPoliciesRoot(ctx, t.db)
+	if err != nil {
+		return nil, err
+	}
+	dataRoot, err := t.workingRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dsess.GetRowPolicyFilter(ctx, policyRoot, dataRoot, t.TableName())
+}
+
+// HasRowPolicies returns whether the rows of this table are filtered by dolt_row_policies for the current user.
+// Operations that read the table's storage directly, rather than through its row iterators, must not be used for
+// such tables.
+func (t *DoltTable) HasRowPolicies(ctx *sql.Context) (bool, error) {
+	filter, err := t.rowPolicyFilter(ctx)
+	return filter != nil, err
+}
+
+// rowPolicyProjection returns the indexes of the projected columns of this table in a row containing all of its
+// columns, or nil if all columns are projected.
+func (t *DoltTable) rowPolicyProjection() []int {
+	if t.projectedCols == nil {
+		return nil
+	}
+	allCols := t.sch.GetAllCols()
+	projection := make([]int, len(t.projectedCols))
+	for i, tag := range t.projectedCols {
+		projection[i] = allCols.TagToIdx[tag]
+	}
+	return projection
+}
+
+// rowPolicyIndexedRows returns the rows of |part|, read through the index |idx|, that are allowed by |filter|. All
+// columns of the table are read so that the filter can be evaluated, and the rows are then projected to the
+// projected columns of the table.
+func (t *DoltTable) rowPolicyIndexedRows(ctx *sql.Context, filter *dsess.RowPolicyFilter, idx index.DoltIndex, key doltdb.DataCacheKey, isDoltFormat bool, part sql.Partition) (sql.RowIter, error) {
+	lb, err := index.NewIndexReaderBuilder(ctx, t, idx, key, t.sch.GetAllCols().Tags, t.sqlSch, isDoltFormat)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := lb.NewPartitionRowIter(ctx, part)
+	if err != nil {
+		return nil, err
+	}
+	return dsess.NewRowPolicyIter(filter, iter, t.rowPolicyProjection()), nil
+}
+
+// withRowPolicies returns |te|, wrapped to enforce the row policies of this table for the current user if it has any.
+func (t *WritableDoltTable) withRowPolicies(ctx *sql.Context, te dsess.TableWriter) dsess.TableWriter {
+	filter, err := t.rowPolicyFilter(ctx)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+	if filter == nil {
+		return te
+	}
+	return &rowPolicyWriter{TableWriter: te, filter: filter}
+}
+
+// deleteVisibleRows deletes the rows of this table that are visible to the current user, and returns the number of
+// rows deleted. It's used in place of truncating a table that has row policies.
+func (t *WritableDoltTable) deleteVisibleRows(ctx *sql.Context) (int, error) {
+	fullTable := t.DoltTable.WithProjections(nil).(*DoltTable)
+	partitions, err := fullTable.Partitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := sql.RowIterToRows(ctx, sql.NewTableRowIter(ctx, fullTable, partitions))
+	if err != nil {
+		return 0, err
+	}
+
+	deleter := t.Deleter(ctx)
+	deleter.StatementBegin(ctx)
+	for _, r := range rows {
+		if err = deleter.Delete(ctx, r); err != nil {
+			_ = deleter.DiscardChanges(ctx, err)
+			_ = deleter.Close(ctx)
+			return 0, err
+		}
+	}
+	if err = deleter.StatementComplete(ctx); err != nil {
+		_ = deleter.Close(ctx)
+		return 0, err
+	}
+	return len(rows), deleter.Close(ctx)
+}
+
+// rowPolicyWriter is a dsess.TableWriter that enforces the row policies of its table. Inserted rows and both the old
+// and new values of updated rows must be allowed by the policies, as must deleted rows, which prevents writes from
+// replacing rows that aren't visible to the user.
+type rowPolicyWriter struct {
+	dsess.TableWriter
+	filter *dsess.RowPolicyFilter
+}
+
+var _ dsess.TableWriter = (*rowPolicyWriter)(nil)
+
+// Insert implements sql.RowInserter
+func (w *rowPolicyWriter) Insert(ctx *sql.Context, row sql.Row) error {
+	if err := w.filter.CheckWrite(ctx, row); err != nil {
+		return err
+	}
+	return w.TableWriter.Insert(ctx, row)
+}
+
+// Update implements sql.RowUpdater
+func (w *rowPolicyWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := w.filter.CheckWrite(ctx, old); err != nil {
+		return err
+	}
+	if err := w.filter.CheckWrite(ctx, new); err != nil {
+		return err
+	}
+	return w.TableWriter.Update(ctx, old, new)
+}
+
+// Delete implements sql.RowDeleter
+func (w *rowPolicyWriter) Delete(ctx *sql.Context, row sql.Row) error {
+	if err := w.filter.CheckWrite(ctx, row); err != nil {
+		return err
+	}
+	return w.TableWriter.Delete(ctx, row)
+}
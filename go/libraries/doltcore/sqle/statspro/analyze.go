@@ -138,6 +138,10 @@ func (p *Provider) RefreshTableStatsWithBranch(ctx *sql.Context, table sql.Table
 	tablePrefix := fmt.Sprintf("%s.", tableName)
 	var idxMetas []indexMeta
 	for _, idx := range indexes {
+		if idx.IsGenerated() {
+			// generated indexes, like vector indexes, have no index data of their own to collect statistics from
+			continue
+		}
 		cols := make([]string, len(idx.Expressions()))
 		for i, c := range idx.Expressions() {
 			cols[i] = strings.TrimPrefix(strings.ToLower(c), tablePrefix)
@@ -171,6 +171,10 @@ func (p *Provider) checkRefresh(ctx *sql.Context, sqlDb sql.Database, dbName, br
 		// collect indexes and ranges to be updated
 		var idxMetas []indexMeta
 		for _, index := range indexes {
+			if index.IsGenerated() {
+				// generated indexes, like vector indexes, have no index data of their own to collect statistics from
+				continue
+			}
 			qual := sql.NewStatQualifier(dbName, table, strings.ToLower(index.ID()))
 			qualExists[qual] = true
 			curStat, ok := statDb.GetStat(branch, qual)
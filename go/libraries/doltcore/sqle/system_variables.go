@@ -204,6 +204,13 @@ var DoltSystemVariables = []sql.SystemVariable{
 		Type:    types.NewSystemBoolType(dsess.ShowSystemTables),
 		Default: int8(0),
 	},
+	&sql.MysqlSystemVariable{
+		Name:    dsess.RowLineageSimilarity,
+		Dynamic: true,
+		Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Both),
+		Type:    types.NewSystemDoubleType(dsess.RowLineageSimilarity, 0, 1),
+		Default: float64(.75),
+	},
 	&sql.MysqlSystemVariable{
 		Name:    "dolt_dont_merge_json",
 		Dynamic: true,
@@ -253,6 +260,13 @@ var DoltSystemVariables = []sql.SystemVariable{
 		Type:    types.NewSystemStringType(dsess.DoltStatsBranches),
 		Default: "",
 	},
+	&sql.MysqlSystemVariable{
+		Name:    dsess.DoltBranchPruneInterval,
+		Dynamic: true,
+		Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Global),
+		Type:    types.NewSystemIntType(dsess.DoltBranchPruneInterval, 0, math.MaxInt, false),
+		Default: 3600,
+	},
 }
 
 func AddDoltSystemVariables() {
@@ -480,6 +494,13 @@ func AddDoltSystemVariables() {
 			Type:    types.NewSystemStringType(dsess.DoltStatsBranches),
 			Default: "",
 		},
+		&sql.MysqlSystemVariable{
+			Name:    dsess.DoltBranchPruneInterval,
+			Dynamic: true,
+			Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Global),
+			Type:    types.NewSystemIntType(dsess.DoltBranchPruneInterval, 0, math.MaxInt, false),
+			Default: 3600,
+		},
 		&sql.MysqlSystemVariable{
 			Name:    "signingkey",
 			Dynamic: true,
@@ -43,6 +43,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/vectorindex"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/writer"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor/creation"
@@ -299,6 +300,9 @@ var _ sql.IndexSearchable = (*DoltTable)(nil)
 
 // IndexedAccess implements sql.IndexAddressableTable
 func (t *DoltTable) IndexedAccess(lookup sql.IndexLookup) sql.IndexedTable {
+	if idx, ok := lookup.Index.(*vectorIndex); ok {
+		return &vectorIndexedTable{DoltTable: t, idx: idx}
+	}
 	return NewIndexedDoltTable(t, lookup.Index.(index.DoltIndex))
 }
 
@@ -378,7 +382,7 @@ func (t *DoltTable) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 		return nil, nil
 	}
 
-	key, tableIsCacheable, err := t.IndexCacheKey(ctx)
+	key, tableIsCacheable, err := t.indexesCacheKey(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -388,7 +392,11 @@ func (t *DoltTable) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 		if err != nil {
 			return nil, err
 		}
-		return index.DoltIndexesFromTable(ctx, t.db.Name(), t.tableName, tbl)
+		indexes, err := index.DoltIndexesFromTable(ctx, t.db.Name(), t.tableName, tbl)
+		if err != nil {
+			return nil, err
+		}
+		return t.withVectorIndexes(ctx, indexes)
 	}
 
 	sess := dsess.DSessFromSess(ctx.Session)
@@ -415,17 +423,63 @@ func (t *DoltTable) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 	if err != nil {
 		return nil, err
 	}
+	indexes, err = t.withVectorIndexes(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
 
 	dbState.SessionCache().CacheTableIndexes(key, t.Name(), indexes)
 	return indexes, nil
 }
 
+// indexesCacheKey returns the key of the indexes of this table in the session cache, and whether they can be cached.
+// Besides the schema of this table, the key covers the vector index definitions, since the vector indexes of a table
+// are declared outside its schema.
+func (t *DoltTable) indexesCacheKey(ctx *sql.Context) (doltdb.DataCacheKey, bool, error) {
+	key, tableIsCacheable, err := t.IndexCacheKey(ctx)
+	if err != nil || !tableIsCacheable || !types.IsFormat_DOLT(t.nbf) {
+		return key, tableIsCacheable, err
+	}
+
+	root, err := t.workingRoot(ctx)
+	if err != nil {
+		return doltdb.DataCacheKey{}, false, err
+	}
+	defsHash, err := vectorindex.DefinitionsHash(ctx, root)
+	if err != nil {
+		return doltdb.DataCacheKey{}, false, err
+	}
+	if defsHash.IsEmpty() {
+		return key, true, nil
+	}
+	return doltdb.DataCacheKey{Hash: hash.Of(append(key.Hash[:], defsHash[:]...))}, true, nil
+}
+
+// withVectorIndexes returns |indexes| followed by the vector indexes of this table.
+func (t *DoltTable) withVectorIndexes(ctx *sql.Context, indexes []sql.Index) ([]sql.Index, error) {
+	root, err := t.workingRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vecIndexes, err := t.vectorIndexes(ctx, root)
+	if err != nil || len(vecIndexes) == 0 {
+		return indexes, err
+	}
+	ret := make([]sql.Index, 0, len(indexes)+len(vecIndexes))
+	ret = append(ret, indexes...)
+	return append(ret, vecIndexes...), nil
+}
+
 func (t *DoltTable) PreciseMatch() bool {
 	return true
 }
 
 // HasIndex returns whether the given index is present in the table
 func (t *DoltTable) HasIndex(ctx *sql.Context, idx sql.Index) (bool, error) {
+	if vecIdx, ok := idx.(*vectorIndex); ok {
+		return t.hasVectorIndex(ctx, vecIdx)
+	}
+
 	tbl, err := t.DoltTable(ctx)
 	if err != nil {
 		return false, err
@@ -578,7 +632,12 @@ func (t *DoltTable) DataLength(ctx *sql.Context) (uint64, error) {
 // RowCount implements the sql.StatisticsTable interface.
 func (t *DoltTable) RowCount(ctx *sql.Context) (uint64, bool, error) {
 	rows, err := t.numRows(ctx)
-	return rows, true, err
+	if err != nil {
+		return 0, false, err
+	}
+	// the count includes rows hidden by row policies, so it's only an estimate for such tables
+	hasPolicies, err := t.HasRowPolicies(ctx)
+	return rows, !hasPolicies, err
 }
 
 func (t *DoltTable) PrimaryKeySchema() sql.PrimaryKeySchema {
@@ -609,8 +668,15 @@ func (t *DoltTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sq
 	// to pass in the full column projection for the original/data schema so that we get all columns back. Then,
 	// the mappingRowIterator that we apply on top of the original row iterator will take care of mapping the
 	// original row and shrinking it down to the projected columns.
+	//
+	// Similarly, row policies are evaluated against rows with every column, so they're projected after filtering.
+	filter, err := t.rowPolicyFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	projCols := t.projectedCols
-	if t.overriddenSchema != nil {
+	if t.overriddenSchema != nil || filter != nil {
 		originalSchemaCols := t.sch.GetAllCols().GetColumns()
 		projCols = make([]uint64, len(originalSchemaCols))
 		for i, col := range originalSchemaCols {
@@ -623,6 +689,14 @@ func (t *DoltTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sq
 		return originalRowIter, err
 	}
 
+	if filter != nil {
+		var projection []int
+		if t.overriddenSchema == nil {
+			projection = t.rowPolicyProjection()
+		}
+		originalRowIter = dsess.NewRowPolicyIter(filter, originalRowIter, projection)
+	}
+
 	if t.overriddenSchema != nil {
 		return newMappingRowIter(ctx, t, originalRowIter)
 	} else {
@@ -679,6 +753,9 @@ func (t *WritableDoltTable) setRoot(ctx *sql.Context, newRoot doltdb.RootValue)
 }
 
 func (t *WritableDoltTable) IndexedAccess(lookup sql.IndexLookup) sql.IndexedTable {
+	if idx, ok := lookup.Index.(*vectorIndex); ok {
+		return &writableVectorIndexedTable{WritableDoltTable: t, idx: idx}
+	}
 	return NewWritableIndexedDoltTable(t, lookup.Index.(index.DoltIndex))
 }
 
@@ -699,7 +776,7 @@ func (t *WritableDoltTable) Inserter(ctx *sql.Context) sql.RowInserter {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
-	return te
+	return t.withRowPolicies(ctx, te)
 }
 
 func (t *WritableDoltTable) getTableEditor(ctx *sql.Context) (ed dsess.TableWriter, err error) {
@@ -722,6 +799,10 @@ func (t *WritableDoltTable) getTableEditor(ctx *sql.Context) (ed dsess.TableWrit
 	if err != nil {
 		return nil, err
 	}
+	ed, err = t.withVectorIndexWriters(ctx, writeSession, setter, ed)
+	if err != nil {
+		return nil, err
+	}
 
 	if t.sch.Indexes().ContainsFullTextIndex() {
 		ftEditor, err := t.getFullTextEditor(ctx)
@@ -959,7 +1040,7 @@ func (t *WritableDoltTable) Deleter(ctx *sql.Context) sql.RowDeleter {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
-	return te
+	return t.withRowPolicies(ctx, te)
 }
 
 // Replacer implements sql.ReplaceableTable
@@ -971,7 +1052,7 @@ func (t *WritableDoltTable) Replacer(ctx *sql.Context) sql.RowReplacer {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
-	return te
+	return t.withRowPolicies(ctx, te)
 }
 
 // Truncate implements sql.TruncateableTable
@@ -979,6 +1060,12 @@ func (t *WritableDoltTable) Truncate(ctx *sql.Context) (int, error) {
 	if err := dsess.CheckAccessForDb(ctx, t.db, branch_control.Permissions_Write); err != nil {
 		return 0, err
 	}
+	if hasPolicies, err := t.HasRowPolicies(ctx); err != nil {
+		return 0, err
+	} else if hasPolicies {
+		// only the rows visible to the user may be deleted
+		return t.deleteVisibleRows(ctx)
+	}
 	table, err := t.DoltTable.DoltTable(ctx)
 	if err != nil {
 		return 0, err
@@ -1013,6 +1100,10 @@ func (t *WritableDoltTable) Truncate(ctx *sql.Context) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	newRoot, err = t.rebuildVectorIndexes(ctx, newRoot)
+	if err != nil {
+		return 0, err
+	}
 
 	err = t.setRoot(ctx, newRoot)
 	if err != nil {
@@ -1125,7 +1216,7 @@ func (t *WritableDoltTable) Updater(ctx *sql.Context) sql.RowUpdater {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
-	return te
+	return t.withRowPolicies(ctx, te)
 }
 
 // AutoIncrementSetter implements sql.AutoIncrementTable
@@ -1234,7 +1325,7 @@ func (t *WritableDoltTable) GetForeignKeyEditor(ctx *sql.Context) sql.ForeignKey
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
-	return te
+	return t.withRowPolicies(ctx, te)
 }
 
 // GetDeclaredForeignKeys implements sql.ForeignKeyTable
@@ -1483,9 +1574,59 @@ func partitionsFromRows(ctx context.Context, rows durable.Index) ([]doltTablePar
 		}, nil
 	}
 
+	if types.IsFormat_DOLT(rows.Format()) {
+		return partitionsFromProllyRows(ctx, rows)
+	}
 	return partitionsFromTableRows(rows)
 }
 
+// partitionsFromProllyRows splits |rows| into key ranges of similar sizes
+// along the split keys of its internal nodes, so that the partitions can be
+// read concurrently. The ranges are returned in key order as ordinal ranges.
+// As for prolly.Map.IterKeyRange, each split key is the exclusive end of one
+// partition and the inclusive start of the next.
+func partitionsFromProllyRows(ctx context.Context, rows durable.Index) ([]doltTablePartition, error) {
+	numElements, err := rows.Count()
+	if err != nil {
+		return nil, err
+	}
+	numPartitions := (numElements / MaxRowsPerPartition) + 1
+	if numPartitions < uint64(partitionMultiplier*runtime.NumCPU()) {
+		numPartitions = uint64(partitionMultiplier * runtime.NumCPU())
+	}
+	if maxPartitions := numElements / MinRowsPerPartition; numPartitions > maxPartitions {
+		numPartitions = max(maxPartitions, 1)
+	}
+
+	m := durable.ProllyMapFromIndex(rows)
+	keys, err := m.SplitKeys(ctx, int(numPartitions))
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]doltTablePartition, 0, len(keys)+1)
+	var start uint64
+	for _, k := range keys {
+		end, err := m.GetOrdinalForKey(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, doltTablePartition{
+			start:   start,
+			end:     end,
+			rowData: rows,
+		})
+		start = end
+	}
+	partitions = append(partitions, doltTablePartition{
+		start:   start,
+		end:     numElements,
+		rowData: rows,
+	})
+
+	return partitions, nil
+}
+
 func partitionsFromTableRows(rows durable.Index) ([]doltTablePartition, error) {
 	numElements, err := rows.Count()
 	if err != nil {
@@ -1721,6 +1862,10 @@ func (t *AlterableDoltTable) RewriteInserter(
 	if err != nil {
 		return nil, err
 	}
+	err = t.checkVectorIndexedColumns(ctx, oldSchema, newSchema, oldColumn, newColumn)
+	if err != nil {
+		return nil, err
+	}
 
 	sess := dsess.DSessFromSess(ctx.Session)
 
@@ -2305,6 +2450,15 @@ func (t *AlterableDoltTable) ModifyColumn(ctx *sql.Context, columnName string, c
 		return err
 	}
 
+	if colIdx := t.sqlSch.Schema.IndexOfColName(existingCol.Name); colIdx >= 0 {
+		newSchema := sql.PrimaryKeySchema{Schema: t.sqlSch.Schema.Copy(), PkOrdinals: t.sqlSch.PkOrdinals}
+		newSchema.Schema[colIdx] = column
+		err = t.checkVectorIndexedColumns(ctx, t.sqlSch, newSchema, t.sqlSch.Schema[colIdx], column)
+		if err != nil {
+			return err
+		}
+	}
+
 	// TODO: move this logic into ShouldRewrite
 	if !existingCol.TypeInfo.Equals(col.TypeInfo) {
 		if existingCol.Kind != col.Kind {
@@ -2468,6 +2622,9 @@ func (t *AlterableDoltTable) DropIndex(ctx *sql.Context, indexName string) error
 	if strings.HasPrefix(indexName, "dolt_") {
 		return fmt.Errorf("dolt internal indexes may not be dropped")
 	}
+	if dropped, err := t.dropVectorIndex(ctx, indexName); err != nil || dropped {
+		return err
+	}
 	newTable, _, err := t.dropIndex(ctx, indexName)
 	if err != nil {
 		return err
@@ -0,0 +1,108 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	ast "github.com/dolthub/vitess/go/vt/sqlparser"
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var ErrVectorIndexColumns = errors.NewKind("vector index '%s' must index exactly one column")
+
+// VectorIndexParser is a sql.Parser that parses CREATE VECTOR INDEX and ALTER TABLE ... ADD VECTOR INDEX statements,
+// which the engine doesn't support, as calls of the dolt_create_vector_index() stored procedure. Every other
+// statement is left as parsed by the wrapped parser.
+type VectorIndexParser struct {
+	sql.Parser
+}
+
+var _ sql.Parser = VectorIndexParser{}
+
+// NewVectorIndexParser returns a VectorIndexParser wrapping the given parser.
+func NewVectorIndexParser(parser sql.Parser) VectorIndexParser {
+	return VectorIndexParser{Parser: parser}
+}
+
+// ParseSimple implements the sql.Parser interface.
+func (p VectorIndexParser) ParseSimple(query string) (ast.Statement, error) {
+	stmt, err := p.Parser.ParseSimple(query)
+	if err != nil {
+		return nil, err
+	}
+	return RewriteVectorIndexStatement(stmt)
+}
+
+// Parse implements the sql.Parser interface.
+func (p VectorIndexParser) Parse(ctx *sql.Context, query string, multi bool) (ast.Statement, string, string, error) {
+	stmt, parsed, remainder, err := p.Parser.Parse(ctx, query, multi)
+	if err != nil {
+		return nil, "", "", err
+	}
+	stmt, err = RewriteVectorIndexStatement(stmt)
+	return stmt, parsed, remainder, err
+}
+
+// ParseWithOptions implements the sql.Parser interface.
+func (p VectorIndexParser) ParseWithOptions(ctx context.Context, query string, delimiter rune, multi bool, options ast.ParserOptions) (ast.Statement, string, string, error) {
+	stmt, parsed, remainder, err := p.Parser.ParseWithOptions(ctx, query, delimiter, multi, options)
+	if err != nil {
+		return nil, "", "", err
+	}
+	stmt, err = RewriteVectorIndexStatement(stmt)
+	return stmt, parsed, remainder, err
+}
+
+// ParseOneWithOptions implements the sql.Parser interface.
+func (p VectorIndexParser) ParseOneWithOptions(ctx context.Context, query string, options ast.ParserOptions) (ast.Statement, int, error) {
+	stmt, ri, err := p.Parser.ParseOneWithOptions(ctx, query, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	stmt, err = RewriteVectorIndexStatement(stmt)
+	return stmt, ri, err
+}
+
+// RewriteVectorIndexStatement returns the call of dolt_create_vector_index() equivalent to |stmt| if it creates a
+// vector index, and |stmt| itself otherwise. Statements parsed without a VectorIndexParser must be rewritten with it
+// before they're given to the engine.
+func RewriteVectorIndexStatement(stmt ast.Statement) (ast.Statement, error) {
+	alter, ok := stmt.(*ast.AlterTable)
+	if !ok || len(alter.Statements) != 1 {
+		return stmt, nil
+	}
+	spec := alter.Statements[0].IndexSpec
+	if spec == nil || !strings.EqualFold(spec.Action, ast.CreateStr) || !strings.EqualFold(spec.Type, ast.VectorStr) {
+		return stmt, nil
+	}
+	if len(spec.Columns) != 1 {
+		return nil, ErrVectorIndexColumns.New(spec.ToName.String())
+	}
+
+	return &ast.Call{
+		ProcName: ast.ProcedureName{
+			Name:      ast.NewColIdent("dolt_create_vector_index"),
+			Qualifier: alter.Table.DbQualifier,
+		},
+		Params: []ast.Expr{
+			ast.NewStrVal([]byte(alter.Table.Name.String())),
+			ast.NewStrVal([]byte(spec.ToName.String())),
+			ast.NewStrVal([]byte(spec.Columns[0].Column.String())),
+		},
+	}, nil
+}
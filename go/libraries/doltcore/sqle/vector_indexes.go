@@ -0,0 +1,672 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/vectorindex"
+	storetypes "github.com/dolthub/dolt/go/store/types"
+)
+
+// vectorIndexCandidateFactor is the number of candidate rows read from a vector index for each row of the limit of a
+// search. Reading more candidates than the limit makes it less likely that a nearer row is missed because it's in a
+// bucket further from the bucket of the query vector.
+const vectorIndexCandidateFactor = 10
+
+// vectorIndexMinCandidates is the minimum number of candidate rows read from a vector index by a search.
+const vectorIndexMinCandidates = 100
+
+// vectorIndex is the sql.Index of a vector index. The analyzer uses it to order rows by their distance from a
+// constant vector, with the VEC_DISTANCE functions. Lookups without such an ordering scan the whole table.
+type vectorIndex struct {
+	def    vectorindex.Definition
+	db     string
+	colTyp sql.Type
+}
+
+var _ sql.Index = (*vectorIndex)(nil)
+var _ sql.OrderedIndex = (*vectorIndex)(nil)
+
+// ID implements sql.Index
+func (idx *vectorIndex) ID() string {
+	return idx.def.Name
+}
+
+// Database implements sql.Index
+func (idx *vectorIndex) Database() string {
+	return idx.db
+}
+
+// Table implements sql.Index
+func (idx *vectorIndex) Table() string {
+	return idx.def.Table
+}
+
+// Expressions implements sql.Index
+func (idx *vectorIndex) Expressions() []string {
+	return []string{idx.def.Table + "." + idx.def.Column}
+}
+
+// IsUnique implements sql.Index
+func (idx *vectorIndex) IsUnique() bool {
+	return false
+}
+
+// IsSpatial implements sql.Index
+func (idx *vectorIndex) IsSpatial() bool {
+	return false
+}
+
+// IsFullText implements sql.Index
+func (idx *vectorIndex) IsFullText() bool {
+	return false
+}
+
+// Comment implements sql.Index
+func (idx *vectorIndex) Comment() string {
+	return ""
+}
+
+// IndexType implements sql.Index
+func (idx *vectorIndex) IndexType() string {
+	return "VECTOR"
+}
+
+// IsGenerated implements sql.Index. Vector indexes are declared in dolt_vector_indexes rather than in the schema of
+// their table, so they're left out of SHOW INDEXES and SHOW CREATE TABLE.
+func (idx *vectorIndex) IsGenerated() bool {
+	return true
+}
+
+// ColumnExpressionTypes implements sql.Index
+func (idx *vectorIndex) ColumnExpressionTypes() []sql.ColumnExpressionType {
+	return []sql.ColumnExpressionType{{Expression: idx.Expressions()[0], Type: idx.colTyp}}
+}
+
+// CanSupport implements sql.Index. Ranges are supported by filtering a scan of the table, except for ranges covering
+// the whole table: the engine only asks for those to read the rows in the order of the indexed column, which a vector
+// index doesn't provide.
+func (idx *vectorIndex) CanSupport(ranges ...sql.Range) bool {
+	for _, rang := range ranges {
+		mysqlRange, ok := rang.(sql.MySQLRange)
+		if !ok {
+			return false
+		}
+		for _, expr := range mysqlRange {
+			if expr.Type() == sql.RangeType_All {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Order implements sql.OrderedIndex
+func (idx *vectorIndex) Order() sql.IndexOrder {
+	return sql.IndexOrderNone
+}
+
+// Reversible implements sql.OrderedIndex
+func (idx *vectorIndex) Reversible() bool {
+	return false
+}
+
+// CanSupportOrderBy implements sql.Index
+func (idx *vectorIndex) CanSupportOrderBy(expr sql.Expression) bool {
+	_, ok := expr.(*expression.Distance)
+	return ok
+}
+
+// PrefixLengths implements sql.Index
+func (idx *vectorIndex) PrefixLengths() []uint16 {
+	return nil
+}
+
+// vectorIndexes returns the vector indexes of this table in |root|, which are only supported in the DOLT format.
+func (t *DoltTable) vectorIndexes(ctx *sql.Context, root doltdb.RootValue) ([]sql.Index, error) {
+	if !storetypes.IsFormat_DOLT(t.nbf) {
+		return nil, nil
+	}
+	defs, err := vectorindex.ForTable(ctx, root, t.tableName)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]sql.Index, 0, len(defs))
+	for _, def := range defs {
+		var colTyp sql.Type = types.JSON
+		if col, ok := t.sch.GetAllCols().GetByNameCaseInsensitive(def.Column); ok {
+			colTyp = col.TypeInfo.ToSqlType()
+		}
+		indexes = append(indexes, &vectorIndex{def: def, db: t.db.Name(), colTyp: colTyp})
+	}
+	return indexes, nil
+}
+
+// hasVectorIndex returns whether |idx| is a vector index of this table.
+func (t *DoltTable) hasVectorIndex(ctx *sql.Context, idx *vectorIndex) (bool, error) {
+	indexes, err := t.GetIndexes(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, other := range indexes {
+		if vecIdx, ok := other.(*vectorIndex); ok && vecIdx.def == idx.def {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// vectorIndexedTable is a DoltTable read through one of its vector indexes. It's returned by DoltTable.IndexedAccess.
+type vectorIndexedTable struct {
+	*DoltTable
+	idx *vectorIndex
+}
+
+var _ sql.IndexedTable = (*vectorIndexedTable)(nil)
+
+// LookupPartitions implements sql.IndexedTable
+func (t *vectorIndexedTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLookup) (sql.PartitionIter, error) {
+	return sql.PartitionsToPartitionIter(vectorIndexPartition{lookup: lookup}), nil
+}
+
+// Partitions implements sql.Table
+func (t *vectorIndexedTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
+	panic("should call LookupPartitions on a vectorIndexedTable")
+}
+
+// PartitionRows implements sql.Table
+func (t *vectorIndexedTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	return t.DoltTable.vectorIndexRows(ctx, t.idx, part.(vectorIndexPartition).lookup)
+}
+
+// WithProjections implements sql.ProjectedTable
+func (t *vectorIndexedTable) WithProjections(colNames []string) sql.Table {
+	return &vectorIndexedTable{
+		DoltTable: t.DoltTable.WithProjections(colNames).(*DoltTable),
+		idx:       t.idx,
+	}
+}
+
+// writableVectorIndexedTable is a WritableDoltTable read through one of its vector indexes. It's returned by
+// WritableDoltTable.IndexedAccess.
+type writableVectorIndexedTable struct {
+	*WritableDoltTable
+	idx *vectorIndex
+}
+
+var _ sql.IndexedTable = (*writableVectorIndexedTable)(nil)
+var _ sql.UpdatableTable = (*writableVectorIndexedTable)(nil)
+var _ sql.DeletableTable = (*writableVectorIndexedTable)(nil)
+
+// LookupPartitions implements sql.IndexedTable
+func (t *writableVectorIndexedTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLookup) (sql.PartitionIter, error) {
+	return sql.PartitionsToPartitionIter(vectorIndexPartition{lookup: lookup}), nil
+}
+
+// Partitions implements sql.Table
+func (t *writableVectorIndexedTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
+	panic("should call LookupPartitions on a writableVectorIndexedTable")
+}
+
+// PartitionRows implements sql.Table
+func (t *writableVectorIndexedTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	return t.DoltTable.vectorIndexRows(ctx, t.idx, part.(vectorIndexPartition).lookup)
+}
+
+// WithProjections implements sql.ProjectedTable
+func (t *writableVectorIndexedTable) WithProjections(colNames []string) sql.Table {
+	return &writableVectorIndexedTable{
+		WritableDoltTable: t.WritableDoltTable.WithProjections(colNames).(*WritableDoltTable),
+		idx:               t.idx,
+	}
+}
+
+// vectorIndexPartition is the only partition of a lookup of a vector index.
+type vectorIndexPartition struct {
+	lookup sql.IndexLookup
+}
+
+var _ sql.Partition = vectorIndexPartition{}
+
+// Key implements sql.Partition
+func (p vectorIndexPartition) Key() []byte {
+	return []byte("vector")
+}
+
+// vectorIndexRows returns the rows of |lookup| of the vector index |idx|. A lookup ordering rows by their distance
+// from a vector returns the nearest candidates found by the index, in order of distance. Rows whose vector is NULL
+// aren't indexed, and so are never returned by such a lookup. Any other lookup filters a scan of the table.
+func (t *DoltTable) vectorIndexRows(ctx *sql.Context, idx *vectorIndex, lookup sql.IndexLookup) (sql.RowIter, error) {
+	root, err := t.workingRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	colIdx := t.sch.GetAllCols().IndexOf(idx.def.Column)
+	if colIdx < 0 {
+		return nil, sql.ErrTableColumnNotFound.New(t.tableName, idx.def.Column)
+	}
+	filter, err := t.rowPolicyFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	if dist, ok := lookup.VectorOrderAndLimit.OrderBy.(*expression.Distance); ok {
+		rows, err = t.nearestRows(ctx, root, idx, colIdx, dist, lookup.VectorOrderAndLimit.Limit)
+	} else {
+		rows, err = t.rowsInRanges(ctx, root, idx, colIdx, lookup.Ranges)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projection := t.rowPolicyProjection()
+	ret := make([]sql.Row, 0, len(rows))
+	for _, row := range rows {
+		if filter != nil {
+			if ok, err := filter.Allows(ctx, row); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+		if projection != nil {
+			projected := make(sql.Row, len(projection))
+			for i, j := range projection {
+				projected[i] = row[j]
+			}
+			row = projected
+		}
+		ret = append(ret, row)
+	}
+	return sql.RowsToRowIter(ret...), nil
+}
+
+// nearestRows returns the candidate rows of the vector index |idx| for the nearest neighbors of the constant side of
+// |dist|, in order of distance and limited to |limit| rows if it isn't nil.
+func (t *DoltTable) nearestRows(ctx *sql.Context, root doltdb.RootValue, idx *vectorIndex, colIdx int, dist *expression.Distance, limit sql.Expression) ([]sql.Row, error) {
+	lit, ok := dist.LeftChild.(*expression.Literal)
+	if !ok {
+		if lit, ok = dist.RightChild.(*expression.Literal); !ok {
+			return nil, fmt.Errorf("vector index '%s' requires a constant vector to search for", idx.def.Name)
+		}
+	}
+	query, err := vectorindex.ToVector(lit.Value())
+	if err != nil || query == nil {
+		return nil, err
+	}
+
+	n, target := -1, 0
+	if limit != nil {
+		l, err := limit.Eval(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		l, _, err = types.Int64.Convert(l)
+		if err != nil {
+			return nil, err
+		}
+		n = int(l.(int64))
+		target = n * vectorIndexCandidateFactor
+		if target < vectorIndexMinCandidates {
+			target = vectorIndexMinCandidates
+		}
+	}
+
+	candidates, err := vectorindex.Candidates(ctx, root, idx.def, query, target)
+	if err != nil {
+		return nil, err
+	}
+	type rowDistance struct {
+		row      sql.Row
+		distance float64
+	}
+	nearest := make([]rowDistance, 0, len(candidates))
+	for _, row := range candidates {
+		vec, err := vectorindex.ToVector(row[colIdx])
+		if err != nil {
+			return nil, err
+		} else if vec == nil {
+			continue
+		}
+		d, err := dist.DistanceType.Eval(vec, query)
+		if err != nil {
+			return nil, err
+		}
+		nearest = append(nearest, rowDistance{row: row, distance: d})
+	}
+	sort.SliceStable(nearest, func(i, j int) bool {
+		return nearest[i].distance < nearest[j].distance
+	})
+	if n >= 0 && n < len(nearest) {
+		nearest = nearest[:n]
+	}
+
+	rows := make([]sql.Row, len(nearest))
+	for i := range nearest {
+		rows[i] = nearest[i].row
+	}
+	return rows, nil
+}
+
+// rowsInRanges returns the rows of the table whose indexed column is in |ranges|.
+func (t *DoltTable) rowsInRanges(ctx *sql.Context, root doltdb.RootValue, idx *vectorIndex, colIdx int, ranges sql.RangeCollection) ([]sql.Row, error) {
+	rows, err := vectorindex.Candidates(ctx, root, idx.def, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	mysqlRanges, ok := ranges.(sql.MySQLRangeCollection)
+	if !ok || len(mysqlRanges) == 0 {
+		return rows, nil
+	}
+
+	var ret []sql.Row
+	for _, row := range rows {
+		v := row[colIdx]
+		point := sql.NullRangeColumnExpr(idx.colTyp)
+		if v != nil {
+			point = sql.ClosedRangeColumnExpr(v, v, idx.colTyp)
+		}
+		for _, rang := range mysqlRanges {
+			if len(rang) == 0 {
+				ret = append(ret, row)
+				break
+			}
+			if ok, err := point.IsSubsetOf(rang[0]); err != nil {
+				return nil, err
+			} else if ok {
+				ret = append(ret, row)
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+// vectorIndexWriter is a dsess.TableWriter that keeps the vector indexes of its table up to date with the rows
+// written to the table.
+type vectorIndexWriter struct {
+	dsess.TableWriter
+	pkOrdinals []int
+	pkTypes    []sql.Type
+	indexes    []vectorIndexTableWriter
+}
+
+// vectorIndexTableWriter writes the pseudo-index table of a vector index.
+type vectorIndexTableWriter struct {
+	colIdx int
+	writer dsess.TableWriter
+}
+
+var _ dsess.TableWriter = (*vectorIndexWriter)(nil)
+
+// withVectorIndexWriters returns |ed|, wrapped to maintain the vector indexes of this table if it has any.
+func (t *WritableDoltTable) withVectorIndexWriters(ctx *sql.Context, writeSession dsess.WriteSession, setter dsess.SessionRootSetter, ed dsess.TableWriter) (dsess.TableWriter, error) {
+	if !storetypes.IsFormat_DOLT(t.nbf) {
+		return ed, nil
+	}
+	root, err := t.workingRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := vectorindex.ForTable(ctx, root, t.tableName)
+	if err != nil || len(defs) == 0 {
+		return ed, err
+	}
+
+	w := &vectorIndexWriter{TableWriter: ed, pkOrdinals: t.sqlSch.PkOrdinals}
+	for _, ord := range t.sqlSch.PkOrdinals {
+		w.pkTypes = append(w.pkTypes, t.sqlSch.Schema[ord].Type)
+	}
+	for _, def := range defs {
+		colIdx := t.sch.GetAllCols().IndexOf(def.Column)
+		if colIdx < 0 {
+			return nil, sql.ErrTableColumnNotFound.New(t.tableName, def.Column)
+		}
+		idxWriter, err := writeSession.GetTableWriter(ctx, doltdb.TableName{Name: def.IndexTableName()}, t.db.RevisionQualifiedName(), setter, false)
+		if err != nil {
+			return nil, err
+		}
+		w.indexes = append(w.indexes, vectorIndexTableWriter{colIdx: colIdx, writer: idxWriter})
+	}
+	return w, nil
+}
+
+// indexRow returns the row of the pseudo-index table of the vector index on the column |colIdx| for |row|, or nil if
+// the vector of |row| is NULL.
+func (w *vectorIndexWriter) indexRow(colIdx int, row sql.Row) (sql.Row, error) {
+	vec, err := vectorindex.ToVector(row[colIdx])
+	if err != nil || vec == nil {
+		return nil, err
+	}
+	idxRow := make(sql.Row, 0, len(w.pkOrdinals)+1)
+	idxRow = append(idxRow, vectorindex.Bucket(vec))
+	for _, ord := range w.pkOrdinals {
+		idxRow = append(idxRow, row[ord])
+	}
+	return idxRow, nil
+}
+
+// sameIndexRow returns whether the pseudo-index table rows |a| and |b| are equal.
+func (w *vectorIndexWriter) sameIndexRow(ctx *sql.Context, a, b sql.Row) (bool, error) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, nil
+	}
+	if a[0] != b[0] {
+		return false, nil
+	}
+	for i, typ := range w.pkTypes {
+		if cmp, err := typ.Compare(a[i+1], b[i+1]); err != nil || cmp != 0 {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Insert implements sql.RowInserter
+func (w *vectorIndexWriter) Insert(ctx *sql.Context, row sql.Row) error {
+	if err := w.TableWriter.Insert(ctx, row); err != nil {
+		return err
+	}
+	for _, idx := range w.indexes {
+		idxRow, err := w.indexRow(idx.colIdx, row)
+		if err != nil {
+			return err
+		} else if idxRow != nil {
+			if err = idx.writer.Insert(ctx, idxRow); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Update implements sql.RowUpdater
+func (w *vectorIndexWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := w.TableWriter.Update(ctx, old, new); err != nil {
+		return err
+	}
+	for _, idx := range w.indexes {
+		oldRow, err := w.indexRow(idx.colIdx, old)
+		if err != nil {
+			return err
+		}
+		newRow, err := w.indexRow(idx.colIdx, new)
+		if err != nil {
+			return err
+		}
+		if equal, err := w.sameIndexRow(ctx, oldRow, newRow); err != nil {
+			return err
+		} else if equal {
+			continue
+		}
+		if oldRow != nil {
+			if err = idx.writer.Delete(ctx, oldRow); err != nil {
+				return err
+			}
+		}
+		if newRow != nil {
+			if err = idx.writer.Insert(ctx, newRow); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Delete implements sql.RowDeleter
+func (w *vectorIndexWriter) Delete(ctx *sql.Context, row sql.Row) error {
+	if err := w.TableWriter.Delete(ctx, row); err != nil {
+		return err
+	}
+	for _, idx := range w.indexes {
+		idxRow, err := w.indexRow(idx.colIdx, row)
+		if err != nil {
+			return err
+		} else if idxRow != nil {
+			if err = idx.writer.Delete(ctx, idxRow); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// StatementBegin implements sql.EditOpenerCloser
+func (w *vectorIndexWriter) StatementBegin(ctx *sql.Context) {
+	w.TableWriter.StatementBegin(ctx)
+	for _, idx := range w.indexes {
+		idx.writer.StatementBegin(ctx)
+	}
+}
+
+// DiscardChanges implements sql.EditOpenerCloser
+func (w *vectorIndexWriter) DiscardChanges(ctx *sql.Context, errorEncountered error) error {
+	err := w.TableWriter.DiscardChanges(ctx, errorEncountered)
+	for _, idx := range w.indexes {
+		if idxErr := idx.writer.DiscardChanges(ctx, errorEncountered); err == nil {
+			err = idxErr
+		}
+	}
+	return err
+}
+
+// StatementComplete implements sql.EditOpenerCloser
+func (w *vectorIndexWriter) StatementComplete(ctx *sql.Context) error {
+	if err := w.TableWriter.StatementComplete(ctx); err != nil {
+		return err
+	}
+	for _, idx := range w.indexes {
+		if err := idx.writer.StatementComplete(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements sql.Closer
+func (w *vectorIndexWriter) Close(ctx *sql.Context) error {
+	err := w.TableWriter.Close(ctx)
+	for _, idx := range w.indexes {
+		if idxErr := idx.writer.Close(ctx); err == nil {
+			err = idxErr
+		}
+	}
+	return err
+}
+
+// rebuildVectorIndexes rebuilds the vector indexes of this table in |root|, after its rows were replaced.
+func (t *DoltTable) rebuildVectorIndexes(ctx *sql.Context, root doltdb.RootValue) (doltdb.RootValue, error) {
+	if !storetypes.IsFormat_DOLT(t.nbf) {
+		return root, nil
+	}
+	defs, err := vectorindex.ForTable(ctx, root, t.tableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		if root, err = vectorindex.Build(ctx, root, def); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// checkVectorIndexedColumns returns an error if the change of the schema of this table from |oldSch| to |newSch|
+// would invalidate its vector indexes: a change to its primary key, or a change to an indexed column.
+func (t *DoltTable) checkVectorIndexedColumns(ctx *sql.Context, oldSch, newSch sql.PrimaryKeySchema, oldColumn, newColumn *sql.Column) error {
+	if !storetypes.IsFormat_DOLT(t.nbf) {
+		return nil
+	}
+	root, err := t.workingRoot(ctx)
+	if err != nil {
+		return err
+	}
+	defs, err := vectorindex.ForTable(ctx, root, t.tableName)
+	if err != nil || len(defs) == 0 {
+		return err
+	}
+
+	if len(oldSch.PkOrdinals) != len(newSch.PkOrdinals) {
+		return vectorindex.ErrPrimaryKeyChange.New(t.tableName)
+	}
+	for i, ord := range oldSch.PkOrdinals {
+		oldCol, newCol := oldSch.Schema[ord], newSch.Schema[newSch.PkOrdinals[i]]
+		if !strings.EqualFold(oldCol.Name, newCol.Name) || !oldCol.Type.Equals(newCol.Type) {
+			return vectorindex.ErrPrimaryKeyChange.New(t.tableName)
+		}
+	}
+
+	if oldColumn == nil {
+		return nil
+	}
+	for _, def := range defs {
+		if !strings.EqualFold(def.Column, oldColumn.Name) {
+			continue
+		}
+		if newColumn == nil || !strings.EqualFold(newColumn.Name, oldColumn.Name) || !newColumn.Type.Equals(oldColumn.Type) {
+			return vectorindex.ErrIndexedColumn.New(oldColumn.Name, def.Name)
+		}
+	}
+	return nil
+}
+
+// dropVectorIndex drops the vector index |name| of this table, returning false if it has no such index.
+func (t *AlterableDoltTable) dropVectorIndex(ctx *sql.Context, name string) (bool, error) {
+	if !storetypes.IsFormat_DOLT(t.nbf) {
+		return false, nil
+	}
+	root, err := t.getRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+	newRoot, dropped, err := vectorindex.Drop(ctx, root, t.tableName, name)
+	if err != nil || !dropped {
+		return false, err
+	}
+	if err = t.setRoot(ctx, newRoot); err != nil {
+		return false, err
+	}
+	return true, t.updateFromRoot(ctx, newRoot)
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorindex
+
+import (
+	"context"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// Candidates returns the rows of the table of |def| in |root| whose vectors are candidates for the nearest neighbors
+// of |query|, with every column of the table. Buckets are probed in order of their distance from the bucket of
+// |query| until at least |target| rows are found, always finishing the buckets at the same distance. If |target| isn't
+// positive, or the index has no pseudo-index table, every row of the table is returned.
+func Candidates(ctx *sql.Context, root doltdb.RootValue, def Definition, query []float64, target int) ([]sql.Row, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: def.Table})
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(def.Table)
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rowData, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := durable.ProllyMapFromIndex(rowData)
+
+	idxTbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: def.IndexTableName()})
+	if err != nil {
+		return nil, err
+	}
+	if !ok || target <= 0 {
+		iter, err := rows.IterAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(ctx, index.NewProllyRowIterForMap(sch, rows, iter, nil))
+	}
+
+	idxRowData, err := idxTbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idxRows := durable.ProllyMapFromIndex(idxRowData)
+	idxKd, _ := idxRows.Descriptors()
+	prefixDesc := idxKd.PrefixDesc(1)
+	prefixBld := val.NewTupleBuilder(prefixDesc)
+	kd, _ := rows.Descriptors()
+	kb := val.NewTupleBuilder(kd)
+
+	bucket := Bucket(query)
+	found := &kvIter{}
+	radius := 0
+	for _, probe := range ProbeOrder(bucket) {
+		if r := ProbeRadius(bucket, probe); r > radius {
+			if len(found.keys) >= target {
+				break
+			}
+			radius = r
+		}
+
+		prefixBld.PutUint64(0, probe)
+		iter, err := idxRows.IterRange(ctx, prolly.PrefixRange(prefixBld.Build(idxRows.Pool()), prefixDesc))
+		if err != nil {
+			return nil, err
+		}
+		for {
+			k, _, err := iter.Next(ctx)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			for i := 0; i < kd.Count(); i++ {
+				kb.PutRaw(i, idxKd.GetField(i+1, k))
+			}
+			err = rows.Get(ctx, kb.Build(rows.Pool()), func(key, value val.Tuple) error {
+				if key != nil {
+					found.keys = append(found.keys, key)
+					found.values = append(found.values, value)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sql.RowIterToRows(ctx, index.NewProllyRowIterForMap(sch, rows, found, nil))
+}
+
+// keyTrackingIter is a prolly.MapIter that remembers the last key it returned, so that the rows read through it can
+// be matched to their keys.
+type keyTrackingIter struct {
+	prolly.MapIter
+	key val.Tuple
+}
+
+func (it *keyTrackingIter) Next(ctx context.Context) (val.Tuple, val.Tuple, error) {
+	k, v, err := it.MapIter.Next(ctx)
+	it.key = k
+	return k, v, err
+}
+
+// kvIter is a prolly.MapIter over key-value pairs read from a map.
+type kvIter struct {
+	keys, values []val.Tuple
+	pos          int
+}
+
+var _ prolly.MapIter = (*kvIter)(nil)
+
+func (it *kvIter) Next(context.Context) (val.Tuple, val.Tuple, error) {
+	if it.pos >= len(it.keys) {
+		return nil, nil, io.EOF
+	}
+	k, v := it.keys[it.pos], it.values[it.pos]
+	it.pos++
+	return k, v, nil
+}
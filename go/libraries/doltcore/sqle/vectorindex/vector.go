@@ -0,0 +1,214 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorindex
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/shopspring/decimal"
+)
+
+// BucketBits is the number of bits of the bucket of a vector, and so the number of hyperplanes that divide the
+// vector space into buckets.
+const BucketBits = 8
+
+// seed is the seed of the hyperplanes. Changing it changes the bucket of every vector, and requires every vector
+// index to be rebuilt.
+const seed = uint64(0x5d0c7a4e1f2b3968)
+
+// ToVector converts a value of an indexed column, or of an argument of a distance function, to a vector. JSON
+// values must be arrays of numbers, strings must hold such an array, and binary values hold the vector as an array of
+// little-endian 32 bit floats. NULL converts to a nil vector.
+func ToVector(v interface{}) ([]float64, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []float64:
+		return v, nil
+	case sql.JSONWrapper:
+		doc, err := v.ToInterface()
+		if err != nil {
+			return nil, err
+		}
+		return jsonToVector(doc)
+	case string:
+		var doc interface{}
+		if err := json.Unmarshal([]byte(v), &doc); err != nil {
+			return nil, fmt.Errorf("can't convert string to vector: %w", err)
+		}
+		return jsonToVector(doc)
+	case []byte:
+		if len(v)%4 != 0 {
+			return nil, fmt.Errorf("can't convert binary value to vector; its length %d is not a multiple of 4", len(v))
+		}
+		vec := make([]float64, len(v)/4)
+		for i := range vec {
+			vec[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(v[i*4:])))
+		}
+		return vec, nil
+	default:
+		return nil, fmt.Errorf("unable to convert %v of type %T to vector", v, v)
+	}
+}
+
+func jsonToVector(doc interface{}) ([]float64, error) {
+	array, ok := doc.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("can't convert JSON to vector; expected array, got %v", doc)
+	}
+	vec := make([]float64, len(array))
+	for i, elem := range array {
+		switch elem := elem.(type) {
+		case float64:
+			vec[i] = elem
+		case float32:
+			vec[i] = float64(elem)
+		case int64:
+			vec[i] = float64(elem)
+		case int32:
+			vec[i] = float64(elem)
+		case int:
+			vec[i] = float64(elem)
+		case uint64:
+			vec[i] = float64(elem)
+		case decimal.Decimal:
+			vec[i], _ = elem.Float64()
+		case json.Number:
+			f, err := elem.Float64()
+			if err != nil {
+				return nil, err
+			}
+			vec[i] = f
+		default:
+			return nil, fmt.Errorf("can't convert JSON to vector; expected array of numbers, got %v", elem)
+		}
+	}
+	return vec, nil
+}
+
+// DistanceL2 is the euclidean distance between two vectors.
+type DistanceL2 struct{}
+
+var _ expression.DistanceType = DistanceL2{}
+
+func (d DistanceL2) String() string {
+	return "VEC_DISTANCE_L2"
+}
+
+func (d DistanceL2) Eval(left []float64, right []float64) (float64, error) {
+	squared, err := expression.DistanceL2Squared{}.Eval(left, right)
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(squared), nil
+}
+
+func (d DistanceL2) CanEval(other expression.DistanceType) bool {
+	return other == DistanceL2{}
+}
+
+// DistanceCosine is the cosine distance between two vectors, which is one minus the cosine of the angle between them.
+type DistanceCosine struct{}
+
+var _ expression.DistanceType = DistanceCosine{}
+
+func (d DistanceCosine) String() string {
+	return "VEC_DISTANCE_COSINE"
+}
+
+func (d DistanceCosine) Eval(left []float64, right []float64) (float64, error) {
+	if len(left) != len(right) {
+		return 0, fmt.Errorf("attempting to find distance between vectors of different lengths: %d vs %d", len(left), len(right))
+	}
+	var dot, leftNorm, rightNorm float64
+	for i, l := range left {
+		r := right[i]
+		dot += l * r
+		leftNorm += l * l
+		rightNorm += r * r
+	}
+	if leftNorm == 0 || rightNorm == 0 {
+		return 0, fmt.Errorf("cosine distance is undefined for a zero vector")
+	}
+	return 1 - dot/math.Sqrt(leftNorm*rightNorm), nil
+}
+
+func (d DistanceCosine) CanEval(other expression.DistanceType) bool {
+	return other == DistanceCosine{}
+}
+
+// Bucket returns the bucket of |vec|. Each bit of the bucket is the side of a hyperplane through the origin that
+// |vec| is on, so that vectors pointing in similar directions tend to share a bucket. The hyperplanes are fixed, which
+// makes the bucket of a vector depend on nothing but the vector itself, and vector indexes history-independent.
+func Bucket(vec []float64) uint64 {
+	var bucket uint64
+	for b := 0; b < BucketBits; b++ {
+		var dot float64
+		for i, v := range vec {
+			dot += v * hyperplane(b, i)
+		}
+		if dot >= 0 {
+			bucket |= 1 << b
+		}
+	}
+	return bucket
+}
+
+// ProbeOrder returns every bucket, in the order they're searched for the neighbors of a vector in |bucket|: by the
+// number of hyperplanes separating them from |bucket|, and then by value.
+func ProbeOrder(bucket uint64) []uint64 {
+	buckets := make([]uint64, 1<<BucketBits)
+	for i := range buckets {
+		buckets[i] = uint64(i)
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return bits.OnesCount64(buckets[i]^bucket) < bits.OnesCount64(buckets[j]^bucket)
+	})
+	return buckets
+}
+
+// ProbeRadius returns the number of hyperplanes separating |a| and |b|.
+func ProbeRadius(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// hyperplane returns the |i|th component of the normal of the |b|th hyperplane. Components are independent standard
+// normal samples derived from |b| and |i|, so that vectors of any dimension can be bucketed.
+func hyperplane(b, i int) float64 {
+	state := seed ^ uint64(b)<<32 ^ uint64(i)
+	u1 := unitFloat(splitmix64(&state))
+	u2 := unitFloat(splitmix64(&state))
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
+
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// unitFloat maps |x| to a float in (0, 1].
+func unitFloat(x uint64) float64 {
+	return (float64(x>>11) + 1) / (1 << 53)
+}
@@ -0,0 +1,472 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vectorindex implements vector indexes, which find the rows of a table whose vectors are nearest to a query
+// vector. The vectors of a table are divided into buckets by a fixed set of hyperplanes, and the index of a table is
+// a pseudo-index table mapping each bucket to the primary keys of the rows whose vectors fall in it. A search probes
+// the bucket of the query vector first, and then the buckets separated from it by ever more hyperplanes, until it has
+// enough candidate rows. Because the bucket of a vector depends on nothing but the vector, an index holds the same
+// data no matter the order its rows were written in, and it diffs and merges like any other table.
+package vectorindex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+	goerrors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+// BucketColumnName is the name of the column of a vector index table holding the bucket of a row.
+const BucketColumnName = "dolt_bucket"
+
+var ErrUnsupportedFormat = goerrors.NewKind("vector indexes are only supported in the %s storage format")
+var ErrKeylessTable = goerrors.NewKind("vector indexes require a primary key, and table '%s' has none")
+var ErrUnsupportedColumnType = goerrors.NewKind("column '%s' has type %s; vector indexes require a JSON or binary column")
+var ErrIndexedColumn = goerrors.NewKind("column '%s' has vector index '%s'; drop the index first")
+var ErrPrimaryKeyChange = goerrors.NewKind("table '%s' has vector indexes; drop them before changing its primary key")
+
+// Definition is a vector index, declared by a row of dolt_vector_indexes.
+type Definition struct {
+	// Table is the name of the indexed table
+	Table string
+	// Name is the name of the index
+	Name string
+	// Column is the name of the indexed column
+	Column string
+}
+
+// IndexTableName returns the name of the pseudo-index table storing the buckets of this index.
+func (d Definition) IndexTableName() string {
+	return fmt.Sprintf("dolt_%s_%s_vec_buckets", d.Table, d.Name)
+}
+
+// definitionsSchema is the schema of dolt_vector_indexes.
+var definitionsSchema = schema.MustSchemaFromCols(schema.NewColCollection(
+	schema.Column{
+		Name:       "table_name",
+		Tag:        schema.DoltVectorIndexesTableNameTag,
+		Kind:       types.StringKind,
+		IsPartOfPK: true,
+		TypeInfo:   typeinfo.FromKind(types.StringKind),
+	},
+	schema.Column{
+		Name:       "index_name",
+		Tag:        schema.DoltVectorIndexesIndexNameTag,
+		Kind:       types.StringKind,
+		IsPartOfPK: true,
+		TypeInfo:   typeinfo.FromKind(types.StringKind),
+	},
+	schema.Column{
+		Name:       "column_name",
+		Tag:        schema.DoltVectorIndexesColumnNameTag,
+		Kind:       types.StringKind,
+		IsPartOfPK: false,
+		TypeInfo:   typeinfo.FromKind(types.StringKind),
+	},
+))
+
+// DefinitionsSchema returns the schema of dolt_vector_indexes.
+func DefinitionsSchema() schema.Schema {
+	return definitionsSchema
+}
+
+// Load returns the vector indexes of |root|.
+func Load(ctx context.Context, root doltdb.RootValue) ([]Definition, error) {
+	m, ok, err := definitionsMap(ctx, root)
+	if err != nil || !ok {
+		return nil, err
+	}
+	kd, vd := m.Descriptors()
+	iter, err := m.IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []Definition
+	for {
+		k, v, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		fields := make([]string, 3)
+		for i, f := range []struct {
+			desc val.TupleDesc
+			idx  int
+			tup  val.Tuple
+		}{{kd, 0, k}, {kd, 1, k}, {vd, 0, v}} {
+			field, err := tree.GetField(ctx, f.desc, f.idx, f.tup, m.NodeStore())
+			if err != nil {
+				return nil, err
+			}
+			fields[i], _ = field.(string)
+		}
+		defs = append(defs, Definition{Table: fields[0], Name: fields[1], Column: fields[2]})
+	}
+	return defs, nil
+}
+
+// DefinitionsHash returns the hash of the vector index definitions of |root|, which changes whenever a vector index
+// is created or dropped. It is empty if |root| has no vector indexes.
+func DefinitionsHash(ctx context.Context, root doltdb.RootValue) (hash.Hash, error) {
+	h, _, err := root.GetTableHash(ctx, doltdb.TableName{Name: doltdb.VectorIndexesTableName})
+	return h, err
+}
+
+// ForTable returns the vector indexes of the table named |table| in |root|.
+func ForTable(ctx context.Context, root doltdb.RootValue, table string) ([]Definition, error) {
+	defs, err := Load(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	var ret []Definition
+	for _, def := range defs {
+		if strings.EqualFold(def.Table, table) {
+			ret = append(ret, def)
+		}
+	}
+	return ret, nil
+}
+
+// Create adds the vector index |def| to |root| and builds it from the rows of its table.
+func Create(ctx *sql.Context, root doltdb.RootValue, def Definition) (doltdb.RootValue, error) {
+	if !types.IsFormat_DOLT(root.VRW().Format()) {
+		return nil, ErrUnsupportedFormat.New(types.Format_DOLT.VersionString())
+	}
+	tbl, tableName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: def.Table})
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(def.Table)
+	}
+	def.Table = tableName
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schema.IsKeyless(sch) {
+		return nil, ErrKeylessTable.New(tableName)
+	}
+	col, ok := sch.GetAllCols().LowerNameToCol[strings.ToLower(def.Column)]
+	if !ok {
+		return nil, sql.ErrTableColumnNotFound.New(tableName, def.Column)
+	}
+	def.Column = col.Name
+	if sqlType := col.TypeInfo.ToSqlType(); !gmstypes.IsJSON(sqlType) && !gmstypes.IsBinaryType(sqlType) {
+		return nil, ErrUnsupportedColumnType.New(col.Name, sqlType.String())
+	}
+
+	if _, ok := sch.Indexes().GetByNameCaseInsensitive(def.Name); ok {
+		return nil, sql.ErrDuplicateKey.New(def.Name)
+	}
+	existing, err := ForTable(ctx, root, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if strings.EqualFold(other.Name, def.Name) {
+			return nil, sql.ErrDuplicateKey.New(def.Name)
+		}
+	}
+
+	root, err = putDefinition(ctx, root, def, false)
+	if err != nil {
+		return nil, err
+	}
+	return Build(ctx, root, def)
+}
+
+// Drop removes the vector index |name| of |table| from |root|, returning false if there's no such index.
+func Drop(ctx context.Context, root doltdb.RootValue, table, name string) (doltdb.RootValue, bool, error) {
+	defs, err := ForTable(ctx, root, table)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, def := range defs {
+		if strings.EqualFold(def.Name, name) {
+			root, err = dropDefinition(ctx, root, def)
+			return root, err == nil, err
+		}
+	}
+	return root, false, nil
+}
+
+// DropTable removes the vector indexes of |table| from |root|.
+func DropTable(ctx context.Context, root doltdb.RootValue, table string) (doltdb.RootValue, error) {
+	defs, err := ForTable(ctx, root, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		if root, err = dropDefinition(ctx, root, def); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// RenameTable moves the vector indexes of the table |oldName| to |newName| in |root|, which must already hold the
+// table under its new name.
+func RenameTable(ctx *sql.Context, root doltdb.RootValue, oldName, newName string) (doltdb.RootValue, error) {
+	defs, err := ForTable(ctx, root, oldName)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		if root, err = dropDefinition(ctx, root, def); err != nil {
+			return nil, err
+		}
+		def.Table = newName
+		if root, err = putDefinition(ctx, root, def, false); err != nil {
+			return nil, err
+		}
+		if root, err = Build(ctx, root, def); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// Build writes the pseudo-index table of |def| to |root|, holding the buckets of the rows of its table.
+func Build(ctx *sql.Context, root doltdb.RootValue, def Definition) (doltdb.RootValue, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: def.Table})
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(def.Table)
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	colIdx, err := columnIndex(sch, def)
+	if err != nil {
+		return nil, err
+	}
+
+	idxName := doltdb.TableName{Name: def.IndexTableName()}
+	if ok, err := root.HasTable(ctx, idxName); err != nil {
+		return nil, err
+	} else if ok {
+		if root, err = root.RemoveTables(ctx, true, true, idxName); err != nil {
+			return nil, err
+		}
+	}
+	idxSch, err := IndexSchema(ctx, root, idxName.Name, sch)
+	if err != nil {
+		return nil, err
+	}
+	if root, err = doltdb.CreateEmptyTable(ctx, root, idxName, idxSch); err != nil {
+		return nil, err
+	}
+	idxTbl, _, err := root.GetTable(ctx, idxName)
+	if err != nil {
+		return nil, err
+	}
+	idxRows, err := idxTbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rowData, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := durable.ProllyMapFromIndex(rowData)
+	kd, _ := rows.Descriptors()
+	idxMap := durable.ProllyMapFromIndex(idxRows)
+	mut := idxMap.Mutate()
+	idxKd, idxVd := idxMap.Descriptors()
+	kb := val.NewTupleBuilder(idxKd)
+	emptyValue := val.NewTupleBuilder(idxVd).Build(idxMap.Pool())
+
+	mapIter, err := rows.IterAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := &keyTrackingIter{MapIter: mapIter}
+	rowIter := index.NewProllyRowIterForMap(sch, rows, keys, nil)
+	for {
+		row, err := rowIter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		vec, err := ToVector(row[colIdx])
+		if err != nil {
+			return nil, err
+		} else if vec == nil {
+			continue
+		}
+		kb.PutUint64(0, Bucket(vec))
+		for i := 0; i < kd.Count(); i++ {
+			kb.PutRaw(i+1, kd.GetField(i, keys.key))
+		}
+		if err = mut.Put(ctx, kb.Build(idxMap.Pool()), emptyValue); err != nil {
+			return nil, err
+		}
+	}
+
+	idxMap, err = mut.Map(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idxTbl, err = idxTbl.UpdateRows(ctx, durable.IndexFromProllyMap(idxMap))
+	if err != nil {
+		return nil, err
+	}
+	return root.PutTable(ctx, idxName, idxTbl)
+}
+
+// IndexSchema returns the schema of the pseudo-index table |idxName| of a vector index of a table with schema |sch|
+// in |root|: the bucket of a row, followed by its primary key. Tags are generated as they are for the columns of a new
+// table, since they must be unique across the tables of |root|.
+func IndexSchema(ctx context.Context, root doltdb.RootValue, idxName string, sch schema.Schema) (schema.Schema, error) {
+	existingTags, err := doltdb.GetAllTagsForRoots(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	var kinds []types.NomsKind
+	newTag := func(name string, kind types.NomsKind) uint64 {
+		tag := schema.AutoGenerateTag(existingTags, idxName, kinds, name, kind)
+		existingTags.Add(tag, idxName)
+		kinds = append(kinds, kind)
+		return tag
+	}
+
+	cols := []schema.Column{{
+		Name:       BucketColumnName,
+		Tag:        newTag(BucketColumnName, types.UintKind),
+		Kind:       types.UintKind,
+		IsPartOfPK: true,
+		TypeInfo:   typeinfo.Uint64Type,
+		Constraints: []schema.ColConstraint{
+			schema.NotNullConstraint{},
+		},
+	}}
+	for _, col := range sch.GetPKCols().GetColumns() {
+		col.Tag = newTag(col.Name, col.Kind)
+		col.AutoIncrement = false
+		col.Default = ""
+		col.Generated = ""
+		cols = append(cols, col)
+	}
+	return schema.NewSchema(schema.NewColCollection(cols...), nil, schema.Collation_Default, nil, nil)
+}
+
+// columnIndex returns the index of the column of |def| in the rows of a table with schema |sch|.
+func columnIndex(sch schema.Schema, def Definition) (int, error) {
+	idx := sch.GetAllCols().IndexOf(def.Column)
+	if idx < 0 {
+		return 0, sql.ErrTableColumnNotFound.New(def.Table, def.Column)
+	}
+	return idx, nil
+}
+
+func definitionsMap(ctx context.Context, root doltdb.RootValue) (prolly.Map, bool, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: doltdb.VectorIndexesTableName})
+	if err != nil || !ok {
+		return prolly.Map{}, false, err
+	}
+	rowData, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return prolly.Map{}, false, err
+	}
+	return durable.ProllyMapFromIndex(rowData), true, nil
+}
+
+// putDefinition writes the row of |def| to dolt_vector_indexes, or deletes it if |remove| is true.
+func putDefinition(ctx context.Context, root doltdb.RootValue, def Definition, remove bool) (doltdb.RootValue, error) {
+	tableName := doltdb.TableName{Name: doltdb.VectorIndexesTableName}
+	tbl, ok, err := root.GetTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if remove {
+			return root, nil
+		}
+		if root, err = doltdb.CreateEmptyTable(ctx, root, tableName, definitionsSchema); err != nil {
+			return nil, err
+		}
+		if tbl, _, err = root.GetTable(ctx, tableName); err != nil {
+			return nil, err
+		}
+	}
+	rowData, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := durable.ProllyMapFromIndex(rowData)
+	ns := m.NodeStore()
+	kd, vd := m.Descriptors()
+	kb, vb := val.NewTupleBuilder(kd), val.NewTupleBuilder(vd)
+	if err = tree.PutField(ctx, ns, kb, 0, def.Table); err != nil {
+		return nil, err
+	}
+	if err = tree.PutField(ctx, ns, kb, 1, def.Name); err != nil {
+		return nil, err
+	}
+
+	mut := m.Mutate()
+	if remove {
+		err = mut.Delete(ctx, kb.Build(m.Pool()))
+	} else {
+		if err = tree.PutField(ctx, ns, vb, 0, def.Column); err != nil {
+			return nil, err
+		}
+		err = mut.Put(ctx, kb.Build(m.Pool()), vb.Build(m.Pool()))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m, err = mut.Map(ctx); err != nil {
+		return nil, err
+	}
+	if tbl, err = tbl.UpdateRows(ctx, durable.IndexFromProllyMap(m)); err != nil {
+		return nil, err
+	}
+	return root.PutTable(ctx, tableName, tbl)
+}
+
+// dropDefinition removes |def| and its pseudo-index table from |root|.
+func dropDefinition(ctx context.Context, root doltdb.RootValue, def Definition) (doltdb.RootValue, error) {
+	root, err := putDefinition(ctx, root, def, true)
+	if err != nil {
+		return nil, err
+	}
+	idxName := doltdb.TableName{Name: def.IndexTableName()}
+	if ok, err := root.HasTable(ctx, idxName); err != nil || !ok {
+		return root, err
+	}
+	return root.RemoveTables(ctx, true, true, idxName)
+}
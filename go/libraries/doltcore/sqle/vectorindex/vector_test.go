@@ -0,0 +1,77 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToVector(t *testing.T) {
+	vec, err := ToVector("[1, 2.5, -3]")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2.5, -3}, vec)
+
+	vec, err = ToVector([]byte{0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x00, 0xc0})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, -2}, vec)
+
+	vec, err = ToVector(nil)
+	require.NoError(t, err)
+	assert.Nil(t, vec)
+
+	_, err = ToVector("[1, \"a\"]")
+	assert.Error(t, err)
+	_, err = ToVector(`{"a": 1}`)
+	assert.Error(t, err)
+	_, err = ToVector([]byte{0x00, 0x00, 0x80})
+	assert.Error(t, err)
+}
+
+func TestDistances(t *testing.T) {
+	d, err := DistanceL2{}.Eval([]float64{0, 0}, []float64{3, 4})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, d)
+
+	d, err = DistanceCosine{}.Eval([]float64{1, 0}, []float64{0, 2})
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, d, 1e-9)
+
+	d, err = DistanceCosine{}.Eval([]float64{1, 1}, []float64{2, 2})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0, d, 1e-9)
+
+	_, err = DistanceCosine{}.Eval([]float64{0, 0}, []float64{1, 1})
+	assert.Error(t, err)
+	_, err = DistanceL2{}.Eval([]float64{1}, []float64{1, 2})
+	assert.Error(t, err)
+}
+
+func TestBucket(t *testing.T) {
+	vec := []float64{0.3, -1.2, 4.5, 0.01}
+	assert.Equal(t, Bucket(vec), Bucket(vec))
+	// buckets only depend on the direction of a vector
+	assert.Equal(t, Bucket(vec), Bucket([]float64{0.6, -2.4, 9, 0.02}))
+	assert.Less(t, Bucket(vec), uint64(1)<<BucketBits)
+
+	probes := ProbeOrder(Bucket(vec))
+	require.Len(t, probes, 1<<BucketBits)
+	assert.Equal(t, Bucket(vec), probes[0])
+	for i := 1; i < len(probes); i++ {
+		assert.LessOrEqual(t, ProbeRadius(probes[i-1], probes[0]), ProbeRadius(probes[i], probes[0]))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/webhook"
+	"github.com/dolthub/dolt/go/libraries/utils/config"
+)
+
+// RepoWebhookName is the name of the webhook configured in the local config of a database, with the webhook.url,
+// webhook.secret and webhook.branches keys.
+const RepoWebhookName = "repo"
+
+// repoWebhookConfig returns the webhook configured in the local config of |dEnv|, if any.
+func repoWebhookConfig(dEnv *env.DoltEnv) (webhook.Config, bool) {
+	localCfg, ok := dEnv.Config.GetConfig(env.LocalConfig)
+	if !ok {
+		return webhook.Config{}, false
+	}
+	url, err := localCfg.GetString(config.WebhookURLKey)
+	if err != nil || url == "" {
+		return webhook.Config{}, false
+	}
+
+	cfg := webhook.Config{
+		Name:       RepoWebhookName,
+		URL:        url,
+		MaxRetries: webhook.DefaultMaxRetries,
+	}
+	if secret, err := localCfg.GetString(config.WebhookSecretKey); err == nil {
+		cfg.Secret = secret
+	}
+	if branches, err := localCfg.GetString(config.WebhookBranchesKey); err == nil {
+		for _, b := range strings.Split(branches, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				cfg.Branches = append(cfg.Branches, b)
+			}
+		}
+	}
+	return cfg, true
+}
+
+// getWebhookHooks creates and starts a webhook.Hook for each of |cfgs| that applies to the database |name|, and for
+// the webhook configured in the local config of |dEnv|. Webhooks that cannot be created are logged and skipped, so
+// that they do not prevent the server from starting.
+func getWebhookHooks(ctx context.Context, bThreads *sql.BackgroundThreads, name string, dEnv *env.DoltEnv, cfgs []webhook.Config, logger io.Writer) []*webhook.Hook {
+	var all []webhook.Config
+	for _, cfg := range cfgs {
+		if cfg.AppliesToDatabase(name) {
+			all = append(all, cfg)
+		}
+	}
+	if cfg, ok := repoWebhookConfig(dEnv); ok {
+		all = append(all, cfg)
+	}
+
+	hooks := make([]*webhook.Hook, 0, len(all))
+	for _, cfg := range all {
+		hook, err := webhook.NewHook(cfg, name, dEnv.DoltDB, dEnv.FS)
+		if err == nil {
+			_ = hook.SetLogger(ctx, logger)
+			err = hook.Run(bThreads)
+		}
+		if err != nil {
+			logrus.Errorf("error loading webhook %s for database %s, webhook disabled: %v", cfg.Name, name, err)
+			continue
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks
+}
+
+// ApplyWebhookConfig installs commit hooks on each of |dbs| for the webhooks in |cfgs| that apply to it, and for the
+// webhook configured in its local config.
+func ApplyWebhookConfig(ctx context.Context, bThreads *sql.BackgroundThreads, mrEnv *env.MultiRepoEnv, cfgs []webhook.Config, logger io.Writer, dbs ...dsess.SqlDatabase) {
+	for _, db := range dbs {
+		dEnv := mrEnv.GetEnv(db.Name())
+		if dEnv == nil {
+			continue
+		}
+		for _, hook := range getWebhookHooks(ctx, bThreads, db.Name(), dEnv, cfgs, logger) {
+			dEnv.DoltDB.PrependCommitHook(ctx, hook)
+		}
+	}
+}
+
+// NewWebhookInitDatabaseHook returns an InitDatabaseHook that installs the webhooks in |cfgs| that apply to a newly
+// created database.
+func NewWebhookInitDatabaseHook(cfgs []webhook.Config, bThreads *sql.BackgroundThreads, logger io.Writer) InitDatabaseHook {
+	return func(ctx *sql.Context, pro *DoltDatabaseProvider, name string, dEnv *env.DoltEnv, db dsess.SqlDatabase) error {
+		for _, hook := range getWebhookHooks(ctx, bThreads, name, dEnv, cfgs, logger) {
+			dEnv.DoltDB.PrependCommitHook(ctx, hook)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/arrio"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/dolthub/go-mysql-server/sql"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/shopspring/decimal"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// fileMagic begins every file in the Arrow IPC file format. Files without it are read as the Arrow IPC streaming
+// format.
+var fileMagic = []byte("ARROW1")
+
+// ArrowReader implements TableReader. It reads Arrow IPC files, in either the file or the streaming format, and
+// returns rows.
+type ArrowReader struct {
+	file   *os.File
+	rd     arrio.Reader
+	closer io.Closer
+	sch    schema.Schema
+	vrw    types.ValueReadWriter
+	// colIdx is the index of the Arrow column read for each column of |sch|
+	colIdx []int
+	rec    arrow.Record
+	recRow int
+}
+
+var _ table.SqlTableReader = (*ArrowReader)(nil)
+
+// OpenArrowReader opens a reader at a given path within local filesystem.
+func OpenArrowReader(vrw types.ValueReadWriter, path string, sch schema.Schema) (*ArrowReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rd, err := NewArrowReader(vrw, f, sch)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	rd.file = f
+	return rd, nil
+}
+
+// NewArrowReader creates an ArrowReader reading from |r|, which must be seekable to read the Arrow IPC file format.
+// The columns of |sch| are read from the Arrow columns of the same name.
+func NewArrowReader(vrw types.ValueReadWriter, r io.Reader, sch schema.Schema) (*ArrowReader, error) {
+	var rd arrio.Reader
+	var closer io.Closer
+	var arrowSch *arrow.Schema
+	if ras, ok := r.(ipc.ReadAtSeeker); ok && hasFileMagic(ras) {
+		fr, err := ipc.NewFileReader(ras)
+		if err != nil {
+			return nil, err
+		}
+		rd, closer, arrowSch = fr, fr, fr.Schema()
+	} else {
+		sr, err := ipc.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		rd, closer, arrowSch = sr, closerFunc(sr.Release), sr.Schema()
+	}
+
+	cols := sch.GetAllCols().GetColumns()
+	colIdx := make([]int, len(cols))
+	for i, col := range cols {
+		indices := arrowSch.FieldIndices(col.Name)
+		if len(indices) == 0 {
+			closer.Close()
+			return nil, fmt.Errorf("cannot read column: column '%s' not found in arrow schema", col.Name)
+		}
+		colIdx[i] = indices[0]
+	}
+
+	return &ArrowReader{
+		rd:     rd,
+		closer: closer,
+		sch:    sch,
+		vrw:    vrw,
+		colIdx: colIdx,
+	}, nil
+}
+
+// hasFileMagic returns whether |r| begins with the magic bytes of the Arrow IPC file format, leaving |r| positioned
+// at its start.
+func hasFileMagic(r ipc.ReadAtSeeker) bool {
+	buf := make([]byte, len(fileMagic))
+	_, err := r.ReadAt(buf, 0)
+	return err == nil && bytes.Equal(buf, fileMagic)
+}
+
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
+
+func (ar *ArrowReader) ReadRow(ctx context.Context) (row.Row, error) {
+	panic("deprecated")
+}
+
+func (ar *ArrowReader) ReadSqlRow(ctx context.Context) (sql.Row, error) {
+	// skips empty record batches
+	for ar.rec == nil || ar.recRow >= int(ar.rec.NumRows()) {
+		rec, err := ar.rd.Read()
+		if err != nil {
+			return nil, err
+		}
+		ar.rec, ar.recRow = rec, 0
+	}
+
+	cols := ar.sch.GetAllCols().GetColumns()
+	r := make(sql.Row, len(cols))
+	for i, col := range cols {
+		v, err := arrowValue(ar.rec.Column(ar.colIdx[i]), ar.recRow)
+		if err != nil {
+			return nil, fmt.Errorf("error reading column '%s': %w", col.Name, err)
+		}
+		if wkb, ok := v.([]byte); ok {
+			v, err = geometryFromWKB(col.TypeInfo.ToSqlType(), wkb)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r[i] = v
+	}
+
+	ar.recRow++
+	return r, nil
+}
+
+// arrowValue returns the value of row |i| of |arr| as a value that can be converted to a sql.Type.
+func arrowValue(arr arrow.Array, i int) (interface{}, error) {
+	if arr.IsNull(i) {
+		return nil, nil
+	}
+
+	switch arr := arr.(type) {
+	case *array.Boolean:
+		return arr.Value(i), nil
+	case *array.Int8:
+		return arr.Value(i), nil
+	case *array.Int16:
+		return arr.Value(i), nil
+	case *array.Int32:
+		return arr.Value(i), nil
+	case *array.Int64:
+		return arr.Value(i), nil
+	case *array.Uint8:
+		return arr.Value(i), nil
+	case *array.Uint16:
+		return arr.Value(i), nil
+	case *array.Uint32:
+		return arr.Value(i), nil
+	case *array.Uint64:
+		return arr.Value(i), nil
+	case *array.Float32:
+		return arr.Value(i), nil
+	case *array.Float64:
+		return arr.Value(i), nil
+	case *array.Decimal128:
+		scale := arr.DataType().(arrow.DecimalType).GetScale()
+		return decimal.NewFromBigInt(arr.Value(i).BigInt(), -scale), nil
+	case *array.Decimal256:
+		scale := arr.DataType().(arrow.DecimalType).GetScale()
+		return decimal.NewFromBigInt(arr.Value(i).BigInt(), -scale), nil
+	case *array.Date32:
+		return arr.Value(i).ToTime(), nil
+	case *array.Date64:
+		return arr.Value(i).ToTime(), nil
+	case *array.Timestamp:
+		unit := arr.DataType().(*arrow.TimestampType).Unit
+		return arr.Value(i).ToTime(unit), nil
+	case *array.Duration:
+		unit := arr.DataType().(*arrow.DurationType).Unit
+		d := time.Duration(arr.Value(i)) * unit.Multiplier()
+		return gmstypes.Timespan(d.Microseconds()), nil
+	case *array.String:
+		// the value is only valid until the next record batch is read
+		return strings.Clone(arr.Value(i)), nil
+	case *array.LargeString:
+		return strings.Clone(arr.Value(i)), nil
+	case *array.Binary:
+		return bytes.Clone(arr.Value(i)), nil
+	case *array.LargeBinary:
+		return bytes.Clone(arr.Value(i)), nil
+	case *array.Dictionary:
+		return arrowValue(arr.Dictionary(), arr.GetValueIndex(i))
+	default:
+		return nil, fmt.Errorf("unsupported arrow type: %v", arr.DataType())
+	}
+}
+
+// geometryFromWKB returns the value of |wkb| for a column of type |t|. If |t| is a geometry type, |wkb| is decoded
+// as WKB with the SRID of the type.
+func geometryFromWKB(t sql.Type, wkb []byte) (interface{}, error) {
+	spatialType, ok := t.(sql.SpatialColumnType)
+	if !ok {
+		return wkb, nil
+	}
+	srid, _ := spatialType.GetSpatialTypeSRID()
+	ewkb := make([]byte, gmstypes.SRIDSize+len(wkb))
+	binary.LittleEndian.PutUint32(ewkb, srid)
+	copy(ewkb[gmstypes.SRIDSize:], wkb)
+	v, _, err := t.Convert(ewkb)
+	return v, err
+}
+
+func (ar *ArrowReader) GetSchema() schema.Schema {
+	return ar.sch
+}
+
+// Close should release resources being held
+func (ar *ArrowReader) Close(ctx context.Context) error {
+	err := ar.closer.Close()
+	if ar.file != nil {
+		if cErr := ar.file.Close(); err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,306 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/decimal128"
+	"github.com/apache/arrow/go/v12/arrow/decimal256"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/shopspring/decimal"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+)
+
+// batchSize is the number of rows written to each Arrow record batch.
+const batchSize = 4096
+
+// maxDecimal128Precision is the greatest precision of a decimal that fits in an arrow.Decimal128Type. Decimals of
+// greater precision are written as an arrow.Decimal256Type.
+const maxDecimal128Precision = 38
+
+// recordWriter is implemented by both the Arrow IPC file writer and the Arrow IPC stream writer.
+type recordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// ArrowRowWriter implements SqlRowWriter. It writes rows in batches to an Arrow IPC file or stream.
+type ArrowRowWriter struct {
+	sch     sql.Schema
+	builder *array.RecordBuilder
+	wr      recordWriter
+	closer  io.Closer
+	ctx     *sql.Context
+	numRows int
+}
+
+var _ table.SqlRowWriter = (*ArrowRowWriter)(nil)
+
+// NewArrowRowWriter creates a new ArrowRowWriter instance for the specified schema, writing the Arrow IPC streaming
+// format to the specified WriteCloser. The streaming format doesn't require |w| to be seekable, so it can be used
+// for writing to stdout.
+func NewArrowRowWriter(outSch sql.Schema, w io.WriteCloser) (*ArrowRowWriter, error) {
+	arrowSch, err := ArrowSchema(outSch)
+	if err != nil {
+		return nil, err
+	}
+
+	wr := ipc.NewWriter(w, ipc.WithSchema(arrowSch))
+	return newArrowRowWriter(outSch, arrowSch, wr, w), nil
+}
+
+// NewArrowRowWriterForFile creates a new ArrowRowWriter instance for the specified schema, writing the Arrow IPC
+// file format, also known as Feather V2, to the specified file name.
+func NewArrowRowWriterForFile(outSch schema.Schema, destName string) (*ArrowRowWriter, error) {
+	primaryKeySchema, err := sqlutil.FromDoltSchema("", "", outSch)
+	if err != nil {
+		return nil, err
+	}
+	arrowSch, err := ArrowSchema(primaryKeySchema.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(destName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	wr, err := ipc.NewFileWriter(f, ipc.WithSchema(arrowSch))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return newArrowRowWriter(primaryKeySchema.Schema, arrowSch, wr, f), nil
+}
+
+func newArrowRowWriter(sch sql.Schema, arrowSch *arrow.Schema, wr recordWriter, closer io.Closer) *ArrowRowWriter {
+	return &ArrowRowWriter{
+		sch:     sch,
+		builder: array.NewRecordBuilder(memory.DefaultAllocator, arrowSch),
+		wr:      wr,
+		closer:  closer,
+		ctx:     sql.NewEmptyContext(),
+	}
+}
+
+func (awr *ArrowRowWriter) WriteSqlRow(_ context.Context, r sql.Row) error {
+	for i, val := range r {
+		err := appendValue(awr.ctx, awr.builder.Field(i), awr.sch[i].Type, val)
+		if err != nil {
+			return fmt.Errorf("error writing column '%s': %w", awr.sch[i].Name, err)
+		}
+	}
+
+	awr.numRows++
+	if awr.numRows == batchSize {
+		return awr.flush()
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a record batch.
+func (awr *ArrowRowWriter) flush() error {
+	rec := awr.builder.NewRecord()
+	defer rec.Release()
+	awr.numRows = 0
+	return awr.wr.Write(rec)
+}
+
+// Close should flush all writes, release resources being held
+func (awr *ArrowRowWriter) Close(_ context.Context) error {
+	defer awr.builder.Release()
+
+	if awr.numRows > 0 {
+		if err := awr.flush(); err != nil {
+			return err
+		}
+	}
+
+	// writes the footer of the file format, or the end of stream marker of the streaming format
+	err := awr.wr.Close()
+	if err != nil {
+		return err
+	}
+
+	if awr.closer != nil {
+		return awr.closer.Close()
+	}
+
+	return nil
+}
+
+// ArrowSchema returns the Arrow schema of the columns of |sch|.
+func ArrowSchema(sch sql.Schema) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(sch))
+	for i, col := range sch {
+		t, err := mapTypeToArrowType(col.Type)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = arrow.Field{Name: col.Name, Type: t, Nullable: col.Nullable}
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// mapTypeToArrowType maps |t| from a sql.Type to the Arrow data type its values are written as.
+func mapTypeToArrowType(t sql.Type) (arrow.DataType, error) {
+	switch t.Type() {
+	case query.Type_INT8:
+		return arrow.PrimitiveTypes.Int8, nil
+	case query.Type_INT16:
+		return arrow.PrimitiveTypes.Int16, nil
+	case query.Type_INT24, query.Type_INT32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case query.Type_INT64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case query.Type_UINT8:
+		return arrow.PrimitiveTypes.Uint8, nil
+	case query.Type_UINT16:
+		return arrow.PrimitiveTypes.Uint16, nil
+	case query.Type_UINT24, query.Type_UINT32:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case query.Type_UINT64, query.Type_BIT:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case query.Type_YEAR:
+		return arrow.PrimitiveTypes.Int16, nil
+	case query.Type_FLOAT32:
+		return arrow.PrimitiveTypes.Float32, nil
+	case query.Type_FLOAT64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case query.Type_DECIMAL:
+		dt := t.(sql.DecimalType)
+		if dt.Precision() > maxDecimal128Precision {
+			return &arrow.Decimal256Type{Precision: int32(dt.Precision()), Scale: int32(dt.Scale())}, nil
+		}
+		return &arrow.Decimal128Type{Precision: int32(dt.Precision()), Scale: int32(dt.Scale())}, nil
+	case query.Type_DATE:
+		return arrow.FixedWidthTypes.Date32, nil
+	case query.Type_DATETIME:
+		return &arrow.TimestampType{Unit: arrow.Microsecond}, nil
+	case query.Type_TIMESTAMP:
+		return arrow.FixedWidthTypes.Timestamp_us, nil
+	case query.Type_TIME:
+		return arrow.FixedWidthTypes.Duration_us, nil
+	case query.Type_CHAR, query.Type_VARCHAR, query.Type_TEXT, query.Type_ENUM, query.Type_SET, query.Type_JSON:
+		return arrow.BinaryTypes.String, nil
+	case query.Type_BINARY, query.Type_VARBINARY, query.Type_BLOB, query.Type_GEOMETRY:
+		return arrow.BinaryTypes.Binary, nil
+	case query.Type_NULL_TYPE:
+		return arrow.Null, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %v", t.Type())
+	}
+}
+
+// appendValue appends |v|, a value of type |t|, to |b|, a builder for the Arrow type returned by mapTypeToArrowType.
+func appendValue(ctx *sql.Context, b array.Builder, t sql.Type, v interface{}) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+
+	switch t.Type() {
+	case query.Type_CHAR, query.Type_VARCHAR, query.Type_TEXT, query.Type_ENUM, query.Type_SET, query.Type_JSON:
+		// enums and sets are written as their string values rather than their indexes
+		res, err := t.SQL(ctx, nil, v)
+		if err != nil {
+			return err
+		}
+		b.(*array.StringBuilder).Append(res.ToString())
+		return nil
+	case query.Type_GEOMETRY:
+		geom, ok := v.(types.GeometryValue)
+		if !ok {
+			return fmt.Errorf("unexpected value of type %T for geometry", v)
+		}
+		// strip the SRID prefix of the serialized geometry, leaving its WKB
+		b.(*array.BinaryBuilder).Append(geom.Serialize()[types.SRIDSize:])
+		return nil
+	case query.Type_BINARY, query.Type_VARBINARY, query.Type_BLOB:
+		res, err := t.SQL(ctx, nil, v)
+		if err != nil {
+			return err
+		}
+		b.(*array.BinaryBuilder).Append(res.Raw())
+		return nil
+	}
+
+	v, _, err := t.Convert(v)
+	if err != nil {
+		return err
+	}
+
+	switch b := b.(type) {
+	case *array.Int8Builder:
+		b.Append(v.(int8))
+	case *array.Int16Builder:
+		b.Append(v.(int16))
+	case *array.Int32Builder:
+		b.Append(v.(int32))
+	case *array.Int64Builder:
+		b.Append(v.(int64))
+	case *array.Uint8Builder:
+		b.Append(v.(uint8))
+	case *array.Uint16Builder:
+		b.Append(v.(uint16))
+	case *array.Uint32Builder:
+		b.Append(v.(uint32))
+	case *array.Uint64Builder:
+		b.Append(v.(uint64))
+	case *array.Float32Builder:
+		b.Append(v.(float32))
+	case *array.Float64Builder:
+		b.Append(v.(float64))
+	case *array.Decimal128Builder:
+		b.Append(decimal128.FromBigInt(unscaledDecimal(v.(decimal.Decimal), b.Type())))
+	case *array.Decimal256Builder:
+		b.Append(decimal256.FromBigInt(unscaledDecimal(v.(decimal.Decimal), b.Type())))
+	case *array.Date32Builder:
+		b.Append(arrow.Date32FromTime(v.(time.Time)))
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(v.(time.Time).UnixMicro()))
+	case *array.DurationBuilder:
+		// a Timespan is a number of microseconds
+		b.Append(arrow.Duration(v.(types.Timespan)))
+	case *array.NullBuilder:
+		b.AppendNull()
+	default:
+		return fmt.Errorf("unsupported arrow type: %v", b.Type())
+	}
+	return nil
+}
+
+// unscaledDecimal returns |d| as an integer number of the smallest units of the decimal type |t|, e.g. 1.23 is
+// returned as 123 for a scale of 2.
+func unscaledDecimal(d decimal.Decimal, t arrow.DataType) *big.Int {
+	scale := t.(arrow.DecimalType).GetScale()
+	return d.Shift(scale).Round(0).BigInt()
+}
@@ -0,0 +1,203 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/dolthub/go-mysql-server/sql"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+type testCol struct {
+	name    string
+	typ     sql.Type
+	arrowID arrow.Type
+}
+
+var testCols = []testCol{
+	{"id", gmstypes.Int32, arrow.INT32},
+	{"i8", gmstypes.Int8, arrow.INT8},
+	{"i24", gmstypes.Int24, arrow.INT32},
+	{"u64", gmstypes.Uint64, arrow.UINT64},
+	{"bits", gmstypes.MustCreateBitType(10), arrow.UINT64},
+	{"dec", gmstypes.MustCreateDecimalType(10, 2), arrow.DECIMAL128},
+	{"bigdec", gmstypes.MustCreateDecimalType(50, 10), arrow.DECIMAL256},
+	{"f32", gmstypes.Float32, arrow.FLOAT32},
+	{"f64", gmstypes.Float64, arrow.FLOAT64},
+	{"e", gmstypes.MustCreateEnumType([]string{"a", "b", "c"}, sql.Collation_Default), arrow.STRING},
+	{"s", gmstypes.MustCreateSetType([]string{"a", "b", "c"}, sql.Collation_Default), arrow.STRING},
+	{"geom", gmstypes.GeometryType{}, arrow.BINARY},
+	{"pt", gmstypes.PointType{}, arrow.BINARY},
+	{"j", gmstypes.JSON, arrow.STRING},
+	{"u", typeinfo.UuidType.ToSqlType(), arrow.STRING},
+	{"dt", gmstypes.DatetimeMaxPrecision, arrow.TIMESTAMP},
+	{"ts", gmstypes.Timestamp, arrow.TIMESTAMP},
+	{"d", gmstypes.Date, arrow.DATE32},
+	{"tm", gmstypes.Time, arrow.DURATION},
+	{"y", gmstypes.Year, arrow.INT16},
+	{"vc", gmstypes.MustCreateStringWithDefaults(sqltypes.VarChar, 20), arrow.STRING},
+	{"vb", gmstypes.MustCreateBinary(sqltypes.VarBinary, 20), arrow.BINARY},
+	{"b", gmstypes.Blob, arrow.BINARY},
+	{"t", gmstypes.Text, arrow.STRING},
+}
+
+func getTestSchema(t *testing.T) schema.Schema {
+	cols := make([]schema.Column, len(testCols))
+	for i, tc := range testCols {
+		ti, err := typeinfo.FromSqlType(tc.typ)
+		require.NoError(t, err)
+		cols[i], err = schema.NewColumnWithTypeInfo(tc.name, uint64(i), ti, i == 0, "", false, "")
+		require.NoError(t, err)
+	}
+	return schema.MustSchemaFromCols(schema.NewColCollection(cols...))
+}
+
+func getTestRows(t *testing.T) []sql.Row {
+	tm, err := gmstypes.Time.ConvertToTimespan("-12:34:56.789")
+	require.NoError(t, err)
+
+	full := sql.Row{
+		int32(1),
+		int8(-5),
+		int32(-8388608),
+		uint64(math.MaxUint64),
+		uint64(0b1010101010),
+		decimal.RequireFromString("12345678.91"),
+		decimal.RequireFromString("-1234567890123456789012345678901234567890.0123456789"),
+		float32(1.25),
+		3.5,
+		"b",
+		"a,c",
+		gmstypes.LineString{Points: []gmstypes.Point{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		gmstypes.Point{X: 1.5, Y: -2.5},
+		gmstypes.MustJSON(`{"a": [1, 2]}`),
+		"6ccd780c-baba-1026-9564-5b8c656024db",
+		time.Date(2024, 5, 6, 7, 8, 9, 123456000, time.UTC),
+		time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+		tm,
+		int16(2024),
+		"hello",
+		[]byte{0, 1, 2},
+		[]byte("blob"),
+		"text",
+	}
+	nulls := make(sql.Row, len(full))
+	nulls[0] = int32(2)
+	return []sql.Row{full, nulls}
+}
+
+func TestArrowSchema(t *testing.T) {
+	sqlSch := make(sql.Schema, len(testCols))
+	for i, tc := range testCols {
+		sqlSch[i] = &sql.Column{Name: tc.name, Type: tc.typ, Nullable: true}
+	}
+	arrowSch, err := ArrowSchema(sqlSch)
+	require.NoError(t, err)
+	for i, tc := range testCols {
+		assert.Equal(t, tc.arrowID, arrowSch.Field(i).Type.ID(), tc.name)
+	}
+}
+
+func TestRoundTripFile(t *testing.T) {
+	sch := getTestSchema(t)
+	rows := getTestRows(t)
+	fileName := path.Join(t.TempDir(), "types.arrow")
+
+	wr, err := NewArrowRowWriterForFile(sch, fileName)
+	require.NoError(t, err)
+	for _, r := range rows {
+		require.NoError(t, wr.WriteSqlRow(context.Background(), r))
+	}
+	require.NoError(t, wr.Close(context.Background()))
+
+	rd, err := OpenArrowReader(nil, fileName, sch)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, rd.Close(context.Background()))
+	}()
+
+	for _, expected := range rows {
+		actual, err := rd.ReadSqlRow(context.Background())
+		require.NoError(t, err)
+		require.Len(t, actual, len(expected))
+		for i, tc := range testCols {
+			if expected[i] == nil {
+				assert.Nil(t, actual[i], tc.name)
+				continue
+			}
+			// values are read as the types they are stored as in the arrow file, such as strings for JSON
+			converted, _, err := tc.typ.Convert(actual[i])
+			require.NoError(t, err, tc.name)
+			cmp, err := tc.typ.Compare(expected[i], converted)
+			require.NoError(t, err, tc.name)
+			assert.Equal(t, 0, cmp, "column %s: expected %v, got %v", tc.name, expected[i], actual[i])
+		}
+	}
+	_, err = rd.ReadSqlRow(context.Background())
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRoundTripStream(t *testing.T) {
+	sqlSch := sql.Schema{
+		{Name: "id", Type: gmstypes.Int64},
+		{Name: "name", Type: gmstypes.Text, Nullable: true},
+	}
+	cols := schema.NewColCollection(
+		schema.NewColumn("name", 1, types.StringKind, false),
+		schema.NewColumn("id", 0, types.IntKind, true),
+	)
+	sch := schema.MustSchemaFromCols(cols)
+
+	// spans several record batches
+	numRows := batchSize*2 + 1
+	buf := &bytes.Buffer{}
+	wr, err := NewArrowRowWriter(sqlSch, iohelp.NopWrCloser(buf))
+	require.NoError(t, err)
+	for i := 0; i < numRows; i++ {
+		require.NoError(t, wr.WriteSqlRow(context.Background(), sql.Row{int64(i), "row"}))
+	}
+	require.NoError(t, wr.Close(context.Background()))
+
+	rd, err := NewArrowReader(nil, bytes.NewReader(buf.Bytes()), sch)
+	require.NoError(t, err)
+	defer rd.Close(context.Background())
+
+	for i := 0; i < numRows; i++ {
+		r, err := rd.ReadSqlRow(context.Background())
+		require.NoError(t, err)
+		// columns are read by name, in the order of the schema
+		assert.Equal(t, sql.Row{"row", int64(i)}, r)
+	}
+	_, err = rd.ReadSqlRow(context.Background())
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// The global tracer provider can only be set once for the tracers handed out before it is set, such as the tracer
+// of this package, so every test shares this exporter.
+var exporter = tracetest.NewInMemoryExporter()
+
+func init() {
+	otel.SetTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSyncer(exporter)))
+}
+
+func newHealthClient(t *testing.T) grpc_health_v1.HealthClient {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(ServerOptions()...)
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func spansByKind(spans tracetest.SpanStubs) map[trace.SpanKind]tracetest.SpanStub {
+	ret := make(map[trace.SpanKind]tracetest.SpanStub)
+	for _, s := range spans {
+		ret[s.SpanKind] = s
+	}
+	return ret
+}
+
+func TestUnaryInterceptorsPropagateTraceContext(t *testing.T) {
+	exporter.Reset()
+	client := newHealthClient(t)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	require.Error(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 5)
+	ok := spansByKind(spans[:2])
+	require.Contains(t, ok, trace.SpanKindClient)
+	require.Contains(t, ok, trace.SpanKindServer)
+	client1, server1 := ok[trace.SpanKindClient], ok[trace.SpanKindServer]
+
+	assert.Equal(t, "grpc.health.v1.Health/Check", client1.Name)
+	assert.Equal(t, "grpc.health.v1.Health/Check", server1.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), client1.Parent.SpanID())
+	assert.Equal(t, client1.SpanContext.TraceID(), server1.SpanContext.TraceID())
+	assert.Equal(t, client1.SpanContext.SpanID(), server1.Parent.SpanID())
+	assert.True(t, server1.Parent.IsRemote())
+	assert.Equal(t, codes.Unset, client1.Status.Code)
+
+	failed := spansByKind(spans[2:4])
+	assert.Equal(t, codes.Error, failed[trace.SpanKindClient].Status.Code)
+	assert.Equal(t, codes.Error, failed[trace.SpanKindServer].Status.Code)
+	assert.Equal(t, "parent", spans[4].Name)
+}
+
+func TestStreamInterceptorsPropagateTraceContext(t *testing.T) {
+	exporter.Reset()
+	client := newHealthClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, parent := otel.Tracer("test").Start(ctx, "parent")
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Empty(t, exporter.GetSpans())
+
+	// the stream ends when the client cancels it
+	cancel()
+	_, err = stream.Recv()
+	require.Error(t, err)
+	parent.End()
+
+	// the server span ends when its handler sees the cancellation, which may be after the client's
+	require.Eventually(t, func() bool { return len(exporter.GetSpans()) == 3 }, time.Second, 10*time.Millisecond)
+	spans := spansByKind(exporter.GetSpans())
+	clientSpan, serverSpan := spans[trace.SpanKindClient], spans[trace.SpanKindServer]
+	assert.Equal(t, "grpc.health.v1.Health/Watch", clientSpan.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), clientSpan.Parent.SpanID())
+	assert.Equal(t, codes.Error, clientSpan.Status.Code)
+	assert.Equal(t, clientSpan.SpanContext.TraceID(), serverSpan.SpanContext.TraceID())
+	assert.Equal(t, clientSpan.SpanContext.SpanID(), serverSpan.Parent.SpanID())
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+)
+
+// Config is the configuration of the OTLP export of traces.
+type Config struct {
+	// Endpoint is the host and port of the OTLP collector.
+	Endpoint string
+	// Protocol is the OTLP protocol spans are exported with: grpc or http.
+	Protocol string
+	// Insecure disables TLS for the export.
+	Insecure bool
+	// ServiceName is the service.name resource attribute of the exported spans.
+	ServiceName string
+	// SampleRatio is the fraction of root traces that are sampled. Traces continued from a remote parent follow the
+	// sampling decision of the parent.
+	SampleRatio float64
+	// Headers are sent with every export request.
+	Headers map[string]string
+}
+
+// Propagator is the propagator used to carry trace context across process boundaries, such as in the metadata of
+// remotesapi gRPC calls. It is used regardless of the global propagator, so that a server always continues the
+// traces of its clients.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// NewTracerProvider returns a TracerProvider which batches the spans of sampled traces and exports them to the OTLP
+// collector described by |cfg|. The caller should Shutdown the provider to flush the remaining spans.
+func NewTracerProvider(ctx context.Context, cfg Config, version string) (*tracesdk.TracerProvider, error) {
+	exp, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewTracerProviderWithExporter(exp, cfg.ServiceName, version, cfg.SampleRatio), nil
+}
+
+// NewTracerProviderWithExporter returns a TracerProvider which batches the spans of sampled traces and exports them
+// with |exp|.
+func NewTracerProviderWithExporter(exp tracesdk.SpanExporter, serviceName, version string, sampleRatio float64) *tracesdk.TracerProvider {
+	return tracesdk.NewTracerProvider(
+		tracesdk.WithBatcher(exp),
+		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(sampleRatio))),
+		tracesdk.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(version),
+		)),
+	)
+}
+
+func newExporter(ctx context.Context, cfg Config) (*otlptrace.Exporter, error) {
+	switch strings.ToLower(cfg.Protocol) {
+	case "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing protocol: %s", cfg.Protocol)
+	}
+}
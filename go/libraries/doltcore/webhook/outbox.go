@@ -0,0 +1,229 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const (
+	outboxFileExt = ".json"
+	outboxTmpExt  = ".tmp"
+	// outboxHeadsFile is the file of an outbox that records the last head added to the outbox for each ref.
+	outboxHeadsFile = "heads.json"
+)
+
+// Event is a single branch head update waiting to be delivered to a webhook endpoint. Only the heads are recorded;
+// the rest of the payload is computed from the commits when the event is delivered.
+type Event struct {
+	// ID uniquely identifies this event, and is sent to the endpoint so that it can detect redeliveries.
+	ID string `json:"id"`
+	// Seq orders the events in an outbox. Events are delivered in the order they were added.
+	Seq      uint64    `json:"seq"`
+	Database string    `json:"database"`
+	Ref      string    `json:"ref"`
+	OldHead  string    `json:"old_head,omitempty"`
+	NewHead  string    `json:"new_head,omitempty"`
+	Created  time.Time `json:"created"`
+	Attempts int       `json:"attempts,omitempty"`
+}
+
+// Outbox is a persistent queue of Events, stored as one file per event in a directory. Events stay in the outbox until
+// they are removed after a successful delivery, so that notifications are not lost if the server stops before they
+// are delivered. The outbox also remembers the new head of the last event added for each ref, so that the next event
+// for the ref has the right old head, even after a restart.
+type Outbox struct {
+	fs  filesys.Filesys
+	dir string
+
+	mu    sync.Mutex
+	seq   uint64
+	heads map[string]string
+}
+
+// NewOutbox returns an Outbox stored in |dir| of |fs|, creating the directory if necessary. Any events left in the
+// directory by a previous process are kept, and will be returned by Pending.
+func NewOutbox(fs filesys.Filesys, dir string) (*Outbox, error) {
+	if err := fs.MkDirs(dir); err != nil {
+		return nil, err
+	}
+
+	ob := &Outbox{fs: fs, dir: dir, heads: make(map[string]string)}
+	seqs, err := ob.seqs()
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) > 0 {
+		ob.seq = seqs[len(seqs)-1]
+	}
+
+	headsPath := filepath.Join(dir, outboxHeadsFile)
+	if exists, _ := fs.Exists(headsPath); exists {
+		data, err := fs.ReadFile(headsPath)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &ob.heads); err != nil {
+			return nil, fmt.Errorf("corrupt webhook outbox heads %s: %w", headsPath, err)
+		}
+	}
+
+	return ob, nil
+}
+
+// Add assigns the next sequence number to |e| and writes it to the outbox. The new head of |e| becomes the last head
+// of its ref, as returned by Heads.
+func (ob *Outbox) Add(e Event) (Event, error) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.seq++
+	e.Seq = ob.seq
+	if err := ob.write(e); err != nil {
+		return Event{}, err
+	}
+
+	prev, known := ob.heads[e.Ref]
+	ob.heads[e.Ref] = e.NewHead
+	if err := ob.writeHeads(); err != nil {
+		if known {
+			ob.heads[e.Ref] = prev
+		} else {
+			delete(ob.heads, e.Ref)
+		}
+		return Event{}, err
+	}
+	return e, nil
+}
+
+// Heads returns the new head of the last event added to the outbox for each ref, including events that were already
+// delivered and removed. The head of a deleted branch is empty.
+func (ob *Outbox) Heads() map[string]string {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	heads := make(map[string]string, len(ob.heads))
+	for r, h := range ob.heads {
+		heads[r] = h
+	}
+	return heads
+}
+
+// Update rewrites |e|, which must already be in the outbox.
+func (ob *Outbox) Update(e Event) error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	return ob.write(e)
+}
+
+// Remove deletes |e| from the outbox.
+func (ob *Outbox) Remove(e Event) error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	return ob.fs.DeleteFile(ob.eventPath(e.Seq))
+}
+
+// Pending returns every event in the outbox, ordered by sequence number.
+func (ob *Outbox) Pending() ([]Event, error) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	seqs, err := ob.seqs()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(seqs))
+	for _, seq := range seqs {
+		data, err := ob.fs.ReadFile(ob.eventPath(seq))
+		if err != nil {
+			return nil, err
+		}
+		var e Event
+		if err = json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("corrupt webhook outbox entry %s: %w", ob.eventPath(seq), err)
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// write writes |e| to a temporary file and then moves it into place, so that a partially written event is never read
+// back by Pending.
+func (ob *Outbox) write(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	path := ob.eventPath(e.Seq)
+	tmp := path + outboxTmpExt
+	if err = ob.fs.WriteFile(tmp, data, os.ModePerm); err != nil {
+		return err
+	}
+	return ob.fs.MoveFile(tmp, path)
+}
+
+// writeHeads writes the heads of the outbox like write writes an event, so that they are never read back partially
+// written.
+func (ob *Outbox) writeHeads() error {
+	data, err := json.Marshal(ob.heads)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(ob.dir, outboxHeadsFile)
+	tmp := path + outboxTmpExt
+	if err = ob.fs.WriteFile(tmp, data, os.ModePerm); err != nil {
+		return err
+	}
+	return ob.fs.MoveFile(tmp, path)
+}
+
+func (ob *Outbox) seqs() ([]uint64, error) {
+	var seqs []uint64
+	err := ob.fs.Iter(ob.dir, false, func(path string, size int64, isDir bool) (stop bool) {
+		name := filepath.Base(path)
+		if isDir || !strings.HasSuffix(name, outboxFileExt) {
+			return false
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, outboxFileExt), 10, 64)
+		if err == nil {
+			seqs = append(seqs, seq)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+func (ob *Outbox) eventPath(seq uint64) string {
+	return filepath.Join(ob.dir, fmt.Sprintf("%020d%s", seq, outboxFileExt))
+}
@@ -0,0 +1,446 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/google/uuid"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	// EventHeader names the kind of event being delivered.
+	EventHeader = "X-Dolt-Event"
+	// DeliveryHeader holds the unique ID of the event being delivered. Redeliveries of an event use the same ID.
+	DeliveryHeader = "X-Dolt-Delivery"
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body, keyed with the webhook's secret and
+	// prefixed with "sha256=". It is only sent for webhooks that have a secret.
+	SignatureHeader = "X-Dolt-Signature-256"
+
+	// EventBranchUpdate is the EventHeader value sent when a branch head moves.
+	EventBranchUpdate = "branch_update"
+
+	// OutboxDir is the directory in the .dolt directory of a database where each webhook keeps its outbox.
+	OutboxDir = "webhooks"
+
+	DefaultMaxRetries = 3
+	DefaultTimeout    = 10 * time.Second
+
+	deliveryInterval   = time.Second
+	retryBackoff       = 200 * time.Millisecond
+	webhookThreadName  = "webhook_delivery"
+	signaturePrefix    = "sha256="
+	webhookContentType = "application/json"
+)
+
+// Config describes a webhook endpoint, and which branch head updates are sent to it.
+type Config struct {
+	// Name identifies the webhook. It must be unique among the webhooks of a database, since it names the webhook's
+	// outbox.
+	Name string
+	// URL is the endpoint that payloads are POSTed to.
+	URL string
+	// Secret, if set, is used to sign every payload. See SignatureHeader.
+	Secret string
+	// Databases limits the webhook to the named databases. If empty, updates in every database are sent.
+	Databases []string
+	// Branches limits the webhook to the branches matching any of these patterns, using the syntax of path.Match. If
+	// empty, updates to every branch are sent.
+	Branches []string
+	// MaxRetries is the number of times a failed delivery is retried before giving up until the next delivery
+	// interval. Events are never dropped from the outbox because of failed deliveries.
+	MaxRetries int
+	// Timeout is the timeout for each delivery attempt.
+	Timeout time.Duration
+}
+
+// AppliesToDatabase returns whether this webhook should be installed on the database named |dbName|.
+func (c Config) AppliesToDatabase(dbName string) bool {
+	if len(c.Databases) == 0 {
+		return true
+	}
+	for _, db := range c.Databases {
+		if db == dbName {
+			return true
+		}
+	}
+	return false
+}
+
+// AppliesToBranch returns whether updates to |branch| should be sent to this webhook.
+func (c Config) AppliesToBranch(branch string) bool {
+	if len(c.Branches) == 0 {
+		return true
+	}
+	for _, pattern := range c.Branches {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body POSTed to a webhook endpoint when a branch head moves. When a branch is deleted, NewHead is
+// empty and the commit fields are omitted.
+type Payload struct {
+	ID            string    `json:"id"`
+	Database      string    `json:"database"`
+	Ref           string    `json:"ref"`
+	OldHead       string    `json:"old_head,omitempty"`
+	NewHead       string    `json:"new_head,omitempty"`
+	Committer     string    `json:"committer,omitempty"`
+	Email         string    `json:"email,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	ChangedTables []string  `json:"changed_tables"`
+}
+
+// Hook is a doltdb.CommitHook that notifies a webhook endpoint when a branch head moves. Execute only records the
+// update in a persistent Outbox; a background thread started by Run delivers the events in the outbox in order,
+// retrying failed deliveries, and removes each one once it has been delivered.
+type Hook struct {
+	cfg    Config
+	dbName string
+	ddb    *doltdb.DoltDB
+	outbox *Outbox
+	client *http.Client
+	notify chan struct{}
+
+	mu    sync.Mutex
+	heads map[string]hash.Hash
+	out   io.Writer
+}
+
+var _ doltdb.CommitHook = (*Hook)(nil)
+
+// NewHook returns a Hook for the webhook |cfg| on the database |dbName|. Its outbox is kept in the .dolt directory of
+// |fs|, which must be the filesystem of the database.
+func NewHook(cfg Config, dbName string, ddb *doltdb.DoltDB, fs filesys.Filesys) (*Hook, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	outbox, err := NewOutbox(fs, filepath.Join(dbfactory.DoltDir, OutboxDir, cfg.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	// the heads recorded by a previous process are the old heads of the next updates to their branches
+	heads := make(map[string]hash.Hash)
+	for r, head := range outbox.Heads() {
+		var addr hash.Hash
+		if head != "" {
+			var ok bool
+			if addr, ok = hash.MaybeParse(head); !ok {
+				return nil, fmt.Errorf("invalid commit hash %s in webhook outbox", head)
+			}
+		}
+		heads[r] = addr
+	}
+
+	return &Hook{
+		cfg:    cfg,
+		dbName: dbName,
+		ddb:    ddb,
+		outbox: outbox,
+		client: &http.Client{Timeout: cfg.Timeout},
+		notify: make(chan struct{}, 1),
+		heads:  heads,
+	}, nil
+}
+
+// Execute implements CommitHook. It adds an event to the outbox if |ds| is a branch accepted by the webhook.
+func (h *Hook) Execute(ctx context.Context, ds datas.Dataset, db datas.Database) (func(context.Context) error, error) {
+	if !ref.IsRef(ds.ID()) {
+		return nil, nil
+	}
+	dref, err := ref.Parse(ds.ID())
+	if err != nil {
+		return nil, err
+	}
+	if dref.GetType() != ref.BranchRefType || !h.cfg.AppliesToBranch(dref.GetPath()) {
+		return nil, nil
+	}
+
+	newHead, _ := ds.MaybeHeadAddr()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	oldHead, known := h.heads[ds.ID()]
+	if known && oldHead == newHead {
+		return nil, nil
+	}
+
+	e := Event{
+		ID:       uuid.NewString(),
+		Database: h.dbName,
+		Ref:      ds.ID(),
+		Created:  time.Now().UTC(),
+	}
+	if !oldHead.IsEmpty() {
+		e.OldHead = oldHead.String()
+	}
+	if !newHead.IsEmpty() {
+		e.NewHead = newHead.String()
+	}
+	if _, err = h.outbox.Add(e); err != nil {
+		return nil, err
+	}
+	h.heads[ds.ID()] = newHead
+
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+
+	return nil, nil
+}
+
+// HandleError implements CommitHook
+func (h *Hook) HandleError(ctx context.Context, err error) error {
+	h.logf("error queueing webhook %s: %v\n", h.cfg.Name, err)
+	return nil
+}
+
+// SetLogger implements CommitHook
+func (h *Hook) SetLogger(ctx context.Context, wr io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.out = wr
+	return nil
+}
+
+func (*Hook) ExecuteForWorkingSets() bool {
+	return false
+}
+
+// Run starts the background thread that delivers the events in the outbox, including any left over from a previous
+// process.
+func (h *Hook) Run(bThreads *sql.BackgroundThreads) error {
+	return bThreads.Add(webhookThreadName+"_"+h.dbName+"_"+h.cfg.Name, func(ctx context.Context) {
+		ticker := time.NewTicker(deliveryInterval)
+		defer ticker.Stop()
+		for {
+			h.DeliverPending(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-h.notify:
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// DeliverPending delivers the events in the outbox in order, stopping at the first event that cannot be delivered
+// so that the endpoint never sees updates to a branch out of order.
+func (h *Hook) DeliverPending(ctx context.Context) {
+	events, err := h.outbox.Pending()
+	if err != nil {
+		h.logf("error reading webhook %s outbox: %v\n", h.cfg.Name, err)
+		return
+	}
+
+	for _, e := range events {
+		if ctx.Err() != nil {
+			return
+		}
+		if err = h.deliver(ctx, e); err != nil {
+			e.Attempts++
+			h.logf("webhook %s delivery %s failed after %d attempt(s): %v\n", h.cfg.Name, e.ID, e.Attempts, err)
+			if err = h.outbox.Update(e); err != nil {
+				h.logf("error updating webhook %s outbox: %v\n", h.cfg.Name, err)
+			}
+			return
+		}
+		if err = h.outbox.Remove(e); err != nil {
+			h.logf("error updating webhook %s outbox: %v\n", h.cfg.Name, err)
+			return
+		}
+	}
+}
+
+// deliver POSTs the payload for |e|, retrying up to MaxRetries times with exponential backoff.
+func (h *Hook) deliver(ctx context.Context, e Event) error {
+	payload, err := h.payloadForEvent(ctx, e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err = h.post(ctx, e.ID, body)
+		if err == nil || attempt >= h.cfg.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (h *Hook) post(ctx context.Context, id string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", webhookContentType)
+	req.Header.Set(EventHeader, EventBranchUpdate)
+	req.Header.Set(DeliveryHeader, id)
+	if h.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(h.cfg.Secret, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// payloadForEvent builds the payload for |e| from the commits it references. If the previous head of the branch is
+// not known, which is the case for the first update of the branch seen since the webhook was configured, changes are
+// computed against the first parent of the new head.
+func (h *Hook) payloadForEvent(ctx context.Context, e Event) (Payload, error) {
+	payload := Payload{
+		ID:            e.ID,
+		Database:      e.Database,
+		Ref:           e.Ref,
+		OldHead:       e.OldHead,
+		NewHead:       e.NewHead,
+		Timestamp:     e.Created,
+		ChangedTables: []string{},
+	}
+	if e.NewHead == "" {
+		return payload, nil
+	}
+
+	newCm, err := h.commitForHash(ctx, e.NewHead)
+	if err != nil {
+		return Payload{}, err
+	}
+	meta, err := newCm.GetCommitMeta(ctx)
+	if err != nil {
+		return Payload{}, err
+	}
+	payload.Committer = meta.Name
+	payload.Email = meta.Email
+	payload.Message = meta.Description
+
+	var fromRoot doltdb.RootValue
+	if e.OldHead != "" {
+		oldCm, err := h.commitForHash(ctx, e.OldHead)
+		if err != nil {
+			return Payload{}, err
+		}
+		if fromRoot, err = oldCm.GetRootValue(ctx); err != nil {
+			return Payload{}, err
+		}
+	} else if newCm.NumParents() > 0 {
+		optCmt, err := newCm.GetParent(ctx, 0)
+		if err != nil {
+			return Payload{}, err
+		}
+		if parent, ok := optCmt.ToCommit(); ok {
+			if fromRoot, err = parent.GetRootValue(ctx); err != nil {
+				return Payload{}, err
+			}
+		}
+	}
+	if fromRoot == nil {
+		if fromRoot, err = doltdb.EmptyRootValue(ctx, h.ddb.ValueReadWriter(), h.ddb.NodeStore()); err != nil {
+			return Payload{}, err
+		}
+	}
+
+	toRoot, err := newCm.GetRootValue(ctx)
+	if err != nil {
+		return Payload{}, err
+	}
+	deltas, err := diff.GetTableDeltas(ctx, fromRoot, toRoot)
+	if err != nil {
+		return Payload{}, err
+	}
+	for _, td := range deltas {
+		changed, err := td.HasChanges()
+		if err != nil {
+			return Payload{}, err
+		}
+		if changed {
+			payload.ChangedTables = append(payload.ChangedTables, td.CurName())
+		}
+	}
+
+	return payload, nil
+}
+
+func (h *Hook) commitForHash(ctx context.Context, s string) (*doltdb.Commit, error) {
+	addr, ok := hash.MaybeParse(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid commit hash %s in webhook outbox", s)
+	}
+	return doltdb.HashToCommit(ctx, h.ddb.ValueReadWriter(), h.ddb.NodeStore(), addr)
+}
+
+func (h *Hook) logf(format string, args ...interface{}) {
+	h.mu.Lock()
+	out := h.out
+	h.mu.Unlock()
+	if out != nil {
+		_, _ = fmt.Fprintf(out, format, args...)
+	}
+}
+
+// Sign returns the value of the SignatureHeader for |body| signed with |secret|.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
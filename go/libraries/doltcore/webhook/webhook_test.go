@@ -0,0 +1,278 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	testHomeDir = "/doesnotexist/home"
+	workingDir  = "/doesnotexist/work"
+	testSecret  = "s3cret"
+)
+
+type delivery struct {
+	header  http.Header
+	body    []byte
+	payload Payload
+}
+
+// testEndpoint is a webhook endpoint that records every delivery, and fails deliveries while |failing| is set.
+type testEndpoint struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	failing    bool
+	deliveries []delivery
+}
+
+func newTestEndpoint(t *testing.T) *testEndpoint {
+	ep := &testEndpoint{}
+	ep.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ep.mu.Lock()
+		defer ep.mu.Unlock()
+		if ep.failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var p Payload
+		require.NoError(t, json.Unmarshal(body, &p))
+		ep.deliveries = append(ep.deliveries, delivery{header: r.Header, body: body, payload: p})
+	}))
+	t.Cleanup(ep.Close)
+	return ep
+}
+
+func (ep *testEndpoint) setFailing(failing bool) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	ep.failing = failing
+}
+
+func (ep *testEndpoint) received() []delivery {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return append([]delivery(nil), ep.deliveries...)
+}
+
+func newTestEnv(t *testing.T) *env.DoltEnv {
+	ctx := context.Background()
+	fs := filesys.NewInMemFS([]string{testHomeDir, workingDir}, nil, workingDir)
+	dEnv := env.Load(ctx, func() (string, error) { return testHomeDir, nil }, fs, doltdb.InMemDoltDB, "test")
+	err := dEnv.InitRepo(ctx, types.Format_Default, "Bill Billerson", "bill@billerson.com", env.DefaultInitBranch)
+	require.NoError(t, err)
+	return dEnv
+}
+
+// commit creates a commit on |branch| whose root has the tables in |tables|, and returns its hash.
+func commit(t *testing.T, dEnv *env.DoltEnv, branch string, msg string, tables ...string) string {
+	ctx := context.Background()
+	ddb := dEnv.DoltDB
+
+	root, err := doltdb.EmptyRootValue(ctx, ddb.ValueReadWriter(), ddb.NodeStore())
+	require.NoError(t, err)
+	for _, tbl := range tables {
+		// column tags must be unique across tables, so derive each table's tag from its name
+		tag := hash.Of([]byte(tbl))
+		sch := schema.MustSchemaFromCols(schema.NewColCollection(
+			schema.NewColumn("pk", binary.BigEndian.Uint64(tag[:])%schema.ReservedTagMin, types.IntKind, true, schema.NotNullConstraint{}),
+		))
+		root, err = doltdb.CreateEmptyTable(ctx, root, doltdb.TableName{Name: tbl}, sch)
+		require.NoError(t, err)
+	}
+	_, rootHash, err := ddb.WriteRootValue(ctx, root)
+	require.NoError(t, err)
+
+	var parents []*doltdb.CommitSpec
+	if has, err := ddb.HasRef(ctx, ref.NewBranchRef(branch)); err == nil && !has {
+		cs, err := doltdb.NewCommitSpec(env.DefaultInitBranch)
+		require.NoError(t, err)
+		parents = append(parents, cs)
+	}
+
+	meta, err := datas.NewCommitMeta("Bill Billerson", "bill@billerson.com", msg)
+	require.NoError(t, err)
+	cm, err := ddb.CommitWithParentSpecs(ctx, rootHash, ref.NewBranchRef(branch), parents, meta)
+	require.NoError(t, err)
+	h, err := cm.HashOf()
+	require.NoError(t, err)
+	return h.String()
+}
+
+func newTestHook(t *testing.T, dEnv *env.DoltEnv, cfg Config) *Hook {
+	hook, err := NewHook(cfg, "test", dEnv.DoltDB, dEnv.FS)
+	require.NoError(t, err)
+	dEnv.DoltDB.SetCommitHooks(context.Background(), []doltdb.CommitHook{hook})
+	return hook
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	ep := newTestEndpoint(t)
+	dEnv := newTestEnv(t)
+	hook := newTestHook(t, dEnv, Config{Name: "test", URL: ep.URL, Secret: testSecret})
+
+	first := commit(t, dEnv, env.DefaultInitBranch, "add tables", "t1", "t2")
+	hook.DeliverPending(ctx)
+
+	received := ep.received()
+	require.Len(t, received, 1)
+	d := received[0]
+	assert.Equal(t, EventBranchUpdate, d.header.Get(EventHeader))
+	assert.Equal(t, d.payload.ID, d.header.Get(DeliveryHeader))
+	assert.Equal(t, Sign(testSecret, d.body), d.header.Get(SignatureHeader))
+	assert.Equal(t, "test", d.payload.Database)
+	assert.Equal(t, "refs/heads/main", d.payload.Ref)
+	assert.Equal(t, "", d.payload.OldHead)
+	assert.Equal(t, first, d.payload.NewHead)
+	assert.Equal(t, "Bill Billerson", d.payload.Committer)
+	assert.Equal(t, "bill@billerson.com", d.payload.Email)
+	assert.Equal(t, "add tables", d.payload.Message)
+	assert.ElementsMatch(t, []string{"t1", "t2"}, d.payload.ChangedTables)
+
+	second := commit(t, dEnv, env.DefaultInitBranch, "drop a table", "t1")
+	hook.DeliverPending(ctx)
+
+	received = ep.received()
+	require.Len(t, received, 2)
+	d = received[1]
+	assert.Equal(t, first, d.payload.OldHead)
+	assert.Equal(t, second, d.payload.NewHead)
+	assert.Equal(t, []string{"t2"}, d.payload.ChangedTables)
+
+	pending, err := hook.outbox.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestWebhookBranchFilter(t *testing.T) {
+	ctx := context.Background()
+	ep := newTestEndpoint(t)
+	dEnv := newTestEnv(t)
+	hook := newTestHook(t, dEnv, Config{Name: "test", URL: ep.URL, Branches: []string{"release/*"}})
+
+	commit(t, dEnv, env.DefaultInitBranch, "on main", "t1")
+	release := commit(t, dEnv, "release/1.0", "on release", "t1", "t2")
+	commit(t, dEnv, "feature", "on feature", "t3")
+	hook.DeliverPending(ctx)
+
+	received := ep.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "refs/heads/release/1.0", received[0].payload.Ref)
+	assert.Equal(t, release, received[0].payload.NewHead)
+	assert.Empty(t, received[0].header.Get(SignatureHeader))
+}
+
+func TestWebhookOutboxSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	ep := newTestEndpoint(t)
+	dEnv := newTestEnv(t)
+	cfg := Config{Name: "test", URL: ep.URL, MaxRetries: 1}
+	hook := newTestHook(t, dEnv, cfg)
+
+	ep.setFailing(true)
+	first := commit(t, dEnv, env.DefaultInitBranch, "first", "t1")
+	second := commit(t, dEnv, env.DefaultInitBranch, "second", "t1", "t2")
+	hook.DeliverPending(ctx)
+	assert.Empty(t, ep.received())
+
+	pending, err := hook.outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, 1, pending[0].Attempts)
+
+	// A new hook for the same database picks up the events left in the outbox by the previous one.
+	ep.setFailing(false)
+	restarted := newTestHook(t, dEnv, cfg)
+	restarted.DeliverPending(ctx)
+
+	received := ep.received()
+	require.Len(t, received, 2)
+	assert.Equal(t, pending[0].ID, received[0].payload.ID)
+	assert.Equal(t, first, received[0].payload.NewHead)
+	assert.Equal(t, second, received[1].payload.NewHead)
+	assert.Equal(t, first, received[1].payload.OldHead)
+
+	pending, err = restarted.outbox.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestWebhookHeadsSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	ep := newTestEndpoint(t)
+	dEnv := newTestEnv(t)
+	cfg := Config{Name: "test", URL: ep.URL}
+	hook := newTestHook(t, dEnv, cfg)
+
+	first := commit(t, dEnv, env.DefaultInitBranch, "first", "t1")
+	hook.DeliverPending(ctx)
+	require.Len(t, ep.received(), 1)
+
+	// The branch moves while the server is stopped, and again once it has restarted. The first update after the
+	// restart is relative to the last head the webhook was notified of, not to the parent of the new head.
+	dEnv.DoltDB.SetCommitHooks(ctx, nil)
+	commit(t, dEnv, env.DefaultInitBranch, "while stopped", "t1", "t2")
+	restarted := newTestHook(t, dEnv, cfg)
+	third := commit(t, dEnv, env.DefaultInitBranch, "after restart", "t1", "t2", "t3")
+	restarted.DeliverPending(ctx)
+
+	received := ep.received()
+	require.Len(t, received, 2)
+	assert.Equal(t, first, received[1].payload.OldHead)
+	assert.Equal(t, third, received[1].payload.NewHead)
+	assert.ElementsMatch(t, []string{"t2", "t3"}, received[1].payload.ChangedTables)
+}
+
+func TestWebhookRun(t *testing.T) {
+	ep := newTestEndpoint(t)
+	dEnv := newTestEnv(t)
+	hook := newTestHook(t, dEnv, Config{Name: "test", URL: ep.URL})
+
+	bThreads := sql.NewBackgroundThreads()
+	defer bThreads.Shutdown()
+	require.NoError(t, hook.Run(bThreads))
+
+	head := commit(t, dEnv, env.DefaultInitBranch, "first", "t1")
+	require.Eventually(t, func() bool {
+		received := ep.received()
+		return len(received) == 1 && received[0].payload.NewHead == head
+	}, 5*time.Second, 10*time.Millisecond)
+}
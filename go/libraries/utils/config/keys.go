@@ -31,6 +31,9 @@ var ConfigOptions = map[string]struct{}{
 	PushAutoSetupRemote:   {},
 	ProfileKey:            {},
 	VersionCheckDisabled:  {},
+	WebhookURLKey:         {},
+	WebhookSecretKey:      {},
+	WebhookBranchesKey:    {},
 }
 
 const UserEmailKey = "user.email"
@@ -68,3 +71,9 @@ const VersionCheckDisabled = "versioncheck.disabled"
 const SignCommitsKey = "commit.gpgsign"
 
 const GPGSigningKeyKey = "user.signingkey"
+
+const WebhookURLKey = "webhook.url"
+
+const WebhookSecretKey = "webhook.secret"
+
+const WebhookBranchesKey = "webhook.branches"
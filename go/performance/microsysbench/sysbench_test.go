@@ -20,17 +20,20 @@ import (
 	"io"
 	"math/rand"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/rowexec"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/commands"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/kvexec"
 )
 
 const (
@@ -106,8 +109,59 @@ func BenchmarkSelectRandomRanges(b *testing.B) {
 	})
 }
 
+func BenchmarkMergeJoin(b *testing.B) {
+	benchmarkExecBuilders(b, func(int) string {
+		return `select /*+ MERGE_JOIN(a,b) */ a.id, b.k
+				from sbtest1 a join sbtest1 b on a.k = b.k`
+	})
+}
+
+func BenchmarkHashJoin(b *testing.B) {
+	benchmarkExecBuilders(b, func(int) string {
+		return `select /*+ HASH_JOIN(a,b) */ a.id, b.k
+				from sbtest1 a join sbtest1 b on a.id = b.k`
+	})
+}
+
+func BenchmarkGroupByAggregation(b *testing.B) {
+	benchmarkExecBuilders(b, func(int) string {
+		return "SELECT id, sum(k), min(k), max(k), avg(k) FROM sbtest1 GROUP BY id"
+	})
+}
+
+func BenchmarkTableScan(b *testing.B) {
+	getQuery := func(int) string {
+		return "SELECT id, k, c, pad FROM sbtest1"
+	}
+	b.Run("parallel", func(b *testing.B) {
+		builder := kvexec.Builder{ScanParallelism: runtime.GOMAXPROCS(0)}
+		benchmarkQueryWithBuilder(b, rowexec.NewOverrideBuilder(builder), getQuery)
+	})
+	b.Run("serial", func(b *testing.B) {
+		benchmarkQueryWithBuilder(b, rowexec.NewOverrideBuilder(kvexec.Builder{}), getQuery)
+	})
+}
+
+// benchmarkExecBuilders compares the kvexec operators with the generic
+// row operators for the same query plans.
+func benchmarkExecBuilders(b *testing.B, getQuery func(int) string) {
+	b.Run("kvexec", func(b *testing.B) {
+		benchmarkQueryWithBuilder(b, rowexec.NewOverrideBuilder(kvexec.Builder{}), getQuery)
+	})
+	b.Run("rowexec", func(b *testing.B) {
+		benchmarkQueryWithBuilder(b, rowexec.DefaultBuilder, getQuery)
+	})
+}
+
 func benchmarkSysbenchQuery(b *testing.B, getQuery func(int) string) {
+	benchmarkQueryWithBuilder(b, nil, getQuery)
+}
+
+func benchmarkQueryWithBuilder(b *testing.B, builder sql.NodeExecBuilder, getQuery func(int) string) {
 	ctx, eng := setupBenchmark(b, dEnv)
+	if builder != nil {
+		eng.GetUnderlyingEngine().Analyzer.ExecBuilder = builder
+	}
 	for i := 0; i < b.N; i++ {
 		_, iter, _, err := eng.Query(ctx, getQuery(i))
 		require.NoError(b, err)
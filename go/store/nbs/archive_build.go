@@ -44,6 +44,7 @@ func UnArchive(ctx context.Context, cs chunks.ChunkStore, smd StorageMetadata, p
 	if gs, ok := cs.(*GenerationalNBS); ok {
 		outPath, _ := gs.oldGen.Path()
 		oldgen := gs.oldGen.tables.upstream
+		keys := gs.oldGen.encryptionKeys()
 
 		swapMap := make(map[hash.Hash]hash.Hash)
 
@@ -84,10 +85,16 @@ func UnArchive(ctx context.Context, cs chunks.ChunkStore, smd StorageMetadata, p
 					if err != nil {
 						return err
 					}
-					err = classicTable.FlushToFile(filepath.Join(outPath, id))
+					err = flushToStorageFile(classicTable, filepath.Join(outPath, id), keys)
 					if err != nil {
 						return err
 					}
+					if keys != nil {
+						err = classicTable.Remove()
+						if err != nil {
+							return err
+						}
+					}
 
 					swapMap[arc.hash()] = hash.Parse(id)
 				}
@@ -121,6 +128,7 @@ func BuildArchive(ctx context.Context, cs chunks.ChunkStore, dagGroups *ChunkRel
 	if gs, ok := cs.(*GenerationalNBS); ok {
 		outPath, _ := gs.oldGen.Path()
 		oldgen := gs.oldGen.tables.upstream
+		keys := gs.oldGen.encryptionKeys()
 
 		swapMap := make(map[hash.Hash]hash.Hash)
 
@@ -138,7 +146,7 @@ func BuildArchive(ctx context.Context, cs chunks.ChunkStore, dagGroups *ChunkRel
 
 			archivePath := ""
 			archiveName := hash.Hash{}
-			archivePath, archiveName, err = convertTableFileToArchive(ctx, ogcs, idx, dagGroups, outPath, progress, &stats)
+			archivePath, archiveName, err = convertTableFileToArchive(ctx, ogcs, idx, dagGroups, outPath, keys, progress, &stats)
 			if err != nil {
 				return err
 			}
@@ -150,7 +158,7 @@ func BuildArchive(ctx context.Context, cs chunks.ChunkStore, dagGroups *ChunkRel
 			}
 			archiveSize := fileInfo.Size()
 
-			err = verifyAllChunks(idx, archivePath, progress)
+			err = verifyAllChunks(idx, archivePath, keys, progress)
 			if err != nil {
 				return err
 			}
@@ -191,6 +199,7 @@ func convertTableFileToArchive(
 	idx tableIndex,
 	dagGroups *ChunkRelations,
 	archivePath string,
+	keys *EncryptionKeys,
 	progress chan interface{},
 	stats *Stats,
 ) (string, hash.Hash, error) {
@@ -246,7 +255,7 @@ func convertTableFileToArchive(
 		return "", hash.Hash{}, err
 	}
 
-	err = indexAndFinalizeArchive(arcW, archivePath, cs.hash())
+	err = indexAndFinalizeArchive(arcW, archivePath, cs.hash(), keys)
 	if err != nil {
 		return "", hash.Hash{}, err
 	}
@@ -266,8 +275,9 @@ func convertTableFileToArchive(
 }
 
 // indexAndFinalizeArchive writes the index, metadata, and footer to the archive file. It also flushes the archive writer
-// to the directory provided. The name is calculated from the footer, and can be obtained by calling getName on the archive.
-func indexAndFinalizeArchive(arcW *archiveWriter, archivePath string, originTableFile hash.Hash) error {
+// to the directory provided, encrypted with the active key of |keys| if it is non-nil. The name is calculated from the
+// footer, and can be obtained by calling getName on the archive.
+func indexAndFinalizeArchive(arcW *archiveWriter, archivePath string, originTableFile hash.Hash, keys *EncryptionKeys) error {
 	err := arcW.finalizeByteSpans()
 	if err != nil {
 		return err
@@ -303,7 +313,7 @@ func indexAndFinalizeArchive(arcW *archiveWriter, archivePath string, originTabl
 		return err
 	}
 
-	return arcW.flushToFile(fileName)
+	return arcW.flushToFile(fileName, keys)
 }
 
 func writeDataToArchive(
@@ -440,19 +450,13 @@ func gatherAllChunks(ctx context.Context, cs chunkSource, idx tableIndex, stats
 
 	return chkCache, defaultSamples, nil
 }
-func verifyAllChunks(idx tableIndex, archiveFile string, progress chan interface{}) error {
-	file, err := os.Open(archiveFile)
-	if err != nil {
-		return err
-	}
-
-	stat, err := file.Stat()
+func verifyAllChunks(idx tableIndex, archiveFile string, keys *EncryptionKeys, progress chan interface{}) error {
+	file, fileSize, err := openReader(archiveFile, keys)
 	if err != nil {
 		return err
 	}
-	fileSize := stat.Size()
 
-	index, err := newArchiveReader(file, uint64(fileSize))
+	index, err := newArchiveReader(file, fileSize)
 	if err != nil {
 		return err
 	}
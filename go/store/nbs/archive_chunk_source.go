@@ -18,7 +18,6 @@ import (
 	"context"
 	"encoding/binary"
 	"io"
-	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
@@ -31,14 +30,15 @@ import (
 type archiveChunkSource struct {
 	file string
 	aRdr archiveReader
+	keys *EncryptionKeys
 }
 
 var _ chunkSource = &archiveChunkSource{}
 
-func newArchiveChunkSource(ctx context.Context, dir string, h hash.Hash, chunkCount uint32, q MemoryQuotaProvider) (archiveChunkSource, error) {
+func newArchiveChunkSource(ctx context.Context, dir string, h hash.Hash, chunkCount uint32, q MemoryQuotaProvider, keys *EncryptionKeys) (archiveChunkSource, error) {
 	archiveFile := filepath.Join(dir, h.String()+archiveFileSuffix)
 
-	file, size, err := openReader(archiveFile)
+	file, size, err := openReader(archiveFile, keys)
 	if err != nil {
 		return archiveChunkSource{}, err
 	}
@@ -47,21 +47,16 @@ func newArchiveChunkSource(ctx context.Context, dir string, h hash.Hash, chunkCo
 	if err != nil {
 		return archiveChunkSource{}, err
 	}
-	return archiveChunkSource{archiveFile, aRdr}, nil
+	return archiveChunkSource{archiveFile, aRdr, keys}, nil
 }
 
-func openReader(file string) (io.ReaderAt, uint64, error) {
-	f, err := os.Open(file)
+func openReader(file string, keys *EncryptionKeys) (io.ReaderAt, uint64, error) {
+	f, err := OpenStorageFile(file, keys)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	stat, err := f.Stat()
-	if err != nil {
-		return nil, 0, err
-	}
-
-	return f, uint64(stat.Size()), nil
+	return f, uint64(f.Size()), nil
 }
 
 func (acs archiveChunkSource) has(h hash.Hash) (bool, error) {
@@ -136,14 +131,14 @@ func (acs archiveChunkSource) index() (tableIndex, error) {
 }
 
 func (acs archiveChunkSource) clone() (chunkSource, error) {
-	newReader, _, err := openReader(acs.file)
+	newReader, _, err := openReader(acs.file, acs.keys)
 	if err != nil {
 		return nil, err
 	}
 
 	rdr := acs.aRdr.clone(newReader)
 
-	return archiveChunkSource{acs.file, rdr}, nil
+	return archiveChunkSource{acs.file, rdr, acs.keys}, nil
 }
 
 func (acs archiveChunkSource) getRecordRanges(_ context.Context, _ []getRecord) (map[hash.Hash]Range, error) {
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"sort"
 
@@ -386,13 +387,14 @@ func writeVarUint64(w io.Writer, val uint64) error {
 }
 
 // flushToFile writes the archive to disk. The input is the directory where the file should be written, the file name
-// will be the footer hash + ".darc" as a suffix.
-func (aw *archiveWriter) flushToFile(fullPath string) error {
+// will be the footer hash + ".darc" as a suffix. The archive is encrypted with the active key of |keys|, if non-nil.
+func (aw *archiveWriter) flushToFile(fullPath string, keys *EncryptionKeys) error {
 	if aw.workflowStage != stageFlush {
 		return fmt.Errorf("Runtime error: flushToFile called out of order")
 	}
 
-	if bs, ok := aw.output.backingSink.(*BufferedFileByteSink); ok {
+	bs, isFileSink := aw.output.backingSink.(*BufferedFileByteSink)
+	if isFileSink {
 		err := bs.finish()
 		if err != nil {
 			return err
@@ -400,10 +402,17 @@ func (aw *archiveWriter) flushToFile(fullPath string) error {
 	}
 
 	aw.finalPath = fullPath
-	err := aw.output.FlushToFile(fullPath)
+	err := flushToStorageFile(aw.output, fullPath, keys)
 	if err != nil {
 		return err
 	}
+	if keys != nil && isFileSink {
+		// the unencrypted archive was copied rather than moved
+		err = os.Remove(bs.path)
+		if err != nil {
+			return err
+		}
+	}
 	aw.workflowStage = stageDone
 	return nil
 }
@@ -250,6 +250,9 @@ func (bsTRA *bsTableReaderAt) Reader(ctx context.Context) (io.ReadCloser, error)
 
 // ReadAtWithStats is the bsTableReaderAt implementation of the tableReaderAt interface
 func (bsTRA *bsTableReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off int64, stats *Stats) (int, error) {
+	ctx, span := startReadSpan(ctx, "nbs.bsTableReaderAt.ReadAt", bsTRA.key, off, len(p))
+	defer span.End()
+
 	br := blobstore.NewBlobRange(off, int64(len(p)))
 	rc, _, err := bsTRA.bs.Get(ctx, bsTRA.key, br)
 
@@ -0,0 +1,816 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dconfig"
+	"github.com/dolthub/dolt/go/libraries/utils/file"
+	"github.com/dolthub/dolt/go/store/util/tempfiles"
+)
+
+// Encrypted storage files begin with a fixed size header identifying the key they were encrypted with, followed by
+// their contents encrypted with AES-256-GCM. Table files and archives are encrypted in fixed size blocks, so that any
+// range of their plaintext can be read by decrypting the blocks that contain it:
+//
+//	header | nonce | block 0 ciphertext+tag | nonce | block 1 ciphertext+tag | ... | nonce | last block ciphertext+tag
+//
+// Every block but the last holds exactly |blockSize| bytes of plaintext, which lets the plaintext size and the
+// location of every block be computed from the size of the file. The additional authenticated data of each block is
+// the key id, the block index, and whether it's the last block, so blocks can't be reordered and truncation of the
+// file is detected.
+//
+// The chunk journal is appended to in arbitrarily sized writes, so it's encrypted in variable length frames instead:
+//
+//	header | length | nonce | frame 0 ciphertext+tag | length | nonce | frame 1 ciphertext+tag | ... | 0
+//
+// The additional authenticated data of each frame is the key id and the logical offset of the frame's plaintext. The
+// frames are followed by a zero length, and a frame that fails to authenticate ends the journal the same way a
+// record that fails its checksum does.
+//
+// Chunk addresses are always computed over the plaintext, so encrypted stores exchange the same table files and
+// chunks with remotes as unencrypted ones do.
+
+const (
+	// EncryptionKeySize is the size in bytes of the AES-256 keys used to encrypt storage files.
+	EncryptionKeySize = 32
+
+	encryptionKeyIDSize  = 8
+	encryptionHeaderSize = 32
+	encryptedBlockSize   = 16 * 1024
+	encryptedFrameSize   = 64 * 1024
+
+	encryptionNonceSize = 12
+	encryptionTagSize   = 16
+	encryptionOverhead  = encryptionNonceSize + encryptionTagSize
+)
+
+const (
+	encryptedBlocksFormat byte = 'B'
+	encryptedFramesFormat byte = 'F'
+)
+
+// encryptionMagic begins every encrypted storage file. Unencrypted table files, archives and journals can't begin
+// with four zero bytes followed by this string.
+var encryptionMagic = []byte("\x00\x00\x00\x00DOLTENC1")
+
+// ErrNoEncryptionKey is returned when reading an encrypted storage file without any encryption keys configured.
+var ErrNoEncryptionKey = errors.New("storage file is encrypted, but no encryption key is configured")
+
+// ErrUnknownEncryptionKey is returned when reading a storage file encrypted with a key that isn't configured.
+var ErrUnknownEncryptionKey = errors.New("storage file is encrypted with a key that is not configured")
+
+// ErrDecryptionFailed is returned when encrypted storage fails to authenticate.
+var ErrDecryptionFailed = errors.New("failed to decrypt storage file")
+
+type encryptionKeyID [encryptionKeyIDSize]byte
+
+func (id encryptionKeyID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+type encryptionKey struct {
+	id   encryptionKeyID
+	aead cipher.AEAD
+}
+
+// EncryptionKeys are the keys used to encrypt and decrypt local storage files. New files are encrypted with the
+// active key, and files encrypted with any of the keys can be read, which allows keys to be rotated.
+type EncryptionKeys struct {
+	active *encryptionKey
+	keys   map[encryptionKeyID]*encryptionKey
+}
+
+// NewEncryptionKeys returns EncryptionKeys that encrypt with |active| and decrypt with |active| or any of |previous|.
+// Every key must be EncryptionKeySize bytes.
+func NewEncryptionKeys(active []byte, previous ...[]byte) (*EncryptionKeys, error) {
+	ek := &EncryptionKeys{keys: make(map[encryptionKeyID]*encryptionKey)}
+	for i, raw := range append([][]byte{active}, previous...) {
+		if len(raw) != EncryptionKeySize {
+			return nil, fmt.Errorf("encryption keys must be %d bytes, got %d", EncryptionKeySize, len(raw))
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k := &encryptionKey{id: newEncryptionKeyID(raw), aead: aead}
+		if i == 0 {
+			ek.active = k
+		}
+		if _, ok := ek.keys[k.id]; !ok {
+			ek.keys[k.id] = k
+		}
+	}
+	return ek, nil
+}
+
+// ParseEncryptionKeys parses a list of hex or base64 encoded keys separated by commas or whitespace. The first key is
+// the active key.
+func ParseEncryptionKeys(s string) (*EncryptionKeys, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+	if len(fields) == 0 {
+		return nil, errors.New("no encryption keys found")
+	}
+	raw := make([][]byte, len(fields))
+	for i, f := range fields {
+		k, err := decodeEncryptionKey(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %d: %w", i+1, err)
+		}
+		raw[i] = k
+	}
+	return NewEncryptionKeys(raw[0], raw[1:]...)
+}
+
+// EncryptionKeysFromEnv returns the encryption keys configured by the DOLT_ENCRYPTION_KEY or DOLT_ENCRYPTION_KEY_FILE
+// environment variables, or nil if neither is set.
+func EncryptionKeysFromEnv() (*EncryptionKeys, error) {
+	if s := os.Getenv(dconfig.EnvEncryptionKey); s != "" {
+		ek, err := ParseEncryptionKeys(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dconfig.EnvEncryptionKey, err)
+		}
+		return ek, nil
+	}
+	if path := os.Getenv(dconfig.EnvEncryptionKeyFile); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dconfig.EnvEncryptionKeyFile, err)
+		}
+		ek, err := ParseEncryptionKeys(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return ek, nil
+	}
+	return nil, nil
+}
+
+// ActiveKeyID returns the id of the key new files are encrypted with, as stored in the headers of encrypted files.
+func (ek *EncryptionKeys) ActiveKeyID() string {
+	return ek.active.id.String()
+}
+
+func (ek *EncryptionKeys) lookup(path string, id encryptionKeyID) (*encryptionKey, error) {
+	if ek == nil {
+		return nil, fmt.Errorf("%w: %s; set %s or %s", ErrNoEncryptionKey, path, dconfig.EnvEncryptionKey, dconfig.EnvEncryptionKeyFile)
+	}
+	k, ok := ek.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is encrypted with key %s", ErrUnknownEncryptionKey, path, id)
+	}
+	return k, nil
+}
+
+func newEncryptionKeyID(raw []byte) (id encryptionKeyID) {
+	sum := sha256.Sum256(append([]byte("dolt encryption key id:"), raw...))
+	copy(id[:], sum[:])
+	return
+}
+
+func decodeEncryptionKey(s string) ([]byte, error) {
+	if len(s) == hex.EncodedLen(EncryptionKeySize) {
+		if b, err := hex.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == EncryptionKeySize {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %d bytes encoded as hex or base64", EncryptionKeySize)
+}
+
+type encryptionHeader struct {
+	format    byte
+	keyID     encryptionKeyID
+	blockSize uint32
+}
+
+func (h encryptionHeader) encode() []byte {
+	b := make([]byte, encryptionHeaderSize)
+	copy(b, encryptionMagic)
+	b[len(encryptionMagic)] = h.format
+	copy(b[16:24], h.keyID[:])
+	binary.BigEndian.PutUint32(b[24:28], h.blockSize)
+	return b
+}
+
+// readEncryptionHeader reads the encryption header of the file |r|, returning false if it isn't encrypted.
+func readEncryptionHeader(r io.ReaderAt) (encryptionHeader, bool, error) {
+	b := make([]byte, encryptionHeaderSize)
+	n, err := r.ReadAt(b, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return encryptionHeader{}, false, err
+	}
+	if n < encryptionHeaderSize || !bytes.Equal(b[:len(encryptionMagic)], encryptionMagic) {
+		return encryptionHeader{}, false, nil
+	}
+	var h encryptionHeader
+	h.format = b[len(encryptionMagic)]
+	copy(h.keyID[:], b[16:24])
+	h.blockSize = binary.BigEndian.Uint32(b[24:28])
+	if h.format != encryptedBlocksFormat && h.format != encryptedFramesFormat {
+		return encryptionHeader{}, false, fmt.Errorf("unknown storage encryption format %q", h.format)
+	}
+	if h.format == encryptedBlocksFormat && h.blockSize == 0 {
+		return encryptionHeader{}, false, errors.New("invalid storage encryption block size")
+	}
+	return h, true, nil
+}
+
+// isEncryptedWithKey returns whether the file at |path| is encrypted with the active key of |keys|.
+func isEncryptedWithKey(path string, keys *EncryptionKeys) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	h, ok, err := readEncryptionHeader(f)
+	if err != nil || !ok {
+		return false, err
+	}
+	return keys != nil && h.keyID == keys.active.id, nil
+}
+
+// StorageFileReader reads the plaintext contents of a table file, archive or chunk journal.
+type StorageFileReader interface {
+	io.ReaderAt
+	io.Closer
+	// Size returns the size of the plaintext contents of the file.
+	Size() int64
+}
+
+// OpenStorageFile opens the table file, archive or chunk journal at |path| for reading, decrypting it with |keys| if
+// it is encrypted.
+func OpenStorageFile(path string, keys *EncryptionKeys) (StorageFileReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newStorageFileReader(f, path, keys)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func newStorageFileReader(f *os.File, path string, keys *EncryptionKeys) (StorageFileReader, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h, ok, err := readEncryptionHeader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	} else if !ok {
+		return plaintextStorageFile{f, fi.Size()}, nil
+	}
+	key, err := keys.lookup(path, h.keyID)
+	if err != nil {
+		return nil, err
+	}
+	if h.format == encryptedFramesFormat {
+		return openEncryptedJournalFile(f, key)
+	}
+	return newEncryptedBlockReader(f, key, int64(h.blockSize), fi.Size())
+}
+
+type plaintextStorageFile struct {
+	*os.File
+	size int64
+}
+
+func (f plaintextStorageFile) Size() int64 {
+	return f.size
+}
+
+// storageFileSectionReader returns an io.ReadSeekCloser of the entire plaintext of |r|.
+func storageFileSectionReader(r StorageFileReader) io.ReadCloser {
+	return struct {
+		*io.SectionReader
+		io.Closer
+	}{io.NewSectionReader(r, 0, r.Size()), r}
+}
+
+// newStorageFileWriter returns a writer that writes the contents of a storage file to |w|, encrypted with the active
+// key of |keys| if it is non-nil. The writer must be closed to finish the file, which does not close |w|.
+func newStorageFileWriter(w io.Writer, keys *EncryptionKeys) (io.WriteCloser, error) {
+	if keys == nil {
+		return nopWriteCloser{w}, nil
+	}
+	return newEncryptedBlockWriter(w, keys.active, encryptedBlockSize)
+}
+
+// writeStorageFile writes the contents of |r| to the new file |f|, encrypted with the active key of |keys| if it is
+// non-nil, and then syncs and closes |f|.
+func writeStorageFile(f *os.File, r io.Reader, keys *EncryptionKeys) (err error) {
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	w, err := newStorageFileWriter(f, keys)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, r); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// storageFileSink is a finished table file or archive that can be written to its final location.
+type storageFileSink interface {
+	FlushToFile(path string) error
+	Reader() (io.ReadCloser, error)
+}
+
+// flushToStorageFile writes the contents of |sink| to |path|, encrypted with the active key of |keys| if it is
+// non-nil. When encrypting, |sink| is copied rather than moved into place, and the caller is responsible for removing
+// it.
+func flushToStorageFile(sink storageFileSink, path string, keys *EncryptionKeys) error {
+	if keys == nil {
+		return sink.FlushToFile(path)
+	}
+	r, err := sink.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	temp, err := tempfiles.MovableTempFileProvider.NewFile(filepath.Dir(path), tempTablePrefix)
+	if err != nil {
+		return err
+	}
+	if err = writeStorageFile(temp, r, keys); err != nil {
+		file.Remove(temp.Name())
+		return err
+	}
+	return file.Rename(temp.Name(), path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func blockAAD(id encryptionKeyID, idx uint64, last bool) []byte {
+	aad := make([]byte, encryptionKeyIDSize+9)
+	copy(aad, id[:])
+	binary.BigEndian.PutUint64(aad[encryptionKeyIDSize:], idx)
+	if last {
+		aad[len(aad)-1] = 1
+	}
+	return aad
+}
+
+func frameAAD(id encryptionKeyID, off int64) []byte {
+	aad := make([]byte, encryptionKeyIDSize+8)
+	copy(aad, id[:])
+	binary.BigEndian.PutUint64(aad[encryptionKeyIDSize:], uint64(off))
+	return aad
+}
+
+// seal appends the nonce and ciphertext of |plaintext| to |dst|.
+func (k *encryptionKey) seal(dst, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, encryptionNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	dst = append(dst, nonce...)
+	return k.aead.Seal(dst, nonce, plaintext, aad), nil
+}
+
+// open decrypts |sealed|, a nonce followed by ciphertext.
+func (k *encryptionKey) open(sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < encryptionOverhead {
+		return nil, ErrDecryptionFailed
+	}
+	pt, err := k.aead.Open(nil, sealed[:encryptionNonceSize], sealed[encryptionNonceSize:], aad)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return pt, nil
+}
+
+// encryptedBlockWriter writes a storage file encrypted in fixed size blocks.
+type encryptedBlockWriter struct {
+	w         io.Writer
+	key       *encryptionKey
+	blockSize int
+	buf       []byte
+	out       []byte
+	idx       uint64
+	closed    bool
+}
+
+func newEncryptedBlockWriter(w io.Writer, key *encryptionKey, blockSize int) (*encryptedBlockWriter, error) {
+	h := encryptionHeader{format: encryptedBlocksFormat, keyID: key.id, blockSize: uint32(blockSize)}
+	if _, err := w.Write(h.encode()); err != nil {
+		return nil, err
+	}
+	return &encryptedBlockWriter{
+		w:         w,
+		key:       key,
+		blockSize: blockSize,
+		buf:       make([]byte, 0, blockSize),
+		out:       make([]byte, 0, blockSize+encryptionOverhead),
+	}, nil
+}
+
+// Write implements io.Writer. Full blocks are only written once more data follows them, since the last block of the
+// file is authenticated differently than the rest.
+func (bw *encryptedBlockWriter) Write(p []byte) (int, error) {
+	if bw.closed {
+		return 0, errors.New("write to closed encrypted writer")
+	}
+	n := 0
+	for len(p) > 0 {
+		if len(bw.buf) == bw.blockSize {
+			if err := bw.writeBlock(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(bw.buf[len(bw.buf):bw.blockSize], p)
+		bw.buf = bw.buf[:len(bw.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close writes the last block of the file.
+func (bw *encryptedBlockWriter) Close() error {
+	if bw.closed {
+		return nil
+	}
+	bw.closed = true
+	return bw.writeBlock(true)
+}
+
+func (bw *encryptedBlockWriter) writeBlock(last bool) (err error) {
+	bw.out, err = bw.key.seal(bw.out[:0], bw.buf, blockAAD(bw.key.id, bw.idx, last))
+	if err != nil {
+		return err
+	}
+	if _, err = bw.w.Write(bw.out); err != nil {
+		return err
+	}
+	bw.buf = bw.buf[:0]
+	bw.idx++
+	return nil
+}
+
+// encryptedBlockReader reads the plaintext of a storage file encrypted in fixed size blocks.
+type encryptedBlockReader struct {
+	f         *os.File
+	key       *encryptionKey
+	blockSize int64
+	blocks    int64
+	size      int64
+}
+
+var _ StorageFileReader = (*encryptedBlockReader)(nil)
+
+func newEncryptedBlockReader(f *os.File, key *encryptionKey, blockSize, fileSize int64) (*encryptedBlockReader, error) {
+	body := fileSize - encryptionHeaderSize
+	physBlock := blockSize + encryptionOverhead
+	if body < encryptionOverhead {
+		return nil, fmt.Errorf("%w: %s is truncated", ErrDecryptionFailed, f.Name())
+	}
+	blocks := (body + physBlock - 1) / physBlock
+	last := body - (blocks-1)*physBlock
+	if last < encryptionOverhead {
+		return nil, fmt.Errorf("%w: %s has an invalid size", ErrDecryptionFailed, f.Name())
+	}
+	return &encryptedBlockReader{
+		f:         f,
+		key:       key,
+		blockSize: blockSize,
+		blocks:    blocks,
+		size:      (blocks-1)*blockSize + last - encryptionOverhead,
+	}, nil
+}
+
+// ReadAt implements io.ReaderAt.
+func (br *encryptedBlockReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	for n < len(p) && off < br.size {
+		idx := off / br.blockSize
+		var pt []byte
+		if pt, err = br.readBlock(idx); err != nil {
+			return n, err
+		}
+		c := copy(p[n:], pt[off-idx*br.blockSize:])
+		n += c
+		off += int64(c)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (br *encryptedBlockReader) readBlock(idx int64) ([]byte, error) {
+	last := idx == br.blocks-1
+	sz := br.blockSize
+	if last {
+		sz = br.size - idx*br.blockSize
+	}
+	sealed := make([]byte, sz+encryptionOverhead)
+	if _, err := br.f.ReadAt(sealed, encryptionHeaderSize+idx*(br.blockSize+encryptionOverhead)); err != nil {
+		return nil, err
+	}
+	pt, err := br.key.open(sealed, blockAAD(br.key.id, uint64(idx), last))
+	if err != nil {
+		return nil, fmt.Errorf("%w: block %d of %s", err, idx, br.f.Name())
+	}
+	return pt, nil
+}
+
+// Size implements StorageFileReader.
+func (br *encryptedBlockReader) Size() int64 {
+	return br.size
+}
+
+// Close implements io.Closer.
+func (br *encryptedBlockReader) Close() error {
+	return br.f.Close()
+}
+
+// journalFile is the file a journalWriter reads and writes chunk journal records to.
+type journalFile interface {
+	io.ReaderAt
+	io.WriterAt
+	io.ReadSeeker
+	Sync() error
+	Close() error
+}
+
+var _ journalFile = (*os.File)(nil)
+var _ journalFile = (*encryptedJournalFile)(nil)
+
+type journalFrame struct {
+	// off is the logical offset of the frame's plaintext, and phys is the offset of the frame in the file.
+	off, phys int64
+	len       int
+}
+
+// encryptedJournalFile is a journalFile encrypted in frames. Its logical offsets are the offsets of the plaintext, so
+// journal records and the journal index address encrypted journals the same way as unencrypted ones. Writes must be
+// at or before the end of the plaintext; writing before the end discards everything after the write.
+type encryptedJournalFile struct {
+	f   *os.File
+	key *encryptionKey
+
+	mu     sync.RWMutex
+	frames []journalFrame
+	size   int64
+	end    int64
+
+	cacheMu    sync.Mutex
+	cachedIdx  int
+	cachedData []byte
+
+	// pos is the offset of Read and Seek
+	pos int64
+}
+
+// createEncryptedJournalFile starts an encrypted journal in the empty, zero-filled file |f|.
+func createEncryptedJournalFile(f *os.File, key *encryptionKey) (*encryptedJournalFile, error) {
+	h := encryptionHeader{format: encryptedFramesFormat, keyID: key.id}
+	if _, err := f.WriteAt(append(h.encode(), 0, 0, 0, 0), 0); err != nil {
+		return nil, err
+	}
+	return &encryptedJournalFile{f: f, key: key, end: encryptionHeaderSize, cachedIdx: -1}, nil
+}
+
+// openEncryptedJournalFile reads the frames of the existing encrypted journal |f|. Reading stops at the first frame
+// with an invalid length, and any frames at the end of the journal that fail to authenticate, such as those torn by
+// a crash, are discarded.
+func openEncryptedJournalFile(f *os.File, key *encryptionKey) (*encryptedJournalFile, error) {
+	jf := &encryptedJournalFile{f: f, key: key, end: encryptionHeaderSize, cachedIdx: -1}
+	var lb [4]byte
+	for {
+		if n, _ := f.ReadAt(lb[:], jf.end); n < len(lb) {
+			break
+		}
+		l := int(binary.BigEndian.Uint32(lb[:]))
+		if l < encryptionOverhead || l > encryptedFrameSize+encryptionOverhead {
+			break
+		}
+		fr := journalFrame{off: jf.size, phys: jf.end, len: l - encryptionOverhead}
+		jf.frames = append(jf.frames, fr)
+		jf.size += int64(fr.len)
+		jf.end += int64(len(lb) + l)
+	}
+	for len(jf.frames) > 0 {
+		last := jf.frames[len(jf.frames)-1]
+		if _, err := jf.decryptFrame(last); err == nil {
+			break
+		}
+		jf.frames = jf.frames[:len(jf.frames)-1]
+		jf.size, jf.end = last.off, last.phys
+	}
+	return jf, nil
+}
+
+func (jf *encryptedJournalFile) decryptFrame(fr journalFrame) ([]byte, error) {
+	sealed := make([]byte, fr.len+encryptionOverhead)
+	if _, err := jf.f.ReadAt(sealed, fr.phys+4); err != nil {
+		return nil, err
+	}
+	return jf.key.open(sealed, frameAAD(jf.key.id, fr.off))
+}
+
+// frameData returns the plaintext of the |idx|th frame. The caller must hold |jf.mu|.
+func (jf *encryptedJournalFile) frameData(idx int) ([]byte, error) {
+	jf.cacheMu.Lock()
+	defer jf.cacheMu.Unlock()
+	if jf.cachedIdx == idx {
+		return jf.cachedData, nil
+	}
+	pt, err := jf.decryptFrame(jf.frames[idx])
+	if err != nil {
+		return nil, fmt.Errorf("%w: journal frame at %d of %s", err, jf.frames[idx].phys, jf.f.Name())
+	}
+	jf.cachedIdx, jf.cachedData = idx, pt
+	return pt, nil
+}
+
+// frameAt returns the index of the frame containing the logical offset |off|. The caller must hold |jf.mu|.
+func (jf *encryptedJournalFile) frameAt(off int64) int {
+	return sort.Search(len(jf.frames), func(i int) bool {
+		return jf.frames[i].off+int64(jf.frames[i].len) > off
+	})
+}
+
+// ReadAt implements io.ReaderAt.
+func (jf *encryptedJournalFile) ReadAt(p []byte, off int64) (n int, err error) {
+	jf.mu.RLock()
+	defer jf.mu.RUnlock()
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	for n < len(p) && off < jf.size {
+		idx := jf.frameAt(off)
+		var pt []byte
+		if pt, err = jf.frameData(idx); err != nil {
+			return n, err
+		}
+		c := copy(p[n:], pt[off-jf.frames[idx].off:])
+		n += c
+		off += int64(c)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt implements io.WriterAt.
+func (jf *encryptedJournalFile) WriteAt(p []byte, off int64) (int, error) {
+	jf.mu.Lock()
+	defer jf.mu.Unlock()
+	if off > jf.size {
+		return 0, fmt.Errorf("cannot write encrypted journal at %d past its end at %d", off, jf.size)
+	}
+
+	frames, size, end := jf.frames, jf.size, jf.end
+	data := p
+	if off < size {
+		// rewrite the frame containing |off| with the part of it before |off|
+		idx := jf.frameAt(off)
+		pt, err := jf.frameData(idx)
+		if err != nil {
+			return 0, err
+		}
+		fr := frames[idx]
+		data = append(pt[:off-fr.off:off-fr.off], p...)
+		frames, size, end = frames[:idx], fr.off, fr.phys
+	}
+
+	var out []byte
+	var err error
+	for len(data) > 0 {
+		l := len(data)
+		if l > encryptedFrameSize {
+			l = encryptedFrameSize
+		}
+		frame := journalFrame{off: size, phys: end + int64(len(out)), len: l}
+		out = binary.BigEndian.AppendUint32(out, uint32(l+encryptionOverhead))
+		if out, err = jf.key.seal(out, data[:l], frameAAD(jf.key.id, size)); err != nil {
+			return 0, err
+		}
+		frames = append(frames, frame)
+		size += int64(l)
+		data = data[l:]
+	}
+	// terminate the frames so that stale frames after them are never read
+	if _, err = jf.f.WriteAt(append(out, 0, 0, 0, 0), end); err != nil {
+		return 0, err
+	}
+
+	jf.cacheMu.Lock()
+	jf.cachedIdx, jf.cachedData = -1, nil
+	jf.cacheMu.Unlock()
+	jf.frames, jf.size, jf.end = frames, size, end+int64(len(out))
+	return len(p), nil
+}
+
+// Read implements io.Reader.
+func (jf *encryptedJournalFile) Read(p []byte) (int, error) {
+	n, err := jf.ReadAt(p, jf.pos)
+	jf.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (jf *encryptedJournalFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += jf.pos
+	case io.SeekEnd:
+		offset += jf.Size()
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	jf.pos = offset
+	return offset, nil
+}
+
+// Size implements StorageFileReader.
+func (jf *encryptedJournalFile) Size() int64 {
+	jf.mu.RLock()
+	defer jf.mu.RUnlock()
+	return jf.size
+}
+
+// Sync implements journalFile.
+func (jf *encryptedJournalFile) Sync() error {
+	return jf.f.Sync()
+}
+
+// Close implements io.Closer.
+func (jf *encryptedJournalFile) Close() error {
+	return jf.f.Close()
+}
+
+// journalFileKeyID returns the id of the key |f| is encrypted with, or false if it isn't encrypted.
+func journalFileKeyID(f journalFile) (encryptionKeyID, bool) {
+	if jf, ok := f.(*encryptedJournalFile); ok {
+		return jf.key.id, true
+	}
+	return encryptionKeyID{}, false
+}
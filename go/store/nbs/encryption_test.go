@@ -0,0 +1,435 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/file"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func makeTestEncryptionKey(t *testing.T) []byte {
+	raw := make([]byte, EncryptionKeySize)
+	_, err := rand.Read(raw)
+	require.NoError(t, err)
+	return raw
+}
+
+func makeTestEncryptionKeys(t *testing.T) *EncryptionKeys {
+	keys, err := NewEncryptionKeys(makeTestEncryptionKey(t))
+	require.NoError(t, err)
+	return keys
+}
+
+func requireEncryptedWith(t *testing.T, path string, keys *EncryptionKeys) {
+	ok, err := isEncryptedWithKey(path, keys)
+	require.NoError(t, err)
+	require.True(t, ok, "%s is not encrypted with key %s", path, keys.ActiveKeyID())
+}
+
+func TestParseEncryptionKeys(t *testing.T) {
+	raw := bytes.Repeat([]byte{7}, EncryptionKeySize)
+	hexKeys, err := ParseEncryptionKeys(hex.EncodeToString(raw))
+	require.NoError(t, err)
+	b64Keys, err := ParseEncryptionKeys("  AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n")
+	require.NoError(t, err)
+	assert.NotEqual(t, hexKeys.ActiveKeyID(), b64Keys.ActiveKeyID())
+
+	rotated, err := ParseEncryptionKeys("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=, " + hex.EncodeToString(raw))
+	require.NoError(t, err)
+	assert.Equal(t, b64Keys.ActiveKeyID(), rotated.ActiveKeyID())
+	assert.Len(t, rotated.keys, 2)
+
+	_, err = ParseEncryptionKeys("")
+	assert.Error(t, err)
+	_, err = ParseEncryptionKeys("abcd")
+	assert.Error(t, err)
+}
+
+func TestEncryptedBlockRoundTrip(t *testing.T) {
+	keys := makeTestEncryptionKeys(t)
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+
+	for _, sz := range []int{0, 1, encryptedBlockSize - 1, encryptedBlockSize, encryptedBlockSize + 1, 5*encryptedBlockSize + 17} {
+		data := make([]byte, sz)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		path := filepath.Join(dir, "blocks")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, writeStorageFile(f, bytes.NewReader(data), keys))
+		requireEncryptedWith(t, path, keys)
+
+		r, err := OpenStorageFile(path, keys)
+		require.NoError(t, err)
+		require.Equal(t, int64(sz), r.Size())
+		all, err := io.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+		require.NoError(t, err)
+		assert.Equal(t, data, all)
+		if sz > 10 {
+			buf := make([]byte, 10)
+			_, err = r.ReadAt(buf, int64(sz-10))
+			require.NoError(t, err)
+			assert.Equal(t, data[sz-10:], buf)
+		}
+		require.NoError(t, r.Close())
+
+		_, err = OpenStorageFile(path, nil)
+		assert.ErrorIs(t, err, ErrNoEncryptionKey)
+		_, err = OpenStorageFile(path, makeTestEncryptionKeys(t))
+		assert.ErrorIs(t, err, ErrUnknownEncryptionKey)
+	}
+
+	t.Run("truncation is detected", func(t *testing.T) {
+		data := make([]byte, 3*encryptedBlockSize)
+		path := filepath.Join(dir, "truncated")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, writeStorageFile(f, bytes.NewReader(data), keys))
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(path, fi.Size()-(encryptedBlockSize+encryptionOverhead)))
+
+		r, err := OpenStorageFile(path, keys)
+		require.NoError(t, err)
+		defer r.Close()
+		_, err = io.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+	})
+}
+
+func TestEncryptedJournalWriterBootstrap(t *testing.T) {
+	ctx := context.Background()
+	keys := makeTestEncryptionKeys(t)
+	path := newTestFilePath(t)
+
+	j, err := createJournalWriter(ctx, path, keys)
+	require.NoError(t, err)
+	_, err = j.bootstrapJournal(ctx, nil)
+	require.NoError(t, err)
+	data := randomCompressedChunks(1024)
+	var last hash.Hash
+	for _, cc := range data {
+		require.NoError(t, j.writeCompressedChunk(ctx, cc))
+		last = cc.Hash()
+	}
+	require.NoError(t, j.commitRootHash(ctx, last))
+	require.NoError(t, j.Close())
+
+	// chunk data must not be stored in plaintext
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, cc := range data {
+		require.False(t, bytes.Contains(raw, cc.FullCompressedChunk))
+		break
+	}
+
+	_, _, err = openJournalWriter(ctx, path, nil)
+	require.ErrorIs(t, err, ErrNoEncryptionKey)
+
+	j, ok, err := openJournalWriter(ctx, path, keys)
+	require.NoError(t, err)
+	require.True(t, ok)
+	root, err := j.bootstrapJournal(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, last, root)
+	validateAllLookups(t, j, data)
+	require.NoError(t, j.Close())
+}
+
+func TestEncryptedJournalRecovery(t *testing.T) {
+	ctx := context.Background()
+	keys := makeTestEncryptionKeys(t)
+	path := newTestFilePath(t)
+
+	j, err := createJournalWriter(ctx, path, keys)
+	require.NoError(t, err)
+	_, err = j.bootstrapJournal(ctx, nil)
+	require.NoError(t, err)
+	committed := randomCompressedChunks(64)
+	var root hash.Hash
+	for _, cc := range committed {
+		require.NoError(t, j.writeCompressedChunk(ctx, cc))
+		root = cc.Hash()
+	}
+	require.NoError(t, j.commitRootHash(ctx, root))
+	require.NoError(t, j.Close())
+
+	// simulate a write torn by a crash by appending a partial frame
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	f, err := os.OpenFile(path, os.O_RDWR, 0666)
+	require.NoError(t, err)
+	torn := []byte{0, 0, 1, 0}
+	torn = append(torn, bytes.Repeat([]byte{0xab}, 100)...)
+	_, err = f.WriteAt(torn, fi.Size()-4)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	j, ok, err := openJournalWriter(ctx, path, keys)
+	require.NoError(t, err)
+	require.True(t, ok)
+	recovered, err := j.bootstrapJournal(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, root, recovered)
+	validateAllLookups(t, j, committed)
+
+	// the journal remains writable after recovery
+	more := randomCompressedChunks(64)
+	for _, cc := range more {
+		require.NoError(t, j.writeCompressedChunk(ctx, cc))
+		root = cc.Hash()
+	}
+	require.NoError(t, j.commitRootHash(ctx, root))
+	require.NoError(t, j.Close())
+
+	j, _, err = openJournalWriter(ctx, path, keys)
+	require.NoError(t, err)
+	recovered, err = j.bootstrapJournal(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, root, recovered)
+	for h, cc := range more {
+		committed[h] = cc
+	}
+	validateAllLookups(t, j, committed)
+	require.NoError(t, j.Close())
+}
+
+func TestEncryptedFSTablePersisterConjoinAll(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	keys := makeTestEncryptionKeys(t)
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+	fts := newFSTablePersister(dir, &UnlimitedQuotaProvider{}, keys)
+
+	sources := make(chunkSources, len(testChunks))
+	for i, c := range testChunks {
+		randChunk := make([]byte, (i+1)*13)
+		_, err := rand.Read(randChunk)
+		require.NoError(t, err)
+		sources[i], err = persistTableData(fts, c, randChunk)
+		require.NoError(t, err)
+		requireEncryptedWith(t, filepath.Join(dir, sources[i].hash().String()), keys)
+	}
+	defer func() {
+		for _, s := range sources {
+			s.close()
+		}
+	}()
+
+	src, _, err := fts.ConjoinAll(ctx, sources, &Stats{})
+	require.NoError(t, err)
+	defer src.close()
+	requireEncryptedWith(t, filepath.Join(dir, src.hash().String()), keys)
+	assertChunksInReader(testChunks, src, assert)
+
+	reopened, err := fts.Open(ctx, src.hash(), mustUint32(src.count()), &Stats{})
+	require.NoError(t, err)
+	defer reopened.close()
+	assertChunksInReader(testChunks, reopened, assert)
+
+	// table files are exchanged with remotes decrypted, under the name of their plaintext
+	r, sz, err := reopened.reader(ctx)
+	require.NoError(t, err)
+	defer r.Close()
+	buf, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, sz, uint64(len(buf)))
+	ti, err := parseTableIndexByCopy(ctx, buf, &UnlimitedQuotaProvider{})
+	require.NoError(t, err)
+	tr, err := newTableReader(ti, tableReaderAtFromBytes(buf), fileBlockSize)
+	require.NoError(t, err)
+	defer tr.close()
+	assertChunksInReader(testChunks, tr, assert)
+}
+
+func makeTestEncryptedLocalStore(t *testing.T, dir string, keys *EncryptionKeys) *NomsBlockStore {
+	st, err := newLocalStore(context.Background(), types.Format_Default.VersionString(), dir, defaultMemTableSize, 8, NewUnlimitedMemQuotaProvider(), keys)
+	require.NoError(t, err)
+	return st
+}
+
+func TestEncryptedNBSCopyGC(t *testing.T) {
+	ctx := context.Background()
+	keys := makeTestEncryptionKeys(t)
+	_, dir, _ := makeTestLocalStore(t, 8)
+	st := makeTestEncryptedLocalStore(t, dir, keys)
+	defer st.Close()
+
+	keepers := makeChunkSet(64, 64)
+	tossers := makeChunkSet(64, 64)
+	for _, set := range []map[hash.Hash]chunks.Chunk{keepers, tossers} {
+		for _, c := range set {
+			require.NoError(t, st.Put(ctx, c, noopGetAddrs))
+		}
+	}
+	r, err := st.Root(ctx)
+	require.NoError(t, err)
+	ok, err := st.Commit(ctx, r, r)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	keepChan := make(chan []hash.Hash, 16)
+	var msErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, st.BeginGC(nil))
+		msErr = st.MarkAndSweepChunks(ctx, keepChan, nil)
+		st.EndGC()
+	}()
+	for h := range keepers {
+		keepChan <- []hash.Hash{h}
+	}
+	close(keepChan)
+	wg.Wait()
+	require.NoError(t, msErr)
+
+	for name := range st.tables.upstream {
+		requireEncryptedWith(t, filepath.Join(dir, name.String()), keys)
+	}
+	for h, c := range keepers {
+		out, err := st.Get(ctx, h)
+		require.NoError(t, err)
+		assert.Equal(t, c, out)
+	}
+	for h := range tossers {
+		out, err := st.Get(ctx, h)
+		require.NoError(t, err)
+		assert.Equal(t, chunks.EmptyChunk, out)
+	}
+}
+
+func TestEncryptedNBSRekey(t *testing.T) {
+	ctx := context.Background()
+	_, dir, _ := makeTestLocalStore(t, 8)
+
+	// write unencrypted table files
+	st := makeTestEncryptedLocalStore(t, dir, nil)
+	data := makeChunkSet(64, 64)
+	for _, c := range data {
+		require.NoError(t, st.Put(ctx, c, noopGetAddrs))
+	}
+	r, err := st.Root(ctx)
+	require.NoError(t, err)
+	ok, err := st.Commit(ctx, r, r)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, st.Close())
+
+	first, second := makeTestEncryptionKey(t), makeTestEncryptionKey(t)
+	encrypt, err := NewEncryptionKeys(first)
+	require.NoError(t, err)
+	rotate, err := NewEncryptionKeys(second, first)
+	require.NoError(t, err)
+
+	for _, keys := range []*EncryptionKeys{encrypt, rotate} {
+		st = makeTestEncryptedLocalStore(t, dir, keys)
+		n, err := st.Rekey(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, len(st.tables.upstream), n)
+		for name := range st.tables.upstream {
+			requireEncryptedWith(t, filepath.Join(dir, name.String()), keys)
+		}
+		n, err = st.Rekey(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		require.NoError(t, st.Close())
+	}
+
+	// only the active key is needed once the store is rekeyed
+	active, err := NewEncryptionKeys(second)
+	require.NoError(t, err)
+	st = makeTestEncryptedLocalStore(t, dir, active)
+	defer st.Close()
+	for h, c := range data {
+		out, err := st.Get(ctx, h)
+		require.NoError(t, err)
+		assert.Equal(t, c, out)
+	}
+}
+
+func TestEncryptedArchiveConversion(t *testing.T) {
+	ctx := context.Background()
+	keys := makeTestEncryptionKeys(t)
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+	fts := newFSTablePersister(dir, &UnlimitedQuotaProvider{}, keys)
+
+	var data [][]byte
+	for i := 0; i < 200; i++ {
+		data = append(data, generateRandomBytes(int64(i), 256))
+	}
+	mt := newMemTable(1 << 20)
+	for _, d := range data {
+		require.Equal(t, chunkAdded, mt.addChunk(computeAddr(d), d))
+	}
+	src, err := fts.Persist(ctx, mt, nil, &Stats{})
+	require.NoError(t, err)
+	defer src.close()
+	idx, err := src.index()
+	require.NoError(t, err)
+
+	progress := make(chan interface{})
+	go func() {
+		for range progress {
+		}
+	}()
+	defer close(progress)
+
+	relations := NewChunkRelations()
+	archivePath, name, err := convertTableFileToArchive(ctx, src, idx, &relations, dir, keys, progress, &Stats{})
+	require.NoError(t, err)
+	requireEncryptedWith(t, archivePath, keys)
+	require.NoError(t, verifyAllChunks(idx, archivePath, keys, progress))
+
+	// no unencrypted copy of the archive is left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		requireEncryptedWith(t, filepath.Join(dir, e.Name()), keys)
+	}
+
+	acs, err := newArchiveChunkSource(ctx, dir, name, idx.chunkCount(), &UnlimitedQuotaProvider{}, keys)
+	require.NoError(t, err)
+	defer acs.close()
+	for _, d := range data {
+		h := computeAddr(d)
+		out, err := acs.get(ctx, h, &Stats{})
+		require.NoError(t, err)
+		assert.Equal(t, d, out)
+	}
+
+	_, err = newArchiveChunkSource(ctx, dir, name, idx.chunkCount(), &UnlimitedQuotaProvider{}, nil)
+	assert.ErrorIs(t, err, ErrNoEncryptionKey)
+}
@@ -42,13 +42,15 @@ import (
 
 const tempTablePrefix = "nbs_table_"
 
-func newFSTablePersister(dir string, q MemoryQuotaProvider) tablePersister {
-	return &fsTablePersister{dir, q, sync.Mutex{}, nil, make(map[string]struct{})}
+func newFSTablePersister(dir string, q MemoryQuotaProvider, keys *EncryptionKeys) tablePersister {
+	return &fsTablePersister{dir, q, keys, sync.Mutex{}, nil, make(map[string]struct{})}
 }
 
 type fsTablePersister struct {
 	dir string
 	q   MemoryQuotaProvider
+	// keys encrypt the table files written by the persister, if non-nil.
+	keys *EncryptionKeys
 
 	// Protects the following two maps.
 	removeMu sync.Mutex
@@ -69,7 +71,7 @@ var _ tablePersister = &fsTablePersister{}
 var _ tableFilePersister = &fsTablePersister{}
 
 func (ftp *fsTablePersister) Open(ctx context.Context, name hash.Hash, chunkCount uint32, stats *Stats) (chunkSource, error) {
-	return newFileTableReader(ctx, ftp.dir, name, chunkCount, ftp.q)
+	return newFileTableReader(ctx, ftp.dir, name, chunkCount, ftp.q, ftp.keys)
 }
 
 func (ftp *fsTablePersister) Exists(ctx context.Context, name hash.Hash, chunkCount uint32, stats *Stats) (bool, error) {
@@ -127,7 +129,18 @@ func (ftp *fsTablePersister) CopyTableFile(ctx context.Context, r io.Reader, fil
 			}
 		}()
 
-		_, err = io.Copy(temp, r)
+		var w io.WriteCloser
+		w, err = newStorageFileWriter(temp, ftp.keys)
+		if err != nil {
+			return "", cleanup, err
+		}
+
+		_, err = io.Copy(w, r)
+		if err != nil {
+			return "", cleanup, err
+		}
+
+		err = w.Close()
 		if err != nil {
 			return "", cleanup, err
 		}
@@ -154,6 +167,11 @@ func (ftp *fsTablePersister) CopyTableFile(ctx context.Context, r io.Reader, fil
 }
 
 func (ftp *fsTablePersister) TryMoveCmpChunkTableWriter(ctx context.Context, filename string, w *CmpChunkTableWriter) error {
+	if ftp.keys != nil {
+		// |w| can't be moved into place since its file is not encrypted
+		return ftp.copyCmpChunkTableWriter(ctx, filename, w)
+	}
+
 	path := filepath.Join(ftp.dir, filename)
 	ftp.removeMu.Lock()
 	if ftp.toKeep != nil {
@@ -163,6 +181,53 @@ func (ftp *fsTablePersister) TryMoveCmpChunkTableWriter(ctx context.Context, fil
 	return w.FlushToFile(path)
 }
 
+func (ftp *fsTablePersister) copyCmpChunkTableWriter(ctx context.Context, filename string, w *CmpChunkTableWriter) error {
+	r, err := w.Reader()
+	if err != nil {
+		return err
+	}
+	err = ftp.CopyTableFile(ctx, r, filename, w.ContentLength(), uint32(w.ChunkCount()))
+	if cerr := r.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return w.Remove()
+}
+
+// rekeyTableFile rewrites the table file or archive |name| encrypted with the persister's active encryption key, if
+// it isn't already encrypted with it. Returns whether the file was rewritten.
+func (ftp *fsTablePersister) rekeyTableFile(ctx context.Context, name hash.Hash) (bool, error) {
+	if ftp.keys == nil {
+		return false, ErrNoEncryptionKey
+	}
+
+	fileId := name.String()
+	if ok, err := tableFileExists(ctx, ftp.dir, name); err != nil {
+		return false, err
+	} else if !ok {
+		fileId += archiveFileSuffix
+	}
+	path := filepath.Join(ftp.dir, fileId)
+
+	if ok, err := isEncryptedWithKey(path, ftp.keys); err != nil || ok {
+		return false, err
+	}
+
+	r, err := OpenStorageFile(path, ftp.keys)
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	err = ftp.CopyTableFile(ctx, io.NewSectionReader(r, 0, r.Size()), fileId, uint64(r.Size()), 0)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (ftp *fsTablePersister) persistTable(ctx context.Context, name hash.Hash, data []byte, chunkCount uint32, stats *Stats) (cs chunkSource, err error) {
 	if chunkCount == 0 {
 		return emptyChunkSource{}, nil
@@ -192,7 +257,18 @@ func (ftp *fsTablePersister) persistTable(ctx context.Context, name hash.Hash, d
 			}
 		}()
 
-		_, ferr = io.Copy(temp, bytes.NewReader(data))
+		var w io.WriteCloser
+		w, ferr = newStorageFileWriter(temp, ftp.keys)
+		if ferr != nil {
+			return "", cleanup, ferr
+		}
+
+		_, ferr = io.Copy(w, bytes.NewReader(data))
+		if ferr != nil {
+			return "", cleanup, ferr
+		}
+
+		ferr = w.Close()
 		if ferr != nil {
 			return "", cleanup, ferr
 		}
@@ -258,6 +334,12 @@ func (ftp *fsTablePersister) ConjoinAll(ctx context.Context, sources chunkSource
 			}
 		}()
 
+		var w io.WriteCloser
+		w, ferr = newStorageFileWriter(temp, ftp.keys)
+		if ferr != nil {
+			return "", cleanup, ferr
+		}
+
 		for _, sws := range plan.sources.sws {
 			var r io.ReadCloser
 			r, _, ferr = sws.source.reader(ctx)
@@ -265,7 +347,7 @@ func (ftp *fsTablePersister) ConjoinAll(ctx context.Context, sources chunkSource
 				return "", cleanup, ferr
 			}
 
-			n, ferr := io.CopyN(temp, r, int64(sws.dataLen))
+			n, ferr := io.CopyN(w, r, int64(sws.dataLen))
 			if ferr != nil {
 				r.Close()
 				return "", cleanup, ferr
@@ -282,8 +364,13 @@ func (ftp *fsTablePersister) ConjoinAll(ctx context.Context, sources chunkSource
 			}
 		}
 
-		_, ferr = temp.Write(plan.mergedIndex)
+		_, ferr = w.Write(plan.mergedIndex)
+
+		if ferr != nil {
+			return "", cleanup, ferr
+		}
 
+		ferr = w.Close()
 		if ferr != nil {
 			return "", cleanup, ferr
 		}
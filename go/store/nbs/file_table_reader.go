@@ -67,52 +67,45 @@ func archiveFileExists(ctx context.Context, dir string, h hash.Hash) (bool, erro
 	return err == nil, err
 }
 
-func newFileTableReader(ctx context.Context, dir string, h hash.Hash, chunkCount uint32, q MemoryQuotaProvider) (cs chunkSource, err error) {
+func newFileTableReader(ctx context.Context, dir string, h hash.Hash, chunkCount uint32, q MemoryQuotaProvider, keys *EncryptionKeys) (cs chunkSource, err error) {
 	// we either have a table file or an archive file
 	tfExists, err := tableFileExists(ctx, dir, h)
 	if err != nil {
 		return nil, err
 	} else if tfExists {
-		return nomsFileTableReader(ctx, filepath.Join(dir, h.String()), h, chunkCount, q)
+		return nomsFileTableReader(ctx, filepath.Join(dir, h.String()), h, chunkCount, q, keys)
 	}
 
 	afExists, err := archiveFileExists(ctx, dir, h)
 	if err != nil {
 		return nil, err
 	} else if afExists {
-		return newArchiveChunkSource(ctx, dir, h, chunkCount, q)
+		return newArchiveChunkSource(ctx, dir, h, chunkCount, q, keys)
 	}
 	return nil, errors.New(fmt.Sprintf("table file %s/%s not found", dir, h.String()))
 }
 
-func nomsFileTableReader(ctx context.Context, path string, h hash.Hash, chunkCount uint32, q MemoryQuotaProvider) (cs chunkSource, err error) {
-	var f *os.File
+func nomsFileTableReader(ctx context.Context, path string, h hash.Hash, chunkCount uint32, q MemoryQuotaProvider, keys *EncryptionKeys) (cs chunkSource, err error) {
+	var f StorageFileReader
 	index, sz, err := func() (ti onHeapTableIndex, sz int64, err error) {
 		// Be careful with how |f| is used below. |RefFile| returns a cached
 		// os.File pointer so the code needs to use f in a concurrency-safe
 		// manner. Moving the file offset is BAD.
-		f, err = os.Open(path)
+		f, err = OpenStorageFile(path, keys)
 		if err != nil {
 			return
 		}
 
 		// Since we can't move the file offset, get the size of the file and use
 		// ReadAt to load the index instead.
-		var fi os.FileInfo
-		fi, err = f.Stat()
-
-		if err != nil {
-			return
-		}
-
-		if fi.Size() < 0 {
+		if f.Size() < 0 {
 			// Size returns the number of bytes for regular files and is system dependent for others (Some of which can be negative).
-			err = fmt.Errorf("%s has invalid size: %d", path, fi.Size())
+			err = fmt.Errorf("%s has invalid size: %d", path, f.Size())
 			return
 		}
 
 		idxSz := int64(indexSize(chunkCount) + footerSize)
-		sz = fi.Size()
+		sz = f.Size()
 		indexOffset := sz - idxSz
 		r := io.NewSectionReader(f, indexOffset, idxSz)
 
@@ -154,7 +147,7 @@ func nomsFileTableReader(ctx context.Context, path string, h hash.Hash, chunkCou
 		return nil, errors.New("unexpected chunk count")
 	}
 
-	tr, err := newTableReader(index, &fileReaderAt{f, path, sz}, fileBlockSize)
+	tr, err := newTableReader(index, &fileReaderAt{f, path, sz, keys}, fileBlockSize)
 	if err != nil {
 		index.Close()
 		f.Close()
@@ -226,13 +219,14 @@ func (ftr *fileTableReader) clone() (chunkSource, error) {
 }
 
 type fileReaderAt struct {
-	f    *os.File
+	f    StorageFileReader
 	path string
 	sz   int64
+	keys *EncryptionKeys
 }
 
 func (fra *fileReaderAt) clone() (tableReaderAt, error) {
-	f, err := os.Open(fra.path)
+	f, err := OpenStorageFile(fra.path, fra.keys)
 	if err != nil {
 		return nil, err
 	}
@@ -240,6 +234,7 @@ func (fra *fileReaderAt) clone() (tableReaderAt, error) {
 		f,
 		fra.path,
 		fra.sz,
+		fra.keys,
 	}, nil
 }
 
@@ -248,10 +243,16 @@ func (fra *fileReaderAt) Close() error {
 }
 
 func (fra *fileReaderAt) Reader(ctx context.Context) (io.ReadCloser, error) {
-	return os.Open(fra.path)
+	f, err := OpenStorageFile(fra.path, fra.keys)
+	if err != nil {
+		return nil, err
+	}
+	return storageFileSectionReader(f), nil
 }
 
 func (fra *fileReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off int64, stats *Stats) (n int, err error) {
+	_, span := startReadSpan(ctx, "nbs.fileReaderAt.ReadAt", fra.path, off, len(p))
+	defer span.End()
 	t1 := time.Now()
 	defer func() {
 		stats.FileBytesPerRead.Sample(uint64(len(p)))
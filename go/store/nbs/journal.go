@@ -144,7 +144,7 @@ func (j *ChunkJournal) bootstrapJournalWriter(ctx context.Context) (err error) {
 	}
 
 	if !ok { // create new journal file
-		j.wr, err = createJournalWriter(ctx, j.path)
+		j.wr, err = createJournalWriter(ctx, j.path, j.persister.keys)
 		if err != nil {
 			return err
 		}
@@ -169,7 +169,7 @@ func (j *ChunkJournal) bootstrapJournalWriter(ctx context.Context) (err error) {
 		return
 	}
 
-	j.wr, ok, err = openJournalWriter(ctx, j.path)
+	j.wr, ok, err = openJournalWriter(ctx, j.path, j.persister.keys)
 	if err != nil {
 		return err
 	} else if !ok {
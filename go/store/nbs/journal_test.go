@@ -38,7 +38,7 @@ func makeTestChunkJournal(t *testing.T) *ChunkJournal {
 	m, err := newJournalManifest(ctx, dir)
 	require.NoError(t, err)
 	q := NewUnlimitedMemQuotaProvider()
-	p := newFSTablePersister(dir, q)
+	p := newFSTablePersister(dir, q, nil)
 	nbf := types.Format_Default.VersionString()
 	j, err := newChunkJournal(ctx, nbf, dir, m, p.(*fsTablePersister))
 	require.NoError(t, err)
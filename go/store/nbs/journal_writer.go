@@ -30,8 +30,10 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/dolthub/dolt/go/libraries/utils/file"
 	"github.com/dolthub/dolt/go/store/chunks"
 	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/util/tempfiles"
 )
 
 const (
@@ -77,7 +79,7 @@ func fileExists(path string) (bool, error) {
 	return true, nil
 }
 
-func openJournalWriter(ctx context.Context, path string) (wr *journalWriter, exists bool, err error) {
+func openJournalWriter(ctx context.Context, path string, keys *EncryptionKeys) (wr *journalWriter, exists bool, err error) {
 	var f *os.File
 	if path, err = filepath.Abs(path); err != nil {
 		return nil, false, err
@@ -94,15 +96,39 @@ func openJournalWriter(ctx context.Context, path string) (wr *journalWriter, exi
 	if f, err = os.OpenFile(path, os.O_RDWR, 0666); err != nil {
 		return nil, true, err
 	}
+	journal, err := openJournalFile(f, path, keys)
+	if err != nil {
+		f.Close()
+		return nil, true, err
+	}
 
 	return &journalWriter{
 		buf:     make([]byte, 0, journalWriterBuffSize),
-		journal: f,
+		journal: journal,
 		path:    path,
+		keys:    keys,
 	}, true, nil
 }
 
-func createJournalWriter(ctx context.Context, path string) (wr *journalWriter, err error) {
+// openJournalFile returns the journalFile of the existing chunk journal |f|, which is encrypted if the journal was
+// created with encryption enabled.
+func openJournalFile(f *os.File, path string, keys *EncryptionKeys) (journalFile, error) {
+	h, ok, err := readEncryptionHeader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	} else if !ok {
+		return f, nil
+	} else if h.format != encryptedFramesFormat {
+		return nil, fmt.Errorf("%s is not an encrypted chunk journal", path)
+	}
+	key, err := keys.lookup(path, h.keyID)
+	if err != nil {
+		return nil, err
+	}
+	return openEncryptedJournalFile(f, key)
+}
+
+func createJournalWriter(ctx context.Context, path string, keys *EncryptionKeys) (wr *journalWriter, err error) {
 	var f *os.File
 	if path, err = filepath.Abs(path); err != nil {
 		return nil, err
@@ -134,10 +160,21 @@ func createJournalWriter(ctx context.Context, path string) (wr *journalWriter, e
 		return nil, fmt.Errorf("expected file journalOffset 0, got %d", o)
 	}
 
+	var journal journalFile = f
+	if keys != nil {
+		if journal, err = createEncryptedJournalFile(f, keys.active); err != nil {
+			return nil, err
+		}
+		if err = f.Sync(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &journalWriter{
 		buf:     make([]byte, 0, journalWriterBuffSize),
-		journal: f,
+		journal: journal,
 		path:    path,
+		keys:    keys,
 	}, nil
 }
 
@@ -152,7 +189,9 @@ func deleteJournalAndIndexFiles(ctx context.Context, path string) (err error) {
 type journalWriter struct {
 	buf []byte
 
-	journal *os.File
+	journal journalFile
+	// keys encrypt new journal files, if non-nil
+	keys *EncryptionKeys
 	// off indicates the last position that has been written to the journal buffer
 	off     int64
 	indexed int64
@@ -592,18 +631,73 @@ func (wr *journalWriter) snapshot(ctx context.Context) (io.ReadCloser, int64, er
 	}
 	// open a new file descriptor with an
 	// independent lifecycle from |wr.file|
-	f, err := os.Open(wr.path)
+	f, err := OpenStorageFile(wr.path, wr.keys)
 	if err != nil {
 		return nil, 0, err
 	}
 	return journalWriterSnapshot{
-		io.LimitReader(f, wr.off),
+		io.NewSectionReader(f, 0, wr.off),
 		func() error {
 			return f.Close()
 		},
 	}, wr.off, nil
 }
 
+// rekey rewrites the journal file encrypted with the active key of |wr.keys|, if it isn't already encrypted with
+// it. The logical offsets of the journal's records are unchanged, so its index remains valid. Returns whether the
+// journal was rewritten.
+func (wr *journalWriter) rekey(ctx context.Context) (bool, error) {
+	wr.lock.Lock()
+	defer wr.lock.Unlock()
+	if wr.keys == nil {
+		return false, ErrNoEncryptionKey
+	} else if id, ok := journalFileKeyID(wr.journal); ok && id == wr.keys.active.id {
+		return false, nil
+	}
+	if err := wr.flush(ctx); err != nil {
+		return false, err
+	}
+
+	tmp, err := tempfiles.MovableTempFileProvider.NewFile(filepath.Dir(wr.path), tempTablePrefix)
+	if err != nil {
+		return false, err
+	}
+	journal, err := createEncryptedJournalFile(tmp, wr.keys.active)
+	if err != nil {
+		tmp.Close()
+		return false, err
+	}
+	err = func() error {
+		buf := make([]byte, journalWriterBuffSize)
+		for off := int64(0); off < wr.off; {
+			n := int64(len(buf))
+			if n > wr.off-off {
+				n = wr.off - off
+			}
+			if _, err := wr.journal.ReadAt(buf[:n], off); err != nil {
+				return err
+			}
+			if _, err := journal.WriteAt(buf[:n], off); err != nil {
+				return err
+			}
+			off += n
+		}
+		if err := journal.Sync(); err != nil {
+			return err
+		}
+		return file.Rename(tmp.Name(), wr.path)
+	}()
+	if err != nil {
+		journal.Close()
+		file.Remove(tmp.Name())
+		return false, err
+	}
+
+	prev := wr.journal
+	wr.journal = journal
+	return true, prev.Close()
+}
+
 func (wr *journalWriter) offset() int64 {
 	return wr.off + int64(len(wr.buf))
 }
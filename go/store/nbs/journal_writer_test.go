@@ -182,7 +182,7 @@ func TestJournalWriterReadWrite(t *testing.T) {
 
 func newTestJournalWriter(t *testing.T, path string) *journalWriter {
 	ctx := context.Background()
-	j, err := createJournalWriter(ctx, path)
+	j, err := createJournalWriter(ctx, path, nil)
 	require.NoError(t, err)
 	require.NotNil(t, j)
 	_, err = j.bootstrapJournal(ctx, nil)
@@ -217,7 +217,7 @@ func TestJournalWriterBootstrap(t *testing.T) {
 	require.NoError(t, j.commitRootHash(context.Background(), last))
 	require.NoError(t, j.Close())
 
-	j, _, err := openJournalWriter(ctx, path)
+	j, _, err := openJournalWriter(ctx, path, nil)
 	require.NoError(t, err)
 	reflogBuffer := newReflogRingBuffer(10)
 	last, err = j.bootstrapJournal(ctx, reflogBuffer)
@@ -357,7 +357,7 @@ func TestJournalIndexBootstrap(t *testing.T) {
 			require.NoError(t, err)
 
 			validateJournal := func(p string, expected []epoch) {
-				journal, ok, err := openJournalWriter(ctx, p)
+				journal, ok, err := openJournalWriter(ctx, p, nil)
 				require.NoError(t, err)
 				require.True(t, ok)
 				// bootstrap journal and validate chunk records
@@ -393,7 +393,7 @@ func TestJournalIndexBootstrap(t *testing.T) {
 
 			// bootstrap journal with corrupted index
 			corruptJournalIndex(t, idxPath)
-			jnl, ok, err := openJournalWriter(ctx, idxPath)
+			jnl, ok, err := openJournalWriter(ctx, idxPath, nil)
 			require.NoError(t, err)
 			require.True(t, ok)
 			_, err = jnl.bootstrapJournal(ctx, nil)
@@ -111,6 +111,11 @@ func GetStorageMetadata(path string) (StorageMetadata, error) {
 		return StorageMetadata{}, err
 	}
 
+	keys, err := EncryptionKeysFromEnv()
+	if err != nil {
+		return StorageMetadata{}, err
+	}
+
 	var artifacts []StorageArtifact
 
 	// for each table in the manifest, get the table spec
@@ -134,7 +139,7 @@ func GetStorageMetadata(path string) (StorageMetadata, error) {
 			_, err := os.Stat(arcPath)
 			if err == nil {
 				// reader for the path. State. call
-				reader, fileSize, err := openReader(arcPath)
+				reader, fileSize, err := openReader(arcPath, keys)
 				if err != nil {
 					return StorageMetadata{}, err
 				}
@@ -0,0 +1,103 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// ErrEncryptionUnsupported is returned when rekeying a store that isn't backed by local files.
+var ErrEncryptionUnsupported = errors.New("encryption is only supported for local storage")
+
+// encryptionKeys returns the keys the store's files are encrypted with, or nil if it isn't encrypted.
+func (nbs *NomsBlockStore) encryptionKeys() *EncryptionKeys {
+	switch p := nbs.p.(type) {
+	case *fsTablePersister:
+		return p.keys
+	case *ChunkJournal:
+		return p.persister.keys
+	default:
+		return nil
+	}
+}
+
+// Rekey rewrites every table file, archive and chunk journal of the store that isn't encrypted with the active key of
+// the store's encryption keys, encrypting it with the active key. Files are rewritten in place under the same names,
+// so the store's manifest is unchanged. Previous keys can be removed from the store's configuration once it has been
+// rekeyed. Returns the number of files rewritten.
+func (nbs *NomsBlockStore) Rekey(ctx context.Context) (int, error) {
+	nbs.mu.Lock()
+	defer nbs.mu.Unlock()
+
+	var ftp *fsTablePersister
+	var journal *ChunkJournal
+	switch p := nbs.p.(type) {
+	case *fsTablePersister:
+		ftp = p
+	case *ChunkJournal:
+		ftp, journal = p.persister, p
+	default:
+		return 0, ErrEncryptionUnsupported
+	}
+	if ftp.keys == nil {
+		return 0, ErrNoEncryptionKey
+	}
+
+	names := make([]hash.Hash, 0, len(nbs.tables.upstream)+len(nbs.tables.novel))
+	for name := range nbs.tables.upstream {
+		names = append(names, name)
+	}
+	for name := range nbs.tables.novel {
+		names = append(names, name)
+	}
+
+	rekeyed := 0
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return rekeyed, err
+		}
+
+		var ok bool
+		var err error
+		if isJournalAddr(name) {
+			if journal == nil || journal.wr == nil {
+				continue
+			}
+			ok, err = journal.wr.rekey(ctx)
+		} else {
+			ok, err = ftp.rekeyTableFile(ctx, name)
+		}
+		if err != nil {
+			return rekeyed, err
+		} else if ok {
+			rekeyed++
+		}
+	}
+	return rekeyed, nil
+}
+
+// Rekey rewrites the files of both generations of the store that aren't encrypted with the active encryption key.
+// See NomsBlockStore.Rekey.
+func (gcs *GenerationalNBS) Rekey(ctx context.Context) (int, error) {
+	oldRekeyed, err := gcs.oldGen.Rekey(ctx)
+	if err != nil {
+		return oldRekeyed, err
+	}
+	newRekeyed, err := gcs.newGen.Rekey(ctx)
+	return oldRekeyed + newRekeyed, err
+}
@@ -65,6 +65,8 @@ func (s3tra *s3TableReaderAt) Reader(ctx context.Context) (io.ReadCloser, error)
 }
 
 func (s3tra *s3TableReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off int64, stats *Stats) (n int, err error) {
+	ctx, span := startReadSpan(ctx, "nbs.s3TableReaderAt.ReadAt", s3tra.h.String(), off, len(p))
+	defer span.End()
 	return s3tra.s3.ReadAt(ctx, s3tra.h, p, off, stats)
 }
 
@@ -572,10 +572,17 @@ func NewNoConjoinBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blo
 }
 
 func NewLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, q MemoryQuotaProvider) (*NomsBlockStore, error) {
-	return newLocalStore(ctx, nbfVerStr, dir, memTableSize, defaultMaxTables, q)
+	return newLocalStore(ctx, nbfVerStr, dir, memTableSize, defaultMaxTables, q, nil)
 }
 
-func newLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, maxTables int, q MemoryQuotaProvider) (*NomsBlockStore, error) {
+// NewEncryptedLocalStore returns a local store whose table files are encrypted with the active key of |keys|. Table
+// files encrypted with any of |keys|, or not encrypted at all, can be read. If |keys| is nil, the store is not
+// encrypted.
+func NewEncryptedLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, q MemoryQuotaProvider, keys *EncryptionKeys) (*NomsBlockStore, error) {
+	return newLocalStore(ctx, nbfVerStr, dir, memTableSize, defaultMaxTables, q, keys)
+}
+
+func newLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, maxTables int, q MemoryQuotaProvider, keys *EncryptionKeys) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 	if err := checkDir(dir); err != nil {
 		return nil, err
@@ -591,13 +598,20 @@ func newLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSi
 	if err != nil {
 		return nil, err
 	}
-	p := newFSTablePersister(dir, q)
+	p := newFSTablePersister(dir, q, keys)
 	c := conjoinStrategy(inlineConjoiner{maxTables})
 
 	return newNomsBlockStore(ctx, nbfVerStr, makeManifestManager(m), p, q, c, memTableSize)
 }
 
 func NewLocalJournalingStore(ctx context.Context, nbfVers, dir string, q MemoryQuotaProvider) (*NomsBlockStore, error) {
+	return NewEncryptedLocalJournalingStore(ctx, nbfVers, dir, q, nil)
+}
+
+// NewEncryptedLocalJournalingStore returns a local journaling store whose table files and chunk journal are encrypted
+// with the active key of |keys|. An existing chunk journal keeps the encryption it was created with until it is
+// rewritten by garbage collection or Rekey. If |keys| is nil, the store is not encrypted.
+func NewEncryptedLocalJournalingStore(ctx context.Context, nbfVers, dir string, q MemoryQuotaProvider, keys *EncryptionKeys) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 	if err := checkDir(dir); err != nil {
 		return nil, err
@@ -607,7 +621,7 @@ func NewLocalJournalingStore(ctx context.Context, nbfVers, dir string, q MemoryQ
 	if err != nil {
 		return nil, err
 	}
-	p := newFSTablePersister(dir, q)
+	p := newFSTablePersister(dir, q, keys)
 
 	journal, err := newChunkJournal(ctx, nbfVers, dir, m, p.(*fsTablePersister))
 	if err != nil {
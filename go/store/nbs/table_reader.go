@@ -29,6 +29,8 @@ import (
 	"sort"
 
 	"github.com/golang/snappy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/dolthub/dolt/go/store/chunks"
@@ -136,6 +138,18 @@ type tableReaderAt interface {
 	clone() (tableReaderAt, error)
 }
 
+// startReadSpan starts the span of a read of |length| bytes at |off| of the table file |file|. Table files are read
+// too often for their reads to start traces of their own, so a span is only started within a trace being recorded.
+func startReadSpan(ctx context.Context, name, file string, off int64, length int) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("file", file),
+		attribute.Int64("offset", off),
+		attribute.Int("length", length)))
+}
+
 // tableReader implements get & has queries against a single nbs table. goroutine safe.
 // |blockSize| refers to the block-size of the underlying storage. We assume that, each
 // time we read data, we actually have to read in blocks of this size. So, we're willing
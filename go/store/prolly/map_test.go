@@ -265,6 +265,58 @@ func TestVisitMapLevelOrder(t *testing.T) {
 	}
 }
 
+func TestSplitKeys(t *testing.T) {
+	scales := []int{
+		20,
+		200,
+		2000,
+		20_000,
+	}
+	for _, s := range scales {
+		t.Run("scale "+strconv.Itoa(s), func(t *testing.T) {
+			ctx := context.Background()
+			tm, tuples := makeProllyMap(t, s)
+			m := tm.(Map)
+			for _, n := range []int{1, 2, 4, 16, 64} {
+				keys, err := m.SplitKeys(ctx, n)
+				require.NoError(t, err)
+				assert.LessOrEqual(t, len(keys), max(n-1, 0))
+				if m.Height() > 1 && n > 1 {
+					assert.NotEmpty(t, keys)
+				}
+				for i := 1; i < len(keys); i++ {
+					assert.True(t, m.KeyDesc().Compare(keys[i-1], keys[i]) < 0)
+				}
+
+				// the ranges between the keys cover the map in order
+				bounds := append(append([]val.Tuple{nil}, keys...), nil)
+				var idx int
+				for i := 1; i < len(bounds); i++ {
+					iter, err := m.IterKeyRange(ctx, bounds[i-1], bounds[i])
+					require.NoError(t, err)
+					start := idx
+					for {
+						k, v, err := iter.Next(ctx)
+						if err == io.EOF {
+							break
+						}
+						require.NoError(t, err)
+						require.Less(t, idx, len(tuples))
+						assert.Equal(t, tuples[idx][0], k)
+						assert.Equal(t, tuples[idx][1], v)
+						idx++
+					}
+					// ranges are balanced when the tree has enough subtrees
+					if len(keys) == n-1 && s >= 100*n {
+						assert.Less(t, idx-start, 2*s/n)
+					}
+				}
+				assert.Equal(t, len(tuples), idx)
+			}
+		})
+	}
+}
+
 func TestNewEmptyNode(t *testing.T) {
 	s := message.NewProllyMapSerializer(val.TupleDesc{}, sharedPool)
 	msg := s.Serialize(nil, nil, nil, 0)
@@ -477,6 +477,72 @@ func (t StaticMap[K, V, O]) getKeyRangeCursors(ctx context.Context, startInclusi
 	return
 }
 
+// SplitKeys returns up to |n|-1 keys which split the map into key ranges of
+// roughly equal size. The keys are taken from the internal nodes of the highest
+// level of the tree with at least |n| subtrees, so no leaf nodes are read. Each
+// key is the last key of a subtree, and is both the exclusive stop of one range
+// and the inclusive start of the next.
+func (t StaticMap[K, V, O]) SplitKeys(ctx context.Context, n int) ([]K, error) {
+	if n < 2 || t.Root.IsLeaf() {
+		return nil, nil
+	}
+
+	level := []Node{t.Root}
+	for {
+		children := 0
+		for _, nd := range level {
+			children += nd.Count()
+		}
+		if children >= n || level[0].Level() == 1 {
+			break
+		}
+		next := make([]Node, 0, children)
+		for _, nd := range level {
+			for i := 0; i < nd.Count(); i++ {
+				child, err := fetchChild(ctx, t.NodeStore, nd.getAddress(i))
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, child)
+			}
+		}
+		level = next
+	}
+
+	var total uint64
+	for i := range level {
+		nd, err := level[i].loadSubtrees()
+		if err != nil {
+			return nil, err
+		}
+		level[i] = nd
+		for j := 0; j < nd.Count(); j++ {
+			cnt, err := nd.getSubtreeCount(j)
+			if err != nil {
+				return nil, err
+			}
+			total += cnt
+		}
+	}
+
+	// the |i|th key closes the first subtree which reaches i/n of the rows
+	keys := make([]K, 0, n-1)
+	var seen uint64
+	for _, nd := range level {
+		for j := 0; j < nd.Count(); j++ {
+			cnt, err := nd.getSubtreeCount(j)
+			if err != nil {
+				return nil, err
+			}
+			seen += cnt
+			if len(keys) < n-1 && seen < total && seen*uint64(n) >= total*uint64(len(keys)+1) {
+				keys = append(keys, K(nd.GetKey(j)))
+			}
+		}
+	}
+	return keys, nil
+}
+
 // GetOrdinalForKey returns the smallest ordinal position at which the key >= |query|.
 func (t StaticMap[K, V, O]) GetOrdinalForKey(ctx context.Context, query K) (uint64, error) {
 	cur, err := newCursorAtKey(ctx, t.NodeStore, t.Root, query, t.Order)
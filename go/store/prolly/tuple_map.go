@@ -341,6 +341,15 @@ func (m Map) IterKeyRange(ctx context.Context, start, stop val.Tuple) (MapIter,
 	return m.tuples.IterKeyRange(ctx, start, stop)
 }
 
+// SplitKeys returns up to |n|-1 keys which split the map into key ranges of
+// roughly equal size, from the boundaries of its internal nodes. Consecutive
+// keys are the |start| and |stop| of a range for IterKeyRange, so each key is
+// the exclusive stop of one range and the inclusive start of the next. The
+// first and last ranges are open.
+func (m Map) SplitKeys(ctx context.Context, n int) ([]val.Tuple, error) {
+	return m.tuples.SplitKeys(ctx, n)
+}
+
 // GetOrdinalForKey returns the smallest ordinal position at which the key >=
 // |query|.
 func (m Map) GetOrdinalForKey(ctx context.Context, query val.Tuple) (uint64, error) {
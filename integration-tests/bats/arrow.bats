@@ -0,0 +1,155 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE types (
+  pk BIGINT PRIMARY KEY,
+  i TINYINT,
+  u BIGINT UNSIGNED,
+  b BIT(10),
+  d DECIMAL(10,2),
+  bd DECIMAL(50,10),
+  f DOUBLE,
+  e ENUM('one', 'two', 'three'),
+  s SET('a', 'b', 'c'),
+  g GEOMETRY,
+  j JSON,
+  uu CHAR(36) CHARACTER SET ascii COLLATE ascii_bin,
+  dt DATETIME(6),
+  dd DATE,
+  t TIME,
+  y YEAR,
+  vc VARCHAR(20),
+  vb VARBINARY(20)
+);
+INSERT INTO types VALUES
+    (1, -5, 18446744073709551615, b'1010101010', 1234.56, -12345678901234567890123456789012345678.0123456789, 1.5,
+     'two', 'a,c', POINT(1, 2), '{"a": [1, 2]}', '123e4567-e89b-12d3-a456-426655440000', '2020-04-08 11:11:11.123456',
+     '2020-04-08', '-11:11:11', 2020, 'hello', 0x00ff),
+    (2, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL);
+SQL
+    dolt add -A && dolt commit -m "create types"
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "arrow: round trip all types to and from an arrow file" {
+    run dolt sql -q "SELECT * FROM types ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    expected=$output
+
+    run dolt table export types types.arrow
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Successfully exported data." ]] || false
+    [ -f types.arrow ]
+
+    dolt table import -r types types.arrow
+
+    run dolt sql -q "SELECT * FROM types ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    [ "$output" = "$expected" ]
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit" ]] || false
+}
+
+@test "arrow: feather files are arrow files" {
+    dolt table export types types.feather
+    dolt schema export types types.sql
+    dolt sql -q "DROP TABLE types"
+
+    run dolt table import -c types types.feather
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Please specify schema file for .arrow tables." ]] || false
+
+    dolt table import -c -s types.sql types types.feather
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit" ]] || false
+}
+
+@test "arrow: import into an existing table reads columns by name" {
+    echo "import pyarrow as pa
+import pyarrow.feather as feather
+table = pa.table({'vc': ['imported'], 'pk': pa.array([3], type=pa.int64())})
+feather.write_feather(table, 'subset.arrow')
+" > write.py
+    run python3 write.py
+    [ "$status" -eq 0 ]
+
+    dolt sql -q "CREATE TABLE subset (pk BIGINT PRIMARY KEY, vc VARCHAR(20))"
+    dolt table import -u subset subset.arrow
+
+    run dolt sql -q "SELECT * FROM subset" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3,imported" ]] || false
+
+    run dolt table import -u types subset.arrow
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "column 'i' not found in arrow schema" ]] || false
+}
+
+@test "arrow: exported types read with pyarrow" {
+    dolt table export types types.arrow
+
+    echo "import pyarrow.feather as feather
+table = feather.read_table('types.arrow')
+for field in table.schema:
+    print(field.name, field.type)
+row = table.slice(0, 1).to_pylist()[0]
+print(row['d'], row['e'], row['s'], row['j'], row['b'], row['g'].hex())
+" > read.py
+    run python3 read.py
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "b uint64" ]] || false
+    [[ "$output" =~ "d decimal128(10, 2)" ]] || false
+    [[ "$output" =~ "bd decimal256(50, 10)" ]] || false
+    [[ "$output" =~ "e string" ]] || false
+    [[ "$output" =~ "g binary" ]] || false
+    [[ "$output" =~ "j string" ]] || false
+    [[ "$output" =~ "dt timestamp[us]" ]] || false
+    [[ "$output" =~ "dd date32[day]" ]] || false
+    [[ "$output" =~ "t duration[us]" ]] || false
+    [[ "$output" =~ '1234.56 two a,c {"a": [1, 2]} 682 0101000000000000000000f03f0000000000000040' ]] || false
+}
+
+@test "arrow: sql result output as an arrow stream" {
+    dolt sql -r arrow -q "SELECT pk, e, vc FROM types ORDER BY pk" > out.arrows
+    [ -s out.arrows ]
+
+    echo "import pyarrow as pa
+print(pa.ipc.open_stream(open('out.arrows', 'rb')).read_all().to_pylist())
+" > stream.py
+    run python3 stream.py
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "[{'pk': 1, 'e': 'two', 'vc': 'hello'}, {'pk': 2, 'e': None, 'vc': None}]" ]] || false
+
+    dolt sql -q "CREATE TABLE results (pk BIGINT PRIMARY KEY, e VARCHAR(10), vc VARCHAR(20))"
+    dolt table import -u --file-type arrow results out.arrows
+    run dolt sql -q "SELECT * FROM results ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1,two,hello" ]] || false
+}
+
+@test "arrow: dump writes an arrow file for each table" {
+    dolt sql -q "CREATE TABLE other (pk INT PRIMARY KEY)"
+    dolt sql -q "INSERT INTO other VALUES (1), (2)"
+
+    run dolt dump -r arrow
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Successfully exported data." ]] || false
+    [ -f doltdump/types.arrow ]
+    [ -f doltdump/other.arrow ]
+
+    dolt sql -q "DELETE FROM other"
+    dolt table import -u other doltdump/other.arrow
+    run dolt sql -q "SELECT COUNT(*) FROM other" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+}
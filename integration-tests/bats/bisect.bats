@@ -0,0 +1,80 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql -q "CREATE TABLE t (pk int primary key, v int);"
+    dolt add -A && dolt commit -m "create table t"
+    dolt sql -q "INSERT INTO t VALUES (1, 1);"
+    dolt commit -am "row 1"
+    dolt sql -q "INSERT INTO t VALUES (2, -2);"
+    dolt commit -am "add bad row"
+    dolt sql -q "INSERT INTO t VALUES (3, 3);"
+    dolt commit -am "row 3"
+    dolt sql -q "INSERT INTO t VALUES (4, 4);"
+    dolt commit -am "row 4"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "bisect: state persists across invocations" {
+    run dolt bisect start
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "waiting for both good and bad commits" ]] || false
+
+    run dolt bisect bad
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "waiting for good commit(s), bad commit known" ]] || false
+
+    run dolt bisect good HEAD~3
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Bisecting: " ]] || false
+    [[ "$output" =~ "row 3" ]] || false
+
+    run dolt bisect bad
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "add bad row" ]] || false
+
+    run dolt bisect bad
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "is the first bad commit" ]] || false
+    [[ "$output" =~ "add bad row" ]] || false
+
+    run dolt bisect log
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "dolt bisect start" ]] || false
+    [[ "$output" =~ "dolt bisect good" ]] || false
+    [[ "$output" =~ "dolt bisect bad" ]] || false
+
+    run dolt bisect reset
+    [ "$status" -eq 0 ]
+
+    run dolt bisect log
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "no bisect in progress" ]] || false
+}
+
+@test "bisect: run evaluates a query against each commit" {
+    dolt bisect start HEAD HEAD~3
+
+    run dolt bisect run "select count(*) = 0 from t where v < 0"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "is the first bad commit" ]] || false
+    [[ "$output" =~ "add bad row" ]] || false
+
+    # the working set is never changed by a bisect
+    run dolt status
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+    dolt bisect reset
+}
+
+@test "bisect: start fails when a bisect is in progress" {
+    dolt bisect start
+    run dolt bisect start
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "a bisect is already in progress" ]] || false
+}
@@ -183,3 +183,55 @@ SQL
     [[ "${lines[9]}" =~ "| sub  | 2   |" ]] || false
     [[ "${lines[10]}" =~ "| zzz  | 4   |" ]] || false
 }
+
+@test "blame: --columns annotates each cell" {
+    run dolt blame --columns blame_test
+    [ "$status" -eq 0 ]
+    [[ "${lines[1]}" =~ "pk".*"column_name".*"commit".*"commit_date".*"committer".*"email".*"message" ]] || false
+    [[ "$output" =~ "| 2  | pk          |".+"| Richard Tracy,  | bats-2@email.fake | add richard to blame_test     |" ]] || false
+    [[ "$output" =~ "| 2  | name        |".+"| Harry Wombat,   | bats-3@email.fake | replace richard with harry    |" ]] || false
+    [[ "$output" =~ "| 4  | name        |".+"| Johnny Moolah,  | bats-4@email.fake | add more people to blame_test |" ]] || false
+
+    run dolt sql -q "select pk, column_name, message from dolt_blame_cells_blame_test where pk = 1" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1,pk,create blame_test table" ]] || false
+    [[ "$output" =~ "1,name,create blame_test table" ]] || false
+}
+
+@test "blame: --columns with --since and --until" {
+    run dolt blame --columns --since HEAD~1 blame_test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| 1  | name        |".+"| Harry Wombat,  | bats-3@email.fake | replace richard with harry    |" ]] || false
+    [[ "$output" =~ "| 3  | name        |".+"| Johnny Moolah, | bats-4@email.fake | add more people to blame_test |" ]] || false
+
+    run dolt blame --columns --until HEAD~2 blame_test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| 2  | name        |".+"| Richard Tracy,  | bats-2@email.fake | add richard to blame_test |" ]] || false
+    [[ ! "$output" =~ "| 3  |" ]] || false
+
+    run dolt blame --columns HEAD~2 blame_test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| 2  | name        |".+"| Richard Tracy,  | bats-2@email.fake | add richard to blame_test |" ]] || false
+
+    run dolt blame --columns --until HEAD~2 HEAD blame_test
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "--until cannot be used with a revision argument" ]] || false
+
+    run dolt blame --since HEAD~1 blame_test
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "--since, --until and --follow can only be used with --columns" ]] || false
+}
+
+@test "blame: --columns --follow follows renamed columns" {
+    dolt sql -q "alter table blame_test rename column name to full_name"
+    dolt commit -am "rename name to full_name"
+
+    run dolt blame --columns blame_test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| 2  | full_name   |".+"| rename name to full_name      |" ]] || false
+
+    run dolt blame --columns --follow blame_test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| 2  | full_name   |".+"| Harry Wombat,   | bats-3@email.fake | replace richard with harry    |" ]] || false
+    [[ ! "$output" =~ "rename name to full_name" ]] || false
+}
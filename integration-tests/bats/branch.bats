@@ -300,3 +300,40 @@ teardown() {
     [ $status -eq "1" ]
     [[ "$output" =~ "is an invalid branch name" ]] || false
 }
+
+@test "branch: --prune-policy deletes the branches selected by dolt_branch_policies" {
+    dolt branch ci/merged
+    dolt branch ci/unmerged
+    dolt checkout ci/unmerged
+    dolt commit --allow-empty -m "unmerged"
+    dolt checkout main
+    dolt sql -q "insert into dolt_branch_policies (name, pattern, merged_into) values ('ci', 'ci/*', 'main')"
+
+    run dolt branch --prune-policy --dry-run
+    [ $status -eq 0 ]
+    [[ "$output" =~ "Would delete branch ci/merged" ]] || false
+    [[ ! "$output" =~ "ci/unmerged" ]] || false
+
+    run dolt branch
+    [[ "$output" =~ "ci/merged" ]] || false
+
+    run dolt branch --prune-policy
+    [ $status -eq 0 ]
+    [[ "$output" =~ "Deleted branch ci/merged" ]] || false
+    [[ "$output" =~ "per policy ci" ]] || false
+
+    run dolt branch
+    [[ ! "$output" =~ "ci/merged" ]] || false
+    [[ "$output" =~ "ci/unmerged" ]] || false
+
+    # the deleted branch remains in the reflog
+    run dolt reflog ci/merged
+    [ $status -eq 0 ]
+    [[ "$output" =~ "ci/merged" ]] || false
+}
+
+@test "branch: --prune-policy can't be combined with other actions" {
+    run dolt branch --prune-policy -d main
+    [ $status -eq 1 ]
+    [[ "$output" =~ "Must specify exactly one of" ]] || false
+}
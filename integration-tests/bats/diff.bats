@@ -1966,3 +1966,54 @@ SQL
   [[ "$output" =~ "t1" ]] || false
   [[ "$output" =~ "t2" ]] || false
 }
+
+@test "diff: --detect-renames shows primary key changes as modified rows" {
+  dolt reset --hard
+  dolt sql -q "create table t (id int primary key, code varchar(10) not null, name varchar(20));"
+  dolt sql -q "insert into t values (1, 'a', 'alice'), (2, 'b', 'bob');"
+  dolt add .
+  dolt commit -m "create t"
+  dolt sql -q "update t set id = 10 where id = 1;"
+
+  run dolt diff --detect-renames
+  [ $status -eq 0 ]
+  [[ "$output" =~ "| < | 1  | a    | alice |" ]] || false
+  [[ "$output" =~ "| > | 10 | a    | alice |" ]] || false
+  [[ ! "$output" =~ "| - |" ]] || false
+
+  run dolt diff --detect-renames -r sql
+  [ $status -eq 1 ]
+  [[ "$output" =~ "--detect-renames cannot be combined with sql output" ]] || false
+
+  dolt commit -am "update id"
+  dolt sql -q "alter table t drop primary key; alter table t add primary key (code);"
+
+  run dolt diff -d
+  [ $status -eq 0 ]
+  [[ "$output" =~ "Primary key sets differ between revisions for table 't', skipping data diff" ]] || false
+
+  run dolt diff -d --detect-renames
+  [ $status -eq 0 ]
+  [[ ! "$output" =~ "skipping data diff" ]] || false
+  [[ "$output" =~ "| < | 10 | a    | alice |" ]] || false
+  [[ "$output" =~ "| > | 10 | a    | alice |" ]] || false
+}
+
+@test "diff: dolt_row_lineage system table" {
+  dolt reset --hard
+  dolt sql -q "create table t (id int primary key, name varchar(20));"
+  dolt sql -q "insert into t values (1, 'alice');"
+  dolt add .
+  dolt commit -m "create t"
+  dolt sql -q "update t set id = 2 where id = 1;"
+  dolt commit -am "update id"
+
+  run dolt sql -r csv -q "select diff_type, \`match\`, from_key, to_key from dolt_row_lineage_t"
+  [ $status -eq 0 ]
+  [[ "$output" =~ 'added,,,"{""id"": 1}"' ]] || false
+  [[ "$output" =~ 'modified,similarity,"{""id"": 1}","{""id"": 2}"' ]] || false
+
+  run dolt sql -r csv -q "select count(distinct row_id) from dolt_row_lineage_t"
+  [ $status -eq 0 ]
+  [[ "$output" =~ "1" ]] || false
+}
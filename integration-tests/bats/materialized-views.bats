@@ -0,0 +1,50 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql -q "CREATE TABLE orders (id int primary key, customer varchar(20) not null, amount int not null);"
+    dolt sql -q "INSERT INTO orders VALUES (1, 'alice', 10), (2, 'alice', 20), (3, 'bob', 5);"
+    dolt add -A && dolt commit -m "orders"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "materialized-views: create, refresh and drop from the CLI" {
+    run dolt sql -q "CREATE MATERIALIZED VIEW totals AS SELECT customer, count(*) AS orders, sum(amount) AS total FROM orders GROUP BY customer;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "totals,full," ]] || false
+
+    dolt sql -q "INSERT INTO orders VALUES (4, 'carol', 7); DELETE FROM orders WHERE id = 1;"
+    run dolt sql -q "REFRESH MATERIALIZED VIEW totals;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "totals,incremental," ]] || false
+
+    run dolt sql -q "SELECT * FROM totals ORDER BY customer;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "alice,1,20" ]] || false
+    [[ "$output" =~ "bob,1,5" ]] || false
+    [[ "$output" =~ "carol,1,7" ]] || false
+
+    dolt sql -q "DROP MATERIALIZED VIEW totals;"
+    run dolt sql -q "SHOW TABLES;"
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "totals" ]] || false
+}
+
+@test "materialized-views: refresh on commit" {
+    dolt sql <<SQL
+CREATE MATERIALIZED VIEW totals REFRESH ON COMMIT AS SELECT customer, sum(amount) AS total, count(*) AS orders FROM orders GROUP BY customer;
+SQL
+    dolt add -A && dolt commit -m "view"
+
+    dolt sql -q "INSERT INTO orders VALUES (4, 'bob', 1);"
+    dolt commit -am "bob"
+
+    run dolt sql -q "SELECT total, source_commit = hashof('HEAD') FROM totals JOIN dolt_materialized_views WHERE customer = 'bob';" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "6,true" ]] || false
+}
@@ -0,0 +1,69 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql -q "CREATE TABLE inventory (id int primary key, qty int, name varchar(20));"
+    dolt sql -q "INSERT INTO inventory VALUES (1, 10, 'apple');"
+    dolt add -A && dolt commit -m "create table inventory"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "merge-strategies: dolt merge resolves conflicts with dolt_merge_strategies" {
+    dolt sql -q "INSERT INTO dolt_merge_strategies VALUES ('inventory', 'qty', 'sum', NULL);"
+
+    run dolt status
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "dolt_merge_strategies" ]] || false
+
+    dolt add -A && dolt commit -m "sum qty"
+
+    dolt checkout -b other
+    dolt sql -q "UPDATE inventory SET qty = 15;"
+    dolt commit -am "restock"
+    dolt checkout main
+    dolt sql -q "UPDATE inventory SET qty = 8;"
+    dolt commit -am "sell"
+
+    run dolt merge other -m "merge other"
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+
+    run dolt sql -q "SELECT qty FROM inventory;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "13" ]] || false
+}
+
+@test "merge-strategies: conflicts without a strategy are still reported" {
+    dolt sql -q "INSERT INTO dolt_merge_strategies VALUES ('inventory', 'qty', 'max', NULL);"
+    dolt add -A && dolt commit -m "max qty"
+
+    dolt checkout -b other
+    dolt sql -q "UPDATE inventory SET qty = 15, name = 'pear';"
+    dolt commit -am "other"
+    dolt checkout main
+    dolt sql -q "UPDATE inventory SET qty = 8, name = 'nashi';"
+    dolt commit -am "main"
+
+    run dolt merge other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT (content): Merge conflict in inventory" ]] || false
+
+    run dolt sql -q "SELECT our_qty, their_qty, our_name, their_name FROM dolt_conflicts_inventory;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "8,15,nashi,pear" ]] || false
+}
+
+@test "merge-strategies: invalid strategies are rejected" {
+    run dolt sql -q "INSERT INTO dolt_merge_strategies VALUES ('inventory', 'qty', 'newest', NULL);"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "unknown merge strategy 'newest'" ]] || false
+
+    run dolt sql -q "INSERT INTO dolt_merge_strategies VALUES ('inventory', '*', 'lww', NULL);"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "requires an lww_column" ]] || false
+}
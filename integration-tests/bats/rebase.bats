@@ -498,3 +498,86 @@ setupCustomEditorScript() {
     run dolt log
     [[ $output =~ "repeating change from main on b1" ]] || false
 }
+
+@test "rebase: edit, exec and break actions" {
+    setupCustomEditorScript "editPlan.txt"
+
+    dolt checkout b1
+    run dolt show head
+    [ "$status" -eq 0 ]
+    COMMIT1=${lines[0]:12:32}
+
+    dolt sql -q "insert into t2 values (1);"
+    dolt commit -am "b1 commit 2"
+    run dolt show head
+    [ "$status" -eq 0 ]
+    COMMIT2=${lines[0]:12:32}
+
+    touch editPlan.txt
+    echo "edit $COMMIT1 b1 commit 1" >> editPlan.txt
+    echo "exec select count(*) from t2 where 'it''s' = 'it''s'" >> editPlan.txt
+    echo "b" >> editPlan.txt
+    echo "pick $COMMIT2 b1 commit 2" >> editPlan.txt
+
+    run dolt rebase -i main
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Interactive rebase stopped at commit $COMMIT1 (b1 commit 1)" ]] || false
+
+    run dolt branch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "* dolt_rebase_b1" ]] || false
+
+    dolt sql -q "insert into t2 values (100);"
+    dolt add t2
+    run dolt rebase --continue
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Interactive rebase stopped at break" ]] || false
+
+    run dolt rebase --continue
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Successfully rebased and updated refs/heads/b1" ]] || false
+
+    run dolt show head~1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "b1 commit 1" ]] || false
+    [[ "$output" =~ "100" ]] || false
+
+    run dolt sql -q "select pk from t2 order by pk" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1" ]] || false
+    [[ "$output" =~ "100" ]] || false
+}
+
+@test "rebase: failing exec action aborts the rebase" {
+    setupCustomEditorScript "execPlan.txt"
+
+    dolt checkout b1
+    run dolt show head
+    [ "$status" -eq 0 ]
+    COMMIT1=${lines[0]:12:32}
+
+    touch execPlan.txt
+    echo "pick $COMMIT1 b1 commit 1" >> execPlan.txt
+    echo "exec select * from doesnotexist" >> execPlan.txt
+
+    run dolt rebase -i main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "exec statement failed while rebasing" ]] || false
+
+    run dolt branch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "* b1" ]] || false
+    [[ ! "$output" =~ "dolt_rebase_b1" ]] || false
+
+    dolt checkout main
+    dolt sql -q "call dolt_rebase('-i', 'b1'); insert into dolt_rebase values (0.5, 'exec', '', '', 'select * from doesnotexist');"
+    dolt checkout dolt_rebase_main
+    run dolt rebase --continue
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "exec statement failed while rebasing" ]] || false
+
+    run dolt branch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "* main" ]] || false
+    [[ ! "$output" =~ "dolt_rebase_main" ]] || false
+}
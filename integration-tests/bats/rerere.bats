@@ -0,0 +1,75 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql -q "CREATE TABLE t (pk int primary key, c int);"
+    dolt sql -q "INSERT INTO t VALUES (1, 1), (2, 2);"
+    dolt add -A && dolt commit -m "create table t"
+    dolt branch other
+    dolt sql -q "UPDATE t SET c = 10 WHERE pk = 1;"
+    dolt commit -am "ours"
+    dolt checkout other
+    dolt sql -q "UPDATE t SET c = 11 WHERE pk = 1; UPDATE t SET c = 22 WHERE pk = 2;"
+    dolt commit -am "theirs"
+    dolt checkout main
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "rerere: resolutions are reused when merging again" {
+    run dolt rerere
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "No recorded resolutions" ]] || false
+
+    run dolt merge other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT (content)" ]] || false
+
+    run dolt conflicts resolve --theirs t
+    [ "$status" -eq 0 ]
+
+    run dolt rerere list
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| t          | 1       | 11           |" ]] || false
+
+    dolt merge --abort
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+
+    run dolt sql -q "SELECT * FROM t ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1,11" ]] || false
+    [[ "$output" =~ "2,22" ]] || false
+}
+
+@test "rerere: forgotten resolutions are not reused" {
+    dolt merge other || true
+    dolt sql -q "SET @@dolt_allow_commit_conflicts = 1; UPDATE t SET c = 100 WHERE pk = 1; DELETE FROM dolt_conflicts_t;"
+
+    run dolt rerere
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| t          | 1       | 100          |" ]] || false
+    id=$(dolt sql -r csv -q "CALL dolt_rerere()" | sed -n 2p | cut -d, -f1)
+
+    run dolt rerere forget "$id"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Forgot resolution $id of t (1)" ]] || false
+
+    run dolt rerere
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "No recorded resolutions" ]] || false
+
+    dolt merge --abort
+    run dolt merge other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT (content)" ]] || false
+
+    run dolt rerere forget
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "requires either resolution ids or --all" ]] || false
+}
@@ -0,0 +1,95 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE t (pk int primary key, owner varchar(20));
+INSERT INTO t VALUES (1, 'root'), (2, 'alice'), (3, 'bob');
+CREATE USER alice@'%';
+GRANT ALL ON *.* TO alice@'%';
+SQL
+    dolt add -A && dolt commit -m "create table t"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "row-policies: rows are filtered for each user" {
+    dolt sql -q "INSERT INTO dolt_row_policies VALUES ('t', 'own_rows', '%', '%', 'owner = SUBSTRING_INDEX(CURRENT_USER(), ''@'', 1)');"
+
+    run dolt status
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "dolt_row_policies" ]] || false
+
+    run dolt sql -q "SELECT pk FROM t;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1" ]] || false
+    [[ ! "$output" =~ "2" ]] || false
+    [[ ! "$output" =~ "3" ]] || false
+
+    run dolt --user alice --password "" sql -q "SELECT pk FROM t;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+    [[ ! "$output" =~ "1" ]] || false
+    [[ ! "$output" =~ "3" ]] || false
+}
+
+@test "row-policies: history can't be used to read hidden rows" {
+    dolt sql -q "INSERT INTO dolt_row_policies VALUES ('t', 'own_rows', '%', '%', 'owner = SUBSTRING_INDEX(CURRENT_USER(), ''@'', 1)');"
+
+    run dolt --user alice --password "" sql -q "SELECT pk FROM t AS OF 'HEAD';" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+    [[ ! "$output" =~ "3" ]] || false
+
+    run dolt --user alice --password "" sql -q "SELECT to_pk FROM dolt_diff_t;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+    [[ ! "$output" =~ "3" ]] || false
+
+    run dolt --user alice --password "" sql -q "SELECT pk FROM dolt_history_t;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+    [[ ! "$output" =~ "3" ]] || false
+}
+
+@test "row-policies: writes must satisfy the policies" {
+    dolt sql -q "INSERT INTO dolt_row_policies VALUES ('t', 'own_rows', '%', '%', 'owner = SUBSTRING_INDEX(CURRENT_USER(), ''@'', 1)');"
+
+    run dolt --user alice --password "" sql -q "INSERT INTO t VALUES (4, 'bob');"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "row policies of table \`t\` do not allow \`alice\`@\`localhost\` to write this row" ]] || false
+
+    run dolt --user alice --password "" sql -q "INSERT INTO t VALUES (4, 'alice');"
+    [ "$status" -eq 0 ]
+
+    run dolt --user alice --password "" sql -q "DELETE FROM t;"
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "DELETE FROM dolt_row_policies;"
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT pk FROM t ORDER BY pk;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1" ]] || false
+    [[ "$output" =~ "3" ]] || false
+    [[ ! "$output" =~ "2" ]] || false
+    [[ ! "$output" =~ "4" ]] || false
+}
+
+@test "row-policies: tables without policies are unaffected" {
+    dolt sql -q "CREATE TABLE u (pk int primary key);"
+    dolt sql -q "INSERT INTO u VALUES (1), (2);"
+    dolt sql -q "INSERT INTO dolt_row_policies VALUES ('t', 'nothing', '%', '%', 'false');"
+
+    run dolt sql -q "SELECT count(*) FROM u;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+
+    run dolt sql -q "SELECT count(*) FROM t;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "0" ]] || false
+}
@@ -684,3 +684,45 @@ GRANT CLONE_ADMIN ON *.* TO clone_admin_user@'localhost';
     [[ "$output" =~ "main" ]] || false
 }
 
+
+@test "sql-server-remotesrv: push to a protected branch from remotesapi port is rejected" {
+    mkdir remote
+    cd remote
+    dolt init
+    dolt sql -q 'create table names (name varchar(10) primary key);'
+    dolt sql -q 'insert into names (name) values ("abe"), ("betsy"), ("calvin");'
+    dolt add names
+    dolt commit -m 'initial names.'
+    dolt branch release HEAD
+
+    APIPORT=$( definePORT )
+    export DOLT_REMOTE_PASSWORD="rootpass"
+    export SQL_USER="root"
+    start_sql_server_with_args -u "$SQL_USER" -p "$DOLT_REMOTE_PASSWORD" --remotesapi-port $APIPORT
+
+    dolt sql -q "insert into dolt_branch_protection values ('%', 'main', 'no_force_push,no_delete'), ('%', 'release', 'no_delete');"
+    dolt sql -q "insert into names values ('zeek'); call dolt_commit('-am', 'add zeek');"
+
+    cd ../
+    dolt clone http://localhost:$APIPORT/remote cloned_db -u $SQL_USER
+    cd cloned_db
+
+    dolt reset --hard HEAD~1
+    dolt sql -q 'insert into names values ("dave");'
+    dolt commit -am 'add dave'
+
+    run dolt push origin --force --user $SQL_USER main:main
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ "branch \`main\` is protected: its new head must descend from its current head" ]] || false
+
+    run dolt push origin --user $SQL_USER :release
+    [[ "$status" -ne 0 ]] || false
+    [[ "$output" =~ "branch \`release\` is protected: it cannot be deleted" ]] || false
+
+    cd ../remote
+    run dolt sql -q 'select * from names;'
+    [[ "$output" =~ "zeek" ]] || false
+    ! [[ "$output" =~ "dave" ]] || false
+    run dolt branch
+    [[ "$output" =~ "release" ]] || false
+}
@@ -0,0 +1,65 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql -q "CREATE TABLE users (id int primary key, name varchar(20));"
+    dolt sql -q "INSERT INTO users VALUES (1, 'alice'), (2, 'bob');"
+    dolt sql -q "INSERT INTO dolt_tests VALUES ('user_count', 'users', 'SELECT * FROM users', 'expected_row_count', NULL, '2');"
+    dolt sql -q "INSERT INTO dolt_tests VALUES ('no_carol', NULL, 'SELECT * FROM users WHERE name = ''carol''', 'no_rows', NULL, NULL);"
+    dolt add -A && dolt commit -m "add users and tests"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "sql-tests: dolt test run reports passing tests" {
+    run dolt test run
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "user_count" ]] || false
+    [[ "$output" =~ "no_carol" ]] || false
+    [[ "$output" =~ "2 passed, 0 failed" ]] || false
+}
+
+@test "sql-tests: dolt test run exits non-zero when a test fails" {
+    dolt sql -q "INSERT INTO users VALUES (3, 'carol');"
+
+    run dolt test run
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "expected no rows, got 1" ]] || false
+    [[ "$output" =~ "expected row count == 2, got 3" ]] || false
+    [[ "$output" =~ "0 passed, 2 failed" ]] || false
+
+    run dolt test run HEAD
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2 passed, 0 failed" ]] || false
+}
+
+@test "sql-tests: dolt test run --group" {
+    dolt sql -q "INSERT INTO users VALUES (3, 'carol');"
+
+    run dolt test run --group users
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "user_count" ]] || false
+    [[ ! "$output" =~ "no_carol" ]] || false
+    [[ "$output" =~ "0 passed, 1 failed" ]] || false
+}
+
+@test "sql-tests: dolt test run result formats" {
+    run dolt test run -r json
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ '"test_name":"user_count"' ]] || false
+
+    dolt sql -q "INSERT INTO users VALUES (3, 'carol');"
+    run dolt test run -r junit
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ '<testsuites name="dolt_tests" tests="2" failures="2">' ]] || false
+    [[ "$output" =~ '<testsuite name="users" tests="1" failures="1">' ]] || false
+    [[ "$output" =~ '<failure message="expected no rows, got 1">' ]] || false
+
+    run dolt test run -r csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Invalid argument for --result-format" ]] || false
+}
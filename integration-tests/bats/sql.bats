@@ -980,6 +980,33 @@ SQL
     [[ "$output" =~ "not found" ]] || false
 }
 
+@test "sql: FOR SYSTEM_TIME queries" {
+    dolt sql -q "create table versioned (pk int primary key, c1 int); insert into versioned values (1, 10), (2, 20);"
+    dolt commit -Am "first" --date "2020-03-01T12:00:00Z"
+    dolt sql -q "update versioned set c1 = 11 where pk = 1"
+    dolt commit -am "second" --date "2020-03-01T13:00:00Z"
+    dolt sql -q "delete from versioned where pk = 2"
+    dolt commit -am "third" --date "2020-03-01T14:00:00Z"
+
+    run dolt sql -r csv -q "select pk, c1, valid_from, valid_to from versioned for system_time all order by pk, valid_from"
+    [ $status -eq 0 ]
+    [[ "$output" =~ "1,10,2020-03-01 12:00:00,2020-03-01 13:00:00" ]] || false
+    [[ "$output" =~ "1,11,2020-03-01 13:00:00," ]] || false
+    [[ "$output" =~ "2,20,2020-03-01 12:00:00,2020-03-01 14:00:00" ]] || false
+
+    run dolt sql -r csv -q "select pk, c1 from versioned for system_time from '2020-03-01 12:30:00' to '2020-03-01 14:00:00' order by pk, valid_from"
+    [ $status -eq 0 ]
+    [ "${lines[1]}" = "1,10" ]
+    [ "${lines[2]}" = "1,11" ]
+    [ "${lines[3]}" = "2,20" ]
+    [ "${#lines[@]}" -eq 4 ]
+
+    run dolt sql -r csv -q "select pk, c1 from versioned for system_time between 'HEAD' and 'HEAD' order by pk"
+    [ $status -eq 0 ]
+    [ "${lines[1]}" = "1,11" ]
+    [ "${#lines[@]}" -eq 2 ]
+}
+
 @test "sql: output formats" {
     dolt sql <<SQL
     CREATE TABLE test (
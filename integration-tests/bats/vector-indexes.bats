@@ -0,0 +1,81 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql -q "CREATE TABLE items (id int primary key, emb json);"
+    dolt sql -q "INSERT INTO items VALUES (1, '[0, 0]'), (2, '[1, 1]'), (3, '[10, 10]');"
+    dolt sql -q "CREATE VECTOR INDEX emb_idx ON items(emb);"
+    dolt add -A && dolt commit -m "items"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "vector-indexes: nearest neighbor search from the CLI" {
+    run dolt sql -q "SELECT id FROM items ORDER BY VEC_DISTANCE_L2(emb, '[9, 9]') LIMIT 2;" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "3" ]
+    [ "${lines[2]}" = "2" ]
+
+    run dolt sql -q "EXPLAIN SELECT id FROM items ORDER BY VEC_DISTANCE_L2(emb, '[9, 9]') LIMIT 2;"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "order: VEC_DISTANCE_L2(items.emb, [9, 9])" ]] || false
+}
+
+@test "vector-indexes: pseudo-index tables are hidden from status and schema output" {
+    dolt sql -q "INSERT INTO items VALUES (4, '[2, 2]');"
+
+    run dolt status
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "items" ]] || false
+    [[ ! "$output" =~ "vec_buckets" ]] || false
+
+    run dolt diff
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "vec_buckets" ]] || false
+
+    run dolt schema show items
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "emb_idx" ]] || false
+
+    run dolt sql -q "SELECT * FROM dolt_vector_indexes;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "items,emb_idx,emb" ]] || false
+}
+
+@test "vector-indexes: the index is rebuilt when merging branches" {
+    dolt checkout -b other
+    dolt sql -q "INSERT INTO items VALUES (4, '[9, 8]');"
+    dolt commit -am "other"
+    dolt checkout main
+    dolt sql -q "INSERT INTO items VALUES (5, '[8, 9]');"
+    dolt commit -am "main"
+
+    run dolt merge other -m "merge"
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT id FROM items ORDER BY VEC_DISTANCE_L2(emb, '[9, 9]') LIMIT 3;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "4" ]] || false
+    [[ "$output" =~ "5" ]] || false
+    [[ "$output" =~ "3" ]] || false
+
+    run dolt sql -q "SELECT count(*) FROM dolt_items_emb_idx_vec_buckets;" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "5" ]
+}
+
+@test "vector-indexes: indexed columns can't be dropped" {
+    run dolt sql -q "ALTER TABLE items DROP COLUMN emb;"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "has vector index 'emb_idx'" ]] || false
+
+    dolt sql -q "DROP INDEX emb_idx ON items;"
+    dolt sql -q "ALTER TABLE items DROP COLUMN emb;"
+    run dolt sql -q "SHOW TABLES;"
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "vec_buckets" ]] || false
+}
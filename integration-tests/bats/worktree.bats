@@ -0,0 +1,116 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql -q "CREATE TABLE t (pk int primary key, c int);"
+    dolt sql -q "INSERT INTO t VALUES (1, 1);"
+    dolt add -A && dolt commit -m "initial"
+    dolt branch feature
+
+    WORKTREE="$BATS_TMPDIR/worktree-$$"
+}
+
+teardown() {
+    rm -rf "$WORKTREE"
+    teardown_common
+}
+
+@test "worktree: add, list and remove" {
+    run dolt worktree add "$WORKTREE" feature
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Created worktree at $WORKTREE with branch 'feature'" ]] || false
+    [ ! -d "$WORKTREE/.dolt/noms" ]
+
+    run dolt worktree list
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "$(pwd) [main]" ]] || false
+    [[ "$output" =~ "$WORKTREE [feature]" ]] || false
+
+    cd "$WORKTREE"
+    run dolt status
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "On branch feature" ]] || false
+
+    run dolt worktree remove "$WORKTREE"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "cannot remove the current worktree" ]] || false
+
+    cd -
+    run dolt worktree remove "$WORKTREE"
+    [ "$status" -eq 0 ]
+    [ ! -d "$WORKTREE" ]
+
+    run dolt worktree
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "$WORKTREE" ]] || false
+
+    run dolt worktree remove "$WORKTREE"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "is not a linked worktree" ]] || false
+}
+
+@test "worktree: worktrees share commits but not working sets" {
+    dolt worktree add "$WORKTREE" feature
+    cd "$WORKTREE"
+    dolt sql -q "INSERT INTO t VALUES (2, 2);"
+    dolt commit -am "feature row"
+    dolt sql -q "INSERT INTO t VALUES (3, 3);"
+    cd -
+
+    run dolt log --oneline feature
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "feature row" ]] || false
+
+    run dolt status
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "nothing to commit" ]] || false
+
+    run dolt sql -q "SELECT count(*) FROM t;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1" ]] || false
+
+    run dolt sql -q "SELECT count(*) FROM t AS OF 'feature';" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+
+    # The uncommitted row stays in the working set of the branch after the worktree is removed
+    dolt worktree remove "$WORKTREE"
+    dolt checkout feature
+    run dolt sql -q "SELECT pk FROM t ORDER BY pk;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+}
+
+@test "worktree: a branch can't be checked out in two worktrees" {
+    run dolt worktree add "$WORKTREE" main
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "branch 'main' is already checked out at" ]] || false
+    [ ! -d "$WORKTREE" ]
+
+    run dolt worktree add "$WORKTREE" missing
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "Branch 'missing' not found" ]] || false
+
+    dolt worktree add "$WORKTREE" feature
+    run dolt checkout feature
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "branch 'feature' is already checked out at '$WORKTREE'" ]] || false
+
+    run dolt worktree add "$WORKTREE" feature
+    [ "$status" -ne 0 ]
+
+    cd "$WORKTREE"
+    run dolt checkout main
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "branch 'main' is already checked out at" ]] || false
+
+    dolt checkout -b other
+    cd -
+    dolt checkout feature
+    run dolt worktree
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "[feature]" ]] || false
+    [[ "$output" =~ "$WORKTREE [other]" ]] || false
+}
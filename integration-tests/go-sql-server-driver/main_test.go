@@ -94,3 +94,11 @@ func TestTLS(t *testing.T) {
 func TestClusterReadOnly(t *testing.T) {
 	RunTestsFile(t, "tests/sql-server-cluster-read-only.yaml")
 }
+
+func TestClusterElection(t *testing.T) {
+	RunTestsFile(t, "tests/sql-server-cluster-election.yaml")
+}
+
+func TestClusterWriteForwarding(t *testing.T) {
+	RunTestsFile(t, "tests/sql-server-cluster-write-forwarding.yaml")
+}